@@ -7,6 +7,7 @@ manifest: {
 	kinds: [
 		repository,
 		connection,
+		repositoryPolicy,
 	]
 	roles: {}
 }
@@ -0,0 +1,54 @@
+package repository
+
+repositoryPolicy: {
+	kind:       "RepositoryPolicy"
+	pluralName: "RepositoryPolicies"
+	current:    "v0alpha1"
+	validation: {
+		operations: [
+			"CREATE",
+			"UPDATE",
+		]
+	}
+	versions: {
+		"v0alpha1": {
+			codegen: {
+				ts: {enabled: false}
+				go: {enabled: true}
+			}
+			schema: {
+				#PolicyRule: {
+					// Name identifies the rule in the reported violations.
+					name: string
+					// Overrides the policy enforcement for this rule.
+					enforcement?: "fail" | "warn"
+					// The resource kinds the rule applies to (e.g. `Dashboard`).
+					kinds?: [...string]
+					// The repository folders the rule applies to, including their subfolders.
+					paths?: [...string]
+					// Conditions on the resource fields. All of them must hold.
+					conditions?: [...#PolicyCondition]
+					// When set, every datasource UID referenced by the resource must be in this list.
+					allowedDatasourceUIDs?: [...string]
+				}
+				#PolicyCondition: {
+					// Dot separated path to the field (e.g. `spec.tags`).
+					field: string
+					// The check run on the field value.
+					operator: "Exists" | "In" | "NotIn" | "MinDuration"
+					// The values used by the In, NotIn and MinDuration operators.
+					values?: [...string]
+				}
+				spec: {
+					// The repositories in the namespace the policy applies to.
+					// When empty, the policy applies to every repository in the namespace.
+					repositories?: [...string]
+					// How violations are handled when a rule does not set its own enforcement.
+					enforcement?: "fail" | "warn"
+					// The rules checked against every changed file.
+					rules?: [...#PolicyRule]
+				}
+			}
+		}
+	}
+}
@@ -92,6 +92,20 @@ repository: {
 					// Path is the subdirectory for the Grafana data. If specified, Grafana will ignore anything that is outside this directory in the repository.
 					path?: string
 				}
+				#BucketRepositoryConfig: {
+					// The bucket URL (e.g. `s3://my-bucket?region=us-east-1`, `gs://my-bucket` or `file:///var/lib/grafana/bucket`).
+					url?: string
+					// Path is the key prefix for the Grafana data inside the bucket.
+					path?: string
+				}
+				#SourceDirectory: {
+					// Path of the folder in the repository. An empty path marks the whole repository.
+					path: string
+					// The language of the source files in the folder.
+					format: "jsonnet" | "cue"
+					// Repository folders with shared code.
+					libraries?: [...string]
+				}
 				#SyncOptions: {
 					// Enabled must be saved as true before any sync job will run
 					enabled: bool
@@ -99,6 +113,24 @@ repository: {
 					target: "instance" | "folder"
 					// When non-zero, the sync will run periodically
 					intervalSeconds?: int
+					// Cron expression for when sync runs. When set, it replaces the interval between sync runs.
+					schedule?: string
+					// IANA time zone used by the schedule and the sync windows. Defaults to UTC.
+					timeZone?: string
+					// Windows that restrict when sync can run.
+					windows?: [...#SyncWindow]
+					// Maximum number of jobs for this repository that can run at the same time.
+					maxConcurrentJobs?: int
+					// How a sync handles resources that changed in both the repository and Grafana since the last sync.
+					conflictStrategy?: "repository" | "grafana" | "branch"
+				}
+				#SyncWindow: {
+					// maintenance only allows sync during the window, blackout pauses sync during the window
+					type: "maintenance" | "blackout"
+					// Cron expression for when the window starts
+					start: string
+					// How long the window lasts, e.g. 2h or 30m
+					duration: string
 				}
 				#ConnectionInfo: {
 					name: string
@@ -135,6 +167,20 @@ repository: {
 					// PEM-encoded X.509 certificate paired with secure.commitSigningKey when
 					// signingMethod is "smime". This is public, not a secret.
 					smimeCertificate?: string
+					// Verification of the commits pulled by sync jobs. When empty, incoming commits are not verified.
+					verification?: #CommitVerification
+				}
+				#CommitVerification: {
+					// When true, sync jobs reject changes from commits touching the synced path
+					// unless they carry a valid signature from one of the trusted keys.
+					// The gpg or ssh key in secure.commitSigningKey is always trusted.
+					requireSignedCommits?: bool
+					// ASCII-armored OpenPGP public keys trusted to sign commits.
+					gpgKeys?: [...string]
+					// SSH keys trusted to sign commits, in the git allowed signers format.
+					sshAllowedSigners?: [...string]
+					// Hashes of commits accepted without a valid signature.
+					acknowledgedCommits?: [...string]
 				}
 				#HealthStatus: {
 					// When not healthy, requests will not be executed
@@ -162,6 +208,25 @@ repository: {
 					lastRef?: string
 					// Incremental synchronization for versioned repositories
 					incremental?: bool
+					// Conflicts found by the last sync, and the ones that kept the Grafana version until they are resolved
+					conflicts?: [...#ResourceConflict]
+				}
+				#ResourceConflict: {
+					// Path to the file in the repository
+					path: string
+					name?:  string
+					group?: string
+					kind?:  string
+					// The change made in the repository (update or delete)
+					action: "create" | "update" | "delete" | "move"
+					// The file hash recorded on the resource when it was last synced
+					syncedHash?: string
+					// The file hash at the previously synced ref
+					baseHash?: string
+					// How the conflict was resolved
+					resolution: "repository" | "grafana" | "branch"
+					// The branch the Grafana version was written to, when resolved with the branch strategy
+					branch?: string
 				}
 				#ResourceCount: {
 					group:    string
@@ -191,7 +256,7 @@ repository: {
 					// Sync settings -- how values are pulled from the repository into grafana
 					sync: #SyncOptions
 					// The repository type. When selected oneOf the values below should be non-nil
-					type: "local" | "github" | "githubEnterprise" | "git" | "bitbucket" | "gitlab"
+					type: "local" | "github" | "githubEnterprise" | "git" | "bitbucket" | "gitlab" | "bucket"
 					// Webhook settings for the repository.
 					webhook?: #WebhookConfig
 					// The repository on the local file system.
@@ -212,9 +277,14 @@ repository: {
 					// The repository on GitLab.
 					// Mutually exclusive with local | github | git.
 					gitlab?: #GitLabRepositoryConfig
+					// The repository in an object storage bucket.
+					// Mutually exclusive with local | github | git.
+					bucket?: #BucketRepositoryConfig
 					// The connection the repository references.
 					// This means the Repository is interacting with git via a Connection.
 					connection?: #ConnectionInfo
+					// Folders holding Jsonnet or CUE source files.
+					sources?: [...#SourceDirectory]
 				}
 				status: {
 					// The generation of the spec last time reconciliation ran
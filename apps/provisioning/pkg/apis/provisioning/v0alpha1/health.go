@@ -113,6 +113,18 @@ const (
 	// without user action, so provisioning surfaces it as a warning rather
 	// than retrying the failed write.
 	ReasonFolderValidationFailed = "FolderValidationFailed"
+	// ReasonSourceEvaluationFailed indicates that a Jsonnet or CUE source file,
+	// or the values file of a template, could not be evaluated into a resource.
+	// The file must be fixed in the repository; the other files are still synced.
+	ReasonSourceEvaluationFailed = "SourceEvaluationFailed"
+	// ReasonPolicyViolation indicates that a resource was applied but does not
+	// satisfy a warn rule of the repository policy. Resources that violate a
+	// fail rule are not applied and reported as errors instead.
+	ReasonPolicyViolation = "PolicyViolation"
+	// ReasonResourceConflict indicates that a resource changed in both the
+	// repository and Grafana since the last sync. The conflict is resolved with
+	// the repository conflict strategy and listed in the sync status.
+	ReasonResourceConflict = "ResourceConflict"
 )
 
 // Condition reasons for the Quota condition
@@ -77,6 +77,10 @@ const (
 	// This action has inverted validation: it is only allowed when the repository
 	// does not exist or has a DeletionTimestamp set.
 	JobActionDeleteResources JobAction = "deleteResources"
+
+	// JobActionRollback restores the resources changed by a previous job.
+	// Write-enabled repositories revert the changed files in a single commit instead.
+	JobActionRollback JobAction = "rollback"
 )
 
 // +enum
@@ -137,6 +141,9 @@ type JobSpec struct {
 
 	// Options when the action is `fix-folder-metadata`
 	FixFolderMetadata *FixFolderMetadataJobOptions `json:"fixFolderMetadata,omitempty"`
+
+	// Required when the action is `rollback`
+	Rollback *RollbackJobOptions `json:"rollback,omitempty"`
 }
 
 func (JobSpec) OpenAPIModelName() string {
@@ -155,6 +162,9 @@ type PullRequestJobOptions struct {
 
 	// URL to the originator (eg, PR URL)
 	URL string `json:"url,omitempty"`
+
+	// The pull request was closed or merged, so its preview is removed
+	Closed bool `json:"closed,omitempty"`
 }
 
 func (PullRequestJobOptions) OpenAPIModelName() string {
@@ -164,12 +174,30 @@ func (PullRequestJobOptions) OpenAPIModelName() string {
 type SyncJobOptions struct {
 	// Incremental synchronization for versioned repositories
 	Incremental bool `json:"incremental"`
+
+	// Resolutions for the conflicts listed in the sync status of the repository.
+	// A conflict the sync finds for the same path is resolved the same way.
+	// +listType=atomic
+	Resolutions []ConflictResolution `json:"resolutions,omitempty"`
 }
 
 func (SyncJobOptions) OpenAPIModelName() string {
 	return OpenAPIPrefix + "SyncJobOptions"
 }
 
+// ConflictResolution resolves the conflict of a single resource
+type ConflictResolution struct {
+	// Path to the file in the repository
+	Path string `json:"path"`
+
+	// Apply the repository version, keep the Grafana version, or write the Grafana version to a merge branch
+	Resolution ConflictStrategy `json:"resolution"`
+}
+
+func (ConflictResolution) OpenAPIModelName() string {
+	return OpenAPIPrefix + "ConflictResolution"
+}
+
 type ExportJobOptions struct {
 	// Message to use when committing the changes in a single commit.
 	// Deprecated: set JobSpec.Message instead. This field is kept for
@@ -299,6 +327,19 @@ func (FixFolderMetadataJobOptions) OpenAPIModelName() string {
 	return OpenAPIPrefix + "FixFolderMetadataJobOptions"
 }
 
+type RollbackJobOptions struct {
+	// The UID of the finished job to roll back
+	Job string `json:"job"`
+
+	// List the changes without applying them.
+	// The planned changes are reported in the job status.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+func (RollbackJobOptions) OpenAPIModelName() string {
+	return OpenAPIPrefix + "RollbackJobOptions"
+}
+
 // The job status
 type JobStatus struct {
 	State    JobState `json:"state,omitempty"`
@@ -316,6 +357,11 @@ type JobStatus struct {
 
 	// URLs contains URLs for the reference branch or commit if applicable.
 	URLs *RepositoryURLs `json:"url,omitempty"`
+
+	// Changes made to resources by the job, used to roll it back.
+	// For a dry run rollback, the changes that would be made.
+	// +listType=atomic
+	Changes []JobResourceChange `json:"changes,omitempty"`
 }
 
 func (JobStatus) OpenAPIModelName() string {
@@ -365,6 +411,29 @@ func (JobResourceSummary) OpenAPIModelName() string {
 	return OpenAPIPrefix + "JobResourceSummary"
 }
 
+// JobResourceChange records the resource versions around a change made by a job
+type JobResourceChange struct {
+	Group    string `json:"group"`
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+
+	// The path of the file in the repository
+	Path string `json:"path,omitempty"`
+
+	// The change made to the resource
+	Action ResourceAction `json:"action"`
+
+	// The resource version before the change (empty when created)
+	PreviousVersion string `json:"previousVersion,omitempty"`
+
+	// The resource version after the change (empty when deleted)
+	Version string `json:"version,omitempty"`
+}
+
+func (JobResourceChange) OpenAPIModelName() string {
+	return OpenAPIPrefix + "JobResourceChange"
+}
+
 // HistoricJob is an append only log, saving all jobs that have been processed.
 //
 // NOTE: This should not be used directly by any external consumer.
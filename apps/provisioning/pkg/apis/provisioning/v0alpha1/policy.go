@@ -0,0 +1,128 @@
+package v0alpha1
+
+import (
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RepositoryPolicy holds declarative rules that each changed file must satisfy.
+// They are checked by sync and pull request jobs before a resource is applied.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RepositoryPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RepositoryPolicySpec `json:"spec,omitempty"`
+}
+
+func (RepositoryPolicy) OpenAPIModelName() string {
+	return OpenAPIPrefix + "RepositoryPolicy"
+}
+
+type RepositoryPolicySpec struct {
+	// The repositories in the namespace the policy applies to.
+	// When empty, the policy applies to every repository in the namespace.
+	Repositories []string `json:"repositories,omitempty"`
+
+	// How violations are handled when a rule does not set its own enforcement.
+	// When empty, violations fail the file.
+	Enforcement PolicyEnforcement `json:"enforcement,omitempty"`
+
+	// The rules checked against every changed file.
+	Rules []PolicyRule `json:"rules,omitempty"`
+}
+
+func (RepositoryPolicySpec) OpenAPIModelName() string {
+	return OpenAPIPrefix + "RepositoryPolicySpec"
+}
+
+// AppliesTo reports whether the policy covers the named repository.
+func (p *RepositoryPolicy) AppliesTo(repository string) bool {
+	return len(p.Spec.Repositories) == 0 || slices.Contains(p.Spec.Repositories, repository)
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RepositoryPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// +listType=atomic
+	Items []RepositoryPolicy `json:"items"`
+}
+
+func (RepositoryPolicyList) OpenAPIModelName() string {
+	return OpenAPIPrefix + "RepositoryPolicyList"
+}
+
+// PolicyEnforcement defines what happens to a file that violates a rule.
+// +enum
+type PolicyEnforcement string
+
+const (
+	// PolicyEnforcementFail skips the file and reports the violations as errors
+	PolicyEnforcementFail PolicyEnforcement = "fail"
+	// PolicyEnforcementWarn applies the file and reports the violations as warnings
+	PolicyEnforcementWarn PolicyEnforcement = "warn"
+)
+
+// PolicyRule checks the resources matched by kind and path.
+// A rule with no kinds and no paths applies to every resource in the repository.
+type PolicyRule struct {
+	// Name identifies the rule in the reported violations.
+	Name string `json:"name"`
+
+	// Overrides the policy enforcement for this rule.
+	Enforcement PolicyEnforcement `json:"enforcement,omitempty"`
+
+	// The resource kinds the rule applies to (e.g. `Dashboard`).
+	// When empty, the rule applies to all kinds.
+	Kinds []string `json:"kinds,omitempty"`
+
+	// The repository folders the rule applies to, including their subfolders (e.g. `prod`).
+	// When empty, the rule applies to the whole repository.
+	Paths []string `json:"paths,omitempty"`
+
+	// Conditions on the resource fields. All of them must hold.
+	Conditions []PolicyCondition `json:"conditions,omitempty"`
+
+	// When set, every datasource UID referenced by the resource must be in this list.
+	// Template variables and the built-in Grafana, Mixed and Dashboard datasources are always allowed.
+	AllowedDatasourceUIDs []string `json:"allowedDatasourceUIDs,omitempty"`
+}
+
+func (PolicyRule) OpenAPIModelName() string {
+	return OpenAPIPrefix + "PolicyRule"
+}
+
+// PolicyCondition checks a single field of the resource.
+type PolicyCondition struct {
+	// Dot separated path to the field (e.g. `spec.tags` or `metadata.labels.team`).
+	Field string `json:"field"`
+
+	// The check run on the field value.
+	Operator PolicyOperator `json:"operator"`
+
+	// The values used by the In, NotIn and MinDuration operators.
+	Values []string `json:"values,omitempty"`
+}
+
+func (PolicyCondition) OpenAPIModelName() string {
+	return OpenAPIPrefix + "PolicyCondition"
+}
+
+// PolicyOperator is the check run by a PolicyCondition.
+// +enum
+type PolicyOperator string
+
+const (
+	// PolicyOperatorExists requires the field to be set to a non-empty value
+	PolicyOperatorExists PolicyOperator = "Exists"
+	// PolicyOperatorIn requires the field, or each of its items, to be one of the values
+	PolicyOperatorIn PolicyOperator = "In"
+	// PolicyOperatorNotIn requires the field, and each of its items, to be none of the values
+	PolicyOperatorNotIn PolicyOperator = "NotIn"
+	// PolicyOperatorMinDuration requires the field, when set, to be a duration of at least the first value (e.g. `1m`)
+	PolicyOperatorMinDuration PolicyOperator = "MinDuration"
+)
@@ -51,6 +51,10 @@ var RepositoryResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
 				target = m.Spec.Bitbucket.URL
 			case GitLabRepositoryType:
 				target = m.Spec.GitLab.URL
+			case BucketRepositoryType:
+				if m.Spec.Bucket != nil {
+					target = m.Spec.Bucket.URL
+				}
 			}
 
 			return []interface{}{
@@ -165,6 +169,31 @@ var ConnectionResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
 		},
 	})
 
+var RepositoryPolicyResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
+	"repositorypolicies", "repositorypolicy", "RepositoryPolicy",
+	func() runtime.Object { return &RepositoryPolicy{} },     // newObj
+	func() runtime.Object { return &RepositoryPolicyList{} }, // newList
+	utils.TableColumns{ // Returned by `kubectl get`. Doesn't affect disk storage.
+		Definition: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Created At", Type: "date"},
+			{Name: "Enforcement", Type: "string"},
+			{Name: "Rules", Type: "number"},
+		},
+		Reader: func(obj any) ([]interface{}, error) {
+			m, ok := obj.(*RepositoryPolicy)
+			if !ok {
+				return nil, errors.New("expected RepositoryPolicy")
+			}
+			return []interface{}{
+				m.Name,
+				m.CreationTimestamp.UTC().Format(time.RFC3339),
+				m.Spec.Enforcement,
+				len(m.Spec.Rules),
+			}, nil
+		},
+	})
+
 var (
 	// SchemeGroupVersion is group version used to register these objects
 	SchemeGroupVersion   = schema.GroupVersion{Group: GROUP, Version: VERSION}
@@ -207,6 +236,8 @@ func AddKnownTypes(gv schema.GroupVersion, scheme *runtime.Scheme) error {
 		&Connection{},
 		&ConnectionList{},
 		&ExternalRepositoryList{},
+		&RepositoryPolicy{},
+		&RepositoryPolicyList{},
 	)
 	return nil
 }
@@ -0,0 +1,33 @@
+package v0alpha1
+
+// SourceDirectory marks a repository folder, and everything below it, as holding
+// Jsonnet or CUE files that each evaluate to a single resource.
+type SourceDirectory struct {
+	// Path of the folder in the repository (e.g. `dashboards/jsonnet`).
+	// An empty path marks the whole repository.
+	Path string `json:"path"`
+
+	// The language of the source files in the folder.
+	Format SourceFormat `json:"format"`
+
+	// Repository folders with shared code. Jsonnet imports are resolved relative to
+	// the importing file and then in each library. CUE files in a library are
+	// evaluated with every source file, like files of the same package.
+	// A change to a library file updates every resource generated in the folder.
+	Libraries []string `json:"libraries,omitempty"`
+}
+
+func (SourceDirectory) OpenAPIModelName() string {
+	return OpenAPIPrefix + "SourceDirectory"
+}
+
+// SourceFormat is the language of the files in a source directory.
+// +enum
+type SourceFormat string
+
+const (
+	// SourceFormatJsonnet evaluates `.jsonnet` files
+	SourceFormatJsonnet SourceFormat = "jsonnet"
+	// SourceFormatCUE evaluates `.cue` files
+	SourceFormatCUE SourceFormat = "cue"
+)
@@ -169,6 +169,22 @@ func (GitLabRepositoryConfig) OpenAPIModelName() string {
 	return OpenAPIPrefix + "GitLabRepositoryConfig"
 }
 
+// BucketRepositoryConfig describes a repository backed by an object storage bucket.
+type BucketRepositoryConfig struct {
+	// The bucket URL, using the same format as the unified storage blob buckets
+	// (e.g. `s3://my-bucket?region=us-east-1`, `gs://my-bucket` or `file:///var/lib/grafana/bucket`).
+	// Credentials are taken from the environment of the Grafana server.
+	URL string `json:"url,omitempty"`
+
+	// Path is the key prefix for the Grafana data inside the bucket.
+	// Objects outside this prefix are ignored. Trailing and leading slash are not required.
+	Path string `json:"path,omitempty"`
+}
+
+func (BucketRepositoryConfig) OpenAPIModelName() string {
+	return OpenAPIPrefix + "BucketRepositoryConfig"
+}
+
 // RepositoryType defines the types of Repository
 // +enum
 type RepositoryType string
@@ -189,6 +205,7 @@ const (
 	GitRepositoryType              RepositoryType = "git"
 	BitbucketRepositoryType        RepositoryType = "bitbucket"
 	GitLabRepositoryType           RepositoryType = "gitlab"
+	BucketRepositoryType           RepositoryType = "bucket"
 )
 
 // IsGit returns true if the repository type is git or github
@@ -326,6 +343,10 @@ func (r *Repository) Path() string {
 		if r.Spec.Local != nil {
 			return r.Spec.Local.Path
 		}
+	case BucketRepositoryType:
+		if r.Spec.Bucket != nil {
+			return r.Spec.Bucket.Path
+		}
 	default:
 		return ""
 	}
@@ -380,6 +401,37 @@ type CommitOptions struct {
 	// signingMethod is "smime". This is public (not a secret) and is embedded
 	// in the commit signature. Unused for the gpg and ssh formats.
 	SMIMECertificate string `json:"smimeCertificate,omitempty"`
+
+	// Verification of the commits pulled by sync jobs.
+	// When empty, incoming commits are not verified.
+	Verification *CommitVerification `json:"verification,omitempty"`
+}
+
+// CommitVerification configures the signatures required on incoming commits.
+type CommitVerification struct {
+	// When true, sync jobs reject changes from commits touching the synced path
+	// unless they carry a valid signature from one of the trusted keys.
+	// The gpg or ssh key in secure.commitSigningKey is always trusted, so the
+	// changes Grafana saves pass verification.
+	RequireSignedCommits bool `json:"requireSignedCommits,omitempty"`
+
+	// ASCII-armored OpenPGP public keys trusted to sign commits.
+	GPGKeys []string `json:"gpgKeys,omitempty"`
+
+	// SSH keys trusted to sign commits, in the git allowed signers format:
+	// "<principal> <key type> <base64 key> [comment]". The principal is
+	// recorded as the signer of the commit.
+	SSHAllowedSigners []string `json:"sshAllowedSigners,omitempty"`
+
+	// Hashes of commits accepted without a valid signature. A sync job stops at
+	// the first commit that fails verification and names it in its error; once
+	// the commit is reviewed, listing it here lets the sync go on. Files changed
+	// by an acknowledged commit are synced without a signer.
+	AcknowledgedCommits []string `json:"acknowledgedCommits,omitempty"`
+}
+
+func (CommitVerification) OpenAPIModelName() string {
+	return OpenAPIPrefix + "CommitVerification"
 }
 
 // SigningMethod selects how commits are signed.
@@ -486,9 +538,17 @@ type RepositorySpec struct {
 	// Mutually exclusive with local | github | git.
 	GitLab *GitLabRepositoryConfig `json:"gitlab,omitempty"`
 
+	// The repository in an object storage bucket.
+	// Mutually exclusive with local | github | git.
+	Bucket *BucketRepositoryConfig `json:"bucket,omitempty"`
+
 	// The connection the repository references.
 	// This means the Repository is interacting with git via a Connection.
 	Connection *ConnectionInfo `json:"connection,omitempty"`
+
+	// Folders holding Jsonnet or CUE source files. Source files outside of
+	// these folders are not evaluated.
+	Sources []SourceDirectory `json:"sources,omitempty"`
 }
 
 func (RepositorySpec) OpenAPIModelName() string {
@@ -537,12 +597,75 @@ type SyncOptions struct {
 	// The system defines a default value for this field, which will overwrite the
 	// user-defined one in case the latter is zero or lower than the system-defined one.
 	IntervalSeconds int64 `json:"intervalSeconds,omitempty"`
+
+	// Cron expression (five fields, or a descriptor such as @daily) for when sync runs.
+	// When set, it replaces the interval between sync runs.
+	Schedule string `json:"schedule,omitempty"`
+
+	// IANA time zone used by the schedule and the sync windows. Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Windows that restrict when sync can run.
+	// Sync only runs inside maintenance windows (when any are defined) and never inside blackout windows.
+	// +listType=atomic
+	Windows []SyncWindow `json:"windows,omitempty"`
+
+	// Maximum number of jobs for this repository that can run at the same time.
+	// When zero, the number of jobs is not limited.
+	MaxConcurrentJobs int64 `json:"maxConcurrentJobs,omitempty"`
+
+	// How a sync handles resources that changed in both the repository
+	// and Grafana since the last sync. When empty, conflicts are not detected and
+	// the repository version is applied.
+	ConflictStrategy ConflictStrategy `json:"conflictStrategy,omitempty"`
 }
 
 func (SyncOptions) OpenAPIModelName() string {
 	return OpenAPIPrefix + "SyncOptions"
 }
 
+// ConflictStrategy defines how a sync resolves a resource that changed both in the repository and in Grafana.
+// A resource changed in Grafana when its saved content no longer matches the file it was synced from.
+// +enum
+type ConflictStrategy string
+
+const (
+	// ConflictStrategyRepository applies the repository version and reports the conflict
+	ConflictStrategyRepository ConflictStrategy = "repository"
+	// ConflictStrategyGrafana keeps the Grafana version and reports the conflict
+	ConflictStrategyGrafana ConflictStrategy = "grafana"
+	// ConflictStrategyBranch keeps the Grafana version and writes it to a merge branch,
+	// so it can be reviewed and merged into the repository
+	ConflictStrategyBranch ConflictStrategy = "branch"
+)
+
+// SyncWindowType defines whether sync is allowed or paused during a window
+// +enum
+type SyncWindowType string
+
+const (
+	// SyncWindowTypeMaintenance only allows sync during the window
+	SyncWindowTypeMaintenance SyncWindowType = "maintenance"
+	// SyncWindowTypeBlackout pauses sync during the window
+	SyncWindowTypeBlackout SyncWindowType = "blackout"
+)
+
+// SyncWindow is a recurring period of time that restricts when sync can run
+type SyncWindow struct {
+	// The type of window
+	Type SyncWindowType `json:"type"`
+
+	// Cron expression for when the window starts
+	Start string `json:"start"`
+
+	// How long the window lasts, e.g. 2h or 30m
+	Duration string `json:"duration"`
+}
+
+func (SyncWindow) OpenAPIModelName() string {
+	return OpenAPIPrefix + "SyncWindow"
+}
+
 type WebhookConfig struct {
 	// Base URL of the Grafana instance used to construct the webhook endpoint
 	// registered with the external Git provider. Only the base URL should be
@@ -633,12 +756,50 @@ type SyncStatus struct {
 
 	// Incremental synchronization for versioned repositories
 	Incremental bool `json:"incremental,omitempty"`
+
+	// Conflicts found by the last sync, and the ones that kept the Grafana version until they are resolved
+	// +listType=atomic
+	Conflicts []ResourceConflict `json:"conflicts,omitempty"`
 }
 
 func (SyncStatus) OpenAPIModelName() string {
 	return OpenAPIPrefix + "SyncStatus"
 }
 
+// ResourceConflict describes a resource that changed in both the repository and Grafana
+type ResourceConflict struct {
+	// Path to the file in the repository
+	Path string `json:"path"`
+
+	// The resource name
+	Name string `json:"name,omitempty"`
+
+	// The resource group
+	Group string `json:"group,omitempty"`
+
+	// The resource kind
+	Kind string `json:"kind,omitempty"`
+
+	// The change made in the repository (update or delete)
+	Action ResourceAction `json:"action"`
+
+	// The file hash recorded on the resource when it was last synced
+	SyncedHash string `json:"syncedHash,omitempty"`
+
+	// The file hash at the previously synced ref
+	BaseHash string `json:"baseHash,omitempty"`
+
+	// How the conflict was resolved
+	Resolution ConflictStrategy `json:"resolution"`
+
+	// The branch the Grafana version was written to, when resolved with the branch strategy
+	Branch string `json:"branch,omitempty"`
+}
+
+func (ResourceConflict) OpenAPIModelName() string {
+	return OpenAPIPrefix + "ResourceConflict"
+}
+
 type WebhookStatus struct {
 	ID               int64    `json:"id,omitempty"`
 	URL              string   `json:"url,omitempty"`
@@ -76,9 +76,30 @@ func (in *BranchOptions) DeepCopy() *BranchOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketRepositoryConfig) DeepCopyInto(out *BucketRepositoryConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketRepositoryConfig.
+func (in *BucketRepositoryConfig) DeepCopy() *BucketRepositoryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketRepositoryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommitOptions) DeepCopyInto(out *CommitOptions) {
 	*out = *in
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(CommitVerification)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -92,6 +113,53 @@ func (in *CommitOptions) DeepCopy() *CommitOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommitVerification) DeepCopyInto(out *CommitVerification) {
+	*out = *in
+	if in.GPGKeys != nil {
+		in, out := &in.GPGKeys, &out.GPGKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SSHAllowedSigners != nil {
+		in, out := &in.SSHAllowedSigners, &out.SSHAllowedSigners
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AcknowledgedCommits != nil {
+		in, out := &in.AcknowledgedCommits, &out.AcknowledgedCommits
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommitVerification.
+func (in *CommitVerification) DeepCopy() *CommitVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(CommitVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConflictResolution) DeepCopyInto(out *ConflictResolution) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConflictResolution.
+func (in *ConflictResolution) DeepCopy() *ConflictResolution {
+	if in == nil {
+		return nil
+	}
+	out := new(ConflictResolution)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Connection) DeepCopyInto(out *Connection) {
 	*out = *in
@@ -743,6 +811,22 @@ func (in *JobList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobResourceChange) DeepCopyInto(out *JobResourceChange) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobResourceChange.
+func (in *JobResourceChange) DeepCopy() *JobResourceChange {
+	if in == nil {
+		return nil
+	}
+	out := new(JobResourceChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JobResourceSummary) DeepCopyInto(out *JobResourceSummary) {
 	*out = *in
@@ -785,7 +869,7 @@ func (in *JobSpec) DeepCopyInto(out *JobSpec) {
 	if in.Pull != nil {
 		in, out := &in.Pull, &out.Pull
 		*out = new(SyncJobOptions)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Migrate != nil {
 		in, out := &in.Migrate, &out.Migrate
@@ -807,6 +891,11 @@ func (in *JobSpec) DeepCopyInto(out *JobSpec) {
 		*out = new(FixFolderMetadataJobOptions)
 		**out = **in
 	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(RollbackJobOptions)
+		**out = **in
+	}
 	return
 }
 
@@ -849,6 +938,11 @@ func (in *JobStatus) DeepCopyInto(out *JobStatus) {
 		*out = new(RepositoryURLs)
 		**out = **in
 	}
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]JobResourceChange, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -946,6 +1040,65 @@ func (in *MoveJobOptions) DeepCopy() *MoveJobOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyCondition) DeepCopyInto(out *PolicyCondition) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyCondition.
+func (in *PolicyCondition) DeepCopy() *PolicyCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyRule) DeepCopyInto(out *PolicyRule) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]PolicyCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowedDatasourceUIDs != nil {
+		in, out := &in.AllowedDatasourceUIDs, &out.AllowedDatasourceUIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyRule.
+func (in *PolicyRule) DeepCopy() *PolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PullRequestJobOptions) DeepCopyInto(out *PullRequestJobOptions) {
 	*out = *in
@@ -1103,13 +1256,101 @@ func (in *RepositoryList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryPolicy) DeepCopyInto(out *RepositoryPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryPolicy.
+func (in *RepositoryPolicy) DeepCopy() *RepositoryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RepositoryPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryPolicyList) DeepCopyInto(out *RepositoryPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RepositoryPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryPolicyList.
+func (in *RepositoryPolicyList) DeepCopy() *RepositoryPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RepositoryPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryPolicySpec) DeepCopyInto(out *RepositoryPolicySpec) {
+	*out = *in
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryPolicySpec.
+func (in *RepositoryPolicySpec) DeepCopy() *RepositoryPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RepositorySpec) DeepCopyInto(out *RepositorySpec) {
 	*out = *in
 	if in.Commit != nil {
 		in, out := &in.Commit, &out.Commit
 		*out = new(CommitOptions)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Branch != nil {
 		in, out := &in.Branch, &out.Branch
@@ -1126,7 +1367,7 @@ func (in *RepositorySpec) DeepCopyInto(out *RepositorySpec) {
 		*out = make([]Workflow, len(*in))
 		copy(*out, *in)
 	}
-	out.Sync = in.Sync
+	in.Sync.DeepCopyInto(&out.Sync)
 	if in.Webhook != nil {
 		in, out := &in.Webhook, &out.Webhook
 		*out = new(WebhookConfig)
@@ -1162,11 +1403,23 @@ func (in *RepositorySpec) DeepCopyInto(out *RepositorySpec) {
 		*out = new(GitLabRepositoryConfig)
 		**out = **in
 	}
+	if in.Bucket != nil {
+		in, out := &in.Bucket, &out.Bucket
+		*out = new(BucketRepositoryConfig)
+		**out = **in
+	}
 	if in.Connection != nil {
 		in, out := &in.Connection, &out.Connection
 		*out = new(ConnectionInfo)
 		**out = **in
 	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]SourceDirectory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1251,7 +1504,7 @@ func (in *RepositoryView) DeepCopyInto(out *RepositoryView) {
 	if in.Commit != nil {
 		in, out := &in.Commit, &out.Commit
 		*out = new(CommitOptions)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.BranchOptions != nil {
 		in, out := &in.BranchOptions, &out.BranchOptions
@@ -1323,6 +1576,22 @@ func (in *RepositoryViewList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceConflict) DeepCopyInto(out *ResourceConflict) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceConflict.
+func (in *ResourceConflict) DeepCopy() *ResourceConflict {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceConflict)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceCount) DeepCopyInto(out *ResourceCount) {
 	*out = *in
@@ -1539,6 +1808,22 @@ func (in *ResourceWrapper) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackJobOptions) DeepCopyInto(out *RollbackJobOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackJobOptions.
+func (in *RollbackJobOptions) DeepCopy() *RollbackJobOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackJobOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecureValues) DeepCopyInto(out *SecureValues) {
 	*out = *in
@@ -1558,6 +1843,27 @@ func (in *SecureValues) DeepCopy() *SecureValues {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceDirectory) DeepCopyInto(out *SourceDirectory) {
+	*out = *in
+	if in.Libraries != nil {
+		in, out := &in.Libraries, &out.Libraries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceDirectory.
+func (in *SourceDirectory) DeepCopy() *SourceDirectory {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceDirectory)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SupportedResource) DeepCopyInto(out *SupportedResource) {
 	*out = *in
@@ -1577,6 +1883,11 @@ func (in *SupportedResource) DeepCopy() *SupportedResource {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncJobOptions) DeepCopyInto(out *SyncJobOptions) {
 	*out = *in
+	if in.Resolutions != nil {
+		in, out := &in.Resolutions, &out.Resolutions
+		*out = make([]ConflictResolution, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1593,6 +1904,11 @@ func (in *SyncJobOptions) DeepCopy() *SyncJobOptions {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncOptions) DeepCopyInto(out *SyncOptions) {
 	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]SyncWindow, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1614,6 +1930,11 @@ func (in *SyncStatus) DeepCopyInto(out *SyncStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]ResourceConflict, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1627,6 +1948,22 @@ func (in *SyncStatus) DeepCopy() *SyncStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncWindow) DeepCopyInto(out *SyncWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncWindow.
+func (in *SyncWindow) DeepCopy() *SyncWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TestResults) DeepCopyInto(out *TestResults) {
 	*out = *in
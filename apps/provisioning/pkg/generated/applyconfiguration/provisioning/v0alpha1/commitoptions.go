@@ -36,6 +36,9 @@ type CommitOptionsApplyConfiguration struct {
 	// signingMethod is "smime". This is public (not a secret) and is embedded
 	// in the commit signature. Unused for the gpg and ssh formats.
 	SMIMECertificate *string `json:"smimeCertificate,omitempty"`
+	// Verification of the commits pulled by sync jobs.
+	// When empty, incoming commits are not verified.
+	Verification *CommitVerificationApplyConfiguration `json:"verification,omitempty"`
 }
 
 // CommitOptionsApplyConfiguration constructs a declarative configuration of the CommitOptions type for use with
@@ -91,3 +94,11 @@ func (b *CommitOptionsApplyConfiguration) WithSMIMECertificate(value string) *Co
 	b.SMIMECertificate = &value
 	return b
 }
+
+// WithVerification sets the Verification field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Verification field is set to the value of the last call.
+func (b *CommitOptionsApplyConfiguration) WithVerification(value *CommitVerificationApplyConfiguration) *CommitOptionsApplyConfiguration {
+	b.Verification = value
+	return b
+}
@@ -35,6 +35,8 @@ type JobSpecApplyConfiguration struct {
 	Move *MoveJobOptionsApplyConfiguration `json:"move,omitempty"`
 	// Options when the action is `fix-folder-metadata`
 	FixFolderMetadata *FixFolderMetadataJobOptionsApplyConfiguration `json:"fixFolderMetadata,omitempty"`
+	// Required when the action is `rollback`
+	Rollback *RollbackJobOptionsApplyConfiguration `json:"rollback,omitempty"`
 }
 
 // JobSpecApplyConfiguration constructs a declarative configuration of the JobSpec type for use with
@@ -122,3 +124,11 @@ func (b *JobSpecApplyConfiguration) WithFixFolderMetadata(value *FixFolderMetada
 	b.FixFolderMetadata = value
 	return b
 }
+
+// WithRollback sets the Rollback field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Rollback field is set to the value of the last call.
+func (b *JobSpecApplyConfiguration) WithRollback(value *RollbackJobOptionsApplyConfiguration) *JobSpecApplyConfiguration {
+	b.Rollback = value
+	return b
+}
@@ -25,6 +25,9 @@ type JobStatusApplyConfiguration struct {
 	Summary []*provisioningv0alpha1.JobResourceSummary `json:"summary,omitempty"`
 	// URLs contains URLs for the reference branch or commit if applicable.
 	URLs *RepositoryURLsApplyConfiguration `json:"url,omitempty"`
+	// Changes made to resources by the job, used to roll it back.
+	// For a dry run rollback, the changes that would be made.
+	Changes []JobResourceChangeApplyConfiguration `json:"changes,omitempty"`
 }
 
 // JobStatusApplyConfiguration constructs a declarative configuration of the JobStatus type for use with
@@ -113,3 +116,16 @@ func (b *JobStatusApplyConfiguration) WithURLs(value *RepositoryURLsApplyConfigu
 	b.URLs = value
 	return b
 }
+
+// WithChanges adds the given value to the Changes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Changes field.
+func (b *JobStatusApplyConfiguration) WithChanges(values ...*JobResourceChangeApplyConfiguration) *JobStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithChanges")
+		}
+		b.Changes = append(b.Changes, *values[i])
+	}
+	return b
+}
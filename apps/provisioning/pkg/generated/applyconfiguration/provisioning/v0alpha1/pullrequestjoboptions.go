@@ -15,6 +15,8 @@ type PullRequestJobOptionsApplyConfiguration struct {
 	Hash *string `json:"hash,omitempty"`
 	// URL to the originator (eg, PR URL)
 	URL *string `json:"url,omitempty"`
+	// The pull request was closed or merged, so its preview is removed
+	Closed *bool `json:"closed,omitempty"`
 }
 
 // PullRequestJobOptionsApplyConfiguration constructs a declarative configuration of the PullRequestJobOptions type for use with
@@ -54,3 +56,11 @@ func (b *PullRequestJobOptionsApplyConfiguration) WithURL(value string) *PullReq
 	b.URL = &value
 	return b
 }
+
+// WithClosed sets the Closed field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Closed field is set to the value of the last call.
+func (b *PullRequestJobOptionsApplyConfiguration) WithClosed(value bool) *PullRequestJobOptionsApplyConfiguration {
+	b.Closed = &value
+	return b
+}
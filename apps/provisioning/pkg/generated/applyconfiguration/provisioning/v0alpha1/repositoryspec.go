@@ -52,9 +52,15 @@ type RepositorySpecApplyConfiguration struct {
 	// The repository on GitLab.
 	// Mutually exclusive with local | github | git.
 	GitLab *GitLabRepositoryConfigApplyConfiguration `json:"gitlab,omitempty"`
+	// The repository in an object storage bucket.
+	// Mutually exclusive with local | github | git.
+	Bucket *BucketRepositoryConfigApplyConfiguration `json:"bucket,omitempty"`
 	// The connection the repository references.
 	// This means the Repository is interacting with git via a Connection.
 	Connection *ConnectionInfoApplyConfiguration `json:"connection,omitempty"`
+	// Folders holding Jsonnet or CUE source files. Source files outside of
+	// these folders are not evaluated.
+	Sources []SourceDirectoryApplyConfiguration `json:"sources,omitempty"`
 }
 
 // RepositorySpecApplyConfiguration constructs a declarative configuration of the RepositorySpec type for use with
@@ -185,6 +191,14 @@ func (b *RepositorySpecApplyConfiguration) WithGitLab(value *GitLabRepositoryCon
 	return b
 }
 
+// WithBucket sets the Bucket field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Bucket field is set to the value of the last call.
+func (b *RepositorySpecApplyConfiguration) WithBucket(value *BucketRepositoryConfigApplyConfiguration) *RepositorySpecApplyConfiguration {
+	b.Bucket = value
+	return b
+}
+
 // WithConnection sets the Connection field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Connection field is set to the value of the last call.
@@ -192,3 +206,16 @@ func (b *RepositorySpecApplyConfiguration) WithConnection(value *ConnectionInfoA
 	b.Connection = value
 	return b
 }
+
+// WithSources adds the given value to the Sources field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Sources field.
+func (b *RepositorySpecApplyConfiguration) WithSources(values ...*SourceDirectoryApplyConfiguration) *RepositorySpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithSources")
+		}
+		b.Sources = append(b.Sources, *values[i])
+	}
+	return b
+}
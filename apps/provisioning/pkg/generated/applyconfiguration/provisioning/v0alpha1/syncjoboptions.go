@@ -9,6 +9,9 @@ package v0alpha1
 type SyncJobOptionsApplyConfiguration struct {
 	// Incremental synchronization for versioned repositories
 	Incremental *bool `json:"incremental,omitempty"`
+	// Resolutions for the conflicts listed in the sync status of the repository.
+	// A conflict the sync finds for the same path is resolved the same way.
+	Resolutions []ConflictResolutionApplyConfiguration `json:"resolutions,omitempty"`
 }
 
 // SyncJobOptionsApplyConfiguration constructs a declarative configuration of the SyncJobOptions type for use with
@@ -24,3 +27,16 @@ func (b *SyncJobOptionsApplyConfiguration) WithIncremental(value bool) *SyncJobO
 	b.Incremental = &value
 	return b
 }
+
+// WithResolutions adds the given value to the Resolutions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Resolutions field.
+func (b *SyncJobOptionsApplyConfiguration) WithResolutions(values ...*ConflictResolutionApplyConfiguration) *SyncJobOptionsApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithResolutions")
+		}
+		b.Resolutions = append(b.Resolutions, *values[i])
+	}
+	return b
+}
@@ -19,6 +19,21 @@ type SyncOptionsApplyConfiguration struct {
 	// The system defines a default value for this field, which will overwrite the
 	// user-defined one in case the latter is zero or lower than the system-defined one.
 	IntervalSeconds *int64 `json:"intervalSeconds,omitempty"`
+	// Cron expression (five fields, or a descriptor such as @daily) for when sync runs.
+	// When set, it replaces the interval between sync runs.
+	Schedule *string `json:"schedule,omitempty"`
+	// IANA time zone used by the schedule and the sync windows. Defaults to UTC.
+	TimeZone *string `json:"timeZone,omitempty"`
+	// Windows that restrict when sync can run.
+	// Sync only runs inside maintenance windows (when any are defined) and never inside blackout windows.
+	Windows []SyncWindowApplyConfiguration `json:"windows,omitempty"`
+	// Maximum number of jobs for this repository that can run at the same time.
+	// When zero, the number of jobs is not limited.
+	MaxConcurrentJobs *int64 `json:"maxConcurrentJobs,omitempty"`
+	// How a sync handles resources that changed in both the repository
+	// and Grafana since the last sync. When empty, conflicts are not detected and
+	// the repository version is applied.
+	ConflictStrategy *provisioningv0alpha1.ConflictStrategy `json:"conflictStrategy,omitempty"`
 }
 
 // SyncOptionsApplyConfiguration constructs a declarative configuration of the SyncOptions type for use with
@@ -50,3 +65,48 @@ func (b *SyncOptionsApplyConfiguration) WithIntervalSeconds(value int64) *SyncOp
 	b.IntervalSeconds = &value
 	return b
 }
+
+// WithSchedule sets the Schedule field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Schedule field is set to the value of the last call.
+func (b *SyncOptionsApplyConfiguration) WithSchedule(value string) *SyncOptionsApplyConfiguration {
+	b.Schedule = &value
+	return b
+}
+
+// WithTimeZone sets the TimeZone field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TimeZone field is set to the value of the last call.
+func (b *SyncOptionsApplyConfiguration) WithTimeZone(value string) *SyncOptionsApplyConfiguration {
+	b.TimeZone = &value
+	return b
+}
+
+// WithWindows adds the given value to the Windows field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Windows field.
+func (b *SyncOptionsApplyConfiguration) WithWindows(values ...*SyncWindowApplyConfiguration) *SyncOptionsApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithWindows")
+		}
+		b.Windows = append(b.Windows, *values[i])
+	}
+	return b
+}
+
+// WithMaxConcurrentJobs sets the MaxConcurrentJobs field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxConcurrentJobs field is set to the value of the last call.
+func (b *SyncOptionsApplyConfiguration) WithMaxConcurrentJobs(value int64) *SyncOptionsApplyConfiguration {
+	b.MaxConcurrentJobs = &value
+	return b
+}
+
+// WithConflictStrategy sets the ConflictStrategy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ConflictStrategy field is set to the value of the last call.
+func (b *SyncOptionsApplyConfiguration) WithConflictStrategy(value provisioningv0alpha1.ConflictStrategy) *SyncOptionsApplyConfiguration {
+	b.ConflictStrategy = &value
+	return b
+}
@@ -27,6 +27,8 @@ type SyncStatusApplyConfiguration struct {
 	LastRef *string `json:"lastRef,omitempty"`
 	// Incremental synchronization for versioned repositories
 	Incremental *bool `json:"incremental,omitempty"`
+	// Conflicts found by the last sync, and the ones that kept the Grafana version until they are resolved
+	Conflicts []ResourceConflictApplyConfiguration `json:"conflicts,omitempty"`
 }
 
 // SyncStatusApplyConfiguration constructs a declarative configuration of the SyncStatus type for use with
@@ -100,3 +102,16 @@ func (b *SyncStatusApplyConfiguration) WithIncremental(value bool) *SyncStatusAp
 	b.Incremental = &value
 	return b
 }
+
+// WithConflicts adds the given value to the Conflicts field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conflicts field.
+func (b *SyncStatusApplyConfiguration) WithConflicts(values ...*ResourceConflictApplyConfiguration) *SyncStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConflicts")
+		}
+		b.Conflicts = append(b.Conflicts, *values[i])
+	}
+	return b
+}
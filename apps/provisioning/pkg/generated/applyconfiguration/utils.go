@@ -24,8 +24,14 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &provisioningv0alpha1.BitbucketRepositoryConfigApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("BranchOptions"):
 		return &provisioningv0alpha1.BranchOptionsApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("BucketRepositoryConfig"):
+		return &provisioningv0alpha1.BucketRepositoryConfigApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("CommitOptions"):
 		return &provisioningv0alpha1.CommitOptionsApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("CommitVerification"):
+		return &provisioningv0alpha1.CommitVerificationApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("ConflictResolution"):
+		return &provisioningv0alpha1.ConflictResolutionApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("Connection"):
 		return &provisioningv0alpha1.ConnectionApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("ConnectionInfo"):
@@ -66,6 +72,8 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &provisioningv0alpha1.HistoricJobApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("Job"):
 		return &provisioningv0alpha1.JobApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("JobResourceChange"):
+		return &provisioningv0alpha1.JobResourceChangeApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("JobResourceSummary"):
 		return &provisioningv0alpha1.JobResourceSummaryApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("JobSpec"):
@@ -78,6 +86,10 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &provisioningv0alpha1.MigrateJobOptionsApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("MoveJobOptions"):
 		return &provisioningv0alpha1.MoveJobOptionsApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("PolicyCondition"):
+		return &provisioningv0alpha1.PolicyConditionApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("PolicyRule"):
+		return &provisioningv0alpha1.PolicyRuleApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("PullRequestJobOptions"):
 		return &provisioningv0alpha1.PullRequestJobOptionsApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("PullRequestOptions"):
@@ -86,24 +98,36 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &provisioningv0alpha1.QuotaStatusApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("Repository"):
 		return &provisioningv0alpha1.RepositoryApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("RepositoryPolicy"):
+		return &provisioningv0alpha1.RepositoryPolicyApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("RepositoryPolicySpec"):
+		return &provisioningv0alpha1.RepositoryPolicySpecApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("RepositorySpec"):
 		return &provisioningv0alpha1.RepositorySpecApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("RepositoryStatus"):
 		return &provisioningv0alpha1.RepositoryStatusApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("RepositoryURLs"):
 		return &provisioningv0alpha1.RepositoryURLsApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("ResourceConflict"):
+		return &provisioningv0alpha1.ResourceConflictApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("ResourceCount"):
 		return &provisioningv0alpha1.ResourceCountApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("ResourceRef"):
 		return &provisioningv0alpha1.ResourceRefApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("RollbackJobOptions"):
+		return &provisioningv0alpha1.RollbackJobOptionsApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("SecureValues"):
 		return &provisioningv0alpha1.SecureValuesApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("SourceDirectory"):
+		return &provisioningv0alpha1.SourceDirectoryApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("SyncJobOptions"):
 		return &provisioningv0alpha1.SyncJobOptionsApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("SyncOptions"):
 		return &provisioningv0alpha1.SyncOptionsApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("SyncStatus"):
 		return &provisioningv0alpha1.SyncStatusApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("SyncWindow"):
+		return &provisioningv0alpha1.SyncWindowApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("TokenStatus"):
 		return &provisioningv0alpha1.TokenStatusApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("WebhookConfig"):
@@ -30,6 +30,10 @@ func (c *FakeProvisioningV0alpha1) Repositories(namespace string) v0alpha1.Repos
 	return newFakeRepositories(c, namespace)
 }
 
+func (c *FakeProvisioningV0alpha1) RepositoryPolicies(namespace string) v0alpha1.RepositoryPolicyInterface {
+	return newFakeRepositoryPolicies(c, namespace)
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeProvisioningV0alpha1) RESTClient() rest.Interface {
@@ -11,3 +11,5 @@ type HistoricJobExpansion interface{}
 type JobExpansion interface{}
 
 type RepositoryExpansion interface{}
+
+type RepositoryPolicyExpansion interface{}
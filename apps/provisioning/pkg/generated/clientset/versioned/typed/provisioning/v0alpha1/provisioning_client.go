@@ -18,6 +18,7 @@ type ProvisioningV0alpha1Interface interface {
 	HistoricJobsGetter
 	JobsGetter
 	RepositoriesGetter
+	RepositoryPoliciesGetter
 }
 
 // ProvisioningV0alpha1Client is used to interact with features provided by the provisioning.grafana.app group.
@@ -41,6 +42,10 @@ func (c *ProvisioningV0alpha1Client) Repositories(namespace string) RepositoryIn
 	return newRepositories(c, namespace)
 }
 
+func (c *ProvisioningV0alpha1Client) RepositoryPolicies(namespace string) RepositoryPolicyInterface {
+	return newRepositoryPolicies(c, namespace)
+}
+
 // NewForConfig creates a new ProvisioningV0alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
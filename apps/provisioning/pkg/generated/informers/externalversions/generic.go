@@ -47,6 +47,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Provisioning().V0alpha1().Jobs().Informer()}, nil
 	case v0alpha1.SchemeGroupVersion.WithResource("repositories"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Provisioning().V0alpha1().Repositories().Informer()}, nil
+	case v0alpha1.SchemeGroupVersion.WithResource("repositorypolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Provisioning().V0alpha1().RepositoryPolicies().Informer()}, nil
 
 	}
 
@@ -18,6 +18,8 @@ type Interface interface {
 	Jobs() JobInformer
 	// Repositories returns a RepositoryInformer.
 	Repositories() RepositoryInformer
+	// RepositoryPolicies returns a RepositoryPolicyInformer.
+	RepositoryPolicies() RepositoryPolicyInformer
 }
 
 type version struct {
@@ -50,3 +52,8 @@ func (v *version) Jobs() JobInformer {
 func (v *version) Repositories() RepositoryInformer {
 	return &repositoryInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// RepositoryPolicies returns a RepositoryPolicyInformer.
+func (v *version) RepositoryPolicies() RepositoryPolicyInformer {
+	return &repositoryPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -38,8 +38,9 @@ func ValidateJob(job *provisioning.Job, supportedResources []provisioning.Suppor
 	case provisioning.JobActionPull:
 		if job.Spec.Pull == nil {
 			list = append(list, field.Required(field.NewPath("spec", "pull"), "pull options required for pull action"))
+		} else {
+			list = append(list, validateSyncJobOptions(job.Spec.Pull)...)
 		}
-		// Pull options are simple, just incremental bool - no further validation needed
 
 	case provisioning.JobActionPush:
 		if job.Spec.Push == nil {
@@ -78,6 +79,13 @@ func ValidateJob(job *provisioning.Job, supportedResources []provisioning.Suppor
 	case provisioning.JobActionFixFolderMetadata:
 		// No required options for fix-folder-metadata; it's a no-op placeholder
 
+	case provisioning.JobActionRollback:
+		if job.Spec.Rollback == nil {
+			list = append(list, field.Required(field.NewPath("spec", "rollback"), "rollback options required for rollback action"))
+		} else if job.Spec.Rollback.Job == "" {
+			list = append(list, field.Required(field.NewPath("spec", "rollback", "job"), "job to roll back must be specified"))
+		}
+
 	case provisioning.JobActionReleaseResources,
 		provisioning.JobActionDeleteResources:
 		// No additional options required; validation is handled by the jobs connector
@@ -216,6 +224,36 @@ func supportedKinds(supported []provisioning.SupportedResource) []string {
 	return kinds
 }
 
+// validateSyncJobOptions validates the conflict resolutions of a pull job
+func validateSyncJobOptions(opts *provisioning.SyncJobOptions) field.ErrorList {
+	list := field.ErrorList{}
+
+	paths := make(map[string]struct{}, len(opts.Resolutions))
+	for i, r := range opts.Resolutions {
+		base := field.NewPath("spec", "pull", "resolutions").Index(i)
+		if r.Path == "" {
+			list = append(list, field.Required(base.Child("path"), "path is required"))
+		} else if err := safepath.IsSafe(r.Path); err != nil {
+			list = append(list, field.Invalid(base.Child("path"), r.Path, err.Error()))
+		} else if _, ok := paths[r.Path]; ok {
+			list = append(list, field.Duplicate(base.Child("path"), r.Path))
+		}
+		paths[r.Path] = struct{}{}
+
+		switch r.Resolution {
+		case provisioning.ConflictStrategyRepository, provisioning.ConflictStrategyGrafana, provisioning.ConflictStrategyBranch:
+		default:
+			list = append(list, field.NotSupported(base.Child("resolution"), r.Resolution, []string{
+				string(provisioning.ConflictStrategyRepository),
+				string(provisioning.ConflictStrategyGrafana),
+				string(provisioning.ConflictStrategyBranch),
+			}))
+		}
+	}
+
+	return list
+}
+
 // validateDeleteJobOptions validates delete job options
 func validateDeleteJobOptions(opts *provisioning.DeleteJobOptions) field.ErrorList {
 	list := field.ErrorList{}
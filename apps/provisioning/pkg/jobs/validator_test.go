@@ -104,6 +104,30 @@ func TestValidateJob(t *testing.T) {
 				require.Contains(t, err.Error(), "spec.pull: Required value")
 			},
 		},
+		{
+			name: "pull action with invalid conflict resolutions",
+			job: &provisioning.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-job",
+				},
+				Spec: provisioning.JobSpec{
+					Action:     provisioning.JobActionPull,
+					Repository: "test-repo",
+					Pull: &provisioning.SyncJobOptions{
+						Resolutions: []provisioning.ConflictResolution{
+							{Path: "dashboards/home.json", Resolution: provisioning.ConflictStrategyRepository},
+							{Path: "dashboards/home.json", Resolution: provisioning.ConflictStrategyGrafana},
+							{Path: "dashboards/other.json", Resolution: "merge"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			validateError: func(t *testing.T, err error) {
+				require.Contains(t, err.Error(), "spec.pull.resolutions[1].path: Duplicate value")
+				require.Contains(t, err.Error(), "spec.pull.resolutions[2].resolution: Unsupported value")
+			},
+		},
 		{
 			name: "push action without push options",
 			job: &provisioning.Job{
@@ -904,6 +928,47 @@ func TestValidateJob(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid rollback job",
+			job: &provisioning.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-job"},
+				Spec: provisioning.JobSpec{
+					Action:     provisioning.JobActionRollback,
+					Repository: "test-repo",
+					Rollback:   &provisioning.RollbackJobOptions{Job: "a6d3bd3a-2b4f-4b7e-9f0e-1c2d3e4f5a6b", DryRun: true},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "rollback action without options",
+			job: &provisioning.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-job"},
+				Spec: provisioning.JobSpec{
+					Action:     provisioning.JobActionRollback,
+					Repository: "test-repo",
+				},
+			},
+			wantErr: true,
+			validateError: func(t *testing.T, err error) {
+				require.Contains(t, err.Error(), "spec.rollback: Required value")
+			},
+		},
+		{
+			name: "rollback action without job",
+			job: &provisioning.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-job"},
+				Spec: provisioning.JobSpec{
+					Action:     provisioning.JobActionRollback,
+					Repository: "test-repo",
+					Rollback:   &provisioning.RollbackJobOptions{},
+				},
+			},
+			wantErr: true,
+			validateError: func(t *testing.T, err error) {
+				require.Contains(t, err.Error(), "spec.rollback.job: Required value")
+			},
+		},
 		{
 			name: "push action at the selective export limit",
 			job: &provisioning.Job{
@@ -0,0 +1,59 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	client "github.com/grafana/grafana/apps/provisioning/pkg/generated/clientset/versioned/typed/provisioning/v0alpha1"
+)
+
+// Lister finds the policies that apply to a repository
+type Lister interface {
+	ForRepository(ctx context.Context, repo *provisioning.Repository) ([]provisioning.RepositoryPolicy, error)
+}
+
+// ClientGetter returns the client used to read the policies.
+// It is called on every lookup, as API servers only create their client once started.
+type ClientGetter func(ctx context.Context) (client.ProvisioningV0alpha1Interface, error)
+
+type clientLister struct {
+	getClient ClientGetter
+}
+
+// NewLister returns a Lister reading the policies from the namespace of the repository
+func NewLister(getClient ClientGetter) Lister {
+	return &clientLister{getClient: getClient}
+}
+
+func (l *clientLister) ForRepository(ctx context.Context, repo *provisioning.Repository) ([]provisioning.RepositoryPolicy, error) {
+	c, err := l.getClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get provisioning client: %w", err)
+	}
+	if c == nil {
+		return nil, errors.New("provisioning client is not ready")
+	}
+
+	list, err := c.RepositoryPolicies(repo.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list repository policies: %w", err)
+	}
+
+	policies := make([]provisioning.RepositoryPolicy, 0, len(list.Items))
+	for _, p := range list.Items {
+		if p.AppliesTo(repo.Name) {
+			policies = append(policies, p)
+		}
+	}
+	// Report violations in a stable order
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+
+	return policies, nil
+}
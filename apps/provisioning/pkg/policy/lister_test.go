@@ -0,0 +1,47 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	provisioningfake "github.com/grafana/grafana/apps/provisioning/pkg/generated/clientset/versioned/fake"
+	typedclient "github.com/grafana/grafana/apps/provisioning/pkg/generated/clientset/versioned/typed/provisioning/v0alpha1"
+)
+
+func TestLister_ForRepository(t *testing.T) {
+	client := provisioningfake.NewSimpleClientset(
+		&provisioning.RepositoryPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "everywhere", Namespace: "default"},
+		},
+		&provisioning.RepositoryPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod-only", Namespace: "default"},
+			Spec:       provisioning.RepositoryPolicySpec{Repositories: []string{"prod"}},
+		},
+		&provisioning.RepositoryPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "other"},
+		},
+	)
+	lister := NewLister(func(context.Context) (typedclient.ProvisioningV0alpha1Interface, error) {
+		return client.ProvisioningV0alpha1(), nil
+	})
+
+	names := func(repo string) []string {
+		policies, err := lister.ForRepository(context.Background(), &provisioning.Repository{
+			ObjectMeta: metav1.ObjectMeta{Name: repo, Namespace: "default"},
+		})
+		require.NoError(t, err)
+
+		out := make([]string, 0, len(policies))
+		for _, p := range policies {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+
+	require.Equal(t, []string{"everywhere", "prod-only"}, names("prod"))
+	require.Equal(t, []string{"everywhere"}, names("dev"))
+}
@@ -0,0 +1,278 @@
+// Package policy checks resources read from a repository against the
+// declarative rules of the repository policies in its namespace.
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+)
+
+// builtinDatasourceUIDs are always allowed, as they do not reference a configured datasource
+var builtinDatasourceUIDs = []string{"grafana", "-- Grafana --", "-- Mixed --", "-- Dashboard --"}
+
+// Violation is a rule that a resource does not satisfy
+type Violation struct {
+	Policy      string
+	Rule        string
+	Enforcement provisioning.PolicyEnforcement
+	Message     string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+// Blocking returns true if the violation stops the resource from being applied
+func (v Violation) Blocking() bool {
+	return v.Enforcement == provisioning.PolicyEnforcementFail
+}
+
+// Blocking returns true if any of the violations must stop the resource from being applied
+func Blocking(violations []Violation) bool {
+	return slices.ContainsFunc(violations, Violation.Blocking)
+}
+
+// Evaluate checks the resource read from filePath against every matching rule of the policies.
+func Evaluate(policies []provisioning.RepositoryPolicy, filePath string, obj *unstructured.Unstructured) []Violation {
+	if obj == nil {
+		return nil
+	}
+
+	var violations []Violation
+	for _, policy := range policies {
+		for _, rule := range policy.Spec.Rules {
+			if !matches(rule, filePath, obj) {
+				continue
+			}
+
+			enforcement := rule.Enforcement
+			if enforcement == "" {
+				enforcement = policy.Spec.Enforcement
+			}
+			if enforcement == "" {
+				enforcement = provisioning.PolicyEnforcementFail
+			}
+
+			for _, msg := range check(rule, obj) {
+				violations = append(violations, Violation{
+					Policy:      policy.Name,
+					Rule:        rule.Name,
+					Enforcement: enforcement,
+					Message:     msg,
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+func matches(rule provisioning.PolicyRule, filePath string, obj *unstructured.Unstructured) bool {
+	if len(rule.Kinds) > 0 && !slices.ContainsFunc(rule.Kinds, func(k string) bool {
+		return strings.EqualFold(k, obj.GetKind())
+	}) {
+		return false
+	}
+
+	if len(rule.Paths) == 0 {
+		return true
+	}
+
+	filePath = safepath.Clean(filePath)
+	for _, p := range rule.Paths {
+		dir := safepath.EnsureTrailingSlash(safepath.Clean(p))
+		if dir == "" || safepath.InDir(filePath, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns a message for each part of the rule the resource does not satisfy
+func check(rule provisioning.PolicyRule, obj *unstructured.Unstructured) []string {
+	var msgs []string
+	for _, c := range rule.Conditions {
+		if msg := checkCondition(c, obj); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	if len(rule.AllowedDatasourceUIDs) > 0 {
+		for _, uid := range datasourceUIDs(obj.Object["spec"]) {
+			if !slices.Contains(rule.AllowedDatasourceUIDs, uid) {
+				msgs = append(msgs, fmt.Sprintf("datasource %q is not allowed", uid))
+			}
+		}
+	}
+
+	return msgs
+}
+
+func checkCondition(c provisioning.PolicyCondition, obj *unstructured.Unstructured) string {
+	value, found, err := unstructured.NestedFieldNoCopy(obj.Object, strings.Split(c.Field, ".")...)
+	if err != nil {
+		// An intermediate field is not an object, so the field can not be set
+		found = false
+	}
+	if found && isEmpty(value) {
+		found = false
+	}
+
+	switch c.Operator {
+	case provisioning.PolicyOperatorExists:
+		if !found {
+			return fmt.Sprintf("%s must be set", c.Field)
+		}
+	case provisioning.PolicyOperatorIn:
+		if !found {
+			return fmt.Sprintf("%s must be one of [%s]", c.Field, strings.Join(c.Values, ", "))
+		}
+		for _, v := range stringValues(value) {
+			if !slices.Contains(c.Values, v) {
+				return fmt.Sprintf("%s must be one of [%s], found %q", c.Field, strings.Join(c.Values, ", "), v)
+			}
+		}
+	case provisioning.PolicyOperatorNotIn:
+		if !found {
+			return ""
+		}
+		for _, v := range stringValues(value) {
+			if slices.Contains(c.Values, v) {
+				return fmt.Sprintf("%s must not be %q", c.Field, v)
+			}
+		}
+	case provisioning.PolicyOperatorMinDuration:
+		if !found || len(c.Values) == 0 {
+			return ""
+		}
+		minimum, err := ParseDuration(c.Values[0])
+		if err != nil {
+			return fmt.Sprintf("invalid minimum duration %q", c.Values[0])
+		}
+		s, ok := value.(string)
+		if !ok {
+			// Not a duration, such as a dashboard `refresh: false` which turns refreshing off
+			return ""
+		}
+		d, err := ParseDuration(s)
+		if err != nil {
+			return fmt.Sprintf("%s must be a duration, found %v", c.Field, value)
+		}
+		if d < minimum {
+			return fmt.Sprintf("%s must be at least %s, found %s", c.Field, c.Values[0], s)
+		}
+	default:
+		return fmt.Sprintf("unsupported operator %q", c.Operator)
+	}
+
+	return ""
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// stringValues returns the items of a list, or the value itself, as strings
+func stringValues(value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		items = []any{value}
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			values = append(values, v)
+		case bool:
+			values = append(values, strconv.FormatBool(v))
+		default:
+			values = append(values, fmt.Sprint(v))
+		}
+	}
+	return values
+}
+
+// datasourceUIDs returns the sorted UIDs of the datasources referenced anywhere in the value.
+// References use `{"uid": "..."}`, or `{"name": "..."}` in v2 dashboards. Older dashboards
+// reference the datasource with a string, its UID or its name, which the allow list is
+// matched against as it is.
+func datasourceUIDs(value any) []string {
+	seen := map[string]struct{}{}
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, child := range v {
+				if key == "datasource" {
+					if uid := datasourceRef(child); uid != "" && !strings.HasPrefix(uid, "$") && !slices.Contains(builtinDatasourceUIDs, uid) {
+						seen[uid] = struct{}{}
+					}
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(value)
+
+	uids := make([]string, 0, len(seen))
+	for uid := range seen {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	return uids
+}
+
+// datasourceRef returns the UID, or name, of a datasource reference
+func datasourceRef(ref any) string {
+	switch ref := ref.(type) {
+	case string:
+		return ref
+	case map[string]any:
+		if uid, _ := ref["uid"].(string); uid != "" {
+			return uid
+		}
+		name, _ := ref["name"].(string)
+		return name
+	}
+	return ""
+}
+
+// ParseDuration parses a Go duration, with the additional `d` (day) and `w` (week)
+// units used by dashboard refresh intervals.
+func ParseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,208 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+)
+
+func dashboard(spec map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind":       "Dashboard",
+		"metadata":   map[string]any{"name": "test"},
+		"spec":       spec,
+	}}
+}
+
+func TestEvaluate(t *testing.T) {
+	prod := provisioning.RepositoryPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Spec: provisioning.RepositoryPolicySpec{Rules: []provisioning.PolicyRule{
+			{
+				Name:  "prod-dashboards",
+				Kinds: []string{"Dashboard"},
+				Paths: []string{"prod"},
+				Conditions: []provisioning.PolicyCondition{
+					{Field: "spec.tags", Operator: provisioning.PolicyOperatorExists},
+					{Field: "spec.refresh", Operator: provisioning.PolicyOperatorMinDuration, Values: []string{"1m"}},
+				},
+			},
+			{
+				Name:                  "datasources",
+				Enforcement:           provisioning.PolicyEnforcementWarn,
+				AllowedDatasourceUIDs: []string{"prom"},
+			},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		policies []provisioning.RepositoryPolicy
+		path     string
+		obj      *unstructured.Unstructured
+		expected []string
+		blocking bool
+	}{
+		{
+			name: "no policy",
+			path: "prod/a.json",
+			obj:  dashboard(map[string]any{}),
+		},
+		{
+			name:     "compliant",
+			policies: []provisioning.RepositoryPolicy{prod},
+			path:     "prod/a.json",
+			obj: dashboard(map[string]any{
+				"tags":    []any{"team-a"},
+				"refresh": "5m",
+				"panels": []any{
+					map[string]any{"datasource": map[string]any{"type": "prometheus", "uid": "prom"}},
+					map[string]any{"datasource": map[string]any{"uid": "${ds}"}},
+					map[string]any{"datasource": map[string]any{"uid": "-- Mixed --"}},
+				},
+			}),
+		},
+		{
+			name:     "empty refresh is allowed",
+			policies: []provisioning.RepositoryPolicy{prod},
+			path:     "prod/nested/a.json",
+			obj:      dashboard(map[string]any{"tags": []any{"team-a"}, "refresh": ""}),
+		},
+		{
+			name:     "refresh turned off",
+			policies: []provisioning.RepositoryPolicy{prod},
+			path:     "prod/a.json",
+			obj:      dashboard(map[string]any{"tags": []any{"team-a"}, "refresh": false}),
+		},
+		{
+			name:     "outside of the rule paths",
+			policies: []provisioning.RepositoryPolicy{prod},
+			path:     "production/a.json",
+			obj:      dashboard(map[string]any{}),
+		},
+		{
+			name:     "missing tags and fast refresh",
+			policy:   prod,
+			path:     "prod/a.json",
+			obj:      dashboard(map[string]any{"tags": []any{}, "refresh": "10s"}),
+			expected: []string{"prod-dashboards: spec.tags must be set", "prod-dashboards: spec.refresh must be at least 1m, found 10s"},
+			blocking: true,
+		},
+		{
+			name:     "datasource outside of the allow list",
+			policies: []provisioning.RepositoryPolicy{prod},
+			path:     "dev/a.json",
+			obj: dashboard(map[string]any{
+				"elements": map[string]any{
+					"panel-1": map[string]any{"spec": map[string]any{"datasource": map[string]any{"name": "loki"}}},
+				},
+			}),
+			expected: []string{`datasources: datasource "loki" is not allowed`},
+			blocking: false,
+		},
+		{
+			name:     "datasource referenced by a string",
+			policies: []provisioning.RepositoryPolicy{prod},
+			path:     "dev/a.json",
+			obj: dashboard(map[string]any{
+				"panels": []any{
+					map[string]any{"datasource": "prom"},
+					map[string]any{"datasource": "Loki"},
+					map[string]any{"datasource": "$ds"},
+					map[string]any{"datasource": "-- Grafana --"},
+					map[string]any{"datasource": nil},
+				},
+			}),
+			expected: []string{`datasources: datasource "Loki" is not allowed`},
+			blocking: false,
+		},
+		{
+			name: "in and not in",
+			policies: []provisioning.RepositoryPolicy{{
+				Spec: provisioning.RepositoryPolicySpec{
+					Enforcement: provisioning.PolicyEnforcementWarn,
+					Rules: []provisioning.PolicyRule{{
+						Name: "labels",
+						Conditions: []provisioning.PolicyCondition{
+							{Field: "metadata.labels.team", Operator: provisioning.PolicyOperatorIn, Values: []string{"a", "b"}},
+							{Field: "spec.tags", Operator: provisioning.PolicyOperatorNotIn, Values: []string{"wip"}},
+						},
+					}},
+				},
+			}},
+			path: "a.json",
+			obj: &unstructured.Unstructured{Object: map[string]any{
+				"kind":     "Dashboard",
+				"metadata": map[string]any{"name": "test", "labels": map[string]any{"team": "c"}},
+				"spec":     map[string]any{"tags": []any{"ok", "wip"}},
+			}},
+			expected: []string{`labels: metadata.labels.team must be one of [a, b], found "c"`, `labels: spec.tags must not be "wip"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := Evaluate(tt.policies, tt.path, tt.obj)
+			msgs := make([]string, 0, len(violations))
+			for _, v := range violations {
+				msgs = append(msgs, v.String())
+			}
+			if len(tt.expected) == 0 {
+				require.Empty(t, msgs)
+			} else {
+				require.Equal(t, tt.expected, msgs)
+			}
+			require.Equal(t, tt.blocking, Blocking(violations))
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	d, err := ParseDuration("1d")
+	require.NoError(t, err)
+	require.Equal(t, 24*time.Hour, d)
+
+	d, err = ParseDuration("30s")
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, d)
+
+	_, err = ParseDuration("xd")
+	require.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	require.Empty(t, Validate(nil, field.NewPath("spec")))
+
+	list := Validate(&provisioning.RepositoryPolicySpec{
+		Repositories: []string{"a", ""},
+		Enforcement:  "block",
+		Rules: []provisioning.PolicyRule{
+			{Name: "a", Paths: []string{"../prod"}, AllowedDatasourceUIDs: []string{"prom"}},
+			{Name: "a", Conditions: []provisioning.PolicyCondition{{Field: "spec.tags", Operator: "Matches"}}},
+			{Conditions: []provisioning.PolicyCondition{{Field: "spec.tags", Operator: provisioning.PolicyOperatorIn}}},
+			{Name: "empty"},
+		},
+	}, field.NewPath("spec"))
+
+	fields := make([]string, 0, len(list))
+	for _, e := range list {
+		fields = append(fields, e.Field)
+	}
+	require.Equal(t, []string{
+		"spec.repositories[1]",
+		"spec.enforcement",
+		"spec.rules[0].paths[0]",
+		"spec.rules[1].name",
+		"spec.rules[1].conditions[0].operator",
+		"spec.rules[2].name",
+		"spec.rules[2].conditions[0].values",
+		"spec.rules[3]",
+	}, fields)
+}
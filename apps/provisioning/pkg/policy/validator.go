@@ -0,0 +1,139 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/admission"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+)
+
+var (
+	enforcements = []string{string(provisioning.PolicyEnforcementFail), string(provisioning.PolicyEnforcementWarn)}
+	operators    = []string{
+		string(provisioning.PolicyOperatorExists),
+		string(provisioning.PolicyOperatorIn),
+		string(provisioning.PolicyOperatorNotIn),
+		string(provisioning.PolicyOperatorMinDuration),
+	}
+)
+
+// AdmissionValidator handles validation for RepositoryPolicy resources during admission
+type AdmissionValidator struct{}
+
+// NewAdmissionValidator creates a new repository policy admission validator
+func NewAdmissionValidator() *AdmissionValidator {
+	return &AdmissionValidator{}
+}
+
+// Validate rejects policies that can not be evaluated
+func (v *AdmissionValidator) Validate(ctx context.Context, a admission.Attributes, o admission.ObjectInterfaces) error {
+	obj := a.GetObject()
+	if obj == nil {
+		return nil
+	}
+
+	// Do not validate objects we are trying to delete
+	meta, _ := utils.MetaAccessor(obj)
+	if meta.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	policy, ok := obj.(*provisioning.RepositoryPolicy)
+	if !ok {
+		return fmt.Errorf("expected repository policy, got %T", obj)
+	}
+
+	list := Validate(&policy.Spec, field.NewPath("spec"))
+	if len(list) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(
+		provisioning.RepositoryPolicyResourceInfo.GroupVersionKind().GroupKind(),
+		policy.Name, list)
+}
+
+// Validate checks that the policy can be evaluated
+func Validate(policy *provisioning.RepositoryPolicySpec, path *field.Path) field.ErrorList {
+	if policy == nil {
+		return nil
+	}
+
+	var list field.ErrorList
+	for i, name := range policy.Repositories {
+		if name == "" {
+			list = append(list, field.Required(path.Child("repositories").Index(i), "a repository name is required"))
+		}
+	}
+
+	list = append(list, validateEnforcement(policy.Enforcement, path.Child("enforcement"))...)
+
+	names := make(map[string]struct{}, len(policy.Rules))
+	for i, rule := range policy.Rules {
+		rulePath := path.Child("rules").Index(i)
+		if rule.Name == "" {
+			list = append(list, field.Required(rulePath.Child("name"), "a rule name is required"))
+		} else if _, ok := names[rule.Name]; ok {
+			list = append(list, field.Duplicate(rulePath.Child("name"), rule.Name))
+		}
+		names[rule.Name] = struct{}{}
+
+		list = append(list, validateEnforcement(rule.Enforcement, rulePath.Child("enforcement"))...)
+
+		for j, p := range rule.Paths {
+			if err := safepath.IsSafe(p); err != nil {
+				list = append(list, field.Invalid(rulePath.Child("paths").Index(j), p, err.Error()))
+			}
+		}
+
+		if len(rule.Conditions) == 0 && len(rule.AllowedDatasourceUIDs) == 0 {
+			list = append(list, field.Required(rulePath, "a rule must have conditions or allowed datasources"))
+		}
+
+		for j, c := range rule.Conditions {
+			list = append(list, validateCondition(c, rulePath.Child("conditions").Index(j))...)
+		}
+	}
+
+	return list
+}
+
+func validateEnforcement(enforcement provisioning.PolicyEnforcement, path *field.Path) field.ErrorList {
+	switch enforcement {
+	case "", provisioning.PolicyEnforcementFail, provisioning.PolicyEnforcementWarn:
+		return nil
+	default:
+		return field.ErrorList{field.NotSupported(path, enforcement, enforcements)}
+	}
+}
+
+func validateCondition(c provisioning.PolicyCondition, path *field.Path) field.ErrorList {
+	var list field.ErrorList
+	if c.Field == "" {
+		list = append(list, field.Required(path.Child("field"), "a field is required"))
+	}
+
+	switch c.Operator {
+	case provisioning.PolicyOperatorExists:
+	case provisioning.PolicyOperatorIn, provisioning.PolicyOperatorNotIn:
+		if len(c.Values) == 0 {
+			list = append(list, field.Required(path.Child("values"), "at least one value is required"))
+		}
+	case provisioning.PolicyOperatorMinDuration:
+		if len(c.Values) != 1 {
+			list = append(list, field.Invalid(path.Child("values"), c.Values, "a single duration is required"))
+		} else if _, err := ParseDuration(c.Values[0]); err != nil {
+			list = append(list, field.Invalid(path.Child("values").Index(0), c.Values[0], err.Error()))
+		}
+	default:
+		list = append(list, field.NotSupported(path.Child("operator"), c.Operator, operators))
+	}
+
+	return list
+}
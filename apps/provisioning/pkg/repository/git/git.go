@@ -13,6 +13,7 @@ type GitRepository interface {
 	repository.SizeLimitedReader
 	repository.StageableRepository
 	repository.BranchHandler
+	repository.CommitVerifier
 	URL() string
 	Branch() string
 }
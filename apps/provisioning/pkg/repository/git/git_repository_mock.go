@@ -964,6 +964,66 @@ func (_c *MockGitRepository_Update_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// VerifyCommits provides a mock function with given fields: ctx, base, ref
+func (_m *MockGitRepository) VerifyCommits(ctx context.Context, base string, ref string) (map[string]string, error) {
+	ret := _m.Called(ctx, base, ref)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyCommits")
+	}
+
+	var r0 map[string]string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (map[string]string, error)); ok {
+		return rf(ctx, base, ref)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) map[string]string); ok {
+		r0 = rf(ctx, base, ref)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, base, ref)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitRepository_VerifyCommits_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyCommits'
+type MockGitRepository_VerifyCommits_Call struct {
+	*mock.Call
+}
+
+// VerifyCommits is a helper method to define mock.On call
+//   - ctx context.Context
+//   - base string
+//   - ref string
+func (_e *MockGitRepository_Expecter) VerifyCommits(ctx interface{}, base interface{}, ref interface{}) *MockGitRepository_VerifyCommits_Call {
+	return &MockGitRepository_VerifyCommits_Call{Call: _e.mock.On("VerifyCommits", ctx, base, ref)}
+}
+
+func (_c *MockGitRepository_VerifyCommits_Call) Run(run func(ctx context.Context, base string, ref string)) *MockGitRepository_VerifyCommits_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockGitRepository_VerifyCommits_Call) Return(_a0 map[string]string, _a1 error) *MockGitRepository_VerifyCommits_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitRepository_VerifyCommits_Call) RunAndReturn(run func(context.Context, string, string) (map[string]string, error)) *MockGitRepository_VerifyCommits_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // WithMaxFileSize provides a mock function with given fields: maxBytes
 func (_m *MockGitRepository) WithMaxFileSize(maxBytes int64) {
 	_m.Called(maxBytes)
@@ -125,6 +125,10 @@ func ValidateGitConfigFields(repo *provisioning.Repository, url, branch, path st
 		list = append(list, field.Invalid(field.NewPath("spec", t, "path"), path, "path must be relative"))
 	}
 
+	if repo.Spec.Commit != nil {
+		list = append(list, ValidateCommitVerification(repo.Spec.Commit.Verification, repo.Spec.Commit.SigningMethod, field.NewPath("spec", "commit", "verification"))...)
+	}
+
 	return list
 }
 
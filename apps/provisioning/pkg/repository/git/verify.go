@@ -0,0 +1,413 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/grafana/nanogit"
+	"github.com/grafana/nanogit/protocol"
+	githash "github.com/grafana/nanogit/protocol/hash"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+)
+
+// maxVerifiedCommits limits how many commits a single sync walks to verify signatures
+const maxVerifiedCommits = 1000
+
+const (
+	gpgSignaturePrefix = "-----BEGIN PGP SIGNATURE-----"
+	sshSignaturePrefix = "-----BEGIN SSH SIGNATURE-----"
+
+	// sshSignatureMagic prefixes SSH signatures (see PROTOCOL.sshsig in OpenSSH)
+	sshSignatureMagic = "SSHSIG"
+	// sshSignatureNamespace is the namespace git uses to sign commits
+	sshSignatureNamespace = "git"
+	// grafanaSigner is recorded as the signer of commits signed with the ssh signing key of the repository
+	grafanaSigner = "grafana"
+)
+
+// CommitVerificationFromSpec returns the commit verification of the repository, or nil when
+// incoming commits are not verified.
+func CommitVerificationFromSpec(r *provisioning.Repository) *provisioning.CommitVerification {
+	if r.Spec.Commit != nil && r.Spec.Commit.Verification != nil && r.Spec.Commit.Verification.RequireSignedCommits {
+		return r.Spec.Commit.Verification
+	}
+	return nil
+}
+
+// ValidateCommitVerification checks that the trusted keys and acknowledged commits of the commit verification
+// can be read, and that the commits Grafana signs with the signing method can be verified.
+func ValidateCommitVerification(cfg *provisioning.CommitVerification, signingMethod provisioning.SigningMethod, path *field.Path) field.ErrorList {
+	if cfg == nil {
+		return nil
+	}
+
+	var list field.ErrorList
+	for i, key := range cfg.GPGKeys {
+		if _, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key)); err != nil {
+			list = append(list, field.Invalid(path.Child("gpgKeys").Index(i), "[KEY]", fmt.Sprintf("invalid armored public key: %v", err)))
+		}
+	}
+	for i, line := range cfg.SSHAllowedSigners {
+		if _, _, err := parseAllowedSigner(line); err != nil {
+			list = append(list, field.Invalid(path.Child("sshAllowedSigners").Index(i), line, err.Error()))
+		}
+	}
+
+	for i, commit := range cfg.AcknowledgedCommits {
+		if b, err := hex.DecodeString(commit); err != nil || (len(b) != 20 && len(b) != 32) {
+			list = append(list, field.Invalid(path.Child("acknowledgedCommits").Index(i), commit, "must be a full commit hash"))
+		}
+	}
+
+	if !cfg.RequireSignedCommits {
+		return list
+	}
+	switch signingMethod {
+	case provisioning.GPGSigningMethod, provisioning.SSHSigningMethod:
+		// The signing key is trusted
+	case provisioning.SMIMESigningMethod:
+		list = append(list, field.Invalid(path.Child("requireSignedCommits"), true, "commits signed with smime can not be verified, use the gpg or ssh signing method"))
+	default:
+		if len(cfg.GPGKeys) == 0 && len(cfg.SSHAllowedSigners) == 0 {
+			list = append(list, field.Required(path, "signed commits require a signing key, or at least one gpg key or ssh allowed signer"))
+		}
+	}
+	return list
+}
+
+// commitKeyring holds the keys trusted to sign incoming commits, including the key Grafana signs its own commits with
+type commitKeyring struct {
+	gpg openpgp.EntityList
+	// ssh maps the SHA256 fingerprint of each trusted key to its principal
+	ssh map[string]string
+}
+
+func newCommitKeyring(cfg *provisioning.CommitVerification, signing RepositoryConfig) (*commitKeyring, error) {
+	keyring := &commitKeyring{ssh: map[string]string{}}
+
+	// Grafana signs the changes it saves with the signing key, which would otherwise stop the next sync
+	if !signing.CommitSigningKey.IsZero() {
+		switch signing.SigningMethod {
+		case provisioning.GPGSigningMethod:
+			entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(string(signing.CommitSigningKey)))
+			if err != nil {
+				return nil, fmt.Errorf("read signing key: %w", err)
+			}
+			keyring.gpg = append(keyring.gpg, entities...)
+		case provisioning.SSHSigningMethod:
+			signer, err := ssh.ParsePrivateKey([]byte(signing.CommitSigningKey))
+			if err != nil {
+				return nil, fmt.Errorf("read signing key: %w", err)
+			}
+			keyring.ssh[ssh.FingerprintSHA256(signer.PublicKey())] = grafanaSigner
+		}
+	}
+
+	for i, key := range cfg.GPGKeys {
+		entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key))
+		if err != nil {
+			return nil, fmt.Errorf("read gpg key %d: %w", i, err)
+		}
+		keyring.gpg = append(keyring.gpg, entities...)
+	}
+	for _, line := range cfg.SSHAllowedSigners {
+		principal, key, err := parseAllowedSigner(line)
+		if err != nil {
+			return nil, err
+		}
+		keyring.ssh[ssh.FingerprintSHA256(key)] = principal
+	}
+	return keyring, nil
+}
+
+// parseAllowedSigner reads a "<principal> <key type> <base64 key> [comment]" line
+func parseAllowedSigner(line string) (string, ssh.PublicKey, error) {
+	principal, key, ok := strings.Cut(strings.TrimSpace(line), " ")
+	if !ok || principal == "" {
+		return "", nil, fmt.Errorf("allowed signer must be formatted as <principal> <key type> <key>")
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(key)))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid ssh key for %s: %w", principal, err)
+	}
+	return principal, pub, nil
+}
+
+// verify checks the signature of the payload and returns the identity of the signer
+func (k *commitKeyring) verify(payload []byte, signature string) (string, error) {
+	signature = strings.TrimSpace(signature)
+	switch {
+	case signature == "":
+		return "", fmt.Errorf("commit is not signed: %w", repository.ErrUnverifiedCommit)
+	case strings.HasPrefix(signature, gpgSignaturePrefix):
+		return k.verifyGPG(payload, signature)
+	case strings.HasPrefix(signature, sshSignaturePrefix):
+		return k.verifySSH(payload, signature)
+	default:
+		return "", fmt.Errorf("unsupported signature format: %w", repository.ErrUnverifiedCommit)
+	}
+}
+
+func (k *commitKeyring) verifyGPG(payload []byte, signature string) (string, error) {
+	signer, err := openpgp.CheckArmoredDetachedSignature(k.gpg, bytes.NewReader(payload), strings.NewReader(signature), nil)
+	if err != nil {
+		if errors.Is(err, pgperrors.ErrUnknownIssuer) {
+			return "", fmt.Errorf("signed by an unknown gpg key: %w", repository.ErrUnverifiedCommit)
+		}
+		return "", fmt.Errorf("invalid gpg signature: %v: %w", err, repository.ErrUnverifiedCommit)
+	}
+
+	if id := signer.PrimaryIdentity(); id != nil && id.UserId != nil {
+		if id.UserId.Email != "" {
+			return id.UserId.Email, nil
+		}
+		if id.UserId.Name != "" {
+			return id.UserId.Name, nil
+		}
+	}
+	return signer.PrimaryKey.KeyIdString(), nil
+}
+
+func (k *commitKeyring) verifySSH(payload []byte, signature string) (string, error) {
+	block, _ := pem.Decode([]byte(signature))
+	if block == nil || block.Type != "SSH SIGNATURE" || !bytes.HasPrefix(block.Bytes, []byte(sshSignatureMagic)) {
+		return "", fmt.Errorf("invalid ssh signature: %w", repository.ErrUnverifiedCommit)
+	}
+
+	var sig struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}
+	if err := ssh.Unmarshal(block.Bytes[len(sshSignatureMagic):], &sig); err != nil {
+		return "", fmt.Errorf("invalid ssh signature: %v: %w", err, repository.ErrUnverifiedCommit)
+	}
+	if sig.Version != 1 || sig.Namespace != sshSignatureNamespace {
+		return "", fmt.Errorf("ssh signature is not a git signature: %w", repository.ErrUnverifiedCommit)
+	}
+
+	pub, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid ssh signature key: %v: %w", err, repository.ErrUnverifiedCommit)
+	}
+	principal, ok := k.ssh[ssh.FingerprintSHA256(pub)]
+	if !ok {
+		return "", fmt.Errorf("signed by an unknown ssh key %s: %w", ssh.FingerprintSHA256(pub), repository.ErrUnverifiedCommit)
+	}
+
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("unsupported ssh signature hash %q: %w", sig.HashAlgorithm, repository.ErrUnverifiedCommit)
+	}
+	h.Write(payload)
+
+	var blob ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &blob); err != nil {
+		return "", fmt.Errorf("invalid ssh signature: %v: %w", err, repository.ErrUnverifiedCommit)
+	}
+
+	signed := append([]byte(sshSignatureMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sig.Namespace, sig.Reserved, sig.HashAlgorithm, h.Sum(nil)})...)
+	if err := pub.Verify(signed, &blob); err != nil {
+		return "", fmt.Errorf("invalid ssh signature: %v: %w", err, repository.ErrUnverifiedCommit)
+	}
+	return principal, nil
+}
+
+// VerifyCommits checks the signature of every commit reachable from ref but not from base that changes
+// files in the configured path, including the commits of merged side branches. Commits that only change
+// other paths are not checked. Without a base, the whole history is not walked: the commit at ref must be
+// signed, as its signature covers the entire tree, and only the files it changes are attributed to a signer.
+// Acknowledged commits are accepted without a signature, and the files they change have no signer.
+func (r *gitRepository) VerifyCommits(ctx context.Context, base, ref string) (map[string]string, error) {
+	ctx, logger := r.withGitContext(ctx, ref)
+
+	cfg := CommitVerificationFromSpec(r.config)
+	if cfg == nil {
+		return nil, nil
+	}
+	keyring, err := newCommitKeyring(cfg, r.gitConfig)
+	if err != nil {
+		return nil, fmt.Errorf("read trusted keys: %w", err)
+	}
+	acknowledged := make(map[string]struct{}, len(cfg.AcknowledgedCommits))
+	for _, commit := range cfg.AcknowledgedCommits {
+		acknowledged[strings.ToLower(commit)] = struct{}{}
+	}
+
+	refHash, err := r.resolveRefToHash(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolve ref: %w", err)
+	}
+
+	var commits []*nanogit.Commit
+	if base == "" {
+		commit, err := r.client.GetCommit(ctx, refHash)
+		if err != nil {
+			return nil, fmt.Errorf("get commit %s: %w", refHash.String(), mapNanogitError(err))
+		}
+		commits = []*nanogit.Commit{commit}
+	} else {
+		baseHash, err := r.resolveRefToHash(ctx, base)
+		if err != nil {
+			return nil, fmt.Errorf("resolve base ref: %w", err)
+		}
+		commits, err = r.commitsBetween(ctx, baseHash, refHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	signers := map[string]string{}
+	for _, commit := range commits {
+		changed, err := r.changedFiles(ctx, commit)
+		if err != nil {
+			return nil, err
+		}
+		if len(changed) == 0 && base != "" {
+			continue
+		}
+
+		var signer string
+		if _, ok := acknowledged[commit.Hash.String()]; !ok {
+			signer, err = keyring.verify(commit.SignedData, commit.Signature)
+			if err != nil {
+				return nil, fmt.Errorf("commit %s: %w, acknowledge it in spec.commit.verification.acknowledgedCommits once reviewed", commit.Hash.String(), err)
+			}
+		}
+		for _, p := range changed {
+			// The latest commit changing a file is the one that signs its resource
+			if _, ok := signers[p]; !ok {
+				signers[p] = signer
+			}
+		}
+	}
+
+	logger.Debug("verified commit signatures", "commits", len(commits), "files", len(signers))
+	return signers, nil
+}
+
+const (
+	reachableFromRef = 1 << iota
+	reachableFromBase
+)
+
+// walkedCommit is a commit visited by commitsBetween, with the sides it is reachable from
+type walkedCommit struct {
+	commit *nanogit.Commit
+	flags  int
+}
+
+// commitsBetween returns the commits reachable from ref but not from base, newest first. Like git, it walks
+// both histories at once in committer time order and stops once every pending commit is reachable from base,
+// so merged side branches are followed without walking the history shared with base.
+func (r *gitRepository) commitsBetween(ctx context.Context, base, ref githash.Hash) ([]*nanogit.Commit, error) {
+	walked := map[githash.Hash]*walkedCommit{}
+	var pending []*walkedCommit
+
+	visit := func(h githash.Hash, flags int) error {
+		if w, ok := walked[h]; ok {
+			w.flags |= flags
+			return nil
+		}
+		if len(walked) >= maxVerifiedCommits {
+			return fmt.Errorf("more than %d commits to verify since %s", maxVerifiedCommits, base.String())
+		}
+
+		commit, err := r.client.GetCommit(ctx, h)
+		if err != nil {
+			return fmt.Errorf("get commit %s: %w", h.String(), mapNanogitError(err))
+		}
+		w := &walkedCommit{commit: commit, flags: flags}
+		walked[h] = w
+		pending = append(pending, w)
+		return nil
+	}
+
+	if err := visit(ref, reachableFromRef); err != nil {
+		return nil, err
+	}
+	if err := visit(base, reachableFromBase); err != nil {
+		return nil, err
+	}
+
+	var candidates []*walkedCommit
+	for slices.ContainsFunc(pending, func(w *walkedCommit) bool { return w.flags&reachableFromBase == 0 }) {
+		// Take the newest pending commit
+		newest := 0
+		for i, w := range pending {
+			if w.commit.Committer.Time.After(pending[newest].commit.Committer.Time) {
+				newest = i
+			}
+		}
+		w := pending[newest]
+		pending = slices.Delete(pending, newest, newest+1)
+
+		if w.flags&reachableFromBase == 0 {
+			candidates = append(candidates, w)
+		}
+		for _, parent := range w.commit.Parents {
+			if err := visit(parent, w.flags); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// A commit found from ref can turn out to be reachable from base later in the walk
+	commits := make([]*nanogit.Commit, 0, len(candidates))
+	for _, w := range candidates {
+		if w.flags&reachableFromBase == 0 {
+			commits = append(commits, w.commit)
+		}
+	}
+	return commits, nil
+}
+
+// changedFiles returns the paths, relative to the configured path, changed by the commit
+func (r *gitRepository) changedFiles(ctx context.Context, commit *nanogit.Commit) ([]string, error) {
+	files, err := r.client.CompareCommits(ctx, commit.Parent, commit.Hash, nanogit.WithRenameDetection())
+	if err != nil {
+		return nil, fmt.Errorf("compare commit %s: %w", commit.Hash.String(), mapNanogitError(err))
+	}
+
+	var changed []string
+	for _, f := range files {
+		if p, err := safepath.RelativeTo(f.Path, r.gitConfig.Path); err == nil {
+			changed = append(changed, p)
+		}
+		if f.Status == protocol.FileStatusRenamed {
+			if p, err := safepath.RelativeTo(f.OldPath, r.gitConfig.Path); err == nil {
+				changed = append(changed, p)
+			}
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,422 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/nanogit"
+	"github.com/grafana/nanogit/mocks"
+	"github.com/grafana/nanogit/protocol"
+	"github.com/grafana/nanogit/protocol/hash"
+)
+
+type testSSHSigner struct {
+	key    ed25519.PrivateKey
+	signer ssh.Signer
+}
+
+func newTestSSHSigner(t *testing.T) *testSSHSigner {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	return &testSSHSigner{key: key, signer: signer}
+}
+
+func (s *testSSHSigner) allowedSigner(principal string) string {
+	return principal + " " + string(ssh.MarshalAuthorizedKey(s.signer.PublicKey()))
+}
+
+func (s *testSSHSigner) privateKey(t *testing.T) string {
+	t.Helper()
+	block, err := ssh.MarshalPrivateKey(s.key, "")
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(block))
+}
+
+// sign creates a git ssh signature of the payload
+func (s *testSSHSigner) sign(t *testing.T, payload []byte) string {
+	t.Helper()
+	digest := sha512.Sum512(payload)
+	signed := append([]byte(sshSignatureMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sshSignatureNamespace, "", "sha512", digest[:]})...)
+	sig, err := s.signer.Sign(rand.Reader, signed)
+	require.NoError(t, err)
+
+	blob := append([]byte(sshSignatureMagic), ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{1, s.signer.PublicKey().Marshal(), sshSignatureNamespace, "", "sha512", ssh.Marshal(sig)})...)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "SSH SIGNATURE", Bytes: blob}))
+}
+
+type testGPGSigner struct {
+	entity *openpgp.Entity
+}
+
+func newTestGPGSigner(t *testing.T, name, email string) *testGPGSigner {
+	t.Helper()
+	entity, err := openpgp.NewEntity(name, "", email, nil)
+	require.NoError(t, err)
+	return &testGPGSigner{entity: entity}
+}
+
+func (s *testGPGSigner) publicKey(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, s.entity.Serialize(w))
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func (s *testGPGSigner) privateKey(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, s.entity.SerializePrivate(w, nil))
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func (s *testGPGSigner) sign(t *testing.T, payload []byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, openpgp.ArmoredDetachSign(&buf, s.entity, bytes.NewReader(payload), nil))
+	return buf.String()
+}
+
+func TestCommitKeyring(t *testing.T) {
+	payload := []byte("tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nupdate dashboards\n")
+
+	alice := newTestSSHSigner(t)
+	mallory := newTestSSHSigner(t)
+	bob := newTestGPGSigner(t, "Bob", "bob@example.com")
+	eve := newTestGPGSigner(t, "Eve", "eve@example.com")
+
+	keyring, err := newCommitKeyring(&provisioning.CommitVerification{
+		RequireSignedCommits: true,
+		GPGKeys:              []string{bob.publicKey(t)},
+		SSHAllowedSigners:    []string{alice.allowedSigner("alice@example.com")},
+	}, RepositoryConfig{})
+	require.NoError(t, err)
+
+	t.Run("trusted ssh key", func(t *testing.T) {
+		signer, err := keyring.verify(payload, alice.sign(t, payload))
+		require.NoError(t, err)
+		require.Equal(t, "alice@example.com", signer)
+	})
+
+	t.Run("trusted gpg key", func(t *testing.T) {
+		signer, err := keyring.verify(payload, bob.sign(t, payload))
+		require.NoError(t, err)
+		require.Equal(t, "bob@example.com", signer)
+	})
+
+	tests := []struct {
+		name      string
+		signature string
+		payload   []byte
+		errorMsg  string
+	}{
+		{name: "unsigned", errorMsg: "commit is not signed"},
+		{name: "unknown ssh key", signature: mallory.sign(t, payload), errorMsg: "signed by an unknown ssh key"},
+		{name: "unknown gpg key", signature: eve.sign(t, payload), errorMsg: "signed by an unknown gpg key"},
+		{name: "ssh signature of another payload", signature: alice.sign(t, []byte("other")), errorMsg: "invalid ssh signature"},
+		{name: "gpg signature of another payload", signature: bob.sign(t, []byte("other")), errorMsg: "invalid gpg signature"},
+		{name: "unsupported format", signature: "-----BEGIN SIGNED MESSAGE-----", errorMsg: "unsupported signature format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := keyring.verify(payload, tt.signature)
+			require.ErrorIs(t, err, repository.ErrUnverifiedCommit)
+			require.ErrorContains(t, err, tt.errorMsg)
+		})
+	}
+
+	t.Run("the ssh signing key of the repository is trusted", func(t *testing.T) {
+		keyring, err := newCommitKeyring(&provisioning.CommitVerification{RequireSignedCommits: true}, RepositoryConfig{
+			SigningMethod:    provisioning.SSHSigningMethod,
+			CommitSigningKey: common.RawSecureValue(mallory.privateKey(t)),
+		})
+		require.NoError(t, err)
+
+		signer, err := keyring.verify(payload, mallory.sign(t, payload))
+		require.NoError(t, err)
+		require.Equal(t, grafanaSigner, signer)
+	})
+
+	t.Run("the gpg signing key of the repository is trusted", func(t *testing.T) {
+		keyring, err := newCommitKeyring(&provisioning.CommitVerification{RequireSignedCommits: true}, RepositoryConfig{
+			SigningMethod:    provisioning.GPGSigningMethod,
+			CommitSigningKey: common.RawSecureValue(eve.privateKey(t)),
+		})
+		require.NoError(t, err)
+
+		signer, err := keyring.verify(payload, eve.sign(t, payload))
+		require.NoError(t, err)
+		require.Equal(t, "eve@example.com", signer)
+	})
+}
+
+func TestValidateCommitVerification(t *testing.T) {
+	path := field.NewPath("spec", "commit", "verification")
+	alice := newTestSSHSigner(t)
+	bob := newTestGPGSigner(t, "Bob", "bob@example.com")
+
+	require.Empty(t, ValidateCommitVerification(nil, "", path))
+	require.Empty(t, ValidateCommitVerification(&provisioning.CommitVerification{
+		RequireSignedCommits: true,
+		GPGKeys:              []string{bob.publicKey(t)},
+		SSHAllowedSigners:    []string{alice.allowedSigner("alice@example.com")},
+		AcknowledgedCommits:  []string{"1111111111111111111111111111111111111111"},
+	}, "", path))
+	// The signing key is trusted without other keys
+	require.Empty(t, ValidateCommitVerification(&provisioning.CommitVerification{
+		RequireSignedCommits: true,
+	}, provisioning.SSHSigningMethod, path))
+
+	errs := ValidateCommitVerification(&provisioning.CommitVerification{
+		RequireSignedCommits: true,
+	}, "", path)
+	require.Len(t, errs, 1)
+	require.Equal(t, "spec.commit.verification", errs[0].Field)
+
+	errs = ValidateCommitVerification(&provisioning.CommitVerification{
+		RequireSignedCommits: true,
+		GPGKeys:              []string{bob.publicKey(t)},
+	}, provisioning.SMIMESigningMethod, path)
+	require.Len(t, errs, 1)
+	require.Equal(t, "spec.commit.verification.requireSignedCommits", errs[0].Field)
+
+	errs = ValidateCommitVerification(&provisioning.CommitVerification{
+		RequireSignedCommits: true,
+		GPGKeys:              []string{"not a key"},
+		SSHAllowedSigners:    []string{"ssh-ed25519", "alice@example.com ssh-ed25519 AAAA"},
+		AcknowledgedCommits:  []string{"1111111"},
+	}, "", path)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	require.Equal(t, []string{
+		"spec.commit.verification.gpgKeys[0]",
+		"spec.commit.verification.sshAllowedSigners[0]",
+		"spec.commit.verification.sshAllowedSigners[1]",
+		"spec.commit.verification.acknowledgedCommits[0]",
+	}, fields)
+}
+
+func TestGitRepository_VerifyCommits(t *testing.T) {
+	alice := newTestSSHSigner(t)
+	bob := newTestGPGSigner(t, "Bob", "bob@example.com")
+
+	base := hash.MustFromHex("1111111111111111111111111111111111111111")
+	unrelated := hash.MustFromHex("2222222222222222222222222222222222222222")
+	sshSigned := hash.MustFromHex("3333333333333333333333333333333333333333")
+	gpgSigned := hash.MustFromHex("4444444444444444444444444444444444444444")
+
+	now := time.Now()
+	newCommit := func(h hash.Hash, age int, sign func(*testing.T, []byte) string, parents ...hash.Hash) *nanogit.Commit {
+		payload := []byte(fmt.Sprintf("tree %s\n\ncommit\n", h.String()))
+		commit := &nanogit.Commit{
+			Hash:       h,
+			Parents:    parents,
+			Committer:  nanogit.Committer{Time: now.Add(-time.Duration(age) * time.Hour)},
+			SignedData: payload,
+		}
+		if len(parents) > 0 {
+			commit.Parent = parents[0]
+		}
+		if sign != nil {
+			commit.Signature = sign(t, payload)
+		}
+		return commit
+	}
+
+	newRepo := func(commits map[hash.Hash]*nanogit.Commit, changes ...[]nanogit.CommitFile) *gitRepository {
+		client := &mocks.FakeClient{}
+		client.GetCommitStub = func(_ context.Context, h hash.Hash) (*nanogit.Commit, error) {
+			commit, ok := commits[h]
+			if !ok {
+				return nil, nanogit.ErrObjectNotFound
+			}
+			return commit, nil
+		}
+		for i, files := range changes {
+			client.CompareCommitsReturnsOnCall(i, files, nil)
+		}
+
+		return &gitRepository{
+			client:    client,
+			gitConfig: RepositoryConfig{Branch: "main", Path: "configs"},
+			config: &provisioning.Repository{
+				Spec: provisioning.RepositorySpec{
+					Commit: &provisioning.CommitOptions{
+						Verification: &provisioning.CommitVerification{
+							RequireSignedCommits: true,
+							GPGKeys:              []string{bob.publicKey(t)},
+							SSHAllowedSigners:    []string{alice.allowedSigner("alice")},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("returns the signer of the latest commit changing each file", func(t *testing.T) {
+		repo := newRepo(map[hash.Hash]*nanogit.Commit{
+			gpgSigned: newCommit(gpgSigned, 1, bob.sign, sshSigned),
+			sshSigned: newCommit(sshSigned, 2, alice.sign, unrelated),
+			unrelated: newCommit(unrelated, 3, nil, base),
+			base:      newCommit(base, 4, nil),
+		},
+			[]nanogit.CommitFile{{Path: "configs/a.json", Status: protocol.FileStatusModified}},
+			[]nanogit.CommitFile{
+				{Path: "configs/a.json", Status: protocol.FileStatusAdded},
+				{Path: "configs/new/b.json", OldPath: "configs/b.json", Status: protocol.FileStatusRenamed},
+			},
+			[]nanogit.CommitFile{{Path: "README.md", Status: protocol.FileStatusModified}},
+		)
+
+		signers, err := repo.VerifyCommits(context.Background(), base.String(), gpgSigned.String())
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"a.json":     "bob@example.com",
+			"new/b.json": "alice",
+			"b.json":     "alice",
+		}, signers)
+	})
+
+	t.Run("unsigned commits changing synced files are rejected", func(t *testing.T) {
+		repo := newRepo(map[hash.Hash]*nanogit.Commit{
+			gpgSigned: newCommit(gpgSigned, 1, bob.sign, unrelated),
+			unrelated: newCommit(unrelated, 2, nil, base),
+			base:      newCommit(base, 3, nil),
+		},
+			[]nanogit.CommitFile{{Path: "configs/a.json", Status: protocol.FileStatusModified}},
+			[]nanogit.CommitFile{{Path: "configs/b.json", Status: protocol.FileStatusDeleted}},
+		)
+
+		_, err := repo.VerifyCommits(context.Background(), base.String(), gpgSigned.String())
+		require.ErrorIs(t, err, repository.ErrUnverifiedCommit)
+		require.ErrorContains(t, err, "commit "+unrelated.String())
+	})
+
+	t.Run("acknowledged commits are accepted without a signer", func(t *testing.T) {
+		repo := newRepo(map[hash.Hash]*nanogit.Commit{
+			gpgSigned: newCommit(gpgSigned, 1, bob.sign, unrelated),
+			unrelated: newCommit(unrelated, 2, nil, base),
+			base:      newCommit(base, 3, nil),
+		},
+			[]nanogit.CommitFile{{Path: "configs/a.json", Status: protocol.FileStatusModified}},
+			[]nanogit.CommitFile{{Path: "configs/b.json", Status: protocol.FileStatusModified}},
+		)
+		repo.config.Spec.Commit.Verification.AcknowledgedCommits = []string{unrelated.String()}
+
+		signers, err := repo.VerifyCommits(context.Background(), base.String(), gpgSigned.String())
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"a.json": "bob@example.com",
+			"b.json": "",
+		}, signers)
+	})
+
+	t.Run("commits signed by grafana are trusted", func(t *testing.T) {
+		grafana := newTestSSHSigner(t)
+		repo := newRepo(map[hash.Hash]*nanogit.Commit{
+			sshSigned: newCommit(sshSigned, 1, grafana.sign, base),
+			base:      newCommit(base, 2, nil),
+		},
+			[]nanogit.CommitFile{{Path: "configs/a.json", Status: protocol.FileStatusModified}},
+		)
+		repo.gitConfig.SigningMethod = provisioning.SSHSigningMethod
+		repo.gitConfig.CommitSigningKey = common.RawSecureValue(grafana.privateKey(t))
+
+		signers, err := repo.VerifyCommits(context.Background(), base.String(), sshSigned.String())
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"a.json": grafanaSigner}, signers)
+	})
+
+	t.Run("only the commit at ref is verified without a base", func(t *testing.T) {
+		repo := newRepo(map[hash.Hash]*nanogit.Commit{
+			sshSigned: newCommit(sshSigned, 1, alice.sign, unrelated),
+		},
+			[]nanogit.CommitFile{{Path: "configs/a.json", Status: protocol.FileStatusModified}},
+		)
+
+		signers, err := repo.VerifyCommits(context.Background(), "", sshSigned.String())
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"a.json": "alice"}, signers)
+	})
+
+	t.Run("the commit at ref must be signed without a base", func(t *testing.T) {
+		repo := newRepo(map[hash.Hash]*nanogit.Commit{
+			unrelated: newCommit(unrelated, 1, nil, base),
+		},
+			[]nanogit.CommitFile{{Path: "README.md", Status: protocol.FileStatusModified}},
+		)
+
+		_, err := repo.VerifyCommits(context.Background(), "", unrelated.String())
+		require.ErrorIs(t, err, repository.ErrUnverifiedCommit)
+	})
+
+	t.Run("commits of merged side branches are verified", func(t *testing.T) {
+		forkPoint := hash.MustFromHex("5555555555555555555555555555555555555555")
+		side := hash.MustFromHex("6666666666666666666666666666666666666666")
+		merge := hash.MustFromHex("7777777777777777777777777777777777777777")
+
+		// The side branch forked before base and is merged after it
+		repo := newRepo(map[hash.Hash]*nanogit.Commit{
+			merge:     newCommit(merge, 1, bob.sign, sshSigned, side),
+			sshSigned: newCommit(sshSigned, 2, alice.sign, base),
+			side:      newCommit(side, 3, nil, forkPoint),
+			base:      newCommit(base, 4, nil, forkPoint),
+			forkPoint: newCommit(forkPoint, 5, nil),
+		},
+			[]nanogit.CommitFile{{Path: "configs/b.json", Status: protocol.FileStatusModified}},
+			[]nanogit.CommitFile{{Path: "configs/a.json", Status: protocol.FileStatusModified}},
+			[]nanogit.CommitFile{{Path: "configs/b.json", Status: protocol.FileStatusModified}},
+		)
+
+		_, err := repo.VerifyCommits(context.Background(), base.String(), merge.String())
+		require.ErrorIs(t, err, repository.ErrUnverifiedCommit)
+		require.ErrorContains(t, err, "commit "+side.String())
+	})
+
+	t.Run("nothing is verified when signed commits are not required", func(t *testing.T) {
+		repo := newRepo(nil)
+		repo.config.Spec.Commit.Verification.RequireSignedCommits = false
+
+		signers, err := repo.VerifyCommits(context.Background(), base.String(), gpgSigned.String())
+		require.NoError(t, err)
+		require.Nil(t, signers)
+	})
+}
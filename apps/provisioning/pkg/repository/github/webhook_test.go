@@ -795,7 +795,7 @@ func TestGitHubRepository_Webhook(t *testing.T) {
 			},
 			setupRequest: func() *http.Request {
 				payload := `{
-					"action": "closed",
+					"action": "labeled",
 					"pull_request": {
 						"html_url": "https://github.com/grafana/grafana/pull/123",
 						"number": 123,
@@ -825,7 +825,7 @@ func TestGitHubRepository_Webhook(t *testing.T) {
 			},
 			expected: &provisioning.WebhookResponse{
 				Code:    http.StatusOK,
-				Message: "ignore pull request event: closed",
+				Message: "ignore pull request event: labeled",
 			},
 		},
 		{
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -76,6 +77,25 @@ var ErrTooManyItems error = &apierrors.StatusError{ErrStatus: metav1.Status{
 	Message: "maximum number of items exceeded",
 }}
 
+// ErrIncrementalSyncNotSupported is returned by Versioned.CompareFiles when a
+// repository can tell that it changed, but can not read the previous version of
+// a file, which incremental sync needs to replace or remove resources.
+var ErrIncrementalSyncNotSupported error = &apierrors.StatusError{ErrStatus: metav1.Status{
+	Status:  metav1.StatusFailure,
+	Code:    http.StatusNotImplemented,
+	Reason:  metav1.StatusReasonMethodNotAllowed,
+	Message: "incremental sync is not supported",
+}}
+
+// ErrUnverifiedCommit is returned when a commit changing synced files is unsigned,
+// or is not signed by one of the keys trusted by the repository.
+var ErrUnverifiedCommit error = &apierrors.StatusError{ErrStatus: metav1.Status{
+	Status:  metav1.StatusFailure,
+	Code:    http.StatusForbidden,
+	Reason:  metav1.StatusReasonForbidden,
+	Message: "commit signature is not verified",
+}}
+
 var ErrRepositoryMismatch = apierrors.NewBadRequest("repository mismatch")
 
 // ErrInvalidRef indicates that a provided git ref (branch or commit SHA) failed validation.
@@ -232,6 +252,33 @@ type Versioned interface {
 	CompareFiles(ctx context.Context, base, ref string) ([]VersionedFileChange, error)
 }
 
+// CommitVerifier is implemented by repositories that can verify the signatures of incoming commits.
+//
+//go:generate mockery --name CommitVerifier --structname MockCommitVerifier --inpackage --filename commit_verifier_mock.go --with-expecter
+type CommitVerifier interface {
+	// VerifyCommits checks the signatures of the commits reachable from ref but not from base,
+	// including merged side branches, that change files in the repository path. It returns the
+	// signer of the latest commit changing each file, keyed by path. When base is empty, only the
+	// commit at ref is verified: its signature is trusted for the whole tree, not its history.
+	VerifyCommits(ctx context.Context, base, ref string) (map[string]string, error)
+}
+
+// VerifyCommits checks the signatures of the commits reachable from ref but not from base when the
+// repository requires signed commits, and returns the signer of each changed file. It returns nil when
+// the repository does not require signed commits.
+func VerifyCommits(ctx context.Context, repo Repository, base, ref string) (map[string]string, error) {
+	cfg := repo.Config()
+	if cfg.Spec.Commit == nil || cfg.Spec.Commit.Verification == nil || !cfg.Spec.Commit.Verification.RequireSignedCommits {
+		return nil, nil
+	}
+
+	verifier, ok := repo.(CommitVerifier)
+	if !ok {
+		return nil, fmt.Errorf("signed commits are not supported on %s repositories", cfg.Spec.Type)
+	}
+	return verifier.VerifyCommits(ctx, base, ref)
+}
+
 // BranchHandler is a repository that supports making actions on branches.
 type BranchHandler interface {
 	GetDefaultBranch(ctx context.Context) (string, error)
@@ -15,6 +15,8 @@ import (
 
 	provisioningadmission "github.com/grafana/grafana/apps/provisioning/pkg/apis/admission"
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+	"github.com/grafana/grafana/apps/provisioning/pkg/schedule"
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
 )
 
@@ -60,6 +62,22 @@ func (v *RepositoryValidator) Validate(ctx context.Context, cfg *provisioning.Re
 		}
 	}
 
+	switch cfg.Spec.Sync.ConflictStrategy {
+	case "", provisioning.ConflictStrategyRepository, provisioning.ConflictStrategyGrafana:
+	case provisioning.ConflictStrategyBranch:
+		if !cfg.Spec.Type.IsGit() {
+			list = append(list, field.Invalid(field.NewPath("spec", "sync", "conflictStrategy"),
+				cfg.Spec.Sync.ConflictStrategy, "the branch conflict strategy is only supported on git repositories"))
+		}
+	default:
+		list = append(list, field.NotSupported(field.NewPath("spec", "sync", "conflictStrategy"),
+			cfg.Spec.Sync.ConflictStrategy, []string{
+				string(provisioning.ConflictStrategyRepository),
+				string(provisioning.ConflictStrategyGrafana),
+				string(provisioning.ConflictStrategyBranch),
+			}))
+	}
+
 	// Reserved names (for now)
 	reserved := []string{"classic", "sql", "SQL", "plugins", "legacy", "new", "job", "github", "s3", "gcs", "file", "new", "create", "update", "delete"}
 	if slices.Contains(reserved, cfg.Name) {
@@ -86,7 +104,14 @@ func (v *RepositoryValidator) Validate(ctx context.Context, cfg *provisioning.Re
 			cfg.Spec.Git, "Git config only valid when type is git"))
 	}
 
+	if cfg.Spec.Type != provisioning.BucketRepositoryType && cfg.Spec.Bucket != nil {
+		list = append(list, field.Invalid(field.NewPath("spec", "bucket"),
+			cfg.Spec.Bucket, "Bucket config only valid when type is bucket"))
+	}
+
 	list = append(list, validateWorkflowOptions(cfg)...)
+	list = append(list, validateSources(cfg.Spec.Sources, field.NewPath("spec", "sources"))...)
+	list = append(list, schedule.Validate(cfg.Spec.Sync, field.NewPath("spec", "sync"))...)
 
 	for _, w := range cfg.Spec.Workflows {
 		switch w {
@@ -153,19 +178,19 @@ func validateWorkflowOptions(cfg *provisioning.Repository) field.ErrorList {
 	var list field.ErrorList
 
 	switch cfg.Spec.Type {
-	case provisioning.LocalRepositoryType:
-		// Local repositories support neither the branch workflow nor pull requests.
+	case provisioning.LocalRepositoryType, provisioning.BucketRepositoryType:
+		// Local and bucket repositories support neither the branch workflow nor pull requests.
 		if cfg.Spec.Branch != nil {
 			list = append(list, field.Invalid(field.NewPath("spec", "branch"),
-				cfg.Spec.Branch, "branch options are not supported on local repositories"))
+				cfg.Spec.Branch, fmt.Sprintf("branch options are not supported on %s repositories", cfg.Spec.Type)))
 		}
 		if cfg.Spec.Commit != nil {
 			list = append(list, field.Invalid(field.NewPath("spec", "commit"),
-				cfg.Spec.Commit, "commit options are not supported on local repositories"))
+				cfg.Spec.Commit, fmt.Sprintf("commit options are not supported on %s repositories", cfg.Spec.Type)))
 		}
 		if cfg.Spec.PullRequest != nil {
 			list = append(list, field.Invalid(field.NewPath("spec", "pullRequest"),
-				cfg.Spec.PullRequest, "pull request options are not supported on local repositories"))
+				cfg.Spec.PullRequest, fmt.Sprintf("pull request options are not supported on %s repositories", cfg.Spec.Type)))
 		}
 	case provisioning.GitRepositoryType:
 		// Plain git supports the branch workflow but cannot open pull requests.
@@ -352,3 +377,37 @@ func invalidRepositoryError(name string, list field.ErrorList) error {
 		provisioning.RepositoryResourceInfo.GroupVersionKind().GroupKind(),
 		name, list)
 }
+
+// validateSources checks that the source directories and their libraries are folders
+// inside the repository, and that each folder is configured once.
+func validateSources(sources []provisioning.SourceDirectory, fldPath *field.Path) field.ErrorList {
+	var list field.ErrorList
+	seen := make(map[string]struct{}, len(sources))
+	for i, src := range sources {
+		p := fldPath.Index(i)
+		dir := safepath.EnsureTrailingSlash(safepath.Clean(src.Path))
+		if err := safepath.IsSafe(dir); err != nil {
+			list = append(list, field.Invalid(p.Child("path"), src.Path, err.Error()))
+		} else if _, ok := seen[dir]; ok {
+			list = append(list, field.Duplicate(p.Child("path"), src.Path))
+		}
+		seen[dir] = struct{}{}
+
+		switch src.Format {
+		case provisioning.SourceFormatJsonnet, provisioning.SourceFormatCUE:
+		default:
+			list = append(list, field.NotSupported(p.Child("format"), src.Format, []string{
+				string(provisioning.SourceFormatJsonnet),
+				string(provisioning.SourceFormatCUE),
+			}))
+		}
+
+		for j, lib := range src.Libraries {
+			libDir := safepath.EnsureTrailingSlash(safepath.Clean(lib))
+			if err := safepath.IsSafe(libDir); err != nil || libDir == "" {
+				list = append(list, field.Invalid(p.Child("libraries").Index(j), lib, "must be a folder in the repository"))
+			}
+		}
+	}
+	return list
+}
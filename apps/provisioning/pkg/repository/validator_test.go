@@ -317,6 +317,113 @@ func TestValidator_Validate(t *testing.T) {
 				require.Contains(t, errors.ToAggregate().Error(), "pull request options are not supported on local repositories")
 			},
 		},
+		{
+			name: "branch options for bucket repository",
+			repository: func() *provisioning.Repository {
+				return &provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{CleanFinalizer},
+					},
+					Spec: provisioning.RepositorySpec{
+						Title:  "Test Repo",
+						Type:   provisioning.BucketRepositoryType,
+						Bucket: &provisioning.BucketRepositoryConfig{URL: "mem://"},
+						Branch: &provisioning.BranchOptions{NameTemplate: "{{title}}"},
+					},
+				}
+			}(),
+			expectedErrs: 1,
+			validateError: func(t *testing.T, errors field.ErrorList) {
+				require.Equal(t, "spec.branch", errors[0].Field)
+				require.Contains(t, errors.ToAggregate().Error(), "branch options are not supported on bucket repositories")
+			},
+		},
+		{
+			name: "bucket config on git repository",
+			repository: func() *provisioning.Repository {
+				return &provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{CleanFinalizer},
+					},
+					Spec: provisioning.RepositorySpec{
+						Title:  "Test Repo",
+						Type:   provisioning.GitRepositoryType,
+						Bucket: &provisioning.BucketRepositoryConfig{URL: "mem://"},
+					},
+				}
+			}(),
+			expectedErrs: 1,
+			validateError: func(t *testing.T, errors field.ErrorList) {
+				require.Equal(t, "spec.bucket", errors[0].Field)
+			},
+		},
+		{
+			name: "invalid sources",
+			repository: func() *provisioning.Repository {
+				return &provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{CleanFinalizer},
+					},
+					Spec: provisioning.RepositorySpec{
+						Title: "Test Repo",
+						Type:  provisioning.GitHubRepositoryType,
+						Sources: []provisioning.SourceDirectory{
+							{Path: "dashboards", Format: provisioning.SourceFormatJsonnet, Libraries: []string{"vendor"}},
+							{Path: "dashboards/", Format: "yaml", Libraries: []string{"../lib"}},
+						},
+					},
+				}
+			}(),
+			expectedErrs: 3,
+			validateError: func(t *testing.T, errors field.ErrorList) {
+				require.Equal(t, "spec.sources[1].path", errors[0].Field)
+				require.Equal(t, field.ErrorTypeDuplicate, errors[0].Type)
+				require.Equal(t, "spec.sources[1].format", errors[1].Field)
+				require.Equal(t, "spec.sources[1].libraries[0]", errors[2].Field)
+			},
+		},
+		{
+			name: "branch conflict strategy on a local repository",
+			repository: func() *provisioning.Repository {
+				return &provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{CleanFinalizer},
+					},
+					Spec: provisioning.RepositorySpec{
+						Title: "Test Repo",
+						Type:  provisioning.LocalRepositoryType,
+						Sync: provisioning.SyncOptions{
+							ConflictStrategy: provisioning.ConflictStrategyBranch,
+						},
+					},
+				}
+			}(),
+			expectedErrs: 1,
+			validateError: func(t *testing.T, errors field.ErrorList) {
+				require.Equal(t, "spec.sync.conflictStrategy", errors[0].Field)
+			},
+		},
+		{
+			name: "invalid sync schedule",
+			repository: func() *provisioning.Repository {
+				return &provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{CleanFinalizer},
+					},
+					Spec: provisioning.RepositorySpec{
+						Title: "Test Repo",
+						Type:  provisioning.LocalRepositoryType,
+						Sync: provisioning.SyncOptions{
+							Schedule: "every day",
+						},
+					},
+				}
+			}(),
+			expectedErrs: 1,
+			validateError: func(t *testing.T, errors field.ErrorList) {
+				require.Equal(t, "spec.sync.schedule", errors[0].Field)
+			},
+		},
 		{
 			name: "branch, commit and pull request options allowed for github repository",
 			repository: func() *provisioning.Repository {
@@ -27,6 +27,7 @@ const (
 	PullRequestActionOpened   PullRequestAction = "opened"
 	PullRequestActionReopened PullRequestAction = "reopened"
 	PullRequestActionUpdated  PullRequestAction = "updated"
+	PullRequestActionClosed   PullRequestAction = "closed"
 )
 
 // ProcessRequestFunc returns a provider-agnostic WebhookEvent for an inbound
@@ -137,10 +138,11 @@ func (m *WebhookHandler) pullRequestResponse(event WebhookEvent) *provisioning.W
 			Repository: m.repoName,
 			Action:     provisioning.JobActionPullRequest,
 			PullRequest: &provisioning.PullRequestJobOptions{
-				URL:  event.PRURL,
-				PR:   event.PRNumber,
-				Ref:  event.SourceRef,
-				Hash: event.Hash,
+				URL:    event.PRURL,
+				PR:     event.PRNumber,
+				Ref:    event.SourceRef,
+				Hash:   event.Hash,
+				Closed: event.Action == PullRequestActionClosed,
 			},
 		},
 	}
@@ -161,7 +163,7 @@ func (m *WebhookHandler) pushSyncResponse(deletedPaths []string, totalChanges in
 
 func watchedPullRequestAction(action PullRequestAction) bool {
 	switch action {
-	case PullRequestActionOpened, PullRequestActionReopened, PullRequestActionUpdated:
+	case PullRequestActionOpened, PullRequestActionReopened, PullRequestActionUpdated, PullRequestActionClosed:
 		return true
 	default:
 		return false
@@ -93,10 +93,38 @@ func TestWebhookHandler_Webhook(t *testing.T) {
 		},
 		{
 			name:  "pull request ignored action",
-			event: WebhookEvent{Type: WebhookEventPullRequest, RepoSlug: "grafana/grafana", Branch: "main", Action: "closed"},
+			event: WebhookEvent{Type: WebhookEventPullRequest, RepoSlug: "grafana/grafana", Branch: "main", Action: "labeled"},
 			expected: &provisioning.WebhookResponse{
 				Code:    http.StatusOK,
-				Message: "ignore pull request event: closed",
+				Message: "ignore pull request event: labeled",
+			},
+		},
+		{
+			name: "pull request closed",
+			event: WebhookEvent{
+				Type:      WebhookEventPullRequest,
+				RepoSlug:  "grafana/grafana",
+				Branch:    "main",
+				Action:    PullRequestActionClosed,
+				PRNumber:  123,
+				PRURL:     "https://github.com/grafana/grafana/pull/123",
+				SourceRef: "feature-branch",
+				Hash:      "abcdef",
+			},
+			expected: &provisioning.WebhookResponse{
+				Code:    http.StatusAccepted,
+				Message: "pull request: closed",
+				Job: &provisioning.JobSpec{
+					Repository: "test-repo",
+					Action:     provisioning.JobActionPullRequest,
+					PullRequest: &provisioning.PullRequestJobOptions{
+						URL:    "https://github.com/grafana/grafana/pull/123",
+						PR:     123,
+						Ref:    "feature-branch",
+						Hash:   "abcdef",
+						Closed: true,
+					},
+				},
 			},
 		},
 		{
@@ -0,0 +1,97 @@
+// Package schedule evaluates the cron schedule and the sync windows of a repository.
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+)
+
+// Parse parses a cron expression with five fields, or a descriptor such as @daily
+func Parse(expr string) (cron.Schedule, error) {
+	return cron.ParseStandard(expr)
+}
+
+// Location returns the time zone of the sync options, UTC when none is set
+func Location(opts provisioning.SyncOptions) (*time.Location, error) {
+	if opts.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(opts.TimeZone)
+}
+
+// Next returns the first scheduled sync after the given time.
+// The zero time is returned when the options do not define a schedule.
+func Next(opts provisioning.SyncOptions, after time.Time) (time.Time, error) {
+	if opts.Schedule == "" {
+		return time.Time{}, nil
+	}
+
+	sched, err := Parse(opts.Schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse schedule: %w", err)
+	}
+	loc, err := Location(opts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("load time zone: %w", err)
+	}
+	return sched.Next(after.In(loc)), nil
+}
+
+// Paused returns a message explaining why the sync windows do not allow sync at the given time.
+// An empty message means sync can run.
+func Paused(opts provisioning.SyncOptions, now time.Time) (string, error) {
+	if len(opts.Windows) == 0 {
+		return "", nil
+	}
+
+	loc, err := Location(opts)
+	if err != nil {
+		return "", fmt.Errorf("load time zone: %w", err)
+	}
+	now = now.In(loc)
+
+	var (
+		hasMaintenance  bool
+		inMaintenance   bool
+		nextMaintenance time.Time
+	)
+	for i, w := range opts.Windows {
+		sched, err := Parse(w.Start)
+		if err != nil {
+			return "", fmt.Errorf("parse start of window %d: %w", i, err)
+		}
+		duration, err := time.ParseDuration(w.Duration)
+		if err != nil {
+			return "", fmt.Errorf("parse duration of window %d: %w", i, err)
+		}
+
+		// The window is open when it last started less than its duration ago
+		start := sched.Next(now.Add(-duration))
+		open := !start.After(now)
+
+		switch w.Type {
+		case provisioning.SyncWindowTypeBlackout:
+			if open {
+				return fmt.Sprintf("sync is paused by a blackout window until %s", start.Add(duration).Format(time.RFC3339)), nil
+			}
+		case provisioning.SyncWindowTypeMaintenance:
+			hasMaintenance = true
+			if open {
+				inMaintenance = true
+				continue
+			}
+			if next := sched.Next(now); nextMaintenance.IsZero() || next.Before(nextMaintenance) {
+				nextMaintenance = next
+			}
+		}
+	}
+
+	if hasMaintenance && !inMaintenance {
+		return fmt.Sprintf("sync only runs during maintenance windows, the next one starts at %s", nextMaintenance.Format(time.RFC3339)), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,130 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+)
+
+func at(t *testing.T, value string) time.Time {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, value)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestNext(t *testing.T) {
+	next, err := Next(provisioning.SyncOptions{}, at(t, "2026-10-19T01:00:00Z"))
+	require.NoError(t, err)
+	require.True(t, next.IsZero(), "no schedule")
+
+	next, err = Next(provisioning.SyncOptions{Schedule: "0 */6 * * *"}, at(t, "2026-10-19T01:00:00Z"))
+	require.NoError(t, err)
+	require.True(t, at(t, "2026-10-19T06:00:00Z").Equal(next))
+
+	next, err = Next(provisioning.SyncOptions{Schedule: "0 6 * * *", TimeZone: "Europe/Berlin"}, at(t, "2026-10-19T01:00:00Z"))
+	require.NoError(t, err)
+	require.True(t, at(t, "2026-10-19T04:00:00Z").Equal(next))
+
+	_, err = Next(provisioning.SyncOptions{Schedule: "often"}, time.Now())
+	require.Error(t, err)
+}
+
+func TestPaused(t *testing.T) {
+	nightly := provisioning.SyncWindow{Type: provisioning.SyncWindowTypeMaintenance, Start: "0 22 * * *", Duration: "4h"}
+	office := provisioning.SyncWindow{Type: provisioning.SyncWindowTypeBlackout, Start: "0 9 * * 1-5", Duration: "8h"}
+
+	tests := []struct {
+		name   string
+		opts   provisioning.SyncOptions
+		now    string
+		paused string
+	}{
+		{
+			name: "no windows",
+			now:  "2026-10-19T12:00:00Z",
+		},
+		{
+			name: "inside a maintenance window",
+			opts: provisioning.SyncOptions{Windows: []provisioning.SyncWindow{nightly}},
+			now:  "2026-10-19T23:00:00Z",
+		},
+		{
+			name: "inside a maintenance window that started the day before",
+			opts: provisioning.SyncOptions{Windows: []provisioning.SyncWindow{nightly}},
+			now:  "2026-10-20T01:30:00Z",
+		},
+		{
+			name:   "outside of the maintenance windows",
+			opts:   provisioning.SyncOptions{Windows: []provisioning.SyncWindow{nightly}},
+			now:    "2026-10-19T12:00:00Z",
+			paused: "sync only runs during maintenance windows, the next one starts at 2026-10-19T22:00:00Z",
+		},
+		{
+			name:   "inside a blackout window",
+			opts:   provisioning.SyncOptions{Windows: []provisioning.SyncWindow{office}},
+			now:    "2026-10-19T10:00:00Z",
+			paused: "sync is paused by a blackout window until 2026-10-19T17:00:00Z",
+		},
+		{
+			name: "outside of the blackout windows",
+			opts: provisioning.SyncOptions{Windows: []provisioning.SyncWindow{office}},
+			now:  "2026-10-24T10:00:00Z",
+		},
+		{
+			name: "windows use the time zone of the repository",
+			opts: provisioning.SyncOptions{
+				TimeZone: "Europe/Berlin",
+				Windows:  []provisioning.SyncWindow{{Type: provisioning.SyncWindowTypeMaintenance, Start: "0 22 * * *", Duration: "1h"}},
+			},
+			now: "2026-10-19T20:30:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paused, err := Paused(tt.opts, at(t, tt.now))
+			require.NoError(t, err)
+			require.Equal(t, tt.paused, paused)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	path := field.NewPath("spec", "sync")
+
+	require.Empty(t, Validate(provisioning.SyncOptions{
+		Schedule:          "@hourly",
+		TimeZone:          "America/New_York",
+		MaxConcurrentJobs: 2,
+		Windows: []provisioning.SyncWindow{
+			{Type: provisioning.SyncWindowTypeMaintenance, Start: "0 22 * * *", Duration: "4h"},
+		},
+	}, path))
+
+	errs := Validate(provisioning.SyncOptions{
+		Schedule:          "every hour",
+		TimeZone:          "Mars/Olympus_Mons",
+		MaxConcurrentJobs: -1,
+		Windows: []provisioning.SyncWindow{
+			{Type: "freeze", Start: "22:00", Duration: "0s"},
+		},
+	}, path)
+
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	require.Equal(t, []string{
+		"spec.sync.schedule",
+		"spec.sync.timeZone",
+		"spec.sync.windows[0].type",
+		"spec.sync.windows[0].start",
+		"spec.sync.windows[0].duration",
+		"spec.sync.maxConcurrentJobs",
+	}, fields)
+}
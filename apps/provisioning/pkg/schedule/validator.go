@@ -0,0 +1,47 @@
+package schedule
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+)
+
+var windowTypes = []string{string(provisioning.SyncWindowTypeMaintenance), string(provisioning.SyncWindowTypeBlackout)}
+
+// Validate checks that the schedule, time zone and windows of the sync options can be evaluated
+func Validate(opts provisioning.SyncOptions, path *field.Path) field.ErrorList {
+	var list field.ErrorList
+
+	if opts.Schedule != "" {
+		if _, err := Parse(opts.Schedule); err != nil {
+			list = append(list, field.Invalid(path.Child("schedule"), opts.Schedule, err.Error()))
+		}
+	}
+
+	if _, err := Location(opts); err != nil {
+		list = append(list, field.Invalid(path.Child("timeZone"), opts.TimeZone, err.Error()))
+	}
+
+	for i, w := range opts.Windows {
+		windowPath := path.Child("windows").Index(i)
+		switch w.Type {
+		case provisioning.SyncWindowTypeMaintenance, provisioning.SyncWindowTypeBlackout:
+		default:
+			list = append(list, field.NotSupported(windowPath.Child("type"), w.Type, windowTypes))
+		}
+		if _, err := Parse(w.Start); err != nil {
+			list = append(list, field.Invalid(windowPath.Child("start"), w.Start, err.Error()))
+		}
+		if d, err := time.ParseDuration(w.Duration); err != nil || d <= 0 {
+			list = append(list, field.Invalid(windowPath.Child("duration"), w.Duration, "must be a positive duration, e.g. 2h or 30m"))
+		}
+	}
+
+	if opts.MaxConcurrentJobs < 0 {
+		list = append(list, field.Invalid(path.Child("maxConcurrentJobs"), opts.MaxConcurrentJobs, "must not be negative"))
+	}
+
+	return list
+}
@@ -10,6 +10,25 @@ const injectedRtkApi = api
         query: () => ({ url: `/` }),
         providesTags: ['API Discovery'],
       }),
+      searchDashboardsAndFoldersInAllNamespaces: build.query<
+        SearchDashboardsAndFoldersInAllNamespacesApiResponse,
+        SearchDashboardsAndFoldersInAllNamespacesApiArg
+      >({
+        query: (queryArg) => ({
+          url: `/admin/search`,
+          params: {
+            namespace: queryArg['namespace'],
+            query: queryArg.query,
+            type: queryArg['type'],
+            facet: queryArg.facet,
+            tag: queryArg.tag,
+            sort: queryArg.sort,
+            limit: queryArg.limit,
+            offset: queryArg.offset,
+          },
+        }),
+        providesTags: ['Search'],
+      }),
       listDashboard: build.query<ListDashboardApiResponse, ListDashboardApiArg>({
         query: (queryArg) => ({
           url: `/dashboards`,
@@ -278,6 +297,32 @@ const injectedRtkApi = api
 export { injectedRtkApi as generatedAPI };
 export type GetApiResourcesApiResponse = /** status 200 OK */ ApiResourceList;
 export type GetApiResourcesApiArg = void;
+export type SearchDashboardsAndFoldersInAllNamespacesApiResponse = /** status 200 undefined */ {
+  denied?: string[];
+  errors?: {
+    [key: string]: string;
+  };
+  results: SearchResults;
+  searched: string[];
+};
+export type SearchDashboardsAndFoldersInAllNamespacesApiArg = {
+  /** namespaces to search. When empty, every namespace is searched */
+  namespace?: string[];
+  /** user query string */
+  query?: string;
+  /** search dashboards or folders.  When empty, this will search both */
+  type?: 'folder' | 'dashboard';
+  /** count distinct terms for selected fields */
+  facet?: string[];
+  /** tag query filter */
+  tag?: string[];
+  /** sortable field */
+  sort?: string;
+  /** number of results to return */
+  limit?: number;
+  /** number of results to skip */
+  offset?: number;
+};
 export type ListDashboardApiResponse = /** status 200 OK */ DashboardList;
 export type ListDashboardApiArg = {
   /** If 'true', then the output is pretty printed. Defaults to 'false' unless the user-agent indicates a browser or command-line HTTP tool (curl and wget). */
@@ -1116,6 +1161,8 @@ export type DashboardSnapshotWithDeleteKey = {
 export const {
   useGetApiResourcesQuery,
   useLazyGetApiResourcesQuery,
+  useSearchDashboardsAndFoldersInAllNamespacesQuery,
+  useLazySearchDashboardsAndFoldersInAllNamespacesQuery,
   useListDashboardQuery,
   useLazyListDashboardQuery,
   useCreateDashboardMutation,
@@ -1668,6 +1668,8 @@ export type MoveJobOptions = {
   targetPath?: string;
 };
 export type PullRequestJobOptions = {
+  /** The pull request was closed or merged, so its preview is removed */
+  closed?: boolean;
   /** The specific commit hash that triggered this notice */
   hash?: string;
   /** Pull request number (when appropriate) */
@@ -1677,9 +1679,22 @@ export type PullRequestJobOptions = {
   /** URL to the originator (eg, PR URL) */
   url?: string;
 };
+export type ConflictResolution = {
+  /** Path to the file in the repository */
+  path: string;
+  /** Apply the repository version, keep the Grafana version, or write the Grafana version to a merge branch
+    
+    Possible enum values:
+     - `"branch"` keeps the Grafana version and writes it to a merge branch, so it can be reviewed and merged into the repository
+     - `"grafana"` keeps the Grafana version and reports the conflict
+     - `"repository"` applies the repository version and reports the conflict */
+  resolution: 'branch' | 'grafana' | 'repository';
+};
 export type SyncJobOptions = {
   /** Incremental synchronization for versioned repositories */
   incremental: boolean;
+  /** Resolutions for the conflicts listed in the sync status of the repository. A conflict the sync finds for the same path is resolved the same way. */
+  resolutions?: ConflictResolution[];
 };
 export type ExportJobOptions = {
   /** FIXME: we should validate this in admission hooks Target branch for export (only git) */
@@ -1904,6 +1919,13 @@ export type PullRequestOptions = {
   titleTemplate?: string;
 };
 export type SyncOptions = {
+  /** How a sync handles resources that changed in both the repository and Grafana since the last sync. When empty, conflicts are not detected and the repository version is applied.
+    
+    Possible enum values:
+     - `"branch"` keeps the Grafana version and writes it to a merge branch, so it can be reviewed and merged into the repository
+     - `"grafana"` keeps the Grafana version and reports the conflict
+     - `"repository"` applies the repository version and reports the conflict */
+  conflictStrategy?: 'branch' | 'grafana' | 'repository';
   /** Enabled must be saved as true before any sync job will run */
   enabled: boolean;
   /** The interval between sync runs. The system defines a default value for this field, which will overwrite the user-defined one in case the latter is zero or lower than the system-defined one. */
@@ -1975,7 +1997,40 @@ export type ResourceCount = {
   group: string;
   resource: string;
 };
+export type ResourceConflict = {
+  /** The change made in the repository (update or delete)
+    
+    Possible enum values:
+     - `"create"`
+     - `"delete"`
+     - `"move"`
+     - `"update"` */
+  action: 'create' | 'delete' | 'move' | 'update';
+  /** The file hash at the previously synced ref */
+  baseHash?: string;
+  /** The branch the Grafana version was written to, when resolved with the branch strategy */
+  branch?: string;
+  /** The resource group */
+  group?: string;
+  /** The resource kind */
+  kind?: string;
+  /** The resource name */
+  name?: string;
+  /** Path to the file in the repository */
+  path: string;
+  /** How the conflict was resolved
+    
+    Possible enum values:
+     - `"branch"` keeps the Grafana version and writes it to a merge branch, so it can be reviewed and merged into the repository
+     - `"grafana"` keeps the Grafana version and reports the conflict
+     - `"repository"` applies the repository version and reports the conflict */
+  resolution: 'branch' | 'grafana' | 'repository';
+  /** The file hash recorded on the resource when it was last synced */
+  syncedHash?: string;
+};
 export type SyncStatus = {
+  /** Conflicts found by the last sync, and the ones that kept the Grafana version until they are resolved */
+  conflicts?: ResourceConflict[];
   /** When the sync job finished */
   finished?: number;
   /** Incremental synchronization for versioned repositories */
@@ -166,6 +166,46 @@ export function QueryHeader({
           />
         )}
 
+        <InlineSwitch
+          id={`sql-bind-variables-${htmlId}`}
+          label={t('grafana-sql.components.query-header.label-bind-variables', 'Bind variables')}
+          transparent={true}
+          showLabel={true}
+          value={query.bindVariables ?? false}
+          onChange={(ev) => {
+            if (!(ev.target instanceof HTMLInputElement)) {
+              return;
+            }
+
+            reportInteraction('grafana_sql_bind_variables_toggled', {
+              datasource: query.datasource?.type,
+              enabled: ev.target.checked,
+            });
+
+            onChange({ ...query, bindVariables: ev.target.checked });
+          }}
+        />
+
+        <InlineSwitch
+          id={`sql-stream-${htmlId}`}
+          label={t('grafana-sql.components.query-header.label-stream', 'Stream')}
+          transparent={true}
+          showLabel={true}
+          value={query.stream ?? false}
+          onChange={(ev) => {
+            if (!(ev.target instanceof HTMLInputElement)) {
+              return;
+            }
+
+            reportInteraction('grafana_sql_stream_toggled', {
+              datasource: query.datasource?.type,
+              enabled: ev.target.checked,
+            });
+
+            onChange({ ...query, stream: ev.target.checked });
+          }}
+        />
+
         {editorMode === EditorMode.Builder && (
           <>
             <InlineSwitch
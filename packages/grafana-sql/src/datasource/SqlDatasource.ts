@@ -1,4 +1,4 @@
-import { lastValueFrom, type Observable, throwError } from 'rxjs';
+import { lastValueFrom, merge, type Observable, throwError } from 'rxjs';
 import { map } from 'rxjs/operators';
 
 import {
@@ -35,6 +35,8 @@ import { MACRO_NAMES } from '../constants';
 import { type DB, type SQLQuery, type SQLOptions, type SqlQueryModel, QueryFormat, type SQLDialect } from '../types';
 import migrateAnnotation from '../utils/migration';
 
+import { doSqlChannelStream } from './streaming';
+
 export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLOptions> {
   uid: string;
   responseParser: ResponseParser;
@@ -116,6 +118,10 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
   }
 
   applyTemplateVariables(target: SQLQuery, scopedVars: ScopedVars) {
+    if (target.bindVariables) {
+      return this.bindTemplateVariables(target, scopedVars);
+    }
+
     return {
       refId: target.refId,
       datasource: this.getRef(),
@@ -124,6 +130,40 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
     };
   }
 
+  /**
+   * Replaces the template variables of the query with references to bind parameters. The backend passes
+   * their values to the database, so they don't need quoting and can't change the SQL. Built-in variables
+   * and the arguments of macros are read by the backend, so they are interpolated as text.
+   */
+  bindTemplateVariables(target: SQLQuery, scopedVars: ScopedVars) {
+    const parameters: Array<string | number> = [];
+    const bind = (value: string | number) => {
+      parameters.push(value);
+      return `$__param(${parameters.length - 1})`;
+    };
+    const bindValue = (value: string | string[] | number) => {
+      if (Array.isArray(value)) {
+        // An empty selection matches nothing, and IN () is not valid SQL
+        return value.length > 0 ? value.map(bind).join(',') : 'NULL';
+      }
+      return bind(value);
+    };
+
+    const rawSql = splitBindableSql(target.rawSql ?? '')
+      .map(({ text, bindable }) =>
+        this.templateSrv.replace(text, scopedVars, bindable ? bindValue : this.interpolateVariable)
+      )
+      .join('');
+
+    return {
+      refId: target.refId,
+      datasource: this.getRef(),
+      rawSql,
+      format: target.format,
+      parameters,
+    };
+  }
+
   query(request: DataQueryRequest<SQLQuery>): Observable<DataQueryResponse> {
     // This logic reenables the previous SQL behavior regarding what databases are available for the user to query.
     const databaseIssue = this.checkForDatabaseIssue(request);
@@ -146,7 +186,20 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
       });
     });
 
-    return super.query(request);
+    // Streaming queries are polled by the backend, which pushes the new rows over Grafana Live
+    const streaming = request.targets.filter((target) => target.stream && this.filterQuery(target));
+    if (!streaming.length) {
+      return super.query(request);
+    }
+
+    const results = streaming.map((target) =>
+      doSqlChannelStream(this.applyTemplateVariables(target, request.scopedVars), target, this.uid, request)
+    );
+    const targets = request.targets.filter((target) => !streaming.includes(target));
+    if (targets.length) {
+      results.push(super.query({ ...request, targets }));
+    }
+    return merge(...results);
   }
 
   private checkForDatabaseIssue(request: DataQueryRequest<SQLQuery>) {
@@ -266,3 +319,41 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
 interface RunSQLOptions extends LegacyMetricFindQueryOptions {
   refId?: string;
 }
+
+// Matches built-in variables, such as $__interval or ${__from:date}, and the start of macro calls, such as $__timeFilter(
+const TEXT_VARIABLE_REGEX = /\$__\w+\(?|\$\{__[^}]*\}|\[\[__[^\]]*\]\]/g;
+
+/**
+ * Splits the SQL into the parts whose template variables can be bound, and the built-in variables and macro
+ * calls, whose variables must be interpolated as text.
+ */
+export function splitBindableSql(sql: string): Array<{ text: string; bindable: boolean }> {
+  const parts: Array<{ text: string; bindable: boolean }> = [];
+  let last = 0;
+  for (const match of sql.matchAll(TEXT_VARIABLE_REGEX)) {
+    const start = match.index ?? 0;
+    if (start < last) {
+      // part of a macro call already split off
+      continue;
+    }
+    let end = start + match[0].length;
+    if (match[0].endsWith('(')) {
+      for (let depth = 1; end < sql.length && depth > 0; end++) {
+        if (sql[end] === '(') {
+          depth++;
+        } else if (sql[end] === ')') {
+          depth--;
+        }
+      }
+    }
+    if (start > last) {
+      parts.push({ text: sql.slice(last, start), bindable: true });
+    }
+    parts.push({ text: sql.slice(start, end), bindable: false });
+    last = end;
+  }
+  if (last < sql.length) {
+    parts.push({ text: sql.slice(last), bindable: true });
+  }
+  return parts;
+}
@@ -0,0 +1,57 @@
+import { defer, mergeMap, type Observable } from 'rxjs';
+
+import {
+  type DataQueryRequest,
+  type DataQueryResponse,
+  LiveChannelScope,
+  type StreamingFrameOptions,
+} from '@grafana/data';
+import { config, getGrafanaLiveSrv } from '@grafana/runtime';
+
+import { type SQLQuery } from '../types';
+
+/**
+ * Calculate a unique key for the query. Subscribers of the same query share a channel, so the
+ * backend polls it once for all of them. This key is not secure and is only picked to avoid
+ * possible collisions
+ */
+async function getLiveStreamKey(uid: string, query: object): Promise<string> {
+  const msgUint8 = new TextEncoder().encode(JSON.stringify(query));
+  const hashBuffer = await crypto.subtle.digest('SHA-1', msgUint8);
+  const hashArray = Array.from(new Uint8Array(hashBuffer.slice(0, 8))); // first 8 bytes
+  return `${uid}/${hashArray.map((b) => b.toString(16).padStart(2, '0')).join('')}/${config.bootData.user.orgId}`;
+}
+
+/**
+ * Streams the rows the backend finds when it polls the query. The query must already be interpolated.
+ */
+export function doSqlChannelStream(
+  query: object,
+  target: SQLQuery,
+  uid: string,
+  request: DataQueryRequest<SQLQuery>
+): Observable<DataQueryResponse> {
+  const data = { ...query, pollInterval: target.pollInterval };
+
+  const buffer: Partial<StreamingFrameOptions> = {
+    maxLength: request.maxDataPoints ?? 1000,
+  };
+  if (request.rangeRaw?.to === 'now') {
+    buffer.maxDelta = request.range.to.valueOf() - request.range.from.valueOf();
+  }
+
+  return defer(() => getLiveStreamKey(uid, data)).pipe(
+    mergeMap((key) =>
+      getGrafanaLiveSrv().getDataStream({
+        key: `${request.requestId}.${target.refId}`,
+        addr: {
+          scope: LiveChannelScope.DataSource,
+          stream: uid,
+          path: `poll/${key}`,
+          data,
+        },
+        buffer,
+      })
+    )
+  );
+}
@@ -1,4 +1,5 @@
 import { type DataSourceInstanceSettings } from '@grafana/data';
+import { type TemplateSrv } from '@grafana/runtime';
 
 import { type DB, type SQLOptions, type SqlQueryModel } from '../types';
 import { makeVariable } from '../utils/testHelpers';
@@ -167,3 +168,68 @@ describe('SqlDatasource - Variable Interpolation', () => {
     });
   });
 });
+
+describe('SqlDatasource - Bind variables', () => {
+  const instanceSettings = {
+    jsonData: {},
+  } as unknown as DataSourceInstanceSettings<SQLOptions>;
+
+  const values: Record<string, string | string[]> = {
+    host: "web'1",
+    dc: ['eu', 'us'],
+    none: [],
+    column: 'time',
+    __interval: '5m',
+  };
+  const templateSrv = {
+    replace: (target: string, _scopedVars: unknown, format: (value: string | string[], variable: object) => string) =>
+      target.replace(/\$(\w+)/g, (match, name: string) => (name in values ? format(values[name], {}) : match)),
+  } as unknown as TemplateSrv;
+
+  it('should replace variables with bind parameters', () => {
+    const ds = new TestSqlDatasource(instanceSettings, templateSrv);
+    const query = ds.applyTemplateVariables(
+      { refId: 'A', rawSql: 'SELECT * FROM t WHERE host = $host AND dc IN ($dc)', bindVariables: true },
+      {}
+    );
+
+    expect(query.rawSql).toEqual('SELECT * FROM t WHERE host = $__param(0) AND dc IN ($__param(1),$__param(2))');
+    expect(query).toHaveProperty('parameters', ["web'1", 'eu', 'us']);
+  });
+
+  it('should interpolate built-in variables and variables in macro arguments', () => {
+    const ds = new TestSqlDatasource(instanceSettings, templateSrv);
+    const query = ds.applyTemplateVariables(
+      {
+        refId: 'A',
+        rawSql: 'SELECT $__timeGroup($column, $__interval), v FROM t WHERE $__timeFilter($column) AND host = $host',
+        bindVariables: true,
+      },
+      {}
+    );
+
+    expect(query.rawSql).toEqual(
+      'SELECT $__timeGroup(time, 5m), v FROM t WHERE $__timeFilter(time) AND host = $__param(0)'
+    );
+    expect(query).toHaveProperty('parameters', ["web'1"]);
+  });
+
+  it('should not bind empty multi-value variables', () => {
+    const ds = new TestSqlDatasource(instanceSettings, templateSrv);
+    const query = ds.applyTemplateVariables(
+      { refId: 'A', rawSql: 'SELECT * FROM t WHERE dc IN ($none)', bindVariables: true },
+      {}
+    );
+
+    expect(query.rawSql).toEqual('SELECT * FROM t WHERE dc IN (NULL)');
+    expect(query).toHaveProperty('parameters', []);
+  });
+
+  it('should interpolate variables without bind variables', () => {
+    const ds = new TestSqlDatasource(instanceSettings, templateSrv);
+    const query = ds.applyTemplateVariables({ refId: 'A', rawSql: 'SELECT * FROM t WHERE host = $host' }, {});
+
+    expect(query.rawSql).toEqual("SELECT * FROM t WHERE host = web''1");
+    expect(query).not.toHaveProperty('parameters');
+  });
+});
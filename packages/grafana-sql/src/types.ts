@@ -53,6 +53,12 @@ export interface SQLQuery extends DataQuery {
   editorMode?: EditorMode;
   rawQuery?: boolean;
   meta?: SQLQueryMeta;
+  /** Pass template variable values to the database as bind parameters instead of splicing them into the SQL */
+  bindVariables?: boolean;
+  /** Poll the query for new rows and stream them. The query must filter an increasing time column with $__timeFilter */
+  stream?: boolean;
+  /** How often a streaming query is polled, such as 10s. Defaults to 5s */
+  pollInterval?: string;
 }
 
 export type SQLVariableQuery = { query: string } & SQLQuery;
@@ -83,9 +83,12 @@ func WithServiceIdentityName(name string) IdentityOpts {
 }
 
 func newInternalIdentity(name string, namespace string, orgID int64, opts ...IdentityOpts) Requester {
-	// Create a copy of the ServiceIdentityClaims to avoid modifying the global one.
+	// Create a copy of the claims to avoid modifying the global ones.
 	// Some of the options might mutate it.
 	claimsCopy := *ServiceIdentityClaims
+	if name == serviceNameForProvisioning {
+		claimsCopy = *provisioningIdentityClaims
+	}
 
 	staticRequester := &StaticRequester{
 		Type:           types.TypeAccessPolicy,
@@ -133,6 +136,18 @@ func WithServiceIdentity(ctx context.Context, orgID int64, opts ...IdentityOpts)
 	return WithRequester(ctx, r), r
 }
 
+// WithServiceIdentityForNamespace sets an identity representing the service itself, restricted to the namespace
+// and its org. Unlike WithServiceIdentityForSingleNamespace, the org is taken from the namespace.
+func WithServiceIdentityForNamespace(ctx context.Context, namespace string, opts ...IdentityOpts) (context.Context, Requester, error) {
+	ns, err := types.ParseNamespace(namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := newInternalIdentity(serviceName, ns.Value, ns.OrgID, opts...)
+	return WithRequester(ctx, r), r, nil
+}
+
 func WithProvisioningIdentity(ctx context.Context, namespace string, opts ...IdentityOpts) (context.Context, Requester, error) {
 	ns, err := types.ParseNamespace(namespace)
 	if err != nil {
@@ -231,6 +246,38 @@ var ServiceIdentityClaims = &authn.Claims[authn.AccessTokenClaims]{
 	},
 }
 
+// provisioningIdentityTokenPermissions are the permissions of the provisioning service identity:
+// those of the service identity, and the alert rule verbs Git Sync uses. They are not part of the
+// service identity, which other services and user sessions share.
+var provisioningIdentityTokenPermissions = append(slices.Clone(serviceIdentityTokenPermissions),
+	// Git sync reads and writes alert rules, and patches them to release them when a repository is removed.
+	"rules.alerting.grafana.app/alertrules:get",
+	"rules.alerting.grafana.app/alertrules:list",
+	"rules.alerting.grafana.app/alertrules:create",
+	"rules.alerting.grafana.app/alertrules:update",
+	"rules.alerting.grafana.app/alertrules:patch",
+	"rules.alerting.grafana.app/alertrules:delete",
+	"rules.alerting.grafana.app/recordingrules:get",
+	"rules.alerting.grafana.app/recordingrules:list",
+	"rules.alerting.grafana.app/recordingrules:create",
+	"rules.alerting.grafana.app/recordingrules:update",
+	"rules.alerting.grafana.app/recordingrules:patch",
+	"rules.alerting.grafana.app/recordingrules:delete",
+	"rules.alerting.grafana.app/rulesequences:get",
+	"rules.alerting.grafana.app/rulesequences:list",
+	"rules.alerting.grafana.app/rulesequences:create",
+	"rules.alerting.grafana.app/rulesequences:update",
+	"rules.alerting.grafana.app/rulesequences:patch",
+	"rules.alerting.grafana.app/rulesequences:delete",
+)
+
+var provisioningIdentityClaims = &authn.Claims[authn.AccessTokenClaims]{
+	Rest: authn.AccessTokenClaims{
+		Permissions:          provisioningIdentityTokenPermissions,
+		DelegatedPermissions: provisioningIdentityTokenPermissions,
+	},
+}
+
 func IsServiceIdentity(ctx context.Context) bool {
 	ident, ok := types.AuthInfoFrom(ctx)
 	if !ok {
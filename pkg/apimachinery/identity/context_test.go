@@ -59,6 +59,17 @@ func TestWithProvisioningIdentity(t *testing.T) {
 		}
 	})
 
+	t.Run("token permissions authorize alert rules, unlike the service identity", func(t *testing.T) {
+		_, service := identity.WithServiceIdentity(context.Background(), 1)
+		for _, verb := range []string{"list", "get", "create", "update", "patch", "delete"} {
+			res := authz.CheckServicePermissions(requester, "rules.alerting.grafana.app", "alertrules", verb)
+			require.True(t, res.Allowed, "alertrules %s should be allowed for the provisioning identity", verb)
+
+			res = authz.CheckServicePermissions(service, "rules.alerting.grafana.app", "alertrules", verb)
+			require.False(t, res.Allowed, "alertrules %s should not be allowed for the service identity", verb)
+		}
+	})
+
 	// The playlist apiserver guards access with its own authorizer, which evaluates the
 	// legacy playlists:read / playlists:write actions against the requester's permission
 	// map. The provisioning identity must carry those actions to read and write playlists.
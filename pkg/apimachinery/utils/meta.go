@@ -23,6 +23,19 @@ const LabelKeyGetHistory = "grafana.app/get-history"
 // LabelKeyGetTrash is used to list objects that have been (soft) deleted
 const LabelKeyGetTrash = "grafana.app/get-trash"
 
+// LabelKeyFieldSelector starts label selector requirements that select on a field of the object instead of
+// a label. Field selectors only have the = and != operators, so the set-based operators are written on the
+// path of a selectable field, e.g. "fields.grafana.app/spec.env in (prod,dev)"
+const LabelKeyFieldSelector = "fields.grafana.app/"
+
+// LabelKeyFieldPrefixSelector starts label selector requirements that select objects whose field starts with
+// the value, e.g. "prefix.fields.grafana.app/spec.title=team"
+const LabelKeyFieldPrefixSelector = "prefix.fields.grafana.app/"
+
+// LabelKeyPreview marks a read-only copy of a resource made to preview a pull request.
+// The value names the preview; everything in it is removed when the pull request is closed.
+const LabelKeyPreview = "grafana.app/preview"
+
 // AnnoKeyKubectlLastAppliedConfig is the annotation kubectl writes with the entire previous config
 const AnnoKeyKubectlLastAppliedConfig = "kubectl.kubernetes.io/last-applied-configuration"
 
@@ -64,6 +77,7 @@ const AnnoKeyManagerSuspended = "grafana.app/managerSuspended"
 const AnnoKeySourcePath = "grafana.app/sourcePath"
 const AnnoKeySourceChecksum = "grafana.app/sourceChecksum"
 const AnnoKeySourceTimestamp = "grafana.app/sourceTimestamp"
+const AnnoKeySourceSigner = "grafana.app/sourceSigner"
 
 // LabelKeyDeprecatedInternalID gives the deprecated internal ID of a resource
 // Deprecated: will be removed in grafana 13
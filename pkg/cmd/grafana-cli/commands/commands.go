@@ -234,6 +234,17 @@ var adminCommands = []*cli.Command{
 		Usage:  "Run schema migrations against the database configured in --config.",
 		Action: runDbCommand(logLastMigration),
 	},
+	{
+		Name:   "verify-audit-chain",
+		Usage:  "Verifies the tamper evident history chain of a unified storage namespace.",
+		Action: runDbCommand(verifyAuditChain),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "namespace",
+				Usage: "Namespace to verify, e.g. default or stacks-123",
+			},
+		},
+	},
 }
 
 var Commands = []*cli.Command{
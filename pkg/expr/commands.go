@@ -91,13 +91,17 @@ type ReduceCommand struct {
 	VarToReduce  string
 	refID        string
 	seriesMapper mathexp.ReduceMapper
+	// histogramParam is the quantile of the quantile reducer, and the threshold of the fraction_above reducer
+	histogramParam float64
 }
 
 // NewReduceCommand creates a new ReduceCMD.
 func NewReduceCommand(refID string, reducer mathexp.ReducerID, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
-	if err != nil {
-		return nil, err
+	if !mathexp.IsHistogramReducer(reducer) {
+		_, err := mathexp.GetReduceFunc(reducer)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &ReduceCommand{
@@ -159,7 +163,39 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 			return nil, fmt.Errorf("field settings must be an object, got %T for refId %v", s, rn.RefID)
 		}
 	}
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+
+	var histogramParam float64
+	if mathexp.IsHistogramReducer(redFunc) {
+		s, _ := settings.(map[string]any)
+		var err error
+		histogramParam, err = unmarshalHistogramParam(redFunc, s)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cmd, err := NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+	if err != nil {
+		return nil, err
+	}
+	cmd.histogramParam = histogramParam
+	return cmd, nil
+}
+
+// unmarshalHistogramParam returns the setting of the parameter of a histogram reducer
+func unmarshalHistogramParam(reducer mathexp.ReducerID, settings map[string]any) (float64, error) {
+	key := "threshold"
+	if reducer == mathexp.ReducerQuantile {
+		key = "quantile"
+	}
+	value, ok := settings[key].(float64)
+	if !ok {
+		return 0, fmt.Errorf("setting %s must be specified as a number when reducer is '%s'", key, reducer)
+	}
+	if reducer == mathexp.ReducerQuantile && (value < 0 || value > 1) {
+		return 0, fmt.Errorf("setting quantile must be between 0 and 1, got %v", value)
+	}
+	return value, nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -180,11 +216,20 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 	for i, val := range vars[gr.VarToReduce].Values {
 		switch v := val.(type) {
 		case mathexp.Series:
+			if mathexp.IsHistogramReducer(gr.Reducer) {
+				return newRes, fmt.Errorf("reducer %s can only reduce type histogram, got type %v", gr.Reducer, val.Type())
+			}
 			num, err := v.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
 			if err != nil {
 				return newRes, err
 			}
 			newRes.Values = append(newRes.Values, num)
+		case mathexp.Histogram:
+			num, err := v.Reduce(gr.refID, gr.Reducer, gr.histogramParam, gr.seriesMapper)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, num)
 		case mathexp.Number: // if incoming vars is just a number, any reduce op is just a noop, add it as it is
 			value := v.GetFloat64Value()
 			if gr.seriesMapper != nil {
@@ -205,7 +250,7 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 		case mathexp.NoData:
 			newRes.Values = append(newRes.Values, v.New())
 		default:
-			return newRes, fmt.Errorf("can only reduce type series, got type %v", val.Type())
+			return newRes, fmt.Errorf("can only reduce type series or histogram, got type %v", val.Type())
 		}
 	}
 	return newRes, nil
@@ -99,6 +99,111 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalReduceCommand_HistogramSettings(t *testing.T) {
+	var tests = []struct {
+		name          string
+		query         string
+		isError       bool
+		expectedParam float64
+	}{
+		{
+			name:          "quantile reducer reads the quantile setting",
+			query:         `{ "expression" : "$A", "reducer": "quantile", "settings": { "quantile": 0.99 } }`,
+			expectedParam: 0.99,
+		},
+		{
+			name:          "fraction_above reducer reads the threshold setting",
+			query:         `{ "expression" : "$A", "reducer": "fraction_above", "settings": { "mode": "dropNN", "threshold": 2.5 } }`,
+			expectedParam: 2.5,
+		},
+		{
+			name:    "error if the quantile is not specified",
+			query:   `{ "expression" : "$A", "reducer": "quantile" }`,
+			isError: true,
+		},
+		{
+			name:    "error if the quantile is out of range",
+			query:   `{ "expression" : "$A", "reducer": "quantile", "settings": { "quantile": 99 } }`,
+			isError: true,
+		},
+		{
+			name:    "error if the threshold is not a number",
+			query:   `{ "expression" : "$A", "reducer": "fraction_above", "settings": { "threshold": "2.5" } }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalReduceCommand(&rawNode{
+				RefID: "B",
+				Query: qmap,
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expectedParam, cmd.histogramParam)
+		})
+	}
+}
+
+func TestReduceExecuteHistogram(t *testing.T) {
+	histogram, err := mathexp.HistogramFromBuckets("latency", data.Labels{"handler": "/api"},
+		[]float64{0, 1}, []float64{1, 2}, []float64{10, 10})
+	require.NoError(t, err)
+	histogram.Exemplars = mathexp.Exemplars{
+		{Time: time.Unix(10, 0), Value: 0.2, TraceID: "fast"},
+		{Time: time.Unix(20, 0), Value: 1.9, TraceID: "slow"},
+	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{histogram}},
+	}
+
+	t.Run("reduces histograms to numbers with the exemplars of their observations", func(t *testing.T) {
+		cmd, err := NewReduceCommand("B", mathexp.ReducerQuantile, "A", nil)
+		require.NoError(t, err)
+		cmd.histogramParam = 0.9
+
+		results, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest(), nil)
+		require.NoError(t, err)
+		require.Len(t, results.Values, 1)
+
+		number, ok := results.Values[0].(mathexp.Number)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"handler": "/api"}, number.GetLabels())
+		require.InDelta(t, 1.8, *number.GetFloat64Value(), 1e-9)
+		require.Equal(t, mathexp.Exemplars{histogram.Exemplars[1]}, number.GetMeta())
+	})
+
+	t.Run("series reducers count histograms", func(t *testing.T) {
+		cmd, err := NewReduceCommand("B", mathexp.ReducerCount, "A", nil)
+		require.NoError(t, err)
+
+		results, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest(), nil)
+		require.NoError(t, err)
+		require.Equal(t, 20.0, *results.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("histogram reducers error on series", func(t *testing.T) {
+		cmd, err := NewReduceCommand("B", mathexp.ReducerFractionAbove, "A", nil)
+		require.NoError(t, err)
+
+		series := mathexp.NewSeries("A", nil, 1)
+		series.SetPoint(0, time.Unix(0, 0), new(1.0))
+		_, err = cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{series}},
+		}, tracing.InitializeTracerForTest(), nil)
+		require.Error(t, err)
+	})
+}
+
 func TestReduceExecute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 
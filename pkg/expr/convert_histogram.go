@@ -0,0 +1,224 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// exemplarFrameName is the name of the frames Prometheus returns the exemplars of a query in
+const exemplarFrameName = "exemplar"
+
+// traceIDLabelNames are the names of the exemplar labels that hold trace IDs, in precedence order
+var traceIDLabelNames = []string{"trace_id", "traceID", "traceId", "traceid"}
+
+// hasNativeHistograms returns true if one of the frames holds a Prometheus native histogram.
+func hasNativeHistograms(frames data.Frames) bool {
+	for _, frame := range frames {
+		if isNativeHistogramFrame(frame) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNativeHistogramFrame returns true if the frame holds a Prometheus native histogram, which the
+// data source returns as heatmap cells with the bounds and the count of each bucket at each step.
+func isNativeHistogramFrame(frame *data.Frame) bool {
+	if frame == nil || frame.Meta == nil || frame.Meta.Type != data.FrameTypeHeatmapCells {
+		return false
+	}
+	for _, name := range []string{"yMin", "yMax", "count"} {
+		if f, _ := frame.FieldByName(name); f == nil || !f.Type().Numeric() {
+			return false
+		}
+	}
+	return true
+}
+
+func isExemplarFrame(frame *data.Frame) bool {
+	return frame != nil && strings.EqualFold(frame.Name, exemplarFrameName)
+}
+
+// framesToHistograms converts the native histograms of a Prometheus response into Histograms, with
+// the exemplars of their series. A histogram is read at the last step of the time range, like the
+// last value of a time series: each step holds the whole histogram at its time, so the steps are not
+// added up.
+func framesToHistograms(frames data.Frames) ([]mathexp.Value, error) {
+	var exemplars mathexp.Exemplars
+	for _, frame := range frames {
+		if isExemplarFrame(frame) {
+			exemplars = append(exemplars, readExemplars(frame)...)
+		}
+	}
+
+	vals := make([]mathexp.Value, 0, len(frames))
+	for _, frame := range frames {
+		if frame == nil || isExemplarFrame(frame) {
+			continue
+		}
+		if !isNativeHistogramFrame(frame) {
+			return nil, fmt.Errorf("frame [%v] (RefID %v) is not a native histogram, native histograms can't be mixed with other series", frame.Name, frame.RefID)
+		}
+		h, err := frameToHistogram(frame)
+		if err != nil {
+			return nil, err
+		}
+		for _, ex := range exemplars {
+			if ex.Labels.Contains(h.GetLabels()) {
+				h.Exemplars = append(h.Exemplars, ex)
+			}
+		}
+		vals = append(vals, h)
+	}
+	return vals, nil
+}
+
+func frameToHistogram(frame *data.Frame) (mathexp.Histogram, error) {
+	lowerField, _ := frame.FieldByName("yMin")
+	upperField, _ := frame.FieldByName("yMax")
+	countField, _ := frame.FieldByName("count")
+
+	// the labels of the series are on one of the fields, depending on the version of the data source
+	var labels data.Labels
+	for _, f := range []*data.Field{countField, upperField, lowerField} {
+		if len(f.Labels) > 0 {
+			labels = f.Labels.Copy()
+			break
+		}
+	}
+
+	lowers, uppers, counts := floatValues(lowerField), floatValues(upperField), floatValues(countField)
+	if rows := lastStepRows(frame); rows != nil {
+		lowers, uppers, counts = pickRows(lowers, rows), pickRows(uppers, rows), pickRows(counts, rows)
+	}
+
+	h, err := mathexp.HistogramFromBuckets(frame.Name, labels, lowers, uppers, counts)
+	if err != nil {
+		return h, fmt.Errorf("failed to read native histogram of frame [%v] (RefID %v): %w", frame.Name, frame.RefID, err)
+	}
+	return h, nil
+}
+
+// lastStepRows returns the rows of the buckets of the last step of a native histogram frame, or nil
+// if the frame has no time field, in which case all its rows are the buckets of a single step.
+func lastStepRows(frame *data.Frame) []int {
+	var timeField *data.Field
+	for _, f := range frame.Fields {
+		if ft := f.Type(); ft == data.FieldTypeTime || ft == data.FieldTypeNullableTime {
+			timeField = f
+			break
+		}
+	}
+	if timeField == nil {
+		return nil
+	}
+
+	var last time.Time
+	rows := []int{}
+	for i := 0; i < timeField.Len(); i++ {
+		v, ok := timeField.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		switch t := v.(time.Time); {
+		case t.After(last):
+			last = t
+			rows = append(rows[:0], i)
+		case t.Equal(last):
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
+
+// pickRows returns the values at the given rows
+func pickRows(values []float64, rows []int) []float64 {
+	picked := make([]float64, len(rows))
+	for i, row := range rows {
+		picked[i] = values[row]
+	}
+	return picked
+}
+
+// floatValues returns the values of a numeric field, with NaN for null values
+func floatValues(field *data.Field) []float64 {
+	values := make([]float64, field.Len())
+	for i := range values {
+		f, err := field.NullableFloatAt(i)
+		if err != nil || f == nil {
+			values[i] = math.NaN()
+			continue
+		}
+		values[i] = *f
+	}
+	return values
+}
+
+// readExemplars reads the exemplars of a Prometheus exemplar frame. The frame has a time field, a
+// value field and a string field for each label of the exemplars, the labels of their series included.
+// Exemplars without a trace ID are dropped.
+func readExemplars(frame *data.Frame) mathexp.Exemplars {
+	timeIdx, valueIdx := -1, -1
+	var labelIdxs []int
+	for i, field := range frame.Fields {
+		switch ft := field.Type(); {
+		case ft == data.FieldTypeTime || ft == data.FieldTypeNullableTime:
+			if timeIdx == -1 {
+				timeIdx = i
+			}
+		case ft.Numeric():
+			if valueIdx == -1 {
+				valueIdx = i
+			}
+		case ft == data.FieldTypeString || ft == data.FieldTypeNullableString:
+			labelIdxs = append(labelIdxs, i)
+		}
+	}
+	if timeIdx == -1 || valueIdx == -1 {
+		return nil
+	}
+
+	exemplars := make(mathexp.Exemplars, 0, frame.Rows())
+	for row := 0; row < frame.Rows(); row++ {
+		t, ok := frame.ConcreteAt(timeIdx, row)
+		if !ok {
+			continue
+		}
+		value, err := frame.Fields[valueIdx].NullableFloatAt(row)
+		if err != nil || value == nil {
+			continue
+		}
+
+		labels := make(data.Labels, len(labelIdxs))
+		for _, idx := range labelIdxs {
+			if v, ok := frame.ConcreteAt(idx, row); ok {
+				labels[frame.Fields[idx].Name] = v.(string)
+			}
+		}
+		traceID := ""
+		for _, name := range traceIDLabelNames {
+			if id, ok := labels[name]; ok && id != "" {
+				traceID = id
+				delete(labels, name)
+				break
+			}
+		}
+		if traceID == "" {
+			continue
+		}
+
+		exemplars = append(exemplars, mathexp.Exemplar{
+			Time:    t.(time.Time),
+			Value:   *value,
+			TraceID: traceID,
+			Labels:  labels,
+		})
+	}
+	return exemplars
+}
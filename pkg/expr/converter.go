@@ -28,6 +28,14 @@ func (c *ResultConverter) Convert(ctx context.Context,
 		return "no-data", mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
 	}
 
+	if isPrometheusDatasource(datasourceType) && hasNativeHistograms(frames) { // Prometheus Native Histograms
+		vals, err := framesToHistograms(frames)
+		if err != nil {
+			return "", mathexp.Results{}, fmt.Errorf("failed to read frames as native histograms: %w", err)
+		}
+		return "native histogram", mathexp.Results{Values: vals}, nil
+	}
+
 	var dt data.FrameType
 	//nolint:staticcheck // not yet migrated to OpenFeature
 	dt, useDataplane, _ := shouldUseDataplane(frames, logger, c.Features.IsEnabled(ctx, featuremgmt.FlagDisableSSEDataplane))
@@ -139,8 +147,12 @@ func getResponseFrame(logger *log.ConcreteLogger, resp *backend.QueryDataRespons
 	return response.Frames, nil
 }
 
+func isPrometheusDatasource(datasourceType string) bool {
+	return datasourceType == datasources.DS_PROMETHEUS || datasourceType == datasources.DS_AMAZON_PROMETHEUS || datasourceType == datasources.DS_AZURE_PROMETHEUS
+}
+
 func isAllFrameVectors(datasourceType string, frames data.Frames) bool {
-	if datasourceType != datasources.DS_PROMETHEUS && datasourceType != datasources.DS_AMAZON_PROMETHEUS && datasourceType != datasources.DS_AZURE_PROMETHEUS {
+	if !isPrometheusDatasource(datasourceType) {
 		return false
 	}
 	allVector := false
@@ -120,3 +120,58 @@ func TestConvertDataFramesToResults(t *testing.T) {
 		})
 	})
 }
+
+func TestConvertNativeHistograms(t *testing.T) {
+	converter := &ResultConverter{Features: featuremgmt.WithFeatures(), Tracer: tracing.InitializeTracerForTest()}
+
+	histogramFrame := func(labels data.Labels) *data.Frame {
+		return data.NewFrame("http_request_duration_seconds",
+			data.NewField("xMax", nil, []time.Time{time.Unix(60, 0), time.Unix(60, 0), time.Unix(120, 0), time.Unix(120, 0)}),
+			data.NewField("yMin", nil, []float64{0.5, 1, 0.5, 1}),
+			data.NewField("yMax", nil, []float64{1, 2, 1, 2}),
+			data.NewField("count", labels, []float64{4, 1, 2, 3}),
+		).SetMeta(&data.FrameMeta{Type: data.FrameTypeHeatmapCells, TypeVersion: data.FrameTypeVersion{0, 1}})
+	}
+	exemplarFrame := data.NewFrame("exemplar",
+		data.NewField("Time", nil, []time.Time{time.Unix(90, 0), time.Unix(100, 0), time.Unix(110, 0)}),
+		data.NewField("Value", nil, []float64{1.5, 0.7, 1.2}),
+		data.NewField("handler", nil, []string{"/api", "/api", "/login"}),
+		data.NewField("trace_id", nil, []string{"abc", "def", "ghi"}),
+	)
+
+	t.Run("reads the buckets of the last step and matches exemplars by labels", func(t *testing.T) {
+		frames := data.Frames{
+			histogramFrame(data.Labels{"handler": "/api"}),
+			histogramFrame(data.Labels{"handler": "/login"}),
+			exemplarFrame,
+		}
+		resultType, res, err := converter.Convert(context.Background(), datasources.DS_PROMETHEUS, frames)
+		require.NoError(t, err)
+		require.Equal(t, "native histogram", resultType)
+		require.Len(t, res.Values, 2)
+
+		h, ok := res.Values[0].(mathexp.Histogram)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"handler": "/api"}, h.GetLabels())
+		require.Equal(t, 2, h.Len())
+		lower, upper, count := h.GetBucket(0)
+		require.Equal(t, []float64{0.5, 1, 2}, []float64{lower, upper, count})
+		lower, upper, count = h.GetBucket(1)
+		require.Equal(t, []float64{1, 2, 3}, []float64{lower, upper, count})
+		require.Equal(t, []string{"def", "abc"}, h.Exemplars.TraceIDs())
+
+		h, ok = res.Values[1].(mathexp.Histogram)
+		require.True(t, ok)
+		require.Equal(t, []string{"ghi"}, h.Exemplars.TraceIDs())
+		require.Equal(t, data.Labels{"handler": "/login"}, h.Exemplars[0].Labels)
+	})
+
+	t.Run("can't be mixed with other series", func(t *testing.T) {
+		series := data.NewFrame("up",
+			data.NewField("time", nil, []time.Time{time.Unix(60, 0)}),
+			data.NewField("value", nil, []float64{1}),
+		)
+		_, _, err := converter.Convert(context.Background(), datasources.DS_PROMETHEUS, data.Frames{histogramFrame(nil), series})
+		require.Error(t, err)
+	})
+}
@@ -407,6 +407,8 @@ const (
 	TypeNoData
 	// TypeTableData is a tabular data response.
 	TypeTableData
+	// TypeHistogramSet is a collection of labelled histograms.
+	TypeHistogramSet
 )
 
 // String returns a string representation of the ReturnType.
@@ -426,6 +428,8 @@ func (f ReturnType) String() string {
 		return "noData"
 	case TypeTableData:
 		return "tableData"
+	case TypeHistogramSet:
+		return "histogramSet"
 	default:
 		return "unknown"
 	}
@@ -22,6 +22,11 @@ const (
 	ReducerCount  ReducerID = "count"
 	ReducerLast   ReducerID = "last"
 	ReducerMedian ReducerID = "median"
+
+	// Quantile of the observations of a histogram
+	ReducerQuantile ReducerID = "quantile"
+	// Fraction of the observations of a histogram above a threshold
+	ReducerFractionAbove ReducerID = "fraction_above"
 )
 
 // GetSupportedReduceFuncs returns collection of supported function names
@@ -29,6 +34,11 @@ func GetSupportedReduceFuncs() []ReducerID {
 	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian}
 }
 
+// GetSupportedHistogramReduceFuncs returns collection of function names that reduce histograms
+func GetSupportedHistogramReduceFuncs() []ReducerID {
+	return []ReducerID{ReducerCount, ReducerSum, ReducerQuantile, ReducerFractionAbove}
+}
+
 func Sum(fv *Float64Field) *float64 {
 	var sum float64
 	for i := 0; i < fv.Len(); i++ {
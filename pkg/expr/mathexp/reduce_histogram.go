@@ -0,0 +1,196 @@
+package mathexp
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// maxReducedExemplars is the number of exemplars kept with the number a histogram is reduced to
+const maxReducedExemplars = 10
+
+// IsHistogramReducer returns true if the reducer only applies to histograms
+func IsHistogramReducer(rFunc ReducerID) bool {
+	return rFunc == ReducerQuantile || rFunc == ReducerFractionAbove
+}
+
+// Reduce turns the Histogram into a Number based on the given reduction function. param is the
+// quantile (0-1) of ReducerQuantile and the threshold of ReducerFractionAbove. ReducerSum estimates
+// the sum from the bounds of the buckets. The exemplars of the
+// observations the number reports on are set as its meta.
+func (h Histogram) Reduce(refID string, rFunc ReducerID, param float64, mapper ReduceMapper) (Number, error) {
+	var l data.Labels
+	if h.GetLabels() != nil {
+		l = h.GetLabels().Copy()
+	}
+	number := NewNumber(refID, l)
+
+	var f float64
+	exemplars := h.Exemplars
+	switch rFunc {
+	case ReducerCount:
+		f = h.count()
+	case ReducerSum:
+		f = h.sum()
+	case ReducerQuantile:
+		f = h.quantile(param)
+		exemplars = exemplars.atLeast(f)
+	case ReducerFractionAbove:
+		f = h.fractionAbove(param)
+		exemplars = exemplars.atLeast(param)
+	default:
+		return number, fmt.Errorf("invalid expression '%s': reduction %v is not supported for histograms. Supported only: %v", refID, rFunc, GetSupportedHistogramReduceFuncs())
+	}
+
+	value := &f
+	if mapper != nil {
+		value = mapper.MapOutput(value)
+	}
+	number.SetValue(value)
+	if len(exemplars) > 0 {
+		number.SetMeta(exemplars.latest(maxReducedExemplars))
+	}
+	return number, nil
+}
+
+// count returns the number of observations of the histogram
+func (h Histogram) count() float64 {
+	var total float64
+	for i := 0; i < h.Len(); i++ {
+		_, _, count := h.GetBucket(i)
+		total += count
+	}
+	return total
+}
+
+// sum estimates the sum of the observations of the histogram, counting the observations of each
+// bucket at its middle, or at its finite bound for the buckets that are open ended. The estimate can
+// be off by up to half the width of the buckets; the exact sum is only in the _sum series of the
+// histogram, which a query can read instead.
+func (h Histogram) sum() float64 {
+	var sum float64
+	for i := 0; i < h.Len(); i++ {
+		lower, upper, count := h.GetBucket(i)
+		if count == 0 {
+			continue
+		}
+		var mid float64
+		switch {
+		case math.IsInf(lower, -1):
+			mid = upper
+		case math.IsInf(upper, 1):
+			mid = lower
+		default:
+			mid = (lower + upper) / 2
+		}
+		sum += mid * count
+	}
+	return sum
+}
+
+// quantile estimates the q-quantile of the observations of the histogram, interpolating linearly
+// within the bucket of the quantile like the histogram_quantile function of Prometheus.
+func (h Histogram) quantile(q float64) float64 {
+	total := h.count()
+	switch {
+	case total == 0 || math.IsNaN(q):
+		return math.NaN()
+	case q < 0:
+		return math.Inf(-1)
+	case q > 1:
+		return math.Inf(1)
+	}
+
+	rank := q * total
+	var cumulative float64
+	last := math.NaN()
+	for i := 0; i < h.Len(); i++ {
+		lower, upper, count := h.GetBucket(i)
+		if count <= 0 {
+			continue
+		}
+		if cumulative+count >= rank {
+			switch {
+			case math.IsInf(lower, -1):
+				return upper
+			case math.IsInf(upper, 1):
+				return lower
+			}
+			return lower + (upper-lower)*(rank-cumulative)/count
+		}
+		cumulative += count
+		last = upper
+	}
+	// only reached when rounding errors leave the rank above the total
+	return last
+}
+
+// fractionAbove estimates the fraction (0-1) of the observations of the histogram that are above
+// the threshold, assuming the observations are evenly spread within the bucket of the threshold.
+func (h Histogram) fractionAbove(threshold float64) float64 {
+	total := h.count()
+	if total == 0 || math.IsNaN(threshold) {
+		return math.NaN()
+	}
+
+	var above float64
+	for i := 0; i < h.Len(); i++ {
+		lower, upper, count := h.GetBucket(i)
+		switch {
+		case lower >= threshold:
+			above += count
+		case upper <= threshold:
+			// below the threshold
+		case math.IsInf(upper, 1):
+			above += count
+		case !math.IsInf(lower, -1):
+			above += count * (upper - threshold) / (upper - lower)
+		}
+	}
+	return above / total
+}
+
+// histogramBucket is a bucket of a histogram that is being built
+type histogramBucket struct {
+	lower, upper, count float64
+}
+
+// HistogramFromBuckets builds a Histogram from buckets given as parallel slices of lower bounds,
+// upper bounds and counts, such as the buckets of one step of a native histogram. Buckets with the
+// same bounds are summed; a histogram over time must be reduced to one step before it is passed in,
+// or the buckets of the steps are added up.
+func HistogramFromBuckets(name string, labels data.Labels, lowers, uppers, counts []float64) (Histogram, error) {
+	if len(lowers) != len(counts) || len(uppers) != len(counts) {
+		return Histogram{}, fmt.Errorf("histogram bounds and counts must have the same length, got %d lower bounds, %d upper bounds and %d counts", len(lowers), len(uppers), len(counts))
+	}
+
+	buckets := make([]histogramBucket, 0, len(counts))
+	index := make(map[[2]float64]int, len(counts))
+	for i, count := range counts {
+		if math.IsNaN(count) {
+			continue
+		}
+		key := [2]float64{lowers[i], uppers[i]}
+		if idx, ok := index[key]; ok {
+			buckets[idx].count += count
+			continue
+		}
+		index[key] = len(buckets)
+		buckets = append(buckets, histogramBucket{lower: lowers[i], upper: uppers[i], count: count})
+	}
+	slices.SortFunc(buckets, func(a, b histogramBucket) int {
+		if a.lower != b.lower {
+			return cmp.Compare(a.lower, b.lower)
+		}
+		return cmp.Compare(a.upper, b.upper)
+	})
+
+	h := NewHistogram(name, labels, len(buckets))
+	for i, b := range buckets {
+		h.SetBucket(i, b.lower, b.upper, b.count)
+	}
+	return h, nil
+}
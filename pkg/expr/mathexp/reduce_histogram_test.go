@@ -0,0 +1,126 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramFromBuckets(t *testing.T) {
+	t.Run("sums buckets with the same bounds and sorts them", func(t *testing.T) {
+		h, err := HistogramFromBuckets("count", data.Labels{"job": "api"},
+			[]float64{1, 0, 1, 2},
+			[]float64{2, 1, 2, 4},
+			[]float64{3, 1, 4, math.NaN()},
+		)
+		require.NoError(t, err)
+		require.Equal(t, data.Labels{"job": "api"}, h.GetLabels())
+		require.Equal(t, 2, h.Len())
+
+		lower, upper, count := h.GetBucket(0)
+		require.Equal(t, []float64{0, 1, 1}, []float64{lower, upper, count})
+		lower, upper, count = h.GetBucket(1)
+		require.Equal(t, []float64{1, 2, 7}, []float64{lower, upper, count})
+	})
+
+	t.Run("errors when the bounds and counts have different lengths", func(t *testing.T) {
+		_, err := HistogramFromBuckets("count", nil, []float64{0}, []float64{1, 2}, []float64{1, 2})
+		require.Error(t, err)
+	})
+}
+
+func TestHistogramReduce(t *testing.T) {
+	// 10 observations between 0 and 1, 20 between 1 and 2 and 10 between 2 and 4
+	h, err := HistogramFromBuckets("count", data.Labels{"job": "api"},
+		[]float64{0, 1, 2},
+		[]float64{1, 2, 4},
+		[]float64{10, 20, 10},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		reducer ReducerID
+		param   float64
+		value   float64
+	}{
+		{name: "count", reducer: ReducerCount, value: 40},
+		{name: "sum", reducer: ReducerSum, value: 10*0.5 + 20*1.5 + 10*3},
+		{name: "median", reducer: ReducerQuantile, param: 0.5, value: 1.5},
+		{name: "quantile in the first bucket", reducer: ReducerQuantile, param: 0.1, value: 0.4},
+		{name: "quantile in the last bucket", reducer: ReducerQuantile, param: 0.95, value: 3.6},
+		{name: "quantile 0", reducer: ReducerQuantile, param: 0, value: 0},
+		{name: "quantile 1", reducer: ReducerQuantile, param: 1, value: 4},
+		{name: "quantile above 1", reducer: ReducerQuantile, param: 1.5, value: math.Inf(1)},
+		{name: "fraction above a bucket bound", reducer: ReducerFractionAbove, param: 2, value: 0.25},
+		{name: "fraction above a threshold within a bucket", reducer: ReducerFractionAbove, param: 1.5, value: 0.5},
+		{name: "fraction above the highest bound", reducer: ReducerFractionAbove, param: 5, value: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := h.Reduce("B", tt.reducer, tt.param, nil)
+			require.NoError(t, err)
+			require.Equal(t, data.Labels{"job": "api"}, n.GetLabels())
+			require.InDelta(t, tt.value, *n.GetFloat64Value(), 1e-9)
+		})
+	}
+
+	t.Run("empty histogram", func(t *testing.T) {
+		n, err := NewHistogram("count", nil, 0).Reduce("B", ReducerQuantile, 0.5, nil)
+		require.NoError(t, err)
+		require.True(t, math.IsNaN(*n.GetFloat64Value()))
+
+		n, err = NewHistogram("count", nil, 0).Reduce("B", ReducerQuantile, 0.5, DropNonNumber{})
+		require.NoError(t, err)
+		require.Nil(t, n.GetFloat64Value())
+	})
+
+	t.Run("series reducers error", func(t *testing.T) {
+		_, err := h.Reduce("B", ReducerLast, 0, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestHistogramReduceExemplars(t *testing.T) {
+	h, err := HistogramFromBuckets("count", nil, []float64{0, 1}, []float64{1, 2}, []float64{10, 10})
+	require.NoError(t, err)
+	h.Exemplars = Exemplars{
+		{Time: time.Unix(10, 0), Value: 0.5, TraceID: "fast"},
+		{Time: time.Unix(20, 0), Value: 1.85, TraceID: "slow"},
+		{Time: time.Unix(30, 0), Value: 1.9, TraceID: "slower"},
+		{Time: time.Unix(5, 0), Value: 1.9, TraceID: "slow"},
+	}
+
+	t.Run("keeps the exemplars above the quantile", func(t *testing.T) {
+		n, err := h.Reduce("B", ReducerQuantile, 0.9, nil)
+		require.NoError(t, err)
+		exemplars, ok := n.GetMeta().(Exemplars)
+		require.True(t, ok)
+		require.Equal(t, []string{"slower", "slow"}, exemplars.TraceIDs())
+	})
+
+	t.Run("keeps the exemplars above the threshold", func(t *testing.T) {
+		n, err := h.Reduce("B", ReducerFractionAbove, 1.85, nil)
+		require.NoError(t, err)
+		exemplars, ok := n.GetMeta().(Exemplars)
+		require.True(t, ok)
+		require.Equal(t, []string{"slower", "slow"}, exemplars.TraceIDs())
+	})
+
+	t.Run("keeps all the exemplars of the count", func(t *testing.T) {
+		n, err := h.Reduce("B", ReducerCount, 0, nil)
+		require.NoError(t, err)
+		exemplars, ok := n.GetMeta().(Exemplars)
+		require.True(t, ok)
+		require.Equal(t, []string{"slower", "slow", "fast"}, exemplars.TraceIDs())
+	})
+
+	t.Run("does not set meta without exemplars", func(t *testing.T) {
+		n, err := h.Reduce("B", ReducerQuantile, 0.99, nil)
+		require.NoError(t, err)
+		require.Nil(t, n.GetMeta())
+	})
+}
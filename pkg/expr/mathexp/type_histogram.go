@@ -0,0 +1,150 @@
+package mathexp
+
+import (
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// histogramTypeMinIdx is the data frame field index for the Histogram type's bucket lower bounds.
+const histogramTypeMinIdx = 0
+
+// histogramTypeMaxIdx is the data frame field index for the Histogram type's bucket upper bounds.
+const histogramTypeMaxIdx = 1
+
+// histogramTypeCountIdx is the data frame field index for the Histogram type's bucket counts.
+const histogramTypeCountIdx = 2
+
+// Histogram holds the buckets of a labelled histogram, such as a Prometheus native histogram
+// at the last step of the time range of a query. Each row is a bucket with its lower bound, its upper
+// bound and the number of observations in it, and rows are sorted by bounds.
+type Histogram struct {
+	Frame *data.Frame
+
+	// Exemplars are observations of the histogram that link to their traces.
+	Exemplars Exemplars
+}
+
+// NewHistogram returns a Histogram with size empty buckets.
+func NewHistogram(name string, labels data.Labels, size int) Histogram {
+	return Histogram{
+		Frame: data.NewFrame("",
+			data.NewField("yMin", nil, make([]float64, size)),
+			data.NewField("yMax", nil, make([]float64, size)),
+			data.NewField(name, labels, make([]float64, size)),
+		),
+	}
+}
+
+// Type returns the Value type and allows it to fulfill the Value interface.
+func (h Histogram) Type() parse.ReturnType { return parse.TypeHistogramSet }
+
+// Value returns the actual value allows it to fulfill the Value interface.
+func (h Histogram) Value() any { return &h }
+
+func (h Histogram) GetLabels() data.Labels { return h.Frame.Fields[histogramTypeCountIdx].Labels }
+
+func (h Histogram) SetLabels(ls data.Labels) { h.Frame.Fields[histogramTypeCountIdx].Labels = ls }
+
+func (h Histogram) GetMeta() any {
+	return h.Frame.Meta.Custom
+}
+
+func (h Histogram) SetMeta(v any) {
+	m := h.Frame.Meta
+	if m == nil {
+		m = &data.FrameMeta{}
+		h.Frame.SetMeta(m)
+	}
+	m.Custom = v
+}
+
+func (h Histogram) AddNotice(notice data.Notice) {
+	m := h.Frame.Meta
+	if m == nil {
+		m = &data.FrameMeta{}
+		h.Frame.SetMeta(m)
+	}
+	m.Notices = append(m.Notices, notice)
+}
+
+// AsDataFrame returns the underlying *data.Frame.
+func (h Histogram) AsDataFrame() *data.Frame { return h.Frame }
+
+// Len returns the number of buckets of the histogram.
+func (h Histogram) Len() int {
+	return h.Frame.Fields[histogramTypeCountIdx].Len()
+}
+
+// GetBucket returns the bounds and the count of the bucket at idx.
+func (h Histogram) GetBucket(idx int) (lower, upper, count float64) {
+	return h.Frame.Fields[histogramTypeMinIdx].At(idx).(float64),
+		h.Frame.Fields[histogramTypeMaxIdx].At(idx).(float64),
+		h.Frame.Fields[histogramTypeCountIdx].At(idx).(float64)
+}
+
+// SetBucket sets the bounds and the count of the bucket at idx.
+func (h Histogram) SetBucket(idx int, lower, upper, count float64) {
+	h.Frame.Fields[histogramTypeMinIdx].Set(idx, lower)
+	h.Frame.Fields[histogramTypeMaxIdx].Set(idx, upper)
+	h.Frame.Fields[histogramTypeCountIdx].Set(idx, count)
+}
+
+// Exemplar is an observation of a histogram that links to its trace.
+type Exemplar struct {
+	Time    time.Time   `json:"time"`
+	Value   float64     `json:"value"`
+	TraceID string      `json:"traceId"`
+	Labels  data.Labels `json:"labels,omitempty"`
+}
+
+// Exemplars is set as the meta of the numbers reduced from histograms with exemplars.
+type Exemplars []Exemplar
+
+// TraceIDs returns the distinct trace IDs of the exemplars, the most recent first.
+func (e Exemplars) TraceIDs() []string {
+	sorted := e.sortedByTime()
+	seen := make(map[string]struct{}, len(sorted))
+	ids := make([]string, 0, len(sorted))
+	for _, ex := range sorted {
+		if _, ok := seen[ex.TraceID]; ok || ex.TraceID == "" {
+			continue
+		}
+		seen[ex.TraceID] = struct{}{}
+		ids = append(ids, ex.TraceID)
+	}
+	return ids
+}
+
+// atLeast returns the exemplars of observations greater than or equal to v.
+func (e Exemplars) atLeast(v float64) Exemplars {
+	var filtered Exemplars
+	for _, ex := range e {
+		if ex.Value >= v {
+			filtered = append(filtered, ex)
+		}
+	}
+	return filtered
+}
+
+// latest returns the n most recent exemplars, the most recent first.
+func (e Exemplars) latest(n int) Exemplars {
+	sorted := e.sortedByTime()
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// sortedByTime returns a copy of the exemplars sorted by time, the most recent first.
+func (e Exemplars) sortedByTime() Exemplars {
+	sorted := make(Exemplars, len(e))
+	copy(sorted, e)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.After(sorted[j].Time)
+	})
+	return sorted
+}
@@ -90,6 +90,12 @@ type ReduceSettings struct {
 
 	// Only valid when mode is replace
 	ReplaceWithValue *float64 `json:"replaceWithValue,omitempty"`
+
+	// Only valid when reducer is quantile, between 0 and 1
+	Quantile *float64 `json:"quantile,omitempty"`
+
+	// Only valid when reducer is fraction_above
+	Threshold *float64 `json:"threshold,omitempty"`
 }
 
 // Non-Number behavior mode
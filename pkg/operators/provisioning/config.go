@@ -31,6 +31,7 @@ import (
 	gitrepo "github.com/grafana/grafana/apps/provisioning/pkg/repository/git"
 	githubrepo "github.com/grafana/grafana/apps/provisioning/pkg/repository/github"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository/local"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/bucket"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/controller"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/webhooks"
@@ -605,6 +606,12 @@ func (c *ControllerConfig) RepositoryExtras() ([]repository.Extra, error) {
 				return nil, fmt.Errorf("local_permitted_prefixes is required in [operator] section for local repository type")
 			}
 			extras = append(extras, local.Extra(homePath, permittedPrefixes))
+		case provisioning.BucketRepositoryType:
+			permittedURLs := provisioningSec.Key("permitted_bucket_urls").Strings("|")
+			if len(permittedURLs) == 0 {
+				return nil, fmt.Errorf("permitted_bucket_urls is required in [provisioning] section for bucket repository type")
+			}
+			extras = append(extras, bucket.Extra(permittedURLs))
 		default:
 			return nil, fmt.Errorf("unsupported repository type: %s", t)
 		}
@@ -1,10 +1,13 @@
 package provisioning
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/grafana/grafana/apps/provisioning/pkg/controller"
+	typedprovisioning "github.com/grafana/grafana/apps/provisioning/pkg/generated/clientset/versioned/typed/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/policy"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
@@ -15,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/migrate"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/move"
 	releaseresourcespkg "github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/releaseresources"
+	rollbackpkg "github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/rollback"
 	jobsync "github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/sync"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/webhooks/pullrequest"
@@ -105,7 +109,10 @@ func buildWorkers(cfg *setting.Cfg, controllerCfg *ControllerConfig, registry pr
 		return nil, nil, fmt.Errorf("failed to create provisioning client: %w", err)
 	}
 
-	repositoryResources := resources.NewRepositoryResourcesFactory(parsers, clients, resourceLister, folderMetadataEnabled, folderAPIVersion)
+	policies := policy.NewLister(func(context.Context) (typedprovisioning.ProvisioningV0alpha1Interface, error) {
+		return provisioningClient.ProvisioningV0alpha1(), nil
+	})
+	repositoryResources := resources.NewRepositoryResourcesFactory(parsers, clients, resourceLister, policies, folderMetadataEnabled, folderAPIVersion)
 	statusPatcher := controller.NewRepositoryStatusPatcher(provisioningClient.ProvisioningV0alpha1())
 
 	urlProvider, err := controllerCfg.URLProvider()
@@ -177,14 +184,18 @@ func buildWorkers(cfg *setting.Cfg, controllerCfg *ControllerConfig, registry pr
 	// Delete Resources (orphan cleanup — deletes managed resources)
 	deleteResourcesWorker := deleteresourcespkg.NewWorker(resourceLister, clients, 10)
 
+	// Rollback (restores the resources changed by a finished job)
+	rollbackWorker := rollbackpkg.NewWorker(jobs.NewAPIClientHistoryReader(provisioningClient.ProvisioningV0alpha1()), clients, syncWorker, stageIfPossible, metrics)
+
 	// PullRequest
 	renderer := pullrequest.NewNoOpRenderer()
-	evaluator := pullrequest.NewEvaluator(renderer, parsers, pullrequest.URLProvider{
+	evaluator := pullrequest.NewEvaluator(renderer, parsers, policies, pullrequest.URLProvider{
 		Internal: urlProvider,
 		Public:   urlProvider,
 	}, registry)
 	commenter := pullrequest.NewCommenter(false)
-	prWorker := pullrequest.NewPullRequestWorker(evaluator, commenter, registry)
+	previewer := pullrequest.NewPreviewer(clients, folderAPIVersion, cfg.ProvisioningPreviewNamespace)
+	prWorker := pullrequest.NewPullRequestWorker(evaluator, commenter, previewer, registry)
 
 	workers := []jobs.Worker{
 		syncWorker,
@@ -195,6 +206,7 @@ func buildWorkers(cfg *setting.Cfg, controllerCfg *ControllerConfig, registry pr
 		fixMetadataWorker,
 		releaseResourcesWorker,
 		deleteResourcesWorker,
+		rollbackWorker,
 		prWorker,
 	}
 
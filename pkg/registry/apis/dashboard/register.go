@@ -69,6 +69,7 @@ import (
 	"github.com/grafana/grafana/pkg/storage/legacysql"
 	"github.com/grafana/grafana/pkg/storage/legacysql/dualwrite"
 	"github.com/grafana/grafana/pkg/storage/unified/apistore"
+	"github.com/grafana/grafana/pkg/storage/unified/federated"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
 	resourcepb "github.com/grafana/grafana/pkg/storage/unified/resourcepb"
 )
@@ -174,6 +175,8 @@ func RegisterAPIService(
 
 	dbp := legacysql.NewDatabaseProvider(sql)
 	namespacer := request.GetNamespaceMapper(cfg)
+	search := NewSearchHandler(tracing, unified, features)
+	search.crossNamespace = federated.NewCrossNamespaceSearcher(unified, &federated.LegacyNamespaceLister{SQL: dbp, Cfg: cfg})
 	folderClient := client.NewK8sHandler(request.GetNamespaceMapper(cfg), folders.FolderResourceInfo.GroupVersionResource(), restConfigProvider.GetRestConfig, userService, unified)
 	dashboardClient := client.NewK8sHandler(namespacer, dashv1.DashboardResourceInfo.GroupVersionResource(), restConfigProvider.GetRestConfig, userService, unified)
 
@@ -194,7 +197,7 @@ func RegisterAPIService(
 		accessControl:            accessControl,
 		accessClient:             accessClient,
 		unified:                  unified,
-		search:                   NewSearchHandler(tracing, unified, features),
+		search:                   search,
 		QuotaService:             quotaService,
 		ProvisioningService:      provisioning,
 		minRefreshInterval:       cfg.MinRefreshInterval,
@@ -1354,6 +1357,20 @@ func (b *DashboardsAPIBuilder) PostProcessOpenAPI(oas *spec3.OpenAPI) (*spec3.Op
 				},
 			},
 		}
+
+		if p, ok := oas.Paths.Paths["/apis/dashboard.grafana.app/v0alpha1/admin/search"]; ok {
+			p.Get.Responses.StatusCodeResponses[200] = &spec3.Response{
+				ResponseProps: spec3.ResponseProps{
+					Content: map[string]*spec3.MediaType{
+						"application/json": {
+							MediaTypeProps: spec3.MediaTypeProps{
+								Schema: crossNamespaceSearchResultsSchema(*spec.RefSchema("#/components/schemas/SearchResults")),
+							},
+						},
+					},
+				},
+			}
+		}
 	}
 
 	return oas, nil
@@ -1372,6 +1389,7 @@ func (b *DashboardsAPIBuilder) GetAPIRoutes(gv schema.GroupVersion) *builder.API
 		}, b.dashboardService)
 
 	return &builder.APIRoutes{
+		Root:      searchAPIRoutes.Root,
 		Namespace: append(searchAPIRoutes.Namespace, snapshotAPIRoutes.Namespace...),
 	}
 }
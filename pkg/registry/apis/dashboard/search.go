@@ -36,6 +36,7 @@ import (
 	dashboardsearch "github.com/grafana/grafana/pkg/services/dashboards/service/search"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	foldermodel "github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/storage/unified/federated"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
 	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
 	"github.com/grafana/grafana/pkg/storage/unified/search/builders"
@@ -48,6 +49,9 @@ type SearchHandler struct {
 	client   resourcepb.ResourceIndexClient
 	tracer   trace.Tracer
 	features featuremgmt.FeatureToggles
+
+	// Searches several namespaces at once for server admins (optional)
+	crossNamespace *federated.CrossNamespaceSearcher
 }
 
 func NewSearchHandler(tracer trace.Tracer, resourceClient resource.ResourceClient, features featuremgmt.FeatureToggles) *SearchHandler {
@@ -334,6 +338,114 @@ func (s *SearchHandler) GetAPIRoutes(defs map[string]common.OpenAPIDefinition) *
 		},
 	}
 
+	// Server admins can search every namespace of the instance at once
+	if s.crossNamespace != nil {
+		routes.Root = append(routes.Root, builder.APIRouteHandler{
+			Path: "admin/search",
+			Spec: &spec3.PathProps{
+				Get: &spec3.Operation{
+					OperationProps: spec3.OperationProps{
+						Tags:        []string{"Search"},
+						OperationId: "searchDashboardsAndFoldersInAllNamespaces",
+						Description: "Dashboard search across namespaces. Only available to server admins",
+						Parameters: []*spec3.Parameter{
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "namespace",
+									In:          "query",
+									Description: "namespaces to search. When empty, every namespace is searched",
+									Required:    false,
+									Schema:      spec.ArrayProperty(spec.StringProperty()),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "query",
+									In:          "query",
+									Description: "user query string",
+									Required:    false,
+									Schema:      spec.StringProperty(),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "type",
+									In:          "query",
+									Description: "search dashboards or folders.  When empty, this will search both",
+									Required:    false,
+									Schema:      spec.StringProperty().WithEnum("folder", "dashboard"),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "facet",
+									In:          "query",
+									Description: "count distinct terms for selected fields",
+									Required:    false,
+									Schema:      spec.ArrayProperty(spec.StringProperty()),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "tag",
+									In:          "query",
+									Description: "tag query filter",
+									Required:    false,
+									Schema:      spec.ArrayProperty(spec.StringProperty()),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "sort",
+									In:          "query",
+									Description: "sortable field",
+									Required:    false,
+									Schema:      spec.StringProperty(),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "limit",
+									In:          "query",
+									Description: "number of results to return",
+									Required:    false,
+									Schema:      spec.Int64Property(),
+								},
+							},
+							{
+								ParameterProps: spec3.ParameterProps{
+									Name:        "offset",
+									In:          "query",
+									Description: "number of results to skip",
+									Required:    false,
+									Schema:      spec.Int64Property(),
+								},
+							},
+						},
+						Responses: &spec3.Responses{
+							ResponsesProps: spec3.ResponsesProps{
+								StatusCodeResponses: map[int]*spec3.Response{
+									200: {
+										ResponseProps: spec3.ResponseProps{
+											Content: map[string]*spec3.MediaType{
+												"application/json": {
+													MediaTypeProps: spec3.MediaTypeProps{
+														Schema: crossNamespaceSearchResultsSchema(searchResults),
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Handler: s.DoCrossNamespaceSearch,
+		})
+	}
+
 	// Semantic (vector) search is still experimental, so it's only registered —
 	// and therefore only present in the OpenAPI spec — when the feature toggle
 	// is enabled.
@@ -516,6 +628,102 @@ func (s *SearchHandler) DoSearch(w http.ResponseWriter, r *http.Request) {
 	s.write(w, parsedResults)
 }
 
+// CrossNamespaceSearchResults holds the hits of every namespace a server admin searched.
+// The namespace of each hit is set in its fields.
+type CrossNamespaceSearchResults struct {
+	Results dashboardv0alpha1.SearchResults `json:"results"`
+
+	// Namespaces that were searched
+	Searched []string `json:"searched"`
+
+	// Namespaces that failed, with the reason
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+func crossNamespaceSearchResultsSchema(searchResults spec.Schema) *spec.Schema {
+	return &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type:     []string{"object"},
+			Required: []string{"results", "searched"},
+			Properties: map[string]spec.Schema{
+				"results":  searchResults,
+				"searched": *spec.ArrayProperty(spec.StringProperty()),
+				"errors":   *spec.MapProperty(spec.StringProperty()),
+			},
+		},
+	}
+}
+
+// DoCrossNamespaceSearch runs a dashboard search in several namespaces, or all of them,
+// and merges the hits. Only server admins can use it, and they see every namespace.
+// Namespaces that fail are listed with their error, and the hits of the others are returned.
+func (s *SearchHandler) DoCrossNamespaceSearch(w http.ResponseWriter, r *http.Request) {
+	ctx, span := s.tracer.Start(r.Context(), "dashboard.crossNamespaceSearch")
+	defer span.End()
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	if !user.GetIsGrafanaAdmin() {
+		errhttp.Write(ctx, apierrors.NewForbidden(dashboardv0alpha1.DashboardResourceInfo.GroupResource(), "",
+			errors.New("only server admins can search across namespaces")), w)
+		return
+	}
+
+	queryParams, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+
+	searchRequest, err := convertHttpSearchRequestToResourceSearchRequest(queryParams, user, func(dashboardaccess.PermissionType) ([]string, error) {
+		return nil, apierrors.NewBadRequest("shared with me is not supported across namespaces")
+	})
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	// Results are merged across namespaces, so only limit and offset are supported
+	searchRequest.Page = 0
+
+	result, err := s.crossNamespace.Search(ctx, &federated.CrossNamespaceSearchRequest{
+		Namespaces: queryParams["namespace"],
+		Request:    searchRequest,
+	})
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	if result.Error != nil {
+		errhttp.Write(ctx, resource.GetError(result.Error), w)
+		return
+	}
+
+	parsedResults, err := dashboardsearch.ParseResults(result.ResourceSearchResponse, searchRequest.Offset)
+	if err != nil {
+		errhttp.Write(ctx, err, w)
+		return
+	}
+	// Hits are in the same order as the rows
+	for i, row := range result.Results.Rows {
+		parsedResults.Hits[i].Field.Set("namespace", row.Key.Namespace)
+	}
+
+	rsp := CrossNamespaceSearchResults{
+		Results:  parsedResults,
+		Searched: result.Searched,
+	}
+	if len(result.Errors) > 0 {
+		rsp.Errors = make(map[string]string, len(result.Errors))
+		for ns, e := range result.Errors {
+			rsp.Errors[ns] = e.Message
+		}
+	}
+	s.write(w, rsp)
+}
+
 // DoVectorSearch serves the semantic (vector) search endpoint. It is registered
 // only when the dashboardVectorSearch feature toggle is enabled. Unlike lexical
 // search it does not fall back: if the vector backend isn't configured the
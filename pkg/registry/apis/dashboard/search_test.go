@@ -25,6 +25,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/storage/unified/federated"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
 	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
 )
@@ -47,6 +48,61 @@ func TestSearch(t *testing.T) {
 	})
 }
 
+func TestCrossNamespaceSearch(t *testing.T) {
+	newHandler := func(client *MockClient) *SearchHandler {
+		h := NewSearchHandler(tracing.NewNoopTracerService(), client, nil)
+		h.crossNamespace = federated.NewCrossNamespaceSearcher(client, nil)
+		return h
+	}
+
+	t.Run("only server admins can search across namespaces", func(t *testing.T) {
+		mockClient := &MockClient{}
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/admin/search?namespace=org-2", nil)
+		req = req.WithContext(identity.WithRequester(req.Context(), &user.SignedInUser{Namespace: "default", OrgRole: identity.RoleAdmin}))
+
+		newHandler(mockClient).DoCrossNamespaceSearch(rr, req)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+		require.Nil(t, mockClient.LastSearchRequest)
+	})
+
+	t.Run("returns the namespace of each hit", func(t *testing.T) {
+		mockClient := &MockClient{
+			MockResponses: []*resourcepb.ResourceSearchResponse{{
+				TotalHits: 1,
+				Results: &resourcepb.ResourceTable{
+					Columns: []*resourcepb.ResourceTableColumnDefinition{
+						{Name: resource.SEARCH_FIELD_TITLE},
+					},
+					Rows: []*resourcepb.ResourceTableRow{{
+						Key: &resourcepb.ResourceKey{
+							Namespace: "org-2",
+							Group:     "dashboard.grafana.app",
+							Resource:  "dashboards",
+							Name:      "d1",
+						},
+						Cells: [][]byte{[]byte("Dashboard 1")},
+					}},
+				},
+			}},
+		}
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/admin/search?namespace=org-2&query=dash", nil)
+		req = req.WithContext(identity.WithRequester(req.Context(), &user.SignedInUser{Namespace: "default", IsGrafanaAdmin: true}))
+
+		newHandler(mockClient).DoCrossNamespaceSearch(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "org-2", mockClient.LastSearchRequest.Options.Key.Namespace)
+
+		rsp := &CrossNamespaceSearchResults{}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(rsp))
+		require.Equal(t, []string{"org-2"}, rsp.Searched)
+		require.Len(t, rsp.Results.Hits, 1)
+		require.Equal(t, "Dashboard 1", rsp.Results.Hits[0].Title)
+		require.Equal(t, "org-2", rsp.Results.Hits[0].Field.Object["namespace"])
+	})
+}
+
 func TestVectorSearch(t *testing.T) {
 	newHandler := func(client *MockClient) SearchHandler {
 		return SearchHandler{
@@ -0,0 +1,871 @@
+// Package bucket implements a provisioning repository on top of an object
+// storage bucket, using the same CDK bucket abstraction as unified storage.
+//
+// Buckets have no commits, so a ref is the hash of a listing: every key under
+// the configured path together with the object version (the MD5 when the
+// driver reports one, otherwise the ETag). The controller polls that ref and
+// only starts a sync when an object was added, removed or rewritten. The sync
+// itself compares object versions with the hashes saved on the resources, so
+// unchanged objects are never read.
+//
+// Buckets do not keep previous object versions either, so the content read
+// during a sync is kept per object version. Incremental sync compares two
+// remembered listings by object version, and reads the previous version of
+// rewritten and removed objects from that content. When a listing or a
+// previous version is no longer known, a full sync runs instead.
+package bucket
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+// keepFile marks a directory in the bucket, since object storage has none
+const keepFile = ".keep"
+
+// maxSnapshots is how many listings are remembered per repository to serve reads at a ref
+const maxSnapshots = 8
+
+// maxContentBytes is how much object content is kept per repository to read previous versions
+const maxContentBytes = 16 << 20
+
+var (
+	_ repository.Repository        = (*bucketRepository)(nil)
+	_ repository.ReaderWriter      = (*bucketRepository)(nil)
+	_ repository.Versioned         = (*bucketRepository)(nil)
+	_ repository.SizeLimitedReader = (*bucketRepository)(nil)
+)
+
+type bucketRepository struct {
+	config *provisioning.Repository
+	bucket resource.CDKBucket
+	state  *bucketState
+
+	// set when the bucket could not be opened, reported by Test
+	openErr error
+	// key prefix with a trailing slash, or empty for the whole bucket
+	prefix   string
+	maxBytes atomic.Int64
+}
+
+// NewRepository creates a repository reading from and writing to the given bucket.
+// The state is shared by every repository built for the same configuration.
+func NewRepository(config *provisioning.Repository, bucket resource.CDKBucket, state *bucketState) *bucketRepository {
+	r := &bucketRepository{
+		config: config,
+		bucket: bucket,
+		state:  state,
+	}
+	if state == nil {
+		r.state = newBucketState()
+	}
+	if config.Spec.Bucket != nil {
+		r.prefix = safepath.EnsureTrailingSlash(safepath.Clean(config.Spec.Bucket.Path))
+	}
+	return r
+}
+
+func (r *bucketRepository) Config() *provisioning.Repository {
+	return r.config
+}
+
+// Test implements provisioning.Repository.
+// NOTE: Validate has been called (and passed) before this function should be called
+func (r *bucketRepository) Test(ctx context.Context) (*provisioning.TestResults, error) {
+	path := field.NewPath("spec", "bucket", "url")
+	if r.config.Spec.Bucket == nil || r.config.Spec.Bucket.URL == "" {
+		return repository.FromFieldError(field.Required(path, "no bucket url is configured")), nil
+	}
+	if r.openErr != nil {
+		return repository.FromFieldError(field.Invalid(path, r.config.Spec.Bucket.URL, r.openErr.Error())), nil
+	}
+
+	if _, _, err := r.bucket.ListPage(ctx, blob.FirstPageToken, 1, &blob.ListOptions{Prefix: r.prefix}); err != nil {
+		return repository.FromFieldError(field.Invalid(path, r.config.Spec.Bucket.URL, fmt.Sprintf("unable to list the bucket: %s", err))), nil
+	}
+
+	return &provisioning.TestResults{
+		Code:    http.StatusOK,
+		Success: true,
+	}, nil
+}
+
+func (r *bucketRepository) WithMaxFileSize(maxBytes int64) {
+	r.maxBytes.Store(maxBytes)
+}
+
+func (r *bucketRepository) key(filePath string) string {
+	return r.prefix + filePath
+}
+
+func (r *bucketRepository) ready() error {
+	if r.openErr != nil {
+		return apierrors.NewServiceUnavailable(fmt.Sprintf("bucket is not available: %s", r.openErr))
+	}
+	if r.bucket == nil {
+		return apierrors.NewServiceUnavailable("bucket is not configured")
+	}
+	return nil
+}
+
+// Read implements repository.Reader.
+// The ref is either a listing returned by LatestRef, or the version of the object itself.
+func (r *bucketRepository) Read(ctx context.Context, filePath, ref string) (*repository.FileInfo, error) {
+	if err := r.ready(); err != nil {
+		return nil, err
+	}
+
+	if safepath.IsDir(filePath) {
+		found, err := r.exists(ctx, r.key(filePath))
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, repository.ErrFileNotFound
+		}
+		return &repository.FileInfo{Path: filePath, Ref: ref}, nil
+	}
+
+	// The content of a remembered listing may be kept, even after the object was rewritten
+	if snap := r.state.snapshot(ref); snap != nil {
+		if kept := r.state.content(filePath, snap.objects[filePath]); kept != nil {
+			return &repository.FileInfo{
+				Path:     filePath,
+				Data:     kept.data,
+				Ref:      ref,
+				Hash:     kept.version,
+				Modified: &metav1.Time{Time: kept.modTime},
+			}, nil
+		}
+	}
+
+	attrs, err := r.bucket.Attributes(ctx, r.key(filePath))
+	if err != nil {
+		return nil, mapBucketError(err)
+	}
+
+	version := objectVersion(attrs.MD5, attrs.ETag)
+	if err := r.checkRef(ctx, filePath, ref, version); err != nil {
+		return nil, err
+	}
+
+	if max := r.maxBytes.Load(); max > 0 && attrs.Size > max {
+		return nil, apierrors.NewRequestEntityTooLargeError(
+			fmt.Sprintf("file %q is %d bytes; max allowed is %d bytes", filePath, attrs.Size, max),
+		)
+	}
+
+	data, err := r.bucket.ReadAll(ctx, r.key(filePath))
+	if err != nil {
+		return nil, mapBucketError(err)
+	}
+	r.state.keepContent(filePath, version, data, attrs.ModTime)
+
+	return &repository.FileInfo{
+		Path:     filePath,
+		Data:     data,
+		Ref:      ref,
+		Hash:     version,
+		Modified: &metav1.Time{Time: attrs.ModTime},
+	}, nil
+}
+
+// checkRef makes sure the object read now is the one the ref points to
+func (r *bucketRepository) checkRef(ctx context.Context, filePath, ref, version string) error {
+	if ref == "" || ref == version {
+		return nil
+	}
+
+	snap, err := r.snapshot(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	expected, ok := snap.objects[filePath]
+	if !ok {
+		return repository.ErrFileNotFound
+	}
+	if expected != version {
+		return fmt.Errorf("%w: %s was rewritten after %s", repository.ErrRefNotFound, filePath, ref)
+	}
+	return nil
+}
+
+// snapshot returns a known listing, or lists the bucket again to see if it is the current one
+func (r *bucketRepository) snapshot(ctx context.Context, ref string) (*snapshot, error) {
+	if snap := r.state.snapshot(ref); snap != nil {
+		return snap, nil
+	}
+
+	snap, err := r.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if snap.ref != ref {
+		return nil, repository.ErrRefNotFound
+	}
+	return snap, nil
+}
+
+// ReadTree implements repository.Reader.
+// Directories are derived from the object keys.
+func (r *bucketRepository) ReadTree(ctx context.Context, ref string) ([]repository.FileTreeEntry, error) {
+	if err := r.ready(); err != nil {
+		return nil, err
+	}
+
+	snap, err := r.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ref != "" && ref != snap.ref {
+		if r.state.snapshot(ref) == nil {
+			return nil, repository.ErrRefNotFound
+		}
+		// The objects of an older listing may already be gone
+		return nil, fmt.Errorf("%w: the bucket changed after %s", repository.ErrRefNotFound, ref)
+	}
+
+	return snap.tree(), nil
+}
+
+// list reads every object under the prefix and remembers the listing
+func (r *bucketRepository) list(ctx context.Context) (*snapshot, error) {
+	objects := make(map[string]string)
+	iter := r.bucket.List(&blob.ListOptions{Prefix: r.prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list bucket: %w", mapBucketError(err))
+		}
+
+		filePath := strings.TrimPrefix(obj.Key, r.prefix)
+		if obj.IsDir || filePath == "" || safepath.IsDir(filePath) {
+			continue
+		}
+
+		version, err := r.version(ctx, filePath, obj)
+		if err != nil {
+			return nil, err
+		}
+		objects[filePath] = version
+	}
+
+	snap := newSnapshot(objects)
+	r.state.add(snap)
+	return snap, nil
+}
+
+// version returns the object version, reading the ETag when the listing has no MD5
+func (r *bucketRepository) version(ctx context.Context, filePath string, obj *blob.ListObject) (string, error) {
+	if len(obj.MD5) > 0 {
+		return objectVersion(obj.MD5, ""), nil
+	}
+
+	if version, ok := r.state.cachedVersion(filePath, obj.ModTime, obj.Size); ok {
+		return version, nil
+	}
+
+	attrs, err := r.bucket.Attributes(ctx, obj.Key)
+	if err != nil {
+		return "", fmt.Errorf("read attributes of %s: %w", obj.Key, mapBucketError(err))
+	}
+
+	version := objectVersion(attrs.MD5, attrs.ETag)
+	r.state.cacheVersion(filePath, obj.ModTime, obj.Size, version)
+	return version, nil
+}
+
+func (r *bucketRepository) exists(ctx context.Context, prefix string) (bool, error) {
+	found, _, err := r.bucket.ListPage(ctx, blob.FirstPageToken, 1, &blob.ListOptions{Prefix: prefix})
+	if err != nil {
+		return false, mapBucketError(err)
+	}
+	return len(found) > 0, nil
+}
+
+// validateWriteRef rejects writes to anything but the current content of the bucket
+func (r *bucketRepository) validateWriteRef(ref string) error {
+	if err := r.ready(); err != nil {
+		return err
+	}
+	if ref != "" {
+		return apierrors.NewBadRequest("bucket repository does not support writing to a ref")
+	}
+	return nil
+}
+
+func (r *bucketRepository) Create(ctx context.Context, filePath, ref string, data []byte, comment string) error {
+	if err := r.validateWriteRef(ref); err != nil {
+		return err
+	}
+
+	if safepath.IsDir(filePath) {
+		if data != nil {
+			return apierrors.NewBadRequest("data cannot be provided for a directory")
+		}
+		filePath = safepath.Join(filePath, keepFile)
+		data = []byte{}
+	}
+
+	found, err := r.exists(ctx, r.key(filePath))
+	if err != nil {
+		return err
+	}
+	if found {
+		return repository.ErrFileAlreadyExists
+	}
+
+	return r.write(ctx, filePath, data)
+}
+
+func (r *bucketRepository) Update(ctx context.Context, filePath, ref string, data []byte, comment string) error {
+	if err := r.validateWriteRef(ref); err != nil {
+		return err
+	}
+
+	if safepath.IsDir(filePath) {
+		return apierrors.NewBadRequest("cannot update a directory")
+	}
+
+	if _, err := r.bucket.Attributes(ctx, r.key(filePath)); err != nil {
+		return mapBucketError(err)
+	}
+
+	return r.write(ctx, filePath, data)
+}
+
+func (r *bucketRepository) Write(ctx context.Context, filePath, ref string, data []byte, comment string) error {
+	if err := r.validateWriteRef(ref); err != nil {
+		return err
+	}
+
+	if safepath.IsDir(filePath) {
+		found, err := r.exists(ctx, r.key(filePath))
+		if err != nil || found {
+			return err
+		}
+		return r.write(ctx, safepath.Join(filePath, keepFile), []byte{})
+	}
+
+	return r.write(ctx, filePath, data)
+}
+
+func (r *bucketRepository) write(ctx context.Context, filePath string, data []byte) error {
+	if err := r.bucket.WriteAll(ctx, r.key(filePath), data, nil); err != nil {
+		return fmt.Errorf("write %s: %w", filePath, mapBucketError(err))
+	}
+	return nil
+}
+
+func (r *bucketRepository) Delete(ctx context.Context, filePath, ref, comment string) error {
+	if err := r.validateWriteRef(ref); err != nil {
+		return err
+	}
+
+	if !safepath.IsDir(filePath) {
+		if err := r.bucket.Delete(ctx, r.key(filePath)); err != nil {
+			return mapBucketError(err)
+		}
+		return nil
+	}
+
+	// if it is a folder, delete all of its contents
+	keys, err := r.keys(ctx, r.key(filePath))
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return repository.ErrFileNotFound
+	}
+	for _, key := range keys {
+		if err := r.bucket.Delete(ctx, key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+			return fmt.Errorf("delete %s: %w", key, mapBucketError(err))
+		}
+	}
+	return nil
+}
+
+func (r *bucketRepository) Move(ctx context.Context, oldPath, newPath, ref, comment string) error {
+	if err := r.validateWriteRef(ref); err != nil {
+		return err
+	}
+
+	if safepath.IsDir(oldPath) != safepath.IsDir(newPath) {
+		return apierrors.NewBadRequest("cannot move between file and directory types")
+	}
+
+	sources := []string{r.key(oldPath)}
+	if safepath.IsDir(oldPath) {
+		var err error
+		if sources, err = r.keys(ctx, r.key(oldPath)); err != nil {
+			return err
+		}
+		if len(sources) == 0 {
+			return repository.ErrFileNotFound
+		}
+	} else if _, err := r.bucket.Attributes(ctx, sources[0]); err != nil {
+		return mapBucketError(err)
+	}
+
+	found, err := r.exists(ctx, r.key(newPath))
+	if err != nil {
+		return err
+	}
+	if found {
+		return repository.ErrFileAlreadyExists
+	}
+
+	// Buckets have no rename: copy everything first, so a failure never loses data
+	for _, source := range sources {
+		data, err := r.bucket.ReadAll(ctx, source)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", source, mapBucketError(err))
+		}
+		target := r.key(newPath) + strings.TrimPrefix(source, r.key(oldPath))
+		if err := r.bucket.WriteAll(ctx, target, data, nil); err != nil {
+			return fmt.Errorf("write %s: %w", target, mapBucketError(err))
+		}
+	}
+	for _, source := range sources {
+		if err := r.bucket.Delete(ctx, source); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+			return fmt.Errorf("delete %s: %w", source, mapBucketError(err))
+		}
+	}
+	return nil
+}
+
+// keys lists every object key under the prefix
+func (r *bucketRepository) keys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	iter := r.bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return keys, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list bucket: %w", mapBucketError(err))
+		}
+		if !obj.IsDir {
+			keys = append(keys, obj.Key)
+		}
+	}
+}
+
+// History implements repository.Versioned.
+// Buckets have no commits, so the history is every remembered listing in
+// which the object, or anything under the path, was added, rewritten or removed.
+func (r *bucketRepository) History(ctx context.Context, filePath, ref string) ([]provisioning.HistoryItem, error) {
+	if err := r.ready(); err != nil {
+		return nil, err
+	}
+
+	// List the bucket so the current content is part of the history
+	if _, err := r.list(ctx); err != nil {
+		return nil, err
+	}
+
+	var (
+		items    []provisioning.HistoryItem
+		previous *snapshot
+	)
+	for _, snap := range r.state.listings() {
+		if message := snap.changeOf(previous, filePath); message != "" {
+			items = append(items, provisioning.HistoryItem{
+				Ref:       snap.ref,
+				Message:   message,
+				Authors:   []provisioning.Author{},
+				CreatedAt: snap.listed.UnixMilli(),
+			})
+		}
+		previous = snap
+		if snap.ref == ref {
+			break
+		}
+	}
+	if ref != "" && (previous == nil || previous.ref != ref) {
+		return nil, repository.ErrRefNotFound
+	}
+
+	// Newest first, like the history of a git repository
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+	return items, nil
+}
+
+// ListRefs implements repository.Versioned.
+// Buckets have no named refs.
+func (r *bucketRepository) ListRefs(_ context.Context) ([]provisioning.RefItem, error) {
+	return []provisioning.RefItem{}, nil
+}
+
+// LatestRef implements repository.Versioned.
+func (r *bucketRepository) LatestRef(ctx context.Context) (string, error) {
+	if err := r.ready(); err != nil {
+		return "", err
+	}
+
+	snap, err := r.list(ctx)
+	if err != nil {
+		return "", err
+	}
+	return snap.ref, nil
+}
+
+// CompareFiles implements repository.Versioned.
+// Two remembered listings are compared by object version. Incremental sync
+// reads the previous version of rewritten and removed objects, so the changes
+// are left to a full sync when that content is not kept.
+func (r *bucketRepository) CompareFiles(ctx context.Context, base, ref string) ([]repository.VersionedFileChange, error) {
+	if base == ref {
+		return []repository.VersionedFileChange{}, nil
+	}
+	if err := r.ready(); err != nil {
+		return nil, err
+	}
+
+	from := r.state.snapshot(base)
+	if from == nil {
+		return nil, repository.ErrIncrementalSyncNotSupported
+	}
+
+	var (
+		to  *snapshot
+		err error
+	)
+	if ref == "" {
+		to, err = r.list(ctx)
+	} else {
+		to, err = r.snapshot(ctx, ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]repository.VersionedFileChange, 0)
+	for filePath, version := range to.objects {
+		previous, ok := from.objects[filePath]
+		switch {
+		case !ok:
+			changes = append(changes, repository.VersionedFileChange{
+				Action: repository.FileActionCreated,
+				Path:   filePath,
+				Ref:    to.ref,
+			})
+		case previous != version:
+			if r.state.content(filePath, previous) == nil {
+				return nil, repository.ErrIncrementalSyncNotSupported
+			}
+			changes = append(changes, repository.VersionedFileChange{
+				Action:      repository.FileActionUpdated,
+				Path:        filePath,
+				Ref:         to.ref,
+				PreviousRef: from.ref,
+			})
+		}
+	}
+	for filePath, previous := range from.objects {
+		if _, ok := to.objects[filePath]; ok {
+			continue
+		}
+		if r.state.content(filePath, previous) == nil {
+			return nil, repository.ErrIncrementalSyncNotSupported
+		}
+		changes = append(changes, repository.VersionedFileChange{
+			Action:      repository.FileActionDeleted,
+			Path:        filePath,
+			Ref:         to.ref,
+			PreviousRef: from.ref,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// objectVersion identifies the content of an object. The MD5 is preferred, as
+// drivers report it both when listing and when reading attributes.
+func objectVersion(md5 []byte, etag string) string {
+	if len(md5) > 0 {
+		return hex.EncodeToString(md5)
+	}
+	return strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+}
+
+func mapBucketError(err error) error {
+	switch gcerrors.Code(err) {
+	case gcerrors.NotFound:
+		return repository.ErrFileNotFound
+	case gcerrors.PermissionDenied:
+		return repository.ErrPermissionDenied
+	case gcerrors.Unauthenticated:
+		return repository.ErrUnauthorized
+	default:
+		return err
+	}
+}
+
+// snapshot is the listing of a bucket at one point in time
+type snapshot struct {
+	ref    string
+	listed time.Time
+	// path -> object version
+	objects map[string]string
+}
+
+func newSnapshot(objects map[string]string) *snapshot {
+	paths := make([]string, 0, len(objects))
+	for p := range objects {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	hasher := sha256.New()
+	for _, p := range paths {
+		_, _ = fmt.Fprintf(hasher, "%s\x00%s\n", p, objects[p])
+	}
+
+	return &snapshot{
+		ref:     hex.EncodeToString(hasher.Sum(nil)),
+		listed:  time.Now(),
+		objects: objects,
+	}
+}
+
+// changeOf describes what changed under the path since the previous listing, if anything
+func (s *snapshot) changeOf(previous *snapshot, filePath string) string {
+	var added, rewritten, removed int
+	for p, version := range s.objects {
+		if !underPath(p, filePath) {
+			continue
+		}
+		if previous == nil {
+			added++
+			continue
+		}
+		before, ok := previous.objects[p]
+		switch {
+		case !ok:
+			added++
+		case before != version:
+			rewritten++
+		}
+	}
+	if previous != nil {
+		for p := range previous.objects {
+			if _, ok := s.objects[p]; !ok && underPath(p, filePath) {
+				removed++
+			}
+		}
+	}
+
+	var parts []string
+	if added > 0 {
+		parts = append(parts, fmt.Sprintf("%d added", added))
+	}
+	if rewritten > 0 {
+		parts = append(parts, fmt.Sprintf("%d rewritten", rewritten))
+	}
+	if removed > 0 {
+		parts = append(parts, fmt.Sprintf("%d removed", removed))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// underPath is true for the file itself, or anything under a directory path
+func underPath(p, filePath string) bool {
+	if filePath == "" {
+		return true
+	}
+	if safepath.IsDir(filePath) {
+		return strings.HasPrefix(p, filePath)
+	}
+	return p == filePath
+}
+
+// tree returns the objects, and every directory that contains them
+func (s *snapshot) tree() []repository.FileTreeEntry {
+	dirs := make(map[string]struct{})
+	entries := make([]repository.FileTreeEntry, 0, len(s.objects))
+	for p, version := range s.objects {
+		entries = append(entries, repository.FileTreeEntry{Path: p, Hash: version, Blob: true})
+		for dir := safepath.Dir(p); dir != ""; dir = safepath.Dir(dir) {
+			if _, ok := dirs[dir]; ok {
+				break
+			}
+			dirs[dir] = struct{}{}
+			entries = append(entries, repository.FileTreeEntry{Path: dir})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries
+}
+
+type cachedVersion struct {
+	modTime time.Time
+	size    int64
+	version string
+}
+
+// keptContent is the content of an object version that was read
+type keptContent struct {
+	version string
+	data    []byte
+	modTime time.Time
+}
+
+// bucketState is kept across builds of the same repository
+type bucketState struct {
+	mu        sync.Mutex
+	snapshots []*snapshot
+	// path -> version read from the attributes, for drivers that list no MD5
+	versions map[string]cachedVersion
+	// path and version -> content, to read previous versions
+	contents     map[string]*keptContent
+	contentBytes int
+	// last build on this state, guarded by the extra
+	used time.Time
+}
+
+func newBucketState() *bucketState {
+	return &bucketState{
+		versions: make(map[string]cachedVersion),
+		contents: make(map[string]*keptContent),
+	}
+}
+
+func contentKey(filePath, version string) string {
+	return filePath + "\x00" + version
+}
+
+func (s *bucketState) add(snap *snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.snapshots {
+		if existing.ref == snap.ref {
+			s.snapshots = append(s.snapshots[:i], s.snapshots[i+1:]...)
+			break
+		}
+	}
+	s.snapshots = append(s.snapshots, snap)
+	if len(s.snapshots) > maxSnapshots {
+		s.snapshots = s.snapshots[len(s.snapshots)-maxSnapshots:]
+	}
+
+	// Forget versions of objects that are gone
+	for filePath := range s.versions {
+		if _, ok := snap.objects[filePath]; !ok {
+			delete(s.versions, filePath)
+		}
+	}
+
+	// Forget content that no remembered listing points to
+	for key, kept := range s.contents {
+		filePath := strings.TrimSuffix(key, "\x00"+kept.version)
+		if !s.remembered(filePath, kept.version) {
+			delete(s.contents, key)
+			s.contentBytes -= len(kept.data)
+		}
+	}
+}
+
+func (s *bucketState) remembered(filePath, version string) bool {
+	for _, snap := range s.snapshots {
+		if snap.objects[filePath] == version {
+			return true
+		}
+	}
+	return false
+}
+
+// listings returns the remembered listings, oldest first
+func (s *bucketState) listings() []*snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*snapshot(nil), s.snapshots...)
+}
+
+func (s *bucketState) snapshot(ref string) *snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, snap := range s.snapshots {
+		if snap.ref == ref {
+			return snap
+		}
+	}
+	return nil
+}
+
+func (s *bucketState) cachedVersion(filePath string, modTime time.Time, size int64) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached, ok := s.versions[filePath]
+	if !ok || !cached.modTime.Equal(modTime) || cached.size != size {
+		return "", false
+	}
+	return cached.version, true
+}
+
+func (s *bucketState) cacheVersion(filePath string, modTime time.Time, size int64, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions[filePath] = cachedVersion{modTime: modTime, size: size, version: version}
+}
+
+// keepContent remembers what an object version contains, as long as it fits
+func (s *bucketState) keepContent(filePath, version string, data []byte, modTime time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := contentKey(filePath, version)
+	if _, ok := s.contents[key]; ok || s.contentBytes+len(data) > maxContentBytes {
+		return
+	}
+	s.contents[key] = &keptContent{version: version, data: data, modTime: modTime}
+	s.contentBytes += len(data)
+}
+
+// content returns what an object version contains, when it was kept
+func (s *bucketState) content(filePath, version string) *keptContent {
+	if version == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.contents[contentKey(filePath, version)]
+}
@@ -0,0 +1,343 @@
+package bucket
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/memblob"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+func newTestRepository(t *testing.T, bucketURL, path string, open OpenBucketFn) *bucketRepository {
+	t.Helper()
+	r, err := ExtraWithOpener([]string{bucketURL}, open).Build(context.Background(), &provisioning.Repository{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "published"},
+		Spec: provisioning.RepositorySpec{
+			Type:   provisioning.BucketRepositoryType,
+			Bucket: &provisioning.BucketRepositoryConfig{URL: bucketURL, Path: path},
+		},
+	})
+	require.NoError(t, err)
+	return r.(*bucketRepository)
+}
+
+func treePaths(entries []repository.FileTreeEntry) []string {
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
+func TestBucketRepository(t *testing.T) {
+	ctx := context.Background()
+	bucket := memblob.OpenBucket(nil)
+	require.NoError(t, bucket.WriteAll(ctx, "outside.json", []byte(`{}`), nil))
+
+	repo := newTestRepository(t, "mem://", "grafana", func(context.Context, string) (*blob.Bucket, error) {
+		return bucket, nil
+	})
+
+	results, err := repo.Test(ctx)
+	require.NoError(t, err)
+	require.True(t, results.Success)
+
+	require.NoError(t, repo.Create(ctx, "team/a.json", "", []byte(`{"v":1}`), "create"))
+	require.NoError(t, repo.Create(ctx, "team/b.json", "", []byte(`{"v":1}`), "create"))
+	require.NoError(t, repo.Create(ctx, "empty/", "", nil, "create"))
+
+	t.Run("tree contains the objects under the path and their directories", func(t *testing.T) {
+		tree, err := repo.ReadTree(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"empty/", "empty/.keep", "team/", "team/a.json", "team/b.json"}, treePaths(tree))
+	})
+
+	ref, err := repo.LatestRef(ctx)
+	require.NoError(t, err)
+
+	t.Run("the hash is the object version", func(t *testing.T) {
+		info, err := repo.Read(ctx, "team/a.json", ref)
+		require.NoError(t, err)
+		require.Equal(t, `{"v":1}`, string(info.Data))
+
+		tree, err := repo.ReadTree(ctx, ref)
+		require.NoError(t, err)
+		for _, e := range tree {
+			if e.Path == "team/a.json" {
+				require.Equal(t, info.Hash, e.Hash)
+			}
+		}
+
+		byVersion, err := repo.Read(ctx, "team/a.json", info.Hash)
+		require.NoError(t, err)
+		require.Equal(t, info.Data, byVersion.Data)
+	})
+
+	t.Run("the ref only changes with the content", func(t *testing.T) {
+		same, err := repo.LatestRef(ctx)
+		require.NoError(t, err)
+		require.Equal(t, ref, same)
+
+		require.NoError(t, bucket.WriteAll(ctx, "other/c.json", []byte(`{}`), nil))
+		same, err = repo.LatestRef(ctx)
+		require.NoError(t, err)
+		require.Equal(t, ref, same, "objects outside of the path are ignored")
+
+		require.NoError(t, repo.Update(ctx, "team/a.json", "", []byte(`{"v":2}`), "update"))
+		changed, err := repo.LatestRef(ctx)
+		require.NoError(t, err)
+		require.NotEqual(t, ref, changed)
+
+		// Unchanged objects can still be read at the previous ref, and
+		// rewritten ones when their previous version was read before
+		_, err = repo.Read(ctx, "team/b.json", ref)
+		require.NoError(t, err)
+		previous, err := repo.Read(ctx, "team/a.json", ref)
+		require.NoError(t, err)
+		require.Equal(t, `{"v":1}`, string(previous.Data))
+		_, err = repo.ReadTree(ctx, ref)
+		require.ErrorIs(t, err, repository.ErrRefNotFound)
+		_, err = repo.Read(ctx, "team/a.json", "unknown")
+		require.ErrorIs(t, err, repository.ErrRefNotFound)
+
+		changes, err := repo.CompareFiles(ctx, ref, changed)
+		require.NoError(t, err)
+		require.Equal(t, []repository.VersionedFileChange{{
+			Action:      repository.FileActionUpdated,
+			Path:        "team/a.json",
+			Ref:         changed,
+			PreviousRef: ref,
+		}}, changes)
+
+		history, err := repo.History(ctx, "team/a.json", "")
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, changed, history[0].Ref)
+		require.Equal(t, "1 rewritten", history[0].Message)
+		require.Equal(t, ref, history[1].Ref)
+	})
+
+	t.Run("writes", func(t *testing.T) {
+		require.ErrorIs(t, repo.Create(ctx, "team/b.json", "", []byte(`{}`), "create"), repository.ErrFileAlreadyExists)
+		require.ErrorIs(t, repo.Update(ctx, "team/missing.json", "", []byte(`{}`), "update"), repository.ErrFileNotFound)
+		require.Error(t, repo.Write(ctx, "team/b.json", ref, []byte(`{}`), "write"), "writes can not target a ref")
+
+		require.NoError(t, repo.Move(ctx, "team/", "moved/", "", "move"))
+		require.ErrorIs(t, repo.Move(ctx, "moved/a.json", "moved/b.json", "", "move"), repository.ErrFileAlreadyExists)
+		require.NoError(t, repo.Delete(ctx, "empty/", "", "delete"))
+		require.ErrorIs(t, repo.Delete(ctx, "empty/", "", "delete"), repository.ErrFileNotFound)
+
+		tree, err := repo.ReadTree(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"moved/", "moved/a.json", "moved/b.json"}, treePaths(tree))
+
+		data, err := bucket.ReadAll(ctx, "grafana/moved/a.json")
+		require.NoError(t, err)
+		require.Equal(t, `{"v":2}`, string(data))
+	})
+}
+
+func TestBucketRepository_CompareFiles(t *testing.T) {
+	ctx := context.Background()
+	bucket := memblob.OpenBucket(nil)
+	repo := newTestRepository(t, "mem://", "", func(context.Context, string) (*blob.Bucket, error) {
+		return bucket, nil
+	})
+
+	require.NoError(t, repo.Write(ctx, "kept.json", "", []byte(`{}`), "write"))
+	require.NoError(t, repo.Write(ctx, "removed.json", "", []byte(`{"v":1}`), "write"))
+	require.NoError(t, repo.Write(ctx, "unread.json", "", []byte(`{"v":1}`), "write"))
+	base, err := repo.LatestRef(ctx)
+	require.NoError(t, err)
+
+	// A sync reads the objects it applies
+	_, err = repo.Read(ctx, "removed.json", base)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(ctx, "removed.json", "", "delete"))
+	require.NoError(t, repo.Write(ctx, "added.json", "", []byte(`{}`), "write"))
+	ref, err := repo.LatestRef(ctx)
+	require.NoError(t, err)
+
+	t.Run("objects are compared by version", func(t *testing.T) {
+		changes, err := repo.CompareFiles(ctx, base, ref)
+		require.NoError(t, err)
+		require.Equal(t, []repository.VersionedFileChange{
+			{Action: repository.FileActionCreated, Path: "added.json", Ref: ref},
+			{Action: repository.FileActionDeleted, Path: "removed.json", Ref: ref, PreviousRef: base},
+		}, changes)
+
+		info, err := repo.Read(ctx, "removed.json", base)
+		require.NoError(t, err)
+		require.Equal(t, `{"v":1}`, string(info.Data))
+	})
+
+	t.Run("a full sync runs when a previous version was never read", func(t *testing.T) {
+		require.NoError(t, repo.Write(ctx, "unread.json", "", []byte(`{"v":2}`), "write"))
+		latest, err := repo.LatestRef(ctx)
+		require.NoError(t, err)
+
+		_, err = repo.CompareFiles(ctx, ref, latest)
+		require.ErrorIs(t, err, repository.ErrIncrementalSyncNotSupported)
+	})
+
+	t.Run("a full sync runs when the base listing is not remembered", func(t *testing.T) {
+		_, err := repo.CompareFiles(ctx, "unknown", ref)
+		require.ErrorIs(t, err, repository.ErrIncrementalSyncNotSupported)
+	})
+}
+
+func TestExtra_EvictsIdleBuckets(t *testing.T) {
+	ctx := context.Background()
+	opened := 0
+	e := ExtraWithOpener([]string{"mem://"}, func(context.Context, string) (*blob.Bucket, error) {
+		opened++
+		return memblob.OpenBucket(nil), nil
+	}).(*extra)
+	// Repositories are released from the goroutine running cleanups
+	var clock sync.Mutex
+	now := time.Now()
+	e.now = func() time.Time {
+		clock.Lock()
+		defer clock.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		clock.Lock()
+		defer clock.Unlock()
+		now = now.Add(d)
+	}
+
+	build := func(name string) repository.Repository {
+		repo, err := e.Build(ctx, &provisioning.Repository{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: provisioning.RepositorySpec{
+				Type:   provisioning.BucketRepositoryType,
+				Bucket: &provisioning.BucketRepositoryConfig{URL: "mem://"},
+			},
+		})
+		require.NoError(t, err)
+		return repo
+	}
+	// Repositories release their bucket when they are collected
+	waitForUsers := func(users int) {
+		require.Eventually(t, func() bool {
+			runtime.GC()
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			return e.buckets["mem://"].users == users
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	build("a")
+	build("b")
+	require.Equal(t, 1, opened, "the bucket is shared")
+	require.Len(t, e.states, 2)
+
+	advance(idleTimeout / 2)
+	build("a")
+	advance(idleTimeout / 2)
+	build("a")
+	require.Equal(t, 1, opened, "the bucket is still in use")
+	require.Len(t, e.states, 1, "the listings of an idle repository are forgotten")
+
+	// A job keeps its repository for as long as it runs
+	job := build("a")
+	waitForUsers(1)
+	advance(2 * idleTimeout)
+	build("a")
+	require.Equal(t, 1, opened, "a bucket used by a running job is not closed")
+	runtime.KeepAlive(job)
+
+	waitForUsers(0)
+	advance(2 * idleTimeout)
+	build("a")
+	require.Equal(t, 2, opened, "an idle bucket is closed and opened again")
+}
+
+func TestBucketRepository_FileBucket(t *testing.T) {
+	ctx := context.Background()
+	bucketURL := "file://" + t.TempDir()
+	repo := newTestRepository(t, bucketURL, "", resource.OpenBlobBucket)
+
+	require.NoError(t, repo.Write(ctx, "dashboards/a.json", "", []byte(`{"v":1}`), "write"))
+
+	// Files have no MD5 without the metadata sidecar files, so the ETag is used
+	tree, err := repo.ReadTree(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"dashboards/", "dashboards/a.json"}, treePaths(tree))
+	require.NotEmpty(t, tree[1].Hash)
+
+	info, err := repo.Read(ctx, "dashboards/a.json", "")
+	require.NoError(t, err)
+	require.Equal(t, tree[1].Hash, info.Hash)
+
+	repo.WithMaxFileSize(2)
+	_, err = repo.Read(ctx, "dashboards/a.json", "")
+	require.Error(t, err)
+}
+
+func TestBucketRepository_NotPermitted(t *testing.T) {
+	repo, err := Extra([]string{"s3://grafana-dashboards"}).Build(context.Background(), &provisioning.Repository{
+		Spec: provisioning.RepositorySpec{
+			Type:   provisioning.BucketRepositoryType,
+			Bucket: &provisioning.BucketRepositoryConfig{URL: "file:///etc"},
+		},
+	})
+	require.NoError(t, err)
+
+	results, err := repo.Test(context.Background())
+	require.NoError(t, err)
+	require.False(t, results.Success)
+	require.Equal(t, http.StatusBadRequest, results.Code)
+	require.Equal(t, "spec.bucket.url", results.Errors[0].Field)
+
+	_, err = repo.(*bucketRepository).ReadTree(context.Background(), "")
+	require.Error(t, err)
+}
+
+func TestCheckPermittedURL(t *testing.T) {
+	permitted := []string{"s3://grafana-dashboards/published", "gs://team-a", "file:///var/lib/grafana/buckets", "s3://pinned?awssdk=v2"}
+
+	for _, u := range []string{
+		"s3://grafana-dashboards/published?region=us-east-1",
+		"s3://pinned?awssdk=v2",
+		"s3://pinned/nested?awssdk=v2&region=eu-west-1",
+		"s3://grafana-dashboards/published/prod",
+		"gs://team-a",
+		"gs://team-a/nested",
+		"file:///var/lib/grafana/buckets/one",
+	} {
+		require.NoError(t, checkPermittedURL(u, permitted), u)
+	}
+
+	for _, u := range []string{
+		"s3://grafana-dashboards",
+		"s3://grafana-dashboards/published-other",
+		"s3://grafana-dashboards-evil/published",
+		"gs://team-b",
+		"file:///etc",
+		"file:///var/lib/grafana/buckets/../../../etc",
+		"not a url",
+		"s3://grafana-dashboards/published?endpoint=http://169.254.169.254",
+		"s3://grafana-dashboards/published?region=us-east-1.example.com/",
+		"s3://grafana-dashboards/published?awssdk=v2",
+		"s3://pinned?awssdk=v1",
+		"s3://pinned?awssdk=v2&endpoint=http://169.254.169.254",
+		"gs://team-a?access_id=someone",
+	} {
+		require.Error(t, checkPermittedURL(u, permitted), u)
+	}
+
+	require.Error(t, checkPermittedURL("gs://team-a", nil))
+}
@@ -0,0 +1,146 @@
+package bucket
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"gocloud.dev/blob"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+// idleTimeout is how long a bucket stays open, and its listings remembered,
+// after the last repository that uses it is released. Repositories are built
+// again for every health check and job, so only deleted or moved ones go idle.
+const idleTimeout = time.Hour
+
+// OpenBucketFn opens the bucket for a URL that passed validation
+type OpenBucketFn func(ctx context.Context, url string) (*blob.Bucket, error)
+
+type openBucket struct {
+	bucket *blob.Bucket
+	used   time.Time
+	// users counts the repositories built on the bucket that are still reachable.
+	// A bucket is only closed when no repository, such as the one of a running job, uses it.
+	users int
+}
+
+type extra struct {
+	permittedURLs []string
+	open          OpenBucketFn
+	now           func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*openBucket
+	states  map[string]*bucketState
+}
+
+// Extra registers the bucket repository type.
+// Only buckets under one of the permitted URLs can be used.
+func Extra(permittedURLs []string) repository.Extra {
+	return ExtraWithOpener(permittedURLs, resource.OpenBlobBucket)
+}
+
+// ExtraWithOpener is like Extra, but opens buckets with the given function
+func ExtraWithOpener(permittedURLs []string, open OpenBucketFn) repository.Extra {
+	return &extra{
+		permittedURLs: permittedURLs,
+		open:          open,
+		now:           time.Now,
+		buckets:       make(map[string]*openBucket),
+		states:        make(map[string]*bucketState),
+	}
+}
+
+func (e *extra) Type() provisioning.RepositoryType {
+	return provisioning.BucketRepositoryType
+}
+
+// Build opens the bucket once per URL. Opening errors are reported by the repository health check.
+func (e *extra) Build(ctx context.Context, r *provisioning.Repository) (repository.Repository, error) {
+	if r.Spec.Bucket == nil {
+		return NewRepository(r, nil, nil), nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	e.evictIdle(ctx, now)
+
+	// Listings are only comparable for the same bucket and path
+	stateKey := r.Namespace + "/" + r.Name + "/" + r.Spec.Bucket.URL + "/" + r.Spec.Bucket.Path
+	state, ok := e.states[stateKey]
+	if !ok {
+		state = newBucketState()
+		e.states[stateKey] = state
+	}
+	state.used = now
+
+	url := r.Spec.Bucket.URL
+	if err := checkPermittedURL(url, e.permittedURLs); err != nil {
+		repo := NewRepository(r, nil, state)
+		repo.openErr = err
+		return repo, nil
+	}
+
+	cached, ok := e.buckets[url]
+	if !ok {
+		bucket, err := e.open(ctx, url)
+		if err != nil {
+			repo := NewRepository(r, nil, state)
+			repo.openErr = err
+			return repo, nil
+		}
+		cached = &openBucket{bucket: bucket}
+		e.buckets[url] = cached
+	}
+	cached.used = now
+	cached.users++
+
+	repo := NewRepository(r, cached.bucket, state)
+	// Repositories have no close method, so the bucket is released when the repository is collected
+	runtime.AddCleanup(repo, e.release, cached)
+	return repo, nil
+}
+
+func (e *extra) release(cached *openBucket) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cached.users--
+	cached.used = e.now()
+}
+
+// evictIdle closes the buckets no repository uses, and forgets the listings no repository was built on, for a while
+func (e *extra) evictIdle(ctx context.Context, now time.Time) {
+	for url, cached := range e.buckets {
+		if cached.users > 0 || now.Sub(cached.used) < idleTimeout {
+			continue
+		}
+		if err := cached.bucket.Close(); err != nil {
+			logging.FromContext(ctx).Warn("failed to close idle bucket", "url", url, "error", err)
+		}
+		delete(e.buckets, url)
+	}
+	for key, state := range e.states {
+		if now.Sub(state.used) >= idleTimeout {
+			delete(e.states, key)
+		}
+	}
+}
+
+func (e *extra) Mutate(_ context.Context, _ runtime.Object) error {
+	return nil
+}
+
+func (e *extra) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
+	return Validate(ctx, obj, e.permittedURLs)
+}
@@ -0,0 +1,119 @@
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+)
+
+// Validate validates the bucket repository configuration without opening the bucket.
+func Validate(_ context.Context, obj runtime.Object, permittedURLs []string) field.ErrorList {
+	repo, ok := obj.(*provisioning.Repository)
+	if !ok {
+		return nil
+	}
+
+	if repo.Spec.Type != provisioning.BucketRepositoryType {
+		return nil
+	}
+
+	cfg := repo.Spec.Bucket
+	if cfg == nil {
+		return field.ErrorList{
+			field.Required(field.NewPath("spec", "bucket"), "bucket configuration is required for bucket repository type"),
+		}
+	}
+
+	var list field.ErrorList
+	if cfg.URL == "" {
+		list = append(list, field.Required(field.NewPath("spec", "bucket", "url"), "must enter a bucket url"))
+	} else if err := checkPermittedURL(cfg.URL, permittedURLs); err != nil {
+		list = append(list, field.Invalid(field.NewPath("spec", "bucket", "url"), cfg.URL, err.Error()))
+	}
+
+	if err := safepath.IsSafe(cfg.Path); err != nil {
+		list = append(list, field.Invalid(field.NewPath("spec", "bucket", "path"), cfg.Path, err.Error()))
+	}
+
+	return list
+}
+
+// safeQueryParameters can be set on any bucket URL. Other query parameters must be set,
+// with the same values, on the permitted URL.
+var safeQueryParameters = map[string]*regexp.Regexp{
+	"region": regexp.MustCompile(`^[a-z0-9-]+$`),
+}
+
+// blockedQueryParameters are rejected even when a permitted URL sets them, as they
+// send the requests of the bucket to another host.
+var blockedQueryParameters = []string{"endpoint", "domain"}
+
+// checkPermittedURL accepts a bucket URL when it has the scheme and host of a
+// permitted URL, and its path is the permitted path or below it. Query parameters
+// configure the bucket driver, and some of them change where requests are sent,
+// so they must be safe or set to the same value by the permitted URL.
+func checkPermittedURL(bucketURL string, permittedURLs []string) error {
+	if len(permittedURLs) == 0 {
+		return fmt.Errorf("no permitted bucket urls were configured")
+	}
+
+	target, err := url.Parse(bucketURL)
+	if err != nil || target.Scheme == "" {
+		return fmt.Errorf("the bucket url is not valid")
+	}
+	if strings.Contains(target.Path, "..") {
+		return fmt.Errorf("the bucket url can not contain '..'")
+	}
+	query := target.Query()
+	for _, name := range blockedQueryParameters {
+		if query.Has(name) {
+			return fmt.Errorf("the bucket url can not set the %s parameter", name)
+		}
+	}
+
+	var queryErr error
+	for _, permitted := range permittedURLs {
+		p, err := url.Parse(strings.TrimSpace(permitted))
+		if err != nil || p.Scheme != target.Scheme || p.Host != target.Host {
+			continue
+		}
+
+		dir := safepath.EnsureTrailingSlash(safepath.Clean(p.Path))
+		if dir != "" && !safepath.InDir(safepath.EnsureTrailingSlash(safepath.Clean(target.Path)), dir) {
+			continue
+		}
+		if queryErr = checkQuery(query, p.Query()); queryErr == nil {
+			return nil
+		}
+	}
+
+	if queryErr != nil {
+		return queryErr
+	}
+	return fmt.Errorf("the bucket url matches no permitted url")
+}
+
+func checkQuery(query, permitted url.Values) error {
+	for name, values := range query {
+		if slices.Equal(values, permitted[name]) {
+			continue
+		}
+		pattern, ok := safeQueryParameters[name]
+		if !ok {
+			return fmt.Errorf("the bucket url parameter %s is not permitted", name)
+		}
+		if len(values) != 1 || !pattern.MatchString(values[0]) {
+			return fmt.Errorf("the bucket url parameter %s is not valid", name)
+		}
+	}
+	return nil
+}
@@ -26,6 +26,7 @@ import (
 	listers "github.com/grafana/grafana/apps/provisioning/pkg/generated/listers/provisioning/v0alpha1"
 	"github.com/grafana/grafana/apps/provisioning/pkg/quotas"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/schedule"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
@@ -324,8 +325,15 @@ func (rc *RepositoryController) updateDeleteStatus(ctx context.Context, obj *pro
 }
 
 func (rc *RepositoryController) shouldResync(ctx context.Context, obj *provisioning.Repository) bool {
+	windowOpen := rc.isSyncWindowOpen(ctx, obj)
+
 	// don't trigger resync if a sync was never started
 	if obj.Status.Sync.Finished == 0 && obj.Status.Sync.State == "" {
+		// The first sync of a repository with sync windows is skipped until a window opens
+		return obj.Spec.Sync.Enabled && len(obj.Spec.Sync.Windows) > 0 &&
+			obj.Status.ObservedGeneration > 0 && windowOpen
+	}
+	if !windowOpen {
 		return false
 	}
 
@@ -367,7 +375,28 @@ func (rc *RepositoryController) shouldResync(ctx context.Context, obj *provision
 	pendingForTooLong := syncAge >= syncInterval/2 && obj.Status.Sync.State == provisioning.JobStatePending
 	isRunning := obj.Status.Sync.State == provisioning.JobStateWorking
 
-	return obj.Spec.Sync.Enabled && syncAge >= (syncInterval-tolerance) && !pendingForTooLong && !isRunning
+	due := syncAge >= (syncInterval - tolerance)
+	if obj.Spec.Sync.Schedule != "" {
+		next, err := schedule.Next(obj.Spec.Sync, time.UnixMilli(obj.Status.Sync.Finished))
+		if err != nil {
+			logging.FromContext(ctx).Warn("invalid sync schedule, falling back to the sync interval", "error", err, "schedule", obj.Spec.Sync.Schedule)
+		} else {
+			due = !time.Now().Before(next)
+		}
+	}
+
+	return obj.Spec.Sync.Enabled && due && !pendingForTooLong && !isRunning
+}
+
+// isSyncWindowOpen returns false when the sync windows of the repository pause sync.
+// Windows that cannot be evaluated never pause sync.
+func (rc *RepositoryController) isSyncWindowOpen(ctx context.Context, obj *provisioning.Repository) bool {
+	paused, err := schedule.Paused(obj.Spec.Sync, time.Now())
+	if err != nil {
+		logging.FromContext(ctx).Warn("unable to evaluate sync windows", "error", err)
+		return true
+	}
+	return paused == ""
 }
 
 func (rc *RepositoryController) runHooks(ctx context.Context, repo repository.Repository, obj *provisioning.Repository) ([]map[string]interface{}, error) {
@@ -423,6 +452,9 @@ func (rc *RepositoryController) determineSyncStrategy(
 	case isBlocked:
 		logger.Info("skip sync for repository over quota")
 		return nil
+	case !rc.isSyncWindowOpen(ctx, obj):
+		logger.Info("skip sync outside of the sync windows")
+		return nil
 	case !healthStatus.Healthy:
 		logger.Info("skip sync for unhealthy repository")
 		return nil
@@ -460,6 +492,10 @@ func (rc *RepositoryController) determineSyncStrategy(
 		// such as .keep file deletions inside a folder with no other deletions (to detect whether the folder
 		// was deleted in git) or when the diff size reaches/exceeds max_incremental_changes.
 		incremental, err := shouldUseIncrementalSync(ctx, versioned, obj, latestRef, rc.incrementalPolicy)
+		if errors.Is(err, repository.ErrIncrementalSyncNotSupported) {
+			logger.Info("full sync on interval as the reference changed")
+			return &provisioning.SyncJobOptions{}
+		}
 		if err != nil {
 			logger.Warn("unable to compare files for incremental sync, doing full sync", "error", err)
 			return &provisioning.SyncJobOptions{}
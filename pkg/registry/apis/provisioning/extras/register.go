@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository/local"
 	"github.com/grafana/grafana/apps/secret/pkg/decrypt"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/bucket"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/webhooks"
@@ -45,6 +46,7 @@ func ProvideProvisioningOSSRepositoryExtras(
 			cfg.PermittedProvisioningPaths,
 		),
 		git.Extra(decrypter, allowInsecure),
+		bucket.Extra(cfg.ProvisioningPermittedBucketURLs),
 		github.Extra(
 			decrypter,
 			ghFactory,
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,9 +19,11 @@ import (
 	"github.com/grafana/grafana/apps/provisioning/pkg/apis/auth"
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/schedule"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/rollback"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
 )
 
@@ -170,7 +173,7 @@ func (c *jobsConnector) handleCreateJob(ctx context.Context, r *http.Request, na
 		return
 	}
 
-	if spec.Action == provisioning.JobActionPull {
+	if spec.Action == provisioning.JobActionPull || spec.Action == provisioning.JobActionRollback {
 		if err := c.authorizeAdminJob(ctx, cfg); err != nil {
 			responder.Error(err)
 			return
@@ -311,7 +314,20 @@ func (c *jobsConnector) authorizeJob(ctx context.Context, repo repository.Reposi
 		if spec.Move != nil {
 			return c.authorizeMoveJob(ctx, repo, cfg, spec.Move)
 		}
-	case provisioning.JobActionPull, provisioning.JobActionPullRequest, provisioning.JobActionFixFolderMetadata:
+	case provisioning.JobActionPull:
+		// Pull is authorized inline in Connect, but it must respect the sync windows.
+		paused, err := schedule.Paused(cfg.Spec.Sync, time.Now())
+		if err != nil {
+			return apierrors.NewBadRequest(fmt.Sprintf("invalid sync windows: %s", err))
+		}
+		if paused != "" {
+			return apierrors.NewBadRequest(paused)
+		}
+	case provisioning.JobActionRollback:
+		if spec.Rollback != nil {
+			return c.validateRollbackJob(ctx, cfg, spec.Rollback)
+		}
+	case provisioning.JobActionPullRequest, provisioning.JobActionFixFolderMetadata:
 		// Read-only operations don't require pre-flight resource authorization.
 		// Pull is authorized inline in Connect.
 	case provisioning.JobActionReleaseResources, provisioning.JobActionDeleteResources:
@@ -321,6 +337,22 @@ func (c *jobsConnector) authorizeJob(ctx context.Context, repo repository.Reposi
 	return nil
 }
 
+// validateRollbackJob checks that the job to roll back exists and recorded the changes it made,
+// so the request fails before a rollback job is queued.
+func (c *jobsConnector) validateRollbackJob(ctx context.Context, cfg *provisioning.Repository, opts *provisioning.RollbackJobOptions) error {
+	if !ValidUUID(opts.Job) {
+		return apierrors.NewBadRequest(fmt.Sprintf("invalid job uid: %s", opts.Job))
+	}
+	target, err := c.historic.GetJob(ctx, cfg.Namespace, cfg.Name, opts.Job)
+	if err != nil {
+		return err
+	}
+	if err := rollback.ValidateTarget(target); err != nil {
+		return apierrors.NewBadRequest(fmt.Sprintf("job cannot be rolled back: %s", err))
+	}
+	return nil
+}
+
 // newJobAuthorizer creates an Authorizer for the given repository. Returns an error
 // if the repository does not implement Reader.
 func (c *jobsConnector) newJobAuthorizer(ctx context.Context, repo repository.Repository, cfg *provisioning.Repository) (resources.Authorizer, error) {
@@ -17,6 +17,7 @@ import (
 	appcontroller "github.com/grafana/grafana/apps/provisioning/pkg/controller"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
 )
 
 // Store is an abstraction for the storage API.
@@ -212,8 +213,8 @@ func (d *jobDriver) claimAndProcessOneJob(ctx context.Context) error {
 	recorder := newJobProgressRecorder(d.onProgress(), d.metrics, claimedJob.Spec.Action)
 	recorder.SetMessage(ctx, "start job")
 
-	// Process the job with lease loss detection
-	err = d.processJobWithLeaseCheck(jobctx, recorder, leaseExpired)
+	// Process the job with lease loss detection, keeping every resource change for rollbacks
+	err = d.processJobWithLeaseCheck(resources.WithChangeRecorder(jobctx, recorder.RecordChange), recorder, leaseExpired)
 	duration := time.Since(recorder.Started())
 
 	// Check if parent context was cancelled (graceful shutdown)
@@ -6,11 +6,13 @@ import (
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	client "github.com/grafana/grafana/apps/provisioning/pkg/generated/clientset/versioned/typed/provisioning/v0alpha1"
 )
 
 // HistoryReader keeps track of completed jobs
@@ -54,7 +56,10 @@ func (s *storageBackedHistory) getJobs(ctx context.Context, namespace string, la
 	if !ok {
 		return nil, fmt.Errorf("expected HistoricJobList, found %T", historic)
 	}
+	return historicToJobs(historic), nil
+}
 
+func historicToJobs(historic *provisioning.HistoricJobList) *provisioning.JobList {
 	jobs := &provisioning.JobList{
 		ListMeta: historic.ListMeta,
 	}
@@ -65,7 +70,7 @@ func (s *storageBackedHistory) getJobs(ctx context.Context, namespace string, la
 			Status:     job.Status,
 		})
 	}
-	return jobs, nil
+	return jobs
 }
 
 // Recent implements History.
@@ -83,8 +88,51 @@ func (s *storageBackedHistory) GetJob(ctx context.Context, namespace, repo, job
 	if err != nil {
 		return nil, err
 	}
+	return singleJob(jobs, job)
+}
+
+func singleJob(jobs *provisioning.JobList, uid string) (*provisioning.Job, error) {
 	if len(jobs.Items) == 1 {
 		return &jobs.Items[0], nil
 	}
-	return nil, apierrors.NewNotFound(provisioning.JobResourceInfo.GroupResource(), job)
+	return nil, apierrors.NewNotFound(provisioning.JobResourceInfo.GroupResource(), uid)
+}
+
+// NewAPIClientHistoryReader creates a HistoryReader for the jobs written by the API client history writer
+func NewAPIClientHistoryReader(provisioningClient client.ProvisioningV0alpha1Interface) HistoryReader {
+	return &apiClientHistoryReader{
+		client: provisioningClient,
+	}
+}
+
+type apiClientHistoryReader struct {
+	client client.ProvisioningV0alpha1Interface
+}
+
+func (r *apiClientHistoryReader) getJobs(ctx context.Context, namespace string, labels labels.Set) (*provisioning.JobList, error) {
+	historic, err := r.client.HistoricJobs(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.AsSelector().String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return historicToJobs(historic), nil
+}
+
+// RecentJobs implements HistoryReader.
+func (r *apiClientHistoryReader) RecentJobs(ctx context.Context, namespace, repo string) (*provisioning.JobList, error) {
+	return r.getJobs(ctx, namespace, labels.Set{
+		LabelRepository: repo,
+	})
+}
+
+// GetJob implements HistoryReader.
+func (r *apiClientHistoryReader) GetJob(ctx context.Context, namespace, repo, job string) (*provisioning.Job, error) {
+	jobs, err := r.getJobs(ctx, namespace, labels.Set{
+		LabelJobOriginalUID: job,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return singleJob(jobs, job)
 }
@@ -289,6 +289,39 @@ func (_c *MockJobProgressRecorder_Record_Call) RunAndReturn(run func(context.Con
 	return _c
 }
 
+// RecordChange provides a mock function with given fields: change
+func (_m *MockJobProgressRecorder) RecordChange(change v0alpha1.JobResourceChange) {
+	_m.Called(change)
+}
+
+// MockJobProgressRecorder_RecordChange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordChange'
+type MockJobProgressRecorder_RecordChange_Call struct {
+	*mock.Call
+}
+
+// RecordChange is a helper method to define mock.On call
+//   - change v0alpha1.JobResourceChange
+func (_e *MockJobProgressRecorder_Expecter) RecordChange(change interface{}) *MockJobProgressRecorder_RecordChange_Call {
+	return &MockJobProgressRecorder_RecordChange_Call{Call: _e.mock.On("RecordChange", change)}
+}
+
+func (_c *MockJobProgressRecorder_RecordChange_Call) Run(run func(change v0alpha1.JobResourceChange)) *MockJobProgressRecorder_RecordChange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(v0alpha1.JobResourceChange))
+	})
+	return _c
+}
+
+func (_c *MockJobProgressRecorder_RecordChange_Call) Return() *MockJobProgressRecorder_RecordChange_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockJobProgressRecorder_RecordChange_Call) RunAndReturn(run func(v0alpha1.JobResourceChange)) *MockJobProgressRecorder_RecordChange_Call {
+	_c.Run(run)
+	return _c
+}
+
 // ResetResults provides a mock function with given fields: keepWarnings
 func (_m *MockJobProgressRecorder) ResetResults(keepWarnings bool) {
 	_m.Called(keepWarnings)
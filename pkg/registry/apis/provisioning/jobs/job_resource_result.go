@@ -62,6 +62,9 @@ func classifyWarning(err error) (string, bool) {
 	var folderManagedByOtherErr *resources.FolderManagedByOtherError
 	var uidTooLongErr *resources.FolderUIDTooLongError
 	var folderValidationErr *resources.FolderValidationError
+	var sourceEvalErr *resources.SourceEvaluationError
+	var policyErr *resources.PolicyViolationError
+	var conflictErr *resources.ResourceConflictError
 
 	// Order matters: the more specific folder reasons must be checked
 	// before the generic FolderValidationError fallback so the user-facing
@@ -69,6 +72,12 @@ func classifyWarning(err error) (string, bool) {
 	switch {
 	case errors.As(err, &quotaExceededErr):
 		return provisioning.ReasonQuotaExceeded, true
+	case errors.As(err, &sourceEvalErr):
+		return provisioning.ReasonSourceEvaluationFailed, true
+	case errors.As(err, &policyErr) && !policyErr.Blocking():
+		return provisioning.ReasonPolicyViolation, true
+	case errors.As(err, &conflictErr):
+		return provisioning.ReasonResourceConflict, true
 	case errors.As(err, &validationErr):
 		return provisioning.ReasonResourceInvalid, true
 	case errors.As(err, &ownershipErr):
@@ -102,13 +111,16 @@ func isWarningError(err error) bool {
 
 // isNonFailingWarning reports whether the warning represents an informational
 // issue where the underlying resource operation still succeeded (e.g. missing
-// or invalid folder metadata).
+// or invalid folder metadata, a warn policy rule, or a conflict resolved with
+// the repository version).
 func isNonFailingWarning(err error) bool {
 	if err == nil {
 		return false
 	}
 	return errors.Is(err, resources.ErrMissingFolderMetadata) ||
-		errors.Is(err, resources.ErrInvalidFolderMetadata)
+		errors.Is(err, resources.ErrInvalidFolderMetadata) ||
+		resources.IsPolicyWarning(err) ||
+		resources.IsConflictWarning(err)
 }
 
 // JobResourceResult represents the result of a resource operation in a job.
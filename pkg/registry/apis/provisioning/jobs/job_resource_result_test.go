@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/policy"
 	"github.com/grafana/grafana/apps/provisioning/pkg/quotas"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
@@ -437,6 +438,47 @@ func TestJobResourceResult_WarningReason(t *testing.T) {
 		assert.NotNil(t, result.Warning(), "uid-too-long should populate the warning slot")
 	})
 
+	t.Run("SourceEvaluationError classifies as ReasonSourceEvaluationFailed", func(t *testing.T) {
+		evalErr := resources.NewSourceEvaluationError("dashboards/home.jsonnet", resources.SourceFormatJsonnet, errors.New("RUNTIME ERROR: field does not exist: titel"))
+		result := NewResourceResult().WithPath("dashboards/home.jsonnet").WithError(fmt.Errorf("writing resource from file: %w", evalErr)).Build()
+
+		assert.Equal(t, provisioning.ReasonSourceEvaluationFailed, result.WarningReason())
+		assert.Nil(t, result.Error(), "evaluation errors are reported per file as warnings")
+		assert.Contains(t, result.Warning().Error(), "titel")
+	})
+
+	t.Run("PolicyViolationError classifies by enforcement", func(t *testing.T) {
+		warnErr := resources.NewPolicyViolationError("prod/home.json", []policy.Violation{
+			{Rule: "tags", Enforcement: provisioning.PolicyEnforcementWarn, Message: "spec.tags must be set"},
+		})
+		result := NewResourceResult().WithPath("prod/home.json").WithError(fmt.Errorf("writing resource from file: %w", warnErr)).Build()
+		assert.Equal(t, provisioning.ReasonPolicyViolation, result.WarningReason())
+		assert.Nil(t, result.Error(), "warn rules are reported as warnings")
+		assert.True(t, isNonFailingWarning(result.Warning()), "the resource was applied")
+
+		failErr := resources.NewPolicyViolationError("prod/home.json", []policy.Violation{
+			{Rule: "tags", Enforcement: provisioning.PolicyEnforcementWarn, Message: "spec.tags must be set"},
+			{Rule: "refresh", Enforcement: provisioning.PolicyEnforcementFail, Message: "spec.refresh must be at least 1m, found 5s"},
+		})
+		result = NewResourceResult().WithPath("prod/home.json").WithError(fmt.Errorf("writing resource from file: %w", failErr)).Build()
+		assert.Nil(t, result.Warning())
+		assert.Contains(t, result.Error().Error(), "refresh: spec.refresh must be at least 1m")
+	})
+
+	t.Run("ResourceConflictError classifies as ReasonResourceConflict", func(t *testing.T) {
+		conflict := provisioning.ResourceConflict{Path: "dashboards/home.json", Action: provisioning.ResourceActionUpdate, SyncedHash: "b", BaseHash: "a"}
+
+		conflict.Resolution = provisioning.ConflictStrategyRepository
+		result := NewPathOnlyResult(conflict.Path).WithWarning(resources.NewResourceConflictError(conflict)).Build()
+		assert.Equal(t, provisioning.ReasonResourceConflict, result.WarningReason())
+		assert.True(t, isNonFailingWarning(result.Warning()), "the repository version was applied")
+
+		conflict.Resolution = provisioning.ConflictStrategyGrafana
+		result = NewPathOnlyResult(conflict.Path).WithWarning(resources.NewResourceConflictError(conflict)).Build()
+		assert.Equal(t, provisioning.ReasonResourceConflict, result.WarningReason())
+		assert.False(t, isNonFailingWarning(result.Warning()), "the repository change was skipped")
+	})
+
 	t.Run("PathCreationError wrapping FolderUIDTooLongError classifies as ReasonFolderUIDTooLong", func(t *testing.T) {
 		uidErr := resources.NewFolderUIDTooLongError("GMPO/bare-metal-services-engineering/", "a0123456789012345678901234567890123456789", errors.New("uid too long, max 40 characters"))
 		pathErr := &resources.PathCreationError{
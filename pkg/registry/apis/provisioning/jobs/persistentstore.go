@@ -132,13 +132,12 @@ func (s *persistentStore) Claim(ctx context.Context) (job *provisioning.Job, rol
 
 	logger.Debug("found jobs available", "count", len(jobs.Items))
 
-	for _, job := range jobs.Items {
-		if job.Labels == nil {
-			job.Labels = make(map[string]string)
-		}
-		job.Labels[LabelJobClaim] = strconv.FormatInt(s.clock().UnixMilli(), 10)
-		s.queueMetrics.RecordWaitTime(string(job.Spec.Action), s.clock().Sub(job.CreationTimestamp.Time).Seconds())
+	// Repositories whose jobs can not be claimed in this round, keyed by namespace and name
+	skipped := make(map[string]bool)
+	// Job limits of the repositories seen in this round, keyed by namespace and name
+	limits := make(map[string]int64)
 
+	for _, job := range jobs.Items {
 		// Set up the provisioning identity for this namespace
 		ctx, _, err = identity.WithProvisioningIdentity(ctx, job.GetNamespace())
 		if err != nil {
@@ -146,6 +145,41 @@ func (s *persistentStore) Claim(ctx context.Context) (job *provisioning.Job, rol
 			return nil, nil, apifmt.Errorf("failed to get provisioning identity for '%s': %w", job.GetNamespace(), err)
 		}
 
+		repoKey := job.GetNamespace() + "/" + job.Spec.Repository
+		if skipped[repoKey] {
+			continue
+		}
+
+		limit, ok := limits[repoKey]
+		if !ok {
+			limit, err = repositoryJobLimit(ctx, s.client, job.GetNamespace(), job.Spec.Repository)
+			if err != nil {
+				logger.Warn("skip jobs of repository as its job limit could not be read", "namespace", job.GetNamespace(), "repository", job.Spec.Repository, "error", err)
+				skipped[repoKey] = true
+				continue
+			}
+			limits[repoKey] = limit
+		}
+		if limit > 0 {
+			running, err := countClaimedJobs(ctx, s.client, job.GetNamespace(), job.Spec.Repository, "")
+			if err != nil {
+				logger.Warn("skip jobs of repository as its running jobs could not be counted", "namespace", job.GetNamespace(), "repository", job.Spec.Repository, "error", err)
+				skipped[repoKey] = true
+				continue
+			}
+			if running >= limit {
+				logger.Debug("skip jobs of repository as it runs as many jobs as it allows", "namespace", job.GetNamespace(), "repository", job.Spec.Repository)
+				skipped[repoKey] = true
+				continue
+			}
+		}
+
+		if job.Labels == nil {
+			job.Labels = make(map[string]string)
+		}
+		job.Labels[LabelJobClaim] = strconv.FormatInt(s.clock().UnixMilli(), 10)
+		s.queueMetrics.RecordWaitTime(string(job.Spec.Action), s.clock().Sub(job.CreationTimestamp.Time).Seconds())
+
 		// This relies on the resource version being updated for us.
 		// If the resource version we pass in via the current job is not the same as the one currently in the store, it will fail with Conflict.
 		// This is the desired behavior, as it ensures that claims are atomic.
@@ -160,6 +194,17 @@ func (s *persistentStore) Claim(ctx context.Context) (job *provisioning.Job, rol
 			return nil, nil, apifmt.Errorf("failed to claim job '%s' in '%s': %w", job.GetName(), job.GetNamespace(), err)
 		}
 
+		// Another worker may have claimed a job of the repository at the same time
+		if limit > 0 {
+			others, err := countClaimedJobs(ctx, s.client, job.GetNamespace(), job.Spec.Repository, updatedJob.GetName())
+			if err != nil || others >= limit {
+				logger.Debug("give back job claimed over the job limit of its repository", "job", updatedJob.GetName(), "namespace", updatedJob.GetNamespace(), "repository", updatedJob.Spec.Repository, "error", err)
+				s.unclaim(ctx, updatedJob)
+				skipped[repoKey] = true
+				continue
+			}
+		}
+
 		logger.Info("job claim complete",
 			"job", updatedJob.GetName(),
 			"namespace", updatedJob.GetNamespace(),
@@ -203,8 +248,10 @@ func (s *persistentStore) Claim(ctx context.Context) (job *provisioning.Job, rol
 			cancel()
 			if err != nil && !apierrors.IsConflict(err) {
 				logger.Warn("failed to roll back job claim; letting periodic cleaner deal with it", "error", err)
+				return
 			} else if err != nil {
 				logger.Debug("failed to roll back job claim; got an OK error", "error", err)
+				return
 			}
 		}, nil
 	}
@@ -214,6 +261,18 @@ func (s *persistentStore) Claim(ctx context.Context) (job *provisioning.Job, rol
 	return nil, nil, ErrNoJobs
 }
 
+// unclaim gives back a job claimed over the job limit of its repository, so that it is claimed again later.
+// A job that can not be given back is left to the periodic cleaner.
+func (s *persistentStore) unclaim(ctx context.Context, job *provisioning.Job) {
+	unclaimed := job.DeepCopy()
+	delete(unclaimed.Labels, LabelJobClaim)
+	_, err := s.client.Jobs(job.GetNamespace()).Update(ctx, unclaimed, metav1.UpdateOptions{})
+	if err != nil && !apierrors.IsConflict(err) && !apierrors.IsNotFound(err) {
+		logging.FromContext(ctx).Warn("failed to give back job claim; letting periodic cleaner deal with it",
+			"namespace", job.GetNamespace(), "job", job.GetName(), "error", err)
+	}
+}
+
 // Update saves the job back to the store.
 func (s *persistentStore) Update(ctx context.Context, job *provisioning.Job) (*provisioning.Job, error) {
 	ctx, span := tracing.Start(ctx, "provisioning.jobs.update")
@@ -2,17 +2,21 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8testing "k8s.io/client-go/testing"
 
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
 	fakeclientset "github.com/grafana/grafana/apps/provisioning/pkg/generated/clientset/versioned/fake"
 	provisioningv0alpha1 "github.com/grafana/grafana/apps/provisioning/pkg/generated/clientset/versioned/typed/provisioning/v0alpha1"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func newTestClientset() provisioningv0alpha1.ProvisioningV0alpha1Interface {
@@ -176,3 +180,148 @@ func TestRenewLease_ThenUpdateDoesNotConflict(t *testing.T) {
 		"Update after RenewLease should not conflict. "+
 			"If it does, RenewLease stored a stale ResourceVersion.")
 }
+
+func TestClaim_RepositoryConcurrencyLimit(t *testing.T) {
+	clientset := fakeclientset.NewSimpleClientset() //nolint:staticcheck // see newTestClientset
+	clientset.PrependReactor("get", "repositories", func(action k8testing.Action) (bool, runtime.Object, error) {
+		if action.(k8testing.GetAction).GetName() == "broken" {
+			return true, nil, errors.New("storage unavailable")
+		}
+		return false, nil, nil
+	})
+	fakeClient := clientset.ProvisioningV0alpha1()
+
+	store := &persistentStore{
+		client:       fakeClient,
+		clock:        time.Now,
+		expiry:       30 * time.Second,
+		queueMetrics: RegisterQueueMetrics(prometheus.NewRegistry()),
+	}
+
+	ctx, _, err := identity.WithProvisioningIdentity(context.Background(), "stacks-123")
+	require.NoError(t, err)
+
+	createRepo := func(name string, limit int64) {
+		_, err := fakeClient.Repositories("stacks-123").Create(ctx, &provisioning.Repository{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "stacks-123"},
+			Spec: provisioning.RepositorySpec{
+				Sync: provisioning.SyncOptions{MaxConcurrentJobs: limit},
+			},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	newJob := func(name, repo string) *provisioning.Job {
+		return &provisioning.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "stacks-123", Labels: map[string]string{
+				LabelRepository: repo,
+			}},
+			Spec: provisioning.JobSpec{
+				Repository: repo,
+				Action:     provisioning.JobActionPull,
+			},
+		}
+	}
+	createJob := func(name, repo string) {
+		_, err := fakeClient.Jobs("stacks-123").Create(ctx, newJob(name, repo), metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	t.Run("one job at a time", func(t *testing.T) {
+		createRepo("limited", 1)
+		createJob("limited-a", "limited")
+		createJob("limited-b", "limited")
+
+		first, _, err := store.Claim(ctx)
+		require.NoError(t, err)
+
+		_, _, err = store.Claim(ctx)
+		require.ErrorIs(t, err, ErrNoJobs, "the repository already runs as many jobs as it allows")
+
+		require.NoError(t, store.Complete(ctx, first))
+
+		second, _, err := store.Claim(ctx)
+		require.NoError(t, err)
+		require.NotEqual(t, first.GetName(), second.GetName())
+		require.NoError(t, store.Complete(ctx, second))
+	})
+
+	t.Run("a new limit applies to the queued jobs", func(t *testing.T) {
+		createJob("limited-c", "limited")
+		createJob("limited-d", "limited")
+
+		first, _, err := store.Claim(ctx)
+		require.NoError(t, err)
+		_, _, err = store.Claim(ctx)
+		require.ErrorIs(t, err, ErrNoJobs)
+
+		repo, err := fakeClient.Repositories("stacks-123").Get(ctx, "limited", metav1.GetOptions{})
+		require.NoError(t, err)
+		repo.Spec.Sync.MaxConcurrentJobs = 2
+		_, err = fakeClient.Repositories("stacks-123").Update(ctx, repo, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		second, _, err := store.Claim(ctx)
+		require.NoError(t, err)
+		require.NotEqual(t, first.GetName(), second.GetName())
+		require.NoError(t, store.Complete(ctx, first))
+		require.NoError(t, store.Complete(ctx, second))
+	})
+
+	t.Run("rollback frees the slot", func(t *testing.T) {
+		createRepo("single", 1)
+		createJob("single-a", "single")
+
+		job, rollback, err := store.Claim(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "single-a", job.GetName())
+
+		rollback()
+
+		job, _, err = store.Claim(ctx)
+		require.NoError(t, err)
+		require.NoError(t, store.Complete(ctx, job))
+	})
+
+	t.Run("a job claimed along with another one over the limit is given back", func(t *testing.T) {
+		createRepo("raced", 1)
+		createJob("raced-a", "raced")
+
+		// Another worker claims a job of the repository while this one claims raced-a
+		other := newJob("raced-b", "raced")
+		other.Labels[LabelJobClaim] = "1000000000000"
+		raced := false
+		clientset.PrependReactor("update", "jobs", func(action k8testing.Action) (bool, runtime.Object, error) {
+			obj := action.(k8testing.UpdateAction).GetObject().(*provisioning.Job)
+			if obj.GetName() == "raced-a" && !raced {
+				raced = true
+				require.NoError(t, clientset.Tracker().Add(other))
+			}
+			return false, nil, nil
+		})
+
+		_, _, err := store.Claim(ctx)
+		require.ErrorIs(t, err, ErrNoJobs)
+
+		given, err := fakeClient.Jobs("stacks-123").Get(ctx, "raced-a", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.NotContains(t, given.Labels, LabelJobClaim)
+
+		require.NoError(t, store.Complete(ctx, other))
+		job, _, err := store.Claim(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "raced-a", job.GetName())
+		require.NoError(t, store.Complete(ctx, job))
+	})
+
+	t.Run("repositories that can not be looked up are skipped", func(t *testing.T) {
+		createJob("broken-a", "broken")
+		createJob("unlimited-a", "unlimited")
+
+		job, _, err := store.Claim(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "unlimited-a", job.GetName())
+
+		_, _, err = store.Claim(ctx)
+		require.ErrorIs(t, err, ErrNoJobs)
+	})
+}
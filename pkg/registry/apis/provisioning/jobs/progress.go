@@ -55,6 +55,8 @@ type jobProgressRecorder struct {
 	failedDeletions     []string // Tracks resource paths that failed to be deleted
 	failedUpdates       []string // Tracks resource paths that failed to be updated
 	resultReasons       map[string]struct{}
+	changes             []provisioning.JobResourceChange
+	changesDropped      bool
 	metrics             *JobMetrics
 	action              provisioning.JobAction
 }
@@ -168,6 +170,24 @@ func (r *jobProgressRecorder) Record(ctx context.Context, result JobResourceResu
 	r.maybeNotify(ctx)
 }
 
+// maxJobChanges bounds the changes kept in the status of a job. Jobs changing more resources can not be
+// rolled back, as restoring only some of their changes would leave the resources inconsistent.
+const maxJobChanges = 1000
+
+func (r *jobProgressRecorder) RecordChange(change provisioning.JobResourceChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.changesDropped {
+		return
+	}
+	if len(r.changes) >= maxJobChanges {
+		r.changes = nil
+		r.changesDropped = true
+		return
+	}
+	r.changes = append(r.changes, change)
+}
+
 // ResetResults will reset the results of the job.
 // If the keepWarnings flag is set to true, the summary will preserve the warnings in it.
 func (r *jobProgressRecorder) ResetResults(keepWarnings bool) {
@@ -371,9 +391,13 @@ func (r *jobProgressRecorder) Complete(ctx context.Context, err error) provision
 	for _, summary := range summaries {
 		warnings = append(warnings, summary.Warnings...)
 	}
+	if r.changesDropped {
+		warnings = append(warnings, fmt.Sprintf("the job changed more than %d resources and can not be rolled back", maxJobChanges))
+	}
 	jobStatus.Warnings = warnings
 
 	jobStatus.URLs = r.refURLs
+	jobStatus.Changes = r.changes
 
 	tooManyErrors := r.maxErrors > 0 && r.errorCount >= r.maxErrors
 	finalMessage := r.finalMessage
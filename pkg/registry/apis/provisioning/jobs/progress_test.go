@@ -3,6 +3,7 @@ package jobs
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 
@@ -191,6 +192,52 @@ func TestJobProgressRecorderCompleteIncludesRefURLs(t *testing.T) {
 	assert.Equal(t, "completed successfully", finalStatus.Message)
 }
 
+func TestJobProgressRecorderCompleteIncludesChanges(t *testing.T) {
+	ctx := context.Background()
+	recorder := newJobProgressRecorder(func(ctx context.Context, status provisioning.JobStatus) error {
+		return nil
+	}, nil, "")
+
+	// Changes are reported through the context by the resource writers
+	changeCtx := resources.WithChangeRecorder(ctx, recorder.RecordChange)
+	resources.RecordChange(changeCtx, provisioning.JobResourceChange{
+		Group: "dashboard.grafana.app", Resource: "dashboards", Name: "a", Path: "a.json",
+		Action: provisioning.ResourceActionCreate, Version: "2",
+	})
+	resources.RecordChange(changeCtx, provisioning.JobResourceChange{
+		Group: "dashboard.grafana.app", Resource: "dashboards", Name: "b", Path: "b.json",
+		Action: provisioning.ResourceActionUpdate, PreviousVersion: "3", Version: "4",
+	})
+
+	// Resetting results does not forget what was written
+	recorder.ResetResults(false)
+
+	finalStatus := recorder.Complete(ctx, nil)
+	require.Len(t, finalStatus.Changes, 2)
+	assert.Equal(t, "a", finalStatus.Changes[0].Name)
+	assert.Equal(t, "3", finalStatus.Changes[1].PreviousVersion)
+	assert.Equal(t, "4", finalStatus.Changes[1].Version)
+}
+
+func TestJobProgressRecorderDropsTooManyChanges(t *testing.T) {
+	ctx := context.Background()
+	recorder := newJobProgressRecorder(func(ctx context.Context, status provisioning.JobStatus) error {
+		return nil
+	}, nil, "")
+
+	for i := 0; i <= maxJobChanges; i++ {
+		recorder.RecordChange(provisioning.JobResourceChange{
+			Group: "dashboard.grafana.app", Resource: "dashboards", Name: fmt.Sprintf("d%d", i),
+			Action: provisioning.ResourceActionCreate, Version: "1",
+		})
+	}
+
+	finalStatus := recorder.Complete(ctx, nil)
+	require.Empty(t, finalStatus.Changes, "a part of the changes can not be rolled back")
+	require.Equal(t, provisioning.JobStateWarning, finalStatus.State)
+	require.Contains(t, finalStatus.Warnings[0], "can not be rolled back")
+}
+
 func TestJobProgressRecorderWarningStatus(t *testing.T) {
 	ctx := context.Background()
 
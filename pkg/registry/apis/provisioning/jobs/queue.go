@@ -5,7 +5,13 @@ import (
 	"fmt"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	client "github.com/grafana/grafana/apps/provisioning/pkg/generated/clientset/versioned/typed/provisioning/v0alpha1"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 )
 
@@ -22,6 +28,8 @@ type RepoGetter interface {
 type JobProgressRecorder interface {
 	Started() time.Time
 	Record(ctx context.Context, result JobResourceResult)
+	// RecordChange keeps the resource versions around a change applied by the job
+	RecordChange(change provisioning.JobResourceChange)
 	ResetResults(keepWarnings bool)
 	SetFinalMessage(ctx context.Context, msg string)
 	SetMessage(ctx context.Context, msg string)
@@ -78,6 +86,52 @@ func ValidateRepoForCleanup(repo repository.Repository) error {
 	return fmt.Errorf("repository %q was recreated since cleanup job was queued; aborting", cfg.Name)
 }
 
+// A repository with a job limit (spec.sync.maxConcurrentJobs) runs at most that many of its jobs at the
+// same time. The claimed jobs of the repository are its slots: a worker claims a job, then counts the other
+// claimed jobs of the repository and gives the job back when they already fill the limit. Every worker
+// counts once its own claim is stored, so of two workers racing for the last slot, the last to count
+// always sees the claim of the other; at worst both give their job back and claim it again later. The limit is read when the job is claimed, so a new limit applies
+// to the jobs already queued.
+
+// repositoryJobLimit returns how many jobs of the repository can run at the same time, or 0 when the
+// number is not limited. Repositories that do not exist are not limited; their jobs fail on their own.
+func repositoryJobLimit(ctx context.Context, c client.ProvisioningV0alpha1Interface, namespace, name string) (int64, error) {
+	repo, err := c.Repositories(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return repo.Spec.Sync.MaxConcurrentJobs, nil
+}
+
+// countClaimedJobs counts the claimed jobs of the repository, leaving out the job named except.
+func countClaimedJobs(ctx context.Context, c client.ProvisioningV0alpha1Interface, namespace, repo, except string) (int64, error) {
+	claimed, err := labels.NewRequirement(LabelJobClaim, selection.Exists, nil)
+	if err != nil {
+		return 0, err
+	}
+	ofRepo, err := labels.NewRequirement(LabelRepository, selection.Equals, []string{repo})
+	if err != nil {
+		return 0, err
+	}
+	jobs, err := c.Jobs(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.NewSelector().Add(*claimed, *ofRepo).String(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for _, job := range jobs.Items {
+		if job.GetName() != except {
+			count++
+		}
+	}
+	return count, nil
+}
+
 // ProgressFn is a function that can be called to update the progress of a job
 //
 //go:generate mockery --name ProgressFn --structname MockProgressFn --inpackage --filename progress_fn_mock.go --with-expecter
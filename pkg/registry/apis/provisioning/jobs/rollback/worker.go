@@ -0,0 +1,416 @@
+package rollback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	apiutils "github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/utils"
+)
+
+// ValidateTarget checks that a finished job can be rolled back
+func ValidateTarget(target *provisioning.Job) error {
+	if target.Status.Finished == 0 {
+		return fmt.Errorf("job %s has not finished", target.Name)
+	}
+	if target.Spec.Rollback != nil && target.Spec.Rollback.DryRun {
+		return fmt.Errorf("job %s is a dry run and did not change any resource", target.Name)
+	}
+	if len(target.Status.Changes) == 0 {
+		return fmt.Errorf("job %s did not record any resource change", target.Name)
+	}
+	return nil
+}
+
+// Worker restores the resources changed by a previous job to the versions they had before it ran.
+// Repositories allowing writes get the previous files committed back instead, so the next sync
+// does not undo the rollback.
+type Worker struct {
+	history       jobs.HistoryReader
+	clientFactory resources.ClientFactory
+	syncWorker    jobs.Worker
+	wrapFn        repository.WrapWithStageFn
+	metrics       jobs.JobMetrics
+}
+
+func NewWorker(history jobs.HistoryReader, clientFactory resources.ClientFactory, syncWorker jobs.Worker, wrapFn repository.WrapWithStageFn, metrics jobs.JobMetrics) *Worker {
+	return &Worker{
+		history:       history,
+		clientFactory: clientFactory,
+		syncWorker:    syncWorker,
+		wrapFn:        wrapFn,
+		metrics:       metrics,
+	}
+}
+
+func (w *Worker) IsSupported(_ context.Context, job provisioning.Job) bool {
+	return job.Spec.Action == provisioning.JobActionRollback
+}
+
+// restore is the planned rollback of a single change
+type restore struct {
+	change  provisioning.JobResourceChange
+	gvk     schema.GroupVersionKind
+	client  dynamic.ResourceInterface
+	current *unstructured.Unstructured
+	// previous is the version to restore, nil when the resource is removed
+	previous *unstructured.Unstructured
+}
+
+func (r restore) fileAction() repository.FileAction {
+	switch {
+	case r.previous == nil:
+		return repository.FileActionDeleted
+	case r.current == nil:
+		return repository.FileActionCreated
+	default:
+		return repository.FileActionUpdated
+	}
+}
+
+func (r restore) result(err error) jobs.JobResourceResult {
+	result := jobs.NewGVKResult(r.change.Name, r.gvk).WithPath(r.change.Path).WithAction(r.fileAction())
+	if err != nil {
+		result.WithError(err)
+	}
+	return result.Build()
+}
+
+func (w *Worker) Process(ctx context.Context, repo repository.Repository, job provisioning.Job, progress jobs.JobProgressRecorder) (processErr error) {
+	if job.Spec.Rollback == nil {
+		return errors.New("missing rollback settings")
+	}
+	opts := *job.Spec.Rollback
+
+	logger := logging.FromContext(ctx).With("options", opts)
+	ctx = logging.Context(ctx, logger)
+	ctx, span := tracing.Start(ctx, "provisioning.rollback.process")
+	defer func() {
+		if processErr != nil {
+			_ = tracing.Error(span, processErr)
+		}
+		span.End()
+	}()
+	span.SetAttributes(
+		attribute.String("rollback.job", opts.Job),
+		attribute.Bool("rollback.dry_run", opts.DryRun),
+	)
+
+	start := time.Now()
+	outcome := utils.ErrorOutcome
+	restored := 0
+	defer func() {
+		w.metrics.RecordJob(string(provisioning.JobActionRollback), outcome, restored, time.Since(start).Seconds())
+	}()
+
+	progress.SetMessage(ctx, "find job to roll back")
+	target, err := w.history.GetJob(ctx, job.Namespace, job.Spec.Repository, opts.Job)
+	if err != nil {
+		return fmt.Errorf("get job to roll back: %w", err)
+	}
+	if err := ValidateTarget(target); err != nil {
+		return err
+	}
+
+	clients, err := w.clientFactory.Clients(ctx, job.Namespace)
+	if err != nil {
+		return fmt.Errorf("create resource clients: %w", err)
+	}
+
+	progress.SetTotal(ctx, len(target.Status.Changes))
+	progress.SetMessage(ctx, "plan rollback")
+	plan, err := w.plan(ctx, clients, target.Status.Changes, progress)
+	if err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		for _, r := range plan {
+			progress.Record(ctx, r.result(nil))
+			progress.RecordChange(r.change)
+		}
+		progress.SetFinalMessage(ctx, fmt.Sprintf("dry run: %d resources would be restored", len(plan)))
+		outcome = utils.SuccessOutcome
+		return nil
+	}
+
+	if rw, ok := repo.(repository.ReaderWriter); ok && repository.IsWriteAllowed(repo.Config(), "") == nil {
+		err = w.revertFiles(ctx, rw, job, target, plan, progress)
+	} else {
+		err = w.restoreResources(ctx, plan, progress)
+	}
+	if err != nil {
+		return err
+	}
+
+	outcome = utils.SuccessOutcome
+	restored = len(plan)
+	return nil
+}
+
+// plan walks the changes of the job backwards and resolves the version each resource is restored to.
+// Resources changed again since the job ran are skipped with a warning.
+func (w *Worker) plan(ctx context.Context, clients resources.ResourceClients, changes []provisioning.JobResourceChange, progress jobs.JobProgressRecorder) ([]restore, error) {
+	changes = squashChanges(changes)
+	plan := make([]restore, 0, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		gvr := schema.GroupVersionResource{Group: change.Group, Resource: change.Resource}
+
+		client, gvk, err := clients.ForResource(ctx, gvr)
+		if err != nil {
+			progress.Record(ctx, jobs.NewGroupKindResult(change.Name, change.Group, change.Resource).
+				WithPath(change.Path).
+				WithError(fmt.Errorf("get client for %s/%s: %w", change.Group, change.Resource, err)).Build())
+			if err := progress.TooManyErrors(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		r := restore{
+			change: provisioning.JobResourceChange{
+				Group:    change.Group,
+				Resource: change.Resource,
+				Name:     change.Name,
+				Path:     change.Path,
+			},
+			gvk:    gvk,
+			client: client,
+		}
+
+		current, err := client.Get(ctx, change.Name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			r.current = current
+		case !apierrors.IsNotFound(err):
+			progress.Record(ctx, jobs.NewGVKResult(change.Name, gvk).WithPath(change.Path).
+				WithError(fmt.Errorf("get resource %s: %w", change.Name, err)).Build())
+			if err := progress.TooManyErrors(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var currentVersion string
+		if r.current != nil {
+			currentVersion = r.current.GetResourceVersion()
+		}
+		if currentVersion != change.Version {
+			progress.Record(ctx, jobs.NewGVKResult(change.Name, gvk).WithPath(change.Path).
+				WithWarning(fmt.Errorf("resource %s changed after the job ran", change.Name)).AsSkipped().Build())
+			continue
+		}
+
+		if change.Action != provisioning.ResourceActionCreate {
+			previous, err := previousVersion(ctx, client, gvr.GroupResource(), change.Name, change.PreviousVersion)
+			if err != nil {
+				progress.Record(ctx, jobs.NewGVKResult(change.Name, gvk).WithPath(change.Path).
+					WithError(fmt.Errorf("get version %s of %s: %w", change.PreviousVersion, change.Name, err)).Build())
+				if err := progress.TooManyErrors(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			r.previous = previous
+		}
+
+		// Created and deleted again by the job, nothing to restore
+		if r.current == nil && r.previous == nil {
+			continue
+		}
+
+		// The rollback records the opposite change so it can be rolled back too
+		r.change.PreviousVersion = currentVersion
+		switch r.fileAction() {
+		case repository.FileActionDeleted:
+			r.change.Action = provisioning.ResourceActionDelete
+		case repository.FileActionCreated:
+			r.change.Action = provisioning.ResourceActionCreate
+		default:
+			r.change.Action = provisioning.ResourceActionUpdate
+		}
+
+		plan = append(plan, r)
+	}
+	return plan, nil
+}
+
+// previousVersion reads a version of the resource from its history, or from the trash when the resource
+// is deleted
+func previousVersion(ctx context.Context, client dynamic.ResourceInterface, gr schema.GroupResource, name, version string) (*unstructured.Unstructured, error) {
+	for _, label := range []string{apiutils.LabelKeyGetHistory, apiutils.LabelKeyGetTrash} {
+		var continueToken string
+		for {
+			list, err := client.List(ctx, metav1.ListOptions{
+				LabelSelector: label + "=true",
+				FieldSelector: "metadata.name=" + name,
+				Limit:         100,
+				Continue:      continueToken,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				if list.Items[i].GetResourceVersion() == version {
+					return &list.Items[i], nil
+				}
+			}
+			continueToken = list.GetContinue()
+			if continueToken == "" || len(list.Items) == 0 {
+				break
+			}
+		}
+	}
+	return nil, apierrors.NewNotFound(gr, name)
+}
+
+// squashChanges merges the changes made to the same resource, so it is restored to the version
+// it had before the first one
+func squashChanges(changes []provisioning.JobResourceChange) []provisioning.JobResourceChange {
+	squashed := make([]provisioning.JobResourceChange, 0, len(changes))
+	index := make(map[schema.GroupResource]map[string]int, len(changes))
+	for _, change := range changes {
+		gr := schema.GroupResource{Group: change.Group, Resource: change.Resource}
+		if index[gr] == nil {
+			index[gr] = map[string]int{}
+		}
+		i, ok := index[gr][change.Name]
+		if !ok {
+			index[gr][change.Name] = len(squashed)
+			squashed = append(squashed, change)
+			continue
+		}
+
+		first := &squashed[i]
+		first.Version = change.Version
+		if first.Action != provisioning.ResourceActionCreate {
+			first.Action = change.Action
+		}
+	}
+	return squashed
+}
+
+// restoreResources writes the previous versions directly to Grafana
+func (w *Worker) restoreResources(ctx context.Context, plan []restore, progress jobs.JobProgressRecorder) error {
+	for _, r := range plan {
+		progress.SetMessage(ctx, "restore "+r.change.Name)
+
+		var written *unstructured.Unstructured
+		var err error
+		switch {
+		case r.previous == nil:
+			err = r.client.Delete(ctx, r.change.Name, metav1.DeleteOptions{})
+		case r.current == nil:
+			obj := r.previous.DeepCopy()
+			obj.SetResourceVersion("")
+			obj.SetUID("")
+			written, err = r.client.Create(ctx, obj, metav1.CreateOptions{})
+		default:
+			obj := r.previous.DeepCopy()
+			obj.SetResourceVersion(r.current.GetResourceVersion())
+			written, err = r.client.Update(ctx, obj, metav1.UpdateOptions{})
+		}
+
+		if err != nil {
+			err = fmt.Errorf("restore %s: %w", r.change.Name, err)
+		} else {
+			change := r.change
+			if written != nil {
+				change.Version = written.GetResourceVersion()
+			}
+			progress.RecordChange(change)
+		}
+		progress.Record(ctx, r.result(err))
+		if err := progress.TooManyErrors(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revertFiles commits the previous versions to the repository and pulls them into Grafana
+func (w *Worker) revertFiles(ctx context.Context, rw repository.ReaderWriter, job provisioning.Job, target *provisioning.Job, plan []restore, progress jobs.JobProgressRecorder) error {
+	progress.StrictMaxErrors(1) // A partial revert is not committed
+
+	fn := func(repo repository.Repository, _ bool) error {
+		staged, ok := repo.(repository.ReaderWriter)
+		if !ok {
+			return errors.New("rollback job submitted targeting repository that is not a ReaderWriter")
+		}
+
+		for _, r := range plan {
+			// Generated resources are changed through the files they are generated from, which the
+			// job did not record
+			if resources.IsSourceFile(r.change.Path) || resources.IsTemplateValuesFile(r.change.Path) {
+				progress.Record(ctx, jobs.NewGVKResult(r.change.Name, r.gvk).WithPath(r.change.Path).
+					WithWarning(fmt.Errorf("resource %s is generated from %s, which must be reverted in the repository", r.change.Name, r.change.Path)).
+					AsSkipped().Build())
+				continue
+			}
+
+			progress.SetMessage(ctx, "revert "+r.change.Path)
+
+			var err error
+			if r.previous == nil {
+				err = staged.Delete(ctx, r.change.Path, "", "Delete "+r.change.Path)
+			} else {
+				parsed := resources.ParsedResource{Info: &repository.FileInfo{Path: r.change.Path}, Obj: r.previous}
+				var body []byte
+				if body, err = parsed.ToSaveBytes(); err == nil {
+					err = staged.Write(ctx, r.change.Path, "", body, "Revert "+r.change.Path)
+				}
+			}
+			if err != nil {
+				err = fmt.Errorf("revert file %s: %w", r.change.Path, err)
+			}
+
+			progress.Record(ctx, r.result(err))
+			if err := progress.TooManyErrors(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	msg := fmt.Sprintf("Roll back job %s", target.Name)
+	stageOptions := repository.StageOptions{
+		Mode:                  repository.StageModeCommitOnlyOnce,
+		CommitOnlyOnceMessage: jobs.CommitMessage(job, msg),
+		Timeout:               10 * time.Minute,
+	}
+	if err := w.wrapFn(ctx, rw, stageOptions, fn); err != nil {
+		return fmt.Errorf("revert files in repository: %w", err)
+	}
+
+	// The synced resources record their own changes
+	progress.ResetResults(true)
+	progress.SetMessage(ctx, "pull resources")
+	syncJob := provisioning.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: job.Name, Namespace: job.Namespace},
+		Spec: provisioning.JobSpec{
+			Action:     provisioning.JobActionPull,
+			Repository: job.Spec.Repository,
+			Pull:       &provisioning.SyncJobOptions{Incremental: false},
+		},
+	}
+	if err := w.syncWorker.Process(ctx, rw, syncJob, progress); err != nil {
+		return fmt.Errorf("pull resources: %w", err)
+	}
+	return nil
+}
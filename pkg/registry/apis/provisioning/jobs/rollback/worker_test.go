@@ -0,0 +1,318 @@
+package rollback
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+)
+
+var dashboardGVR = schema.GroupVersionResource{Group: "dashboard.grafana.app", Resource: "dashboards"}
+
+// fakeVersionedClient keeps the current objects and the previous versions, listed in the history of
+// the current objects and in the trash for the deleted ones
+type fakeVersionedClient struct {
+	dynamic.ResourceInterface
+	current  map[string]*unstructured.Unstructured
+	versions map[string]*unstructured.Unstructured
+	deleted  []string
+}
+
+func newDashboard(name, rv, title string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind":       "Dashboard",
+		"metadata":   map[string]any{"name": name, "resourceVersion": rv},
+		"spec":       map[string]any{"title": title},
+	}}
+}
+
+func (f *fakeVersionedClient) Get(_ context.Context, name string, _ metav1.GetOptions, _ ...string) (*unstructured.Unstructured, error) {
+	obj, ok := f.current[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(dashboardGVR.GroupResource(), name)
+	}
+	return obj.DeepCopy(), nil
+}
+
+func (f *fakeVersionedClient) List(_ context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	name := strings.TrimPrefix(opts.FieldSelector, "metadata.name=")
+	_, exists := f.current[name]
+	list := &unstructured.UnstructuredList{}
+	if (opts.LabelSelector == utils.LabelKeyGetHistory+"=true") != exists {
+		return list, nil
+	}
+	for key, obj := range f.versions {
+		if strings.HasPrefix(key, name+"@") {
+			list.Items = append(list.Items, *obj.DeepCopy())
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeVersionedClient) Create(_ context.Context, obj *unstructured.Unstructured, _ metav1.CreateOptions, _ ...string) (*unstructured.Unstructured, error) {
+	created := obj.DeepCopy()
+	created.SetResourceVersion("10")
+	f.current[obj.GetName()] = created
+	return created, nil
+}
+
+func (f *fakeVersionedClient) Update(_ context.Context, obj *unstructured.Unstructured, _ metav1.UpdateOptions, _ ...string) (*unstructured.Unstructured, error) {
+	updated := obj.DeepCopy()
+	updated.SetResourceVersion("11")
+	f.current[obj.GetName()] = updated
+	return updated, nil
+}
+
+func (f *fakeVersionedClient) Delete(_ context.Context, name string, _ metav1.DeleteOptions, _ ...string) error {
+	delete(f.current, name)
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+func TestValidateTarget(t *testing.T) {
+	changes := []provisioning.JobResourceChange{{Name: "a", Action: provisioning.ResourceActionCreate, Version: "1"}}
+
+	require.NoError(t, ValidateTarget(&provisioning.Job{Status: provisioning.JobStatus{Finished: 1, Changes: changes}}))
+	require.ErrorContains(t, ValidateTarget(&provisioning.Job{Status: provisioning.JobStatus{Changes: changes}}), "has not finished")
+	require.ErrorContains(t, ValidateTarget(&provisioning.Job{Status: provisioning.JobStatus{Finished: 1}}), "did not record any resource change")
+	require.ErrorContains(t, ValidateTarget(&provisioning.Job{
+		Spec:   provisioning.JobSpec{Rollback: &provisioning.RollbackJobOptions{Job: "x", DryRun: true}},
+		Status: provisioning.JobStatus{Finished: 1, Changes: changes},
+	}), "is a dry run")
+}
+
+func TestSquashChanges(t *testing.T) {
+	squashed := squashChanges([]provisioning.JobResourceChange{
+		{Group: "g", Resource: "r", Name: "a", Action: provisioning.ResourceActionCreate, Version: "1"},
+		{Group: "g", Resource: "r", Name: "b", Action: provisioning.ResourceActionUpdate, PreviousVersion: "2", Version: "3"},
+		{Group: "g", Resource: "r", Name: "a", Action: provisioning.ResourceActionUpdate, PreviousVersion: "1", Version: "4"},
+		{Group: "g", Resource: "r", Name: "b", Action: provisioning.ResourceActionDelete, PreviousVersion: "3"},
+	})
+	require.Equal(t, []provisioning.JobResourceChange{
+		{Group: "g", Resource: "r", Name: "a", Action: provisioning.ResourceActionCreate, Version: "4"},
+		{Group: "g", Resource: "r", Name: "b", Action: provisioning.ResourceActionDelete, PreviousVersion: "2"},
+	}, squashed)
+}
+
+type rollbackFixture struct {
+	worker   *Worker
+	client   *fakeVersionedClient
+	progress *jobs.MockJobProgressRecorder
+	wrapFn   *repository.MockWrapWithStageFn
+	sync     *jobs.MockWorker
+	results  []jobs.JobResourceResult
+	changes  []provisioning.JobResourceChange
+}
+
+// newRollbackFixture sets up a sync job that created "created", updated "updated", deleted "deleted"
+// and updated "drifted", which was changed again afterwards
+func newRollbackFixture(t *testing.T, extra ...provisioning.JobResourceChange) *rollbackFixture {
+	f := &rollbackFixture{
+		client: &fakeVersionedClient{
+			current: map[string]*unstructured.Unstructured{
+				"created": newDashboard("created", "2", "created"),
+				"updated": newDashboard("updated", "4", "new title"),
+				"drifted": newDashboard("drifted", "8", "edited in grafana"),
+			},
+			versions: map[string]*unstructured.Unstructured{
+				"updated@3": newDashboard("updated", "3", "old title"),
+				"deleted@5": newDashboard("deleted", "5", "deleted"),
+				"drifted@6": newDashboard("drifted", "6", "drifted"),
+			},
+		},
+		progress: jobs.NewMockJobProgressRecorder(t),
+		wrapFn:   repository.NewMockWrapWithStageFn(t),
+		sync:     jobs.NewMockWorker(t),
+	}
+
+	history := jobs.NewMockHistoryReader(t)
+	history.EXPECT().GetJob(mock.Anything, "default", "my-repo", "sync-uid").Return(&provisioning.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "sync"},
+		Status: provisioning.JobStatus{
+			Finished: 1,
+			Changes: append([]provisioning.JobResourceChange{
+				{Group: dashboardGVR.Group, Resource: dashboardGVR.Resource, Name: "created", Path: "created.json", Action: provisioning.ResourceActionCreate, Version: "2"},
+				{Group: dashboardGVR.Group, Resource: dashboardGVR.Resource, Name: "updated", Path: "updated.json", Action: provisioning.ResourceActionUpdate, PreviousVersion: "3", Version: "4"},
+				{Group: dashboardGVR.Group, Resource: dashboardGVR.Resource, Name: "deleted", Path: "deleted.json", Action: provisioning.ResourceActionDelete, PreviousVersion: "5"},
+				{Group: dashboardGVR.Group, Resource: dashboardGVR.Resource, Name: "drifted", Path: "drifted.json", Action: provisioning.ResourceActionUpdate, PreviousVersion: "6", Version: "7"},
+			}, extra...),
+		},
+	}, nil)
+
+	clients := resources.NewMockResourceClients(t)
+	clients.EXPECT().ForResource(mock.Anything, dashboardGVR).Return(f.client, schema.GroupVersionKind{Group: dashboardGVR.Group, Version: "v1", Kind: "Dashboard"}, nil)
+	clientFactory := resources.NewMockClientFactory(t)
+	clientFactory.EXPECT().Clients(mock.Anything, "default").Return(clients, nil)
+
+	f.progress.On("SetMessage", mock.Anything, mock.Anything).Return().Maybe()
+	f.progress.On("SetTotal", mock.Anything, 4+len(extra)).Return()
+	f.progress.On("TooManyErrors").Return(nil).Maybe()
+	f.progress.On("Record", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		f.results = append(f.results, args.Get(1).(jobs.JobResourceResult))
+	}).Return().Maybe()
+	f.progress.On("RecordChange", mock.Anything).Run(func(args mock.Arguments) {
+		f.changes = append(f.changes, args.Get(0).(provisioning.JobResourceChange))
+	}).Return().Maybe()
+
+	f.worker = NewWorker(history, clientFactory, f.sync, f.wrapFn.Execute, jobs.RegisterJobMetrics(prometheus.NewPedanticRegistry()))
+	return f
+}
+
+func rollbackJob(dryRun bool) provisioning.Job {
+	return provisioning.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "rollback", Namespace: "default"},
+		Spec: provisioning.JobSpec{
+			Action:     provisioning.JobActionRollback,
+			Repository: "my-repo",
+			Rollback:   &provisioning.RollbackJobOptions{Job: "sync-uid", DryRun: dryRun},
+		},
+	}
+}
+
+func readOnlyRepo(t *testing.T) *repository.MockReaderWriter {
+	repo := repository.NewMockReaderWriter(t)
+	repo.EXPECT().Config().Return(&provisioning.Repository{}).Maybe()
+	return repo
+}
+
+func TestWorker_IsSupported(t *testing.T) {
+	w := NewWorker(nil, nil, nil, nil, jobs.RegisterJobMetrics(prometheus.NewPedanticRegistry()))
+	require.True(t, w.IsSupported(context.Background(), rollbackJob(false)))
+	require.False(t, w.IsSupported(context.Background(), provisioning.Job{Spec: provisioning.JobSpec{Action: provisioning.JobActionPull}}))
+}
+
+func TestWorker_ProcessMissingOptions(t *testing.T) {
+	w := NewWorker(nil, nil, nil, nil, jobs.RegisterJobMetrics(prometheus.NewPedanticRegistry()))
+	err := w.Process(context.Background(), nil, provisioning.Job{Spec: provisioning.JobSpec{Action: provisioning.JobActionRollback}}, jobs.NewMockJobProgressRecorder(t))
+	require.EqualError(t, err, "missing rollback settings")
+}
+
+func TestWorker_ProcessDryRun(t *testing.T) {
+	f := newRollbackFixture(t)
+	f.progress.On("SetFinalMessage", mock.Anything, "dry run: 3 resources would be restored").Return()
+
+	err := f.worker.Process(context.Background(), readOnlyRepo(t), rollbackJob(true), f.progress)
+	require.NoError(t, err)
+
+	// Nothing is written
+	require.Empty(t, f.client.deleted)
+	require.Equal(t, "4", f.client.current["updated"].GetResourceVersion())
+	require.NotContains(t, f.client.current, "deleted")
+
+	// Changes are listed newest first, with the drifted resource skipped
+	require.Len(t, f.changes, 3)
+	require.Equal(t, "deleted", f.changes[0].Name)
+	require.Equal(t, provisioning.ResourceActionCreate, f.changes[0].Action)
+	require.Equal(t, "updated", f.changes[1].Name)
+	require.Equal(t, provisioning.ResourceActionUpdate, f.changes[1].Action)
+	require.Equal(t, "created", f.changes[2].Name)
+	require.Equal(t, provisioning.ResourceActionDelete, f.changes[2].Action)
+
+	require.Len(t, f.results, 4)
+	require.Equal(t, "drifted", f.results[0].Name())
+	require.ErrorContains(t, f.results[0].Warning(), "changed after the job ran")
+}
+
+func TestWorker_ProcessRestoresResources(t *testing.T) {
+	f := newRollbackFixture(t)
+
+	err := f.worker.Process(context.Background(), readOnlyRepo(t), rollbackJob(false), f.progress)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"created"}, f.client.deleted)
+	require.Equal(t, "old title", f.client.current["updated"].Object["spec"].(map[string]any)["title"])
+	require.Contains(t, f.client.current, "deleted")
+	require.Equal(t, "8", f.client.current["drifted"].GetResourceVersion())
+
+	// The rollback records its own changes so it can be undone
+	require.Equal(t, []provisioning.JobResourceChange{
+		{Group: dashboardGVR.Group, Resource: dashboardGVR.Resource, Name: "deleted", Path: "deleted.json", Action: provisioning.ResourceActionCreate, Version: "10"},
+		{Group: dashboardGVR.Group, Resource: dashboardGVR.Resource, Name: "updated", Path: "updated.json", Action: provisioning.ResourceActionUpdate, PreviousVersion: "4", Version: "11"},
+		{Group: dashboardGVR.Group, Resource: dashboardGVR.Resource, Name: "created", Path: "created.json", Action: provisioning.ResourceActionDelete, PreviousVersion: "2"},
+	}, f.changes)
+}
+
+func TestWorker_ProcessRevertsFilesInWritableRepository(t *testing.T) {
+	f := newRollbackFixture(t)
+
+	repo := repository.NewMockReaderWriter(t)
+	repo.EXPECT().Config().Return(&provisioning.Repository{
+		Spec: provisioning.RepositorySpec{Workflows: []provisioning.Workflow{provisioning.WriteWorkflow}},
+	})
+	repo.EXPECT().Write(mock.Anything, "deleted.json", "", mock.Anything, mock.Anything).Return(nil)
+	repo.EXPECT().Write(mock.Anything, "updated.json", "", mock.MatchedBy(func(body []byte) bool {
+		return !strings.Contains(string(body), "resourceVersion") && strings.Contains(string(body), "old title")
+	}), mock.Anything).Return(nil)
+	repo.EXPECT().Delete(mock.Anything, "created.json", "", mock.Anything).Return(nil)
+
+	f.progress.On("StrictMaxErrors", 1).Return()
+	f.progress.On("ResetResults", true).Return()
+	f.wrapFn.EXPECT().Execute(mock.Anything, repo, mock.MatchedBy(func(opts repository.StageOptions) bool {
+		return opts.Mode == repository.StageModeCommitOnlyOnce && opts.CommitOnlyOnceMessage == "Roll back job sync"
+	}), mock.Anything).RunAndReturn(func(_ context.Context, repo repository.Repository, _ repository.StageOptions, fn func(repository.Repository, bool) error) error {
+		return fn(repo, true)
+	})
+	f.sync.EXPECT().Process(mock.Anything, repo, mock.MatchedBy(func(job provisioning.Job) bool {
+		return job.Name == "rollback" && job.Spec.Action == provisioning.JobActionPull && job.Spec.Repository == "my-repo" &&
+			job.Spec.Pull != nil && !job.Spec.Pull.Incremental
+	}), f.progress).Return(nil)
+
+	err := f.worker.Process(context.Background(), repo, rollbackJob(false), f.progress)
+	require.NoError(t, err)
+
+	// Grafana is only updated by the sync of the reverted files
+	require.Empty(t, f.client.deleted)
+	require.Empty(t, f.changes)
+}
+
+func TestWorker_ProcessSkipsGeneratedResourcesInWritableRepository(t *testing.T) {
+	f := newRollbackFixture(t,
+		provisioning.JobResourceChange{Group: dashboardGVR.Group, Resource: dashboardGVR.Resource, Name: "jsonnet", Path: "dashboards/a.jsonnet", Action: provisioning.ResourceActionUpdate, PreviousVersion: "12", Version: "13"},
+		provisioning.JobResourceChange{Group: dashboardGVR.Group, Resource: dashboardGVR.Resource, Name: "instance", Path: "teams/a.values.yaml", Action: provisioning.ResourceActionUpdate, PreviousVersion: "14", Version: "15"},
+	)
+	f.client.current["jsonnet"] = newDashboard("jsonnet", "13", "generated")
+	f.client.versions["jsonnet@12"] = newDashboard("jsonnet", "12", "generated before")
+	f.client.current["instance"] = newDashboard("instance", "15", "instance")
+	f.client.versions["instance@14"] = newDashboard("instance", "14", "instance before")
+
+	repo := repository.NewMockReaderWriter(t)
+	repo.EXPECT().Config().Return(&provisioning.Repository{
+		Spec: provisioning.RepositorySpec{Workflows: []provisioning.Workflow{provisioning.WriteWorkflow}},
+	})
+	repo.EXPECT().Write(mock.Anything, mock.MatchedBy(func(p string) bool { return strings.HasSuffix(p, ".json") }), "", mock.Anything, mock.Anything).Return(nil)
+	repo.EXPECT().Delete(mock.Anything, "created.json", "", mock.Anything).Return(nil)
+
+	f.progress.On("StrictMaxErrors", 1).Return()
+	f.progress.On("ResetResults", true).Return()
+	f.wrapFn.EXPECT().Execute(mock.Anything, repo, mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, repo repository.Repository, _ repository.StageOptions, fn func(repository.Repository, bool) error) error {
+		return fn(repo, true)
+	})
+	f.sync.EXPECT().Process(mock.Anything, repo, mock.Anything, f.progress).Return(nil)
+
+	err := f.worker.Process(context.Background(), repo, rollbackJob(false), f.progress)
+	require.NoError(t, err)
+
+	skipped := map[string]error{}
+	for _, result := range f.results {
+		if result.Warning() != nil {
+			skipped[result.Path()] = result.Warning()
+		}
+	}
+	require.ErrorContains(t, skipped["dashboards/a.jsonnet"], "must be reverted in the repository")
+	require.ErrorContains(t, skipped["teams/a.values.yaml"], "must be reverted in the repository")
+}
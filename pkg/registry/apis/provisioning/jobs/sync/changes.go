@@ -79,6 +79,7 @@ func Compare(
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("error reading tree: %w", err)
 	}
+	source = resources.NewSourceDirectories(repo.Config().Spec.Sources).Resolve(source)
 
 	changes, err := Changes(ctx, source, target, folderMetadataEnabled)
 	if err != nil {
@@ -110,6 +111,8 @@ func Compare(
 }
 
 // Changes computes the diff between a repository source tree and the current Grafana state (target).
+// Jsonnet and CUE files in the tree are resources, so the tree must be resolved against the
+// source directories of the repository first (see resources.SourceDirectories.Resolve).
 //
 //nolint:gocyclo
 func Changes(
@@ -139,12 +142,17 @@ func Changes(
 
 	keep := safepath.NewTrie()
 	changes := make([]ResourceFileChange, 0, len(source))
+	// Resources instantiated from a template record the hash of their values file and template
+	templateDirs := resources.NewTemplateDirectories(source)
 
 	for _, file := range source {
 		// TODO: why do we have to do this here?
 		if !file.Blob && !strings.HasSuffix(file.Path, "/") {
 			file.Path = file.Path + "/"
 		}
+		if file.Blob {
+			file.Hash = templateDirs.Hash(file)
+		}
 
 		items, ok := lookup[file.Path]
 		if ok {
@@ -44,6 +44,31 @@ func TestChanges(t *testing.T) {
 			Hash:   "xyz",
 		}, changes[0])
 	})
+	t.Run("template changes update the instances of the template", func(t *testing.T) {
+		source := []repository.FileTreeEntry{
+			{Path: "service/", Blob: false},
+			{Path: "service/_template.json", Hash: "template-v2", Blob: true},
+			{Path: "service/dev.values.yaml", Hash: "dev", Blob: true},
+		}
+		target := &provisioning.ResourceList{
+			Items: []provisioning.ResourceListItem{
+				{Path: "service/", Resource: resources.FolderResource.Resource, Group: resources.FolderResource.Group},
+				{Path: "service/dev.values.yaml", Hash: resources.TemplateInstanceHash("dev", "template-v1")},
+			},
+		}
+
+		changes, err := Changes(context.Background(), source, target, true)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, repository.FileActionUpdated, changes[0].Action)
+		require.Equal(t, "service/dev.values.yaml", changes[0].Path)
+
+		target.Items[1].Hash = resources.TemplateInstanceHash("dev", "template-v2")
+		changes, err = Changes(context.Background(), source, target, true)
+		require.NoError(t, err)
+		require.Empty(t, changes)
+	})
+
 	t.Run("empty file path", func(t *testing.T) {
 		source := []repository.FileTreeEntry{}
 		target := &provisioning.ResourceList{
@@ -766,6 +791,7 @@ func TestChanges_DuplicatePaths(t *testing.T) {
 func TestCompare_DuplicateFolderOrphanWithChildren(t *testing.T) {
 	t.Run("reparents orphan folder child before deferred folder cleanup", func(t *testing.T) {
 		repo := repository.NewMockRepository(t)
+		repo.On("Config").Return(&provisioning.Repository{}).Maybe()
 		repoResources := resources.NewMockRepositoryResources(t)
 
 		source := []repository.FileTreeEntry{
@@ -816,6 +842,7 @@ func TestCompare_DuplicateFolderOrphanWithChildren(t *testing.T) {
 
 	t.Run("deletes duplicate orphan child when surviving child already matches source", func(t *testing.T) {
 		repo := repository.NewMockRepository(t)
+		repo.On("Config").Return(&provisioning.Repository{}).Maybe()
 		repoResources := resources.NewMockRepositoryResources(t)
 
 		source := []repository.FileTreeEntry{
@@ -868,6 +895,7 @@ func TestCompare_DuplicateFolderOrphanWithChildren(t *testing.T) {
 
 	t.Run("prefers child under orphan folder UID over stale-hash child under surviving folder", func(t *testing.T) {
 		repo := repository.NewMockRepository(t)
+		repo.On("Config").Return(&provisioning.Repository{}).Maybe()
 		repoResources := resources.NewMockRepositoryResources(t)
 
 		source := []repository.FileTreeEntry{
@@ -927,6 +955,7 @@ func TestCompare_DuplicateFolderOrphanWithChildren(t *testing.T) {
 
 	t.Run("invalid folder metadata suppresses duplicate folder orphan cleanup", func(t *testing.T) {
 		repo := repository.NewMockRepository(t)
+		repo.On("Config").Return(&provisioning.Repository{}).Maybe()
 		repoResources := resources.NewMockRepositoryResources(t)
 
 		source := []repository.FileTreeEntry{
@@ -1073,6 +1102,7 @@ func TestCompare(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := repository.NewMockRepository(t)
+			repo.On("Config").Return(&provisioning.Repository{}).Maybe()
 			repoResources := resources.NewMockRepositoryResources(t)
 
 			tt.setupMocks(repo, repoResources)
@@ -1097,6 +1127,7 @@ func TestCompare(t *testing.T) {
 func TestCompare_FolderMetadataFlagDisabled(t *testing.T) {
 	t.Run("augmentChangesForFolderMetadata skipped when flag off", func(t *testing.T) {
 		repo := repository.NewMockRepository(t)
+		repo.On("Config").Return(&provisioning.Repository{}).Maybe()
 		repoResources := resources.NewMockRepositoryResources(t)
 
 		source := []repository.FileTreeEntry{
@@ -1147,6 +1178,7 @@ func TestCompare_InvalidFolderMetadataWarning(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := repository.NewMockRepository(t)
+			repo.On("Config").Return(&provisioning.Repository{}).Maybe()
 			repoResources := resources.NewMockRepositoryResources(t)
 
 			source := []repository.FileTreeEntry{
@@ -1185,6 +1217,7 @@ func TestCompare_InvalidFolderMetadataWarning(t *testing.T) {
 
 func TestCompare_InvalidCreatedFolderMetadataWarningPreservesFolderCreate(t *testing.T) {
 	repo := repository.NewMockRepository(t)
+	repo.On("Config").Return(&provisioning.Repository{}).Maybe()
 	repoResources := resources.NewMockRepositoryResources(t)
 
 	source := []repository.FileTreeEntry{
@@ -0,0 +1,352 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+)
+
+// ConflictChecker looks for resources that changed both in Grafana and in the repository since the
+// last sync, and resolves the conflicts. Syncs without a ConflictChecker apply the repository changes
+// over Grafana.
+type ConflictChecker interface {
+	// PreviousRef is the ref of the last sync, which Grafana is compared with
+	PreviousRef() string
+	// Check returns the conflict for the change, or nil when only the repository changed the resource
+	Check(ctx context.Context, change repository.VersionedFileChange, syncedHash string, repositoryResources resources.RepositoryResources) (*provisioning.ResourceConflict, error)
+	// Resolve applies the resolution of the conflict to the change
+	Resolve(ctx context.Context, conflict provisioning.ResourceConflict, change repository.VersionedFileChange, repositoryResources resources.RepositoryResources) (bool, error)
+}
+
+// conflictRecorder is the ConflictChecker of a repository with a conflict strategy. It wraps the
+// progress of the sync job to collect the conflicts so they can be saved in the repository status.
+//
+// Grafana changed a resource when its saved content no longer matches the file at the ref
+// of the last sync. When the repository changed the file as well, the conflict is resolved
+// with the strategy of the repository, or the resolution the job asked for the path.
+type conflictRecorder struct {
+	jobs.JobProgressRecorder
+	strategy provisioning.ConflictStrategy
+	// ref of the last sync, which full sync compares Grafana with
+	previousRef string
+	// path -> conflict of a previous sync that kept the Grafana version
+	pending map[string]provisioning.ResourceConflict
+	// path -> resolution requested by the job
+	resolutions map[string]provisioning.ConflictStrategy
+
+	mu        sync.Mutex
+	conflicts []provisioning.ResourceConflict
+}
+
+func newConflictRecorder(progress jobs.JobProgressRecorder, strategy provisioning.ConflictStrategy, previousRef string, pending []provisioning.ResourceConflict, resolutions []provisioning.ConflictResolution) *conflictRecorder {
+	r := &conflictRecorder{
+		JobProgressRecorder: progress,
+		strategy:            strategy,
+		previousRef:         previousRef,
+		pending:             make(map[string]provisioning.ResourceConflict, len(pending)),
+		resolutions:         make(map[string]provisioning.ConflictStrategy, len(resolutions)),
+	}
+	for _, conflict := range pending {
+		if conflict.Resolution != provisioning.ConflictStrategyRepository {
+			r.pending[conflict.Path] = conflict
+		}
+	}
+	for _, resolution := range resolutions {
+		r.resolutions[resolution.Path] = resolution.Resolution
+	}
+	return r
+}
+
+func (r *conflictRecorder) Record(ctx context.Context, result jobs.JobResourceResult) {
+	var conflictErr *resources.ResourceConflictError
+	if errors.As(result.Warning(), &conflictErr) {
+		conflict := conflictErr.Conflict
+		if conflict.Name == "" {
+			conflict.Name = result.Name()
+		}
+		if conflict.Group == "" {
+			conflict.Group = result.Group()
+		}
+		if conflict.Kind == "" {
+			conflict.Kind = result.Kind()
+		}
+		r.mu.Lock()
+		r.conflicts = append(r.conflicts, conflict)
+		r.mu.Unlock()
+	}
+	r.JobProgressRecorder.Record(ctx, result)
+}
+
+// PreviousRef returns the ref of the last sync
+func (r *conflictRecorder) PreviousRef() string {
+	return r.previousRef
+}
+
+// Conflicts returns the conflicts recorded during the sync
+func (r *conflictRecorder) Conflicts() []provisioning.ResourceConflict {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.conflicts)
+}
+
+// resolution returns how a conflict on the path is resolved
+func (r *conflictRecorder) resolution(path string) provisioning.ConflictStrategy {
+	if resolution, ok := r.resolutions[path]; ok {
+		return resolution
+	}
+	return r.strategy
+}
+
+// conflictBranch is the branch the Grafana versions are written to with the branch strategy
+func conflictBranch(currentRef string) string {
+	if len(currentRef) > 7 {
+		currentRef = currentRef[:7]
+	}
+	return "grafana/conflicts-" + currentRef
+}
+
+// isGone is true when the file is not in the ref, or the resource is not in Grafana
+func isGone(err error) bool {
+	return errors.Is(err, repository.ErrFileNotFound) || errors.Is(err, repository.ErrRefNotFound) || apierrors.IsNotFound(err)
+}
+
+// Check returns the conflict for the change, or nil when only the repository changed the resource.
+func (r *conflictRecorder) Check(ctx context.Context, change repository.VersionedFileChange, syncedHash string, repositoryResources resources.RepositoryResources) (*provisioning.ResourceConflict, error) {
+	// Template instances can not be changed in Grafana
+	if safepath.IsDir(change.Path) || change.PreviousRef == "" || resources.IsTemplateValuesFile(change.Path) {
+		return nil, nil
+	}
+
+	var action provisioning.ResourceAction
+	switch change.Action {
+	case repository.FileActionUpdated:
+		action = provisioning.ResourceActionUpdate
+	case repository.FileActionDeleted:
+		action = provisioning.ResourceActionDelete
+	default:
+		return nil, nil
+	}
+
+	var currentHash string
+	if action == provisioning.ResourceActionUpdate {
+		_, hash, matches, err := repositoryResources.GrafanaMatchesFile(ctx, change.Path, change.Ref)
+		if err != nil && !isGone(err) {
+			return nil, fmt.Errorf("compare with the file: %w", err)
+		}
+		// Grafana already has the new version
+		if matches {
+			return nil, nil
+		}
+		currentHash = hash
+	}
+
+	// The Grafana version kept by a previous sync stays until the conflict is resolved
+	if pending, ok := r.pending[change.Path]; ok {
+		pending.Action = action
+		if resolution := r.resolution(change.Path); resolution != pending.Resolution {
+			pending.Resolution = resolution
+			pending.Branch = ""
+		}
+		return &pending, nil
+	}
+
+	_, baseHash, matches, err := repositoryResources.GrafanaMatchesFile(ctx, change.Path, change.PreviousRef)
+	switch {
+	case isGone(err):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("compare with the previous file: %w", err)
+	case matches:
+		return nil, nil
+	}
+
+	// Only Grafana changed the resource
+	if baseHash == currentHash {
+		return nil, nil
+	}
+
+	return &provisioning.ResourceConflict{
+		Path:       change.Path,
+		Action:     action,
+		SyncedHash: syncedHash,
+		BaseHash:   baseHash,
+		Resolution: r.resolution(change.Path),
+	}, nil
+}
+
+// Resolve applies the resolution of the conflict to the change.
+// It returns true when the repository change must still be applied, along with the error to
+// record for the change: the conflict itself, or the failure to resolve it.
+func (r *conflictRecorder) Resolve(ctx context.Context, conflict provisioning.ResourceConflict, change repository.VersionedFileChange, repositoryResources resources.RepositoryResources) (bool, error) {
+	switch conflict.Resolution {
+	case provisioning.ConflictStrategyGrafana:
+		return false, resources.NewResourceConflictError(conflict)
+	case provisioning.ConflictStrategyBranch:
+		// The Grafana version is already on a branch
+		if conflict.Branch != "" {
+			return false, resources.NewResourceConflictError(conflict)
+		}
+		branch := conflictBranch(change.Ref)
+		message := fmt.Sprintf("Keep the Grafana version of %s", change.Path)
+		name, err := repositoryResources.WriteExistingResourceToRef(ctx, change.Path, change.PreviousRef, branch, message)
+		if err != nil {
+			return false, fmt.Errorf("writing grafana version of %s to branch %s: %w", change.Path, branch, err)
+		}
+		conflict.Name = name
+		conflict.Branch = branch
+		return false, resources.NewResourceConflictError(conflict)
+	default:
+		return true, resources.NewResourceConflictError(conflict)
+	}
+}
+
+// checkConflict looks for a conflict on the change and resolves it.
+// It returns false when the change must not be applied, along with the error or warning to
+// record for the change.
+func checkConflict(ctx context.Context, checker ConflictChecker, tracer tracing.Tracer, change repository.VersionedFileChange, syncedHash string, repositoryResources resources.RepositoryResources) (bool, error) {
+	ctx, span := tracer.Start(ctx, "provisioning.sync.check_conflict")
+	defer span.End()
+
+	conflict, err := checker.Check(ctx, change, syncedHash, repositoryResources)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("checking conflicts for %s: %w", change.Path, err)
+	}
+	if conflict == nil {
+		return true, nil
+	}
+	return checker.Resolve(ctx, *conflict, change, repositoryResources)
+}
+
+// Pending returns the conflicts of previous syncs that kept the Grafana version.
+// A conflict found by this sync replaces the previous one for the same path.
+func (r *conflictRecorder) Pending() []provisioning.ResourceConflict {
+	found := make(map[string]struct{})
+	for _, conflict := range r.Conflicts() {
+		found[conflict.Path] = struct{}{}
+	}
+
+	pending := make([]provisioning.ResourceConflict, 0, len(r.pending))
+	for path, conflict := range r.pending {
+		if _, ok := found[path]; !ok {
+			pending = append(pending, conflict)
+		}
+	}
+	slices.SortFunc(pending, func(a, b provisioning.ResourceConflict) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return pending
+}
+
+// ResolvePending returns the pending conflicts that are still not resolved.
+// A conflict that kept the Grafana version stays in the status until the job asks for a
+// resolution, or the repository and Grafana agree on the resource again.
+func (r *conflictRecorder) ResolvePending(ctx context.Context, currentRef string, repositoryResources resources.RepositoryResources, clients resources.ResourceClients) []provisioning.ResourceConflict {
+	pending := r.Pending()
+	remaining := make([]provisioning.ResourceConflict, 0, len(pending))
+	for _, conflict := range pending {
+		var resolved bool
+		if resolution, ok := r.resolutions[conflict.Path]; ok {
+			resolved = r.resolvePending(ctx, conflict, resolution, currentRef, repositoryResources, clients)
+		} else {
+			resolved = settled(ctx, conflict, currentRef, repositoryResources, clients)
+		}
+		if !resolved {
+			remaining = append(remaining, conflict)
+		}
+	}
+	return remaining
+}
+
+// resolvePending applies the resolution the job asked for to a conflict of a previous sync
+func (r *conflictRecorder) resolvePending(ctx context.Context, conflict provisioning.ResourceConflict, resolution provisioning.ConflictStrategy, currentRef string, repositoryResources resources.RepositoryResources, clients resources.ResourceClients) bool {
+	result := jobs.NewPathOnlyResult(conflict.Path).
+		WithName(conflict.Name).
+		WithGroup(conflict.Group).
+		WithKind(conflict.Kind).
+		WithAction(repository.FileActionIgnored)
+
+	var err error
+	switch resolution {
+	case provisioning.ConflictStrategyGrafana:
+		// The Grafana version is already there
+	case provisioning.ConflictStrategyBranch:
+		if conflict.Action != provisioning.ResourceActionUpdate {
+			err = fmt.Errorf("the file was deleted, so there is nothing to write the grafana version over")
+			break
+		}
+		message := fmt.Sprintf("Keep the Grafana version of %s", conflict.Path)
+		_, err = repositoryResources.WriteExistingResourceToRef(ctx, conflict.Path, currentRef, conflictBranch(currentRef), message)
+	default:
+		if conflict.Action == provisioning.ResourceActionDelete {
+			result.WithAction(repository.FileActionDeleted)
+			err = deleteResource(ctx, conflict, clients)
+		} else {
+			result.WithAction(repository.FileActionUpdated)
+			_, _, err = repositoryResources.WriteResourceFromFile(ctx, conflict.Path, currentRef)
+		}
+	}
+
+	if err != nil {
+		r.JobProgressRecorder.Record(ctx, result.WithError(fmt.Errorf("resolving the conflict on %s: %w", conflict.Path, err)).Build())
+		return false
+	}
+	r.JobProgressRecorder.Record(ctx, result.Build())
+	return true
+}
+
+// deleteResource applies a deletion from the repository that kept the Grafana version before
+func deleteResource(ctx context.Context, conflict provisioning.ResourceConflict, clients resources.ResourceClients) error {
+	if conflict.Name == "" || conflict.Kind == "" {
+		return fmt.Errorf("the conflict does not name the resource")
+	}
+
+	client, _, err := clients.ForKind(ctx, schema.GroupVersionKind{Group: conflict.Group, Kind: conflict.Kind})
+	if err != nil {
+		return fmt.Errorf("get client: %w", err)
+	}
+	if err := client.Delete(ctx, conflict.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// settled reports whether the repository and Grafana agree on the resource again,
+// e.g. once the merge branch with the Grafana version was merged
+func settled(ctx context.Context, conflict provisioning.ResourceConflict, currentRef string, repositoryResources resources.RepositoryResources, clients resources.ResourceClients) bool {
+	logger := logging.FromContext(ctx).With("path", conflict.Path)
+
+	if conflict.Action == provisioning.ResourceActionDelete {
+		if conflict.Name == "" || conflict.Kind == "" {
+			return false
+		}
+		client, _, err := clients.ForKind(ctx, schema.GroupVersionKind{Group: conflict.Group, Kind: conflict.Kind})
+		if err != nil {
+			logger.Warn("failed to check the conflict", "error", err)
+			return false
+		}
+		_, err = client.Get(ctx, conflict.Name, metav1.GetOptions{})
+		return apierrors.IsNotFound(err)
+	}
+
+	_, _, matches, err := repositoryResources.GrafanaMatchesFile(ctx, conflict.Path, currentRef)
+	if err != nil && !isGone(err) {
+		logger.Warn("failed to check the conflict", "error", err)
+	}
+	return err == nil && matches
+}
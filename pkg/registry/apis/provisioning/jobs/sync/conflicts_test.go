@@ -0,0 +1,378 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+)
+
+func TestConflictRecorder_Check(t *testing.T) {
+	update := repository.VersionedFileChange{
+		Action:      repository.FileActionUpdated,
+		Path:        "dashboards/home.json",
+		Ref:         "new-ref",
+		PreviousRef: "old-ref",
+	}
+	deletion := repository.VersionedFileChange{
+		Action:      repository.FileActionDeleted,
+		Path:        "dashboards/home.json",
+		PreviousRef: "old-ref",
+	}
+	notFound := apierrors.NewNotFound(schema.GroupResource{Group: "dashboard.grafana.app", Resource: "dashboards"}, "home")
+
+	tests := []struct {
+		name     string
+		change   repository.VersionedFileChange
+		pending  []provisioning.ResourceConflict
+		setup    func(*resources.MockRepositoryResources)
+		expected *provisioning.ResourceConflict
+	}{
+		{
+			name:   "created files never conflict",
+			change: repository.VersionedFileChange{Action: repository.FileActionCreated, Path: "dashboards/home.json", Ref: "new-ref", PreviousRef: "old-ref"},
+		},
+		{
+			name:   "first sync never conflicts",
+			change: repository.VersionedFileChange{Action: repository.FileActionUpdated, Path: "dashboards/home.json", Ref: "new-ref"},
+		},
+		{
+			name:   "grafana already has the new version",
+			change: update,
+			setup: func(repoResources *resources.MockRepositoryResources) {
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "new-ref").Return("home", "new", true, nil)
+			},
+		},
+		{
+			name:   "grafana has the previous version",
+			change: update,
+			setup: func(repoResources *resources.MockRepositoryResources) {
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "new-ref").Return("home", "new", false, nil)
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "old-ref").Return("home", "base", true, nil)
+			},
+		},
+		{
+			name:   "resource is not in grafana",
+			change: update,
+			setup: func(repoResources *resources.MockRepositoryResources) {
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "new-ref").Return("home", "new", false, notFound)
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "old-ref").Return("home", "base", false, notFound)
+			},
+		},
+		{
+			name:   "only grafana changed the resource",
+			change: update,
+			setup: func(repoResources *resources.MockRepositoryResources) {
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "new-ref").Return("home", "same", false, nil)
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "old-ref").Return("home", "same", false, nil)
+			},
+		},
+		{
+			name:   "updated in both",
+			change: update,
+			setup: func(repoResources *resources.MockRepositoryResources) {
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "new-ref").Return("home", "new", false, nil)
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "old-ref").Return("home", "base", false, nil)
+			},
+			expected: &provisioning.ResourceConflict{
+				Path:       "dashboards/home.json",
+				Action:     provisioning.ResourceActionUpdate,
+				SyncedHash: "synced",
+				BaseHash:   "base",
+				Resolution: provisioning.ConflictStrategyGrafana,
+			},
+		},
+		{
+			name:   "deleted in the repository and updated in grafana",
+			change: deletion,
+			setup: func(repoResources *resources.MockRepositoryResources) {
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "old-ref").Return("home", "base", false, nil)
+			},
+			expected: &provisioning.ResourceConflict{
+				Path:       "dashboards/home.json",
+				Action:     provisioning.ResourceActionDelete,
+				SyncedHash: "synced",
+				BaseHash:   "base",
+				Resolution: provisioning.ConflictStrategyGrafana,
+			},
+		},
+		{
+			name:   "deleted in the repository and unchanged in grafana",
+			change: deletion,
+			setup: func(repoResources *resources.MockRepositoryResources) {
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "old-ref").Return("home", "base", true, nil)
+			},
+		},
+		{
+			name:   "conflict of a previous sync is kept",
+			change: update,
+			pending: []provisioning.ResourceConflict{{
+				Path:       "dashboards/home.json",
+				Name:       "home",
+				Action:     provisioning.ResourceActionUpdate,
+				SyncedHash: "older",
+				BaseHash:   "older-base",
+				Resolution: provisioning.ConflictStrategyGrafana,
+			}},
+			setup: func(repoResources *resources.MockRepositoryResources) {
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "new-ref").Return("home", "new", false, nil)
+			},
+			expected: &provisioning.ResourceConflict{
+				Path:       "dashboards/home.json",
+				Name:       "home",
+				Action:     provisioning.ResourceActionUpdate,
+				SyncedHash: "older",
+				BaseHash:   "older-base",
+				Resolution: provisioning.ConflictStrategyGrafana,
+			},
+		},
+		{
+			name:   "conflict of a previous sync is settled once grafana has the new version",
+			change: update,
+			pending: []provisioning.ResourceConflict{{
+				Path:       "dashboards/home.json",
+				Action:     provisioning.ResourceActionUpdate,
+				Resolution: provisioning.ConflictStrategyGrafana,
+			}},
+			setup: func(repoResources *resources.MockRepositoryResources) {
+				repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "new-ref").Return("home", "new", true, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoResources := resources.NewMockRepositoryResources(t)
+			if tt.setup != nil {
+				tt.setup(repoResources)
+			}
+
+			recorder := newConflictRecorder(jobs.NewMockJobProgressRecorder(t), provisioning.ConflictStrategyGrafana, "old-ref", tt.pending, nil)
+			conflict, err := recorder.Check(context.Background(), tt.change, "synced", repoResources)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, conflict)
+		})
+	}
+
+	t.Run("resolution requested for the path", func(t *testing.T) {
+		repoResources := resources.NewMockRepositoryResources(t)
+		repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "new-ref").Return("home", "new", false, nil)
+		repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "old-ref").Return("home", "base", false, nil)
+
+		recorder := newConflictRecorder(jobs.NewMockJobProgressRecorder(t), provisioning.ConflictStrategyGrafana, "old-ref", nil, []provisioning.ConflictResolution{
+			{Path: "dashboards/home.json", Resolution: provisioning.ConflictStrategyRepository},
+		})
+		conflict, err := recorder.Check(context.Background(), update, "synced", repoResources)
+		require.NoError(t, err)
+		require.NotNil(t, conflict)
+		require.Equal(t, provisioning.ConflictStrategyRepository, conflict.Resolution)
+	})
+
+	t.Run("failure to compare the resource", func(t *testing.T) {
+		repoResources := resources.NewMockRepositoryResources(t)
+		repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "new-ref").Return("", "", false, errors.New("connection refused"))
+
+		recorder := newConflictRecorder(jobs.NewMockJobProgressRecorder(t), provisioning.ConflictStrategyGrafana, "old-ref", nil, nil)
+		_, err := recorder.Check(context.Background(), update, "synced", repoResources)
+		require.ErrorContains(t, err, "connection refused")
+	})
+}
+
+func TestConflictRecorder_Resolve(t *testing.T) {
+	change := repository.VersionedFileChange{
+		Action:      repository.FileActionUpdated,
+		Path:        "dashboards/home.json",
+		Ref:         "0123456789abcdef",
+		PreviousRef: "old-ref",
+	}
+	conflict := provisioning.ResourceConflict{Path: change.Path, Action: provisioning.ResourceActionUpdate, SyncedHash: "grafana", BaseHash: "base"}
+
+	t.Run("repository strategy applies the change", func(t *testing.T) {
+		recorder := newConflictRecorder(jobs.NewMockJobProgressRecorder(t), provisioning.ConflictStrategyRepository, change.PreviousRef, nil, nil)
+		conflict.Resolution = provisioning.ConflictStrategyRepository
+
+		apply, err := recorder.Resolve(context.Background(), conflict, change, resources.NewMockRepositoryResources(t))
+		require.True(t, apply)
+		require.True(t, resources.IsConflictWarning(err))
+	})
+
+	t.Run("grafana strategy skips the change", func(t *testing.T) {
+		recorder := newConflictRecorder(jobs.NewMockJobProgressRecorder(t), provisioning.ConflictStrategyGrafana, change.PreviousRef, nil, nil)
+		conflict.Resolution = provisioning.ConflictStrategyGrafana
+
+		apply, err := recorder.Resolve(context.Background(), conflict, change, resources.NewMockRepositoryResources(t))
+		require.False(t, apply)
+		var conflictErr *resources.ResourceConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.False(t, resources.IsConflictWarning(err))
+	})
+
+	t.Run("branch strategy writes the grafana version", func(t *testing.T) {
+		recorder := newConflictRecorder(jobs.NewMockJobProgressRecorder(t), provisioning.ConflictStrategyBranch, change.PreviousRef, nil, nil)
+		conflict.Resolution = provisioning.ConflictStrategyBranch
+		repoResources := resources.NewMockRepositoryResources(t)
+		repoResources.On("WriteExistingResourceToRef", mock.Anything, "dashboards/home.json", "old-ref", "grafana/conflicts-0123456", mock.Anything).
+			Return("home", nil)
+
+		apply, err := recorder.Resolve(context.Background(), conflict, change, repoResources)
+		require.False(t, apply)
+		var conflictErr *resources.ResourceConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.Equal(t, "home", conflictErr.Conflict.Name)
+		require.Equal(t, "grafana/conflicts-0123456", conflictErr.Conflict.Branch)
+	})
+
+	t.Run("branch strategy fails when the branch cannot be written", func(t *testing.T) {
+		recorder := newConflictRecorder(jobs.NewMockJobProgressRecorder(t), provisioning.ConflictStrategyBranch, change.PreviousRef, nil, nil)
+		conflict.Resolution = provisioning.ConflictStrategyBranch
+		repoResources := resources.NewMockRepositoryResources(t)
+		repoResources.On("WriteExistingResourceToRef", mock.Anything, "dashboards/home.json", "old-ref", "grafana/conflicts-0123456", mock.Anything).
+			Return("", errors.New("permission denied"))
+
+		apply, err := recorder.Resolve(context.Background(), conflict, change, repoResources)
+		require.False(t, apply)
+		require.ErrorContains(t, err, "permission denied")
+		var conflictErr *resources.ResourceConflictError
+		require.False(t, errors.As(err, &conflictErr))
+	})
+
+	t.Run("branch strategy does not write the grafana version twice", func(t *testing.T) {
+		recorder := newConflictRecorder(jobs.NewMockJobProgressRecorder(t), provisioning.ConflictStrategyBranch, change.PreviousRef, nil, nil)
+		pending := conflict
+		pending.Resolution = provisioning.ConflictStrategyBranch
+		pending.Branch = "grafana/conflicts-fedcba9"
+
+		apply, err := recorder.Resolve(context.Background(), pending, change, resources.NewMockRepositoryResources(t))
+		require.False(t, apply)
+		var conflictErr *resources.ResourceConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.Equal(t, "grafana/conflicts-fedcba9", conflictErr.Conflict.Branch)
+	})
+}
+
+func TestConflictRecorder(t *testing.T) {
+	progress := jobs.NewMockJobProgressRecorder(t)
+	progress.On("Record", mock.Anything, mock.Anything).Return().Twice()
+
+	recorder := newConflictRecorder(progress, provisioning.ConflictStrategyRepository, "old-ref", nil, nil)
+	conflict := provisioning.ResourceConflict{
+		Path:       "dashboards/home.json",
+		Action:     provisioning.ResourceActionUpdate,
+		Resolution: provisioning.ConflictStrategyRepository,
+	}
+	recorder.Record(context.Background(), jobs.NewPathOnlyResult("dashboards/home.json").
+		WithName("home").
+		WithGroup("dashboard.grafana.app").
+		WithKind("Dashboard").
+		WithWarning(resources.NewResourceConflictError(conflict)).
+		Build())
+	recorder.Record(context.Background(), jobs.NewPathOnlyResult("dashboards/other.json").Build())
+
+	conflict.Name = "home"
+	conflict.Group = "dashboard.grafana.app"
+	conflict.Kind = "Dashboard"
+	require.Equal(t, []provisioning.ResourceConflict{conflict}, recorder.Conflicts())
+}
+
+func TestConflictRecorder_ResolvePending(t *testing.T) {
+	kept := provisioning.ResourceConflict{
+		Path:       "dashboards/kept.json",
+		Name:       "kept",
+		Group:      "dashboard.grafana.app",
+		Kind:       "Dashboard",
+		Action:     provisioning.ResourceActionUpdate,
+		Resolution: provisioning.ConflictStrategyGrafana,
+	}
+	merged := provisioning.ResourceConflict{
+		Path:       "dashboards/merged.json",
+		Name:       "merged",
+		Action:     provisioning.ResourceActionUpdate,
+		Resolution: provisioning.ConflictStrategyBranch,
+		Branch:     "grafana/conflicts-0123456",
+	}
+	requested := provisioning.ResourceConflict{
+		Path:       "dashboards/requested.json",
+		Name:       "requested",
+		Action:     provisioning.ResourceActionUpdate,
+		Resolution: provisioning.ConflictStrategyGrafana,
+	}
+	found := provisioning.ResourceConflict{
+		Path:       "dashboards/found.json",
+		Name:       "found",
+		Action:     provisioning.ResourceActionUpdate,
+		Resolution: provisioning.ConflictStrategyGrafana,
+	}
+	dropped := provisioning.ResourceConflict{
+		Path:       "dashboards/dropped.json",
+		Action:     provisioning.ResourceActionUpdate,
+		Resolution: provisioning.ConflictStrategyRepository,
+	}
+
+	progress := jobs.NewMockJobProgressRecorder(t)
+	progress.On("Record", mock.Anything, mock.MatchedBy(func(result jobs.JobResourceResult) bool {
+		return result.Path() == "dashboards/requested.json" && result.Action() == repository.FileActionUpdated && result.Error() == nil
+	})).Return().Once()
+	progress.On("Record", mock.Anything, mock.MatchedBy(func(result jobs.JobResourceResult) bool {
+		return result.Path() == "dashboards/found.json"
+	})).Return().Once()
+
+	repoResources := resources.NewMockRepositoryResources(t)
+	repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/kept.json", "new-ref").Return("kept", "grafana", false, nil)
+	repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/merged.json", "new-ref").Return("merged", "grafana", true, nil)
+	repoResources.On("WriteResourceFromFile", mock.Anything, "dashboards/requested.json", "new-ref").Return("requested", schema.GroupVersionKind{}, nil)
+
+	recorder := newConflictRecorder(progress, provisioning.ConflictStrategyGrafana, "old-ref",
+		[]provisioning.ResourceConflict{kept, merged, requested, found, dropped},
+		[]provisioning.ConflictResolution{{Path: "dashboards/requested.json", Resolution: provisioning.ConflictStrategyRepository}},
+	)
+	// Found again by this sync
+	recorder.Record(context.Background(), jobs.NewPathOnlyResult("dashboards/found.json").
+		WithWarning(resources.NewResourceConflictError(found)).
+		Build())
+
+	remaining := recorder.ResolvePending(context.Background(), "new-ref", repoResources, resources.NewMockResourceClients(t))
+	require.Equal(t, []provisioning.ResourceConflict{kept}, remaining)
+	require.Equal(t, []provisioning.ResourceConflict{found}, recorder.Conflicts())
+}
+
+func TestIncrementalSync_ConflictChecker(t *testing.T) {
+	repo := repository.NewMockVersioned(t)
+	repo.On("CompareFiles", mock.Anything, "old-ref", "new-ref").Return([]repository.VersionedFileChange{{
+		Action:      repository.FileActionUpdated,
+		Path:        "dashboards/home.json",
+		Ref:         "new-ref",
+		PreviousRef: "old-ref",
+	}}, nil)
+
+	// The progress recorder knows nothing about conflicts
+	progress := jobs.NewMockJobProgressRecorder(t)
+	progress.On("SetTotal", mock.Anything, 1).Return()
+	progress.On("SetMessage", mock.Anything, "replicating versioned changes").Return()
+	progress.On("SetMessage", mock.Anything, "versioned changes replicated").Return()
+	progress.On("TooManyErrors").Return(nil)
+	progress.On("HasDirPathFailedCreation", "dashboards/home.json").Return(false)
+	progress.On("Record", mock.Anything, mock.MatchedBy(func(result jobs.JobResourceResult) bool {
+		var conflictErr *resources.ResourceConflictError
+		return result.Path() == "dashboards/home.json" && errors.As(result.Error(), &conflictErr)
+	})).Return().Once()
+
+	// Grafana and the repository both changed the dashboard, so it is not written
+	repoResources := resources.NewMockRepositoryResources(t)
+	repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "new-ref").Return("home", "grafana", false, nil)
+	repoResources.On("GrafanaMatchesFile", mock.Anything, "dashboards/home.json", "old-ref").Return("home", "base", false, nil)
+
+	checker := newConflictRecorder(jobs.NewMockJobProgressRecorder(t), provisioning.ConflictStrategyGrafana, "old-ref", nil, nil)
+	err := IncrementalSync(context.Background(), repo, "old-ref", "new-ref", repoResources, progress, checker, tracing.NewNoopTracerService(), jobs.RegisterJobMetrics(prometheus.NewPedanticRegistry()), newPermissiveMockQuotaTracker(t), false)
+	require.NoError(t, err)
+}
@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/grafana/grafana-app-sdk/logging"
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
 	"github.com/grafana/grafana/apps/provisioning/pkg/quotas"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
@@ -32,6 +33,7 @@ func FullSync(
 	currentRef string,
 	repositoryResources resources.RepositoryResources,
 	progress jobs.JobProgressRecorder,
+	conflicts ConflictChecker,
 	tracer tracing.Tracer,
 	maxSyncWorkers int,
 	metrics jobs.JobMetrics,
@@ -147,7 +149,7 @@ func FullSync(
 	}
 	span.SetAttributes(attribute.Bool("pre_check_quota", true))
 
-	return applyChanges(ctx, changes, clients, currentRef, repositoryResources, progress, tracer, maxSyncWorkers, metrics, quotaTracker, folderMetadataEnabled)
+	return applyChanges(ctx, changes, clients, currentRef, repositoryResources, progress, conflicts, tracer, maxSyncWorkers, metrics, quotaTracker, folderMetadataEnabled)
 }
 
 // shouldSkipChange checks if a change should be skipped based on previous failures on parent/child folders.
@@ -190,6 +192,7 @@ func applyChange(
 	currentRef string,
 	repositoryResources resources.RepositoryResources,
 	progress jobs.JobProgressRecorder,
+	conflicts ConflictChecker,
 	tracer tracing.Tracer,
 	quotaTracker quotas.QuotaTracker,
 	folderMetadataEnabled bool,
@@ -202,9 +205,31 @@ func applyChange(
 		return
 	}
 
+	// Resources that also changed in Grafana since the last sync are resolved with the conflict
+	// strategy. Renamed files are compared by incremental sync only.
+	var conflictWarning error
+	if conflicts != nil && change.Existing != nil && change.Existing.Path == change.Path {
+		apply, conflictErr := checkConflict(ctx, conflicts, tracer, repository.VersionedFileChange{
+			Action:      change.Action,
+			Path:        change.Path,
+			Ref:         currentRef,
+			PreviousRef: conflicts.PreviousRef(),
+		}, change.Existing.Hash, repositoryResources)
+		if !apply {
+			progress.Record(ctx, jobs.NewPathOnlyResult(change.Path).
+				WithAction(change.Action).
+				WithName(change.Existing.Name).
+				WithGroup(change.Existing.Group).
+				WithError(conflictErr).
+				Build())
+			return
+		}
+		conflictWarning = conflictErr
+	}
+
 	if change.Action == repository.FileActionDeleted {
 		deleteCtx, deleteSpan := tracer.Start(ctx, "provisioning.sync.full.apply_changes.delete")
-		resultBuilder := jobs.NewPathOnlyResult(change.Path).WithAction(change.Action)
+		resultBuilder := jobs.NewPathOnlyResult(change.Path).WithAction(change.Action).WithWarning(conflictWarning)
 
 		if change.Existing == nil || change.Existing.Name == "" {
 			result := resultBuilder.WithError(fmt.Errorf("processing deletion for file %s: missing existing reference", change.Path)).Build()
@@ -234,9 +259,27 @@ func applyChange(
 		resultBuilder.WithName(change.Existing.Name).
 			WithGVK(gvk)
 
+		// Keep the deleted version so the job can be rolled back
+		var previousVersion string
+		if resources.RecordsChanges(deleteCtx) {
+			if existing, err := client.Get(deleteCtx, change.Existing.Name, metav1.GetOptions{}); err == nil {
+				previousVersion = existing.GetResourceVersion()
+			}
+		}
+
 		if err := client.Delete(deleteCtx, change.Existing.Name, metav1.DeleteOptions{}); err != nil {
 			resultBuilder.WithError(fmt.Errorf("deleting resource %s/%s %s: %w", change.Existing.Group, gvk.Kind, change.Existing.Name, err))
 		} else {
+			if previousVersion != "" {
+				resources.RecordChange(deleteCtx, provisioning.JobResourceChange{
+					Group:           change.Existing.Group,
+					Resource:        change.Existing.Resource,
+					Name:            change.Existing.Name,
+					Path:            change.Path,
+					Action:          provisioning.ResourceActionDelete,
+					PreviousVersion: previousVersion,
+				})
+			}
 			quotaTracker.Release()
 			// Keep this tree mutation scoped to folder metadata for now.
 			// It clears the deleted folder's stale in-memory entry so the same
@@ -318,7 +361,7 @@ func applyChange(
 	} else {
 		name, gvk, err = repositoryResources.WriteResourceFromFile(writeCtx, change.Path, currentRef, writeOpts...)
 	}
-	resultBuilder := jobs.NewGVKResult(name, gvk).WithAction(change.Action).WithPath(change.Path)
+	resultBuilder := jobs.NewGVKResult(name, gvk).WithAction(change.Action).WithPath(change.Path).WithWarning(conflictWarning)
 	if err != nil {
 		writeSpan.RecordError(err)
 		resultBuilder.WithError(fmt.Errorf("writing resource from file %s: %w", change.Path, err))
@@ -345,6 +388,7 @@ func applyChanges(
 	currentRef string,
 	repositoryResources resources.RepositoryResources,
 	progress jobs.JobProgressRecorder,
+	conflicts ConflictChecker,
 	tracer tracing.Tracer,
 	maxSyncWorkers int,
 	metrics jobs.JobMetrics,
@@ -378,7 +422,7 @@ func applyChanges(
 
 	if len(buckets.fileDeletions) > 0 {
 		if err := instrumentedFullSyncPhase(jobs.FullSyncPhaseFileDeletions, func() error {
-			return applyResourcesInParallel(ctx, buckets.fileDeletions, clients, currentRef, repositoryResources, progress, tracer, maxSyncWorkers, quotaTracker, folderMetadataEnabled)
+			return applyResourcesInParallel(ctx, buckets.fileDeletions, clients, currentRef, repositoryResources, progress, conflicts, tracer, maxSyncWorkers, quotaTracker, folderMetadataEnabled)
 		}, metrics); err != nil {
 			return err
 		}
@@ -389,7 +433,7 @@ func applyChanges(
 		// before children are walked to ensure consistency in moves and renames.
 		safepath.SortByDepth(buckets.folderCreations, func(c ResourceFileChange) string { return c.Path }, true)
 		if err := instrumentedFullSyncPhase(jobs.FullSyncPhaseFolderCreations, func() error {
-			return applyFoldersSerially(ctx, buckets.folderCreations, clients, currentRef, repositoryResources, progress, tracer, quotaTracker, folderMetadataEnabled)
+			return applyFoldersSerially(ctx, buckets.folderCreations, clients, currentRef, repositoryResources, progress, conflicts, tracer, quotaTracker, folderMetadataEnabled)
 		}, metrics); err != nil {
 			return err
 		}
@@ -397,7 +441,7 @@ func applyChanges(
 
 	if len(buckets.fileRenames) > 0 {
 		if err := instrumentedFullSyncPhase(jobs.FullSyncPhaseFileRenames, func() error {
-			return applyResourcesInParallel(ctx, buckets.fileRenames, clients, currentRef, repositoryResources, progress, tracer, maxSyncWorkers, quotaTracker, folderMetadataEnabled)
+			return applyResourcesInParallel(ctx, buckets.fileRenames, clients, currentRef, repositoryResources, progress, conflicts, tracer, maxSyncWorkers, quotaTracker, folderMetadataEnabled)
 		}, metrics); err != nil {
 			return err
 		}
@@ -405,7 +449,7 @@ func applyChanges(
 
 	if len(buckets.folderDeletions) > 0 {
 		if err := instrumentedFullSyncPhase(jobs.FullSyncPhaseFolderDeletions, func() error {
-			return applyFoldersSerially(ctx, buckets.folderDeletions, clients, currentRef, repositoryResources, progress, tracer, quotaTracker, folderMetadataEnabled)
+			return applyFoldersSerially(ctx, buckets.folderDeletions, clients, currentRef, repositoryResources, progress, conflicts, tracer, quotaTracker, folderMetadataEnabled)
 		}, metrics); err != nil {
 			return err
 		}
@@ -413,7 +457,7 @@ func applyChanges(
 
 	if len(buckets.fileCreations) > 0 {
 		if err := instrumentedFullSyncPhase(jobs.FullSyncPhaseFileCreations, func() error {
-			return applyResourcesInParallel(ctx, buckets.fileCreations, clients, currentRef, repositoryResources, progress, tracer, maxSyncWorkers, quotaTracker, folderMetadataEnabled)
+			return applyResourcesInParallel(ctx, buckets.fileCreations, clients, currentRef, repositoryResources, progress, conflicts, tracer, maxSyncWorkers, quotaTracker, folderMetadataEnabled)
 		}, metrics); err != nil {
 			return err
 		}
@@ -564,6 +608,7 @@ func applyFoldersSerially(
 	currentRef string,
 	repositoryResources resources.RepositoryResources,
 	progress jobs.JobProgressRecorder,
+	conflicts ConflictChecker,
 	tracer tracing.Tracer,
 	quotaTracker quotas.QuotaTracker,
 	folderMetadataEnabled bool,
@@ -578,7 +623,7 @@ func applyFoldersSerially(
 		}
 
 		wrapWithTimeout(ctx, 15*time.Second, func(timeoutCtx context.Context) {
-			applyChange(timeoutCtx, folder, clients, currentRef, repositoryResources, progress, tracer, quotaTracker, folderMetadataEnabled)
+			applyChange(timeoutCtx, folder, clients, currentRef, repositoryResources, progress, conflicts, tracer, quotaTracker, folderMetadataEnabled)
 		})
 	}
 
@@ -594,6 +639,7 @@ func applyResourcesInParallel(
 	currentRef string,
 	repositoryResources resources.RepositoryResources,
 	progress jobs.JobProgressRecorder,
+	conflicts ConflictChecker,
 	tracer tracing.Tracer,
 	maxSyncWorkers int,
 	quotaTracker quotas.QuotaTracker,
@@ -628,7 +674,7 @@ loop:
 			defer func() { <-sem }()
 
 			wrapWithTimeout(ctx, 15*time.Second, func(timeoutCtx context.Context) {
-				applyChange(timeoutCtx, change, clients, currentRef, repositoryResources, progress, tracer, quotaTracker, folderMetadataEnabled)
+				applyChange(timeoutCtx, change, clients, currentRef, repositoryResources, progress, conflicts, tracer, quotaTracker, folderMetadataEnabled)
 			})
 		}(change)
 	}
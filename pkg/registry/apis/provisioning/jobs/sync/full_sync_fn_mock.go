@@ -30,9 +30,9 @@ func (_m *MockFullSyncFn) EXPECT() *MockFullSyncFn_Expecter {
 	return &MockFullSyncFn_Expecter{mock: &_m.Mock}
 }
 
-// Execute provides a mock function with given fields: ctx, repo, compare, clients, currentRef, repositoryResources, progress, tracer, maxSyncWorkers, metrics, quotaTracker, folderMetadataEnabled
-func (_m *MockFullSyncFn) Execute(ctx context.Context, repo repository.Reader, compare CompareFn, clients resources.ResourceClients, currentRef string, repositoryResources resources.RepositoryResources, progress jobs.JobProgressRecorder, tracer tracing.Tracer, maxSyncWorkers int, metrics jobs.JobMetrics, quotaTracker quotas.QuotaTracker, folderMetadataEnabled bool) error {
-	ret := _m.Called(ctx, repo, compare, clients, currentRef, repositoryResources, progress, tracer, maxSyncWorkers, metrics, quotaTracker, folderMetadataEnabled)
+// Execute provides a mock function with given fields: ctx, repo, compare, clients, currentRef, repositoryResources, progress, conflicts, tracer, maxSyncWorkers, metrics, quotaTracker, folderMetadataEnabled
+func (_m *MockFullSyncFn) Execute(ctx context.Context, repo repository.Reader, compare CompareFn, clients resources.ResourceClients, currentRef string, repositoryResources resources.RepositoryResources, progress jobs.JobProgressRecorder, conflicts ConflictChecker, tracer tracing.Tracer, maxSyncWorkers int, metrics jobs.JobMetrics, quotaTracker quotas.QuotaTracker, folderMetadataEnabled bool) error {
+	ret := _m.Called(ctx, repo, compare, clients, currentRef, repositoryResources, progress, conflicts, tracer, maxSyncWorkers, metrics, quotaTracker, folderMetadataEnabled)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Execute")
@@ -40,7 +40,7 @@ func (_m *MockFullSyncFn) Execute(ctx context.Context, repo repository.Reader, c
 
 	var r0 error
 	if rf, ok := ret.Get(0).(func(context.Context, repository.Reader, CompareFn, resources.ResourceClients, string, resources.RepositoryResources, jobs.JobProgressRecorder, tracing.Tracer, int, jobs.JobMetrics, quotas.QuotaTracker, bool) error); ok {
-		r0 = rf(ctx, repo, compare, clients, currentRef, repositoryResources, progress, tracer, maxSyncWorkers, metrics, quotaTracker, folderMetadataEnabled)
+		r0 = rf(ctx, repo, compare, clients, currentRef, repositoryResources, progress, conflicts, tracer, maxSyncWorkers, metrics, quotaTracker, folderMetadataEnabled)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -61,18 +61,19 @@ type MockFullSyncFn_Execute_Call struct {
 //   - currentRef string
 //   - repositoryResources resources.RepositoryResources
 //   - progress jobs.JobProgressRecorder
+//   - conflicts ConflictChecker
 //   - tracer tracing.Tracer
 //   - maxSyncWorkers int
 //   - metrics jobs.JobMetrics
 //   - quotaTracker quotas.QuotaTracker
 //   - folderMetadataEnabled bool
-func (_e *MockFullSyncFn_Expecter) Execute(ctx interface{}, repo interface{}, compare interface{}, clients interface{}, currentRef interface{}, repositoryResources interface{}, progress interface{}, tracer interface{}, maxSyncWorkers interface{}, metrics interface{}, quotaTracker interface{}, folderMetadataEnabled interface{}) *MockFullSyncFn_Execute_Call {
-	return &MockFullSyncFn_Execute_Call{Call: _e.mock.On("Execute", ctx, repo, compare, clients, currentRef, repositoryResources, progress, tracer, maxSyncWorkers, metrics, quotaTracker, folderMetadataEnabled)}
+func (_e *MockFullSyncFn_Expecter) Execute(ctx interface{}, repo interface{}, compare interface{}, clients interface{}, currentRef interface{}, repositoryResources interface{}, progress interface{}, conflicts interface{}, tracer interface{}, maxSyncWorkers interface{}, metrics interface{}, quotaTracker interface{}, folderMetadataEnabled interface{}) *MockFullSyncFn_Execute_Call {
+	return &MockFullSyncFn_Execute_Call{Call: _e.mock.On("Execute", ctx, repo, compare, clients, currentRef, repositoryResources, progress, conflicts, tracer, maxSyncWorkers, metrics, quotaTracker, folderMetadataEnabled)}
 }
 
-func (_c *MockFullSyncFn_Execute_Call) Run(run func(ctx context.Context, repo repository.Reader, compare CompareFn, clients resources.ResourceClients, currentRef string, repositoryResources resources.RepositoryResources, progress jobs.JobProgressRecorder, tracer tracing.Tracer, maxSyncWorkers int, metrics jobs.JobMetrics, quotaTracker quotas.QuotaTracker, folderMetadataEnabled bool)) *MockFullSyncFn_Execute_Call {
+func (_c *MockFullSyncFn_Execute_Call) Run(run func(ctx context.Context, repo repository.Reader, compare CompareFn, clients resources.ResourceClients, currentRef string, repositoryResources resources.RepositoryResources, progress jobs.JobProgressRecorder, conflicts ConflictChecker, tracer tracing.Tracer, maxSyncWorkers int, metrics jobs.JobMetrics, quotaTracker quotas.QuotaTracker, folderMetadataEnabled bool)) *MockFullSyncFn_Execute_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(repository.Reader), args[2].(CompareFn), args[3].(resources.ResourceClients), args[4].(string), args[5].(resources.RepositoryResources), args[6].(jobs.JobProgressRecorder), args[7].(tracing.Tracer), args[8].(int), args[9].(jobs.JobMetrics), args[10].(quotas.QuotaTracker), args[11].(bool))
+		run(args[0].(context.Context), args[1].(repository.Reader), args[2].(CompareFn), args[3].(resources.ResourceClients), args[4].(string), args[5].(resources.RepositoryResources), args[6].(jobs.JobProgressRecorder), args[7].(ConflictChecker), args[8].(tracing.Tracer), args[9].(int), args[10].(jobs.JobMetrics), args[11].(quotas.QuotaTracker), args[12].(bool))
 	})
 	return _c
 }
@@ -21,7 +21,7 @@ import (
 )
 
 // Convert git changes into resource file changes
-func IncrementalSync(ctx context.Context, repo repository.Versioned, previousRef, currentRef string, repositoryResources resources.RepositoryResources, progress jobs.JobProgressRecorder, tracer tracing.Tracer, metrics jobs.JobMetrics, quotaTracker quotas.QuotaTracker, folderMetadataEnabled bool) error {
+func IncrementalSync(ctx context.Context, repo repository.Versioned, previousRef, currentRef string, repositoryResources resources.RepositoryResources, progress jobs.JobProgressRecorder, conflicts ConflictChecker, tracer tracing.Tracer, metrics jobs.JobMetrics, quotaTracker quotas.QuotaTracker, folderMetadataEnabled bool) error {
 	syncStart := time.Now()
 	if previousRef == currentRef {
 		// We still need to detect missing folder metadata if the flag is enabled
@@ -55,6 +55,16 @@ func IncrementalSync(ctx context.Context, repo repository.Versioned, previousRef
 		return nil
 	}
 
+	diff, err = expandTemplateChanges(ctx, repo, currentRef, diff)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("expand template changes: %w", err))
+	}
+
+	diff, err = expandSourceChanges(ctx, repo, currentRef, diff)
+	if err != nil {
+		return tracing.Error(span, fmt.Errorf("expand source changes: %w", err))
+	}
+
 	var replaced []replacedFolder
 	var relocations map[string][]string
 	var invalidFolderMetadata []*resources.InvalidFolderMetadata
@@ -113,7 +123,7 @@ func IncrementalSync(ctx context.Context, repo repository.Versioned, previousRef
 	progress.SetTotal(ctx, len(diff))
 	progress.SetMessage(ctx, "replicating versioned changes")
 	applyStart := time.Now()
-	affectedFolders, err := applyIncrementalChanges(ctx, diff, repositoryResources, progress, tracer, span, quotaTracker, folderMetadataEnabled, relocations, existingHashes)
+	affectedFolders, err := applyIncrementalChanges(ctx, diff, repositoryResources, progress, tracer, span, quotaTracker, folderMetadataEnabled, relocations, existingHashes, conflicts)
 	metrics.RecordIncrementalSyncPhase(jobs.IncrementalSyncPhaseApply, time.Since(applyStart))
 	if err != nil {
 		return err
@@ -164,6 +174,7 @@ func applyIncrementalChanges(
 	folderMetadataEnabled bool,
 	relocations map[string][]string,
 	existingHashes map[string]string,
+	conflicts ConflictChecker,
 ) (affectedFolders map[string]string, err error) {
 	// this will keep track of any folders that had resources deleted from it
 	// with key-value as path:grafana uid.
@@ -266,6 +277,15 @@ func applyIncrementalChanges(
 			continue
 		}
 
+		if conflicts != nil {
+			apply, conflictErr := checkConflict(ctx, conflicts, tracer, change, existingHashes[change.Path], repositoryResources)
+			if !apply {
+				progress.Record(ctx, resultBuilder.WithError(conflictErr).Build())
+				continue
+			}
+			resultBuilder.WithWarning(conflictErr)
+		}
+
 		switch change.Action {
 		case repository.FileActionCreated:
 			writeCtx, writeSpan := tracer.Start(ctx, "provisioning.sync.incremental.write_resource_from_file")
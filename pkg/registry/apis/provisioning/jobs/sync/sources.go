@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+)
+
+// expandSourceChanges adds an update for the source files of every source directory with a
+// library changed in the diff, so the resources generated from the unchanged source files
+// importing a library are updated with it.
+func expandSourceChanges(ctx context.Context, repo repository.Versioned, currentRef string, diff []repository.VersionedFileChange) ([]repository.VersionedFileChange, error) {
+	reader, ok := repo.(repository.Reader)
+	if !ok {
+		// Source files can only be evaluated from a repository.Reader
+		return diff, nil
+	}
+	sources := resources.NewSourceDirectories(reader.Config().Spec.Sources)
+	if len(sources) == 0 {
+		return diff, nil
+	}
+
+	changedDirs := map[string]struct{}{}
+	for _, change := range diff {
+		for _, dir := range sources.Dependents(change.Path) {
+			changedDirs[dir] = struct{}{}
+		}
+		if change.PreviousPath != "" {
+			for _, dir := range sources.Dependents(change.PreviousPath) {
+				changedDirs[dir] = struct{}{}
+			}
+		}
+	}
+	if len(changedDirs) == 0 {
+		return diff, nil
+	}
+
+	tree, err := reader.ReadTree(ctx, currentRef)
+	if err != nil {
+		return nil, fmt.Errorf("read tree: %w", err)
+	}
+
+	inDiff := make(map[string]struct{}, len(diff))
+	for _, change := range diff {
+		inDiff[change.Path] = struct{}{}
+	}
+
+	for _, entry := range tree {
+		if !entry.Blob || !resources.IsSourceFile(entry.Path) {
+			continue
+		}
+		if _, ok := inDiff[entry.Path]; ok {
+			continue
+		}
+		// Source files below a nested source directory belong to that directory
+		src, ok := sources.Find(entry.Path)
+		if !ok {
+			continue
+		}
+		if _, ok := changedDirs[src.Path]; ok {
+			diff = append(diff, repository.VersionedFileChange{
+				Action: repository.FileActionUpdated,
+				Path:   entry.Path,
+				Ref:    currentRef,
+			})
+		}
+	}
+	return diff, nil
+}
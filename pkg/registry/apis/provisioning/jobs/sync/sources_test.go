@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+)
+
+func TestExpandSourceChanges(t *testing.T) {
+	config := &provisioning.Repository{
+		Spec: provisioning.RepositorySpec{
+			Sources: []provisioning.SourceDirectory{
+				{Path: "dashboards", Format: provisioning.SourceFormatJsonnet, Libraries: []string{"vendor"}},
+				{Path: "dashboards/cue", Format: provisioning.SourceFormatCUE},
+			},
+		},
+	}
+
+	t.Run("diff without library changes is returned as is", func(t *testing.T) {
+		reader := repository.NewMockReader(t)
+		reader.On("Config").Return(config)
+		repo := &compositeRepo{MockVersioned: repository.NewMockVersioned(t), MockReader: reader}
+		diff := []repository.VersionedFileChange{{Action: repository.FileActionUpdated, Path: "dashboards/a.jsonnet", Ref: "new"}}
+
+		expanded, err := expandSourceChanges(context.Background(), repo, "new", diff)
+		require.NoError(t, err)
+		require.Equal(t, diff, expanded)
+	})
+
+	t.Run("library changes update the source files importing them", func(t *testing.T) {
+		reader := repository.NewMockReader(t)
+		reader.On("Config").Return(config)
+		reader.On("ReadTree", mock.Anything, "new").Return([]repository.FileTreeEntry{
+			{Path: "dashboards/", Blob: false},
+			{Path: "dashboards/a.jsonnet", Blob: true},
+			{Path: "dashboards/b.jsonnet", Blob: true},
+			{Path: "dashboards/cue/c.cue", Blob: true},
+			{Path: "dashboards/home.json", Blob: true},
+			{Path: "other/d.jsonnet", Blob: true},
+			{Path: "vendor/grafonnet/main.libsonnet", Blob: true},
+		}, nil)
+		repo := &compositeRepo{MockVersioned: repository.NewMockVersioned(t), MockReader: reader}
+
+		diff := []repository.VersionedFileChange{
+			{Action: repository.FileActionUpdated, Path: "vendor/grafonnet/main.libsonnet", Ref: "new", PreviousRef: "old"},
+			{Action: repository.FileActionUpdated, Path: "dashboards/b.jsonnet", Ref: "new", PreviousRef: "old"},
+		}
+
+		expanded, err := expandSourceChanges(context.Background(), repo, "new", diff)
+		require.NoError(t, err)
+		require.Equal(t, append(diff, repository.VersionedFileChange{
+			Action: repository.FileActionUpdated,
+			Path:   "dashboards/a.jsonnet",
+			Ref:    "new",
+		}), expanded)
+	})
+}
@@ -2,6 +2,7 @@ package sync
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/grafana/grafana-app-sdk/logging"
@@ -14,17 +15,19 @@ import (
 )
 
 //go:generate mockery --name FullSyncFn --structname MockFullSyncFn --inpackage --filename full_sync_fn_mock.go --with-expecter
-type FullSyncFn func(ctx context.Context, repo repository.Reader, compare CompareFn, clients resources.ResourceClients, currentRef string, repositoryResources resources.RepositoryResources, progress jobs.JobProgressRecorder, tracer tracing.Tracer, maxSyncWorkers int, metrics jobs.JobMetrics, quotaTracker quotas.QuotaTracker, folderMetadataEnabled bool) error
+type FullSyncFn func(ctx context.Context, repo repository.Reader, compare CompareFn, clients resources.ResourceClients, currentRef string, repositoryResources resources.RepositoryResources, progress jobs.JobProgressRecorder, conflicts ConflictChecker, tracer tracing.Tracer, maxSyncWorkers int, metrics jobs.JobMetrics, conflicts ConflictChecker, quotaTracker quotas.QuotaTracker, folderMetadataEnabled bool) error
 
 //go:generate mockery --name CompareFn --structname MockCompareFn --inpackage --filename compare_fn_mock.go --with-expecter
 type CompareFn func(ctx context.Context, repo repository.Reader, repositoryResources resources.RepositoryResources, ref string, folderMetadataEnabled bool) ([]ResourceFileChange, []string, []*resources.InvalidFolderMetadata, error)
 
 //go:generate mockery --name IncrementalSyncFn --structname MockIncrementalSyncFn --inpackage --filename incremental_sync_fn_mock.go --with-expecter
-type IncrementalSyncFn func(ctx context.Context, repo repository.Versioned, previousRef, currentRef string, repositoryResources resources.RepositoryResources, progress jobs.JobProgressRecorder, tracer tracing.Tracer, metrics jobs.JobMetrics, quotaTracker quotas.QuotaTracker, folderMetadataEnabled bool) error
+type IncrementalSyncFn func(ctx context.Context, repo repository.Versioned, previousRef, currentRef string, repositoryResources resources.RepositoryResources, progress jobs.JobProgressRecorder, conflicts ConflictChecker, tracer tracing.Tracer, metrics jobs.JobMetrics, quotaTracker quotas.QuotaTracker, folderMetadataEnabled bool) error
 
 //go:generate mockery --name Syncer --structname MockSyncer --inpackage --filename syncer_mock.go --with-expecter
 type Syncer interface {
-	Sync(ctx context.Context, repo repository.ReaderWriter, options provisioning.SyncJobOptions, repositoryResources resources.RepositoryResources, clients resources.ResourceClients, progress jobs.JobProgressRecorder, quotaTracker quotas.QuotaTracker) (string, error)
+	// Sync applies the repository to Grafana. Resources that also changed in Grafana are resolved by
+	// the conflict checker, when there is one.
+	Sync(ctx context.Context, repo repository.ReaderWriter, options provisioning.SyncJobOptions, repositoryResources resources.RepositoryResources, clients resources.ResourceClients, progress jobs.JobProgressRecorder, conflicts ConflictChecker, quotaTracker quotas.QuotaTracker) (string, error)
 }
 
 type syncer struct {
@@ -49,22 +52,38 @@ func NewSyncer(compare CompareFn, fullSync FullSyncFn, incrementalSync Increment
 	}
 }
 
-func (r *syncer) Sync(ctx context.Context, repo repository.ReaderWriter, options provisioning.SyncJobOptions, repositoryResources resources.RepositoryResources, clients resources.ResourceClients, progress jobs.JobProgressRecorder, quotaTracker quotas.QuotaTracker) (string, error) {
+func (r *syncer) Sync(ctx context.Context, repo repository.ReaderWriter, options provisioning.SyncJobOptions, repositoryResources resources.RepositoryResources, clients resources.ResourceClients, progress jobs.JobProgressRecorder, conflicts ConflictChecker, quotaTracker quotas.QuotaTracker) (string, error) {
 	cfg := repo.Config()
 	logger := logging.FromContext(ctx)
 
 	var currentRef string
+	var err error
 	versionedRepo, ok := repo.(repository.Versioned)
 	if ok && versionedRepo != nil {
-		var err error
 		currentRef, err = versionedRepo.LatestRef(ctx)
 		if err != nil {
 			return "", fmt.Errorf("get latest ref: %w", err)
 		}
+	}
+
+	// Nothing is applied when a commit is neither signed by a trusted key nor acknowledged.
+	// The first sync of a repository only verifies the latest commit, which must be signed: the history
+	// before it is not checked, so the tree is trusted on the strength of that one signature.
+	signers, err := repository.VerifyCommits(ctx, repo, cfg.Status.Sync.LastRef, currentRef)
+	if err != nil {
+		return "", fmt.Errorf("verify commits: %w", err)
+	}
+	ctx = resources.WithSourceSigners(ctx, signers)
 
+	if ok && versionedRepo != nil {
 		if cfg.Status.Sync.LastRef != "" && options.Incremental && !quotas.IsQuotaExceeded(cfg.Status.Conditions) {
 			progress.SetMessage(ctx, "incremental sync")
-			return currentRef, r.incrementalSync(ctx, versionedRepo, cfg.Status.Sync.LastRef, currentRef, repositoryResources, progress, r.tracer, r.metrics, quotaTracker, r.folderMetadataEnabled)
+			err = r.incrementalSync(ctx, versionedRepo, cfg.Status.Sync.LastRef, currentRef, repositoryResources, progress, conflicts, r.tracer, r.metrics, quotaTracker, r.folderMetadataEnabled)
+			// Nothing is applied before the files are compared, so a full sync can take over
+			if !errors.Is(err, repository.ErrIncrementalSyncNotSupported) {
+				return currentRef, err
+			}
+			logger.Info("repository does not support incremental sync, running full sync")
 		}
 
 		if quotas.IsQuotaExceeded(cfg.Status.Conditions) {
@@ -72,5 +91,5 @@ func (r *syncer) Sync(ctx context.Context, repo repository.ReaderWriter, options
 		}
 	}
 	progress.SetMessage(ctx, "full sync")
-	return currentRef, r.fullSync(ctx, repo, r.compare, clients, currentRef, repositoryResources, progress, r.tracer, r.maxSyncWorkers, r.metrics, quotaTracker, r.folderMetadataEnabled)
+	return currentRef, r.fullSync(ctx, repo, r.compare, clients, currentRef, repositoryResources, progress, conflicts, r.tracer, r.maxSyncWorkers, r.metrics, quotaTracker, r.folderMetadataEnabled)
 }
@@ -68,7 +68,7 @@ func TestSyncer_Sync(t *testing.T) {
 				repo.MockVersioned.On("LatestRef", mock.Anything).Return("new-ref", nil)
 
 				progress.On("SetMessage", mock.Anything, "full sync").Return()
-				fullSyncFn.EXPECT().Execute(mock.Anything, mock.Anything, mock.Anything, mock.Anything, "new-ref", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				fullSyncFn.EXPECT().Execute(mock.Anything, mock.Anything, mock.Anything, mock.Anything, "new-ref", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			},
 			expectedMessages: []string{"full sync"},
 		},
@@ -90,7 +90,7 @@ func TestSyncer_Sync(t *testing.T) {
 				})
 				repo.MockVersioned.On("LatestRef", mock.Anything).Return("new-ref", nil)
 				progress.On("SetMessage", mock.Anything, "incremental sync").Return()
-				incrementalSyncFn.EXPECT().Execute(mock.Anything, mock.Anything, "old-ref", "new-ref", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				incrementalSyncFn.EXPECT().Execute(mock.Anything, mock.Anything, "old-ref", "new-ref", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			},
 			expectedRef:      "new-ref",
 			expectedMessages: []string{"incremental sync"},
@@ -123,7 +123,7 @@ func TestSyncer_Sync(t *testing.T) {
 				})
 				repo.MockVersioned.On("LatestRef", mock.Anything).Return("new-ref", nil)
 				progress.On("SetMessage", mock.Anything, "full sync").Return()
-				fullSyncFn.EXPECT().Execute(mock.Anything, mock.Anything, mock.Anything, mock.Anything, "new-ref", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				fullSyncFn.EXPECT().Execute(mock.Anything, mock.Anything, mock.Anything, mock.Anything, "new-ref", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			},
 			expectedMessages: []string{"full sync"},
 		},
@@ -165,12 +165,37 @@ func TestSyncer_Sync(t *testing.T) {
 				})
 				repo.MockVersioned.On("LatestRef", mock.Anything).Return("new-ref", nil)
 				progress.On("SetMessage", mock.Anything, "incremental sync").Return()
-				incrementalSyncFn.On("Execute", mock.Anything, mock.Anything, "old-ref", "new-ref", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("incremental sync failed"))
+				incrementalSyncFn.On("Execute", mock.Anything, mock.Anything, "old-ref", "new-ref", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("incremental sync failed"))
 			},
 			expectedRef:      "new-ref",
 			expectedMessages: []string{"incremental sync"},
 			expectedError:    "incremental sync failed",
 		},
+		{
+			name: "incremental sync not supported falls back to full sync",
+			options: provisioning.SyncJobOptions{
+				Incremental: true,
+			},
+			setupMocks: func(repo *mockReaderWriter, repoResources *resources.MockRepositoryResources, clients *resources.MockResourceClients, progress *jobs.MockJobProgressRecorder, compareFn *MockCompareFn, fullSyncFn *MockFullSyncFn, incrementalSyncFn *MockIncrementalSyncFn) {
+				repo.MockRepository.On("Config").Return(&provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-repo",
+					},
+					Status: provisioning.RepositoryStatus{
+						Sync: provisioning.SyncStatus{
+							LastRef: "old-ref",
+						},
+					},
+				})
+				repo.MockVersioned.On("LatestRef", mock.Anything).Return("new-ref", nil)
+				progress.On("SetMessage", mock.Anything, "incremental sync").Return()
+				incrementalSyncFn.EXPECT().Execute(mock.Anything, mock.Anything, "old-ref", "new-ref", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("compare files error: %w", repository.ErrIncrementalSyncNotSupported))
+				progress.On("SetMessage", mock.Anything, "full sync").Return()
+				fullSyncFn.EXPECT().Execute(mock.Anything, mock.Anything, mock.Anything, mock.Anything, "new-ref", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedRef:      "new-ref",
+			expectedMessages: []string{"incremental sync", "full sync"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -200,7 +225,7 @@ func TestSyncer_Sync(t *testing.T) {
 			)
 
 			quotaTracker := quotas.NewMockQuotaTracker(t)
-			ref, err := syncer.Sync(context.Background(), repo, tt.options, repoResources, clients, progress, quotaTracker)
+			ref, err := syncer.Sync(context.Background(), repo, tt.options, repoResources, clients, progress, nil, quotaTracker)
 			if tt.expectedError != "" {
 				require.EqualError(t, err, tt.expectedError)
 			} else {
@@ -223,3 +248,85 @@ func TestSyncer_Sync(t *testing.T) {
 		})
 	}
 }
+
+type mockVerifiedReaderWriter struct {
+	*mockReaderWriter
+	*repository.MockCommitVerifier
+}
+
+func TestSyncer_Sync_SignedCommits(t *testing.T) {
+	config := &provisioning.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-repo"},
+		Spec: provisioning.RepositorySpec{
+			Type: provisioning.GitRepositoryType,
+			Commit: &provisioning.CommitOptions{
+				Verification: &provisioning.CommitVerification{RequireSignedCommits: true},
+			},
+		},
+		Status: provisioning.RepositoryStatus{
+			Sync: provisioning.SyncStatus{LastRef: "old-ref"},
+		},
+	}
+
+	newSyncer := func(t *testing.T) (Syncer, *MockIncrementalSyncFn) {
+		incrementalSyncFn := NewMockIncrementalSyncFn(t)
+		return NewSyncer(
+			NewMockCompareFn(t).Execute,
+			NewMockFullSyncFn(t).Execute,
+			incrementalSyncFn.Execute,
+			tracing.NewNoopTracerService(),
+			10,
+			jobs.RegisterJobMetrics(prometheus.NewPedanticRegistry()),
+			false,
+		), incrementalSyncFn
+	}
+
+	newRepo := func(t *testing.T) *mockVerifiedReaderWriter {
+		repo := &mockVerifiedReaderWriter{
+			mockReaderWriter: &mockReaderWriter{
+				MockRepository: repository.NewMockRepository(t),
+				MockVersioned:  repository.NewMockVersioned(t),
+			},
+			MockCommitVerifier: repository.NewMockCommitVerifier(t),
+		}
+		repo.MockRepository.On("Config").Return(config)
+		repo.MockVersioned.On("LatestRef", mock.Anything).Return("new-ref", nil)
+		return repo
+	}
+
+	t.Run("verified signers are passed to the sync", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.MockCommitVerifier.EXPECT().VerifyCommits(mock.Anything, "old-ref", "new-ref").Return(map[string]string{"dashboard.json": "alice"}, nil)
+
+		progress := jobs.NewMockJobProgressRecorder(t)
+		progress.On("SetMessage", mock.Anything, "incremental sync").Return()
+
+		syncer, incrementalSyncFn := newSyncer(t)
+		incrementalSyncFn.EXPECT().Execute(mock.Anything, mock.Anything, "old-ref", "new-ref", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			RunAndReturn(func(ctx context.Context, _ repository.Versioned, _, _ string, _ resources.RepositoryResources, _ jobs.JobProgressRecorder, _ tracing.Tracer, _ jobs.JobMetrics, _ quotas.QuotaTracker, _ bool) error {
+				require.Equal(t, "alice", resources.SourceSignerFromContext(ctx, "dashboard.json"))
+				return nil
+			})
+
+		ref, err := syncer.Sync(context.Background(), repo, provisioning.SyncJobOptions{Incremental: true}, resources.NewMockRepositoryResources(t), resources.NewMockResourceClients(t), progress, nil, quotas.NewMockQuotaTracker(t))
+		require.NoError(t, err)
+		require.Equal(t, "new-ref", ref)
+	})
+
+	t.Run("unverified commits fail the sync before anything is applied", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.MockCommitVerifier.EXPECT().VerifyCommits(mock.Anything, "old-ref", "new-ref").Return(nil, fmt.Errorf("commit abc: commit is not signed: %w", repository.ErrUnverifiedCommit))
+
+		syncer, _ := newSyncer(t)
+		_, err := syncer.Sync(context.Background(), repo, provisioning.SyncJobOptions{Incremental: true}, resources.NewMockRepositoryResources(t), resources.NewMockResourceClients(t), jobs.NewMockJobProgressRecorder(t), nil, quotas.NewMockQuotaTracker(t))
+		require.ErrorIs(t, err, repository.ErrUnverifiedCommit)
+	})
+
+	t.Run("repositories that can not verify commits fail the sync", func(t *testing.T) {
+		repo := newRepo(t).mockReaderWriter
+
+		syncer, _ := newSyncer(t)
+		_, err := syncer.Sync(context.Background(), repo, provisioning.SyncJobOptions{Incremental: true}, resources.NewMockRepositoryResources(t), resources.NewMockResourceClients(t), jobs.NewMockJobProgressRecorder(t), nil, quotas.NewMockQuotaTracker(t))
+		require.EqualError(t, err, "verify commits: signed commits are not supported on git repositories")
+	})
+}
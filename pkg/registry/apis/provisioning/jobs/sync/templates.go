@@ -0,0 +1,119 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+)
+
+// expandTemplateChanges adds an update for the values files of every template changed in the diff,
+// so the instances of a template are updated even when their own values file did not change.
+// When the template of a directory is deleted or moved away, its instances are deleted instead:
+// the values files are read at the previous ref, where they still render from the template.
+func expandTemplateChanges(ctx context.Context, repo repository.Versioned, currentRef string, diff []repository.VersionedFileChange) ([]repository.VersionedFileChange, error) {
+	changedDirs := map[string]struct{}{}
+	// removedDirs maps the directories that lost their template to the ref before the change
+	removedDirs := map[string]string{}
+	for _, change := range diff {
+		if resources.IsTemplateFile(change.Path) {
+			changedDirs[safepath.Dir(change.Path)] = struct{}{}
+			if change.Action == repository.FileActionDeleted {
+				removedDirs[safepath.Dir(change.Path)] = change.PreviousRef
+			}
+		}
+		if change.PreviousPath != "" && resources.IsTemplateFile(change.PreviousPath) {
+			changedDirs[safepath.Dir(change.PreviousPath)] = struct{}{}
+			removedDirs[safepath.Dir(change.PreviousPath)] = change.PreviousRef
+		}
+	}
+	if len(changedDirs) == 0 {
+		return diff, nil
+	}
+
+	reader, ok := repo.(repository.Reader)
+	if !ok {
+		return nil, fmt.Errorf("template changes require repository.Reader")
+	}
+	tree, err := reader.ReadTree(ctx, currentRef)
+	if err != nil {
+		return nil, fmt.Errorf("read tree: %w", err)
+	}
+
+	templates := resources.NewTemplateDirectories(tree)
+	// A directory whose template was replaced, e.g. by one in another format, still has a template
+	for dir := range removedDirs {
+		if _, ok := templates[dir]; ok {
+			delete(removedDirs, dir)
+		}
+	}
+
+	inDiff := make(map[string]int, len(diff))
+	for i, change := range diff {
+		inDiff[change.Path] = i
+	}
+
+	for _, entry := range tree {
+		if !entry.Blob || !resources.IsTemplateValuesFile(entry.Path) {
+			continue
+		}
+
+		// Values files below a nested template directory belong to that template
+		tmpl, hasTemplate := templates.Template(entry.Path)
+		if previousRef, removed := removedTemplateRef(removedDirs, entry.Path, tmpl, hasTemplate); removed {
+			if i, ok := inDiff[entry.Path]; !ok {
+				diff = append(diff, repository.VersionedFileChange{
+					Action:      repository.FileActionDeleted,
+					Path:        entry.Path,
+					Ref:         currentRef,
+					PreviousRef: previousRef,
+				})
+			} else if diff[i].Action == repository.FileActionUpdated {
+				// The updated values file no longer renders from its template
+				diff[i].Action = repository.FileActionDeleted
+			} else {
+				// A values file created in the same change never had an instance
+				continue
+			}
+			// A template of a parent directory now renders the values file
+			if hasTemplate {
+				diff = append(diff, repository.VersionedFileChange{
+					Action: repository.FileActionCreated,
+					Path:   entry.Path,
+					Ref:    currentRef,
+				})
+			}
+			continue
+		}
+
+		if _, ok := inDiff[entry.Path]; ok || !hasTemplate {
+			continue
+		}
+		if _, ok := changedDirs[safepath.Dir(tmpl.Path)]; ok {
+			diff = append(diff, repository.VersionedFileChange{
+				Action: repository.FileActionUpdated,
+				Path:   entry.Path,
+				Ref:    currentRef,
+			})
+		}
+	}
+	return diff, nil
+}
+
+// removedTemplateRef returns the ref before the change when the template that rendered the values file was removed.
+// The template of the deepest directory wins, so a template left below a removed one still owns its values files.
+func removedTemplateRef(removedDirs map[string]string, path string, tmpl repository.FileTreeEntry, hasTemplate bool) (string, bool) {
+	ref, found, depth := "", false, -1
+	for dir, previousRef := range removedDirs {
+		if !safepath.InDir(path, dir) || len(dir) <= depth {
+			continue
+		}
+		ref, found, depth = previousRef, true, len(dir)
+	}
+	if !found || (hasTemplate && len(safepath.Dir(tmpl.Path)) > depth) {
+		return "", false
+	}
+	return ref, true
+}
@@ -0,0 +1,100 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+)
+
+func TestExpandTemplateChanges(t *testing.T) {
+	t.Run("diff without template changes is returned as is", func(t *testing.T) {
+		repo := &compositeRepo{MockVersioned: repository.NewMockVersioned(t), MockReader: repository.NewMockReader(t)}
+		diff := []repository.VersionedFileChange{{Action: repository.FileActionUpdated, Path: "dashboards/home.json", Ref: "new"}}
+
+		expanded, err := expandTemplateChanges(context.Background(), repo, "new", diff)
+		require.NoError(t, err)
+		require.Equal(t, diff, expanded)
+	})
+
+	t.Run("template changes update the values files of the template", func(t *testing.T) {
+		reader := repository.NewMockReader(t)
+		reader.On("ReadTree", mock.Anything, "new").Return([]repository.FileTreeEntry{
+			{Path: "dashboards/", Blob: false},
+			{Path: "dashboards/_template.json", Blob: true},
+			{Path: "dashboards/dev.values.yaml", Blob: true},
+			{Path: "dashboards/eu/prod.values.yaml", Blob: true},
+			{Path: "dashboards/home.json", Blob: true},
+			{Path: "dashboards/nested/_template.json", Blob: true},
+			{Path: "dashboards/nested/qa.values.yaml", Blob: true},
+			{Path: "other/stage.values.yaml", Blob: true},
+		}, nil)
+		repo := &compositeRepo{MockVersioned: repository.NewMockVersioned(t), MockReader: reader}
+
+		diff := []repository.VersionedFileChange{
+			{Action: repository.FileActionUpdated, Path: "dashboards/_template.json", Ref: "new", PreviousRef: "old"},
+			{Action: repository.FileActionUpdated, Path: "dashboards/dev.values.yaml", Ref: "new", PreviousRef: "old"},
+		}
+
+		expanded, err := expandTemplateChanges(context.Background(), repo, "new", diff)
+		require.NoError(t, err)
+		require.Equal(t, append(diff, repository.VersionedFileChange{
+			Action: repository.FileActionUpdated,
+			Path:   "dashboards/eu/prod.values.yaml",
+			Ref:    "new",
+		}), expanded)
+	})
+
+	t.Run("deleting a template deletes the instances of its values files", func(t *testing.T) {
+		reader := repository.NewMockReader(t)
+		reader.On("ReadTree", mock.Anything, "new").Return([]repository.FileTreeEntry{
+			{Path: "dashboards/", Blob: false},
+			{Path: "dashboards/dev.values.yaml", Blob: true},
+			{Path: "dashboards/eu/prod.values.yaml", Blob: true},
+			{Path: "dashboards/nested/_template.json", Blob: true},
+			{Path: "dashboards/nested/qa.values.yaml", Blob: true},
+			{Path: "dashboards/test.values.yaml", Blob: true},
+		}, nil)
+		repo := &compositeRepo{MockVersioned: repository.NewMockVersioned(t), MockReader: reader}
+
+		diff := []repository.VersionedFileChange{
+			{Action: repository.FileActionDeleted, Path: "dashboards/_template.json", Ref: "new", PreviousRef: "old"},
+			{Action: repository.FileActionUpdated, Path: "dashboards/dev.values.yaml", Ref: "new", PreviousRef: "old"},
+			{Action: repository.FileActionCreated, Path: "dashboards/test.values.yaml", Ref: "new"},
+		}
+
+		expanded, err := expandTemplateChanges(context.Background(), repo, "new", diff)
+		require.NoError(t, err)
+		require.Equal(t, []repository.VersionedFileChange{
+			{Action: repository.FileActionDeleted, Path: "dashboards/_template.json", Ref: "new", PreviousRef: "old"},
+			{Action: repository.FileActionDeleted, Path: "dashboards/dev.values.yaml", Ref: "new", PreviousRef: "old"},
+			{Action: repository.FileActionCreated, Path: "dashboards/test.values.yaml", Ref: "new"},
+			{Action: repository.FileActionDeleted, Path: "dashboards/eu/prod.values.yaml", Ref: "new", PreviousRef: "old"},
+		}, expanded)
+	})
+
+	t.Run("values files of a deleted template are recreated from the template of a parent directory", func(t *testing.T) {
+		reader := repository.NewMockReader(t)
+		reader.On("ReadTree", mock.Anything, "new").Return([]repository.FileTreeEntry{
+			{Path: "_template.json", Blob: true},
+			{Path: "dashboards/", Blob: false},
+			{Path: "dashboards/dev.values.yaml", Blob: true},
+		}, nil)
+		repo := &compositeRepo{MockVersioned: repository.NewMockVersioned(t), MockReader: reader}
+
+		diff := []repository.VersionedFileChange{
+			{Action: repository.FileActionDeleted, Path: "dashboards/_template.json", Ref: "new", PreviousRef: "old"},
+		}
+
+		expanded, err := expandTemplateChanges(context.Background(), repo, "new", diff)
+		require.NoError(t, err)
+		require.Equal(t, []repository.VersionedFileChange{
+			{Action: repository.FileActionDeleted, Path: "dashboards/_template.json", Ref: "new", PreviousRef: "old"},
+			{Action: repository.FileActionDeleted, Path: "dashboards/dev.values.yaml", Ref: "new", PreviousRef: "old"},
+			{Action: repository.FileActionCreated, Path: "dashboards/dev.values.yaml", Ref: "new"},
+		}, expanded)
+	})
+}
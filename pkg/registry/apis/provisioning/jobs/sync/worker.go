@@ -182,9 +182,32 @@ func (r *SyncWorker) Process(ctx context.Context, repo repository.Repository, jo
 	syncCtx, syncSpan := r.tracer.Start(ctx, "provisioning.sync.execute")
 	progress.SetMessage(ctx, "execute sync job")
 	progress.StrictMaxErrors(20) // make it stop after 20 errors
-	currentRef, syncError := r.syncer.Sync(syncCtx, rw, *job.Spec.Pull, repositoryResources, clients, progress, quotaTracker)
+	// Conflicts are only looked for when the repository has a conflict strategy
+	syncProgress := progress
+	var checker ConflictChecker
+	var conflicts *conflictRecorder
+	if strategy := cfg.Spec.Sync.ConflictStrategy; strategy != "" {
+		conflicts = newConflictRecorder(progress, strategy, lastRef, cfg.Status.Sync.Conflicts, job.Spec.Pull.Resolutions)
+		syncProgress = conflicts
+		checker = conflicts
+	}
+	currentRef, syncError := r.syncer.Sync(syncCtx, rw, *job.Spec.Pull, repositoryResources, clients, syncProgress, checker, quotaTracker)
+
+	// Conflicts that kept the Grafana version are listed until they are resolved
+	var pendingConflicts []provisioning.ResourceConflict
+	if conflicts != nil {
+		if syncError == nil {
+			pendingConflicts = conflicts.ResolvePending(syncCtx, currentRef, repositoryResources, clients)
+		} else {
+			pendingConflicts = conflicts.Pending()
+		}
+	}
+
 	jobStatus := progress.Complete(ctx, syncError)
 	syncStatus = jobStatus.ToSyncStatus(job.Name)
+	if conflicts != nil {
+		syncStatus.Conflicts = append(pendingConflicts, conflicts.Conflicts()...)
+	}
 	resultReasons := progress.ResultReasons()
 	isQuotaWarning := slices.Contains(resultReasons, provisioning.ReasonQuotaExceeded)
 
@@ -1009,3 +1009,42 @@ func TestAuthorizeAdminJob(t *testing.T) {
 		assert.True(t, apierrors.IsForbidden(err))
 	})
 }
+
+func TestValidateRollbackJob(t *testing.T) {
+	ctx := context.Background()
+	cfg := newTestRepo("my-repo", "default")
+	jobUID := "6f9a4f0e-2b6e-4a4a-9a4e-1c2d3e4f5a6b"
+
+	t.Run("invalid job uid", func(t *testing.T) {
+		c := &jobsConnector{}
+		err := c.validateRollbackJob(ctx, cfg, &provisioning.RollbackJobOptions{Job: "../other"})
+		require.Error(t, err)
+		assert.True(t, apierrors.IsBadRequest(err))
+	})
+
+	t.Run("job without recorded changes", func(t *testing.T) {
+		history := jobs.NewMockHistoryReader(t)
+		history.EXPECT().GetJob(mock.Anything, "default", "my-repo", jobUID).Return(&provisioning.Job{
+			Status: provisioning.JobStatus{Finished: 1},
+		}, nil)
+
+		c := &jobsConnector{historic: history}
+		err := c.validateRollbackJob(ctx, cfg, &provisioning.RollbackJobOptions{Job: jobUID})
+		require.Error(t, err)
+		assert.True(t, apierrors.IsBadRequest(err))
+		assert.Contains(t, err.Error(), "did not record any resource change")
+	})
+
+	t.Run("finished job with changes", func(t *testing.T) {
+		history := jobs.NewMockHistoryReader(t)
+		history.EXPECT().GetJob(mock.Anything, "default", "my-repo", jobUID).Return(&provisioning.Job{
+			Status: provisioning.JobStatus{
+				Finished: 1,
+				Changes:  []provisioning.JobResourceChange{{Name: "a", Action: provisioning.ResourceActionCreate, Version: "1"}},
+			},
+		}, nil)
+
+		c := &jobsConnector{historic: history}
+		require.NoError(t, c.validateRollbackJob(ctx, cfg, &provisioning.RollbackJobOptions{Job: jobUID}))
+	})
+}
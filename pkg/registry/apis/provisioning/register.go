@@ -37,6 +37,7 @@ import (
 	informer "github.com/grafana/grafana/apps/provisioning/pkg/informer"
 	appjobs "github.com/grafana/grafana/apps/provisioning/pkg/jobs"
 	"github.com/grafana/grafana/apps/provisioning/pkg/loki"
+	"github.com/grafana/grafana/apps/provisioning/pkg/policy"
 	"github.com/grafana/grafana/apps/provisioning/pkg/quotas"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 	repogithub "github.com/grafana/grafana/apps/provisioning/pkg/repository/github"
@@ -56,6 +57,7 @@ import (
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/migrate"
 	movepkg "github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/move"
 	releaseresourcespkg "github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/releaseresources"
+	rollbackpkg "github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/rollback"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/sync"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/usage"
@@ -122,6 +124,7 @@ type APIBuilder struct {
 	}
 	jobHistoryConfig  *JobHistoryConfig
 	jobHistoryLoki    *jobs.LokiJobHistory
+	jobHistory        jobs.HistoryReader
 	resourceLister    resources.ResourceLister
 	unified           resource.ResourceClient
 	repoFactory       repository.Factory
@@ -249,7 +252,6 @@ func NewAPIBuilder(
 		clients:                             clients,
 		supportedResources:                  supportedResources,
 		parsers:                             parsers,
-		repositoryResources:                 resources.NewRepositoryResourcesFactory(parsers, clients, resourceLister, features.IsEnabledGlobally(featuremgmt.FlagProvisioningFolderMetadata), folderAPIVersion), //nolint:staticcheck
 		resourceLister:                      resourceLister,
 		unified:                             unified,
 		access:                              accessChecker,
@@ -270,6 +272,7 @@ func NewAPIBuilder(
 		// Per-file cap for the files API. Non-positive (<=0) disables the cap.
 		maxFileSize: maxFileSize,
 	}
+	b.repositoryResources = resources.NewRepositoryResourcesFactory(parsers, clients, resourceLister, policy.NewLister(b.policyClient), features.IsEnabledGlobally(featuremgmt.FlagProvisioningFolderMetadata), folderAPIVersion) //nolint:staticcheck
 
 	for _, builder := range extraBuilders {
 		b.extras = append(b.extras, builder(b))
@@ -478,6 +481,9 @@ func (b *APIBuilder) GetAuthorizer() authorizer.Authorizer {
 // Historic Jobs:
 //   - Read-only: historicjobs:read
 //
+// Repository Policies:
+//   - CRUD: repositorypolicies:create/read/write/delete
+//
 // Settings:
 //   - settings:read - granted to Viewer (all logged-in users)
 //
@@ -506,6 +512,15 @@ func (b *APIBuilder) authorizeResource(ctx context.Context, a authorizer.Attribu
 			Name:      a.GetName(),
 			Namespace: a.GetNamespace(),
 		}, ""))
+	case provisioning.RepositoryPolicyResourceInfo.GetName():
+		// Policies decide what may be synced, so only admins manage them
+		return toAuthorizerDecision(b.accessWithAdmin.Check(ctx, authlib.CheckRequest{
+			Verb:      a.GetVerb(),
+			Group:     provisioning.GROUP,
+			Resource:  provisioning.RepositoryPolicyResourceInfo.GetName(),
+			Name:      a.GetName(),
+			Namespace: a.GetNamespace(),
+		}, ""))
 	case "settings":
 		// Settings are read-only and accessible by all logged-in users (Viewer role)
 		return toAuthorizerDecision(b.accessWithViewer.Check(ctx, authlib.CheckRequest{
@@ -712,6 +727,12 @@ func (b *APIBuilder) GetClient() client.ProvisioningV0alpha1Interface {
 	return b.client
 }
 
+// policyClient returns the client used to read repository policies.
+// It is nil until the post start hook has run.
+func (b *APIBuilder) policyClient(_ context.Context) (client.ProvisioningV0alpha1Interface, error) {
+	return b.client, nil
+}
+
 func (b *APIBuilder) GetJobQueue() jobs.Queue {
 	return b.jobs
 }
@@ -809,6 +830,8 @@ func (b *APIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver.APIGroupI
 	}
 	b.admissionHandler.RegisterValidator(provisioning.JobResourceInfo.GetName(), appjobs.NewAdmissionValidator(jobSupportedResources))
 	b.admissionHandler.RegisterValidator(provisioning.HistoricJobResourceInfo.GetName(), appjobs.NewHistoricJobAdmissionValidator())
+	// Repository policy validator (no mutator needed)
+	b.admissionHandler.RegisterValidator(provisioning.RepositoryPolicyResourceInfo.GetName(), policy.NewAdmissionValidator())
 
 	jobStore, err := grafanaregistry.NewCompleteRegistryStore(opts.Scheme, provisioning.JobResourceInfo, opts.OptsGetter)
 	if err != nil {
@@ -834,6 +857,7 @@ func (b *APIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver.APIGroupI
 		}
 		storage[provisioning.HistoricJobResourceInfo.StoragePath()] = historicJobStore
 	}
+	b.jobHistory = jobHistory
 
 	connectionsStore, err := grafanaregistry.NewRegistryStore(opts.Scheme, provisioning.ConnectionResourceInfo, opts.OptsGetter)
 	if err != nil {
@@ -841,6 +865,11 @@ func (b *APIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver.APIGroupI
 	}
 	connectionStatusStorage := grafanaregistry.NewRegistryStatusStore(opts.Scheme, connectionsStore)
 
+	policiesStore, err := grafanaregistry.NewRegistryStore(opts.Scheme, provisioning.RepositoryPolicyResourceInfo, opts.OptsGetter)
+	if err != nil {
+		return fmt.Errorf("failed to create repository policy storage: %w", err)
+	}
+
 	// When serving a non-storage version (e.g. v1beta1), wrap the CRUD stores
 	// so that List re-stamps each item's apiVersion to match the served version.
 	// See grafanaregistry.VersionedStore for details on why this is necessary.
@@ -848,12 +877,14 @@ func (b *APIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver.APIGroupI
 		storage[provisioning.RepositoryResourceInfo.StoragePath()] = grafanaregistry.NewVersionedStore(repositoryStorage, b.gv)
 		storage[provisioning.ConnectionResourceInfo.StoragePath()] = grafanaregistry.NewVersionedStore(connectionsStore, b.gv)
 		storage[provisioning.JobResourceInfo.StoragePath()] = grafanaregistry.NewVersionedStore(jobStore, b.gv)
+		storage[provisioning.RepositoryPolicyResourceInfo.StoragePath()] = grafanaregistry.NewVersionedStore(policiesStore, b.gv)
 		b.repoStore = grafanaregistry.NewVersionedStore(repositoryStorage, b.gv)
 		b.connectionStore = grafanaregistry.NewVersionedStore(connectionsStore, b.gv)
 	} else {
 		storage[provisioning.RepositoryResourceInfo.StoragePath()] = repositoryStorage
 		storage[provisioning.ConnectionResourceInfo.StoragePath()] = connectionsStore
 		storage[provisioning.JobResourceInfo.StoragePath()] = jobStore
+		storage[provisioning.RepositoryPolicyResourceInfo.StoragePath()] = policiesStore
 		b.repoStore = repositoryStorage
 		b.connectionStore = connectionsStore
 	}
@@ -1022,9 +1053,10 @@ func (b *APIBuilder) GetPostStartHooks() (map[string]genericapiserver.PostStartH
 			fixMetadataWorker := fixfoldermetadata.NewWorker(resources.FolderGVKForVersion(b.folderAPIVersion))
 			releaseResourcesWorker := releaseresourcespkg.NewWorker(b.resourceLister, b.clients, 10)
 			deleteResourcesWorker := deleteresourcespkg.NewWorker(b.resourceLister, b.clients, 10)
+			rollbackWorker := rollbackpkg.NewWorker(b.jobHistory, b.clients, syncWorker, stageIfPossible, metrics)
 
 			// All workers registered - export/migrate will check feature flag at runtime
-			workers := make([]jobs.Worker, 0, 8+len(b.extraWorkers))
+			workers := make([]jobs.Worker, 0, 9+len(b.extraWorkers))
 			workers = append(workers,
 				deleteResourcesWorker,
 				deleteWorker,
@@ -1033,6 +1065,7 @@ func (b *APIBuilder) GetPostStartHooks() (map[string]genericapiserver.PostStartH
 				migrationWorker,
 				moveWorker,
 				releaseResourcesWorker,
+				rollbackWorker,
 				syncWorker,
 			)
 
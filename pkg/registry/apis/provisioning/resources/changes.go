@@ -0,0 +1,61 @@
+package resources
+
+import (
+	"context"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+)
+
+type changeRecorderKey struct{}
+
+// ChangeRecorder receives the resource versions around every resource change made by a job
+type ChangeRecorder func(change provisioning.JobResourceChange)
+
+// WithChangeRecorder returns a context where resources written or deleted from repository
+// files are reported to the recorder, so the job that changed them can be rolled back.
+func WithChangeRecorder(ctx context.Context, recorder ChangeRecorder) context.Context {
+	if recorder == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, changeRecorderKey{}, recorder)
+}
+
+// RecordsChanges reports whether ctx carries a change recorder
+func RecordsChanges(ctx context.Context) bool {
+	_, ok := ctx.Value(changeRecorderKey{}).(ChangeRecorder)
+	return ok
+}
+
+// RecordChange reports the change to the recorder stored in ctx, if any
+func RecordChange(ctx context.Context, change provisioning.JobResourceChange) {
+	if recorder, ok := ctx.Value(changeRecorderKey{}).(ChangeRecorder); ok {
+		recorder(change)
+	}
+}
+
+// recordParsedChange reports the change applied by a successful Run of the parsed resource
+func recordParsedChange(ctx context.Context, path string, parsed *ParsedResource) {
+	if !RecordsChanges(ctx) || parsed.Obj == nil {
+		return
+	}
+
+	change := provisioning.JobResourceChange{
+		Group:    parsed.GVR.Group,
+		Resource: parsed.GVR.Resource,
+		Name:     parsed.Obj.GetName(),
+		Path:     path,
+		Action:   parsed.Action,
+	}
+	if parsed.Existing != nil && parsed.Action != provisioning.ResourceActionCreate {
+		change.PreviousVersion = parsed.Existing.GetResourceVersion()
+	}
+	if parsed.Upsert != nil && parsed.Action != provisioning.ResourceActionDelete {
+		change.Version = parsed.Upsert.GetResourceVersion()
+	}
+
+	// Nothing was written, e.g. a delete of a resource that no longer exists
+	if change.PreviousVersion == "" && change.Version == "" {
+		return
+	}
+	RecordChange(ctx, change)
+}
@@ -70,6 +70,11 @@ type SupportedResource struct {
 	Capabilities sets.Set[string]
 }
 
+// unmanagedGroups are the API groups whose objects are stored without the managed-by annotation,
+// so a repository could not keep the ownership of them. The notification kinds of alerting live
+// in the Alertmanager configuration of the legacy storage.
+var unmanagedGroups = sets.New("notifications.alerting.grafana.app")
+
 // IsActive reports whether the resource is acted on by the pipeline (the default).
 func (r SupportedResource) IsActive() bool { return !r.Capabilities.Has(CapabilityDisabled) }
 
@@ -86,8 +91,8 @@ func (r SupportedResource) IsFolderScoped() bool { return r.Capabilities.Has(Cap
 // Group and kind are split on the last "/" (groups contain dots and may be multi-segment).
 // Capabilities are ":"-separated and must be in KnownCapabilities. Parsing is strict and
 // fails fast at startup: each entry must be "<group>/<Kind>" with a non-empty, dotted group
-// and non-empty kind; capabilities must be known and not repeated; and a resource ID must
-// not appear twice. Whitespace is trimmed and empty entries are skipped.
+// and non-empty kind outside of the unmanaged groups; capabilities must be known and not
+// repeated; and a resource ID must not appear twice. Whitespace is trimmed and empty entries are skipped.
 func ParseSupportedResources(entries []string) ([]SupportedResource, error) {
 	out := make([]SupportedResource, 0, len(entries))
 	seen := sets.New[schema.GroupKind]()
@@ -107,6 +112,9 @@ func ParseSupportedResources(entries []string) ([]SupportedResource, error) {
 		if !strings.Contains(group, ".") {
 			return nil, fmt.Errorf("invalid provisioning resource %q: group %q must contain a dot", entry, group)
 		}
+		if unmanagedGroups.Has(group) {
+			return nil, fmt.Errorf("invalid provisioning resource %q: the objects of group %q can not be managed by a repository", entry, group)
+		}
 
 		gk := schema.GroupKind{Group: group, Kind: kind}
 		if seen.Has(gk) {
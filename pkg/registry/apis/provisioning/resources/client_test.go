@@ -109,6 +109,7 @@ func TestParseSupportedResources(t *testing.T) {
 		{"no slash", "Dashboard"},
 		{"group without a dot", "dashboard/Dashboard"},
 		{"unknown capability", "dashboard.grafana.app/Dashboard:bogus"},
+		{"unmanaged group", "notifications.alerting.grafana.app/Receiver:disabled"},
 	} {
 		t.Run("rejects "+tc.name, func(t *testing.T) {
 			_, err := ParseSupportedResources([]string{tc.entry})
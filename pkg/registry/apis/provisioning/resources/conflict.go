@@ -0,0 +1,138 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+// ResourceConflictError is reported when a resource changed both in the repository
+// and in Grafana since the last sync.
+type ResourceConflictError struct {
+	Conflict provisioning.ResourceConflict
+}
+
+func (e *ResourceConflictError) Error() string {
+	c := e.Conflict
+	msg := fmt.Sprintf("resource %s was changed in both the repository and grafana (previous file hash %q)", c.Path, c.BaseHash)
+	switch c.Resolution {
+	case provisioning.ConflictStrategyGrafana:
+		return msg + ": kept the grafana version"
+	case provisioning.ConflictStrategyBranch:
+		return fmt.Sprintf("%s: kept the grafana version and wrote it to branch %s", msg, c.Branch)
+	default:
+		return msg + ": applied the repository version"
+	}
+}
+
+func NewResourceConflictError(conflict provisioning.ResourceConflict) *ResourceConflictError {
+	return &ResourceConflictError{Conflict: conflict}
+}
+
+// IsConflictWarning returns true when the error reports a conflict that was resolved
+// by applying the repository version, so the resource operation still succeeded.
+func IsConflictWarning(err error) bool {
+	var conflictErr *ResourceConflictError
+	return errors.As(err, &conflictErr) && conflictErr.Conflict.Resolution == provisioning.ConflictStrategyRepository
+}
+
+// WriteExistingResourceToRef writes the current Grafana version of the resource defined
+// in the file at path/ref back to the same path on targetRef.
+// It returns the name of the resource that was written.
+func (r *ResourcesManager) WriteExistingResourceToRef(ctx context.Context, path, ref, targetRef, message string) (string, error) {
+	info, err := r.repo.Read(ctx, path, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	parsed, err := r.parser.Parse(ctx, info)
+	if err != nil {
+		return "", err
+	}
+
+	name := parsed.Obj.GetName()
+	ctx, _, err = identity.WithProvisioningIdentity(ctx, r.repo.Config().GetNamespace())
+	if err != nil {
+		return name, fmt.Errorf("unable to use provisioning identity: %w", err)
+	}
+
+	existing, err := parsed.Client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return name, fmt.Errorf("resource %s no longer exists in grafana", name)
+		}
+		return name, fmt.Errorf("failed to get existing resource: %w", err)
+	}
+
+	current := ParsedResource{
+		Info: &repository.FileInfo{Path: path, Ref: targetRef},
+		Obj:  existing,
+	}
+	body, err := current.ToSaveBytes()
+	if err != nil {
+		return name, err
+	}
+
+	if err := r.repo.Write(ctx, path, targetRef, body, message); err != nil {
+		return name, fmt.Errorf("failed to write file: %s, %w", path, err)
+	}
+	return name, nil
+}
+
+// GrafanaMatchesFile compares the resource defined in the file at path/ref with the version
+// saved in Grafana. It returns the name of the resource and the hash of the file.
+// A resource that no longer exists in Grafana is reported with a not found error.
+func (r *ResourcesManager) GrafanaMatchesFile(ctx context.Context, path, ref string) (string, string, bool, error) {
+	info, err := r.repo.Read(ctx, path, ref)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	parsed, err := r.parser.Parse(ctx, info)
+	if err != nil {
+		return "", info.Hash, false, err
+	}
+
+	name := parsed.Obj.GetName()
+	ctx, _, err = identity.WithProvisioningIdentity(ctx, r.repo.Config().GetNamespace())
+	if err != nil {
+		return name, info.Hash, false, fmt.Errorf("unable to use provisioning identity: %w", err)
+	}
+
+	existing, err := parsed.Client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return name, info.Hash, false, fmt.Errorf("failed to get existing resource: %w", err)
+	}
+	return name, info.Hash, sameContent(parsed.Obj, existing), nil
+}
+
+// serverSpecFields are set by Grafana when a resource is saved, so they are not part of its content
+var serverSpecFields = []string{"id", "uid", "version"}
+
+// sameContent compares what a file defines with a saved resource, ignoring metadata and status
+func sameContent(file, saved *unstructured.Unstructured) bool {
+	return equality.Semantic.DeepEqual(content(file), content(saved))
+}
+
+func content(obj *unstructured.Unstructured) map[string]any {
+	c := obj.DeepCopy().Object
+	delete(c, "apiVersion")
+	delete(c, "kind")
+	delete(c, "metadata")
+	delete(c, "status")
+	if spec, ok := c["spec"].(map[string]any); ok {
+		for _, field := range serverSpecFields {
+			delete(spec, field)
+		}
+	}
+	return c
+}
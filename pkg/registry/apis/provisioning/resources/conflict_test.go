@@ -0,0 +1,38 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSameContent(t *testing.T) {
+	file := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1beta1",
+		"kind":       "Dashboard",
+		"metadata":   map[string]any{"name": "home"},
+		"spec":       map[string]any{"title": "Home", "panels": []any{}},
+	}}
+
+	t.Run("ignores metadata, status and fields set by grafana", func(t *testing.T) {
+		saved := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "dashboard.grafana.app/v1",
+			"kind":       "Dashboard",
+			"metadata": map[string]any{
+				"name":            "home",
+				"resourceVersion": "42",
+				"annotations":     map[string]any{"grafana.app/sourceChecksum": "abc"},
+			},
+			"spec":   map[string]any{"title": "Home", "panels": []any{}, "id": int64(3), "uid": "home", "version": int64(7)},
+			"status": map[string]any{},
+		}}
+		require.True(t, sameContent(file, saved))
+	})
+
+	t.Run("detects changed spec", func(t *testing.T) {
+		saved := file.DeepCopy()
+		require.NoError(t, unstructured.SetNestedField(saved.Object, "Changed in Grafana", "spec", "title"))
+		require.False(t, sameContent(file, saved))
+	})
+}
@@ -12,6 +12,7 @@ var (
 	ErrPathTooDeep              = errors.New("the path is too deep")
 	ErrUnsupportedFileExtension = errors.New("unsupported file extension")
 	ErrNotRelative              = errors.New("path must be relative to the root")
+	ErrTemplateFile             = errors.New("template is not a resource, it is instantiated by values files")
 )
 
 const maxPathDepth = 8
@@ -30,15 +31,18 @@ var readOnlyExtensions = map[string]bool{
 
 // IsPathSupported checks if the file path is supported by the provisioning API for write operations.
 // It validates the path is safe and that the file extension is one of the resource types
-// (yml, yaml, json).
+// (yml, yaml, json) or a source file (jsonnet, cue).
 func IsPathSupported(filePath string) error {
 	if err := validatePathBasics(filePath); err != nil {
 		return err
 	}
 
 	if !safepath.IsDir(filePath) {
+		if IsTemplateFile(filePath) {
+			return ErrTemplateFile
+		}
 		ext := strings.ToLower(path.Ext(filePath))
-		if !resourceExtensions[ext] {
+		if !resourceExtensions[ext] && !IsSourceFile(filePath) {
 			return ErrUnsupportedFileExtension
 		}
 	}
@@ -47,7 +51,7 @@ func IsPathSupported(filePath string) error {
 }
 
 // IsReadablePath checks if the file path is supported for read operations. This includes resource
-// files (yml, yaml, json) and read-only files (md).
+// files (yml, yaml, json), source files (jsonnet, cue) and read-only files (md).
 func IsReadablePath(filePath string) error {
 	if err := validatePathBasics(filePath); err != nil {
 		return err
@@ -55,7 +59,7 @@ func IsReadablePath(filePath string) error {
 
 	if !safepath.IsDir(filePath) {
 		ext := strings.ToLower(path.Ext(filePath))
-		if !resourceExtensions[ext] && !readOnlyExtensions[ext] {
+		if !resourceExtensions[ext] && !readOnlyExtensions[ext] && !IsSourceFile(filePath) {
 			return ErrUnsupportedFileExtension
 		}
 	}
@@ -69,7 +73,7 @@ func IsRawFile(filePath string) bool {
 		return false
 	}
 	ext := strings.ToLower(path.Ext(filePath))
-	return readOnlyExtensions[ext]
+	return readOnlyExtensions[ext] || IsTemplateFile(filePath)
 }
 
 func validatePathBasics(filePath string) error {
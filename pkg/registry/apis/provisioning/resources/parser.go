@@ -92,6 +92,8 @@ func (f *parserFactory) GetParser(ctx context.Context, repo repository.Reader) (
 		clients:               clients,
 		config:                config,
 		folderMetadataEnabled: f.folderMetadataEnabled,
+		sources:               newSourceEvaluator(repo, config.Spec.Sources),
+		templates:             newTemplateRenderer(repo, config.Name),
 	}, nil
 }
 
@@ -111,6 +113,12 @@ type parser struct {
 	clients ResourceClients
 
 	folderMetadataEnabled bool
+
+	// evaluates Jsonnet and CUE source files
+	sources *sourceEvaluator
+
+	// instantiates templates with values files
+	templates *templateRenderer
 }
 
 type ParsedResource struct {
@@ -126,6 +134,9 @@ type ParsedResource struct {
 	// Check for classic file types (dashboard.json, etc)
 	Classic provisioning.ClassicFileType
 
+	// Path of the template the resource was instantiated from, when the file is a values file
+	Template string
+
 	// Parsed contents
 	Obj *unstructured.Unstructured
 	// Metadata accessor for the file object
@@ -177,8 +188,20 @@ func (r *parser) Parse(ctx context.Context, info *repository.FileInfo) (parsed *
 		return nil, NewResourceValidationError(err)
 	}
 
+	// Source files are evaluated first, and the output is parsed like any other file
+	evaluated, err := r.sources.Evaluate(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+
+	// Values files in a template directory instantiate the template
+	evaluated, parsed.Template, err = r.templates.Render(ctx, evaluated)
+	if err != nil {
+		return nil, err
+	}
+
 	var gvk *schema.GroupVersionKind
-	parsed.Obj, gvk, parsed.Classic, err = ParseFileResource(ctx, info)
+	parsed.Obj, gvk, parsed.Classic, err = ParseFileResource(ctx, evaluated)
 	if err != nil {
 		return nil, err
 	}
@@ -226,7 +249,7 @@ func (r *parser) Parse(ctx context.Context, info *repository.FileInfo) (parsed *
 	})
 	parsed.Meta.SetSourceProperties(utils.SourceProperties{
 		Path:     info.Path, // joinPathWithRef(info.Path, info.Ref),
-		Checksum: info.Hash,
+		Checksum: evaluated.Hash,
 	})
 
 	if obj.GetName() == "" {
@@ -579,6 +602,13 @@ func (f *ParsedResource) ToSaveBytes() ([]byte, error) {
 		obj["metadata"] = map[string]any{"name": name}
 	}
 
+	if IsSourceFile(f.Info.Path) {
+		return nil, ErrGeneratedFromSource
+	}
+	if f.Template != "" {
+		return nil, ErrGeneratedFromTemplate
+	}
+
 	switch path.Ext(f.Info.Path) {
 	// JSON pretty print
 	case ".json":
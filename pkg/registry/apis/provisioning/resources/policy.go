@@ -0,0 +1,74 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	clientset "github.com/grafana/grafana/apps/provisioning/pkg/generated/clientset/versioned"
+	provisioningv0alpha1 "github.com/grafana/grafana/apps/provisioning/pkg/generated/clientset/versioned/typed/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/policy"
+	"github.com/grafana/grafana/pkg/services/apiserver"
+)
+
+// PolicyViolationError is returned when a resource does not satisfy the repository policies.
+// The resource is only applied when none of the violations are blocking.
+type PolicyViolationError struct {
+	Path       string
+	Violations []policy.Violation
+}
+
+func (e *PolicyViolationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.String())
+	}
+	return fmt.Sprintf("policy violations in %s: %s", e.Path, strings.Join(msgs, "; "))
+}
+
+// Blocking returns true when the resource was not applied because of the violations
+func (e *PolicyViolationError) Blocking() bool {
+	return policy.Blocking(e.Violations)
+}
+
+func NewPolicyViolationError(path string, violations []policy.Violation) *PolicyViolationError {
+	return &PolicyViolationError{Path: path, Violations: violations}
+}
+
+// IsPolicyWarning returns true when the error only reports policy violations
+// that did not stop the resource from being applied.
+func IsPolicyWarning(err error) bool {
+	var policyErr *PolicyViolationError
+	return errors.As(err, &policyErr) && !policyErr.Blocking()
+}
+
+// NewPolicyLister returns a policy.Lister that creates its provisioning client on first use,
+// as the rest config is only available once the API server has started
+func NewPolicyLister(configProvider apiserver.RestConfigProvider) policy.Lister {
+	var (
+		once    sync.Once
+		client  provisioningv0alpha1.ProvisioningV0alpha1Interface
+		initErr error
+	)
+
+	return policy.NewLister(func(ctx context.Context) (provisioningv0alpha1.ProvisioningV0alpha1Interface, error) {
+		once.Do(func() {
+			restConfig, err := configProvider.GetRestConfig(ctx)
+			if err != nil {
+				initErr = fmt.Errorf("get rest config: %w", err)
+				return
+			}
+
+			c, err := clientset.NewForConfig(restConfig)
+			if err != nil {
+				initErr = fmt.Errorf("create provisioning client: %w", err)
+				return
+			}
+			client = c.ProvisioningV0alpha1()
+		})
+
+		return client, initErr
+	})
+}
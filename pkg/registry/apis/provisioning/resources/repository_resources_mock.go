@@ -252,6 +252,78 @@ func (_c *MockRepositoryResources_FindResourcePath_Call) RunAndReturn(run func(c
 	return _c
 }
 
+// GrafanaMatchesFile provides a mock function with given fields: ctx, path, ref
+func (_m *MockRepositoryResources) GrafanaMatchesFile(ctx context.Context, path string, ref string) (string, string, bool, error) {
+	ret := _m.Called(ctx, path, ref)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GrafanaMatchesFile")
+	}
+
+	var r0 string
+	var r1 string
+	var r2 bool
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, string, bool, error)); ok {
+		return rf(ctx, path, ref)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, path, ref)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) string); ok {
+		r1 = rf(ctx, path, ref)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) bool); ok {
+		r2 = rf(ctx, path, ref)
+	} else {
+		r2 = ret.Get(2).(bool)
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context, string, string) error); ok {
+		r3 = rf(ctx, path, ref)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// MockRepositoryResources_GrafanaMatchesFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GrafanaMatchesFile'
+type MockRepositoryResources_GrafanaMatchesFile_Call struct {
+	*mock.Call
+}
+
+// GrafanaMatchesFile is a helper method to define mock.On call
+//   - ctx context.Context
+//   - path string
+//   - ref string
+func (_e *MockRepositoryResources_Expecter) GrafanaMatchesFile(ctx interface{}, path interface{}, ref interface{}) *MockRepositoryResources_GrafanaMatchesFile_Call {
+	return &MockRepositoryResources_GrafanaMatchesFile_Call{Call: _e.mock.On("GrafanaMatchesFile", ctx, path, ref)}
+}
+
+func (_c *MockRepositoryResources_GrafanaMatchesFile_Call) Run(run func(ctx context.Context, path string, ref string)) *MockRepositoryResources_GrafanaMatchesFile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryResources_GrafanaMatchesFile_Call) Return(_a0 string, _a1 string, _a2 bool, _a3 error) *MockRepositoryResources_GrafanaMatchesFile_Call {
+	_c.Call.Return(_a0, _a1, _a2, _a3)
+	return _c
+}
+
+func (_c *MockRepositoryResources_GrafanaMatchesFile_Call) RunAndReturn(run func(context.Context, string, string) (string, string, bool, error)) *MockRepositoryResources_GrafanaMatchesFile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // List provides a mock function with given fields: ctx
 func (_m *MockRepositoryResources) List(ctx context.Context) (*v0alpha1.ResourceList, error) {
 	ret := _m.Called(ctx)
@@ -870,6 +942,66 @@ func (_c *MockRepositoryResources_Stats_Call) RunAndReturn(run func(context.Cont
 	return _c
 }
 
+// WriteExistingResourceToRef provides a mock function with given fields: ctx, path, ref, targetRef, message
+func (_m *MockRepositoryResources) WriteExistingResourceToRef(ctx context.Context, path string, ref string, targetRef string, message string) (string, error) {
+	ret := _m.Called(ctx, path, ref, targetRef, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteExistingResourceToRef")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) (string, error)); ok {
+		return rf(ctx, path, ref, targetRef, message)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) string); ok {
+		r0 = rf(ctx, path, ref, targetRef, message)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) error); ok {
+		r1 = rf(ctx, path, ref, targetRef, message)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryResources_WriteExistingResourceToRef_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WriteExistingResourceToRef'
+type MockRepositoryResources_WriteExistingResourceToRef_Call struct {
+	*mock.Call
+}
+
+// WriteExistingResourceToRef is a helper method to define mock.On call
+//   - ctx context.Context
+//   - path string
+//   - ref string
+//   - targetRef string
+//   - message string
+func (_e *MockRepositoryResources_Expecter) WriteExistingResourceToRef(ctx interface{}, path interface{}, ref interface{}, targetRef interface{}, message interface{}) *MockRepositoryResources_WriteExistingResourceToRef_Call {
+	return &MockRepositoryResources_WriteExistingResourceToRef_Call{Call: _e.mock.On("WriteExistingResourceToRef", ctx, path, ref, targetRef, message)}
+}
+
+func (_c *MockRepositoryResources_WriteExistingResourceToRef_Call) Run(run func(ctx context.Context, path string, ref string, targetRef string, message string)) *MockRepositoryResources_WriteExistingResourceToRef_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryResources_WriteExistingResourceToRef_Call) Return(_a0 string, _a1 error) *MockRepositoryResources_WriteExistingResourceToRef_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepositoryResources_WriteExistingResourceToRef_Call) RunAndReturn(run func(context.Context, string, string, string, string) (string, error)) *MockRepositoryResources_WriteExistingResourceToRef_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // WriteResourceFileFromObject provides a mock function with given fields: ctx, obj, options
 func (_m *MockRepositoryResources) WriteResourceFileFromObject(ctx context.Context, obj *unstructured.Unstructured, options WriteOptions) (string, error) {
 	ret := _m.Called(ctx, obj, options)
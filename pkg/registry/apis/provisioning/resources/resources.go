@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/policy"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
@@ -94,6 +95,7 @@ type ResourcesManager struct {
 	parser          Parser
 	clients         ResourceClients
 	resourcesLookup map[resourceID]string // the path with this k8s name
+	policies        []provisioning.RepositoryPolicy
 	mu              sync.RWMutex
 }
 
@@ -273,6 +275,13 @@ func (r *ResourcesManager) writeResourceFromParsed(ctx context.Context, path, re
 		return "", schema.GroupVersionKind{}, NewResourceValidationError(ErrMissingName)
 	}
 
+	// Blocking policy violations stop the resource before any folder is created.
+	// Other violations are returned once the resource is applied.
+	violations := policy.Evaluate(r.policies, path, parsed.Obj)
+	if policy.Blocking(violations) {
+		return parsed.Obj.GetName(), parsed.GVK, NewPolicyViolationError(path, violations)
+	}
+
 	// Check if the resource already exists
 	id := resourceID{
 		Name:     parsed.Obj.GetName(),
@@ -310,15 +319,25 @@ func (r *ResourcesManager) writeResourceFromParsed(ctx context.Context, path, re
 	parsed.Meta.SetUID("")
 	parsed.Meta.SetResourceVersion("")
 
+	if signer := SourceSignerFromContext(ctx, path); signer != "" {
+		parsed.Meta.SetAnnotation(utils.AnnoKeySourceSigner, signer)
+	}
+
 	runCtx, runSpan := tracing.Start(ctx, "provisioning.resources.write_resource_from_file.run_resource")
 	err := parsed.Run(runCtx)
 	if err != nil {
 		runSpan.RecordError(err)
 		// Wrap resource validation errors (like dashboard refresh interval) as warnings
 		err = wrapAsValidationErrorIfNeeded(err)
+	} else {
+		recordParsedChange(ctx, path, parsed)
 	}
 	runSpan.End()
 
+	if err == nil && len(violations) > 0 {
+		err = NewPolicyViolationError(path, violations)
+	}
+
 	return parsed.Obj.GetName(), parsed.GVK, err
 }
 
@@ -327,11 +346,14 @@ func (r *ResourcesManager) writeResourceFromParsed(ctx context.Context, path, re
 // Used by full sync where the old identity is known from Changes().Existing.
 func (r *ResourcesManager) ReplaceResourceFromFile(ctx context.Context, path, ref string, oldName string, oldGVR schema.GroupVersionResource, opts ...WriteResourceOption) (string, schema.GroupVersionKind, error) {
 	newName, gvk, err := r.WriteResourceFromFile(ctx, path, ref, opts...)
-	if err != nil || oldName == "" || oldName == newName {
+	if (err != nil && !IsPolicyWarning(err)) || oldName == "" || oldName == newName {
 		return newName, gvk, err
 	}
 
-	return newName, gvk, r.deleteOldResource(ctx, path, oldName, oldGVR, newName)
+	if deleteErr := r.deleteOldResource(ctx, path, oldName, oldGVR, newName); deleteErr != nil {
+		return newName, gvk, deleteErr
+	}
+	return newName, gvk, err
 }
 
 // ReplaceResourceFromFileByRef writes a resource from the file at path/ref and,
@@ -355,16 +377,19 @@ func (r *ResourcesManager) ReplaceResourceFromFileByRef(ctx context.Context, pat
 		opts = append(opts, WithExistingHash(oldInfo.Hash))
 	}
 	newName, gvk, writeErr := r.WriteResourceFromFile(ctx, path, ref, opts...)
-	if writeErr != nil {
+	if writeErr != nil && !IsPolicyWarning(writeErr) {
 		return newName, gvk, writeErr
 	}
 
 	oldName := oldParsed.Obj.GetName()
 	if oldName == "" || oldName == newName {
-		return newName, gvk, nil
+		return newName, gvk, writeErr
 	}
 
-	return newName, gvk, r.deleteOldResource(ctx, path, oldName, oldParsed.GVR, newName)
+	if err := r.deleteOldResource(ctx, path, oldName, oldParsed.GVR, newName); err != nil {
+		return newName, gvk, err
+	}
+	return newName, gvk, writeErr
 }
 
 // deleteOldResource deletes the previous resource when a name change is
@@ -409,9 +434,21 @@ func (r *ResourcesManager) deleteOldResource(ctx context.Context, sourcePath, ol
 		return fmt.Errorf("wrote new resource %s but failed to delete old resource %s: %w", newName, oldName, err)
 	}
 
-	if err := client.Delete(ctx, oldName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+	if err := client.Delete(ctx, oldName, metav1.DeleteOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
 		return fmt.Errorf("wrote new resource %s but failed to delete old resource %s: %w", newName, oldName, err)
 	}
+
+	RecordChange(ctx, provisioning.JobResourceChange{
+		Group:           oldGVR.Group,
+		Resource:        oldGVR.Resource,
+		Name:            oldName,
+		Path:            sourcePath,
+		Action:          provisioning.ResourceActionDelete,
+		PreviousVersion: existing.GetResourceVersion(),
+	})
 	return nil
 }
 
@@ -441,6 +478,7 @@ func (r *ResourcesManager) RenameResourceFile(ctx context.Context, previousPath,
 		if err := oldParsed.Run(ctx); err != nil {
 			return oldParsed.Obj.GetName(), oldParsed.ExistingFolder(), oldParsed.GVK, fmt.Errorf("failed to delete old resource: %w", err)
 		}
+		recordParsedChange(ctx, previousPath, oldParsed)
 	} else {
 		// Delete dry-run fetches the existing object (with ownership validation)
 		// without mutating it, populating oldParsed.Existing for identity comparison.
@@ -465,7 +503,7 @@ func (r *ResourcesManager) RenameResourceFile(ctx context.Context, previousPath,
 	oldFolderName := oldParsed.ExistingFolder()
 
 	newName, gvk, err := r.writeResourceFromParsed(ctx, newPath, newRef, newParsed, folderOpts...)
-	if err != nil {
+	if err != nil && !IsPolicyWarning(err) {
 		return oldParsed.Obj.GetName(), oldFolderName, gvk, fmt.Errorf("failed to write resource: %w", err)
 	}
 
@@ -477,7 +515,7 @@ func (r *ResourcesManager) RenameResourceFile(ctx context.Context, previousPath,
 		oldFolderName = ""
 	}
 
-	return newName, oldFolderName, gvk, nil
+	return newName, oldFolderName, gvk, err
 }
 
 func (r *ResourcesManager) RemoveResourceFromFile(ctx context.Context, path string, ref string) (string, string, schema.GroupVersionKind, error) {
@@ -501,6 +539,7 @@ func (r *ResourcesManager) RemoveResourceFromFile(ctx context.Context, path stri
 	if err != nil {
 		return objName, folderName, parsed.GVK, fmt.Errorf("failed to delete: %w", err)
 	}
+	recordParsedChange(ctx, path, parsed)
 
 	return objName, folderName, parsed.GVK, nil
 }
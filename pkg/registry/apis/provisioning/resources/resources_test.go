@@ -130,6 +130,41 @@ func TestWriteResourceFromParsed_FolderAnnotation(t *testing.T) {
 	})
 }
 
+func TestWriteResourceFromFile_SourceSigner(t *testing.T) {
+	write := func(t *testing.T, ctx context.Context) *ParsedResource {
+		t.Helper()
+		repo := repository.NewMockReaderWriter(t)
+		mockParser := NewMockParser(t)
+
+		clients := NewMockResourceClients(t)
+		clients.EXPECT().SupportedResources().Return([]SupportedResource{
+			{GroupKind: replaceTestGVK.GroupKind(), Capabilities: sets.New[string]()},
+		})
+
+		fileInfo := &repository.FileInfo{Data: []byte(`{}`), Path: "alerts/rule.json"}
+		repo.On("Read", mock.Anything, "alerts/rule.json", "").Return(fileInfo, nil)
+		parsed, _ := newWritableParsedResource("rule-1")
+		mockParser.On("Parse", mock.Anything, fileInfo).Return(parsed, nil)
+
+		mgr := NewResourcesManager(repo, nil, mockParser, clients)
+		_, _, err := mgr.WriteResourceFromFile(ctx, "alerts/rule.json", "")
+		require.NoError(t, err)
+		return parsed
+	}
+
+	t.Run("records the verified signer of the file", func(t *testing.T) {
+		ctx := WithSourceSigners(context.Background(), map[string]string{"alerts/rule.json": "alice@example.com"})
+		parsed := write(t, ctx)
+		require.Equal(t, "alice@example.com", parsed.Meta.GetAnnotation(grafanautils.AnnoKeySourceSigner))
+	})
+
+	t.Run("does not record a signer for files without one", func(t *testing.T) {
+		ctx := WithSourceSigners(context.Background(), map[string]string{"alerts/other.json": "alice@example.com"})
+		parsed := write(t, ctx)
+		require.Empty(t, parsed.Meta.GetAnnotation(grafanautils.AnnoKeySourceSigner))
+	})
+}
+
 func TestReplaceResourceFromFile(t *testing.T) {
 	t.Run("name unchanged skips delete", func(t *testing.T) {
 		repo := repository.NewMockReaderWriter(t)
@@ -0,0 +1,21 @@
+package resources
+
+import "context"
+
+type sourceSignersKey struct{}
+
+// WithSourceSigners returns a context that carries the verified signer of each file,
+// keyed by path. During sync, resources written from these files record their signer
+// in the source signer annotation.
+func WithSourceSigners(ctx context.Context, signers map[string]string) context.Context {
+	if len(signers) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, sourceSignersKey{}, signers)
+}
+
+// SourceSignerFromContext returns the verified signer of the file stored in ctx, or an empty string.
+func SourceSignerFromContext(ctx context.Context, path string) string {
+	signers, _ := ctx.Value(sourceSignersKey{}).(map[string]string)
+	return signers[path]
+}
@@ -0,0 +1,501 @@
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cuelang.org/go/cue/ast"
+	"github.com/google/go-jsonnet"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+)
+
+// Source files are Jsonnet or CUE programs that evaluate to a single resource.
+// The sources of the repository spec mark a folder, and everything below it, as
+// a source directory, with the format of its files and the libraries they use:
+//
+//	sources:
+//	  - path: dashboards
+//	    format: jsonnet
+//	    libraries: [vendor, lib]
+//
+// Library paths are relative to the repository root and must be vendored in the
+// repository. Evaluation is hermetic: files are only read from the repository at
+// the ref being synced, and nothing is fetched from the network. Each evaluation
+// runs in a child process under the deadline of sourceEvaluationTimeout, with its
+// memory and the bytes it reads and writes capped, so a runaway program fails its
+// file rather than the job.
+//
+// Changes are detected with a hash of the source file and of the files it can
+// import: every file in the libraries and the Jsonnet libraries (.libsonnet) of
+// its source directory. A change that only touches a library updates every
+// resource generated in the source directories using it.
+
+// SourceFormat is the language of the files in a source directory
+type SourceFormat = provisioning.SourceFormat
+
+const (
+	SourceFormatJsonnet = provisioning.SourceFormatJsonnet
+	SourceFormatCUE     = provisioning.SourceFormatCUE
+	// SourceFormatTemplate is reported for values files that fail to instantiate
+	// their template. It can not be used in a source directory.
+	SourceFormatTemplate SourceFormat = "template"
+)
+
+var sourceExtensions = map[string]SourceFormat{
+	".jsonnet": SourceFormatJsonnet,
+	".cue":     SourceFormatCUE,
+}
+
+// jsonnetLibraryExtension is the extension of the Jsonnet files that are only imported
+const jsonnetLibraryExtension = ".libsonnet"
+
+const (
+	// jsonnetMaxStack bounds the recursion depth of a Jsonnet evaluation
+	jsonnetMaxStack = 500
+
+	// sourceEvaluationTimeout bounds the time spent evaluating a single source file
+	sourceEvaluationTimeout = 10 * time.Second
+
+	// sourceMaxInputBytes bounds the size of a source file and of the files it imports
+	sourceMaxInputBytes = 10 << 20
+
+	// sourceMaxOutputBytes bounds the size of the JSON a source file evaluates to
+	sourceMaxOutputBytes = 10 << 20
+
+	// sourceMaxConcurrentEvaluations bounds the evaluator processes running at once
+	sourceMaxConcurrentEvaluations = 8
+)
+
+var sourceEvaluationSlots = make(chan struct{}, sourceMaxConcurrentEvaluations)
+
+var (
+	ErrNotInSourceDirectory = errors.New("source file is not in a source directory of the repository")
+	ErrGeneratedFromSource  = errors.New("resource is generated from a source file and can only be changed in the repository")
+	ErrSourceTooLarge       = errors.New("source exceeds the size limit")
+)
+
+// SourceEvaluationError is returned when a source file fails to evaluate
+type SourceEvaluationError struct {
+	Path   string
+	Format SourceFormat
+	Err    error
+}
+
+func (e *SourceEvaluationError) Error() string {
+	return fmt.Sprintf("evaluate %s source %s: %v", e.Format, e.Path, e.Err)
+}
+
+func (e *SourceEvaluationError) Unwrap() error {
+	return e.Err
+}
+
+func NewSourceEvaluationError(filePath string, format SourceFormat, err error) *SourceEvaluationError {
+	return &SourceEvaluationError{Path: filePath, Format: format, Err: err}
+}
+
+// IsSourceFile reports whether the file is a Jsonnet or CUE source file
+func IsSourceFile(filePath string) bool {
+	_, ok := sourceFormat(filePath)
+	return ok
+}
+
+func sourceFormat(filePath string) (SourceFormat, bool) {
+	if safepath.IsDir(filePath) {
+		return "", false
+	}
+	format, ok := sourceExtensions[strings.ToLower(path.Ext(filePath))]
+	return format, ok
+}
+
+// SourceDirectories are the source directories of the repository spec, by path
+type SourceDirectories map[string]provisioning.SourceDirectory
+
+// NewSourceDirectories collects the source directories of the repository spec, with
+// their path and libraries cleaned to a directory path
+func NewSourceDirectories(sources []provisioning.SourceDirectory) SourceDirectories {
+	dirs := make(SourceDirectories, len(sources))
+	for _, src := range sources {
+		dir := provisioning.SourceDirectory{
+			Path:   safepath.EnsureTrailingSlash(safepath.Clean(src.Path)),
+			Format: src.Format,
+		}
+		for _, lib := range src.Libraries {
+			dir.Libraries = append(dir.Libraries, safepath.EnsureTrailingSlash(safepath.Clean(lib)))
+		}
+		dirs[dir.Path] = dir
+	}
+	return dirs
+}
+
+// Find returns the closest source directory containing the file
+func (s SourceDirectories) Find(filePath string) (provisioning.SourceDirectory, bool) {
+	if len(s) == 0 {
+		return provisioning.SourceDirectory{}, false
+	}
+	for dir := safepath.Dir(filePath); ; dir = safepath.Dir(dir) {
+		if src, ok := s[dir]; ok {
+			return src, true
+		}
+		if dir == "" {
+			return provisioning.SourceDirectory{}, false
+		}
+	}
+}
+
+// Ignored reports whether the file is a source file outside of every source directory.
+// Those are treated like any other file that does not hold a resource (e.g. vendored libraries).
+func (s SourceDirectories) Ignored(filePath string) bool {
+	if !IsSourceFile(filePath) {
+		return false
+	}
+	_, ok := s.Find(filePath)
+	return !ok
+}
+
+// Dependents returns the paths of the source directories whose files can import the file
+func (s SourceDirectories) Dependents(filePath string) []string {
+	var dirs []string
+	for dir, src := range s {
+		if isSourceDependency(src, filePath) {
+			dirs = append(dirs, dir)
+		}
+	}
+	slices.Sort(dirs)
+	return dirs
+}
+
+// Resolve returns the tree as seen by a sync: source files outside of every source
+// directory are left out, and the hash of the other source files is combined with
+// the hash of the files they can import.
+func (s SourceDirectories) Resolve(tree []repository.FileTreeEntry) []repository.FileTreeEntry {
+	resolved := make([]repository.FileTreeEntry, 0, len(tree))
+	dependencies := map[string]string{}
+	for _, entry := range tree {
+		if !entry.Blob || !IsSourceFile(entry.Path) {
+			resolved = append(resolved, entry)
+			continue
+		}
+		src, ok := s.Find(entry.Path)
+		if !ok {
+			continue
+		}
+		hash, ok := dependencies[src.Path]
+		if !ok {
+			hash = sourceDependencyHash(src, tree)
+			dependencies[src.Path] = hash
+		}
+		entry.Hash = sourceInstanceHash(entry.Hash, hash)
+		resolved = append(resolved, entry)
+	}
+	return resolved
+}
+
+// isSourceDependency reports whether the source files of the directory can import the file:
+// a file in one of its libraries, or a Jsonnet library below the directory.
+func isSourceDependency(src provisioning.SourceDirectory, filePath string) bool {
+	if safepath.IsDir(filePath) {
+		return false
+	}
+	for _, lib := range src.Libraries {
+		if safepath.InDir(filePath, lib) {
+			return true
+		}
+	}
+	return src.Format == SourceFormatJsonnet &&
+		safepath.InDir(filePath, src.Path) &&
+		strings.ToLower(path.Ext(filePath)) == jsonnetLibraryExtension
+}
+
+// sourceDependencyHash combines the hashes of the files the source files of the directory
+// can import.  It is empty when they can not import any file.
+func sourceDependencyHash(src provisioning.SourceDirectory, tree []repository.FileTreeEntry) string {
+	var deps []repository.FileTreeEntry
+	for _, entry := range tree {
+		if entry.Blob && isSourceDependency(src, entry.Path) {
+			deps = append(deps, entry)
+		}
+	}
+	if len(deps) == 0 {
+		return ""
+	}
+	slices.SortFunc(deps, func(a, b repository.FileTreeEntry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	h := sha256.New()
+	for _, dep := range deps {
+		_, _ = fmt.Fprintf(h, "%s:%s\n", dep.Path, dep.Hash)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sourceInstanceHash combines the hash of a source file with the hash of its dependencies
+func sourceInstanceHash(sourceHash, dependencyHash string) string {
+	if dependencyHash == "" {
+		return sourceHash
+	}
+	sum := sha256.Sum256([]byte(sourceHash + ":" + dependencyHash))
+	return hex.EncodeToString(sum[:20])
+}
+
+// readSourceFile reads a file at ref, falling back to the configured branch when the
+// ref does not exist yet (e.g. a new branch being written through the files API)
+func readSourceFile(ctx context.Context, reader repository.Reader, filePath, ref string) (*repository.FileInfo, error) {
+	info, err := reader.Read(ctx, filePath, ref)
+	if err != nil && ref != "" && errors.Is(err, repository.ErrRefNotFound) {
+		return reader.Read(ctx, filePath, "")
+	}
+	return info, err
+}
+
+// sourceEvaluator evaluates the source files read by a parser.  The trees are
+// cached since a sync evaluates many files at the same ref.
+type sourceEvaluator struct {
+	reader  repository.Reader
+	sources SourceDirectories
+
+	mu    sync.Mutex
+	trees map[string][]repository.FileTreeEntry
+}
+
+func newSourceEvaluator(reader repository.Reader, sources []provisioning.SourceDirectory) *sourceEvaluator {
+	return &sourceEvaluator{
+		reader:  reader,
+		sources: NewSourceDirectories(sources),
+		trees:   map[string][]repository.FileTreeEntry{},
+	}
+}
+
+// Evaluate returns a copy of the file info holding the evaluated JSON, which can be
+// read with ParseFileResource.  The path and ref of the source file are kept, and its
+// hash is combined with the hash of the files it can import.
+func (e *sourceEvaluator) Evaluate(ctx context.Context, info *repository.FileInfo) (*repository.FileInfo, error) {
+	format, ok := sourceFormat(info.Path)
+	if !ok {
+		return info, nil
+	}
+	if e == nil || e.reader == nil {
+		return nil, NewResourceValidationError(ErrNotInSourceDirectory)
+	}
+
+	src, ok := e.sources.Find(info.Path)
+	if !ok {
+		return nil, NewResourceValidationError(ErrNotInSourceDirectory)
+	}
+	if src.Format != format {
+		return nil, NewResourceValidationError(fmt.Errorf("%s files are not evaluated in %q, which holds %s sources", format, src.Path, src.Format))
+	}
+
+	tree, err := e.tree(ctx, info.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	switch format {
+	case SourceFormatJsonnet:
+		out, err = e.evaluateJsonnet(ctx, info, src)
+	case SourceFormatCUE:
+		out, err = e.evaluateCUE(ctx, info, src, tree)
+	}
+	if err != nil {
+		return nil, NewSourceEvaluationError(info.Path, format, err)
+	}
+
+	evaluated := *info
+	evaluated.Data = out
+	evaluated.Hash = sourceInstanceHash(info.Hash, sourceDependencyHash(src, tree))
+	return &evaluated, nil
+}
+
+func (e *sourceEvaluator) evaluateJsonnet(ctx context.Context, info *repository.FileInfo, src provisioning.SourceDirectory) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, sourceEvaluationTimeout)
+	defer cancel()
+
+	importer := &jsonnetImporter{
+		ctx:       ctx,
+		reader:    e.reader,
+		ref:       info.Ref,
+		libraries: src.Libraries,
+		cache:     map[string]jsonnet.Contents{},
+	}
+	if err := importer.count(len(info.Data)); err != nil {
+		return nil, err
+	}
+
+	return runSourceEvaluation(ctx, sourceEvaluationRequest{
+		Format:    SourceFormatJsonnet,
+		Path:      info.Path,
+		Data:      info.Data,
+		MaxStack:  jsonnetMaxStack,
+		MaxMemory: sourceMaxMemoryBytes,
+	}, importer)
+}
+
+func (e *sourceEvaluator) evaluateCUE(ctx context.Context, info *repository.FileInfo, src provisioning.SourceDirectory, tree []repository.FileTreeEntry) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, sourceEvaluationTimeout)
+	defer cancel()
+
+	// The library files are read up front, as CUE evaluates them with the source file
+	size := len(info.Data)
+	libraries := map[string][]byte{}
+	for _, lib := range libraryFiles(src, tree, ".cue") {
+		libInfo, err := readSourceFile(ctx, e.reader, lib, info.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("read library %s: %w", lib, err)
+		}
+		if size += len(libInfo.Data); size > sourceMaxInputBytes {
+			return nil, fmt.Errorf("read library %s: %w: the source and its libraries are over %d bytes", lib, ErrSourceTooLarge, sourceMaxInputBytes)
+		}
+		libraries[lib] = libInfo.Data
+	}
+
+	return runSourceEvaluation(ctx, sourceEvaluationRequest{
+		Format:    SourceFormatCUE,
+		Path:      info.Path,
+		Data:      info.Data,
+		Libraries: libraries,
+		MaxMemory: sourceMaxMemoryBytes,
+	}, nil)
+}
+
+// tree returns the repository tree at ref, which lists the library files
+func (e *sourceEvaluator) tree(ctx context.Context, ref string) ([]repository.FileTreeEntry, error) {
+	e.mu.Lock()
+	tree, ok := e.trees[ref]
+	e.mu.Unlock()
+	if ok {
+		return tree, nil
+	}
+
+	tree, err := e.reader.ReadTree(ctx, ref)
+	if err != nil && ref != "" && errors.Is(err, repository.ErrRefNotFound) {
+		tree, err = e.reader.ReadTree(ctx, "")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read tree for libraries: %w", err)
+	}
+
+	e.mu.Lock()
+	e.trees[ref] = tree
+	e.mu.Unlock()
+	return tree, nil
+}
+
+// mergeCUEFiles combines the files into one, as if they were files of the same package
+func mergeCUEFiles(files []*ast.File) *ast.File {
+	merged := &ast.File{Filename: files[0].Filename}
+	seen := map[string]bool{}
+	var imports, decls []ast.Decl
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.Package:
+			case *ast.ImportDecl:
+				for _, spec := range d.Specs {
+					key := spec.Path.Value
+					if spec.Name != nil {
+						key = spec.Name.Name + " " + key
+					}
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+					merged.Imports = append(merged.Imports, spec)
+					imports = append(imports, &ast.ImportDecl{Specs: []*ast.ImportSpec{spec}})
+				}
+			default:
+				decls = append(decls, decl)
+			}
+		}
+	}
+	merged.Decls = append(imports, decls...)
+	return merged
+}
+
+// libraryFiles lists the files with the extension in the library directories
+func libraryFiles(src provisioning.SourceDirectory, tree []repository.FileTreeEntry, ext string) []string {
+	var files []string
+	for _, entry := range tree {
+		if !entry.Blob || strings.ToLower(path.Ext(entry.Path)) != ext {
+			continue
+		}
+		for _, lib := range src.Libraries {
+			if safepath.InDir(entry.Path, lib) {
+				files = append(files, entry.Path)
+				break
+			}
+		}
+	}
+	return files
+}
+
+// jsonnetImporter resolves Jsonnet imports from the repository only.  It stops
+// the evaluation once its context is done or the imports are over the size limit.
+type jsonnetImporter struct {
+	ctx       context.Context
+	reader    repository.Reader
+	ref       string
+	libraries []string
+	cache     map[string]jsonnet.Contents
+	size      int
+}
+
+// count adds the bytes read to the size of the evaluation
+func (i *jsonnetImporter) count(n int) error {
+	i.size += n
+	if i.size > sourceMaxInputBytes {
+		return fmt.Errorf("%w: the source and its imports are over %d bytes", ErrSourceTooLarge, sourceMaxInputBytes)
+	}
+	return nil
+}
+
+func (i *jsonnetImporter) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	if err := i.ctx.Err(); err != nil {
+		return jsonnet.Contents{}, "", fmt.Errorf("import %q: %w", importedPath, err)
+	}
+	if path.IsAbs(importedPath) {
+		return jsonnet.Contents{}, "", fmt.Errorf("import %q: absolute imports are not supported", importedPath)
+	}
+
+	candidates := []string{path.Join(path.Dir(importedFrom), importedPath)}
+	for _, lib := range i.libraries {
+		candidates = append(candidates, path.Join(lib, importedPath))
+	}
+
+	for _, candidate := range candidates {
+		// Imports can not leave the repository
+		if safepath.IsSafe(candidate) != nil {
+			continue
+		}
+		if contents, ok := i.cache[candidate]; ok {
+			return contents, candidate, nil
+		}
+
+		info, err := readSourceFile(i.ctx, i.reader, candidate, i.ref)
+		if err != nil {
+			if errors.Is(err, repository.ErrFileNotFound) {
+				continue
+			}
+			return jsonnet.Contents{}, "", fmt.Errorf("import %q: %w", importedPath, err)
+		}
+		if err := i.count(len(info.Data)); err != nil {
+			return jsonnet.Contents{}, "", fmt.Errorf("import %q: %w", importedPath, err)
+		}
+		contents := jsonnet.MakeContents(string(info.Data))
+		i.cache[candidate] = contents
+		return contents, candidate, nil
+	}
+	return jsonnet.Contents{}, "", fmt.Errorf("import %q: not found in the repository", importedPath)
+}
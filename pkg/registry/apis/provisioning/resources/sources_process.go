@@ -0,0 +1,311 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuecontext"
+	cueparser "cuelang.org/go/cue/parser"
+	"github.com/google/go-jsonnet"
+)
+
+// Jsonnet and CUE evaluations can not be interrupted, and their memory use can not be
+// bounded, so source files are evaluated in a child process: the Grafana binary run again
+// with sourceEvaluatorEnv set.  The child exits once its memory is over the limit, and is
+// killed at the deadline of its evaluation, so an evaluation never outlives its slot.
+//
+// The child only reads the files sent by the parent.  It asks the parent for the Jsonnet
+// imports, which the parent resolves from the repository, and sends back the JSON the file
+// evaluates to, one JSON message per line.
+
+const (
+	// sourceEvaluatorEnv is set in the environment of the child process
+	sourceEvaluatorEnv = "GF_PROVISIONING_SOURCE_EVALUATOR"
+
+	// sourceMaxMemoryBytes bounds the heap of the child process
+	sourceMaxMemoryBytes = 512 << 20
+
+	// sourceOutOfMemoryExitCode is the exit code of a child process over its memory limit
+	sourceOutOfMemoryExitCode = 3
+
+	// sourceMaxStderrBytes bounds the output of a crashed child process kept for its error
+	sourceMaxStderrBytes = 4 << 10
+)
+
+func init() {
+	// The child process evaluates its source file before Grafana starts
+	if os.Getenv(sourceEvaluatorEnv) != "" {
+		os.Exit(runSourceEvaluator(os.Stdin, os.Stdout))
+	}
+}
+
+// sourceEvaluationRequest is the source file sent to the child process
+type sourceEvaluationRequest struct {
+	Format    SourceFormat      `json:"format"`
+	Path      string            `json:"path"`
+	Data      []byte            `json:"data"`
+	Libraries map[string][]byte `json:"libraries,omitempty"`
+	MaxStack  int               `json:"maxStack,omitempty"`
+	MaxMemory uint64            `json:"maxMemory"`
+}
+
+// sourceEvaluatorMessage is sent by the child process: a Jsonnet import to resolve, or the
+// result of the evaluation.  Outputs over sourceMaxOutputBytes are left out, with their size.
+type sourceEvaluatorMessage struct {
+	ImportFrom string `json:"importFrom,omitempty"`
+	ImportPath string `json:"importPath,omitempty"`
+
+	Done       bool   `json:"done,omitempty"`
+	Output     []byte `json:"output,omitempty"`
+	OutputSize int    `json:"outputSize,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// sourceImportReply is the reply of the parent to an import
+type sourceImportReply struct {
+	FoundAt string `json:"foundAt,omitempty"`
+	Data    string `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// runSourceEvaluation evaluates a source file in a child process holding a slot.  The slot
+// is released once the process has exited, after it returned its result or was killed at
+// the deadline of the context.  The imports of Jsonnet files are resolved with the importer.
+func runSourceEvaluation(ctx context.Context, req sourceEvaluationRequest, importer *jsonnetImporter) ([]byte, error) {
+	select {
+	case sourceEvaluationSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("wait for an evaluation slot: %w", ctx.Err())
+	}
+	defer func() { <-sourceEvaluationSlots }()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("find the evaluator executable: %w", err)
+	}
+	// The child does not inherit the environment, which can hold secrets
+	cmd := exec.CommandContext(ctx, executable)
+	cmd.Env = []string{sourceEvaluatorEnv + "=1"}
+	cmd.WaitDelay = time.Second
+	stderr := &cappedWriter{limit: sourceMaxStderrBytes}
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start the evaluator: %w", err)
+	}
+
+	result, exchangeErr := exchangeSourceEvaluation(req, importer, stdin, stdout)
+	_ = stdin.Close()
+	waitErr := cmd.Wait()
+
+	switch {
+	case result != nil:
+	case ctx.Err() != nil:
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("evaluation did not finish within %s: %w", sourceEvaluationTimeout, ctx.Err())
+		}
+		return nil, ctx.Err()
+	case cmd.ProcessState != nil && cmd.ProcessState.ExitCode() == sourceOutOfMemoryExitCode:
+		return nil, fmt.Errorf("%w: evaluation used over %d bytes of memory", ErrSourceTooLarge, req.MaxMemory)
+	case waitErr != nil:
+		return nil, fmt.Errorf("evaluator failed: %w: %s", waitErr, strings.TrimSpace(stderr.String()))
+	default:
+		return nil, fmt.Errorf("evaluator failed: %w", exchangeErr)
+	}
+
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+	if result.OutputSize > sourceMaxOutputBytes {
+		return nil, fmt.Errorf("%w: evaluated to %d bytes, over %d", ErrSourceTooLarge, result.OutputSize, sourceMaxOutputBytes)
+	}
+	return result.Output, nil
+}
+
+// exchangeSourceEvaluation sends the request to the child process and resolves its imports
+// until it sends its result
+func exchangeSourceEvaluation(req sourceEvaluationRequest, importer *jsonnetImporter, stdin io.Writer, stdout io.Reader) (*sourceEvaluatorMessage, error) {
+	enc := json.NewEncoder(stdin)
+	// The output is base64 encoded, and the imports are small
+	dec := json.NewDecoder(io.LimitReader(stdout, 2*sourceMaxOutputBytes))
+	if err := enc.Encode(req); err != nil {
+		return nil, fmt.Errorf("send the source: %w", err)
+	}
+
+	for {
+		var msg sourceEvaluatorMessage
+		if err := dec.Decode(&msg); err != nil {
+			return nil, fmt.Errorf("read the result: %w", err)
+		}
+		if msg.Done {
+			return &msg, nil
+		}
+
+		var reply sourceImportReply
+		if importer == nil {
+			reply.Error = fmt.Sprintf("import %q: imports are not supported", msg.ImportPath)
+		} else if contents, foundAt, err := importer.Import(msg.ImportFrom, msg.ImportPath); err != nil {
+			reply.Error = err.Error()
+		} else {
+			reply.FoundAt = foundAt
+			reply.Data = contents.String()
+		}
+		if err := enc.Encode(reply); err != nil {
+			return nil, fmt.Errorf("send an import: %w", err)
+		}
+	}
+}
+
+// runSourceEvaluator evaluates the source file read from in, in the child process, and
+// returns the exit code of the process
+func runSourceEvaluator(in io.Reader, out io.Writer) int {
+	dec := json.NewDecoder(in)
+	enc := json.NewEncoder(out)
+
+	var req sourceEvaluationRequest
+	if err := dec.Decode(&req); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "read the source: %v\n", err)
+		return 1
+	}
+	watchSourceEvaluatorMemory(req.MaxMemory)
+
+	msg := sourceEvaluatorMessage{Done: true}
+	output, err := evaluateSourceRequest(req, &processImporter{dec: dec, enc: enc, cache: map[string]jsonnet.Contents{}})
+	switch {
+	case err != nil:
+		msg.Error = err.Error()
+	case len(output) > sourceMaxOutputBytes:
+		msg.OutputSize = len(output)
+	default:
+		msg.Output = output
+	}
+	if err := enc.Encode(msg); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "send the result: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// watchSourceEvaluatorMemory makes the garbage collector keep the heap under the limit, and
+// exits the process once it can not
+func watchSourceEvaluatorMemory(limit uint64) {
+	if limit == 0 {
+		return
+	}
+	debug.SetMemoryLimit(int64(limit))
+	go func() {
+		var stats runtime.MemStats
+		for range time.Tick(10 * time.Millisecond) {
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc > limit {
+				os.Exit(sourceOutOfMemoryExitCode)
+			}
+		}
+	}()
+}
+
+// evaluateSourceRequest evaluates the source file of the request.  Panics fail the evaluation.
+func evaluateSourceRequest(req sourceEvaluationRequest, importer jsonnet.Importer) (out []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("evaluation panicked: %v", r)
+		}
+	}()
+
+	switch req.Format {
+	case SourceFormatJsonnet:
+		vm := jsonnet.MakeVM()
+		vm.MaxStack = req.MaxStack
+		vm.Importer(importer)
+		out, err := vm.EvaluateAnonymousSnippet(req.Path, string(req.Data))
+		return []byte(out), err
+
+	case SourceFormatCUE:
+		source, err := cueparser.ParseFile(req.Path, req.Data)
+		if err != nil {
+			return nil, err
+		}
+		files := []*ast.File{source}
+		for _, lib := range slices.Sorted(maps.Keys(req.Libraries)) {
+			f, err := cueparser.ParseFile(lib, req.Libraries[lib])
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+		}
+
+		// The files are built without the loader, so imports that are not part of
+		// the CUE standard library fail instead of being fetched from a registry
+		value := cuecontext.New().BuildFile(mergeCUEFiles(files), cue.Filename(req.Path))
+		if err := value.Validate(cue.Concrete(true)); err != nil {
+			return nil, err
+		}
+		return value.MarshalJSON()
+	}
+	return nil, fmt.Errorf("unsupported source format %q", req.Format)
+}
+
+// processImporter resolves the Jsonnet imports of the child process with the parent
+type processImporter struct {
+	dec   *json.Decoder
+	enc   *json.Encoder
+	cache map[string]jsonnet.Contents
+}
+
+func (i *processImporter) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	if err := i.enc.Encode(sourceEvaluatorMessage{ImportFrom: importedFrom, ImportPath: importedPath}); err != nil {
+		return jsonnet.Contents{}, "", fmt.Errorf("import %q: %w", importedPath, err)
+	}
+	var reply sourceImportReply
+	if err := i.dec.Decode(&reply); err != nil {
+		return jsonnet.Contents{}, "", fmt.Errorf("import %q: %w", importedPath, err)
+	}
+	if reply.Error != "" {
+		return jsonnet.Contents{}, "", errors.New(reply.Error)
+	}
+
+	// The same file is imported with the same contents, so Jsonnet evaluates it once
+	contents, ok := i.cache[reply.FoundAt]
+	if !ok {
+		contents = jsonnet.MakeContents(reply.Data)
+		i.cache[reply.FoundAt] = contents
+	}
+	return contents, reply.FoundAt, nil
+}
+
+// cappedWriter keeps the first bytes written to it, up to its limit
+type cappedWriter struct {
+	limit int
+	buf   strings.Builder
+}
+
+func (w *cappedWriter) Write(p []byte) (int, error) {
+	if room := w.limit - w.buf.Len(); room > 0 {
+		w.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (w *cappedWriter) String() string {
+	return w.buf.String()
+}
@@ -0,0 +1,231 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+)
+
+// newSourceReader serves the files, with their content as hash
+func newSourceReader(t *testing.T, files map[string]string) *repository.MockReader {
+	reader := repository.NewMockReader(t)
+	reader.EXPECT().Read(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, p, ref string) (*repository.FileInfo, error) {
+			data, ok := files[p]
+			if !ok {
+				return nil, repository.ErrFileNotFound
+			}
+			return &repository.FileInfo{Path: p, Ref: ref, Data: []byte(data), Hash: data}, nil
+		}).Maybe()
+	reader.EXPECT().ReadTree(mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, _ string) ([]repository.FileTreeEntry, error) {
+			tree := make([]repository.FileTreeEntry, 0, len(files))
+			for p, data := range files {
+				tree = append(tree, repository.FileTreeEntry{Path: p, Hash: data, Blob: true})
+			}
+			return tree, nil
+		}).Maybe()
+	return reader
+}
+
+func TestSourceEvaluator(t *testing.T) {
+	ctx := context.Background()
+	sources := []provisioning.SourceDirectory{
+		{Path: "dashboards", Format: provisioning.SourceFormatJsonnet, Libraries: []string{"vendor"}},
+		{Path: "schemas/", Format: provisioning.SourceFormatCUE, Libraries: []string{"cuelib"}},
+		{Path: "large", Format: provisioning.SourceFormatJsonnet},
+	}
+	reader := newSourceReader(t, map[string]string{
+		"dashboards/util.libsonnet":  `{ title(name):: "Team " + name }`,
+		"vendor/grafonnet.libsonnet": `{ dashboard(title):: { uid: std.asciiLower(title), title: title, panels: [], schemaVersion: 41, tags: [] } }`,
+		"cuelib/playlist.cue":        `#Interval: "5m" | "10m"`,
+		"large/big.libsonnet":        `"` + strings.Repeat("a", sourceMaxInputBytes) + `"`,
+	})
+	evaluator := newSourceEvaluator(reader, sources)
+
+	evaluate := func(t *testing.T, p, data string) map[string]any {
+		t.Helper()
+		out, err := evaluator.Evaluate(ctx, &repository.FileInfo{Path: p, Data: []byte(data), Hash: "abc"})
+		require.NoError(t, err)
+		require.Equal(t, p, out.Path)
+		require.NotEqual(t, "abc", out.Hash, "the hash covers the libraries of the source")
+
+		obj := map[string]any{}
+		require.NoError(t, json.Unmarshal(out.Data, &obj))
+		return obj
+	}
+
+	t.Run("jsonnet imports from the directory and libraries", func(t *testing.T) {
+		obj := evaluate(t, "dashboards/team/a.jsonnet", `
+			local g = import 'grafonnet.libsonnet';
+			local util = import '../util.libsonnet';
+			g.dashboard(util.title('A'))`)
+		require.Equal(t, "Team A", obj["title"])
+		require.Equal(t, "team a", obj["uid"])
+	})
+
+	t.Run("evaluated classic dashboards are parsed like any other file", func(t *testing.T) {
+		info, err := evaluator.Evaluate(ctx, &repository.FileInfo{
+			Path: "dashboards/b.jsonnet",
+			Data: []byte(`(import 'grafonnet.libsonnet').dashboard('b')`),
+		})
+		require.NoError(t, err)
+		obj, gvk, _, err := ParseFileResource(ctx, info)
+		require.NoError(t, err)
+		require.Equal(t, "Dashboard", gvk.Kind)
+		require.Equal(t, "b", obj.GetName())
+	})
+
+	t.Run("cue is evaluated with the library definitions", func(t *testing.T) {
+		obj := evaluate(t, "schemas/p.cue", `
+			apiVersion: "playlist.grafana.app/v0alpha1"
+			kind:       "Playlist"
+			metadata: name: "p"
+			spec: {title: "P", interval: #Interval & "5m"}`)
+		require.Equal(t, map[string]any{"title": "P", "interval": "5m"}, obj["spec"])
+	})
+
+	t.Run("evaluation errors are reported for the file", func(t *testing.T) {
+		_, err := evaluator.Evaluate(ctx, &repository.FileInfo{Path: "dashboards/c.jsonnet", Data: []byte(`import 'missing.libsonnet'`)})
+		var evalErr *SourceEvaluationError
+		require.ErrorAs(t, err, &evalErr)
+		require.Equal(t, "dashboards/c.jsonnet", evalErr.Path)
+		require.ErrorContains(t, err, "not found in the repository")
+
+		_, err = evaluator.Evaluate(ctx, &repository.FileInfo{Path: "schemas/q.cue", Data: []byte(`spec: interval: #Interval & "1h"`)})
+		require.ErrorAs(t, err, &evalErr)
+		require.Equal(t, SourceFormatCUE, evalErr.Format)
+	})
+
+	t.Run("imports can not leave the repository", func(t *testing.T) {
+		_, err := evaluator.Evaluate(ctx, &repository.FileInfo{Path: "dashboards/d.jsonnet", Data: []byte(`import '../../../etc/passwd'`)})
+		require.ErrorContains(t, err, "not found in the repository")
+	})
+
+	t.Run("imports are bounded in size", func(t *testing.T) {
+		_, err := evaluator.Evaluate(ctx, &repository.FileInfo{Path: "large/e.jsonnet", Data: []byte(`{ title: import 'big.libsonnet' }`)})
+		require.ErrorContains(t, err, ErrSourceTooLarge.Error())
+	})
+
+	t.Run("source files must be in a source directory of the same format", func(t *testing.T) {
+		_, err := evaluator.Evaluate(ctx, &repository.FileInfo{Path: "other/e.jsonnet", Data: []byte(`{}`)})
+		require.ErrorIs(t, err, ErrNotInSourceDirectory)
+
+		_, err = evaluator.Evaluate(ctx, &repository.FileInfo{Path: "dashboards/f.cue", Data: []byte(`a: 1`)})
+		var validationErr *ResourceValidationError
+		require.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("other files are returned unchanged", func(t *testing.T) {
+		info := &repository.FileInfo{Path: "dashboards/g.json", Data: []byte(`{}`)}
+		out, err := evaluator.Evaluate(ctx, info)
+		require.NoError(t, err)
+		require.Same(t, info, out)
+	})
+}
+
+func TestRunSourceEvaluation(t *testing.T) {
+	jsonnetRequest := func(source string) sourceEvaluationRequest {
+		return sourceEvaluationRequest{
+			Format:    SourceFormatJsonnet,
+			Path:      "a.jsonnet",
+			Data:      []byte(source),
+			MaxStack:  jsonnetMaxStack,
+			MaxMemory: 64 << 20,
+		}
+	}
+
+	t.Run("sources are evaluated in a child process", func(t *testing.T) {
+		out, err := runSourceEvaluation(context.Background(), jsonnetRequest(`{ a: 1 + 1 }`), nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"a": 2}`, string(out))
+
+		_, err = runSourceEvaluation(context.Background(), jsonnetRequest(`import 'a.libsonnet'`), nil)
+		require.ErrorContains(t, err, "imports are not supported")
+	})
+
+	t.Run("evaluations are stopped at the deadline and release their slot", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		_, err := runSourceEvaluation(ctx, jsonnetRequest(`
+			local loop(n) = if n == 0 then 0 else loop(n - 1) tailstrict;
+			loop(1e12)`), nil)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Empty(t, sourceEvaluationSlots, "the process exited before the slot was released")
+	})
+
+	t.Run("evaluations are bounded in memory", func(t *testing.T) {
+		_, err := runSourceEvaluation(context.Background(), jsonnetRequest(`
+			std.length(std.join('', std.makeArray(1e8, function(i) std.toString(i))))`), nil)
+		require.ErrorIs(t, err, ErrSourceTooLarge)
+		require.ErrorContains(t, err, "bytes of memory")
+	})
+
+	t.Run("outputs are bounded in size", func(t *testing.T) {
+		req := jsonnetRequest(`
+			local double(s, n) = if n == 0 then s else double(s + s, n - 1) tailstrict;
+			double('a', 24)`)
+		req.MaxMemory = sourceMaxMemoryBytes
+		_, err := runSourceEvaluation(context.Background(), req, nil)
+		require.ErrorIs(t, err, ErrSourceTooLarge)
+		require.ErrorContains(t, err, "evaluated to")
+	})
+}
+
+func TestSourceDirectories(t *testing.T) {
+	dirs := NewSourceDirectories([]provisioning.SourceDirectory{
+		{Path: "dashboards", Format: provisioning.SourceFormatJsonnet, Libraries: []string{"vendor/"}},
+		{Path: "dashboards/cue/", Format: provisioning.SourceFormatCUE, Libraries: []string{"cuelib"}},
+	})
+
+	t.Run("source files outside of the source directories are ignored", func(t *testing.T) {
+		require.False(t, dirs.Ignored("dashboards/a.jsonnet"))
+		require.False(t, dirs.Ignored("dashboards/nested/b.cue"))
+		require.False(t, dirs.Ignored("other/c.json"))
+		require.True(t, dirs.Ignored("vendor/grafonnet/d.jsonnet"))
+
+		src, ok := dirs.Find("dashboards/cue/nested/e.cue")
+		require.True(t, ok)
+		require.Equal(t, "dashboards/cue/", src.Path)
+		require.Equal(t, []string{"cuelib/"}, src.Libraries)
+	})
+
+	t.Run("libraries are dependencies of the directories using them", func(t *testing.T) {
+		require.Equal(t, []string{"dashboards/"}, dirs.Dependents("vendor/grafonnet/main.libsonnet"))
+		require.Equal(t, []string{"dashboards/"}, dirs.Dependents("dashboards/util.libsonnet"))
+		require.Equal(t, []string{"dashboards/cue/"}, dirs.Dependents("cuelib/defs.cue"))
+		require.Empty(t, dirs.Dependents("dashboards/a.jsonnet"))
+		require.Empty(t, dirs.Dependents("other/util.libsonnet"))
+	})
+
+	t.Run("resolved source files change with their libraries", func(t *testing.T) {
+		tree := []repository.FileTreeEntry{
+			{Path: "dashboards/", Blob: false},
+			{Path: "dashboards/a.jsonnet", Hash: "a", Blob: true},
+			{Path: "dashboards/cue/b.cue", Hash: "b", Blob: true},
+			{Path: "dashboards/home.json", Hash: "home", Blob: true},
+			{Path: "vendor/grafonnet.libsonnet", Hash: "v1", Blob: true},
+			{Path: "vendor/grafonnet/d.jsonnet", Hash: "d", Blob: true},
+		}
+		resolved := dirs.Resolve(tree)
+		require.Len(t, resolved, 5, "source files outside of the source directories are left out")
+		require.Equal(t, tree[0], resolved[0])
+		require.NotEqual(t, "a", resolved[1].Hash)
+		require.Equal(t, "b", resolved[2].Hash, "sources without dependencies keep their hash")
+		require.Equal(t, "home", resolved[3].Hash)
+
+		tree[4].Hash = "v2"
+		require.NotEqual(t, resolved[1].Hash, dirs.Resolve(tree)[1].Hash)
+	})
+
+	require.NoError(t, IsPathSupported("dashboards/a.jsonnet"))
+}
@@ -0,0 +1,336 @@
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+)
+
+// Templates instantiate the same resource once per environment. A directory, and
+// everything below it, is marked as a template directory by a _template.json (or
+// _template.yaml) file holding the resource to instantiate. Each values file below
+// the directory, named <instance>.values.yaml (or .yml, .json), creates one resource
+// from the template:
+//
+//	dashboards/service/_template.json
+//	dashboards/service/dev.values.yaml
+//	dashboards/service/prod/prod.values.yaml
+//
+// String values of the template can hold ${values.<key>} placeholders, where the key
+// is a dot separated path in the values file. A string that is only a placeholder is
+// replaced with the value as is, so numbers, lists and objects keep their type.
+//
+// The name of each resource is derived from the template name and the path of the
+// values file relative to the template directory, so it stays the same across syncs.
+// The resource is owned by the repository and placed in the folder of its values file,
+// which is its source path. Changes are detected with a hash of both the values file
+// and the template, so a template change updates every instance.
+var templateFileNames = []string{"_template.json", "_template.yaml", "_template.yml"}
+
+var templateValuesSuffixes = []string{".values.json", ".values.yaml", ".values.yml"}
+
+// templatePlaceholder matches ${values.<key>} in template strings
+var templatePlaceholder = regexp.MustCompile(`\$\{values\.([A-Za-z0-9_.-]+)\}`)
+
+var ErrGeneratedFromTemplate = errors.New("resource is instantiated from a template and can only be changed in the repository")
+
+// IsTemplateFile reports whether the file marks its directory as a template directory
+func IsTemplateFile(filePath string) bool {
+	if safepath.IsDir(filePath) {
+		return false
+	}
+	base := safepath.Base(filePath)
+	for _, name := range templateFileNames {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTemplateValuesFile reports whether the file is named like a values file.
+// Values files are only instantiated inside a template directory.
+func IsTemplateValuesFile(filePath string) bool {
+	return templateInstanceName(filePath) != ""
+}
+
+// templateInstanceName returns the name of the values file without its suffix
+func templateInstanceName(filePath string) string {
+	if safepath.IsDir(filePath) {
+		return ""
+	}
+	lower := strings.ToLower(filePath)
+	for _, suffix := range templateValuesSuffixes {
+		if strings.HasSuffix(lower, suffix) && len(filePath) > len(suffix) {
+			return filePath[:len(filePath)-len(suffix)]
+		}
+	}
+	return ""
+}
+
+// TemplateInstanceHash combines the hashes of a values file and its template
+func TemplateInstanceHash(valuesHash, templateHash string) string {
+	sum := sha256.Sum256([]byte(valuesHash + ":" + templateHash))
+	return hex.EncodeToString(sum[:20])
+}
+
+// TemplateDirectories maps the template directories found in a repository tree to their template file
+type TemplateDirectories map[string]repository.FileTreeEntry
+
+// NewTemplateDirectories collects the directories marked with a template file
+func NewTemplateDirectories(tree []repository.FileTreeEntry) TemplateDirectories {
+	dirs := TemplateDirectories{}
+	for _, entry := range tree {
+		if entry.Blob && IsTemplateFile(entry.Path) {
+			dirs[safepath.Dir(entry.Path)] = entry
+		}
+	}
+	return dirs
+}
+
+// Template returns the template file of the closest template directory containing the values file
+func (t TemplateDirectories) Template(filePath string) (repository.FileTreeEntry, bool) {
+	if len(t) == 0 || !IsTemplateValuesFile(filePath) {
+		return repository.FileTreeEntry{}, false
+	}
+	for dir := safepath.Dir(filePath); ; dir = safepath.Dir(dir) {
+		if entry, ok := t[dir]; ok {
+			return entry, true
+		}
+		if dir == "" {
+			return repository.FileTreeEntry{}, false
+		}
+	}
+}
+
+// Hash returns the hash recorded on the resource of the file: the combined hash for
+// values files in a template directory, and the file hash for every other file.
+func (t TemplateDirectories) Hash(entry repository.FileTreeEntry) string {
+	if tmpl, ok := t.Template(entry.Path); ok {
+		return TemplateInstanceHash(entry.Hash, tmpl.Hash)
+	}
+	return entry.Hash
+}
+
+// FindTemplate returns the template file of the closest template directory containing
+// the file, or nil when the file is not in a template directory.
+func FindTemplate(ctx context.Context, reader repository.Reader, filePath, ref string) (*repository.FileInfo, error) {
+	for dir := safepath.Dir(filePath); ; dir = safepath.Dir(dir) {
+		for _, name := range templateFileNames {
+			templatePath := safepath.Join(dir, name)
+			info, err := readSourceFile(ctx, reader, templatePath, ref)
+			if err == nil {
+				info.Path = templatePath
+				return info, nil
+			}
+			if !errors.Is(err, repository.ErrFileNotFound) {
+				return nil, err
+			}
+		}
+		if dir == "" {
+			return nil, nil
+		}
+	}
+}
+
+// templateRenderer instantiates the values files read by a parser.  The templates are
+// cached since a sync renders many values files at the same ref.
+type templateRenderer struct {
+	reader   repository.Reader
+	repoName string
+
+	mu        sync.Mutex
+	templates map[string]*repository.FileInfo
+}
+
+func newTemplateRenderer(reader repository.Reader, repoName string) *templateRenderer {
+	return &templateRenderer{
+		reader:    reader,
+		repoName:  repoName,
+		templates: map[string]*repository.FileInfo{},
+	}
+}
+
+// Render returns a copy of the file info holding the instantiated template, which can be
+// read with ParseFileResource, along with the path of the template.  Files that are not
+// values files in a template directory are returned as is, with an empty template path.
+func (r *templateRenderer) Render(ctx context.Context, info *repository.FileInfo) (*repository.FileInfo, string, error) {
+	if r == nil || r.reader == nil || !IsTemplateValuesFile(info.Path) {
+		return info, "", nil
+	}
+
+	tmpl, err := r.template(ctx, info.Path, info.Ref)
+	if err != nil {
+		return nil, "", err
+	}
+	if tmpl == nil {
+		return info, "", nil
+	}
+
+	out, err := r.render(tmpl, info)
+	if err != nil {
+		return nil, tmpl.Path, NewSourceEvaluationError(info.Path, SourceFormatTemplate, err)
+	}
+
+	rendered := *info
+	rendered.Data = out
+	rendered.Hash = TemplateInstanceHash(info.Hash, tmpl.Hash)
+	return &rendered, tmpl.Path, nil
+}
+
+func (r *templateRenderer) template(ctx context.Context, filePath, ref string) (*repository.FileInfo, error) {
+	key := ref + ":" + safepath.Dir(filePath)
+
+	r.mu.Lock()
+	tmpl, ok := r.templates[key]
+	r.mu.Unlock()
+	if ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := FindTemplate(ctx, r.reader, filePath, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.templates[key] = tmpl
+	r.mu.Unlock()
+	return tmpl, nil
+}
+
+func (r *templateRenderer) render(tmpl, info *repository.FileInfo) ([]byte, error) {
+	values := map[string]any{}
+	if err := yaml.Unmarshal(info.Data, &values); err != nil {
+		return nil, fmt.Errorf("read values: %w", err)
+	}
+
+	obj := map[string]any{}
+	if err := yaml.Unmarshal(tmpl.Data, &obj); err != nil {
+		return nil, fmt.Errorf("read template %s: %w", tmpl.Path, err)
+	}
+
+	rendered, err := renderTemplateValue(obj, values)
+	if err != nil {
+		return nil, err
+	}
+	obj, _ = rendered.(map[string]any)
+
+	name := r.instanceName(tmpl, info.Path, obj)
+	if _, ok := obj["kind"]; ok {
+		metadata, _ := obj["metadata"].(map[string]any)
+		if metadata == nil {
+			metadata = map[string]any{}
+			obj["metadata"] = metadata
+		}
+		delete(metadata, "generateName")
+		metadata["name"] = name
+	} else {
+		// Classic dashboards are named after their uid
+		obj["uid"] = name
+	}
+
+	return json.Marshal(obj)
+}
+
+// instanceName derives the name of the resource from the template name and the
+// path of the values file relative to the template directory
+func (r *templateRenderer) instanceName(tmpl *repository.FileInfo, valuesPath string, obj map[string]any) string {
+	base := ""
+	if metadata, ok := obj["metadata"].(map[string]any); ok {
+		base, _ = metadata["name"].(string)
+	}
+	if base == "" {
+		base, _ = obj["uid"].(string)
+	}
+	if base == "" {
+		base = safepath.Base(strings.TrimSuffix(safepath.Dir(tmpl.Path), "/"))
+	}
+	if base == "" {
+		base = "template"
+	}
+
+	instance := strings.TrimPrefix(templateInstanceName(valuesPath), safepath.Dir(tmpl.Path))
+	name := base + "-" + strings.ReplaceAll(strings.Trim(instance, "/"), "/", "-")
+	if clean := sanitiseKubeName(name); clean == name && len(name) <= 40 {
+		return name
+	}
+	return appendHashSuffix(valuesPath, r.repoName)(sanitiseKubeName(name))
+}
+
+// renderTemplateValue replaces the placeholders in the string values of v
+func renderTemplateValue(v any, values map[string]any) (any, error) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			rendered, err := renderTemplateValue(item, values)
+			if err != nil {
+				return nil, err
+			}
+			val[k] = rendered
+		}
+		return val, nil
+	case []any:
+		for i, item := range val {
+			rendered, err := renderTemplateValue(item, values)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = rendered
+		}
+		return val, nil
+	case string:
+		// A single placeholder keeps the type of the value
+		if m := templatePlaceholder.FindStringSubmatchIndex(val); m != nil && m[0] == 0 && m[1] == len(val) {
+			return lookupTemplateValue(values, val[m[2]:m[3]])
+		}
+
+		var lookupErr error
+		out := templatePlaceholder.ReplaceAllStringFunc(val, func(match string) string {
+			key := templatePlaceholder.FindStringSubmatch(match)[1]
+			value, err := lookupTemplateValue(values, key)
+			if err != nil {
+				lookupErr = err
+				return match
+			}
+			if s, ok := value.(string); ok {
+				return s
+			}
+			b, err := json.Marshal(value)
+			if err != nil {
+				lookupErr = fmt.Errorf("value %q: %w", key, err)
+				return match
+			}
+			return string(b)
+		})
+		return out, lookupErr
+	default:
+		return v, nil
+	}
+}
+
+func lookupTemplateValue(values map[string]any, key string) (any, error) {
+	var current any = values
+	for _, part := range strings.Split(key, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("missing value %q", key)
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil, fmt.Errorf("missing value %q", key)
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,140 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+)
+
+func TestTemplateRenderer(t *testing.T) {
+	ctx := context.Background()
+	reader := newSourceReader(t, map[string]string{
+		"dashboards/service/_template.json": `{
+			"apiVersion": "dashboard.grafana.app/v1",
+			"kind": "Dashboard",
+			"metadata": {"name": "service"},
+			"spec": {
+				"title": "Service (${values.env})",
+				"panels": [{"datasource": {"uid": "${values.datasource}"}, "thresholds": "${values.thresholds}"}]
+			}
+		}`,
+		"classic/_template.yaml": `{"uid": "latency", "title": "Latency ${values.env}", "panels": [], "schemaVersion": 41}`,
+	})
+	renderer := newTemplateRenderer(reader, "repo")
+
+	render := func(t *testing.T, p, values string) (map[string]any, string) {
+		t.Helper()
+		out, tmpl, err := renderer.Render(ctx, &repository.FileInfo{Path: p, Data: []byte(values), Hash: "abc"})
+		require.NoError(t, err)
+		require.Equal(t, p, out.Path)
+		require.NotEqual(t, "abc", out.Hash, "the hash includes the template")
+
+		obj := map[string]any{}
+		require.NoError(t, json.Unmarshal(out.Data, &obj))
+		return obj, tmpl
+	}
+
+	t.Run("values are substituted with their type", func(t *testing.T) {
+		obj, tmpl := render(t, "dashboards/service/prod.values.yaml", `
+env: prod
+datasource: prom-prod
+thresholds: [80, 95]`)
+		require.Equal(t, "dashboards/service/_template.json", tmpl)
+		require.Equal(t, map[string]any{"name": "service-prod"}, obj["metadata"])
+
+		spec := obj["spec"].(map[string]any)
+		require.Equal(t, "Service (prod)", spec["title"])
+		panel := spec["panels"].([]any)[0].(map[string]any)
+		require.Equal(t, map[string]any{"uid": "prom-prod"}, panel["datasource"])
+		require.Equal(t, []any{float64(80), float64(95)}, panel["thresholds"])
+	})
+
+	t.Run("names include the path of nested values files", func(t *testing.T) {
+		obj, _ := render(t, "dashboards/service/eu/stage.values.json", `{"env": "stage", "datasource": "prom", "thresholds": []}`)
+		require.Equal(t, map[string]any{"name": "service-eu-stage"}, obj["metadata"])
+	})
+
+	t.Run("classic dashboards are named after their uid", func(t *testing.T) {
+		obj, _ := render(t, "classic/dev.values.yml", `env: dev`)
+		require.Equal(t, "latency-dev", obj["uid"])
+		require.Equal(t, "Latency dev", obj["title"])
+	})
+
+	t.Run("long names are hashed", func(t *testing.T) {
+		obj, _ := render(t, "classic/a-very-long-environment-name-for-a-dashboard.values.yaml", `env: long`)
+		uid := obj["uid"].(string)
+		require.LessOrEqual(t, len(uid), 40)
+
+		again, _ := render(t, "classic/a-very-long-environment-name-for-a-dashboard.values.yaml", `env: long`)
+		require.Equal(t, uid, again["uid"], "names are deterministic")
+	})
+
+	t.Run("missing values are reported for the values file", func(t *testing.T) {
+		_, _, err := renderer.Render(ctx, &repository.FileInfo{Path: "dashboards/service/qa.values.yaml", Data: []byte("env: qa\nthresholds: []")})
+		var evalErr *SourceEvaluationError
+		require.ErrorAs(t, err, &evalErr)
+		require.Equal(t, SourceFormatTemplate, evalErr.Format)
+		require.Equal(t, "dashboards/service/qa.values.yaml", evalErr.Path)
+		require.ErrorContains(t, err, `missing value "datasource"`)
+	})
+
+	t.Run("files outside of template directories are returned unchanged", func(t *testing.T) {
+		info := &repository.FileInfo{Path: "other/helm.values.yaml", Data: []byte(`replicas: 1`)}
+		out, tmpl, err := renderer.Render(ctx, info)
+		require.NoError(t, err)
+		require.Same(t, info, out)
+		require.Empty(t, tmpl)
+
+		info = &repository.FileInfo{Path: "dashboards/service/home.json", Data: []byte(`{}`)}
+		out, _, err = renderer.Render(ctx, info)
+		require.NoError(t, err)
+		require.Same(t, info, out)
+	})
+}
+
+func TestTemplateDirectories(t *testing.T) {
+	dirs := NewTemplateDirectories([]repository.FileTreeEntry{
+		{Path: "dashboards/_template.json", Blob: true, Hash: "template"},
+		{Path: "dashboards/nested/_template.yaml", Blob: true, Hash: "nested"},
+		{Path: "dashboards/dev.values.yaml", Blob: true, Hash: "dev"},
+	})
+
+	tmpl, ok := dirs.Template("dashboards/dev.values.yaml")
+	require.True(t, ok)
+	require.Equal(t, "dashboards/_template.json", tmpl.Path)
+
+	tmpl, ok = dirs.Template("dashboards/nested/deeper/prod.values.json")
+	require.True(t, ok)
+	require.Equal(t, "dashboards/nested/_template.yaml", tmpl.Path)
+
+	_, ok = dirs.Template("dashboards/home.json")
+	require.False(t, ok)
+	_, ok = dirs.Template("other/dev.values.yaml")
+	require.False(t, ok)
+
+	require.Equal(t, TemplateInstanceHash("dev", "template"), dirs.Hash(repository.FileTreeEntry{Path: "dashboards/dev.values.yaml", Hash: "dev"}))
+	require.Equal(t, "home", dirs.Hash(repository.FileTreeEntry{Path: "dashboards/home.json", Hash: "home"}))
+
+	require.ErrorIs(t, IsPathSupported("dashboards/_template.json"), ErrTemplateFile)
+	require.True(t, IsRawFile("dashboards/_template.json"))
+	require.NoError(t, IsPathSupported("dashboards/dev.values.yaml"))
+}
+
+func TestToSaveBytes_Template(t *testing.T) {
+	parsed := &ParsedResource{
+		Info:     &repository.FileInfo{Path: "dashboards/dev.values.yaml"},
+		Template: "dashboards/_template.json",
+		Obj: &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "dashboard.grafana.app/v1",
+			"kind":       "Dashboard",
+			"metadata":   map[string]any{"name": "service-dev"},
+		}},
+	}
+	_, err := parsed.ToSaveBytes()
+	require.ErrorIs(t, err, ErrGeneratedFromTemplate)
+}
@@ -14,6 +14,7 @@ import (
 
 	dashboard "github.com/grafana/grafana/apps/dashboard/pkg/apis/dashboard/v1"
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/policy"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/infra/slugify"
@@ -38,6 +39,9 @@ type changeInfo struct {
 
 	// Requested image render, but it is not available
 	MissingImageRenderer bool
+
+	// The folder holding the read-only preview of the changed dashboards
+	PreviewFolderURL string
 }
 
 func (c changeInfo) GrafanaHost() string {
@@ -69,6 +73,12 @@ type fileChangeInfo struct {
 	// HasRemovedMetadata is true when the original file contains metadata
 	// fields (namespace, labels, annotations) that will be removed when parsing the resource.
 	HasRemovedMetadata bool
+
+	// The repository policy rules this file does not satisfy
+	PolicyViolations []policy.Violation
+
+	// The panels, queries and variables changed compared to the base branch (dashboards only)
+	Diff *dashboardDiff
 }
 
 // URLProvider yields the two base URLs Grafana uses when referring to itself
@@ -89,19 +99,21 @@ type URLProvider struct {
 }
 
 type evaluator struct {
-	render  ScreenshotRenderer
-	parsers resources.ParserFactory
-	urls    URLProvider
-	metrics screenshotMetrics
+	render   ScreenshotRenderer
+	parsers  resources.ParserFactory
+	policies policy.Lister
+	urls     URLProvider
+	metrics  screenshotMetrics
 }
 
-func NewEvaluator(render ScreenshotRenderer, parsers resources.ParserFactory, urls URLProvider, registry prometheus.Registerer) Evaluator {
+func NewEvaluator(render ScreenshotRenderer, parsers resources.ParserFactory, policies policy.Lister, urls URLProvider, registry prometheus.Registerer) Evaluator {
 	metrics := registerScreenshotMetrics(registry)
 	return &evaluator{
-		render:  render,
-		parsers: parsers,
-		urls:    urls,
-		metrics: metrics,
+		render:   render,
+		parsers:  parsers,
+		policies: policies,
+		urls:     urls,
+		metrics:  metrics,
 	}
 }
 
@@ -113,6 +125,11 @@ func (e *evaluator) Evaluate(ctx context.Context, repo repository.Reader, opts p
 		return changeInfo{}, fmt.Errorf("failed to get parser for %s: %w", cfg.Name, err)
 	}
 
+	policies, err := e.policies.ForRepository(ctx, cfg)
+	if err != nil {
+		return changeInfo{}, fmt.Errorf("failed to get policies for %s: %w", cfg.Name, err)
+	}
+
 	rendererAvailable := e.render.IsAvailable(ctx)
 	shouldRender := rendererAvailable && len(changes) == 1 && cfg.Spec.GitHub.GenerateDashboardPreviews
 	info := changeInfo{
@@ -136,7 +153,7 @@ func (e *evaluator) Evaluate(ctx context.Context, repo repository.Reader, opts p
 
 		progress.SetMessage(ctx, fmt.Sprintf("process %s", change.Path))
 		logger.With("action", change.Action).With("path", change.Path)
-		info.Changes = append(info.Changes, e.evaluateFile(ctx, repo, info.GrafanaBaseURL, screenshotBaseURL, orgID, change, opts, parser, shouldRender))
+		info.Changes = append(info.Changes, e.evaluateFile(ctx, repo, info.GrafanaBaseURL, screenshotBaseURL, orgID, change, opts, parser, policies, shouldRender))
 	}
 
 	return info, nil
@@ -156,7 +173,7 @@ func orgIDForLinks(namespace string) int64 {
 	return ns.OrgID
 }
 
-func (e *evaluator) evaluateFile(ctx context.Context, repo repository.Reader, baseURL string, screenshotBaseURL string, orgID int64, change repository.VersionedFileChange, opts provisioning.PullRequestJobOptions, parser resources.Parser, shouldRender bool) fileChangeInfo {
+func (e *evaluator) evaluateFile(ctx context.Context, repo repository.Reader, baseURL string, screenshotBaseURL string, orgID int64, change repository.VersionedFileChange, opts provisioning.PullRequestJobOptions, parser resources.Parser, policies []provisioning.RepositoryPolicy, shouldRender bool) fileChangeInfo {
 	if change.Action == repository.FileActionDeleted {
 		return e.evaluateDeletedFile(ctx, repo, baseURL, orgID, change, parser)
 	}
@@ -182,9 +199,14 @@ func (e *evaluator) evaluateFile(ctx context.Context, repo repository.Reader, ba
 		// metadata against the PR-branch parsed version.
 		baseFileInfo, baseErr := repo.Read(ctx, change.Path, "")
 		if baseErr == nil && baseFileInfo != nil {
-			baseObj, _, _, parseErr := resources.ParseFileResource(ctx, baseFileInfo)
+			baseObj, baseGVK, _, parseErr := resources.ParseFileResource(ctx, baseFileInfo)
 			if parseErr == nil && baseObj != nil {
 				info.HasRemovedMetadata = hasRemovedMetadata(baseObj, info.Parsed.Obj)
+
+				// Summarise what reviewers will see change in the dashboard
+				if baseGVK != nil && baseGVK.Kind == dashboardKind && info.Parsed.GVK.Kind == dashboardKind {
+					info.Diff = diffDashboards(baseObj, info.Parsed.Obj)
+				}
 			}
 		}
 	}
@@ -200,6 +222,9 @@ func (e *evaluator) evaluateFile(ctx context.Context, repo repository.Reader, ba
 		info.Error = err.Error()
 	}
 
+	// Report the policy rules the sync job would enforce on this file
+	info.PolicyViolations = policy.Evaluate(policies, change.Path, obj)
+
 	// Dashboards get special handling
 	if info.Parsed.GVK.Kind == dashboardKind {
 		// FIXME: extract the logic out of a dashboard URL builder/injector or similar
@@ -16,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/policy"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
@@ -31,6 +32,7 @@ func TestCalculateChanges(t *testing.T) {
 		expectedError     string
 		grafanaBaseURL    string
 		screenshotBaseURL string
+		policies          []provisioning.RepositoryPolicy
 	}{
 		{
 			name: "with screenshot",
@@ -485,6 +487,93 @@ func TestCalculateChanges(t *testing.T) {
 				}},
 			},
 		},
+		{
+			name: "policy violations",
+			setupMocks: func(parser *resources.MockParser, reader *repository.MockReader, progress *jobs.MockJobProgressRecorder, renderer *MockScreenshotRenderer, parserFactory *resources.MockParserFactory) {
+				finfo := &repository.FileInfo{
+					Path: "path/to/file.json",
+					Ref:  "ref",
+					Data: []byte("xxxx"),
+				}
+				obj := &unstructured.Unstructured{
+					Object: map[string]interface{}{
+						"apiVersion": resources.DashboardResource.GroupVersion().String(),
+						"kind":       dashboardKind,
+						"metadata": map[string]interface{}{
+							"name": "the-uid",
+						},
+						"spec": map[string]interface{}{
+							"title": "hello world",
+						},
+					},
+				}
+				meta, _ := utils.MetaAccessor(obj)
+
+				progress.On("SetMessage", mock.Anything, "process path/to/file.json").Return()
+				reader.On("Read", mock.Anything, "path/to/file.json", "ref").Return(finfo, nil)
+				reader.On("Read", mock.Anything, "path/to/file.json", "").Maybe().Return(nil, repository.ErrFileNotFound)
+				reader.On("Config").Return(&provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-repo",
+						Namespace: "x",
+					},
+					Spec: provisioning.RepositorySpec{
+						GitHub: &provisioning.GitHubRepositoryConfig{
+							GenerateDashboardPreviews: true,
+						},
+					},
+				})
+				parser.On("Parse", mock.Anything, finfo).Return(&resources.ParsedResource{
+					Info: finfo,
+					Repo: provisioning.ResourceRepositoryInfo{
+						Namespace: "x",
+						Name:      "y",
+					},
+					GVK: schema.GroupVersionKind{
+						Kind: dashboardKind,
+					},
+					Obj:            obj,
+					Existing:       obj,
+					Meta:           meta,
+					DryRunResponse: obj,
+				}, nil)
+				renderer.On("IsAvailable", mock.Anything, mock.Anything).Return(false)
+				parserFactory.On("GetParser", mock.Anything, mock.Anything).Return(parser, nil)
+			},
+			policies: []provisioning.RepositoryPolicy{{
+				ObjectMeta: metav1.ObjectMeta{Name: "tags"},
+				Spec: provisioning.RepositoryPolicySpec{
+					Rules: []provisioning.PolicyRule{{
+						Name:       "dashboards",
+						Conditions: []provisioning.PolicyCondition{{Field: "spec.tags", Operator: provisioning.PolicyOperatorExists}},
+					}},
+				},
+			}},
+			changes: []repository.VersionedFileChange{{
+				Action: repository.FileActionCreated,
+				Path:   "path/to/file.json",
+				Ref:    "ref",
+			}},
+			expectedInfo: changeInfo{
+				Changes: []fileChangeInfo{{
+					Change: repository.VersionedFileChange{
+						Action: repository.FileActionCreated,
+						Path:   "path/to/file.json",
+						Ref:    "ref",
+					},
+					GrafanaURL:           "http://host/d/the-uid/hello-world",
+					PreviewURL:           "http://host/admin/provisioning/y/dashboard/preview/path/to/file.json?pull_request_url=http%253A%252F%252Fgithub.com%252Fpr%252F&ref=ref",
+					GrafanaScreenshotURL: "",
+					PreviewScreenshotURL: "",
+					PolicyViolations: []policy.Violation{{
+						Policy:      "tags",
+						Rule:        "dashboards",
+						Enforcement: provisioning.PolicyEnforcementFail,
+						Message:     "spec.tags must be set",
+					}},
+				}},
+			},
+		},
 		{
 			name: "non-default org pins orgId on links",
 			setupMocks: func(parser *resources.MockParser, reader *repository.MockReader, progress *jobs.MockJobProgressRecorder, renderer *MockScreenshotRenderer, parserFactory *resources.MockParserFactory) {
@@ -1388,7 +1477,7 @@ func TestCalculateChanges(t *testing.T) {
 			if tt.grafanaBaseURL != "" {
 				internalURL = tt.grafanaBaseURL
 			}
-			evaluator := NewEvaluator(renderer, parserFactory, URLProvider{
+			evaluator := NewEvaluator(renderer, parserFactory, staticPolicies(tt.policies), URLProvider{
 				Internal: func(_ context.Context, _ string) string { return internalURL },
 				Public:   func(_ context.Context, _ string) string { return screenshotBaseURL },
 			}, prometheus.NewPedanticRegistry())
@@ -1416,11 +1505,18 @@ func TestCalculateChanges(t *testing.T) {
 				require.Equal(t, tt.expectedInfo.Changes[i].GrafanaScreenshotURL, change.GrafanaScreenshotURL)
 				require.Equal(t, tt.expectedInfo.Changes[i].PreviewScreenshotURL, change.PreviewScreenshotURL)
 				require.Equal(t, tt.expectedInfo.Changes[i].Error, change.Error)
+				require.Equal(t, tt.expectedInfo.Changes[i].PolicyViolations, change.PolicyViolations)
 			}
 		})
 	}
 }
 
+type staticPolicies []provisioning.RepositoryPolicy
+
+func (p staticPolicies) ForRepository(_ context.Context, _ *provisioning.Repository) ([]provisioning.RepositoryPolicy, error) {
+	return p, nil
+}
+
 func TestDummyImageURL(t *testing.T) {
 	urls := make([]string, 0, 10)
 	for i := range 10 {
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"text/template"
+
+	"github.com/grafana/grafana/apps/provisioning/pkg/policy"
 )
 
 const maxErrorLength = 256
@@ -18,6 +20,9 @@ type commenter struct {
 	templateFooter           *template.Template
 	templateValidationErrors *template.Template
 	templateMetadataNotice   *template.Template
+	templatePolicy           *template.Template
+	templateDiff             *template.Template
+	templatePreview          *template.Template
 	showImageRendererNote    bool
 }
 
@@ -29,6 +34,9 @@ func NewCommenter(showImageRendererNote bool) Commenter {
 		templateFooter:           template.Must(template.New("footer").Parse(commentTemplateFooter)),
 		templateValidationErrors: template.Must(template.New("errors").Parse(commentTemplateValidationErrors)),
 		templateMetadataNotice:   template.Must(template.New("metadata").Parse(commentTemplateMetadataNotice)),
+		templatePolicy:           template.Must(template.New("policy").Funcs(template.FuncMap{"cell": tableCell}).Parse(commentTemplatePolicyViolations)),
+		templateDiff:             template.Must(template.New("diff").Funcs(template.FuncMap{"cell": tableCell}).Parse(commentTemplateDashboardDiff)),
+		templatePreview:          template.Must(template.New("preview").Parse(commentTemplatePreview)),
 		showImageRendererNote:    showImageRendererNote,
 	}
 }
@@ -60,19 +68,37 @@ func (c *commenter) generateComment(_ context.Context, info changeInfo) (string,
 		if err := c.templateTable.Execute(&buf, &info); err != nil {
 			return "", fmt.Errorf("unable to execute template: %w", err)
 		}
-		if info.HasErrors() {
+		if info.HasValidationErrors() {
 			if err := c.templateValidationErrors.Execute(&buf, &info); err != nil {
 				return "", fmt.Errorf("unable to execute validation errors template: %w", err)
 			}
 		}
 	}
 
+	if info.HasDashboardDiffs() {
+		if err := c.templateDiff.Execute(&buf, &info); err != nil {
+			return "", fmt.Errorf("unable to execute dashboard diff template: %w", err)
+		}
+	}
+
+	if info.HasPolicyViolations() {
+		if err := c.templatePolicy.Execute(&buf, &info); err != nil {
+			return "", fmt.Errorf("unable to execute policy violations template: %w", err)
+		}
+	}
+
 	if info.HasRemovedMetadataChanges() {
 		if err := c.templateMetadataNotice.Execute(&buf, &info); err != nil {
 			return "", fmt.Errorf("unable to execute metadata notice template: %w", err)
 		}
 	}
 
+	if info.PreviewFolderURL != "" {
+		if err := c.templatePreview.Execute(&buf, info); err != nil {
+			return "", fmt.Errorf("unable to execute preview template: %w", err)
+		}
+	}
+
 	if info.MissingImageRenderer && c.showImageRendererNote {
 		if err := c.templateRenderInfo.Execute(&buf, info); err != nil {
 			return "", fmt.Errorf("unable to execute template: %w", err)
@@ -154,6 +180,40 @@ const commentTemplateValidationErrors = `
 {{- end}}{{ end}}
 `
 
+const commentTemplateDashboardDiff = `
+
+### 🔍 Dashboard Changes
+{{- range .Changes}}{{ if .Diff}}
+
+| ` + "`{{.Change.Path}}`" + ` | Kind | Name |
+|------|------|------|
+{{- range .Diff.Entries}}
+| {{.Icon}} {{.Action}} | {{.Kind}} | {{cell .Name}} |
+{{- end}}
+{{- if .Diff.Skipped}}
+
+and {{.Diff.Skipped}} more changes.
+{{- end}}
+{{- end}}{{ end}}
+`
+
+const commentTemplatePolicyViolations = `
+
+### 🛡️ Policy Violations
+
+| File | Rule | Violation | Sync |
+|------|------|-----------|------|
+{{- range .Changes}}{{ $path := .Change.Path }}{{ range .PolicyViolations}}
+| ` + "`{{$path}}`" + ` | {{cell .Rule}} | {{cell .Message}} | {{ if .Blocking}}❌ blocked{{ else }}⚠️ warning{{ end }} |
+{{- end}}{{ end}}
+`
+
+const commentTemplatePreview = `
+
+### 👀 Preview
+
+The changed dashboards are loaded into a [read-only preview]({{.PreviewFolderURL}}). It is updated with every push and removed when the pull request is closed.`
+
 // TODO(ferruvich): let's discuss this text with the team
 const commentTemplateMetadataNotice = `
 
@@ -196,7 +256,7 @@ func (f *fileChangeInfo) ExistingLink() string {
 }
 
 func (f *fileChangeInfo) StatusIcon() string {
-	if f.Error != "" {
+	if f.HasIssues() {
 		return "⚠️"
 	}
 	return "✅"
@@ -205,16 +265,36 @@ func (f *fileChangeInfo) StatusIcon() string {
 // TruncatedError returns a sanitized, length-limited error suitable for a
 // pullrequest comment.
 func (f *fileChangeInfo) TruncatedError() string {
-	msg := strings.ReplaceAll(f.Error, "\n", " ")
-	msg = strings.ReplaceAll(msg, "\r", "")
-	msg = strings.ReplaceAll(msg, "|", "\\|")
+	msg := tableCell(f.Error)
 	if len(msg) > maxErrorLength {
 		return msg[:maxErrorLength] + "…"
 	}
 	return msg
 }
 
+// HasIssues returns true when the file fails validation or would be blocked by the repository policy
+func (f *fileChangeInfo) HasIssues() bool {
+	return f.Error != "" || policy.Blocking(f.PolicyViolations)
+}
+
+// tableCell makes the value safe to use inside a markdown table cell
+func tableCell(value string) string {
+	msg := strings.ReplaceAll(value, "\n", " ")
+	msg = strings.ReplaceAll(msg, "\r", "")
+	return strings.ReplaceAll(msg, "|", "\\|")
+}
+
 func (c *changeInfo) HasErrors() bool {
+	for i := range c.Changes {
+		if c.Changes[i].HasIssues() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasValidationErrors returns true when a file failed validation, ignoring policy violations
+func (c *changeInfo) HasValidationErrors() bool {
 	for i := range c.Changes {
 		if c.Changes[i].Error != "" {
 			return true
@@ -232,6 +312,24 @@ func (c *changeInfo) HasRemovedMetadataChanges() bool {
 	return false
 }
 
+func (c *changeInfo) HasDashboardDiffs() bool {
+	for i := range c.Changes {
+		if c.Changes[i].Diff != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *changeInfo) HasPolicyViolations() bool {
+	for i := range c.Changes {
+		if len(c.Changes[i].PolicyViolations) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *changeInfo) TotalChanges() int {
 	return len(c.Changes)
 }
@@ -239,7 +337,7 @@ func (c *changeInfo) TotalChanges() int {
 func (c *changeInfo) ErrorCount() int {
 	n := 0
 	for i := range c.Changes {
-		if c.Changes[i].Error != "" {
+		if c.Changes[i].HasIssues() {
 			n++
 		}
 	}
@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/policy"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
 )
@@ -55,6 +56,25 @@ func TestGenerateComment(t *testing.T) {
 				},
 			},
 		}},
+		{"preview dashboard", changeInfo{
+			GrafanaBaseURL:   "http://host/",
+			RepositoryName:   "my-repo",
+			RepositoryTitle:  "My Repo",
+			PreviewFolderURL: "http://host/dashboards/f/preview-abc",
+			Changes: []fileChangeInfo{
+				{
+					Parsed: &resources.ParsedResource{
+						Info: &repository.FileInfo{
+							Path: "file.json",
+						},
+						GVK:    schema.GroupVersionKind{Kind: "Dashboard"},
+						Action: v0alpha1.ResourceActionCreate,
+					},
+					Title:      "New Dashboard",
+					PreviewURL: "http://host/d/pv-abc/new-dashboard",
+				},
+			},
+		}},
 		{"update dashboard", changeInfo{
 			GrafanaBaseURL:  "http://host/",
 			RepositoryName:  "my-repo",
@@ -250,6 +270,75 @@ func TestGenerateComment(t *testing.T) {
 				},
 			},
 		}},
+		{"update dashboard with diff", changeInfo{
+			GrafanaBaseURL: "http://host/",
+			Changes: []fileChangeInfo{
+				{
+					Change: repository.VersionedFileChange{
+						Path: "file.json",
+					},
+					Parsed: &resources.ParsedResource{
+						Info: &repository.FileInfo{
+							Path: "file.json",
+						},
+						Action: v0alpha1.ResourceActionUpdate,
+						GVK:    schema.GroupVersionKind{Kind: "Dashboard"},
+					},
+					Title:      "Existing Dashboard",
+					GrafanaURL: "http://grafana/d/uid",
+					PreviewURL: "http://grafana/admin/preview",
+					Diff: &dashboardDiff{
+						Entries: []diffEntry{
+							{Action: diffAdded, Kind: "Panel", Name: "Network"},
+							{Action: diffChanged, Kind: "Query", Name: "CPU / A"},
+							{Action: diffRemoved, Kind: "Variable", Name: "region"},
+						},
+						Skipped: 2,
+					},
+				},
+			},
+		}},
+		{"multiple files with policy violations", changeInfo{
+			GrafanaBaseURL: "http://host/",
+			Changes: []fileChangeInfo{
+				{
+					Change: repository.VersionedFileChange{
+						Path: "prod/home.json",
+					},
+					Parsed: &resources.ParsedResource{
+						Info: &repository.FileInfo{
+							Path: "prod/home.json",
+						},
+						Action: v0alpha1.ResourceActionUpdate,
+						GVK:    schema.GroupVersionKind{Kind: "Dashboard"},
+					},
+					Title:      "Home",
+					GrafanaURL: "http://grafana/d/home",
+					PreviewURL: "http://grafana/admin/preview",
+					PolicyViolations: []policy.Violation{
+						{Rule: "prod-refresh", Enforcement: v0alpha1.PolicyEnforcementFail, Message: "spec.refresh must be at least 1m, found 5s"},
+						{Rule: "prod-tags", Enforcement: v0alpha1.PolicyEnforcementWarn, Message: "spec.tags must be set"},
+					},
+				},
+				{
+					Change: repository.VersionedFileChange{
+						Path: "dev/test.json",
+					},
+					Parsed: &resources.ParsedResource{
+						Info: &repository.FileInfo{
+							Path: "dev/test.json",
+						},
+						Action: v0alpha1.ResourceActionCreate,
+						GVK:    schema.GroupVersionKind{Kind: "Dashboard"},
+					},
+					Title:      "Test",
+					PreviewURL: "http://grafana/admin/preview",
+					PolicyViolations: []policy.Violation{
+						{Rule: "datasources", Enforcement: v0alpha1.PolicyEnforcementWarn, Message: "datasource \"a|b\" is not allowed"},
+					},
+				},
+			},
+		}},
 	} {
 		t.Run(tc.Name, func(t *testing.T) {
 			repo := NewMockPullRequestRepo(t)
@@ -0,0 +1,233 @@
+package pullrequest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// maxDiffEntries limits the number of changes listed for a single dashboard
+const maxDiffEntries = 20
+
+type diffAction string
+
+const (
+	diffAdded   diffAction = "added"
+	diffRemoved diffAction = "removed"
+	diffChanged diffAction = "changed"
+)
+
+// diffEntry is a single panel, query or variable that differs between two dashboard versions
+type diffEntry struct {
+	Action diffAction
+	Kind   string
+	Name   string
+}
+
+func (e diffEntry) Icon() string {
+	switch e.Action {
+	case diffAdded:
+		return "➕"
+	case diffRemoved:
+		return "➖"
+	default:
+		return "✏️"
+	}
+}
+
+// dashboardDiff summarises the changes to the panels, queries and variables of a dashboard.
+// Layout changes (panel positions) are ignored.
+type dashboardDiff struct {
+	Entries []diffEntry
+
+	// Entries not listed because of maxDiffEntries
+	Skipped int
+}
+
+// diffDashboards compares the dashboard on the base branch with the one in the pull request.
+// Both classic (panels/templating) and v2 (elements/variables) dashboard specs are supported.
+func diffDashboards(base, changed *unstructured.Unstructured) *dashboardDiff {
+	if base == nil || changed == nil {
+		return nil
+	}
+
+	basePanels, changedPanels := dashboardPanels(base), dashboardPanels(changed)
+	var entries []diffEntry
+	entries = append(entries, diffItems("Panel", panelBodies(basePanels), panelBodies(changedPanels))...)
+	entries = append(entries, diffItems("Query", panelQueries(basePanels), panelQueries(changedPanels))...)
+	entries = append(entries, diffItems("Variable", dashboardVariables(base), dashboardVariables(changed))...)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	diff := &dashboardDiff{Entries: entries}
+	if len(entries) > maxDiffEntries {
+		diff.Entries = entries[:maxDiffEntries]
+		diff.Skipped = len(entries) - maxDiffEntries
+	}
+	return diff
+}
+
+// diffItems compares two sets of named items, returning the entries sorted by name
+func diffItems(kind string, base, changed map[string]any) []diffEntry {
+	var entries []diffEntry
+	for name, after := range changed {
+		before, ok := base[name]
+		switch {
+		case !ok:
+			entries = append(entries, diffEntry{Action: diffAdded, Kind: kind, Name: name})
+		case !reflect.DeepEqual(before, after):
+			entries = append(entries, diffEntry{Action: diffChanged, Kind: kind, Name: name})
+		}
+	}
+	for name := range base {
+		if _, ok := changed[name]; !ok {
+			entries = append(entries, diffEntry{Action: diffRemoved, Kind: kind, Name: name})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+type dashboardPanel struct {
+	// The panel body without its position and queries
+	Body map[string]any
+	// The queries by refId
+	Queries map[string]any
+}
+
+// dashboardPanels returns the panels of the dashboard by title.
+// Panels without a title are named by their id.
+func dashboardPanels(obj *unstructured.Unstructured) map[string]dashboardPanel {
+	panels := map[string]dashboardPanel{}
+
+	// v2 dashboards keep the panels in a map of elements
+	if elements, ok, _ := unstructured.NestedMap(obj.Object, "spec", "elements"); ok {
+		for key, v := range elements {
+			element, ok := v.(map[string]any)
+			if !ok || element["kind"] != "Panel" {
+				continue
+			}
+			spec, _ := element["spec"].(map[string]any)
+			body := copyWithout(spec, "data")
+			queries := map[string]any{}
+			list, _, _ := unstructured.NestedSlice(spec, "data", "spec", "queries")
+			for i, q := range list {
+				query, _ := q.(map[string]any)
+				refID, _, _ := unstructured.NestedString(query, "spec", "refId")
+				queries[queryName(refID, i)] = query
+			}
+			panels[panelName(spec, key)] = dashboardPanel{Body: body, Queries: queries}
+		}
+		return panels
+	}
+
+	list, _, _ := unstructured.NestedSlice(obj.Object, "spec", "panels")
+	var walk func(list []any)
+	walk = func(list []any) {
+		for _, v := range list {
+			panel, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			// Collapsed rows keep their panels inside the row
+			if nested, ok := panel["panels"].([]any); ok {
+				walk(nested)
+			}
+			queries := map[string]any{}
+			targets, _ := panel["targets"].([]any)
+			for i, t := range targets {
+				target, _ := t.(map[string]any)
+				refID, _ := target["refId"].(string)
+				queries[queryName(refID, i)] = target
+			}
+			panels[panelName(panel, fmt.Sprint(panel["id"]))] = dashboardPanel{
+				Body:    copyWithout(panel, "gridPos", "targets", "panels"),
+				Queries: queries,
+			}
+		}
+	}
+	walk(list)
+
+	return panels
+}
+
+func panelName(panel map[string]any, fallback string) string {
+	if title, _ := panel["title"].(string); title != "" {
+		return title
+	}
+	return "#" + fallback
+}
+
+func queryName(refID string, index int) string {
+	if refID != "" {
+		return refID
+	}
+	return fmt.Sprintf("#%d", index)
+}
+
+func panelBodies(panels map[string]dashboardPanel) map[string]any {
+	bodies := make(map[string]any, len(panels))
+	for name, panel := range panels {
+		bodies[name] = panel.Body
+	}
+	return bodies
+}
+
+// panelQueries returns the queries of every panel, named `<panel> / <refId>`
+func panelQueries(panels map[string]dashboardPanel) map[string]any {
+	queries := map[string]any{}
+	for name, panel := range panels {
+		for refID, query := range panel.Queries {
+			queries[name+" / "+refID] = query
+		}
+	}
+	return queries
+}
+
+// dashboardVariables returns the template variables by name.
+// The current value is ignored, as it changes when the dashboard is saved.
+func dashboardVariables(obj *unstructured.Unstructured) map[string]any {
+	variables := map[string]any{}
+
+	// v2 dashboards keep the variables in the spec
+	if list, ok, _ := unstructured.NestedSlice(obj.Object, "spec", "variables"); ok {
+		for _, v := range list {
+			variable, _ := v.(map[string]any)
+			spec, _ := variable["spec"].(map[string]any)
+			if name, _ := spec["name"].(string); name != "" {
+				variables[name] = map[string]any{
+					"kind": variable["kind"],
+					"spec": copyWithout(spec, "current", "options"),
+				}
+			}
+		}
+		return variables
+	}
+
+	list, _, _ := unstructured.NestedSlice(obj.Object, "spec", "templating", "list")
+	for _, v := range list {
+		variable, _ := v.(map[string]any)
+		if name, _ := variable["name"].(string); name != "" {
+			variables[name] = copyWithout(variable, "current", "options")
+		}
+	}
+	return variables
+}
+
+// copyWithout returns a shallow copy of the map without the given keys
+func copyWithout(m map[string]any, keys ...string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	for _, k := range keys {
+		delete(out, k)
+	}
+	return out
+}
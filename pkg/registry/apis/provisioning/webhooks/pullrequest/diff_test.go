@@ -0,0 +1,119 @@
+package pullrequest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDiffDashboards(t *testing.T) {
+	t.Run("classic dashboard", func(t *testing.T) {
+		base := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{
+				"panels": []any{
+					map[string]any{"id": int64(1), "title": "CPU", "type": "timeseries", "gridPos": map[string]any{"x": int64(0)},
+						"targets": []any{map[string]any{"refId": "A", "expr": "rate(cpu[1m])"}}},
+					map[string]any{"id": int64(2), "title": "Memory", "type": "timeseries"},
+					map[string]any{"id": int64(3), "type": "row", "collapsed": true, "panels": []any{
+						map[string]any{"id": int64(4), "title": "Disk", "type": "stat"},
+					}},
+				},
+				"templating": map[string]any{"list": []any{
+					map[string]any{"name": "env", "query": "prod,dev", "current": map[string]any{"value": "prod"}},
+					map[string]any{"name": "region", "query": "eu"},
+				}},
+			},
+		}}
+		changed := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{
+				"panels": []any{
+					map[string]any{"id": int64(1), "title": "CPU", "type": "timeseries", "gridPos": map[string]any{"x": int64(12)},
+						"targets": []any{map[string]any{"refId": "A", "expr": "rate(cpu[5m])"}, map[string]any{"refId": "B", "expr": "up"}}},
+					map[string]any{"id": int64(2), "title": "Memory", "type": "stat"},
+					map[string]any{"id": int64(3), "type": "row", "collapsed": true, "panels": []any{
+						map[string]any{"id": int64(4), "title": "Disk", "type": "stat"},
+						map[string]any{"id": int64(5), "title": "Network", "type": "stat"},
+					}},
+				},
+				"templating": map[string]any{"list": []any{
+					map[string]any{"name": "env", "query": "prod,dev", "current": map[string]any{"value": "dev"}},
+				}},
+			},
+		}}
+
+		diff := diffDashboards(base, changed)
+		require.NotNil(t, diff)
+		require.Equal(t, []diffEntry{
+			{Action: diffChanged, Kind: "Panel", Name: "Memory"},
+			{Action: diffAdded, Kind: "Panel", Name: "Network"},
+			{Action: diffChanged, Kind: "Query", Name: "CPU / A"},
+			{Action: diffAdded, Kind: "Query", Name: "CPU / B"},
+			{Action: diffRemoved, Kind: "Variable", Name: "region"},
+		}, diff.Entries)
+		require.Zero(t, diff.Skipped)
+	})
+
+	t.Run("v2 dashboard", func(t *testing.T) {
+		query := func(refID, expr string) map[string]any {
+			return map[string]any{"kind": "PanelQuery", "spec": map[string]any{"refId": refID, "query": map[string]any{"expr": expr}}}
+		}
+		panel := func(title string, queries ...any) map[string]any {
+			return map[string]any{"kind": "Panel", "spec": map[string]any{
+				"title": title,
+				"data":  map[string]any{"kind": "QueryGroup", "spec": map[string]any{"queries": queries}},
+			}}
+		}
+		base := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{
+				"elements": map[string]any{
+					"panel-1": panel("Requests", query("A", "sum(requests)")),
+					"panel-2": panel("Errors"),
+				},
+				"variables": []any{
+					map[string]any{"kind": "QueryVariable", "spec": map[string]any{"name": "cluster", "query": "a"}},
+				},
+			},
+		}}
+		changed := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{
+				"elements": map[string]any{
+					"panel-1": panel("Requests", query("A", "sum(requests)")),
+				},
+				"variables": []any{
+					map[string]any{"kind": "QueryVariable", "spec": map[string]any{"name": "cluster", "query": "b"}},
+				},
+			},
+		}}
+
+		diff := diffDashboards(base, changed)
+		require.NotNil(t, diff)
+		require.Equal(t, []diffEntry{
+			{Action: diffRemoved, Kind: "Panel", Name: "Errors"},
+			{Action: diffChanged, Kind: "Variable", Name: "cluster"},
+		}, diff.Entries)
+	})
+
+	t.Run("only layout changes", func(t *testing.T) {
+		base := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{"panels": []any{map[string]any{"id": int64(1), "title": "CPU", "gridPos": map[string]any{"y": int64(0)}}}},
+		}}
+		changed := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{"panels": []any{map[string]any{"id": int64(1), "title": "CPU", "gridPos": map[string]any{"y": int64(8)}}}},
+		}}
+		require.Nil(t, diffDashboards(base, changed))
+	})
+
+	t.Run("limits the number of entries", func(t *testing.T) {
+		panels := []any{}
+		for i := range maxDiffEntries + 5 {
+			panels = append(panels, map[string]any{"id": int64(i)})
+		}
+		base := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{}}}
+		changed := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{"panels": panels}}}
+
+		diff := diffDashboards(base, changed)
+		require.Len(t, diff.Entries, maxDiffEntries)
+		require.Equal(t, 5, diff.Skipped)
+	})
+}
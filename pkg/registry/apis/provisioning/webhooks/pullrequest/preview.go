@@ -0,0 +1,248 @@
+package pullrequest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/infra/slugify"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+)
+
+type previewer struct {
+	clients          resources.ClientFactory
+	folderAPIVersion string
+	namespace        string
+}
+
+// NewPreviewer returns a Previewer loading previews into the given namespace. Previews are not loaded
+// when the namespace is empty.
+func NewPreviewer(clients resources.ClientFactory, folderAPIVersion, namespace string) Previewer {
+	return &previewer{
+		clients:          clients,
+		folderAPIVersion: folderAPIVersion,
+		namespace:        namespace,
+	}
+}
+
+// previewName is the name of the preview of a pull request, shared by its folder and the label of its
+// resources. It is derived from the repository and pull request so that every job of the pull request
+// updates the same preview.
+func previewName(repo *provisioning.Repository, pr int) string {
+	hash := sha256.Sum256([]byte(repo.Namespace + "/" + repo.Name + "/" + strconv.Itoa(pr)))
+	return "preview-" + hex.EncodeToString(hash[:])[:24]
+}
+
+// previewResourceName is the name of the copy of a resource in a preview. The copy can not keep the
+// name of the resource, as other pull requests may change the same resource.
+func previewResourceName(preview, name string) string {
+	hash := sha256.Sum256([]byte(preview + "/" + name))
+	return "pv-" + hex.EncodeToString(hash[:])[:24]
+}
+
+// previewClients returns the clients of the preview namespace. Previews are written as provisioning of
+// that namespace, never to the namespace of the repository.
+func (p *previewer) previewClients(ctx context.Context) (context.Context, resources.ResourceClients, error) {
+	ctx, _, err := identity.WithProvisioningIdentity(ctx, p.namespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get provisioning identity for '%s': %w", p.namespace, err)
+	}
+	clients, err := p.clients.Clients(ctx, p.namespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get clients: %w", err)
+	}
+	return ctx, clients, nil
+}
+
+// Apply loads the changed dashboards of the pull request into its preview, a folder of the preview
+// namespace holding read-only copies of them, and points their preview links at the copies. Copies of
+// dashboards the pull request no longer changes are removed.
+func (p *previewer) Apply(ctx context.Context, repo *provisioning.Repository, pr int, info *changeInfo) error {
+	if p.namespace == "" {
+		return nil
+	}
+
+	logger := logging.FromContext(ctx)
+	name := previewName(repo, pr)
+	ctx, clients, err := p.previewClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	folders, folderGVK, err := clients.Folder(ctx, p.folderAPIVersion)
+	if err != nil {
+		return fmt.Errorf("get folder client: %w", err)
+	}
+
+	title := fmt.Sprintf("Preview of pull request #%d", pr)
+	if repo.Spec.Title != "" {
+		title = fmt.Sprintf("Preview of %s pull request #%d", repo.Spec.Title, pr)
+	}
+	folder := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"title": title},
+	}}
+	folder.SetGroupVersionKind(folderGVK)
+	folder.SetNamespace(p.namespace)
+	folder.SetName(name)
+	if err := p.upsert(ctx, folders, folder, name); err != nil {
+		return fmt.Errorf("create preview folder: %w", err)
+	}
+
+	base, err := url.Parse(info.GrafanaBaseURL)
+	if err != nil {
+		return fmt.Errorf("parse base url: %w", err)
+	}
+	orgID := orgIDForLinks(p.namespace)
+	withOrg := func(u *url.URL) string {
+		if orgID > 0 {
+			query := url.Values{}
+			query.Set("orgId", strconv.FormatInt(orgID, 10))
+			u.RawQuery = query.Encode()
+		}
+		return u.String()
+	}
+	info.PreviewFolderURL = withOrg(base.JoinPath("dashboards/f", name))
+
+	current := map[string]bool{}
+	for i := range info.Changes {
+		change := &info.Changes[i]
+		if change.Parsed == nil || change.Parsed.GVK.Kind != dashboardKind || change.Error != "" {
+			continue
+		}
+
+		client, _, err := clients.ForKind(ctx, change.Parsed.GVK)
+		if err != nil {
+			logger.Warn("failed to get preview client", "path", change.Change.Path, "error", err)
+			change.Error = fmt.Sprintf("load into preview: %s", err)
+			continue
+		}
+
+		obj := change.Parsed.Obj.DeepCopy()
+		copyName := previewResourceName(name, obj.GetName())
+		obj.SetNamespace(p.namespace)
+		obj.SetName(copyName)
+		obj.SetGenerateName("")
+		obj.SetUID("")
+		obj.SetResourceVersion("")
+		obj.SetGeneration(0)
+		obj.SetCreationTimestamp(metav1.Time{})
+		obj.SetManagedFields(nil)
+		if _, found, _ := unstructured.NestedString(obj.Object, "spec", "uid"); found {
+			if err := unstructured.SetNestedField(obj.Object, copyName, "spec", "uid"); err != nil {
+				return fmt.Errorf("set preview uid: %w", err)
+			}
+		}
+
+		if err := p.upsert(ctx, client, obj, name); err != nil {
+			logger.Warn("failed to load dashboard into preview", "path", change.Change.Path, "error", err)
+			change.Error = fmt.Sprintf("load into preview: %s", err)
+			continue
+		}
+		current[copyName] = true
+		change.PreviewURL = withOrg(base.JoinPath("d", copyName, slugify.Slugify(change.Title)))
+	}
+
+	dashboards, _, err := clients.ForResource(ctx, resources.DashboardResource)
+	if err != nil {
+		return fmt.Errorf("get dashboard client: %w", err)
+	}
+	return deletePreviewResources(ctx, dashboards, name, func(item *unstructured.Unstructured) bool {
+		return !current[item.GetName()]
+	})
+}
+
+// Remove deletes the preview of a pull request, its dashboards and then its folder.
+func (p *previewer) Remove(ctx context.Context, repo *provisioning.Repository, pr int) error {
+	if p.namespace == "" {
+		return nil
+	}
+
+	name := previewName(repo, pr)
+	ctx, clients, err := p.previewClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	dashboards, _, err := clients.ForResource(ctx, resources.DashboardResource)
+	if err != nil {
+		return fmt.Errorf("get dashboard client: %w", err)
+	}
+	if err := deletePreviewResources(ctx, dashboards, name, func(*unstructured.Unstructured) bool { return true }); err != nil {
+		return err
+	}
+
+	folders, _, err := clients.Folder(ctx, p.folderAPIVersion)
+	if err != nil {
+		return fmt.Errorf("get folder client: %w", err)
+	}
+	if err := folders.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete preview folder: %w", err)
+	}
+	return nil
+}
+
+// upsert writes the object as part of the preview, marking it read-only and placing it in the preview
+// folder unless it is the folder itself.
+func (p *previewer) upsert(ctx context.Context, client dynamic.ResourceInterface, obj *unstructured.Unstructured, preview string) error {
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return err
+	}
+
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[utils.LabelKeyPreview] = preview
+	obj.SetLabels(labels)
+	meta.SetManagerProperties(utils.ManagerProperties{
+		Kind:     utils.ManagerKindRepo,
+		Identity: preview,
+	})
+	meta.SetSourceProperties(utils.SourceProperties{})
+	if obj.GetName() != preview {
+		meta.SetFolder(preview)
+	}
+
+	existing, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = client.Create(ctx, obj, metav1.CreateOptions{})
+		return err
+	case err != nil:
+		return err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	_, err = client.Update(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
+
+// deletePreviewResources deletes the resources of the preview selected by the function
+func deletePreviewResources(ctx context.Context, client dynamic.ResourceInterface, preview string, selected func(*unstructured.Unstructured) bool) error {
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: utils.LabelKeyPreview + "=" + preview})
+	if err != nil {
+		return fmt.Errorf("list preview resources: %w", err)
+	}
+	for i := range list.Items {
+		item := &list.Items[i]
+		if !selected(item) {
+			continue
+		}
+		if err := client.Delete(ctx, item.GetName(), metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete preview resource %s: %w", item.GetName(), err)
+		}
+	}
+	return nil
+}
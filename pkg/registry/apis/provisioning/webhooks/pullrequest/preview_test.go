@@ -0,0 +1,161 @@
+package pullrequest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+)
+
+func TestPreviewer(t *testing.T) {
+	ctx := context.Background()
+	repo := &provisioning.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-repo", Namespace: "default"},
+		Spec:       provisioning.RepositorySpec{Title: "Test Repo"},
+	}
+	preview := previewName(repo, 42)
+
+	stale := &unstructured.Unstructured{}
+	stale.SetAPIVersion(resources.DashboardResource.GroupVersion().String())
+	stale.SetKind("Dashboard")
+	stale.SetNamespace("org-2")
+	stale.SetName("stale")
+	stale.SetLabels(map[string]string{utils.LabelKeyPreview: preview})
+
+	fake := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resources.DashboardResource: "DashboardList",
+		resources.FolderResource:    "FolderList",
+	}, stale)
+	dashboardGVK := resources.DashboardResource.GroupVersion().WithKind("Dashboard")
+	production := fake.Resource(resources.DashboardResource).Namespace("default")
+	dashboards := fake.Resource(resources.DashboardResource).Namespace("org-2")
+	folders := fake.Resource(resources.FolderResource).Namespace("org-2")
+
+	clients := resources.NewMockResourceClients(t)
+	clients.EXPECT().Folder(mock.Anything, resources.FolderResource.Version).Return(folders, resources.FolderKind, nil)
+	clients.EXPECT().ForKind(mock.Anything, dashboardGVK).Return(dashboards, resources.DashboardResource, nil)
+	clients.EXPECT().ForResource(mock.Anything, resources.DashboardResource).Return(dashboards, dashboardGVK, nil)
+	factory := resources.NewMockClientFactory(t)
+	factory.EXPECT().Clients(mock.Anything, "org-2").Return(clients, nil)
+
+	dashboard := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": resources.DashboardResource.GroupVersion().String(),
+		"kind":       "Dashboard",
+		"metadata": map[string]any{
+			"name": "my-dashboard",
+			"annotations": map[string]any{
+				utils.AnnoKeySourcePath: "dashboards/my-dashboard.json",
+			},
+		},
+		"spec": map[string]any{"uid": "my-dashboard", "title": "My Dashboard"},
+	}}
+	info := changeInfo{
+		GrafanaBaseURL: "http://localhost:3000/",
+		Changes: []fileChangeInfo{
+			{
+				Title:      "My Dashboard",
+				PreviewURL: "http://localhost:3000/admin/provisioning/test-repo/dashboard/preview/dashboards/my-dashboard.json",
+				Parsed: &resources.ParsedResource{
+					GVK:    dashboardGVK,
+					Obj:    dashboard,
+					Client: production,
+				},
+			},
+			{
+				Title:  "Broken",
+				Error:  "invalid dashboard",
+				Parsed: &resources.ParsedResource{GVK: schema.GroupVersionKind{Kind: "Dashboard"}},
+			},
+			{
+				Change: repository.VersionedFileChange{Path: "playlist.yaml"},
+				Parsed: &resources.ParsedResource{GVK: schema.GroupVersionKind{Kind: "Playlist"}},
+			},
+		},
+	}
+
+	previewer := NewPreviewer(factory, resources.FolderResource.Version, "org-2")
+	copyName := previewResourceName(preview, "my-dashboard")
+
+	t.Run("loads the changed dashboards into a read-only folder of the preview namespace", func(t *testing.T) {
+		require.NoError(t, previewer.Apply(ctx, repo, 42, &info))
+
+		folder, err := folders.Get(ctx, preview, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, preview, folder.GetLabels()[utils.LabelKeyPreview])
+		title, _, _ := unstructured.NestedString(folder.Object, "spec", "title")
+		require.Equal(t, "Preview of Test Repo pull request #42", title)
+
+		copied, err := dashboards.Get(ctx, copyName, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, preview, copied.GetLabels()[utils.LabelKeyPreview])
+		require.Equal(t, preview, copied.GetAnnotations()[utils.AnnoKeyFolder])
+		require.Equal(t, string(utils.ManagerKindRepo), copied.GetAnnotations()[utils.AnnoKeyManagerKind])
+		require.Equal(t, preview, copied.GetAnnotations()[utils.AnnoKeyManagerIdentity])
+		require.NotContains(t, copied.GetAnnotations(), utils.AnnoKeySourcePath)
+		uid, _, _ := unstructured.NestedString(copied.Object, "spec", "uid")
+		require.Equal(t, copyName, uid)
+		require.Equal(t, "my-dashboard", dashboard.GetName(), "the parsed dashboard is left as is")
+
+		require.Equal(t, "http://localhost:3000/d/"+copyName+"/my-dashboard?orgId=2", info.Changes[0].PreviewURL)
+		require.Empty(t, info.Changes[1].PreviewURL)
+		require.Equal(t, "http://localhost:3000/dashboards/f/"+preview+"?orgId=2", info.PreviewFolderURL)
+
+		list, err := production.List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Empty(t, list.Items, "nothing is written to the namespace of the repository")
+
+		_, err = dashboards.Get(ctx, "stale", metav1.GetOptions{})
+		require.True(t, apierrors.IsNotFound(err), "dashboards no longer changed are removed from the preview")
+	})
+
+	t.Run("updates the preview on later pushes", func(t *testing.T) {
+		require.NoError(t, unstructured.SetNestedField(dashboard.Object, "Renamed", "spec", "title"))
+		require.NoError(t, previewer.Apply(ctx, repo, 42, &info))
+
+		copied, err := dashboards.Get(ctx, copyName, metav1.GetOptions{})
+		require.NoError(t, err)
+		title, _, _ := unstructured.NestedString(copied.Object, "spec", "title")
+		require.Equal(t, "Renamed", title)
+	})
+
+	t.Run("removes the preview", func(t *testing.T) {
+		require.NoError(t, previewer.Remove(ctx, repo, 42))
+
+		list, err := dashboards.List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Empty(t, list.Items)
+		_, err = folders.Get(ctx, preview, metav1.GetOptions{})
+		require.True(t, apierrors.IsNotFound(err))
+
+		require.NoError(t, previewer.Remove(ctx, repo, 42), "removing a removed preview is a no-op")
+	})
+}
+
+func TestPreviewer_WithoutNamespace(t *testing.T) {
+	repo := &provisioning.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-repo", Namespace: "default"},
+	}
+	info := changeInfo{
+		GrafanaBaseURL: "http://localhost:3000/",
+		Changes:        []fileChangeInfo{{Title: "My Dashboard", PreviewURL: "http://localhost:3000/admin/provisioning/test-repo/dashboard/preview/dashboards/my-dashboard.json"}},
+	}
+
+	// No client is created without a preview namespace
+	previewer := NewPreviewer(resources.NewMockClientFactory(t), resources.FolderResource.Version, "")
+	require.NoError(t, previewer.Apply(context.Background(), repo, 42, &info))
+	require.NoError(t, previewer.Remove(context.Background(), repo, 42))
+	require.Equal(t, "http://localhost:3000/admin/provisioning/test-repo/dashboard/preview/dashboards/my-dashboard.json", info.Changes[0].PreviewURL)
+	require.Empty(t, info.PreviewFolderURL)
+}
@@ -48,10 +48,11 @@ func ProvidePullRequestWorker(
 	clients := resources.NewClientFactory(configProvider)
 	parsers := resources.NewParserFactory(clients, resources.IsFolderMetadataEnabled(cfg))
 	screenshotRenderer := NewScreenshotRenderer(renderer, blobstore)
-	evaluator := NewEvaluator(screenshotRenderer, parsers, urls, registry)
+	evaluator := NewEvaluator(screenshotRenderer, parsers, resources.NewPolicyLister(configProvider), urls, registry)
 	commenter := NewCommenter(cfg.ProvisioningAllowImageRendering)
+	previewer := NewPreviewer(clients, cfg.ProvisioningFolderAPIVersion, cfg.ProvisioningPreviewNamespace)
 
-	return NewPullRequestWorker(evaluator, commenter, registry)
+	return NewPullRequestWorker(evaluator, commenter, previewer, registry)
 }
 
 //go:generate mockery --name=PullRequestRepo --structname=MockPullRequestRepo --inpackage --filename=mock_pullrequest_repo.go --with-expecter
@@ -72,17 +73,27 @@ type Commenter interface {
 	Comment(ctx context.Context, repo PullRequestRepo, pr int, changeInfo changeInfo) error
 }
 
+// Previewer loads the changed dashboards of a pull request into a read-only preview
+//
+//go:generate mockery --name=Previewer --structname=MockPreviewer --inpackage --filename=mock_previewer.go --with-expecter
+type Previewer interface {
+	Apply(ctx context.Context, repo *provisioning.Repository, pr int, info *changeInfo) error
+	Remove(ctx context.Context, repo *provisioning.Repository, pr int) error
+}
+
 type PullRequestWorker struct {
 	evaluator Evaluator
 	commenter Commenter
+	previewer Previewer
 	metrics   pullRequestMetrics
 }
 
-func NewPullRequestWorker(evaluator Evaluator, commenter Commenter, registry prometheus.Registerer) *PullRequestWorker {
+func NewPullRequestWorker(evaluator Evaluator, commenter Commenter, previewer Previewer, registry prometheus.Registerer) *PullRequestWorker {
 	metrics := registerPullRequestMetrics(registry)
 	return &PullRequestWorker{
 		evaluator: evaluator,
 		commenter: commenter,
+		previewer: previewer,
 		metrics:   metrics,
 	}
 }
@@ -150,9 +161,28 @@ func (c *PullRequestWorker) Process(ctx context.Context,
 	logger.Info("process pull request")
 	defer logger.Info("pull request processed")
 
-	progress.SetMessage(ctx, "listing pull request files")
+	if opts.Closed {
+		progress.SetMessage(ctx, "removing pull request preview")
+		if err := c.previewer.Remove(ctx, repo.Config(), opts.PR); err != nil {
+			logger.Error("failed to remove pull request preview", "error", err)
+			return fmt.Errorf("remove pull request preview: %w", err)
+		}
+		outcome = utils.SuccessOutcome
+		progress.SetFinalMessage(ctx, "pull request preview removed")
+		return nil
+	}
+
 	// FIXME: this is leaky because it's supposed to be already a PullRequestRepo
 	base := cfg.GitHub.Branch
+
+	// Nothing of the pull request is loaded unless its commits pass the signature policy of the repository
+	progress.SetMessage(ctx, "verifying pull request commits")
+	if _, err := repository.VerifyCommits(ctx, repo, base, opts.Ref); err != nil {
+		logger.Error("failed to verify pull request commits", "error", err)
+		return fmt.Errorf("verify pull request commits: %w", err)
+	}
+
+	progress.SetMessage(ctx, "listing pull request files")
 	files, err := prRepo.CompareFiles(ctx, base, opts.Ref)
 	if err != nil {
 		logger.Error("failed to list pull request files", "error", err)
@@ -171,6 +201,14 @@ func (c *PullRequestWorker) Process(ctx context.Context,
 		return fmt.Errorf("calculate changes: %w", err)
 	}
 
+	if cfg.GitHub.GenerateDashboardPreviews {
+		progress.SetMessage(ctx, "loading pull request preview")
+		if err := c.previewer.Apply(ctx, repo.Config(), opts.PR, &changeInfo); err != nil {
+			logger.Error("failed to load pull request preview", "error", err)
+			return fmt.Errorf("load pull request preview: %w", err)
+		}
+	}
+
 	if err := c.commenter.Comment(ctx, prRepo, opts.PR, changeInfo); err != nil {
 		c.metrics.recordCommentPosted(utils.ErrorOutcome)
 		return fmt.Errorf("comment pull request: %w", err)
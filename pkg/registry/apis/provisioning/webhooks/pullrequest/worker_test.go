@@ -3,6 +3,7 @@ package pullrequest
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -46,7 +47,7 @@ func TestPullRequestWorker_IsSupported(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			evaluator := NewMockEvaluator(t)
 			commenter := NewMockCommenter(t)
-			worker := NewPullRequestWorker(evaluator, commenter, prometheus.NewPedanticRegistry())
+			worker := NewPullRequestWorker(evaluator, commenter, NewMockPreviewer(t), prometheus.NewPedanticRegistry())
 			result := worker.IsSupported(context.Background(), tt.job)
 			require.Equal(t, tt.expected, result)
 		})
@@ -70,7 +71,7 @@ func TestPullRequestWorker_Process_NotPullRequestRepository(t *testing.T) {
 		},
 	})
 
-	worker := NewPullRequestWorker(evaluator, commenter, prometheus.NewPedanticRegistry())
+	worker := NewPullRequestWorker(evaluator, commenter, NewMockPreviewer(t), prometheus.NewPedanticRegistry())
 	job := provisioning.Job{
 		Spec: provisioning.JobSpec{
 			Action: provisioning.JobActionPullRequest,
@@ -108,7 +109,7 @@ func TestPullRequestWorker_Process_NotReaderRepository(t *testing.T) {
 		},
 	})
 
-	worker := NewPullRequestWorker(evaluator, commenter, prometheus.NewPedanticRegistry())
+	worker := NewPullRequestWorker(evaluator, commenter, NewMockPreviewer(t), prometheus.NewPedanticRegistry())
 	job := provisioning.Job{
 		Spec: provisioning.JobSpec{
 			Action: provisioning.JobActionPullRequest,
@@ -131,6 +132,7 @@ func TestPullRequestWorker_Process(t *testing.T) {
 		name          string
 		opts          *provisioning.PullRequestJobOptions
 		setupMocks    func(*MockEvaluator, *MockCommenter, *mockPullRequestRepo, *jobs.MockJobProgressRecorder)
+		setupPreview  func(*MockPreviewer)
 		expectedError string
 	}{
 		{
@@ -199,6 +201,7 @@ func TestPullRequestWorker_Process(t *testing.T) {
 						GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main"},
 					},
 				})
+				progress.On("SetMessage", mock.Anything, "verifying pull request commits").Return()
 				progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
 				repo.MockPullRequestRepo.On("CompareFiles", mock.Anything, "main", "test-ref").Return(nil, errors.New("failed to list files"))
 			},
@@ -220,6 +223,7 @@ func TestPullRequestWorker_Process(t *testing.T) {
 						GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main"},
 					},
 				})
+				progress.On("SetMessage", mock.Anything, "verifying pull request commits").Return()
 				progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
 				repo.MockPullRequestRepo.On("CompareFiles", mock.Anything, "main", "test-ref").Return([]repository.VersionedFileChange{}, nil)
 				progress.On("SetFinalMessage", mock.Anything, "no files to process").Return()
@@ -242,6 +246,7 @@ func TestPullRequestWorker_Process(t *testing.T) {
 						GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main"},
 					},
 				})
+				progress.On("SetMessage", mock.Anything, "verifying pull request commits").Return()
 				progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
 
 				// Create a mix of ignored and supported files
@@ -280,6 +285,7 @@ func TestPullRequestWorker_Process(t *testing.T) {
 						GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main"},
 					},
 				})
+				progress.On("SetMessage", mock.Anything, "verifying pull request commits").Return()
 				progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
 
 				// Create a mix of supported and unsupported files
@@ -320,6 +326,7 @@ func TestPullRequestWorker_Process(t *testing.T) {
 						GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main"},
 					},
 				})
+				progress.On("SetMessage", mock.Anything, "verifying pull request commits").Return()
 				progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
 				files := []repository.VersionedFileChange{
 					{Path: "test.yaml"},
@@ -345,6 +352,7 @@ func TestPullRequestWorker_Process(t *testing.T) {
 						GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main"},
 					},
 				})
+				progress.On("SetMessage", mock.Anything, "verifying pull request commits").Return()
 				progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
 				files := []repository.VersionedFileChange{
 					{Path: "test.yaml"},
@@ -371,6 +379,7 @@ func TestPullRequestWorker_Process(t *testing.T) {
 						GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main"},
 					},
 				})
+				progress.On("SetMessage", mock.Anything, "verifying pull request commits").Return()
 				progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
 				files := []repository.VersionedFileChange{
 					{Path: "test.yaml"},
@@ -381,6 +390,94 @@ func TestPullRequestWorker_Process(t *testing.T) {
 			},
 			expectedError: "",
 		},
+		{
+			name: "previews the changed dashboards when enabled",
+			opts: &provisioning.PullRequestJobOptions{
+				PR:  123,
+				Ref: "test-ref",
+			},
+			setupMocks: func(evaluator *MockEvaluator, commenter *MockCommenter, repo *mockPullRequestRepo, progress *jobs.MockJobProgressRecorder) {
+				repo.MockRepository.On("Config").Return(&provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-repo",
+					},
+					Spec: provisioning.RepositorySpec{
+						Title:  "test-repo",
+						GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main", GenerateDashboardPreviews: true},
+					},
+				})
+				progress.On("SetMessage", mock.Anything, "verifying pull request commits").Return()
+				progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
+				progress.On("SetMessage", mock.Anything, "loading pull request preview").Return()
+				files := []repository.VersionedFileChange{
+					{Path: "test.yaml"},
+				}
+				repo.MockPullRequestRepo.On("CompareFiles", mock.Anything, "main", "test-ref").Return(files, nil)
+				evaluator.On("Evaluate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(changeInfo{}, nil)
+				commenter.On("Comment", mock.Anything, mock.Anything, 123, changeInfo{PreviewFolderURL: "http://localhost/dashboards/f/preview"}).Return(nil)
+			},
+			setupPreview: func(previewer *MockPreviewer) {
+				previewer.On("Apply", mock.Anything, mock.Anything, 123, mock.Anything).Run(func(args mock.Arguments) {
+					args.Get(3).(*changeInfo).PreviewFolderURL = "http://localhost/dashboards/f/preview"
+				}).Return(nil)
+			},
+			expectedError: "",
+		},
+		{
+			name: "preview fails",
+			opts: &provisioning.PullRequestJobOptions{
+				PR:  123,
+				Ref: "test-ref",
+			},
+			setupMocks: func(evaluator *MockEvaluator, commenter *MockCommenter, repo *mockPullRequestRepo, progress *jobs.MockJobProgressRecorder) {
+				repo.MockRepository.On("Config").Return(&provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-repo",
+					},
+					Spec: provisioning.RepositorySpec{
+						Title:  "test-repo",
+						GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main", GenerateDashboardPreviews: true},
+					},
+				})
+				progress.On("SetMessage", mock.Anything, "verifying pull request commits").Return()
+				progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
+				progress.On("SetMessage", mock.Anything, "loading pull request preview").Return()
+				files := []repository.VersionedFileChange{
+					{Path: "test.yaml"},
+				}
+				repo.MockPullRequestRepo.On("CompareFiles", mock.Anything, "main", "test-ref").Return(files, nil)
+				evaluator.On("Evaluate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(changeInfo{}, nil)
+			},
+			setupPreview: func(previewer *MockPreviewer) {
+				previewer.On("Apply", mock.Anything, mock.Anything, 123, mock.Anything).Return(errors.New("folder exists"))
+			},
+			expectedError: "load pull request preview: folder exists",
+		},
+		{
+			name: "closed pull request removes the preview",
+			opts: &provisioning.PullRequestJobOptions{
+				PR:     123,
+				Ref:    "test-ref",
+				Closed: true,
+			},
+			setupMocks: func(evaluator *MockEvaluator, commenter *MockCommenter, repo *mockPullRequestRepo, progress *jobs.MockJobProgressRecorder) {
+				repo.MockRepository.On("Config").Return(&provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-repo",
+					},
+					Spec: provisioning.RepositorySpec{
+						Title:  "test-repo",
+						GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main", GenerateDashboardPreviews: true},
+					},
+				})
+				progress.On("SetMessage", mock.Anything, "removing pull request preview").Return()
+				progress.On("SetFinalMessage", mock.Anything, "pull request preview removed").Return()
+			},
+			setupPreview: func(previewer *MockPreviewer) {
+				previewer.On("Remove", mock.Anything, mock.Anything, 123).Return(nil)
+			},
+			expectedError: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -394,7 +491,7 @@ func TestPullRequestWorker_Process(t *testing.T) {
 			progress := jobs.NewMockJobProgressRecorder(t)
 			tt.setupMocks(evaluator, commenter, &repo, progress)
 
-			worker := NewPullRequestWorker(evaluator, commenter, prometheus.NewPedanticRegistry())
+			worker := NewPullRequestWorker(evaluator, commenter, NewMockPreviewer(t), prometheus.NewPedanticRegistry())
 			job := provisioning.Job{
 				Spec: provisioning.JobSpec{
 					Action:      provisioning.JobActionPullRequest,
@@ -417,6 +514,53 @@ func TestPullRequestWorker_Process(t *testing.T) {
 	}
 }
 
+func TestPullRequestWorker_SignaturePolicy(t *testing.T) {
+	repo := signedPullRequestRepo{
+		mockPullRequestRepo: mockPullRequestRepo{
+			MockRepository:      repository.NewMockRepository(t),
+			MockPullRequestRepo: NewMockPullRequestRepo(t),
+		},
+		MockCommitVerifier: repository.NewMockCommitVerifier(t),
+	}
+	repo.MockRepository.On("Config").Return(&provisioning.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-repo"},
+		Spec: provisioning.RepositorySpec{
+			Title:  "test-repo",
+			GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main", GenerateDashboardPreviews: true},
+			Commit: &provisioning.CommitOptions{
+				Verification: &provisioning.CommitVerification{RequireSignedCommits: true},
+			},
+		},
+	})
+	repo.MockCommitVerifier.EXPECT().VerifyCommits(mock.Anything, "main", "test-ref").Return(nil, fmt.Errorf("commit abc: commit is not signed: %w", repository.ErrUnverifiedCommit))
+
+	progress := jobs.NewMockJobProgressRecorder(t)
+	progress.On("SetMessage", mock.Anything, "verifying pull request commits").Return()
+
+	// Neither the files of the pull request are listed nor a preview loaded
+	worker := NewPullRequestWorker(NewMockEvaluator(t), NewMockCommenter(t), NewMockPreviewer(t), prometheus.NewPedanticRegistry())
+	err := worker.Process(logging.Context(context.Background(), logging.DefaultLogger), repo, provisioning.Job{
+		Spec: provisioning.JobSpec{
+			Action:      provisioning.JobActionPullRequest,
+			PullRequest: &provisioning.PullRequestJobOptions{PR: 123, Ref: "test-ref"},
+		},
+	}, progress)
+	require.ErrorIs(t, err, repository.ErrUnverifiedCommit)
+	repo.AssertExpectations(t)
+	progress.AssertExpectations(t)
+}
+
+// signedPullRequestRepo is a pull request repository verifying commit signatures
+type signedPullRequestRepo struct {
+	mockPullRequestRepo
+	*repository.MockCommitVerifier
+}
+
+func (m signedPullRequestRepo) AssertExpectations(t *testing.T) {
+	m.mockPullRequestRepo.AssertExpectations(t)
+	m.MockCommitVerifier.AssertExpectations(t)
+}
+
 type mockPullRequestRepo struct {
 	*repository.MockRepository
 	*MockPullRequestRepo
@@ -148,9 +148,10 @@ func ProvideWebhooksWithImages(
 			render := NewRenderConnector(blobstore, b)
 			webhook := NewWebhookConnector(isPublic, b, screenshotRenderer, registry)
 
-			evaluator := pullrequest.NewEvaluator(screenshotRenderer, parsers, urls, registry)
+			evaluator := pullrequest.NewEvaluator(screenshotRenderer, parsers, resources.NewPolicyLister(configProvider), urls, registry)
 			commenter := pullrequest.NewCommenter(cfg.ProvisioningAllowImageRendering)
-			pullRequestWorker := pullrequest.NewPullRequestWorker(evaluator, commenter, registry)
+			previewer := pullrequest.NewPreviewer(clients, cfg.ProvisioningFolderAPIVersion, cfg.ProvisioningPreviewNamespace)
+			pullRequestWorker := pullrequest.NewPullRequestWorker(evaluator, commenter, previewer, registry)
 
 			return NewWebhookExtraWithImages(
 				render,
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana-app-sdk/logging"
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/schedule"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	provisioningapis "github.com/grafana/grafana/pkg/registry/apis/provisioning"
@@ -183,6 +184,21 @@ func (s *webhookConnector) Connect(ctx context.Context, name string, opts runtim
 			s.metrics.recordEventProcessed(actionTaken)
 		}()
 
+		if rsp.Job != nil && rsp.Job.Action == provisioning.JobActionPull {
+			// Pushes outside of the sync windows are picked up by the next scheduled sync
+			paused, err := schedule.Paused(repo.Config().Spec.Sync, time.Now())
+			if err != nil {
+				logger.Warn("unable to evaluate sync windows", "error", err)
+			} else if paused != "" {
+				logger.Info("skip webhook sync outside of the sync windows", "reason", paused)
+				responder.Object(http.StatusOK, &provisioning.WebhookResponse{
+					Code:    http.StatusOK,
+					Message: paused,
+				})
+				return
+			}
+		}
+
 		if rsp.Job != nil {
 			rsp.Job.Repository = name
 			actionTaken = string(rsp.Job.Action)
@@ -25,6 +25,7 @@ import (
 	grafanaapiserveroptions "github.com/grafana/grafana/pkg/services/apiserver/options"
 	"github.com/grafana/grafana/pkg/storage/legacysql/dualwrite"
 	apistore "github.com/grafana/grafana/pkg/storage/unified/apistore"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
 )
 
 type LegacyStorageProvider interface {
@@ -69,6 +70,14 @@ type StorageOptionsProvider interface {
 	GetStorageOptions(gr schema.GroupResource) *apistore.StorageOptions
 }
 
+// SchemaConversionsProvider allows app installers to convert the objects stored with an older
+// version of their schema. Objects are converted when they are read, and rewritten in the
+// background by the storage server running in the same process.
+// Optional to implement.
+type SchemaConversionsProvider interface {
+	GetSchemaConversions() []resource.SchemaConversion
+}
+
 type AppInstallerConfig struct {
 	CustomConfig             any
 	AllowedV0Alpha1Resources []string
@@ -179,6 +188,11 @@ func InstallAPIs(
 		// the options when it creates the underlying storage.
 		registerStorageOptions(installer, restOpsGetter, logger)
 
+		// Register the schema conversions before the objects of the app are served
+		if err := registerSchemaConversions(installer, resource.DefaultSchemaMigrations()); err != nil {
+			return fmt.Errorf("failed to register schema conversions for app %s: %w", installer.ManifestData().AppName, err)
+		}
+
 		wrapper := &serverWrapper{
 			ctx:               ctx,
 			GenericAPIServer:  appsdkapiserver.NewKubernetesGenericAPIServer(server),
@@ -283,3 +297,20 @@ func registerStorageOptions(
 		}
 	}
 }
+
+// registerSchemaConversions registers the schema conversions of the installer,
+// if it implements SchemaConversionsProvider.
+func registerSchemaConversions(installer appsdkapiserver.AppInstaller, migrations *resource.SchemaMigrations) error {
+	provider, ok := installer.(SchemaConversionsProvider)
+	if !ok {
+		return nil
+	}
+	group := installer.ManifestData().Group
+	conversions := provider.GetSchemaConversions()
+	for _, c := range conversions {
+		if c.Group != group {
+			return fmt.Errorf("schema conversion of %s/%s is not in the group of the app (%s)", c.Group, c.Resource, group)
+		}
+	}
+	return migrations.Register(conversions...)
+}
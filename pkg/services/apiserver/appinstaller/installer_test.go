@@ -9,11 +9,13 @@ import (
 	"github.com/grafana/grafana-app-sdk/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
 	"k8s.io/apiserver/pkg/storage/storagebackend"
 
 	apistore "github.com/grafana/grafana/pkg/storage/unified/apistore"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
 )
 
 func TestRegisterAuthorizers(t *testing.T) {
@@ -246,3 +248,53 @@ func (m *mockAppInstallerWithStorageOpts) ManifestData() *app.ManifestData {
 func (m *mockAppInstallerWithStorageOpts) GetStorageOptions(gr schema.GroupResource) *apistore.StorageOptions {
 	return m.getOpts(gr)
 }
+
+func TestRegisterSchemaConversions(t *testing.T) {
+	convert := func(ctx context.Context, obj *unstructured.Unstructured) error { return nil }
+	manifest := &app.ManifestData{AppName: "test-app", Group: "test.grafana.app"}
+
+	t.Run("installer without SchemaConversionsProvider is a no-op", func(t *testing.T) {
+		migrations := resource.NewSchemaMigrations()
+		require.NoError(t, registerSchemaConversions(&mockAppInstaller{}, migrations))
+	})
+
+	t.Run("registers the conversions of the app", func(t *testing.T) {
+		migrations := resource.NewSchemaMigrations()
+		installer := &mockAppInstallerWithSchemaConversions{
+			mockAppInstaller: &mockAppInstaller{},
+			manifest:         manifest,
+			conversions: []resource.SchemaConversion{
+				{Group: "test.grafana.app", Resource: "foos", From: "v0alpha1", To: "v1", Convert: convert},
+			},
+		}
+		require.NoError(t, registerSchemaConversions(installer, migrations))
+		assert.True(t, migrations.Handles("test.grafana.app", "foos"))
+	})
+
+	t.Run("rejects conversions of another group", func(t *testing.T) {
+		migrations := resource.NewSchemaMigrations()
+		installer := &mockAppInstallerWithSchemaConversions{
+			mockAppInstaller: &mockAppInstaller{},
+			manifest:         manifest,
+			conversions: []resource.SchemaConversion{
+				{Group: "other.grafana.app", Resource: "foos", From: "v0alpha1", To: "v1", Convert: convert},
+			},
+		}
+		require.Error(t, registerSchemaConversions(installer, migrations))
+		assert.False(t, migrations.Handles("other.grafana.app", "foos"))
+	})
+}
+
+type mockAppInstallerWithSchemaConversions struct {
+	*mockAppInstaller
+	manifest    *app.ManifestData
+	conversions []resource.SchemaConversion
+}
+
+func (m *mockAppInstallerWithSchemaConversions) ManifestData() *app.ManifestData {
+	return m.manifest
+}
+
+func (m *mockAppInstallerWithSchemaConversions) GetSchemaConversions() []resource.SchemaConversion {
+	return m.conversions
+}
@@ -312,6 +312,71 @@ func newAlertmanagerImportsTranslation() translation {
 	}
 }
 
+// newAlertRuleTranslation maps the rules.alerting.grafana.app resources to the
+// alert.rules actions. Rule permissions are granted on the containing folder, so
+// the check relies on folder inheritance rather than a per-rule scope.
+// Only the verbs used by git sync and export are mapped; they must stay in line with
+// the rules.alerting.grafana.app entries of the provisioning identity token.
+func newAlertRuleTranslation() translation {
+	return translation{
+		resource:  "alert.rules",
+		attribute: "uid",
+		verbMapping: map[string]string{
+			utils.VerbGet:    accesscontrol.ActionAlertingRuleRead,
+			utils.VerbList:   accesscontrol.ActionAlertingRuleRead,
+			utils.VerbCreate: accesscontrol.ActionAlertingRuleCreate,
+			utils.VerbUpdate: accesscontrol.ActionAlertingRuleUpdate,
+			utils.VerbPatch:  accesscontrol.ActionAlertingRuleUpdate,
+			utils.VerbDelete: accesscontrol.ActionAlertingRuleDelete,
+		},
+		folderSupport: true,
+	}
+}
+
+// newReceiverTranslation maps the notifications.alerting.grafana.app receivers
+// resource to the receiver actions, which are scoped by receivers:uid:<uid>.
+func newReceiverTranslation() translation {
+	return translation{
+		resource:  "receivers",
+		attribute: "uid",
+		verbMapping: map[string]string{
+			utils.VerbGet:              accesscontrol.ActionAlertingReceiversRead,
+			utils.VerbList:             accesscontrol.ActionAlertingReceiversRead,
+			utils.VerbWatch:            accesscontrol.ActionAlertingReceiversRead,
+			utils.VerbCreate:           accesscontrol.ActionAlertingReceiversCreate,
+			utils.VerbUpdate:           accesscontrol.ActionAlertingReceiversUpdate,
+			utils.VerbPatch:            accesscontrol.ActionAlertingReceiversUpdate,
+			utils.VerbDelete:           accesscontrol.ActionAlertingReceiversDelete,
+			utils.VerbDeleteCollection: accesscontrol.ActionAlertingReceiversDelete,
+			utils.VerbGetPermissions:   accesscontrol.ActionAlertingReceiversPermissionsRead,
+			utils.VerbSetPermissions:   accesscontrol.ActionAlertingReceiversPermissionsWrite,
+		},
+		folderSupport:   false,
+		skipScopeOnVerb: map[string]bool{utils.VerbCreate: true},
+	}
+}
+
+// newUnscopedAlertingTranslation maps alerting notification resources whose
+// actions are not scoped (templates and time intervals), so every verb skips scope.
+func newUnscopedAlertingTranslation(resource, read, write, del string, skipScope map[string]bool) translation {
+	return translation{
+		resource:  resource,
+		attribute: "uid",
+		verbMapping: map[string]string{
+			utils.VerbGet:              read,
+			utils.VerbList:             read,
+			utils.VerbWatch:            read,
+			utils.VerbCreate:           write,
+			utils.VerbUpdate:           write,
+			utils.VerbPatch:            write,
+			utils.VerbDelete:           del,
+			utils.VerbDeleteCollection: del,
+		},
+		folderSupport:   false,
+		skipScopeOnVerb: skipScope,
+	}
+}
+
 func NewMapperRegistry() MapperRegistry {
 	skipScopeOnAllVerbs := map[string]bool{
 		utils.VerbCreate:           true,
@@ -330,6 +395,24 @@ func NewMapperRegistry() MapperRegistry {
 		"notifications.alerting.grafana.app": {
 			"routingtrees":        newRoutingTreeTranslation(),
 			"alertmanagerimports": newAlertmanagerImportsTranslation(),
+			"receivers":           newReceiverTranslation(),
+			"templategroups": newUnscopedAlertingTranslation("alert.notifications.templates",
+				accesscontrol.ActionAlertingNotificationsTemplatesRead,
+				accesscontrol.ActionAlertingNotificationsTemplatesWrite,
+				accesscontrol.ActionAlertingNotificationsTemplatesDelete,
+				skipScopeOnAllVerbs),
+			"timeintervals": newUnscopedAlertingTranslation("alert.notifications.time-intervals",
+				accesscontrol.ActionAlertingNotificationsTimeIntervalsRead,
+				accesscontrol.ActionAlertingNotificationsTimeIntervalsWrite,
+				accesscontrol.ActionAlertingNotificationsTimeIntervalsDelete,
+				skipScopeOnAllVerbs),
+		},
+		// Alert rules are authorized here so the provisioning authorizer and export
+		// preflight can check them like other git synced kinds.
+		"rules.alerting.grafana.app": {
+			"alertrules":     newAlertRuleTranslation(),
+			"recordingrules": newAlertRuleTranslation(),
+			"rulesequences":  newAlertRuleTranslation(),
 		},
 		"dashboard.grafana.app": {
 			"dashboards":    newDashboardTranslation(),
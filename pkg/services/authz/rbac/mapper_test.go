@@ -69,6 +69,48 @@ func TestMapperRegistry_Playlist(t *testing.T) {
 	assert.False(t, mapping.HasFolderSupport(), "playlists are not folder-scoped")
 }
 
+func TestMapperRegistry_Alerting(t *testing.T) {
+	reg := NewMapperRegistry()
+
+	for _, resource := range []string{"alertrules", "recordingrules", "rulesequences"} {
+		mapping, ok := reg.Get("rules.alerting.grafana.app", resource, "")
+		require.True(t, ok, "%s should be registered in the mapper", resource)
+
+		action, ok := mapping.Action(utils.VerbGet)
+		assert.True(t, ok)
+		assert.Equal(t, "alert.rules:read", action)
+		action, ok = mapping.Action(utils.VerbCreate)
+		assert.True(t, ok)
+		assert.Equal(t, "alert.rules:create", action)
+		_, ok = mapping.Action(utils.VerbWatch)
+		assert.False(t, ok, "only the verbs used by git sync are mapped")
+		assert.True(t, mapping.HasFolderSupport(), "rule permissions are inherited from the folder")
+	}
+
+	receivers, ok := reg.Get("notifications.alerting.grafana.app", "receivers", "")
+	require.True(t, ok)
+	action, ok := receivers.Action(utils.VerbUpdate)
+	assert.True(t, ok)
+	assert.Equal(t, "alert.notifications.receivers:write", action)
+	assert.Equal(t, "receivers:uid:r1", receivers.Scope("r1"))
+	assert.True(t, receivers.SkipScope(utils.VerbCreate))
+	assert.False(t, receivers.SkipScope(utils.VerbGet))
+
+	for resource, write := range map[string]string{
+		"templategroups": "alert.notifications.templates:write",
+		"timeintervals":  "alert.notifications.time-intervals:write",
+	} {
+		mapping, ok := reg.Get("notifications.alerting.grafana.app", resource, "")
+		require.True(t, ok, "%s should be registered in the mapper", resource)
+		action, ok := mapping.Action(utils.VerbCreate)
+		assert.True(t, ok)
+		assert.Equal(t, write, action)
+		for _, verb := range []string{utils.VerbGet, utils.VerbCreate, utils.VerbUpdate, utils.VerbDelete} {
+			assert.True(t, mapping.SkipScope(verb), "%s actions are not scoped", resource)
+		}
+	}
+}
+
 // TestFindGroupKey_WildcardMatching exercises findGroupKey via a minimal mapper.
 // It covers: exact match, wildcard match, group starts with *, key not wildcard (continue),
 // suffix mismatch, empty group, and no match.
@@ -0,0 +1,30 @@
+package caching
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *OSSCachingService) registerAPIEndpoints(routeRegister routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	uidScope := datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	routeRegister.Group("/api/datasources/uid/:uid/cache", func(cacheRoute routing.RouteRegister) {
+		cacheRoute.Delete("/", authorize(ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.invalidateHandler))
+	})
+}
+
+// invalidateHandler drops the cached query and resource responses of a data source
+func (s *OSSCachingService) invalidateHandler(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if err := s.Invalidate(c.Req.Context(), c.GetOrgID(), uid); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to invalidate data source cache", err)
+	}
+	return response.Success("Data source cache invalidated")
+}
@@ -0,0 +1,434 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+)
+
+const bucketKeyPrefix = "query-bucket"
+
+// incrementalDatasources are the time-series datasources whose range queries can be split in time buckets
+var incrementalDatasources = map[string]bool{
+	"prometheus": true,
+	"loki":       true,
+	"influxdb":   true,
+}
+
+// rangeDependentRegExp matches the variables and functions whose result depends on the whole time range of the
+// query, so that a bucket would not hold the same points as the full range: the $__range variables, and the
+// functions accumulating or differencing the series from the start of the range, in Graphite and InfluxQL.
+var rangeDependentRegExp = regexp.MustCompile(`\$\{?__range(_s|_ms)?\b|(?i:\b(integral|derivative|nonNegativeDerivative|summarize|non_negative_derivative|difference|non_negative_difference|cumulative_sum|moving_average)\s*\()`)
+
+var errNotTimeSeries = errors.New("response is not a time series")
+
+// timeRange is a time range in unix milliseconds, from inclusive and to exclusive
+type timeRange struct {
+	from int64
+	to   int64
+}
+
+// bucketCacheKey identifies the cached frames of a query in one time bucket
+type bucketCacheKey struct {
+	OrgID             int64           `json:"orgId"`
+	PluginID          string          `json:"pluginId"`
+	DatasourceUID     string          `json:"datasourceUid"`
+	DatasourceUpdated int64           `json:"datasourceUpdated"`
+	Generation        string          `json:"generation"`
+	User              string          `json:"user,omitempty"`
+	QueryType         string          `json:"queryType"`
+	IntervalMS        int64           `json:"intervalMs"`
+	ResolutionMS      int64           `json:"resolutionMs"`
+	JSON              json.RawMessage `json:"json"`
+	From              int64           `json:"from"`
+	To                int64           `json:"to"`
+}
+
+// incrementalQuery is the plan of a query split in time buckets
+type incrementalQuery struct {
+	query backend.DataQuery
+	timeRange
+	// buckets are the aligned time buckets covering the time range of the query
+	buckets []*queryBucket
+	// segments cover the time range in order, either with a cached bucket or with a range to query
+	segments []*querySegment
+}
+
+type queryBucket struct {
+	timeRange
+	// key is empty for buckets that are too recent to be cached
+	key string
+}
+
+type querySegment struct {
+	timeRange
+	cached bool
+	frames data.Frames
+}
+
+// HandleIncrementalQueryRequest answers range queries of time-series datasources from the cached time buckets,
+// and only queries the buckets that are missing or too recent to be cached.
+func (s *OSSCachingService) HandleIncrementalQueryRequest(ctx context.Context, req *backend.QueryDataRequest, query QueryDataFn) (bool, *backend.QueryDataResponse, CacheStatus, error) {
+	ds := req.PluginContext.DataSourceInstanceSettings
+	if !s.settings.IncrementalEnabled || s.settings.IncrementalBucketSize <= 0 || ds == nil || !incrementalDatasources[ds.Type] {
+		return false, nil, StatusDisabled, nil
+	}
+	if len(req.Queries) == 0 || s.queryTTL(ds) <= 0 || skipCache(ctx) {
+		return false, nil, StatusDisabled, nil
+	}
+	caller, ok := s.cacheIdentity(ctx, req.PluginContext)
+	if !ok {
+		return false, nil, StatusDisabled, nil
+	}
+	for _, q := range req.Queries {
+		if !s.isIncrementalQuery(ds, q) {
+			return false, nil, StatusDisabled, nil
+		}
+	}
+
+	generation, err := s.generation(ctx, req.PluginContext.OrgID, ds.UID)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to read cache generation", "datasource", ds.UID, "error", err)
+		return false, nil, StatusError, nil
+	}
+
+	now := time.Now()
+	plans := make([]*incrementalQuery, 0, len(req.Queries))
+	cachedSegments := 0
+	for _, q := range req.Queries {
+		plan, err := s.planIncrementalQuery(ctx, req.PluginContext, caller, generation, q, now)
+		if err != nil {
+			s.log.FromContext(ctx).Warn("Failed to plan incremental query", "datasource", ds.UID, "error", err)
+			return false, nil, StatusError, nil
+		}
+		for _, segment := range plan.segments {
+			if segment.cached {
+				cachedSegments++
+			}
+		}
+		plans = append(plans, plan)
+	}
+
+	// Queries missing the same time range are sent in a single request
+	requests := map[timeRange]*backend.QueryDataRequest{}
+	ranges := []timeRange{}
+	for _, plan := range plans {
+		for _, segment := range plan.segments {
+			if segment.cached {
+				continue
+			}
+			sub, ok := requests[segment.timeRange]
+			if !ok {
+				sub = &backend.QueryDataRequest{PluginContext: req.PluginContext, Headers: req.Headers}
+				requests[segment.timeRange] = sub
+				ranges = append(ranges, segment.timeRange)
+			}
+			sub.Queries = append(sub.Queries, plan.subQuery(segment.timeRange))
+		}
+	}
+
+	responses := make(map[timeRange]*backend.QueryDataResponse, len(ranges))
+	for _, r := range ranges {
+		resp, err := query(ctx, requests[r])
+		if err != nil {
+			return true, nil, StatusError, err
+		}
+		responses[r] = resp
+	}
+
+	resp := backend.NewQueryDataResponse()
+	for _, plan := range plans {
+		dr, err := s.completeIncrementalQuery(ctx, plan, responses)
+		if errors.Is(err, errNotTimeSeries) {
+			// The cached buckets can't be stitched with the response, query the whole time range instead
+			full, err := query(ctx, &backend.QueryDataRequest{PluginContext: req.PluginContext, Headers: req.Headers, Queries: []backend.DataQuery{plan.query}})
+			if err != nil {
+				return true, nil, StatusError, err
+			}
+			if full != nil {
+				dr = full.Responses[plan.query.RefID]
+			}
+		}
+		resp.Responses[plan.query.RefID] = dr
+	}
+
+	switch {
+	case cachedSegments == 0:
+		return true, resp, StatusMiss, nil
+	case len(ranges) == 0:
+		return true, resp, StatusHit, nil
+	default:
+		return true, resp, StatusPartial, nil
+	}
+}
+
+// isIncrementalQuery reports whether the query is a range query over a time range longer than a bucket
+func (s *OSSCachingService) isIncrementalQuery(ds *backend.DataSourceInstanceSettings, q backend.DataQuery) bool {
+	if q.TimeRange.To.Sub(q.TimeRange.From) <= s.settings.IncrementalBucketSize {
+		return false
+	}
+
+	var model struct {
+		QueryType    string `json:"queryType"`
+		Instant      bool   `json:"instant"`
+		Exemplar     bool   `json:"exemplar"`
+		Format       string `json:"format"`
+		ResultFormat string `json:"resultFormat"`
+	}
+	if err := json.Unmarshal(q.JSON, &model); err != nil {
+		return false
+	}
+	if rangeDependentRegExp.Match(q.JSON) {
+		return false
+	}
+
+	switch ds.Type {
+	case "prometheus":
+		return !model.Instant && !model.Exemplar && model.Format != "table"
+	case "loki":
+		// Log queries are detected from their response, which is not a time series
+		return model.QueryType == "" || model.QueryType == "range"
+	case "influxdb":
+		var jsonData struct {
+			Version string `json:"version"`
+		}
+		if len(ds.JSONData) > 0 {
+			if err := json.Unmarshal(ds.JSONData, &jsonData); err != nil {
+				return false
+			}
+		}
+		return (jsonData.Version == "" || jsonData.Version == "InfluxQL") && (model.ResultFormat == "" || model.ResultFormat == "time_series")
+	default:
+		return true
+	}
+}
+
+// planIncrementalQuery splits the time range of the query in aligned buckets and reads the cached buckets
+func (s *OSSCachingService) planIncrementalQuery(ctx context.Context, pCtx backend.PluginContext, caller, generation string, q backend.DataQuery, now time.Time) (*incrementalQuery, error) {
+	ds := pCtx.DataSourceInstanceSettings
+	size := s.settings.IncrementalBucketSize.Milliseconds()
+	cacheBefore := now.Add(-s.settings.IncrementalRecentWindow).UnixMilli()
+
+	plan := &incrementalQuery{
+		query:     q,
+		timeRange: timeRange{from: q.TimeRange.From.UnixMilli(), to: q.TimeRange.To.UnixMilli()},
+	}
+
+	key := bucketCacheKey{
+		OrgID:             pCtx.OrgID,
+		PluginID:          pCtx.PluginID,
+		DatasourceUID:     ds.UID,
+		DatasourceUpdated: ds.Updated.UnixMilli(),
+		Generation:        generation,
+		User:              caller,
+		QueryType:         q.QueryType,
+		IntervalMS:        q.Interval.Milliseconds(),
+		JSON:              q.JSON,
+	}
+	// The datasource picks the resolution from the length of the time range, buckets are only shared by queries with the same resolution
+	if q.MaxDataPoints > 0 {
+		key.ResolutionMS = (plan.to - plan.from) / q.MaxDataPoints / 1000 * 1000
+	}
+
+	anyCached := false
+	for start := plan.from - plan.from%size; start < plan.to; start += size {
+		bucket := &queryBucket{timeRange: timeRange{from: start, to: start + size}}
+		plan.buckets = append(plan.buckets, bucket)
+
+		if bucket.to > cacheBefore {
+			plan.segments = append(plan.segments, &querySegment{timeRange: bucket.timeRange})
+			continue
+		}
+
+		key.From, key.To = bucket.from, bucket.to
+		k, err := GetKey(strconv.FormatInt(pCtx.OrgID, 10), bucketKeyPrefix, key)
+		if err != nil {
+			return nil, err
+		}
+		bucket.key = k
+
+		frames, ok := s.cachedBucket(ctx, k)
+		anyCached = anyCached || ok
+		plan.segments = append(plan.segments, &querySegment{timeRange: bucket.timeRange, cached: ok, frames: frames})
+	}
+
+	// Adjacent missing buckets are queried together
+	segments := make([]*querySegment, 0, len(plan.segments))
+	for _, segment := range plan.segments {
+		if last := len(segments) - 1; last >= 0 && !segment.cached && !segments[last].cached {
+			segments[last].to = segment.to
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	for _, segment := range segments {
+		if segment.cached {
+			continue
+		}
+		segment.to = min(segment.to, plan.to)
+		// Until the query is known to return time series, only its own time range is queried.
+		// Afterwards the first bucket is queried whole, so it can be cached.
+		if !anyCached {
+			segment.from = max(segment.from, plan.from)
+		}
+	}
+	plan.segments = segments
+
+	return plan, nil
+}
+
+// subQuery returns the query restricted to the time range, keeping the resolution of the whole time range
+func (plan *incrementalQuery) subQuery(r timeRange) backend.DataQuery {
+	q := plan.query
+	q.TimeRange = backend.TimeRange{From: time.UnixMilli(r.from).UTC(), To: time.UnixMilli(r.to).UTC()}
+	if length := plan.to - plan.from; q.MaxDataPoints > 0 && length > 0 {
+		q.MaxDataPoints = max(1, q.MaxDataPoints*(r.to-r.from)/length)
+	}
+	return q
+}
+
+// completeIncrementalQuery caches the queried buckets and stitches the segments of the query together
+func (s *OSSCachingService) completeIncrementalQuery(ctx context.Context, plan *incrementalQuery, responses map[timeRange]*backend.QueryDataResponse) (backend.DataResponse, error) {
+	pieces := make([]data.Frames, 0, len(plan.segments))
+	for _, segment := range plan.segments {
+		frames := segment.frames
+		if !segment.cached {
+			resp := responses[segment.timeRange]
+			if resp == nil {
+				return backend.ErrDataResponse(backend.StatusInternal, "missing response of the query"), nil
+			}
+			dr, ok := resp.Responses[plan.query.RefID]
+			if !ok {
+				return backend.ErrDataResponse(backend.StatusInternal, "missing response of the query"), nil
+			}
+			if dr.Error != nil {
+				return dr, nil
+			}
+			if !isTimeSeries(dr.Frames) {
+				if len(plan.segments) == 1 && segment.timeRange == plan.timeRange {
+					return dr, nil
+				}
+				return backend.DataResponse{}, errNotTimeSeries
+			}
+			frames = dr.Frames
+			s.storeBuckets(ctx, plan, segment.timeRange, frames)
+		}
+
+		inclusive := segment.to >= plan.to
+		pieces = append(pieces, sliceFrames(frames, max(segment.from, plan.from), min(segment.to, plan.to), inclusive))
+	}
+
+	return backend.DataResponse{Frames: mergeFrames(pieces)}, nil
+}
+
+// storeBuckets caches the buckets fully covered by the queried time range
+func (s *OSSCachingService) storeBuckets(ctx context.Context, plan *incrementalQuery, queried timeRange, frames data.Frames) {
+	for _, bucket := range plan.buckets {
+		if bucket.key == "" || bucket.from < queried.from || bucket.to > queried.to {
+			continue
+		}
+		s.store(ctx, bucket.key, sliceFrames(frames, bucket.from, bucket.to, false), s.settings.IncrementalTTL)
+	}
+}
+
+func (s *OSSCachingService) cachedBucket(ctx context.Context, key string) (data.Frames, bool) {
+	cached, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			s.log.FromContext(ctx).Warn("Failed to read cached time bucket", "error", err)
+		}
+		return nil, false
+	}
+
+	frames := data.Frames{}
+	if err := json.Unmarshal(cached, &frames); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to decode cached time bucket", "error", err)
+		return nil, false
+	}
+	return frames, true
+}
+
+// isTimeSeries reports whether every frame has a single time field and numeric values
+func isTimeSeries(frames data.Frames) bool {
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 {
+			continue
+		}
+		if len(frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)) != 1 {
+			return false
+		}
+		for _, field := range frame.Fields {
+			if !field.Type().Time() && !field.Type().Numeric() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sliceFrames returns the rows of the frames within the time range. Frames without rows in the range are dropped.
+func sliceFrames(frames data.Frames, from, to int64, inclusive bool) data.Frames {
+	sliced := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		idx := frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+		if len(idx) != 1 {
+			continue
+		}
+
+		out := frame.EmptyCopy()
+		for i := 0; i < frame.Rows(); i++ {
+			v, ok := frame.Fields[idx[0]].ConcreteAt(i)
+			if !ok {
+				continue
+			}
+			ts := v.(time.Time).UnixMilli()
+			if ts >= from && (ts < to || inclusive && ts == to) {
+				out.AppendRow(frame.RowCopy(i)...)
+			}
+		}
+		if out.Rows() > 0 {
+			sliced = append(sliced, out)
+		}
+	}
+	return sliced
+}
+
+// mergeFrames concatenates the rows of the frames of the same series, in the order of the pieces
+func mergeFrames(pieces []data.Frames) data.Frames {
+	merged := data.Frames{}
+	series := map[string]*data.Frame{}
+	for _, frames := range pieces {
+		for _, frame := range frames {
+			key := seriesKey(frame)
+			out, ok := series[key]
+			if !ok {
+				out = frame.EmptyCopy()
+				series[key] = out
+				merged = append(merged, out)
+			}
+			for i := 0; i < frame.Rows(); i++ {
+				out.AppendRow(frame.RowCopy(i)...)
+			}
+		}
+	}
+	return merged
+}
+
+func seriesKey(frame *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		fmt.Fprintf(&sb, "\x00%s\x00%s\x00%s", field.Name, field.Type(), field.Labels.String())
+	}
+	return sb.String()
+}
@@ -0,0 +1,197 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeTimeSeriesDatasource struct {
+	requests []*backend.QueryDataRequest
+	logs     bool
+}
+
+// query returns a point per minute of the time range
+func (f *fakeTimeSeriesDatasource) query(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	f.requests = append(f.requests, req)
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		times := []time.Time{}
+		values := []float64{}
+		lines := []string{}
+		for t := q.TimeRange.From.Truncate(time.Minute); !t.After(q.TimeRange.To); t = t.Add(time.Minute) {
+			if t.Before(q.TimeRange.From) {
+				continue
+			}
+			times = append(times, t)
+			values = append(values, float64(t.Unix()/60))
+			lines = append(lines, t.String())
+		}
+		frame := data.NewFrame("up", data.NewField("time", nil, times), data.NewField("value", data.Labels{"job": "grafana"}, values))
+		if f.logs {
+			frame = data.NewFrame("logs", data.NewField("time", nil, times), data.NewField("line", nil, lines))
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
+	}
+	return resp, nil
+}
+
+// frameRows returns the rows of a time series frame, with times in unix milliseconds
+func frameRows(frame *data.Frame) [][]any {
+	rows := make([][]any, 0, frame.Rows())
+	for i := 0; i < frame.Rows(); i++ {
+		row := frame.RowCopy(i)
+		row[0] = row[0].(time.Time).UnixMilli()
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func newTestIncrementalRequest(dsType string, from, to time.Time, model string) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			OrgID:                      1,
+			PluginID:                   dsType,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "ds", Type: dsType},
+		},
+		Queries: []backend.DataQuery{{
+			RefID:         "A",
+			MaxDataPoints: 600,
+			TimeRange:     backend.TimeRange{From: from, To: to},
+			JSON:          json.RawMessage(model),
+		}},
+	}
+}
+
+func TestOSSCachingService_HandleIncrementalQueryRequest(t *testing.T) {
+	ctx := context.Background()
+	settings := setting.QueryCachingSettings{
+		Enabled:                 true,
+		TTL:                     time.Minute,
+		IncrementalEnabled:      true,
+		IncrementalBucketSize:   time.Hour,
+		IncrementalRecentWindow: 10 * time.Minute,
+		IncrementalTTL:          time.Hour,
+	}
+	to := time.Now().Truncate(time.Second)
+	from := to.Truncate(time.Hour).Add(-6 * time.Hour)
+
+	t.Run("only the recent part of the time range is queried once buckets are cached", func(t *testing.T) {
+		s, _ := newTestCachingService(t, settings)
+		ds := &fakeTimeSeriesDatasource{}
+		req := newTestIncrementalRequest("prometheus", from, to, `{"expr":"up"}`)
+
+		handled, first, status, err := s.HandleIncrementalQueryRequest(ctx, req, ds.query)
+		require.NoError(t, err)
+		require.True(t, handled)
+		require.Equal(t, StatusMiss, status)
+		require.Len(t, ds.requests, 1)
+		require.Equal(t, from.UnixMilli(), ds.requests[0].Queries[0].TimeRange.From.UnixMilli())
+
+		handled, second, status, err := s.HandleIncrementalQueryRequest(ctx, req, ds.query)
+		require.NoError(t, err)
+		require.True(t, handled)
+		require.Equal(t, StatusPartial, status)
+		require.Len(t, ds.requests, 2)
+
+		recent := ds.requests[1].Queries[0]
+		require.Equal(t, to.UnixMilli(), recent.TimeRange.To.UnixMilli())
+		require.LessOrEqual(t, recent.TimeRange.To.Sub(recent.TimeRange.From), time.Hour+10*time.Minute)
+		// The resolution of the whole time range is kept
+		require.Less(t, recent.MaxDataPoints, int64(600))
+
+		require.Len(t, second.Responses["A"].Frames, 1)
+		require.Equal(t, frameRows(first.Responses["A"].Frames[0]), frameRows(second.Responses["A"].Frames[0]))
+		require.Equal(t, data.Labels{"job": "grafana"}, second.Responses["A"].Frames[0].Fields[1].Labels)
+	})
+
+	t.Run("cached buckets are trimmed to a later time range", func(t *testing.T) {
+		s, _ := newTestCachingService(t, settings)
+		ds := &fakeTimeSeriesDatasource{}
+		_, _, _, err := s.HandleIncrementalQueryRequest(ctx, newTestIncrementalRequest("prometheus", from, to, `{"expr":"up"}`), ds.query)
+		require.NoError(t, err)
+
+		later := from.Add(30 * time.Minute)
+		_, resp, status, err := s.HandleIncrementalQueryRequest(ctx, newTestIncrementalRequest("prometheus", later, to, `{"expr":"up"}`), ds.query)
+		require.NoError(t, err)
+		require.Equal(t, StatusPartial, status)
+
+		frame := resp.Responses["A"].Frames[0]
+		rows := frameRows(frame)
+		require.Equal(t, later.UnixMilli(), rows[0][0])
+		require.Equal(t, to.Truncate(time.Minute).UnixMilli(), rows[len(rows)-1][0])
+	})
+
+	t.Run("time series of other queries are cached separately", func(t *testing.T) {
+		s, _ := newTestCachingService(t, settings)
+		ds := &fakeTimeSeriesDatasource{}
+		_, _, _, err := s.HandleIncrementalQueryRequest(ctx, newTestIncrementalRequest("prometheus", from, to, `{"expr":"up"}`), ds.query)
+		require.NoError(t, err)
+
+		_, _, status, err := s.HandleIncrementalQueryRequest(ctx, newTestIncrementalRequest("prometheus", from, to, `{"expr":"down"}`), ds.query)
+		require.NoError(t, err)
+		require.Equal(t, StatusMiss, status)
+	})
+
+	t.Run("responses that are not time series are not cached", func(t *testing.T) {
+		s, cache := newTestCachingService(t, settings)
+		ds := &fakeTimeSeriesDatasource{logs: true}
+		req := newTestIncrementalRequest("loki", from, to, `{"expr":"{job=\"grafana\"}"}`)
+
+		for i := 0; i < 2; i++ {
+			handled, resp, status, err := s.HandleIncrementalQueryRequest(ctx, req, ds.query)
+			require.NoError(t, err)
+			require.True(t, handled)
+			require.Equal(t, StatusMiss, status)
+			require.Equal(t, "logs", resp.Responses["A"].Frames[0].Name)
+		}
+		require.Empty(t, cache.Storage)
+	})
+
+	t.Run("error responses are not cached", func(t *testing.T) {
+		s, cache := newTestCachingService(t, settings)
+		failing := func(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			return &backend.QueryDataResponse{Responses: backend.Responses{
+				"A": backend.ErrDataResponse(backend.StatusBadRequest, "bad query"),
+			}}, nil
+		}
+
+		_, resp, _, err := s.HandleIncrementalQueryRequest(ctx, newTestIncrementalRequest("prometheus", from, to, `{"expr":"up"}`), failing)
+		require.NoError(t, err)
+		require.Error(t, resp.Responses["A"].Error)
+		require.Empty(t, cache.Storage)
+	})
+
+	t.Run("requests that can't be split are not handled", func(t *testing.T) {
+		s, _ := newTestCachingService(t, settings)
+		ds := &fakeTimeSeriesDatasource{}
+
+		for _, req := range []*backend.QueryDataRequest{
+			newTestIncrementalRequest("prometheus", from, to, `{"expr":"up","instant":true}`),
+			newTestIncrementalRequest("prometheus", to.Add(-30*time.Minute), to, `{"expr":"up"}`),
+			newTestIncrementalRequest("loki", from, to, `{"expr":"{job=\"grafana\"}","queryType":"instant"}`),
+			newTestIncrementalRequest("mysql", from, to, `{"rawSql":"SELECT 1"}`),
+			newTestIncrementalRequest("graphite", from, to, `{"target":"a.b.c"}`),
+			newTestIncrementalRequest("prometheus", from, to, `{"expr":"increase(up[$__range])"}`),
+			newTestIncrementalRequest("loki", from, to, `{"expr":"count_over_time({job=\"grafana\"}[${__range_s}s])"}`),
+			newTestIncrementalRequest("influxdb", from, to, `{"query":"SELECT DERIVATIVE(mean(value)) FROM cpu"}`),
+		} {
+			handled, _, _, err := s.HandleIncrementalQueryRequest(ctx, req, ds.query)
+			require.NoError(t, err)
+			require.False(t, handled)
+		}
+		require.Empty(t, ds.requests)
+
+		s.settings.IncrementalEnabled = false
+		handled, _, _, err := s.HandleIncrementalQueryRequest(ctx, newTestIncrementalRequest("prometheus", from, to, `{"expr":"up"}`), ds.query)
+		require.NoError(t, err)
+		require.False(t, handled)
+	})
+}
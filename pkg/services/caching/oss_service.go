@@ -0,0 +1,336 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-azure-sdk-go/v2/azcredentials"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	queryKeyPrefix      = "query"
+	resourceKeyPrefix   = "resource"
+	generationKeyPrefix = "caching-generation"
+)
+
+// OSSCachingService caches query and resource responses of datasources in the remote cache.
+type OSSCachingService struct {
+	settings setting.QueryCachingSettings
+	cache    remotecache.CacheStorage
+	log      log.Logger
+
+	// sendUserHeader is set when the login of the user is sent to every datasource
+	sendUserHeader bool
+}
+
+func ProvideCachingService(cfg *setting.Cfg, cache remotecache.CacheStorage, routeRegister routing.RouteRegister, accessControl ac.AccessControl) *OSSCachingService {
+	s := newOSSCachingService(cfg.QueryCaching, cache)
+	s.sendUserHeader = cfg.SendUserHeader
+
+	// Register routes only when caching is enabled
+	if s.settings.Enabled {
+		s.registerAPIEndpoints(routeRegister, accessControl)
+	}
+
+	return s
+}
+
+func newOSSCachingService(settings setting.QueryCachingSettings, cache remotecache.CacheStorage) *OSSCachingService {
+	return &OSSCachingService{
+		settings: settings,
+		cache:    cache,
+		log:      log.New("query-caching"),
+	}
+}
+
+var _ CachingService = &OSSCachingService{}
+
+// queryCacheKey identifies a cached query response. Time ranges are aligned to the interval of the
+// query, which the datasource aligns its points to, so refreshes of a relative time range within the
+// same interval share the cached response. Queries without an interval are keyed on their exact range.
+type queryCacheKey struct {
+	OrgID             int64         `json:"orgId"`
+	PluginID          string        `json:"pluginId"`
+	DatasourceUID     string        `json:"datasourceUid"`
+	DatasourceUpdated int64         `json:"datasourceUpdated"`
+	Generation        string        `json:"generation"`
+	User              string        `json:"user,omitempty"`
+	Queries           []cachedQuery `json:"queries"`
+}
+
+type cachedQuery struct {
+	RefID         string          `json:"refId"`
+	QueryType     string          `json:"queryType"`
+	MaxDataPoints int64           `json:"maxDataPoints"`
+	IntervalMS    int64           `json:"intervalMs"`
+	From          int64           `json:"from"`
+	To            int64           `json:"to"`
+	JSON          json.RawMessage `json:"json"`
+}
+
+type resourceCacheKey struct {
+	OrgID             int64  `json:"orgId"`
+	PluginID          string `json:"pluginId"`
+	DatasourceUID     string `json:"datasourceUid"`
+	DatasourceUpdated int64  `json:"datasourceUpdated"`
+	Generation        string `json:"generation"`
+	User              string `json:"user,omitempty"`
+	Method            string `json:"method"`
+	URL               string `json:"url"`
+	Body              []byte `json:"body,omitempty"`
+}
+
+func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse, CacheStatus) {
+	ds := req.PluginContext.DataSourceInstanceSettings
+	ttl := s.queryTTL(ds)
+	if ttl <= 0 {
+		return false, CachedQueryDataResponse{}, StatusDisabled
+	}
+	caller, ok := s.cacheIdentity(ctx, req.PluginContext)
+	if !ok || skipCache(ctx) {
+		return false, CachedQueryDataResponse{}, StatusBypass
+	}
+
+	generation, err := s.generation(ctx, req.PluginContext.OrgID, ds.UID)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to read cache generation", "datasource", ds.UID, "error", err)
+		return false, CachedQueryDataResponse{}, StatusError
+	}
+
+	key := queryCacheKey{
+		OrgID:             req.PluginContext.OrgID,
+		PluginID:          req.PluginContext.PluginID,
+		DatasourceUID:     ds.UID,
+		DatasourceUpdated: ds.Updated.UnixMilli(),
+		Generation:        generation,
+		User:              caller,
+		Queries:           make([]cachedQuery, 0, len(req.Queries)),
+	}
+	for _, q := range req.Queries {
+		key.Queries = append(key.Queries, cachedQuery{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			MaxDataPoints: q.MaxDataPoints,
+			IntervalMS:    q.Interval.Milliseconds(),
+			From:          q.TimeRange.From.Truncate(q.Interval).UnixMilli(),
+			To:            q.TimeRange.To.Truncate(q.Interval).UnixMilli(),
+			JSON:          q.JSON,
+		})
+	}
+
+	cacheKey, err := GetKey(strconv.FormatInt(key.OrgID, 10), queryKeyPrefix, key)
+	if err != nil {
+		return false, CachedQueryDataResponse{}, StatusError
+	}
+
+	update := func(ctx context.Context, resp *backend.QueryDataResponse) {
+		if resp == nil || hasQueryErrors(resp) {
+			return
+		}
+		s.store(ctx, cacheKey, resp, ttl)
+	}
+
+	cached, err := s.cache.Get(ctx, cacheKey)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			s.log.FromContext(ctx).Warn("Failed to read cached query response", "datasource", ds.UID, "error", err)
+		}
+		return false, CachedQueryDataResponse{UpdateCacheFn: update}, StatusMiss
+	}
+
+	resp := &backend.QueryDataResponse{}
+	if err := json.Unmarshal(cached, resp); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to decode cached query response", "datasource", ds.UID, "error", err)
+		return false, CachedQueryDataResponse{UpdateCacheFn: update}, StatusMiss
+	}
+	return true, CachedQueryDataResponse{Response: resp}, StatusHit
+}
+
+func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse, CacheStatus) {
+	ds := req.PluginContext.DataSourceInstanceSettings
+	if !s.settings.Enabled || ds == nil || s.settings.ResourcesTTL <= 0 || s.queryTTL(ds) <= 0 {
+		return false, CachedResourceDataResponse{}, StatusDisabled
+	}
+	caller, ok := s.cacheIdentity(ctx, req.PluginContext)
+	if !ok || req.Method != http.MethodGet || skipCache(ctx) {
+		return false, CachedResourceDataResponse{}, StatusBypass
+	}
+
+	generation, err := s.generation(ctx, req.PluginContext.OrgID, ds.UID)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to read cache generation", "datasource", ds.UID, "error", err)
+		return false, CachedResourceDataResponse{}, StatusError
+	}
+
+	cacheKey, err := GetKey(strconv.FormatInt(req.PluginContext.OrgID, 10), resourceKeyPrefix, resourceCacheKey{
+		OrgID:             req.PluginContext.OrgID,
+		PluginID:          req.PluginContext.PluginID,
+		DatasourceUID:     ds.UID,
+		DatasourceUpdated: ds.Updated.UnixMilli(),
+		Generation:        generation,
+		User:              caller,
+		Method:            req.Method,
+		URL:               req.URL,
+		Body:              req.Body,
+	})
+	if err != nil {
+		return false, CachedResourceDataResponse{}, StatusError
+	}
+
+	// Streamed responses are sent in several parts, only single responses are cached
+	responses := 0
+	update := func(ctx context.Context, resp *backend.CallResourceResponse) {
+		responses++
+		if responses > 1 {
+			_ = s.cache.Delete(ctx, cacheKey)
+			return
+		}
+		if resp == nil || resp.Status != http.StatusOK {
+			return
+		}
+		s.store(ctx, cacheKey, resp, s.settings.ResourcesTTL)
+	}
+
+	cached, err := s.cache.Get(ctx, cacheKey)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			s.log.FromContext(ctx).Warn("Failed to read cached resource response", "datasource", ds.UID, "error", err)
+		}
+		return false, CachedResourceDataResponse{UpdateCacheFn: update}, StatusMiss
+	}
+
+	resp := &backend.CallResourceResponse{}
+	if err := json.Unmarshal(cached, resp); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to decode cached resource response", "datasource", ds.UID, "error", err)
+		return false, CachedResourceDataResponse{UpdateCacheFn: update}, StatusMiss
+	}
+	return true, CachedResourceDataResponse{Response: resp}, StatusHit
+}
+
+// Invalidate drops every cached response of the datasource
+func (s *OSSCachingService) Invalidate(ctx context.Context, orgID int64, datasourceUID string) error {
+	generation := strconv.FormatInt(time.Now().UnixNano(), 10)
+	// The generation outlives every cached response it invalidates
+	return s.cache.Set(ctx, generationKey(orgID, datasourceUID), []byte(generation), 0)
+}
+
+// queryTTL returns the TTL of the datasource, or zero when its responses are not cached
+func (s *OSSCachingService) queryTTL(ds *backend.DataSourceInstanceSettings) time.Duration {
+	if !s.settings.Enabled || ds == nil {
+		return 0
+	}
+	if ttl, ok := s.settings.DatasourceTTLs[ds.UID]; ok {
+		return ttl
+	}
+	if ttl, ok := s.settings.DatasourceTTLs[ds.Type]; ok {
+		return ttl
+	}
+	return s.settings.TTL
+}
+
+func (s *OSSCachingService) generation(ctx context.Context, orgID int64, datasourceUID string) (string, error) {
+	generation, err := s.cache.Get(ctx, generationKey(orgID, datasourceUID))
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(generation), nil
+}
+
+func (s *OSSCachingService) store(ctx context.Context, key string, value any, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to encode response for caching", "error", err)
+		return
+	}
+	if s.settings.MaxValueSize > 0 && len(data) > s.settings.MaxValueSize {
+		s.log.FromContext(ctx).Debug("Response is too large to be cached", "size", len(data))
+		return
+	}
+	if err := s.cache.Set(ctx, key, data, ttl); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to cache response", "error", err)
+	}
+}
+
+func generationKey(orgID int64, datasourceUID string) string {
+	return fmt.Sprintf("%s:%d:%s", generationKeyPrefix, orgID, datasourceUID)
+}
+
+// skipCache reports whether the incoming request asked to bypass the cache with the X-Cache-Skip header
+func skipCache(ctx context.Context) bool {
+	reqCtx := contexthandler.FromContext(ctx)
+	return reqCtx != nil && reqCtx.SkipQueryCache
+}
+
+// cacheIdentity returns the identity of the caller the responses are cached for, and false when the
+// responses depend on a caller that is not known. Responses are shared between callers unless the
+// datasource forwards the identity of the user or restricts its data by team.
+func (s *OSSCachingService) cacheIdentity(ctx context.Context, pCtx backend.PluginContext) (string, bool) {
+	perUser, perTeam := s.sendUserHeader, false
+	if ds := pCtx.DataSourceInstanceSettings; ds != nil && len(ds.JSONData) > 0 {
+		var jsonData struct {
+			OAuthPassThru   bool     `json:"oauthPassThru"`
+			KeepCookies     []string `json:"keepCookies"`
+			TeamHTTPHeaders struct {
+				Headers map[string]json.RawMessage `json:"headers"`
+			} `json:"teamHttpHeaders"`
+			AzureCredentials struct {
+				AuthType string `json:"authType"`
+			} `json:"azureCredentials"`
+		}
+		if err := json.Unmarshal(ds.JSONData, &jsonData); err != nil {
+			// Without the settings, it is not known what the responses depend on
+			return "", false
+		}
+		authType := jsonData.AzureCredentials.AuthType
+		perUser = perUser || jsonData.OAuthPassThru || len(jsonData.KeepCookies) > 0 ||
+			authType == azcredentials.AzureAuthCurrentUserIdentity || authType == azcredentials.AzureAuthClientSecretObo
+		perTeam = len(jsonData.TeamHTTPHeaders.Headers) > 0
+	}
+	if !perUser && !perTeam {
+		return "", true
+	}
+
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		// Only the login of the user is known to the plugin, which is enough unless the teams matter
+		if perTeam || pCtx.User == nil || pCtx.User.Login == "" {
+			return "", false
+		}
+		return pCtx.User.Login, true
+	}
+	caller := requester.GetUID()
+	if perTeam {
+		// nolint:staticcheck
+		teams := slices.Clone(requester.GetTeams())
+		slices.Sort(teams)
+		caller += fmt.Sprintf(":teams=%v", teams)
+	}
+	return caller, true
+}
+
+func hasQueryErrors(resp *backend.QueryDataResponse) bool {
+	for _, r := range resp.Responses {
+		if r.Error != nil {
+			return true
+		}
+	}
+	return false
+}
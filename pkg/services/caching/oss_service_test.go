@@ -0,0 +1,290 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestCachingService(t *testing.T, settings setting.QueryCachingSettings) (*OSSCachingService, remotecache.FakeCacheStorage) {
+	t.Helper()
+	cache := remotecache.NewFakeCacheStorage()
+	return newOSSCachingService(settings, cache), cache
+}
+
+func newTestQueryRequest(from, to time.Time) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			OrgID:    1,
+			PluginID: "prometheus",
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+				UID:  "prom",
+				Type: "prometheus",
+			},
+		},
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			Interval:  time.Minute,
+			TimeRange: backend.TimeRange{From: from, To: to},
+			JSON:      json.RawMessage(`{"expr":"up"}`),
+		}},
+	}
+}
+
+func newTestQueryResponse() *backend.QueryDataResponse {
+	return &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: data.Frames{data.NewFrame("up", data.NewField("value", nil, []float64{1, 2}))}},
+	}}
+}
+
+func TestOSSCachingService_HandleQueryRequest(t *testing.T) {
+	ctx := context.Background()
+	enabled := setting.QueryCachingSettings{Enabled: true, TTL: time.Minute, ResourcesTTL: time.Minute}
+	now := time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC)
+
+	t.Run("disabled", func(t *testing.T) {
+		s, _ := newTestCachingService(t, setting.QueryCachingSettings{})
+		hit, cr, status := s.HandleQueryRequest(ctx, newTestQueryRequest(now.Add(-time.Hour), now))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Equal(t, StatusDisabled, status)
+	})
+
+	t.Run("miss then hit within the aligned time range", func(t *testing.T) {
+		s, _ := newTestCachingService(t, enabled)
+
+		hit, cr, status := s.HandleQueryRequest(ctx, newTestQueryRequest(now.Add(-time.Hour), now))
+		require.False(t, hit)
+		require.Equal(t, StatusMiss, status)
+		require.NotNil(t, cr.UpdateCacheFn)
+		cr.UpdateCacheFn(ctx, newTestQueryResponse())
+
+		// A refresh 30 seconds later reuses the response
+		later := now.Add(30 * time.Second)
+		hit, cr, status = s.HandleQueryRequest(ctx, newTestQueryRequest(later.Add(-time.Hour), later))
+		require.True(t, hit)
+		require.Equal(t, StatusHit, status)
+		require.Len(t, cr.Response.Responses["A"].Frames, 1)
+		require.Equal(t, "up", cr.Response.Responses["A"].Frames[0].Name)
+
+		// The next aligned range is a miss
+		later = now.Add(time.Minute)
+		hit, _, status = s.HandleQueryRequest(ctx, newTestQueryRequest(later.Add(-time.Hour), later))
+		require.False(t, hit)
+		require.Equal(t, StatusMiss, status)
+	})
+
+	t.Run("distinct time ranges within the TTL miss each other", func(t *testing.T) {
+		s, _ := newTestCachingService(t, setting.QueryCachingSettings{Enabled: true, TTL: time.Hour})
+
+		_, cr, _ := s.HandleQueryRequest(ctx, newTestQueryRequest(now.Add(-time.Hour), now))
+		cr.UpdateCacheFn(ctx, newTestQueryResponse())
+
+		later := now.Add(5 * time.Minute)
+		hit, _, status := s.HandleQueryRequest(ctx, newTestQueryRequest(later.Add(-time.Hour), later))
+		require.False(t, hit)
+		require.Equal(t, StatusMiss, status)
+
+		hit, _, _ = s.HandleQueryRequest(ctx, newTestQueryRequest(now.Add(-30*time.Minute), now))
+		require.False(t, hit)
+
+		// Without an interval, the range is matched exactly
+		req := newTestQueryRequest(now.Add(-time.Hour), now)
+		req.Queries[0].Interval = 0
+		_, cr, _ = s.HandleQueryRequest(ctx, req)
+		cr.UpdateCacheFn(ctx, newTestQueryResponse())
+
+		req = newTestQueryRequest(now.Add(-time.Hour), now.Add(time.Second))
+		req.Queries[0].Interval = 0
+		hit, _, _ = s.HandleQueryRequest(ctx, req)
+		require.False(t, hit)
+	})
+
+	t.Run("responses with errors are not cached", func(t *testing.T) {
+		s, cache := newTestCachingService(t, enabled)
+		_, cr, _ := s.HandleQueryRequest(ctx, newTestQueryRequest(now.Add(-time.Hour), now))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": backend.ErrDataResponse(backend.StatusBadRequest, "bad query"),
+		}})
+		require.Empty(t, cache.Storage)
+	})
+
+	t.Run("responses larger than the max value size are not cached", func(t *testing.T) {
+		s, cache := newTestCachingService(t, setting.QueryCachingSettings{Enabled: true, TTL: time.Minute, MaxValueSize: 10})
+		_, cr, _ := s.HandleQueryRequest(ctx, newTestQueryRequest(now.Add(-time.Hour), now))
+		cr.UpdateCacheFn(ctx, newTestQueryResponse())
+		require.Empty(t, cache.Storage)
+	})
+
+	t.Run("datasource TTL overrides", func(t *testing.T) {
+		s, _ := newTestCachingService(t, setting.QueryCachingSettings{
+			Enabled:        true,
+			TTL:            time.Minute,
+			DatasourceTTLs: map[string]time.Duration{"prometheus": 0},
+		})
+		_, _, status := s.HandleQueryRequest(ctx, newTestQueryRequest(now.Add(-time.Hour), now))
+		require.Equal(t, StatusDisabled, status)
+
+		s.settings.DatasourceTTLs["prom"] = time.Hour
+		require.Equal(t, time.Hour, s.queryTTL(&backend.DataSourceInstanceSettings{UID: "prom", Type: "prometheus"}))
+	})
+
+	t.Run("requests can skip the cache", func(t *testing.T) {
+		s, _ := newTestCachingService(t, enabled)
+		reqCtx := &contextmodel.ReqContext{SkipQueryCache: true}
+
+		hit, _, status := s.HandleQueryRequest(context.WithValue(ctx, ctxkey.Key{}, reqCtx), newTestQueryRequest(now.Add(-time.Hour), now))
+		require.False(t, hit)
+		require.Equal(t, StatusBypass, status)
+	})
+
+	t.Run("invalidation drops cached responses of the datasource", func(t *testing.T) {
+		s, _ := newTestCachingService(t, enabled)
+		_, cr, _ := s.HandleQueryRequest(ctx, newTestQueryRequest(now.Add(-time.Hour), now))
+		cr.UpdateCacheFn(ctx, newTestQueryResponse())
+
+		require.NoError(t, s.Invalidate(ctx, 1, "prom"))
+
+		hit, _, status := s.HandleQueryRequest(ctx, newTestQueryRequest(now.Add(-time.Hour), now))
+		require.False(t, hit)
+		require.Equal(t, StatusMiss, status)
+	})
+
+	t.Run("users share responses unless the datasource forwards their identity", func(t *testing.T) {
+		s, _ := newTestCachingService(t, enabled)
+		req := newTestQueryRequest(now.Add(-time.Hour), now)
+		req.PluginContext.User = &backend.User{Login: "alice"}
+		req.PluginContext.DataSourceInstanceSettings.JSONData = json.RawMessage(`{"oauthPassThru":true}`)
+		_, cr, _ := s.HandleQueryRequest(ctx, req)
+		cr.UpdateCacheFn(ctx, newTestQueryResponse())
+
+		req.PluginContext.User = &backend.User{Login: "bob"}
+		hit, _, _ := s.HandleQueryRequest(ctx, req)
+		require.False(t, hit)
+
+		req.PluginContext.User = &backend.User{Login: "alice"}
+		hit, _, _ = s.HandleQueryRequest(ctx, req)
+		require.True(t, hit)
+	})
+
+	t.Run("responses of datasources depending on the caller are cached per caller", func(t *testing.T) {
+		for name, jsonData := range map[string]string{
+			"forwarded cookies":  `{"keepCookies":["session"]}`,
+			"azure current user": `{"azureCredentials":{"authType":"currentuser"}}`,
+			"team headers":       `{"teamHttpHeaders":{"headers":{"1":[{"header":"X-Prom-Label-Policy","value":"1:{env=\"dev\"}"}]}}}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				s, _ := newTestCachingService(t, enabled)
+				req := newTestQueryRequest(now.Add(-time.Hour), now)
+				req.PluginContext.DataSourceInstanceSettings.JSONData = json.RawMessage(jsonData)
+				alice := identity.WithRequester(ctx, &user.SignedInUser{UserUID: "alice", TeamIDs: []int64{1}})
+				_, cr, status := s.HandleQueryRequest(alice, req)
+				require.Equal(t, StatusMiss, status)
+				cr.UpdateCacheFn(alice, newTestQueryResponse())
+
+				hit, _, _ := s.HandleQueryRequest(identity.WithRequester(ctx, &user.SignedInUser{UserUID: "bob", TeamIDs: []int64{1}}), req)
+				require.False(t, hit)
+
+				hit, _, _ = s.HandleQueryRequest(identity.WithRequester(ctx, &user.SignedInUser{UserUID: "alice", TeamIDs: []int64{1}}), req)
+				require.True(t, hit)
+			})
+		}
+
+		t.Run("team changes are not served the responses of other teams", func(t *testing.T) {
+			s, _ := newTestCachingService(t, enabled)
+			req := newTestQueryRequest(now.Add(-time.Hour), now)
+			req.PluginContext.DataSourceInstanceSettings.JSONData = json.RawMessage(`{"teamHttpHeaders":{"headers":{"1":[]}}}`)
+			alice := identity.WithRequester(ctx, &user.SignedInUser{UserUID: "alice", TeamIDs: []int64{1}})
+			_, cr, _ := s.HandleQueryRequest(alice, req)
+			cr.UpdateCacheFn(alice, newTestQueryResponse())
+
+			hit, _, _ := s.HandleQueryRequest(identity.WithRequester(ctx, &user.SignedInUser{UserUID: "alice", TeamIDs: []int64{2}}), req)
+			require.False(t, hit)
+		})
+
+		t.Run("unknown callers are not cached", func(t *testing.T) {
+			s, cache := newTestCachingService(t, enabled)
+			req := newTestQueryRequest(now.Add(-time.Hour), now)
+			req.PluginContext.User = &backend.User{Login: "alice"}
+			req.PluginContext.DataSourceInstanceSettings.JSONData = json.RawMessage(`{"teamHttpHeaders":{"headers":{"1":[]}}}`)
+			hit, cr, status := s.HandleQueryRequest(ctx, req)
+			require.False(t, hit)
+			require.Nil(t, cr.UpdateCacheFn)
+			require.Equal(t, StatusBypass, status)
+			require.Empty(t, cache.Storage)
+		})
+
+		t.Run("the user header is sent to every datasource", func(t *testing.T) {
+			s, _ := newTestCachingService(t, enabled)
+			s.sendUserHeader = true
+			_, _, status := s.HandleQueryRequest(ctx, newTestQueryRequest(now.Add(-time.Hour), now))
+			require.Equal(t, StatusBypass, status)
+		})
+	})
+}
+
+func TestOSSCachingService_HandleResourceRequest(t *testing.T) {
+	ctx := context.Background()
+	s, cache := newTestCachingService(t, setting.QueryCachingSettings{Enabled: true, TTL: time.Minute, ResourcesTTL: time.Minute})
+
+	newRequest := func(method string) *backend.CallResourceRequest {
+		return &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				PluginID:                   "prometheus",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "prom", Type: "prometheus"},
+			},
+			Method: method,
+			URL:    "api/v1/labels",
+		}
+	}
+
+	_, _, status := s.HandleResourceRequest(ctx, newRequest(http.MethodPost))
+	require.Equal(t, StatusBypass, status)
+
+	hit, cr, status := s.HandleResourceRequest(ctx, newRequest(http.MethodGet))
+	require.False(t, hit)
+	require.Equal(t, StatusMiss, status)
+	cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`["job"]`)})
+
+	hit, cr, status = s.HandleResourceRequest(ctx, newRequest(http.MethodGet))
+	require.True(t, hit)
+	require.Equal(t, StatusHit, status)
+	require.Equal(t, []byte(`["job"]`), cr.Response.Body)
+
+	t.Run("streamed responses are not cached", func(t *testing.T) {
+		require.NoError(t, s.Invalidate(ctx, 1, "prom"))
+		_, cr, _ := s.HandleResourceRequest(ctx, newRequest(http.MethodGet))
+		before := len(cache.Storage)
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte("part 1")})
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte("part 2")})
+		require.Equal(t, before, len(cache.Storage))
+	})
+
+	t.Run("responses of datasources forwarding the identity are cached per user", func(t *testing.T) {
+		req := newRequest(http.MethodGet)
+		req.PluginContext.DataSourceInstanceSettings.JSONData = json.RawMessage(`{"oauthPassThru":true}`)
+		alice := identity.WithRequester(ctx, &user.SignedInUser{UserUID: "alice"})
+		_, cr, _ := s.HandleResourceRequest(alice, req)
+		cr.UpdateCacheFn(alice, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`["alice"]`)})
+
+		hit, _, _ := s.HandleResourceRequest(identity.WithRequester(ctx, &user.SignedInUser{UserUID: "bob"}), req)
+		require.False(t, hit)
+
+		_, _, status := s.HandleResourceRequest(ctx, req)
+		require.Equal(t, StatusBypass, status, "unknown callers are not cached")
+	})
+}
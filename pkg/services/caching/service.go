@@ -30,6 +30,8 @@ const (
 	StatusBypass   CacheStatus = "BYPASS"
 	StatusError    CacheStatus = "ERROR"
 	StatusDisabled CacheStatus = "DISABLED"
+	// StatusPartial is returned when part of the time range was read from the cache and the rest was queried
+	StatusPartial CacheStatus = "PARTIAL"
 )
 
 // needed to mock the function for testing
@@ -38,6 +40,9 @@ var ShouldCacheQuery = awsds.ShouldCacheQuery
 type CacheQueryResponseFn func(context.Context, *backend.QueryDataResponse)
 type CacheResourceResponseFn func(context.Context, *backend.CallResourceResponse)
 
+// QueryDataFn sends a query data request to the datasource
+type QueryDataFn func(context.Context, *backend.QueryDataRequest) (*backend.QueryDataResponse, error)
+
 type CachedQueryDataResponse struct {
 	// The cached data response associated with a query, or nil if no cached data is found
 	Response *backend.QueryDataResponse
@@ -55,10 +60,6 @@ type CachedResourceDataResponse struct {
 	UpdateCacheFn CacheResourceResponseFn
 }
 
-func ProvideCachingService() *OSSCachingService {
-	return &OSSCachingService{}
-}
-
 type CachingService interface {
 	// HandleQueryRequest uses a QueryDataRequest to check the cache for any existing results for that query.
 	// If none are found, it should return false and a CachedQueryDataResponse with an UpdateCacheFn which can be used to update the results cache after the fact.
@@ -69,20 +70,14 @@ type CachingService interface {
 	HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse, CacheStatus)
 }
 
-// Implementation of interface - does nothing
-type OSSCachingService struct {
-}
-
-func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse, CacheStatus) {
-	return false, CachedQueryDataResponse{}, ""
+// IncrementalCachingService is implemented by caching services that cache time-series queries in time buckets.
+type IncrementalCachingService interface {
+	// HandleIncrementalQueryRequest answers the request from the cached time buckets, and sends the missing and recent parts
+	// of the time range to the datasource with `query`. It returns false when the request can't be cached incrementally,
+	// in which case the request has not been sent.
+	HandleIncrementalQueryRequest(ctx context.Context, req *backend.QueryDataRequest, query QueryDataFn) (bool, *backend.QueryDataResponse, CacheStatus, error)
 }
 
-func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse, CacheStatus) {
-	return false, CachedResourceDataResponse{}, ""
-}
-
-var _ CachingService = &OSSCachingService{}
-
 // GetKey creates a prefixed cache key and uses the internal `encoder` to encode the query into a string
 func GetKey(namespace, prefix string, query interface{}) (string, error) {
 	keybuf := bytes.NewBuffer(nil)
@@ -234,6 +229,34 @@ func (c *CachingServiceClient) WithQueryDataCaching(ctx context.Context, req *ba
 	return resp, err
 }
 
+// WithIncrementalQueryDataCaching answers time-series queries from cached time buckets if the caching service supports it,
+// calling `f` only for the parts of the time range that are missing. Other requests are cached with WithQueryDataCaching.
+func (c *CachingServiceClient) WithIncrementalQueryDataCaching(ctx context.Context, req *backend.QueryDataRequest, f QueryDataFn) (*backend.QueryDataResponse, error) {
+	if c == nil || req == nil {
+		return f(ctx, req)
+	}
+
+	if incremental, ok := c.cachingService.(IncrementalCachingService); ok {
+		start := time.Now()
+		handled, resp, status, err := incremental.HandleIncrementalQueryRequest(ctx, req, f)
+		if handled {
+			if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil {
+				reqCtx.Resp.Header().Set(XCacheHeader, string(status))
+				QueryCachingRequestHistogram.With(prometheus.Labels{
+					"datasource_type": getDatasourceType(req.PluginContext),
+					"cache":           string(status),
+					"query_type":      getQueryType(reqCtx),
+				}).Observe(time.Since(start).Seconds())
+			}
+			return resp, err
+		}
+	}
+
+	return c.WithQueryDataCaching(ctx, req, func() (*backend.QueryDataResponse, error) {
+		return f(ctx, req)
+	})
+}
+
 // WithCallResourceCaching calls `f` and caches the returned value if `req` has not been cached already.
 // Returns the cached value otherwise.
 func (c *CachingServiceClient) WithCallResourceCaching(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender, f func(backend.CallResourceResponseSender) error) error {
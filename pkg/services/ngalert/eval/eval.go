@@ -6,8 +6,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/url"
 	"runtime/debug"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/expr/classic"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -271,6 +274,23 @@ type Result struct {
 	EvaluationString string
 }
 
+// TraceIDs returns the distinct trace IDs of the exemplars of the values of the result. The trace
+// IDs of each value are kept most recent first, and the values are taken in the order of their refIDs.
+func (r Result) TraceIDs() []string {
+	var ids []string
+	seen := map[string]struct{}{}
+	for _, refID := range slices.Sorted(maps.Keys(r.Values)) {
+		for _, id := range r.Values[refID].TraceIDs {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 func NewResultFromError(err error, evaluatedAt time.Time, duration time.Duration) Result {
 	return Result{
 		State:              Error,
@@ -465,6 +485,10 @@ type NumberValueCapture struct {
 	Type             string // Expression type (reduce, threshold, classic_conditions, etc.)
 
 	Value *float64
+
+	// TraceIDs are the trace IDs of the exemplars of the value, such as the exemplars of a
+	// histogram reduced to a quantile.
+	TraceIDs []string
 }
 
 func IsNoData(res backend.DataResponse) bool {
@@ -497,7 +521,7 @@ func queryDataResponseToExecutionResults(c models.Condition, execResp *backend.Q
 		}
 	}
 
-	captureFn := func(refID string, datasourceType expr.NodeType, labels data.Labels, value *float64) {
+	captureFn := func(refID string, datasourceType expr.NodeType, labels data.Labels, value *float64, traceIDs []string) {
 		m := captures[refID]
 		if m == nil {
 			m = make(map[data.Fingerprint]NumberValueCapture)
@@ -515,6 +539,7 @@ func queryDataResponseToExecutionResults(c models.Condition, execResp *backend.Q
 			Value:            value,
 			Labels:           labels.Copy(),
 			Type:             exprType,
+			TraceIDs:         traceIDs,
 		}
 		captures[refID] = m
 	}
@@ -558,7 +583,13 @@ func queryDataResponseToExecutionResults(c models.Condition, execResp *backend.Q
 			if frame.Fields[0].Len() == 1 {
 				v = frame.At(0, 0).(*float64) // type checked above
 			}
-			captureFn(refID, datasourceType, frame.Fields[0].Labels, v)
+			var traceIDs []string
+			if frame.Meta != nil {
+				if exemplars, ok := frame.Meta.Custom.(mathexp.Exemplars); ok {
+					traceIDs = exemplars.TraceIDs()
+				}
+			}
+			captureFn(refID, datasourceType, frame.Fields[0].Labels, v, traceIDs)
 		}
 
 		if refID == c.Condition {
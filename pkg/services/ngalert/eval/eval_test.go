@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -815,6 +816,42 @@ func TestQueryDataResponseToExecutionResults(t *testing.T) {
 		require.True(t, result.Values["A"].IsDatasourceNode)
 		require.False(t, result.Values["B"].IsDatasourceNode)
 	})
+
+	t.Run("should capture the trace IDs of the exemplars of values", func(t *testing.T) {
+		c := models.Condition{
+			Condition: "C",
+			Data: []models.AlertQuery{
+				{RefID: "B", DatasourceUID: expr.DatasourceUID},
+				{RefID: "C", DatasourceUID: expr.DatasourceUID},
+			},
+		}
+
+		reduced := data.NewFrame("",
+			data.NewField("B", data.Labels{"handler": "/api"}, []*float64{new(1.8)}),
+		).SetMeta(&data.FrameMeta{
+			Custom: mathexp.Exemplars{
+				{Time: time.Unix(20, 0), Value: 1.9, TraceID: "def"},
+				{Time: time.Unix(10, 0), Value: 1.8, TraceID: "abc"},
+			},
+		})
+		execResp := &backend.QueryDataResponse{
+			Responses: backend.Responses{
+				"B": {Frames: data.Frames{reduced}},
+				"C": {Frames: data.Frames{
+					data.NewFrame("", data.NewField("C", data.Labels{"handler": "/api"}, []*float64{new(1.0)})),
+				}},
+			},
+		}
+
+		results := queryDataResponseToExecutionResults(c, execResp)
+		evaluatedResults := evaluateExecutionResult(results, time.Now(), time.Now())
+
+		require.Len(t, evaluatedResults, 1)
+		result := evaluatedResults[0]
+		require.Equal(t, []string{"def", "abc"}, result.Values["B"].TraceIDs)
+		require.Empty(t, result.Values["C"].TraceIDs)
+		require.Equal(t, []string{"def", "abc"}, result.TraceIDs())
+	})
 }
 
 func TestEvaluate(t *testing.T) {
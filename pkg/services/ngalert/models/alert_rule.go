@@ -25,6 +25,7 @@ import (
 
 	alertingModels "github.com/grafana/alerting/models"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -145,6 +146,10 @@ const (
 	// StateReasonAnnotation is the name of the annotation that explains the difference between evaluation state and alert state (i.e. changing state when NoData or Error).
 	StateReasonAnnotation = GrafanaReservedLabelPrefix + "state_reason"
 
+	// ExemplarTraceIDsAnnotation is the name of the annotation that lists the trace IDs of the exemplars of the values of an alert,
+	// such as the exemplars of a Prometheus native histogram.
+	ExemplarTraceIDsAnnotation = GrafanaReservedLabelPrefix + "exemplar_trace_ids"
+
 	// MigratedLabelPrefix is a label prefix for all labels created during legacy migration.
 	MigratedLabelPrefix = "__legacy_"
 	// MigratedUseLegacyChannelsLabel is created during legacy migration to route to separate nested policies for migrated channels.
@@ -449,6 +454,15 @@ func (n Namespace) ValidateForRuleStorage() error {
 	return nil
 }
 
+// ValidateForRuleStorageBy is like ValidateForRuleStorage, but lets the provisioning
+// service identity store rules in folders managed by Git Sync, since it is the one syncing them.
+func (n Namespace) ValidateForRuleStorageBy(user identity.Requester) error {
+	if n.ManagedBy == utils.ManagerKindRepo && n.UID != "" && identity.IsProvisioningServiceIdentity(user) {
+		return nil
+	}
+	return n.ValidateForRuleStorage()
+}
+
 func (n Namespace) GetNamespaceUID() string {
 	return n.UID
 }
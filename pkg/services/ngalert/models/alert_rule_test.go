@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -20,6 +21,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.yaml.in/yaml/v3"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/cmputil"
@@ -1556,3 +1561,17 @@ func TestAlertRuleGetEvalCondition_Origin(t *testing.T) {
 		})
 	}
 }
+
+func TestNamespace_ValidateForRuleStorageBy(t *testing.T) {
+	managed := Namespace{FolderReference: folder.FolderReference{UID: "f1", ManagedBy: utils.ManagerKindRepo}}
+	_, provisioner, err := identity.WithProvisioningIdentity(context.Background(), "default")
+	require.NoError(t, err)
+	signedIn := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+	require.ErrorContains(t, managed.ValidateForRuleStorageBy(signedIn), "managed by Git Sync")
+	require.ErrorContains(t, managed.ValidateForRuleStorageBy(nil), "managed by Git Sync")
+	require.NoError(t, managed.ValidateForRuleStorageBy(provisioner))
+
+	missingUID := Namespace{FolderReference: folder.FolderReference{ManagedBy: utils.ManagerKindRepo}}
+	require.ErrorContains(t, missingUID.ValidateForRuleStorageBy(provisioner), "without UID")
+}
@@ -1251,7 +1251,8 @@ func (service *AlertRuleService) checkGroupLimits(group models.AlertRuleGroup) e
 
 // ensureNamespace ensures that the rule has a valid namespace UID.
 // If the rule does not have a namespace UID or the namespace (folder) does not exist it will return an error.
-// If the folder is managed by a manager, it will also return an error.
+// If the folder is managed by a manager, it will also return an error, unless the
+// user is the provisioning service syncing the rules from a repository.
 func (service *AlertRuleService) ensureNamespace(ctx context.Context, user identity.Requester, orgID int64, namespaceUID string) error {
 	if namespaceUID == "" {
 		return fmt.Errorf("%w: folderUID must be set", models.ErrAlertRuleFailedValidation)
@@ -1277,7 +1278,7 @@ func (service *AlertRuleService) ensureNamespace(ctx context.Context, user ident
 	}
 
 	// check if the folder is managed by a manager
-	if err := models.NewNamespace(f).ValidateForRuleStorage(); err != nil {
+	if err := models.NewNamespace(f).ValidateForRuleStorageBy(user); err != nil {
 		return fmt.Errorf("%w: %s", models.ErrAlertRuleFailedValidation, err)
 	}
 
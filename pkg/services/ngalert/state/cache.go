@@ -157,6 +157,11 @@ func expandAnnotationsAndLabels(ctx context.Context, log log.Logger, alertRule *
 	// In the future, we want to show these errors to the user somehow.
 	labels, _ := expand(ctx, log, alertRule.Title, alertRule.Labels, templateData, externalURL, result.EvaluatedAt)
 	annotations, _ := expand(ctx, log, alertRule.Title, alertRule.Annotations, templateData, externalURL, result.EvaluatedAt)
+	if traceIDs := result.TraceIDs(); len(traceIDs) > 0 {
+		if _, ok := annotations[ngModels.ExemplarTraceIDsAnnotation]; !ok {
+			annotations[ngModels.ExemplarTraceIDsAnnotation] = strings.Join(traceIDs, ",")
+		}
+	}
 
 	// If the result contains an error, we want to add the ref_id and datasource_uid labels
 	// to the new state if the alert rule should be in the ErrorErrState.
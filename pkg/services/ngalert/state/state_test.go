@@ -1130,6 +1130,28 @@ func TestNewState(t *testing.T) {
 		assert.Equal(t, result.EvaluatedAt, state.LastEvaluationTime)
 		assert.Equal(t, result.EvaluationDuration, state.EvaluationDuration)
 	})
+
+	t.Run("annotates the trace IDs of the exemplars of the values", func(t *testing.T) {
+		rule := generateRule()
+		rule.Annotations = map[string]string{}
+
+		result := eval.Result{
+			Instance: ngmodels.GenerateAlertLabels(5, "result-"),
+			Values: map[string]eval.NumberValueCapture{
+				"A": {Var: "A", Value: new(1.0), TraceIDs: []string{"def", "abc"}},
+				"B": {Var: "B", Value: new(2.0), TraceIDs: []string{"abc"}},
+			},
+		}
+
+		state := newState(context.Background(), l, rule, result, nil, url)
+		assert.Equal(t, "def,abc", state.Annotations[ngmodels.ExemplarTraceIDsAnnotation])
+
+		t.Run("unless the rule defines the annotation", func(t *testing.T) {
+			rule.Annotations[ngmodels.ExemplarTraceIDsAnnotation] = "{{ $values.A.TraceIDs }}"
+			state := newState(context.Background(), l, rule, result, nil, url)
+			assert.Equal(t, "[def abc]", state.Annotations[ngmodels.ExemplarTraceIDsAnnotation])
+		})
+	})
 }
 
 func TestPatch(t *testing.T) {
@@ -44,6 +44,7 @@ func (l Labels) String() string {
 type Value struct {
 	Labels           Labels
 	Value            float64
+	TraceIDs         []string
 	isDatasourceNode bool
 }
 
@@ -66,6 +67,7 @@ func NewValues(captures map[string]eval.NumberValueCapture) map[string]Value {
 		values[refID] = Value{
 			Labels:           Labels(capture.Labels),
 			Value:            f,
+			TraceIDs:         capture.TraceIDs,
 			isDatasourceNode: capture.IsDatasourceNode,
 		}
 	}
@@ -115,16 +115,24 @@ func (s *ServiceImpl) queryData(ctx context.Context, user identity.Requester, sk
 		return nil, err
 	}
 
+	var resp *backend.QueryDataResponse
+	switch {
 	// If there are expressions, handle them and return
-	if parsedReq.hasExpression || fromAlert {
-		return s.handleExpressions(ctx, user, parsedReq)
-	}
+	case parsedReq.hasExpression || fromAlert:
+		resp, err = s.handleExpressions(ctx, user, parsedReq)
 	// If there is only one datasource, query it and return
-	if len(parsedReq.parsedQueries) == 1 {
-		return s.handleQuerySingleDatasource(ctx, user, parsedReq)
+	case len(parsedReq.parsedQueries) == 1:
+		resp, err = s.handleQuerySingleDatasource(ctx, user, parsedReq)
+	// If there are multiple datasources, handle their queries concurrently and return the aggregate result.
+	// The responses of every datasource are recorded by its own query.
+	default:
+		return s.executeConcurrentQueries(ctx, user, skipDSCache, reqDTO, parsedReq.parsedQueries)
+	}
+
+	if err != nil || !isRecordingResponses(ctx) {
+		return resp, err
 	}
-	// If there are multiple datasources, handle their queries concurrently and return the aggregate result
-	return s.executeConcurrentQueries(ctx, user, skipDSCache, reqDTO, parsedReq.parsedQueries)
+	return resp, recordResponses(resp, parsedReq)
 }
 
 func (s *ServiceImpl) QueryData(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error) {
@@ -3,6 +3,7 @@ package query
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -15,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	sdkdata "github.com/grafana/grafana-plugin-sdk-go/data"
 	data "github.com/grafana/grafana-plugin-sdk-go/experimental/apis/datasource/v0alpha1"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
@@ -996,3 +998,35 @@ func (c *testClient) QueryData(ctx context.Context, req data.QueryDataRequest) (
 	}
 	return nil, errors.New("no response stubbed")
 }
+
+func TestRecordResponses(t *testing.T) {
+	timeRange := backend.TimeRange{From: time.UnixMilli(1609459200000), To: time.UnixMilli(1609462800000)}
+	parsedReq := &parsedRequest{
+		parsedQueries: map[string][]parsedQuery{
+			"ds1": {
+				{query: backend.DataQuery{RefID: "A", TimeRange: timeRange}},
+				{query: backend.DataQuery{RefID: "B", TimeRange: timeRange}},
+			},
+		},
+	}
+	resp := &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: sdkdata.Frames{sdkdata.NewFrame("requests", sdkdata.NewField("value", nil, []float64{1, 2}))}},
+		"B": {Error: errors.New("query failed")},
+	}}
+
+	require.NoError(t, recordResponses(resp, parsedReq))
+
+	frames := resp.Responses["A"].Frames
+	require.Len(t, frames, 1)
+	assert.Equal(t, "A", frames[0].RefID)
+	recording := responseRecording{}
+	require.NoError(t, json.Unmarshal([]byte(frames[0].Fields[0].At(0).(string)), &recording))
+	assert.Equal(t, int64(1609459200000), recording.From)
+	assert.Equal(t, int64(1609462800000), recording.To)
+	require.Len(t, recording.Frames, 1)
+	assert.Equal(t, "requests", recording.Frames[0].Name)
+	assert.Equal(t, 2.0, recording.Frames[0].Fields[0].At(1))
+
+	require.Error(t, resp.Responses["B"].Error)
+	assert.Empty(t, resp.Responses["B"].Frames)
+}
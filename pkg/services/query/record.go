@@ -0,0 +1,60 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+)
+
+// HeaderRecordResponse asks for recordings of the query responses instead of the responses. The
+// record action of the query inspector sets it, and the recorded scenario of the testdata datasource
+// replays the recordings.
+const HeaderRecordResponse = "X-Grafana-Record-Response"
+
+// responseRecording is the recording of a query response, with the time range it was queried for
+type responseRecording struct {
+	From   int64       `json:"from"`
+	To     int64       `json:"to"`
+	Frames data.Frames `json:"frames"`
+}
+
+func isRecordingResponses(ctx context.Context) bool {
+	reqCtx := contexthandler.FromContext(ctx)
+	return reqCtx != nil && reqCtx.Req != nil && reqCtx.Req.Header.Get(HeaderRecordResponse) == "true"
+}
+
+// recordResponses replaces the frames of every successful response with a single frame, holding
+// the recording of the frames in its only value
+func recordResponses(resp *backend.QueryDataResponse, parsedReq *parsedRequest) error {
+	timeRanges := map[string]backend.TimeRange{}
+	for _, pq := range parsedReq.getFlattenedQueries() {
+		timeRanges[pq.query.RefID] = pq.query.TimeRange
+	}
+
+	for refID, res := range resp.Responses {
+		if res.Error != nil {
+			continue
+		}
+		timeRange := timeRanges[refID]
+
+		recording, err := json.Marshal(responseRecording{
+			From:   timeRange.From.UnixMilli(),
+			To:     timeRange.To.UnixMilli(),
+			Frames: res.Frames,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record the response of query %s: %w", refID, err)
+		}
+
+		frame := data.NewFrame("recording", data.NewField("recording", nil, []string{string(recording)}))
+		frame.RefID = refID
+		res.Frames = data.Frames{frame}
+		resp.Responses[refID] = res
+	}
+	return nil
+}
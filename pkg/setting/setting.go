@@ -164,6 +164,7 @@ type Cfg struct {
 	ProvisioningAllowInsecure                 bool // allow http:// repository URLs together with a token (cleartext credentials); local/dev only
 	ProvisioningMinSyncInterval               time.Duration
 	ProvisioningRepositoryTypes               []string
+	ProvisioningPermittedBucketURLs           []string // bucket repositories must be under one of these URLs
 	ProvisioningLokiURL                       string
 	ProvisioningLokiUser                      string
 	ProvisioningLokiPassword                  string
@@ -175,6 +176,7 @@ type Cfg struct {
 	ProvisioningMaxFileSize                   int64         // bytes; default 5 MiB (5242880); <=0 = unlimited
 	ProvisioningWebhookSecretRotationInterval time.Duration // default 30 days
 	ProvisioningPublicRootURL                 string        // public-facing root URL of this Grafana instance for provisioning consumers (webhooks, screenshots); falls back to AppURL when empty
+	ProvisioningPreviewNamespace              string        // namespace pull request previews are loaded into; previews are not loaded when empty
 	DataPath                                  string
 	LogsPath                                  string
 	EnterpriseLicensePath                     string
@@ -363,6 +365,9 @@ type Cfg struct {
 	// DistributedCache
 	RemoteCacheOptions *RemoteCacheSettings
 
+	// Query and resource caching
+	QueryCaching QueryCachingSettings
+
 	// Deprecated: no longer used
 	ViewersCanEdit bool
 
@@ -1819,6 +1824,7 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.GeomapEnableCustomBaseLayers = geomapSection.Key("enable_custom_baselayers").MustBool(true)
 
 	cfg.readRemoteCacheSettings()
+	cfg.readQueryCachingSettings()
 	cfg.readDateFormats()
 	cfg.readGrafanaJavascriptAgentConfig()
 
@@ -2580,6 +2586,7 @@ func (cfg *Cfg) readProvisioningSettings(iniFile *ini.File) error {
 	cfg.ProvisioningAllowImageRendering = iniFile.Section("provisioning").Key("allow_image_rendering").MustBool(true)
 	cfg.ProvisioningAllowInsecure = iniFile.Section("provisioning").Key("allow_insecure").MustBool(false)
 	cfg.ProvisioningMinSyncInterval = iniFile.Section("provisioning").Key("min_sync_interval").MustDuration(10 * time.Second)
+	cfg.ProvisioningPermittedBucketURLs = iniFile.Section("provisioning").Key("permitted_bucket_urls").Strings("|")
 	cfg.ProvisioningMaxResourcesPerRepository = iniFile.Section("provisioning").Key("max_resources_per_repository").MustInt64(0)
 	cfg.ProvisioningMaxRepositories = iniFile.Section("provisioning").Key("max_repositories").MustInt64(10)
 	cfg.ProvisioningFolderAPIVersion = iniFile.Section("provisioning").Key("folders_api_version").MustString("v1")
@@ -2587,6 +2594,7 @@ func (cfg *Cfg) readProvisioningSettings(iniFile *ini.File) error {
 	cfg.ProvisioningMaxFileSize = iniFile.Section("provisioning").Key("max_file_size").MustInt64(ProvisioningMaxFileSizeDefault)
 	cfg.ProvisioningWebhookSecretRotationInterval = iniFile.Section("provisioning").Key("webhook_secret_rotation_interval").MustDuration(30 * 24 * time.Hour)
 	cfg.ProvisioningPublicRootURL = strings.TrimRight(valueAsString(iniFile.Section("provisioning"), "public_root_url", ""), "/")
+	cfg.ProvisioningPreviewNamespace = valueAsString(iniFile.Section("provisioning"), "preview_namespace", "")
 
 	// Read job history configuration
 	cfg.ProvisioningLokiURL = valueAsString(iniFile.Section("provisioning"), "loki_url", "")
@@ -2607,6 +2615,11 @@ func defaultProvisioningResources() []string {
 		"dashboard.grafana.app/Dashboard:folder",
 		"dashboard.grafana.app/LibraryPanel:folder:disabled",
 		"playlist.grafana.app/Playlist:disabled",
+		// Alert rules are opt-in. The notification kinds of alerting are not listed: they are
+		// stored in the Alertmanager configuration, which drops the managed-by annotation.
+		"rules.alerting.grafana.app/AlertRule:folder:disabled",
+		"rules.alerting.grafana.app/RecordingRule:folder:disabled",
+		"rules.alerting.grafana.app/RuleSequence:folder:disabled",
 	}
 }
 
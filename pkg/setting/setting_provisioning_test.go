@@ -17,6 +17,9 @@ func TestReadProvisioningResources(t *testing.T) {
 			"dashboard.grafana.app/Dashboard:folder",
 			"dashboard.grafana.app/LibraryPanel:folder:disabled",
 			"playlist.grafana.app/Playlist:disabled",
+			"rules.alerting.grafana.app/AlertRule:folder:disabled",
+			"rules.alerting.grafana.app/RecordingRule:folder:disabled",
+			"rules.alerting.grafana.app/RuleSequence:folder:disabled",
 		}, cfg.ProvisioningResources)
 	})
 
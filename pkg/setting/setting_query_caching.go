@@ -0,0 +1,52 @@
+package setting
+
+import (
+	"time"
+)
+
+type QueryCachingSettings struct {
+	Enabled bool
+	// TTL of cached query responses
+	TTL time.Duration
+	// ResourcesTTL of cached resource responses
+	ResourcesTTL time.Duration
+	// MaxValueSize is the largest response, in bytes, that is cached
+	MaxValueSize int
+	// DatasourceTTLs overrides the query TTL by datasource UID or plugin ID. A zero TTL disables caching.
+	DatasourceTTLs map[string]time.Duration
+
+	// IncrementalEnabled caches time-series queries in time buckets, so only missing and recent buckets are queried
+	IncrementalEnabled bool
+	// IncrementalBucketSize is the length of the time buckets
+	IncrementalBucketSize time.Duration
+	// IncrementalRecentWindow is the most recent part of the time range, which is always queried and never cached
+	IncrementalRecentWindow time.Duration
+	// IncrementalTTL of cached time buckets
+	IncrementalTTL time.Duration
+}
+
+func (cfg *Cfg) readQueryCachingSettings() {
+	section := cfg.Raw.Section("caching")
+
+	cfg.QueryCaching = QueryCachingSettings{
+		Enabled:        section.Key("enabled").MustBool(false),
+		TTL:            section.Key("ttl").MustDuration(time.Minute),
+		ResourcesTTL:   section.Key("resources_ttl").MustDuration(5 * time.Minute),
+		MaxValueSize:   section.Key("max_value_mb").MustInt(1) * 1024 * 1024,
+		DatasourceTTLs: map[string]time.Duration{},
+
+		IncrementalEnabled:      section.Key("incremental_enabled").MustBool(false),
+		IncrementalBucketSize:   section.Key("incremental_bucket_size").MustDuration(time.Hour),
+		IncrementalRecentWindow: section.Key("incremental_recent_window").MustDuration(10 * time.Minute),
+		IncrementalTTL:          section.Key("incremental_ttl").MustDuration(24 * time.Hour),
+	}
+
+	for _, key := range cfg.Raw.Section("caching.datasources").Keys() {
+		ttl, err := time.ParseDuration(key.String())
+		if err != nil {
+			cfg.Logger.Warn("Invalid query caching TTL", "datasource", key.Name(), "ttl", key.String(), "error", err)
+			continue
+		}
+		cfg.QueryCaching.DatasourceTTLs[key.Name()] = ttl
+	}
+}
@@ -26,6 +26,13 @@ import (
 var (
 	errResourceIsManagedInRepository = fmt.Errorf("this resource is managed by a repository")
 
+	errResourceIsPreview = &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusForbidden,
+		Reason:  metav1.StatusReasonForbidden,
+		Message: "this resource is a read-only pull request preview",
+	}}
+
 	// terraformUserAgentPattern matches the User-Agent based manager ID format used by Terraform providers.
 	// Format: "Terraform/{version} (+https://www.terraform.io) terraform-provider-{name}/{version}"
 	// Example: "Terraform/1.5.0 (+https://www.terraform.io) terraform-provider-grafana/v3.0.0"
@@ -63,6 +70,11 @@ func checkManagerPropertiesOnCreate(auth authtypes.AuthInfo, obj utils.GrafanaMe
 }
 
 func checkManagerPropertiesOnUpdateSpec(auth authtypes.AuthInfo, obj utils.GrafanaMetaAccessor, old utils.GrafanaMetaAccessor) error {
+	// Dropping the preview label does not make a preview editable
+	if err := enforcePreviewReadOnly(auth, old); err != nil {
+		return err
+	}
+
 	managerNew, hasNew := obj.GetManagerProperties()
 	managerOld, hasOld := old.GetManagerProperties()
 
@@ -179,7 +191,20 @@ func ensureSameRepoManager(folder utils.GrafanaMetaAccessor, resource utils.Graf
 	return nil
 }
 
+// enforcePreviewReadOnly only lets provisioning write pull request previews.
+// They are never routed to the repository, as they do not exist on its branch.
+func enforcePreviewReadOnly(auth authtypes.AuthInfo, obj utils.GrafanaMetaAccessor) error {
+	if obj.GetLabels()[utils.LabelKeyPreview] == "" || identity.IsProvisioningServiceIdentity(auth) {
+		return nil
+	}
+	return errResourceIsPreview
+}
+
 func enforceManagerProperties(auth authtypes.AuthInfo, obj utils.GrafanaMetaAccessor) error {
+	if err := enforcePreviewReadOnly(auth, obj); err != nil {
+		return err
+	}
+
 	kind := obj.GetAnnotation(utils.AnnoKeyManagerKind)
 	if kind == "" {
 		return nil
@@ -379,6 +379,63 @@ func TestManagedAuthorizer(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "provisioning can create pull request previews",
+			auth: provisioner,
+			obj: &dashboard.Dashboard{
+				ObjectMeta: v1.ObjectMeta{
+					Labels: map[string]string{utils.LabelKeyPreview: "my-repo-pr-1"},
+					Annotations: map[string]string{
+						utils.AnnoKeyManagerKind:     string(utils.ManagerKindRepo),
+						utils.AnnoKeyManagerIdentity: "my-repo-pr-1",
+					},
+				},
+			},
+		},
+		{
+			name: "server admin can not update pull request previews",
+			auth: serverAdmin,
+			err:  "read-only pull request preview",
+			obj: &dashboard.Dashboard{
+				ObjectMeta: v1.ObjectMeta{
+					Generation: 2,
+					Annotations: map[string]string{
+						utils.AnnoKeyManagerKind:     string(utils.ManagerKindRepo),
+						utils.AnnoKeyManagerIdentity: "my-repo-pr-1",
+					},
+				},
+			},
+			old: &dashboard.Dashboard{
+				ObjectMeta: v1.ObjectMeta{
+					Generation: 1,
+					Labels:     map[string]string{utils.LabelKeyPreview: "my-repo-pr-1"},
+					Annotations: map[string]string{
+						utils.AnnoKeyManagerKind:     string(utils.ManagerKindRepo),
+						utils.AnnoKeyManagerIdentity: "my-repo-pr-1",
+					},
+				},
+			},
+		},
+		{
+			name: "org admin can not release pull request previews",
+			auth: orgAdmin,
+			err:  "read-only pull request preview",
+			obj: &dashboard.Dashboard{
+				ObjectMeta: v1.ObjectMeta{
+					Generation: 2,
+				},
+			},
+			old: &dashboard.Dashboard{
+				ObjectMeta: v1.ObjectMeta{
+					Generation: 1,
+					Labels:     map[string]string{utils.LabelKeyPreview: "my-repo-pr-1"},
+					Annotations: map[string]string{
+						utils.AnnoKeyManagerKind:     string(utils.ManagerKindRepo),
+						utils.AnnoKeyManagerIdentity: "my-repo-pr-1",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
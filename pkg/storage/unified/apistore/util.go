@@ -8,13 +8,16 @@ package apistore
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apiserver/pkg/storage"
 
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
 	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
 )
 
@@ -54,6 +57,7 @@ func toListRequest(k *resourcepb.ResourceKey, opts storage.ListOptions) (*resour
 		if !selectable {
 			return nil, predicate, nil // not selectable
 		}
+		labelRequirements := make([]labels.Requirement, 0, len(requirements))
 
 		for _, r := range requirements {
 			v := r.Key()
@@ -102,12 +106,25 @@ func toListRequest(k *resourcepb.ResourceKey, opts storage.ListOptions) (*resour
 				return req, storage.Everything, nil
 			}
 
+			// Requirements on fields are evaluated by storage, not on the labels of the object
+			if field, ok := toFieldRequirement(r); ok {
+				if field == nil {
+					return nil, predicate, apierrors.NewBadRequest(fmt.Sprintf("unsupported operator %s for: %s", r.Operator(), v))
+				}
+				req.Options.Fields = append(req.Options.Fields, field)
+				continue
+			}
+
+			labelRequirements = append(labelRequirements, r)
 			req.Options.Labels = append(req.Options.Labels, &resourcepb.Requirement{
 				Key:      v,
 				Operator: string(r.Operator()),
 				Values:   r.Values().List(),
 			})
 		}
+		if len(labelRequirements) < len(requirements) {
+			predicate.Label = labels.NewSelector().Add(labelRequirements...)
+		}
 	}
 
 	if opts.Predicate.Field != nil && !opts.Predicate.Field.Empty() {
@@ -123,3 +140,34 @@ func toListRequest(k *resourcepb.ResourceKey, opts storage.ListOptions) (*resour
 
 	return req, predicate, nil
 }
+
+// toFieldRequirement converts a label selector requirement on a field for storage. Field selectors only
+// have the =, == and != operators, so the other operators are written as label requirements on the field:
+//
+//	fields.grafana.app/spec.env in (prod,staging)
+//	fields.grafana.app/spec.env notin (prod,staging)
+//	prefix.fields.grafana.app/spec.title=team
+//
+// It returns false when the requirement is on a label, and a nil requirement when the operator is not
+// supported on the field.
+func toFieldRequirement(r labels.Requirement) (*resourcepb.Requirement, bool) {
+	if field, ok := strings.CutPrefix(r.Key(), utils.LabelKeyFieldPrefixSelector); ok {
+		if r.Operator() != selection.Equals && r.Operator() != selection.DoubleEquals {
+			return nil, true
+		}
+		return &resourcepb.Requirement{Key: field, Operator: resource.FieldSelectorOperatorPrefix, Values: r.Values().List()}, true
+	}
+
+	field, ok := strings.CutPrefix(r.Key(), utils.LabelKeyFieldSelector)
+	if !ok {
+		return nil, false
+	}
+	switch r.Operator() {
+	case selection.In:
+		return &resourcepb.Requirement{Key: field, Operator: resource.FieldSelectorOperatorIn, Values: r.Values().List()}, true
+	case selection.NotIn:
+		return &resourcepb.Requirement{Key: field, Operator: resource.FieldSelectorOperatorNotIn, Values: r.Values().List()}, true
+	default:
+		return nil, true
+	}
+}
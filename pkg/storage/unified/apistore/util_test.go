@@ -160,6 +160,58 @@ func TestToListRequest(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "with in, notin and prefix field requirements",
+			key: &resourcepb.ResourceKey{
+				Group:     "test",
+				Resource:  "test",
+				Namespace: "default",
+			},
+			opts: storage.ListOptions{
+				Predicate: storage.SelectionPredicate{
+					Label: mustParseLabelSelector(t, "fields.grafana.app/spec.env in (prod,dev),fields.grafana.app/spec.team notin (a,b),prefix.fields.grafana.app/spec.title=Team,label=A"),
+					Field: mustParseFieldSelector(t, "spec.kind=in(B)"),
+				},
+			},
+			want: &resourcepb.ListRequest{
+				VersionMatchV2: 1,
+				Options: &resourcepb.ListOptions{
+					Key: &resourcepb.ResourceKey{
+						Group:     "test",
+						Resource:  "test",
+						Namespace: "default",
+					},
+					Labels: []*resourcepb.Requirement{
+						{Key: "label", Operator: string(selection.Equals), Values: []string{"A"}},
+					},
+					Fields: []*resourcepb.Requirement{
+						{Key: "spec.env", Operator: "in", Values: []string{"dev", "prod"}},
+						{Key: "spec.team", Operator: "notin", Values: []string{"a", "b"}},
+						{Key: "spec.title", Operator: "prefix", Values: []string{"Team"}},
+						// Field selector values are always matched literally
+						{Key: "spec.kind", Operator: string(selection.Equals), Values: []string{"in(B)"}},
+					},
+				},
+			},
+			wantPredicate: storage.SelectionPredicate{
+				Label: mustParseLabelSelector(t, "label=A"),
+				Field: mustParseFieldSelector(t, "spec.kind=in(B)"),
+			},
+		},
+		{
+			name: "with unsupported field requirement operator",
+			key: &resourcepb.ResourceKey{
+				Group:     "test",
+				Resource:  "test",
+				Namespace: "default",
+			},
+			opts: storage.ListOptions{
+				Predicate: storage.SelectionPredicate{
+					Label: mustParseLabelSelector(t, "prefix.fields.grafana.app/spec.title!=Team"),
+				},
+			},
+			wantErr: apierrors.NewBadRequest("unsupported operator != for: prefix.fields.grafana.app/spec.title"),
+		},
 		{
 			name: "with trash label",
 			key: &resourcepb.ResourceKey{
@@ -234,3 +286,17 @@ func TestToListRequest(t *testing.T) {
 		})
 	}
 }
+
+func mustParseFieldSelector(t *testing.T, selector string) fields.Selector {
+	t.Helper()
+	parsed, err := fields.ParseSelector(selector)
+	require.NoError(t, err)
+	return parsed
+}
+
+func mustParseLabelSelector(t *testing.T, selector string) labels.Selector {
+	t.Helper()
+	parsed, err := labels.Parse(selector)
+	require.NoError(t, err)
+	return parsed
+}
@@ -180,21 +180,34 @@ func newClient(opts options.StorageOptions,
 			}
 		}
 
+		auditChain, err := sql.ProvideAuditChain(cfg, eDB)
+		if err != nil {
+			return nil, err
+		}
+
+		schemaRewrite, err := sql.ProvideSchemaRewrite(cfg, eDB)
+		if err != nil {
+			return nil, err
+		}
+
 		serverOptions := sql.ServerOptions{
-			Backend:        backend,
-			VectorBackend:  vectorBackend,
-			Embedder:       embedderInstance,
-			Cfg:            cfg,
-			Tracer:         tracer,
-			Reg:            reg,
-			AccessClient:   authzc,
-			SearchOptions:  searchOptions,
-			StorageMetrics: storageMetrics,
-			IndexMetrics:   indexMetrics,
-			VectorMetrics:  vectorMetrics,
-			Features:       features,
-			SecureValues:   secure,
-			DashboardStats: dashboardStats,
+			Backend:          backend,
+			VectorBackend:    vectorBackend,
+			Embedder:         embedderInstance,
+			Cfg:              cfg,
+			Tracer:           tracer,
+			Reg:              reg,
+			AccessClient:     authzc,
+			SearchOptions:    searchOptions,
+			StorageMetrics:   storageMetrics,
+			IndexMetrics:     indexMetrics,
+			VectorMetrics:    vectorMetrics,
+			Features:         features,
+			SecureValues:     secure,
+			DashboardStats:   dashboardStats,
+			AuditChain:       auditChain,
+			SchemaMigrations: resource.DefaultSchemaMigrations(),
+			SchemaRewrite:    schemaRewrite,
 		}
 
 		if cfg.QOSEnabled {
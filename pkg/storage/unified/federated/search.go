@@ -0,0 +1,517 @@
+package federated
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/legacysql"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// defaultCrossNamespaceConcurrency bounds how many namespaces are searched in parallel.
+const defaultCrossNamespaceConcurrency = 8
+
+// NamespaceLister returns every namespace known to the instance
+type NamespaceLister interface {
+	ListNamespaces(ctx context.Context) ([]string, error)
+}
+
+// Lists namespaces using the legacy org table
+type LegacyNamespaceLister struct {
+	SQL legacysql.LegacyDatabaseProvider
+	Cfg *setting.Cfg
+}
+
+func (l *LegacyNamespaceLister) ListNamespaces(ctx context.Context) ([]string, error) {
+	helper, err := l.SQL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orgIDs []int64
+	err = helper.DB.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table(helper.Table("org")).Cols("id").OrderBy("id").Find(&orgIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mapper := request.GetNamespaceMapper(l.Cfg)
+	namespaces := make([]string, 0, len(orgIDs))
+	for _, id := range orgIDs {
+		ns := mapper(id)
+		// In cloud every org maps to the same stack namespace
+		if !slices.Contains(namespaces, ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces, nil
+}
+
+// CrossNamespaceSearchRequest runs the same search in multiple namespaces.
+// The namespace in Request.Options.Key is ignored.
+type CrossNamespaceSearchRequest struct {
+	// Namespaces to search. When empty, all namespaces are searched
+	Namespaces []string
+
+	Request *resourcepb.ResourceSearchRequest
+}
+
+// CrossNamespaceSearchResponse holds the merged results
+type CrossNamespaceSearchResponse struct {
+	*resourcepb.ResourceSearchResponse
+
+	// Namespaces that were searched
+	Searched []string
+
+	// Namespaces that could not be searched, with the reason.
+	// The results of the other namespaces are still returned
+	Errors map[string]*resourcepb.ErrorResult
+}
+
+func (r *CrossNamespaceSearchResponse) addError(ns string, err error) {
+	if r.Errors == nil {
+		r.Errors = make(map[string]*resourcepb.ErrorResult)
+	}
+	r.Errors[ns] = resource.AsErrorResult(err)
+}
+
+// CrossNamespaceSearcher fans a search request out over several namespaces
+// and merges the ranked results and facets. This is intended for instance
+// (server) admins that need to find resources without switching orgs.
+// The requester is authorized once, then every namespace is searched with
+// the service identity of that namespace, since access clients only check
+// the namespace of the requester.
+type CrossNamespaceSearcher struct {
+	client      resourcepb.ResourceIndexClient
+	namespaces  NamespaceLister
+	concurrency int
+}
+
+func NewCrossNamespaceSearcher(client resourcepb.ResourceIndexClient, namespaces NamespaceLister) *CrossNamespaceSearcher {
+	return &CrossNamespaceSearcher{
+		client:      client,
+		namespaces:  namespaces,
+		concurrency: defaultCrossNamespaceConcurrency,
+	}
+}
+
+func (s *CrossNamespaceSearcher) Search(ctx context.Context, in *CrossNamespaceSearchRequest) (*CrossNamespaceSearchResponse, error) {
+	req := in.Request
+	if req == nil || req.Options == nil || req.Options.Key == nil {
+		return nil, fmt.Errorf("missing search request key")
+	}
+	if req.Options.Key.Group == "" || req.Options.Key.Resource == "" {
+		return &CrossNamespaceSearchResponse{
+			ResourceSearchResponse: &resourcepb.ResourceSearchResponse{
+				Error: resource.NewBadRequestError("missing group or resource"),
+			},
+		}, nil
+	}
+	if req.Limit < 0 || req.Offset < 0 {
+		return &CrossNamespaceSearchResponse{
+			ResourceSearchResponse: &resourcepb.ResourceSearchResponse{
+				Error: resource.NewBadRequestError("limit and offset cannot be negative"),
+			},
+		}, nil
+	}
+	if req.Page > 0 || len(req.SearchAfter) > 0 || len(req.SearchBefore) > 0 {
+		return &CrossNamespaceSearchResponse{
+			ResourceSearchResponse: &resourcepb.ResourceSearchResponse{
+				Error: resource.NewBadRequestError("cross namespace search only supports limit and offset pagination"),
+			},
+		}, nil
+	}
+
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !user.GetIsGrafanaAdmin() {
+		return &CrossNamespaceSearchResponse{
+			ResourceSearchResponse: &resourcepb.ResourceSearchResponse{
+				Error: &resourcepb.ErrorResult{
+					Message: "only server admins can search across namespaces",
+					Code:    http.StatusForbidden,
+				},
+			},
+		}, nil
+	}
+
+	namespaces := in.Namespaces
+	if len(namespaces) == 0 {
+		if s.namespaces == nil {
+			return nil, fmt.Errorf("namespaces must be set when no namespace lister is configured")
+		}
+		namespaces, err = s.namespaces.ListNamespaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(namespaces) == 0 {
+		return &CrossNamespaceSearchResponse{
+			ResourceSearchResponse: &resourcepb.ResourceSearchResponse{
+				Error: resource.NewBadRequestError("no namespaces to search"),
+			},
+		}, nil
+	}
+	rsp := &CrossNamespaceSearchResponse{Searched: namespaces}
+
+	// Each namespace returns enough rows to fill the requested window after merging
+	limit := req.Limit
+	if limit == 0 {
+		limit = 50
+	}
+
+	// A namespace that fails does not fail the whole search
+	results := make([]*resourcepb.ResourceSearchResponse, len(rsp.Searched))
+	errs := make([]error, len(rsp.Searched))
+	g := errgroup.Group{}
+	g.SetLimit(s.concurrency)
+	for i, ns := range rsp.Searched {
+		g.Go(func() error {
+			nsReq := forNamespace(req, ns)
+			nsReq.Limit = req.Offset + limit
+			nsReq.Offset = 0
+
+			nsCtx, _, err := identity.WithServiceIdentityForNamespace(ctx, ns)
+			if err != nil {
+				errs[i] = err
+				return nil
+			}
+			r, err := s.client.Search(nsCtx, nsReq)
+			switch {
+			case err != nil:
+				errs[i] = err
+			case r.Error != nil:
+				errs[i] = resource.GetError(r.Error)
+			default:
+				results[i] = r
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			rsp.addError(rsp.Searched[i], err)
+			failed++
+		}
+	}
+	if failed == len(rsp.Searched) {
+		rsp.ResourceSearchResponse = &resourcepb.ResourceSearchResponse{
+			Error: resource.AsErrorResult(fmt.Errorf("search failed in every namespace: %w", errors.Join(errs...))),
+		}
+		return rsp, nil
+	}
+
+	merged, err := mergeSearchResponses(req, results)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the requested window to the merged rows
+	if merged.Results != nil {
+		rows := merged.Results.Rows
+		start := min(int(req.Offset), len(rows))
+		end := min(start+int(limit), len(rows))
+		merged.Results.Rows = rows[start:end]
+	}
+	rsp.ResourceSearchResponse = merged
+	return rsp, nil
+}
+
+// forNamespace returns a shallow copy of the request scoped to a single namespace
+func forNamespace(req *resourcepb.ResourceSearchRequest, ns string) *resourcepb.ResourceSearchRequest {
+	key := &resourcepb.ResourceKey{
+		Namespace: ns,
+		Group:     req.Options.Key.Group,
+		Resource:  req.Options.Key.Resource,
+	}
+	federated := make([]*resourcepb.ResourceKey, len(req.Federated))
+	for i, f := range req.Federated {
+		federated[i] = &resourcepb.ResourceKey{
+			Namespace: ns,
+			Group:     f.Group,
+			Resource:  f.Resource,
+		}
+	}
+	return &resourcepb.ResourceSearchRequest{
+		Options: &resourcepb.ListOptions{
+			Key:    key,
+			Labels: req.Options.Labels,
+			Fields: req.Options.Fields,
+		},
+		Federated:   federated,
+		Query:       req.Query,
+		SortBy:      req.SortBy,
+		Facet:       req.Facet,
+		Fields:      req.Fields,
+		Explain:     req.Explain,
+		IsDeleted:   req.IsDeleted,
+		Permission:  req.Permission,
+		QueryFields: req.QueryFields,
+	}
+}
+
+type rankedRow struct {
+	row   *resourcepb.ResourceTableRow
+	score float64
+	index int   // position within the namespace results
+	sort  []any // typed values of the requested sort fields
+}
+
+// mergeSearchResponses combines results from several indexes into a single response.
+// Rows are ordered by the requested sort fields, or by score when a query is set.
+// Otherwise the results from each index are interleaved, preserving their original order.
+// The columns are the union of the columns of every index.
+func mergeSearchResponses(req *resourcepb.ResourceSearchRequest, results []*resourcepb.ResourceSearchResponse) (*resourcepb.ResourceSearchResponse, error) {
+	merged := &resourcepb.ResourceSearchResponse{}
+
+	var columns []*resourcepb.ResourceTableColumnDefinition
+	for _, r := range results {
+		if r == nil || r.Results == nil {
+			continue
+		}
+		for _, c := range r.Results.Columns {
+			if !slices.ContainsFunc(columns, func(e *resourcepb.ResourceTableColumnDefinition) bool { return e.Name == c.Name }) {
+				columns = append(columns, c)
+			}
+		}
+	}
+
+	var rows []rankedRow
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		merged.TotalHits += r.TotalHits
+		merged.QueryCost += r.QueryCost
+		merged.MaxScore = max(merged.MaxScore, r.MaxScore)
+		merged.ResourceVersion = max(merged.ResourceVersion, r.ResourceVersion)
+		mergeFacets(merged, r.Facet)
+
+		if r.Results == nil {
+			continue
+		}
+		remap := columnMapping(columns, r.Results.Columns)
+		scoreIdx := columnIndex(r.Results.Columns, resource.SEARCH_FIELD_SCORE)
+		sortIdx := make([]int, len(req.SortBy))
+		for i, sort := range req.SortBy {
+			sortIdx[i] = columnIndex(r.Results.Columns, strings.TrimPrefix(sort.Field, resource.SEARCH_FIELD_PREFIX))
+		}
+		for i, row := range r.Results.Rows {
+			rr := rankedRow{row: remapRow(row, remap), index: i, sort: make([]any, len(sortIdx))}
+			if scoreIdx >= 0 && scoreIdx < len(row.Cells) {
+				v, err := resource.DecodeCell(r.Results.Columns[scoreIdx], scoreIdx, row.Cells[scoreIdx])
+				if err != nil {
+					return nil, err
+				}
+				if f, ok := v.(float64); ok {
+					rr.score = f
+				}
+			}
+			for j, idx := range sortIdx {
+				v, err := sortValue(r.Results.Columns, row, j, idx)
+				if err != nil {
+					return nil, err
+				}
+				rr.sort[j] = v
+			}
+			rows = append(rows, rr)
+		}
+	}
+
+	switch {
+	case len(req.SortBy) > 0:
+		slices.SortStableFunc(rows, func(a, b rankedRow) int {
+			for i, s := range req.SortBy {
+				c := compareValues(a.sort[i], b.sort[i])
+				if s.Desc {
+					c = -c
+				}
+				if c != 0 {
+					return c
+				}
+			}
+			return 0
+		})
+	case req.Query != "":
+		slices.SortStableFunc(rows, func(a, b rankedRow) int {
+			return cmp.Compare(b.score, a.score)
+		})
+	default:
+		slices.SortStableFunc(rows, func(a, b rankedRow) int {
+			return cmp.Compare(a.index, b.index)
+		})
+	}
+
+	merged.Results = &resourcepb.ResourceTable{
+		Columns: columns,
+		Rows:    make([]*resourcepb.ResourceTableRow, len(rows)),
+	}
+	for i, r := range rows {
+		merged.Results.Rows[i] = r.row
+	}
+	limitFacets(merged, req.Facet)
+	return merged, nil
+}
+
+func columnIndex(columns []*resourcepb.ResourceTableColumnDefinition, name string) int {
+	return slices.IndexFunc(columns, func(c *resourcepb.ResourceTableColumnDefinition) bool {
+		return c.Name == name
+	})
+}
+
+// sortValue returns the typed value of the i-th sort field of the row. Numbers, times and booleans are
+// decoded from the column of the sort field, as the raw sort values of the index only order strings.
+func sortValue(columns []*resourcepb.ResourceTableColumnDefinition, row *resourcepb.ResourceTableRow, i, column int) (any, error) {
+	if column >= 0 && column < len(row.Cells) && row.Cells[column] != nil &&
+		columns[column].Type != resourcepb.ResourceTableColumnDefinition_STRING {
+		return resource.DecodeCell(columns[column], column, row.Cells[column])
+	}
+	if i < len(row.SortFields) {
+		return row.SortFields[i], nil
+	}
+	return nil, nil
+}
+
+// compareValues orders numbers by value and times chronologically. Missing values come first.
+func compareValues(a, b any) int {
+	if a == nil || b == nil {
+		return cmp.Compare(boolRank(a != nil), boolRank(b != nil))
+	}
+	if fa, ok := asFloat(a); ok {
+		if fb, ok := asFloat(b); ok {
+			return cmp.Compare(fa, fb)
+		}
+	}
+	switch va := a.(type) {
+	case time.Time:
+		if vb, ok := b.(time.Time); ok {
+			return va.Compare(vb)
+		}
+	case bool:
+		if vb, ok := b.(bool); ok {
+			return cmp.Compare(boolRank(va), boolRank(vb))
+		}
+	}
+	return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func asFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// columnMapping returns, for each target column, the index of the same column in source (or -1)
+func columnMapping(target, source []*resourcepb.ResourceTableColumnDefinition) []int {
+	same := len(target) == len(source)
+	mapping := make([]int, len(target))
+	for i, t := range target {
+		mapping[i] = slices.IndexFunc(source, func(c *resourcepb.ResourceTableColumnDefinition) bool {
+			return c.Name == t.Name
+		})
+		if mapping[i] != i {
+			same = false
+		}
+	}
+	if same {
+		return nil
+	}
+	return mapping
+}
+
+func remapRow(row *resourcepb.ResourceTableRow, mapping []int) *resourcepb.ResourceTableRow {
+	if mapping == nil {
+		return row
+	}
+	cells := make([][]byte, len(mapping))
+	for i, idx := range mapping {
+		if idx >= 0 && idx < len(row.Cells) {
+			cells[i] = row.Cells[idx]
+		}
+	}
+	return &resourcepb.ResourceTableRow{
+		Key:             row.Key,
+		ResourceVersion: row.ResourceVersion,
+		Cells:           cells,
+		Object:          row.Object,
+		SortFields:      row.SortFields,
+	}
+}
+
+func mergeFacets(merged *resourcepb.ResourceSearchResponse, facets map[string]*resourcepb.ResourceSearchResponse_Facet) {
+	if len(facets) == 0 {
+		return
+	}
+	if merged.Facet == nil {
+		merged.Facet = make(map[string]*resourcepb.ResourceSearchResponse_Facet, len(facets))
+	}
+	for k, f := range facets {
+		m, ok := merged.Facet[k]
+		if !ok {
+			m = &resourcepb.ResourceSearchResponse_Facet{Field: f.Field}
+			merged.Facet[k] = m
+		}
+		m.Total += f.Total
+		m.Missing += f.Missing
+		for _, t := range f.Terms {
+			idx := slices.IndexFunc(m.Terms, func(e *resourcepb.ResourceSearchResponse_TermFacet) bool {
+				return e.Term == t.Term
+			})
+			if idx < 0 {
+				m.Terms = append(m.Terms, &resourcepb.ResourceSearchResponse_TermFacet{Term: t.Term, Count: t.Count})
+				continue
+			}
+			m.Terms[idx].Count += t.Count
+		}
+	}
+}
+
+// limitFacets sorts terms by count and trims them to the requested limit
+func limitFacets(merged *resourcepb.ResourceSearchResponse, req map[string]*resourcepb.ResourceSearchRequest_Facet) {
+	for k, f := range merged.Facet {
+		slices.SortStableFunc(f.Terms, func(a, b *resourcepb.ResourceSearchResponse_TermFacet) int {
+			if c := cmp.Compare(b.Count, a.Count); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Term, b.Term)
+		})
+		if r, ok := req[k]; ok && r.Limit > 0 && int64(len(f.Terms)) > r.Limit {
+			f.Terms = f.Terms[:r.Limit]
+		}
+	}
+}
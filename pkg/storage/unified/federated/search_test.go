@@ -0,0 +1,310 @@
+package federated
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fullstorydev/grpchan/inprocgrpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	authzlib "github.com/grafana/authlib/authz"
+	"github.com/grafana/authlib/cache"
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+type fakeIndexClient struct {
+	resourcepb.ResourceIndexClient
+
+	mu        sync.Mutex
+	responses map[string]*resourcepb.ResourceSearchResponse
+	errors    map[string]error
+	requests  []*resourcepb.ResourceSearchRequest
+}
+
+func (f *fakeIndexClient) Search(_ context.Context, in *resourcepb.ResourceSearchRequest, _ ...grpc.CallOption) (*resourcepb.ResourceSearchResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, in)
+	if err := f.errors[in.Options.Key.Namespace]; err != nil {
+		return nil, err
+	}
+	return f.responses[in.Options.Key.Namespace], nil
+}
+
+// authorizingIndexClient checks access to every search with the identity in the context,
+// like the search server does
+type authorizingIndexClient struct {
+	*fakeIndexClient
+
+	access claims.AccessClient
+}
+
+func (c *authorizingIndexClient) Search(ctx context.Context, in *resourcepb.ResourceSearchRequest, opts ...grpc.CallOption) (*resourcepb.ResourceSearchResponse, error) {
+	user, ok := claims.AuthInfoFrom(ctx)
+	if !ok {
+		return nil, errors.New("no user found in context")
+	}
+	a, err := c.access.Check(ctx, user, claims.CheckRequest{
+		Verb:      utils.VerbList,
+		Group:     in.Options.Key.Group,
+		Resource:  in.Options.Key.Resource,
+		Namespace: in.Options.Key.Namespace,
+	}, "")
+	if err != nil {
+		return nil, err
+	}
+	if !a.Allowed {
+		return &resourcepb.ResourceSearchResponse{
+			Error: &resourcepb.ErrorResult{Code: http.StatusForbidden, Message: "forbidden"},
+		}, nil
+	}
+	return c.fakeIndexClient.Search(ctx, in, opts...)
+}
+
+type staticNamespaces []string
+
+func (s staticNamespaces) ListNamespaces(_ context.Context) ([]string, error) {
+	return s, nil
+}
+
+func searchResponse(t *testing.T, ns string, facetTerms map[string]int64, hits map[string]float64) *resourcepb.ResourceSearchResponse {
+	t.Helper()
+
+	builder, err := resource.NewTableBuilder([]*resourcepb.ResourceTableColumnDefinition{
+		{Name: resource.SEARCH_FIELD_TITLE, Type: resourcepb.ResourceTableColumnDefinition_STRING},
+		{Name: resource.SEARCH_FIELD_SCORE, Type: resourcepb.ResourceTableColumnDefinition_DOUBLE},
+	})
+	require.NoError(t, err)
+
+	for name, score := range hits {
+		err = builder.AddRow(&resourcepb.ResourceKey{
+			Namespace: ns,
+			Group:     "dashboard.grafana.app",
+			Resource:  "dashboards",
+			Name:      name,
+		}, 1, map[string]any{
+			resource.SEARCH_FIELD_TITLE: name,
+			resource.SEARCH_FIELD_SCORE: score,
+		})
+		require.NoError(t, err)
+	}
+
+	facet := &resourcepb.ResourceSearchResponse_Facet{Field: "tags", Total: int64(len(hits))}
+	for term, count := range facetTerms {
+		facet.Terms = append(facet.Terms, &resourcepb.ResourceSearchResponse_TermFacet{Term: term, Count: count})
+	}
+
+	return &resourcepb.ResourceSearchResponse{
+		Results:   &builder.ResourceTable,
+		TotalHits: int64(len(hits)),
+		Facet:     map[string]*resourcepb.ResourceSearchResponse_Facet{"tags": facet},
+	}
+}
+
+func TestCrossNamespaceSearch(t *testing.T) {
+	admin := &identity.StaticRequester{
+		Type:           claims.TypeUser,
+		UserUID:        "admin",
+		Namespace:      "default",
+		OrgID:          1,
+		IsGrafanaAdmin: true,
+	}
+	ctx := identity.WithRequester(context.Background(), admin)
+	request := func() *resourcepb.ResourceSearchRequest {
+		return &resourcepb.ResourceSearchRequest{
+			Options: &resourcepb.ListOptions{
+				Key: &resourcepb.ResourceKey{
+					Group:    "dashboard.grafana.app",
+					Resource: "dashboards",
+				},
+			},
+			Query: "cpu",
+			Limit: 10,
+			Facet: map[string]*resourcepb.ResourceSearchRequest_Facet{
+				"tags": {Field: "tags", Limit: 2},
+			},
+		}
+	}
+
+	client := &fakeIndexClient{
+		responses: map[string]*resourcepb.ResourceSearchResponse{
+			"default": searchResponse(t, "default", map[string]int64{"prod": 2, "infra": 1}, map[string]float64{"cpu-a": 0.5}),
+			"org-2":   searchResponse(t, "org-2", map[string]int64{"prod": 1, "dev": 3}, map[string]float64{"cpu-b": 0.9, "cpu-c": 0.1}),
+			"org-3":   searchResponse(t, "org-3", nil, map[string]float64{"cpu-d": 1}),
+		},
+	}
+
+	t.Run("searches all namespaces and merges by score", func(t *testing.T) {
+		client.requests = nil
+		searcher := NewCrossNamespaceSearcher(client, staticNamespaces{"default", "org-2", "org-3"})
+
+		rsp, err := searcher.Search(ctx, &CrossNamespaceSearchRequest{Request: request()})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Equal(t, []string{"default", "org-2", "org-3"}, rsp.Searched)
+		require.Len(t, client.requests, 3)
+
+		require.Equal(t, int64(4), rsp.TotalHits)
+		names := []string{}
+		for _, row := range rsp.Results.Rows {
+			names = append(names, row.Key.Name)
+		}
+		require.Equal(t, []string{"cpu-d", "cpu-b", "cpu-a", "cpu-c"}, names)
+
+		terms := rsp.Facet["tags"].Terms
+		require.Len(t, terms, 2)
+		require.Equal(t, "dev", terms[0].Term)
+		require.Equal(t, int64(3), terms[0].Count)
+		require.Equal(t, "prod", terms[1].Term)
+		require.Equal(t, int64(3), terms[1].Count)
+	})
+
+	t.Run("every namespace is authorized by the access client of storage", func(t *testing.T) {
+		access := resource.NewAuthzLimitedClient(authzlib.NewClient(
+			&inprocgrpc.Channel{},
+			authzlib.WithCacheClientOption(cache.NewLocalCache(cache.Config{Expiry: time.Minute, CleanupInterval: time.Minute})),
+		), resource.AuthzOptions{Registry: prometheus.NewRegistry()})
+		authorizing := &authorizingIndexClient{fakeIndexClient: &fakeIndexClient{responses: client.responses}, access: access}
+		searcher := NewCrossNamespaceSearcher(authorizing, nil)
+
+		rsp, err := searcher.Search(ctx, &CrossNamespaceSearchRequest{
+			Namespaces: []string{"default", "org-2", "org-3"},
+			Request:    request(),
+		})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Empty(t, rsp.Errors)
+		require.Len(t, rsp.Results.Rows, 4)
+	})
+
+	t.Run("applies offset and limit after merging", func(t *testing.T) {
+		searcher := NewCrossNamespaceSearcher(client, nil)
+
+		req := request()
+		req.Offset = 1
+		req.Limit = 1
+		rsp, err := searcher.Search(ctx, &CrossNamespaceSearchRequest{
+			Namespaces: []string{"default", "org-2"},
+			Request:    req,
+		})
+		require.NoError(t, err)
+		require.Len(t, rsp.Results.Rows, 1)
+		require.Equal(t, "cpu-a", rsp.Results.Rows[0].Key.Name)
+	})
+
+	t.Run("returns the results of the other namespaces when one fails", func(t *testing.T) {
+		failing := &fakeIndexClient{
+			responses: client.responses,
+			errors:    map[string]error{"org-2": errors.New("index unavailable")},
+		}
+		searcher := NewCrossNamespaceSearcher(failing, nil)
+
+		rsp, err := searcher.Search(ctx, &CrossNamespaceSearchRequest{
+			Namespaces: []string{"default", "org-2"},
+			Request:    request(),
+		})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Equal(t, []string{"default", "org-2"}, rsp.Searched)
+		require.Len(t, rsp.Errors, 1)
+		require.Contains(t, rsp.Errors["org-2"].Message, "index unavailable")
+		require.Len(t, rsp.Results.Rows, 1)
+		require.Equal(t, "cpu-a", rsp.Results.Rows[0].Key.Name)
+	})
+
+	t.Run("fails when every namespace fails", func(t *testing.T) {
+		failing := &fakeIndexClient{
+			errors: map[string]error{"default": errors.New("index unavailable")},
+		}
+		searcher := NewCrossNamespaceSearcher(failing, nil)
+
+		rsp, err := searcher.Search(ctx, &CrossNamespaceSearchRequest{
+			Namespaces: []string{"default"},
+			Request:    request(),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, rsp.Error)
+		require.Contains(t, rsp.Errors, "default")
+	})
+
+	t.Run("forbidden for users that are not server admins", func(t *testing.T) {
+		client.requests = nil
+		searcher := NewCrossNamespaceSearcher(client, nil)
+
+		editor := &identity.StaticRequester{Type: claims.TypeUser, UserUID: "editor", Namespace: "default", OrgID: 1, OrgRole: identity.RoleAdmin}
+		rsp, err := searcher.Search(identity.WithRequester(context.Background(), editor), &CrossNamespaceSearchRequest{
+			Namespaces: []string{"default"},
+			Request:    request(),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, rsp.Error)
+		require.Equal(t, int32(http.StatusForbidden), rsp.Error.Code)
+		require.Empty(t, client.requests)
+	})
+
+	t.Run("sorts by typed values and merges the columns of every namespace", func(t *testing.T) {
+		views := func(ns string, columns []*resourcepb.ResourceTableColumnDefinition, rows map[string]int64) *resourcepb.ResourceSearchResponse {
+			builder, err := resource.NewTableBuilder(columns)
+			require.NoError(t, err)
+			for name, v := range rows {
+				err = builder.AddRow(&resourcepb.ResourceKey{
+					Namespace: ns,
+					Group:     "dashboard.grafana.app",
+					Resource:  "dashboards",
+					Name:      name,
+				}, 1, map[string]any{resource.SEARCH_FIELD_TITLE: name, "views": v})
+				require.NoError(t, err)
+			}
+			// The raw sort values of the index only order strings
+			for _, row := range builder.Rows {
+				v, err := resource.DecodeCell(columns[1], 1, row.Cells[1])
+				require.NoError(t, err)
+				row.SortFields = []string{strconv.FormatInt(v.(int64), 10)}
+			}
+			return &resourcepb.ResourceSearchResponse{Results: &builder.ResourceTable, TotalHits: int64(len(rows))}
+		}
+		title := &resourcepb.ResourceTableColumnDefinition{Name: resource.SEARCH_FIELD_TITLE, Type: resourcepb.ResourceTableColumnDefinition_STRING}
+		viewCount := &resourcepb.ResourceTableColumnDefinition{Name: "views", Type: resourcepb.ResourceTableColumnDefinition_INT64}
+		folder := &resourcepb.ResourceTableColumnDefinition{Name: resource.SEARCH_FIELD_FOLDER, Type: resourcepb.ResourceTableColumnDefinition_STRING}
+
+		sorted := &fakeIndexClient{
+			responses: map[string]*resourcepb.ResourceSearchResponse{
+				"default": views("default", []*resourcepb.ResourceTableColumnDefinition{title, viewCount}, map[string]int64{"nine": 9}),
+				"org-2":   views("org-2", []*resourcepb.ResourceTableColumnDefinition{title, viewCount, folder}, map[string]int64{"ten": 10, "two": 2}),
+			},
+		}
+		searcher := NewCrossNamespaceSearcher(sorted, nil)
+
+		req := request()
+		req.Query = ""
+		req.SortBy = []*resourcepb.ResourceSearchRequest_Sort{{Field: resource.SEARCH_FIELD_PREFIX + "views"}}
+		rsp, err := searcher.Search(ctx, &CrossNamespaceSearchRequest{
+			Namespaces: []string{"default", "org-2"},
+			Request:    req,
+		})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+
+		names := []string{}
+		for _, row := range rsp.Results.Rows {
+			names = append(names, row.Key.Name)
+			require.Len(t, row.Cells, 3)
+		}
+		require.Equal(t, []string{"two", "nine", "ten"}, names)
+		require.Equal(t, []string{resource.SEARCH_FIELD_TITLE, "views", resource.SEARCH_FIELD_FOLDER}, []string{
+			rsp.Results.Columns[0].Name, rsp.Results.Columns[1].Name, rsp.Results.Columns[2].Name,
+		})
+	})
+}
@@ -0,0 +1,895 @@
+package resource
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/storage/unified/resource/kv"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+const (
+	auditChainSection      = kv.AuditChainSection
+	auditCheckpointSection = kv.AuditCheckpointSection
+
+	defaultAuditCheckpointInterval = 15 * time.Minute
+
+	// A link claims its predecessor by creating this key next to it, so two links can not
+	// follow the same predecessor
+	auditSuccessorSuffix = ".next"
+
+	// Concurrent appends to the same object are retried this many times
+	auditAppendAttempts = 5
+)
+
+// AuditChainLink is written for every change to a resource. The hash covers the hash
+// of the previous link for the same object, so rewriting or removing a history row
+// breaks every link that follows it.
+type AuditChainLink struct {
+	Group           string `json:"group"`
+	Resource        string `json:"resource"`
+	Namespace       string `json:"namespace"`
+	Name            string `json:"name"`
+	ResourceVersion int64  `json:"rv"`
+	Action          string `json:"action"`
+	ValueHash       string `json:"valueHash"`
+	PreviousRV      int64  `json:"previousRV,omitempty"`
+	PreviousHash    string `json:"previousHash,omitempty"`
+	Hash            string `json:"hash"`
+}
+
+func (l *AuditChainLink) computeHash() string {
+	h := sha256.New()
+	for _, v := range []string{
+		l.PreviousHash,
+		l.Namespace,
+		l.Group,
+		l.Resource,
+		l.Name,
+		strconv.FormatInt(l.ResourceVersion, 10),
+		l.Action,
+		l.ValueHash,
+	} {
+		_, _ = h.Write([]byte(v))
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (l *AuditChainLink) objectKey() string {
+	return l.Group + "/" + l.Resource + "/" + l.Name
+}
+
+// AuditCheckpoint signs every link added to a namespace since the previous checkpoint.
+// The digest covers the digest of the previous checkpoint, so the checkpoints form a
+// chain of their own. Since the signing key is not stored with the data, a rewritten
+// chain no longer matches the checkpoints.
+//
+// Links are numbered in the order they are appended to the namespace, which is not
+// the order of their resource versions when writes commit concurrently, so checkpoints
+// cover a range of those numbers.
+type AuditCheckpoint struct {
+	Namespace        string    `json:"namespace"`
+	Sequence         int64     `json:"seq"`                   // the last link included
+	PreviousSequence int64     `json:"previousSeq,omitempty"` // the previous checkpoint
+	ResourceVersion  int64     `json:"rv"`                    // the highest resource version included
+	Links            int       `json:"links"`                 // links added since the previous checkpoint
+	Digest           string    `json:"digest"`
+	KeyID            string    `json:"keyId"`
+	Signature        string    `json:"signature"`
+	Created          time.Time `json:"created"`
+}
+
+func (c *AuditCheckpoint) message() []byte {
+	return []byte(fmt.Sprintf("%s\n%d\n%d\n%d\n%d\n%s", c.Namespace, c.Sequence, c.PreviousSequence, c.ResourceVersion, c.Links, c.Digest))
+}
+
+// AuditHistoryBoundary marks the links of an object whose history rows the history pruner
+// may have removed: the links up to ResourceVersion, older than the versions it keeps.
+// Their history is not compared with the chain. The boundary is signed like the
+// checkpoints, so moving it to hide removed rows does not verify.
+type AuditHistoryBoundary struct {
+	Namespace       string `json:"namespace"`
+	Group           string `json:"group"`
+	Resource        string `json:"resource"`
+	Name            string `json:"name"`
+	ResourceVersion int64  `json:"rv"`
+	KeyID           string `json:"keyId,omitempty"`
+	Signature       string `json:"signature,omitempty"`
+}
+
+func (b *AuditHistoryBoundary) message() []byte {
+	return []byte(fmt.Sprintf("history\n%s\n%s\n%s\n%s\n%d", b.Namespace, b.Group, b.Resource, b.Name, b.ResourceVersion))
+}
+
+func (b *AuditHistoryBoundary) objectKey() string {
+	return b.Group + "/" + b.Resource + "/" + b.Name
+}
+
+// AuditChainSigner signs checkpoints
+type AuditChainSigner interface {
+	KeyID() string
+	Sign(msg []byte) ([]byte, error)
+}
+
+// AuditChainVerifier verifies checkpoint signatures. It only holds the public key, so
+// the checkpoints can be verified where the signing key is not available.
+type AuditChainVerifier interface {
+	KeyID() string
+	Verify(msg, sig []byte) bool
+}
+
+func auditKeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+type ed25519AuditChainSigner struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewEd25519AuditChainSigner creates a checkpoint signer from a 32 byte ed25519 seed
+func NewEd25519AuditChainSigner(seed []byte) (AuditChainSigner, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("audit chain signing key must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	key := ed25519.NewKeyFromSeed(seed)
+	return &ed25519AuditChainSigner{
+		key:   key,
+		keyID: auditKeyID(key.Public().(ed25519.PublicKey)),
+	}, nil
+}
+
+func (s *ed25519AuditChainSigner) KeyID() string {
+	return s.keyID
+}
+
+func (s *ed25519AuditChainSigner) Sign(msg []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, msg), nil
+}
+
+type ed25519AuditChainVerifier struct {
+	key   ed25519.PublicKey
+	keyID string
+}
+
+// NewEd25519AuditChainVerifier creates a checkpoint verifier from a 32 byte ed25519 public key
+func NewEd25519AuditChainVerifier(publicKey []byte) (AuditChainVerifier, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("audit chain public key must be %d bytes, got %d", ed25519.PublicKeySize, len(publicKey))
+	}
+	key := ed25519.PublicKey(publicKey)
+	return &ed25519AuditChainVerifier{
+		key:   key,
+		keyID: auditKeyID(key),
+	}, nil
+}
+
+func (v *ed25519AuditChainVerifier) KeyID() string {
+	return v.keyID
+}
+
+func (v *ed25519AuditChainVerifier) Verify(msg, sig []byte) bool {
+	return ed25519.Verify(v.key, msg, sig)
+}
+
+// AuditChainOptions configures the tamper evident history chain
+type AuditChainOptions struct {
+	KV KV
+
+	// Signs periodic checkpoints. When nil, links are written but no checkpoints.
+	Signer AuditChainSigner
+
+	// Verifies the checkpoints. It is configured with the public key on its own, never
+	// derived from the signer, so a replaced signing key does not verify.
+	Verifier AuditChainVerifier
+
+	// How often namespaces with new links are checkpointed (defaults to 15m)
+	CheckpointInterval time.Duration
+
+	// Returns how many versions of a resource the history pruner keeps, or zero when the
+	// history is not pruned. The history of older links is not compared with the chain.
+	HistoryLimit func(group, resource string) int
+}
+
+// AuditChain keeps a hash chain next to the resource history
+type AuditChain struct {
+	kv                 KV
+	signer             AuditChainSigner
+	verifier           AuditChainVerifier
+	checkpointInterval time.Duration
+	historyLimit       func(group, resource string) int
+	log                log.Logger
+	now                func() time.Time
+
+	mu         sync.Mutex
+	dirty      map[string]struct{}    // namespaces with links written since the last checkpoint
+	namespaces map[string]*sync.Mutex // serializes the appends and checkpoints of each namespace
+}
+
+func NewAuditChain(opts AuditChainOptions) (*AuditChain, error) {
+	if opts.KV == nil {
+		return nil, fmt.Errorf("audit chain requires a KV store")
+	}
+	if opts.CheckpointInterval <= 0 {
+		opts.CheckpointInterval = defaultAuditCheckpointInterval
+	}
+	return &AuditChain{
+		kv:                 opts.KV,
+		signer:             opts.Signer,
+		verifier:           opts.Verifier,
+		checkpointInterval: opts.CheckpointInterval,
+		historyLimit:       opts.HistoryLimit,
+		log:                log.New("resource-audit-chain"),
+		now:                time.Now,
+		dirty:              make(map[string]struct{}),
+		namespaces:         make(map[string]*sync.Mutex),
+	}, nil
+}
+
+// lockNamespace serializes the appends and checkpoints of the namespace in this process.
+// Appends from other processes are serialized by the sequence numbers they claim.
+func (c *AuditChain) lockNamespace(namespace string) func() {
+	c.mu.Lock()
+	mu, ok := c.namespaces[namespace]
+	if !ok {
+		mu = &sync.Mutex{}
+		c.namespaces[namespace] = mu
+	}
+	c.mu.Unlock()
+
+	mu.Lock()
+	return mu.Unlock
+}
+
+func auditLinkPrefix(namespace string) string {
+	return namespace + "/"
+}
+
+func auditObjectPrefix(key *resourcepb.ResourceKey) string {
+	return key.Namespace + "/" + key.Group + "/" + key.Resource + "/" + key.Name + "/"
+}
+
+// Resource versions and sequence numbers are zero padded so the keys sort numerically
+func auditRV(rv int64) string {
+	return fmt.Sprintf("%019d", rv)
+}
+
+// auditIndexPrefix lists the links of a namespace by sequence number, in the order they
+// were appended, so a checkpoint only reads the links added since the previous one.
+// Namespaces can not start with '~', so the index is not listed with the links.
+func auditIndexPrefix(namespace string) string {
+	return "~seq/" + namespace + "/"
+}
+
+// auditHistoryPrefix lists the history boundaries of the objects of a namespace
+func auditHistoryPrefix(namespace string) string {
+	return "~history/" + namespace + "/"
+}
+
+// lastSequence returns the sequence number of the last link indexed in the namespace
+func (c *AuditChain) lastSequence(ctx context.Context, namespace string) (int64, error) {
+	index := auditIndexPrefix(namespace)
+	latest, err := c.keys(ctx, auditChainSection, index, SortOrderDesc, 1)
+	if err != nil || len(latest) == 0 {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimPrefix(latest[0], index), 10, 64)
+}
+
+func isAuditSuccessorKey(key string) bool {
+	return strings.HasSuffix(key, auditSuccessorSuffix)
+}
+
+func (c *AuditChain) readJSON(ctx context.Context, section, key string, v any) error {
+	r, err := c.kv.Get(ctx, section, key)
+	if err != nil {
+		return err
+	}
+	data, err := readAndClose(r)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (c *AuditChain) writeJSON(ctx context.Context, section, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w, err := c.kv.Save(ctx, section, key)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// keys returns the keys under the prefix.  They are collected before reading any
+// value, since some KV implementations can not read while a listing is open.
+func (c *AuditChain) keys(ctx context.Context, section, prefix string, order SortOrder, limit int64) ([]string, error) {
+	var keys []string
+	for k, err := range c.kv.Keys(ctx, section, ListOptions{
+		StartKey: prefix,
+		EndKey:   PrefixRangeEnd(prefix),
+		Sort:     order,
+		Limit:    limit,
+	}) {
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// Append links the event written at rv to the latest link of the same object. The link
+// is written together with a claim on its predecessor and on the next sequence number of
+// the namespace, so a concurrent append that read the same predecessor or sequence number
+// fails and is retried on top of the new link.
+func (c *AuditChain) Append(ctx context.Context, event *WriteEvent, rv int64) error {
+	unlock := c.lockNamespace(event.Key.Namespace)
+	defer unlock()
+
+	var err error
+	for range auditAppendAttempts {
+		err = c.append(ctx, event, rv)
+		if !errors.Is(err, kv.ErrKeyAlreadyExists) {
+			return err
+		}
+	}
+	return fmt.Errorf("audit chain link at rv %d kept conflicting with concurrent writes: %w", rv, err)
+}
+
+func (c *AuditChain) append(ctx context.Context, event *WriteEvent, rv int64) error {
+	link := &AuditChainLink{
+		Group:           event.Key.Group,
+		Resource:        event.Key.Resource,
+		Namespace:       event.Key.Namespace,
+		Name:            event.Key.Name,
+		ResourceVersion: rv,
+		Action:          event.Type.String(),
+	}
+	sum := sha256.Sum256(event.Value)
+	link.ValueHash = hex.EncodeToString(sum[:])
+
+	prefix := auditObjectPrefix(event.Key)
+	latest, err := c.keys(ctx, auditChainSection, prefix, SortOrderDesc, 1)
+	if err != nil {
+		return err
+	}
+	for _, k := range latest {
+		prev := &AuditChainLink{}
+		if err := c.readJSON(ctx, auditChainSection, k, prev); err != nil {
+			return err
+		}
+		if prev.ResourceVersion >= rv {
+			return fmt.Errorf("audit chain already has a link at rv %d, after rv %d", prev.ResourceVersion, rv)
+		}
+		link.PreviousRV = prev.ResourceVersion
+		link.PreviousHash = prev.Hash
+	}
+	link.Hash = link.computeHash()
+
+	data, err := json.Marshal(link)
+	if err != nil {
+		return err
+	}
+	seq, err := c.lastSequence(ctx, link.Namespace)
+	if err != nil {
+		return err
+	}
+	linkKey := prefix + auditRV(rv)
+	if err := c.kv.Batch(ctx, auditChainSection, []BatchOp{
+		// The first link of an object claims rv 0
+		{Mode: kv.BatchOpCreate, Key: prefix + auditRV(link.PreviousRV) + auditSuccessorSuffix, Value: []byte(link.Hash)},
+		{Mode: kv.BatchOpCreate, Key: linkKey, Value: data},
+		{Mode: kv.BatchOpCreate, Key: auditIndexPrefix(link.Namespace) + auditRV(seq+1), Value: []byte(linkKey)},
+	}); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.dirty[link.Namespace] = struct{}{}
+	c.mu.Unlock()
+
+	if err := c.moveHistoryBoundary(ctx, event.Key); err != nil {
+		return fmt.Errorf("audit chain history boundary at rv %d: %w", rv, err)
+	}
+	return nil
+}
+
+// moveHistoryBoundary moves the history boundary of the object to the newest link whose
+// history row the pruner may remove, which is older than every version the pruner keeps
+func (c *AuditChain) moveHistoryBoundary(ctx context.Context, key *resourcepb.ResourceKey) error {
+	if c.historyLimit == nil {
+		return nil
+	}
+	limit := c.historyLimit(key.Group, key.Resource)
+	if limit <= 0 {
+		return nil
+	}
+
+	// Every link but the latest is followed by the claim of its successor
+	prefix := auditObjectPrefix(key)
+	keys, err := c.keys(ctx, auditChainSection, prefix, SortOrderDesc, int64(2*limit+1))
+	if err != nil {
+		return err
+	}
+	keys = slices.DeleteFunc(keys, isAuditSuccessorKey)
+	if len(keys) <= limit {
+		return nil
+	}
+	rv, err := strconv.ParseInt(strings.TrimPrefix(keys[limit], prefix), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	boundary := &AuditHistoryBoundary{
+		Namespace:       key.Namespace,
+		Group:           key.Group,
+		Resource:        key.Resource,
+		Name:            key.Name,
+		ResourceVersion: rv,
+	}
+	if c.signer != nil {
+		sig, err := c.signer.Sign(boundary.message())
+		if err != nil {
+			return err
+		}
+		boundary.KeyID = c.signer.KeyID()
+		boundary.Signature = hex.EncodeToString(sig)
+	}
+	return c.writeJSON(ctx, auditChainSection, auditHistoryPrefix(key.Namespace)+boundary.objectKey(), boundary)
+}
+
+// links returns every link in the namespace, grouped by object and sorted by resource version,
+// and by KV key
+func (c *AuditChain) links(ctx context.Context, namespace string) (map[string][]*AuditChainLink, map[string]*AuditChainLink, error) {
+	prefix := auditLinkPrefix(namespace)
+	keys, err := c.keys(ctx, auditChainSection, prefix, SortOrderAsc, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	objects := map[string][]*AuditChainLink{}
+	byKey := map[string]*AuditChainLink{}
+	for _, k := range keys {
+		if isAuditSuccessorKey(k) {
+			continue
+		}
+		link := &AuditChainLink{}
+		if err := c.readJSON(ctx, auditChainSection, k, link); err != nil {
+			return nil, nil, fmt.Errorf("reading audit link %s: %w", k, err)
+		}
+		// The object key is read from the KV key, so a link copied to another object does not verify
+		objectKey := strings.TrimPrefix(k[:strings.LastIndex(k, "/")], prefix)
+		objects[objectKey] = append(objects[objectKey], link)
+		byKey[k] = link
+	}
+	for _, links := range objects {
+		sort.Slice(links, func(i, j int) bool {
+			return links[i].ResourceVersion < links[j].ResourceVersion
+		})
+	}
+	return objects, byKey, nil
+}
+
+// indexedLink is a link of the namespace index
+type indexedLink struct {
+	seq  int64
+	key  string
+	link *AuditChainLink
+}
+
+// index returns the links of the namespace index with a sequence number above after, in
+// the order they were appended
+func (c *AuditChain) index(ctx context.Context, namespace string, after int64) ([]indexedLink, error) {
+	index := auditIndexPrefix(namespace)
+	var keys []string
+	for k, err := range c.kv.Keys(ctx, auditChainSection, ListOptions{
+		StartKey: index + auditRV(after+1),
+		EndKey:   PrefixRangeEnd(index),
+		Sort:     SortOrderAsc,
+	}) {
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	links := make([]indexedLink, 0, len(keys))
+	for _, k := range keys {
+		seq, err := strconv.ParseInt(strings.TrimPrefix(k, index), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("reading audit index %s: %w", k, err)
+		}
+		r, err := c.kv.Get(ctx, auditChainSection, k)
+		if err != nil {
+			return nil, err
+		}
+		linkKey, err := readAndClose(r)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, indexedLink{seq: seq, key: string(linkKey)})
+	}
+	return links, nil
+}
+
+// checkpointDigest chains the digest of the previous checkpoint with the links added
+// since, in the order they were appended. Links missing from the chain are digested by
+// their key, so they do not match.
+func checkpointDigest(previous string, links []indexedLink) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\n", previous)
+	for _, l := range links {
+		if l.link == nil {
+			_, _ = fmt.Fprintf(h, "%d missing %s\n", l.seq, l.key)
+			continue
+		}
+		_, _ = fmt.Fprintf(h, "%d %s %d %s\n", l.seq, l.link.objectKey(), l.link.ResourceVersion, l.link.Hash)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lastCheckpoint returns the latest checkpoint of the namespace, or nil before the first one
+func (c *AuditChain) lastCheckpoint(ctx context.Context, namespace string) (*AuditCheckpoint, error) {
+	latest, err := c.keys(ctx, auditCheckpointSection, auditLinkPrefix(namespace), SortOrderDesc, 1)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range latest {
+		cp := &AuditCheckpoint{}
+		if err := c.readJSON(ctx, auditCheckpointSection, k, cp); err != nil {
+			return nil, fmt.Errorf("reading audit checkpoint %s: %w", k, err)
+		}
+		return cp, nil
+	}
+	return nil, nil
+}
+
+// Checkpoint signs the links added to the namespace since the previous checkpoint.
+// Only those links are read, so the cost does not grow with the history.
+func (c *AuditChain) Checkpoint(ctx context.Context, namespace string) (*AuditCheckpoint, error) {
+	if c.signer == nil {
+		return nil, fmt.Errorf("audit chain checkpoints require a signing key")
+	}
+	unlock := c.lockNamespace(namespace)
+	defer unlock()
+
+	last, err := c.lastCheckpoint(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	cp := &AuditCheckpoint{
+		Namespace: namespace,
+		KeyID:     c.signer.KeyID(),
+		Created:   c.now().UTC(),
+	}
+	previousDigest := ""
+	if last != nil {
+		cp.PreviousSequence = last.Sequence
+		previousDigest = last.Digest
+	}
+
+	links, err := c.index(ctx, namespace, cp.PreviousSequence)
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+	for i, l := range links {
+		link := &AuditChainLink{}
+		if err := c.readJSON(ctx, auditChainSection, l.key, link); err != nil {
+			return nil, fmt.Errorf("reading audit link %s: %w", l.key, err)
+		}
+		links[i].link = link
+		cp.ResourceVersion = max(cp.ResourceVersion, link.ResourceVersion)
+	}
+	cp.Sequence = links[len(links)-1].seq
+	cp.Links = len(links)
+	cp.Digest = checkpointDigest(previousDigest, links)
+
+	sig, err := c.signer.Sign(cp.message())
+	if err != nil {
+		return nil, err
+	}
+	cp.Signature = hex.EncodeToString(sig)
+
+	if err := c.writeJSON(ctx, auditCheckpointSection, auditLinkPrefix(namespace)+auditRV(cp.Sequence), cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// checkpointDirty writes checkpoints for the namespaces changed since the last call
+func (c *AuditChain) checkpointDirty(ctx context.Context) {
+	c.mu.Lock()
+	namespaces := make([]string, 0, len(c.dirty))
+	for ns := range c.dirty {
+		namespaces = append(namespaces, ns)
+	}
+	c.dirty = make(map[string]struct{})
+	c.mu.Unlock()
+
+	for _, ns := range namespaces {
+		if _, err := c.Checkpoint(ctx, ns); err != nil {
+			c.log.Error("failed to write audit checkpoint", "namespace", ns, "error", err)
+			c.mu.Lock()
+			c.dirty[ns] = struct{}{} // retry on the next tick
+			c.mu.Unlock()
+		}
+	}
+}
+
+// AuditChainBreak describes a link or checkpoint that failed verification
+type AuditChainBreak struct {
+	Group           string `json:"group,omitempty"`
+	Resource        string `json:"resource,omitempty"`
+	Name            string `json:"name,omitempty"`
+	ResourceVersion int64  `json:"rv"`
+	Reason          string `json:"reason"`
+}
+
+// AuditChainReport is returned by Verify
+type AuditChainReport struct {
+	Namespace   string            `json:"namespace"`
+	Objects     int               `json:"objects"`
+	Links       int               `json:"links"`
+	Pruned      int               `json:"pruned"` // links whose history rows may have been pruned
+	Checkpoints int               `json:"checkpoints"`
+	Breaks      []AuditChainBreak `json:"breaks,omitempty"`
+}
+
+// OK is true when no break was found
+func (r *AuditChainReport) OK() bool {
+	return len(r.Breaks) == 0
+}
+
+// AuditValueReader reads the stored history, so the chain can be compared with the content
+type AuditValueReader interface {
+	ReadResource(context.Context, *resourcepb.ReadRequest) *BackendReadResponse
+}
+
+// Verify walks every chain in the namespace and reports links that do not match their
+// predecessor, links that do not match the stored history, and invalid checkpoints.
+// When reader is nil, only the chain itself and the checkpoints are verified. The
+// history of the links behind the history boundary of their object is not read, as the
+// history pruner may have removed it.
+func (c *AuditChain) Verify(ctx context.Context, namespace string, reader AuditValueReader) (*AuditChainReport, error) {
+	objects, byKey, err := c.links(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	report := &AuditChainReport{
+		Namespace: namespace,
+		Objects:   len(objects),
+	}
+	addBreak := func(link *AuditChainLink, reason string, args ...any) {
+		report.Breaks = append(report.Breaks, AuditChainBreak{
+			Group:           link.Group,
+			Resource:        link.Resource,
+			Name:            link.Name,
+			ResourceVersion: link.ResourceVersion,
+			Reason:          fmt.Sprintf(reason, args...),
+		})
+	}
+
+	boundaries, err := c.historyBoundaries(ctx, namespace, report)
+	if err != nil {
+		return report, err
+	}
+
+	for objectKey, links := range objects {
+		var prev *AuditChainLink
+		for _, link := range links {
+			report.Links++
+			if link.Namespace != namespace || link.objectKey() != objectKey {
+				addBreak(link, "link is stored under %s", objectKey)
+			}
+			if link.computeHash() != link.Hash {
+				addBreak(link, "link hash does not match its content")
+			}
+			if prev == nil {
+				if link.PreviousHash != "" {
+					addBreak(link, "missing previous link at rv %d", link.PreviousRV)
+				}
+			} else if link.PreviousHash != prev.Hash || link.PreviousRV != prev.ResourceVersion {
+				addBreak(link, "previous link does not match (expected rv %d)", prev.ResourceVersion)
+			}
+			prev = link
+
+			if link.ResourceVersion <= boundaries[objectKey] {
+				report.Pruned++
+				continue
+			}
+			if reader == nil || link.Action == resourcepb.WatchEvent_DELETED.String() {
+				continue // deletion markers are not readable by resource version
+			}
+			rsp := reader.ReadResource(ctx, &resourcepb.ReadRequest{
+				Key: &resourcepb.ResourceKey{
+					Namespace: link.Namespace,
+					Group:     link.Group,
+					Resource:  link.Resource,
+					Name:      link.Name,
+				},
+				ResourceVersion: link.ResourceVersion,
+			})
+			if rsp.Error != nil {
+				if rsp.Error.Code == http.StatusNotFound {
+					addBreak(link, "history row is missing")
+					continue
+				}
+				return report, GetError(rsp.Error)
+			}
+			sum := sha256.Sum256(rsp.Value)
+			if hex.EncodeToString(sum[:]) != link.ValueHash {
+				addBreak(link, "history value does not match the chain")
+			}
+		}
+	}
+
+	// The checkpoints sign the links of the whole namespace in the order they were appended
+	ordered, err := c.index(ctx, namespace, 0)
+	if err != nil {
+		return report, err
+	}
+	indexed := make(map[string]bool, len(ordered))
+	for i, l := range ordered {
+		ordered[i].link = byKey[l.key]
+		indexed[l.key] = true
+	}
+	for k, link := range byKey {
+		if !indexed[k] {
+			addBreak(link, "link is not in the namespace index")
+		}
+	}
+
+	checkpoints, err := c.keys(ctx, auditCheckpointSection, auditLinkPrefix(namespace), SortOrderAsc, 0)
+	if err != nil {
+		return report, err
+	}
+	var previous *AuditCheckpoint
+	next := 0
+	for _, k := range checkpoints {
+		cp := &AuditCheckpoint{}
+		if err := c.readJSON(ctx, auditCheckpointSection, k, cp); err != nil {
+			return report, fmt.Errorf("reading audit checkpoint %s: %w", k, err)
+		}
+		report.Checkpoints++
+
+		cpBreak := func(reason string) {
+			report.Breaks = append(report.Breaks, AuditChainBreak{ResourceVersion: cp.ResourceVersion, Reason: reason})
+		}
+		start := next
+		for next < len(ordered) && ordered[next].seq <= cp.Sequence {
+			next++
+		}
+		var previousSequence int64
+		previousDigest := ""
+		if previous != nil {
+			previousSequence = previous.Sequence
+			previousDigest = previous.Digest
+		}
+		previous = cp
+
+		sig, err := hex.DecodeString(cp.Signature)
+		switch {
+		case c.verifier == nil:
+			cpBreak("checkpoint can not be verified without the public key")
+		case cp.KeyID != c.verifier.KeyID():
+			cpBreak(fmt.Sprintf("checkpoint signed with unknown key %s", cp.KeyID))
+		case err != nil || cp.Namespace != namespace || !c.verifier.Verify(cp.message(), sig):
+			cpBreak("checkpoint signature is invalid")
+		case cp.PreviousSequence != previousSequence:
+			cpBreak(fmt.Sprintf("checkpoint does not follow the previous checkpoint (expected link %d)", previousSequence))
+		case cp.Links != next-start || cp.Digest != checkpointDigest(previousDigest, ordered[start:next]):
+			cpBreak("chain does not match the signed checkpoint")
+		}
+	}
+
+	sort.Slice(report.Breaks, func(i, j int) bool {
+		a, b := report.Breaks[i], report.Breaks[j]
+		if a.ResourceVersion != b.ResourceVersion {
+			return a.ResourceVersion < b.ResourceVersion
+		}
+		return a.Reason < b.Reason
+	})
+	return report, nil
+}
+
+// historyBoundaries returns the history boundaries of the objects of the namespace, by object
+// key. Boundaries that do not verify are reported and ignored. Without a public key, the
+// boundaries can not be verified and are trusted like the rest of the chain.
+func (c *AuditChain) historyBoundaries(ctx context.Context, namespace string, report *AuditChainReport) (map[string]int64, error) {
+	prefix := auditHistoryPrefix(namespace)
+	keys, err := c.keys(ctx, auditChainSection, prefix, SortOrderAsc, 0)
+	if err != nil {
+		return nil, err
+	}
+	boundaries := make(map[string]int64, len(keys))
+	for _, k := range keys {
+		b := &AuditHistoryBoundary{}
+		if err := c.readJSON(ctx, auditChainSection, k, b); err != nil {
+			return nil, fmt.Errorf("reading audit history boundary %s: %w", k, err)
+		}
+		if c.verifier != nil {
+			sig, err := hex.DecodeString(b.Signature)
+			if err != nil || b.KeyID != c.verifier.KeyID() || b.Namespace != namespace ||
+				b.objectKey() != strings.TrimPrefix(k, prefix) || !c.verifier.Verify(b.message(), sig) {
+				report.Breaks = append(report.Breaks, AuditChainBreak{
+					Group:           b.Group,
+					Resource:        b.Resource,
+					Name:            b.Name,
+					ResourceVersion: b.ResourceVersion,
+					Reason:          "history boundary signature is invalid",
+				})
+				continue
+			}
+		}
+		boundaries[strings.TrimPrefix(k, prefix)] = b.ResourceVersion
+	}
+	return boundaries, nil
+}
+
+// appendAuditLink adds the write to the audit chain. The write is already committed, so a
+// failure can not undo it and is not returned to the caller; it is logged and counted as a
+// degraded operation instead, as the change is missing from the chain until it is repaired.
+func (s *server) appendAuditLink(ctx context.Context, event *WriteEvent, rv int64) {
+	if s.auditChain == nil || rv <= 0 {
+		return
+	}
+	if err := s.auditChain.Append(ctx, event, rv); err != nil {
+		s.log.FromContext(ctx).Error("failed to append audit chain link",
+			"group", event.Key.Group,
+			"resource", event.Key.Resource,
+			"namespace", event.Key.Namespace,
+			"name", event.Key.Name,
+			"rv", rv,
+			"error", err)
+		if s.storageMetrics != nil {
+			s.storageMetrics.DegradedOperations.
+				WithLabelValues("audit_chain_append", "append_error", event.Key.Group, event.Key.Resource).Inc()
+		}
+	}
+}
+
+// startAuditCheckpoints periodically signs the namespaces written since the last checkpoint
+func (s *server) startAuditCheckpoints() {
+	if s.auditChain == nil || s.auditChain.signer == nil {
+		return
+	}
+
+	s.indexersWG.Go(func() {
+		ticker := time.NewTicker(s.auditChain.checkpointInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.auditChain.checkpointDirty(s.ctx)
+			}
+		}
+	})
+}
@@ -0,0 +1,295 @@
+package resource
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource/kv"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// historyReader returns values by resource version, like the history table
+type historyReader map[int64][]byte
+
+func (h historyReader) ReadResource(_ context.Context, req *resourcepb.ReadRequest) *BackendReadResponse {
+	value, ok := h[req.ResourceVersion]
+	if !ok {
+		return &BackendReadResponse{Error: &resourcepb.ErrorResult{Code: http.StatusNotFound}}
+	}
+	return &BackendReadResponse{Value: value, ResourceVersion: req.ResourceVersion}
+}
+
+func newTestAuditChain(t *testing.T, store KV) *AuditChain {
+	t.Helper()
+	seed := make([]byte, ed25519.SeedSize)
+	signer, err := NewEd25519AuditChainSigner(seed)
+	require.NoError(t, err)
+	verifier, err := NewEd25519AuditChainVerifier(ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey))
+	require.NoError(t, err)
+	chain, err := NewAuditChain(AuditChainOptions{KV: store, Signer: signer, Verifier: verifier})
+	require.NoError(t, err)
+	return chain
+}
+
+var auditTestKey = &resourcepb.ResourceKey{
+	Namespace: "default",
+	Group:     "dashboard.grafana.app",
+	Resource:  "dashboards",
+	Name:      "d1",
+}
+
+func TestAuditChain(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestAuditChain(t, setupBadgerKV(t))
+	key := auditTestKey
+	history := historyReader{}
+	write := func(t *testing.T, typ resourcepb.WatchEvent_Type, rv int64, value string) {
+		t.Helper()
+		history[rv] = []byte(value)
+		require.NoError(t, chain.Append(ctx, &WriteEvent{Type: typ, Key: key, Value: []byte(value)}, rv))
+	}
+	verify := func(t *testing.T) *AuditChainReport {
+		t.Helper()
+		report, err := chain.Verify(ctx, key.Namespace, history)
+		require.NoError(t, err)
+		return report
+	}
+
+	write(t, resourcepb.WatchEvent_ADDED, 100, `{"v":1}`)
+	write(t, resourcepb.WatchEvent_MODIFIED, 200, `{"v":2}`)
+	write(t, resourcepb.WatchEvent_MODIFIED, 300, `{"v":3}`)
+
+	cp, err := chain.Checkpoint(ctx, key.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, int64(300), cp.ResourceVersion)
+	require.Equal(t, 3, cp.Links)
+
+	t.Run("untouched chain verifies", func(t *testing.T) {
+		report := verify(t)
+		require.True(t, report.OK(), report.Breaks)
+		require.Equal(t, 3, report.Links)
+		require.Equal(t, 1, report.Checkpoints)
+	})
+
+	t.Run("rewritten history value", func(t *testing.T) {
+		history[200] = []byte(`{"v":"tampered"}`)
+		defer func() { history[200] = []byte(`{"v":2}`) }()
+
+		report := verify(t)
+		require.Len(t, report.Breaks, 1)
+		require.Equal(t, int64(200), report.Breaks[0].ResourceVersion)
+		require.Contains(t, report.Breaks[0].Reason, "history value")
+	})
+
+	t.Run("removed history row", func(t *testing.T) {
+		saved := history[100]
+		delete(history, 100)
+		defer func() { history[100] = saved }()
+
+		report := verify(t)
+		require.Len(t, report.Breaks, 1)
+		require.Contains(t, report.Breaks[0].Reason, "missing")
+	})
+
+	t.Run("checkpoints are verified with the configured public key only", func(t *testing.T) {
+		verifier := chain.verifier
+		defer func() { chain.verifier = verifier }()
+
+		chain.verifier = nil
+		report := verify(t)
+		require.Len(t, report.Breaks, 1)
+		require.Equal(t, "checkpoint can not be verified without the public key", report.Breaks[0].Reason)
+
+		other, _, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		chain.verifier, err = NewEd25519AuditChainVerifier(other)
+		require.NoError(t, err)
+		report = verify(t)
+		require.Len(t, report.Breaks, 1)
+		require.Contains(t, report.Breaks[0].Reason, "unknown key")
+	})
+
+	t.Run("rewritten chain no longer matches the checkpoint", func(t *testing.T) {
+		// Rewrite the value and recompute every link that follows, as someone with
+		// database access could do.  Only the signed checkpoint can detect this.
+		history[200] = []byte(`{"v":"tampered"}`)
+		prefix := auditObjectPrefix(key)
+		var prev *AuditChainLink
+		for _, rv := range []int64{100, 200, 300} {
+			link := &AuditChainLink{}
+			require.NoError(t, chain.readJSON(ctx, auditChainSection, prefix+auditRV(rv), link))
+			if rv == 200 {
+				sum := sha256.Sum256(history[200])
+				link.ValueHash = hex.EncodeToString(sum[:])
+			}
+			if prev != nil {
+				link.PreviousHash = prev.Hash
+			}
+			link.Hash = link.computeHash()
+			require.NoError(t, chain.writeJSON(ctx, auditChainSection, prefix+auditRV(rv), link))
+			prev = link
+		}
+
+		report := verify(t)
+		require.Len(t, report.Breaks, 1)
+		require.Equal(t, "chain does not match the signed checkpoint", report.Breaks[0].Reason)
+	})
+}
+
+func TestAuditChainCheckpointsAreIncremental(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestAuditChain(t, setupBadgerKV(t))
+	history := historyReader{}
+	write := func(t *testing.T, name string, rv int64) {
+		t.Helper()
+		key := &resourcepb.ResourceKey{Namespace: "default", Group: "dashboard.grafana.app", Resource: "dashboards", Name: name}
+		value := []byte(fmt.Sprintf(`{"rv":%d}`, rv))
+		history[rv] = value
+		require.NoError(t, chain.Append(ctx, &WriteEvent{Type: resourcepb.WatchEvent_ADDED, Key: key, Value: value}, rv))
+	}
+
+	write(t, "a", 100)
+	write(t, "b", 200)
+	first, err := chain.Checkpoint(ctx, "default")
+	require.NoError(t, err)
+	require.Equal(t, int64(200), first.ResourceVersion)
+	require.Equal(t, 2, first.Links)
+
+	cp, err := chain.Checkpoint(ctx, "default")
+	require.NoError(t, err)
+	require.Nil(t, cp, "nothing to checkpoint without new links")
+
+	write(t, "a", 300)
+	second, err := chain.Checkpoint(ctx, "default")
+	require.NoError(t, err)
+	require.Equal(t, int64(300), second.ResourceVersion)
+	require.Equal(t, int64(3), second.Sequence)
+	require.Equal(t, int64(2), second.PreviousSequence)
+	require.Equal(t, 1, second.Links, "only the links since the previous checkpoint are signed")
+
+	report, err := chain.Verify(ctx, "default", history)
+	require.NoError(t, err)
+	require.True(t, report.OK(), report.Breaks)
+	require.Equal(t, 2, report.Checkpoints)
+
+	t.Run("removed checkpoint", func(t *testing.T) {
+		require.NoError(t, chain.kv.Delete(ctx, auditCheckpointSection, auditLinkPrefix("default")+auditRV(2)))
+		report, err := chain.Verify(ctx, "default", history)
+		require.NoError(t, err)
+		require.Len(t, report.Breaks, 1)
+		require.Contains(t, report.Breaks[0].Reason, "does not follow the previous checkpoint")
+	})
+}
+
+func TestAuditChainCheckpointsFollowTheAppendOrder(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestAuditChain(t, setupBadgerKV(t))
+	write := func(t *testing.T, name string, rv int64) {
+		t.Helper()
+		key := &resourcepb.ResourceKey{Namespace: "default", Group: "dashboard.grafana.app", Resource: "dashboards", Name: name}
+		require.NoError(t, chain.Append(ctx, &WriteEvent{Type: resourcepb.WatchEvent_ADDED, Key: key, Value: []byte(name)}, rv))
+	}
+
+	write(t, "a", 200)
+	_, err := chain.Checkpoint(ctx, "default")
+	require.NoError(t, err)
+
+	// A concurrent write committed at a lower resource version is appended after the checkpoint
+	write(t, "b", 100)
+	cp, err := chain.Checkpoint(ctx, "default")
+	require.NoError(t, err)
+	require.Equal(t, 1, cp.Links)
+
+	report, err := chain.Verify(ctx, "default", nil)
+	require.NoError(t, err)
+	require.True(t, report.OK(), report.Breaks)
+	require.Equal(t, 2, report.Checkpoints)
+}
+
+func TestAuditChainPrunedHistory(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestAuditChain(t, setupBadgerKV(t))
+	chain.historyLimit = func(group, resource string) int { return 2 }
+	history := historyReader{}
+	for rv := int64(100); rv <= 500; rv += 100 {
+		value := []byte(fmt.Sprintf(`{"rv":%d}`, rv))
+		history[rv] = value
+		require.NoError(t, chain.Append(ctx, &WriteEvent{Type: resourcepb.WatchEvent_MODIFIED, Key: auditTestKey, Value: value}, rv))
+	}
+	_, err := chain.Checkpoint(ctx, "default")
+	require.NoError(t, err)
+
+	// The pruner keeps the latest two versions
+	delete(history, 100)
+	delete(history, 200)
+	delete(history, 300)
+
+	t.Run("pruned history rows are not breaks", func(t *testing.T) {
+		report, err := chain.Verify(ctx, "default", history)
+		require.NoError(t, err)
+		require.True(t, report.OK(), report.Breaks)
+		require.Equal(t, 3, report.Pruned)
+	})
+
+	t.Run("rows newer than the boundary are still verified", func(t *testing.T) {
+		saved := history[400]
+		delete(history, 400)
+		defer func() { history[400] = saved }()
+
+		report, err := chain.Verify(ctx, "default", history)
+		require.NoError(t, err)
+		require.Len(t, report.Breaks, 1)
+		require.Equal(t, int64(400), report.Breaks[0].ResourceVersion)
+		require.Equal(t, "history row is missing", report.Breaks[0].Reason)
+	})
+
+	t.Run("a moved boundary does not verify", func(t *testing.T) {
+		key := auditHistoryPrefix("default") + "dashboard.grafana.app/dashboards/d1"
+		boundary := &AuditHistoryBoundary{}
+		require.NoError(t, chain.readJSON(ctx, auditChainSection, key, boundary))
+		require.Equal(t, int64(300), boundary.ResourceVersion)
+
+		saved := *boundary
+		boundary.ResourceVersion = 500
+		require.NoError(t, chain.writeJSON(ctx, auditChainSection, key, boundary))
+		defer func() { require.NoError(t, chain.writeJSON(ctx, auditChainSection, key, &saved)) }()
+
+		report, err := chain.Verify(ctx, "default", history)
+		require.NoError(t, err)
+		require.Equal(t, 0, report.Pruned)
+		require.NotEmpty(t, report.Breaks)
+		require.Equal(t, "history boundary signature is invalid", report.Breaks[len(report.Breaks)-1].Reason)
+	})
+}
+
+func TestAuditChainAppendClaimsThePreviousLink(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestAuditChain(t, setupBadgerKV(t))
+	event := func(value string) *WriteEvent {
+		return &WriteEvent{Type: resourcepb.WatchEvent_MODIFIED, Key: auditTestKey, Value: []byte(value)}
+	}
+	require.NoError(t, chain.Append(ctx, event(`{"v":1}`), 100))
+
+	t.Run("a link can not fork from a claimed link", func(t *testing.T) {
+		// Another writer linked to rv 100 after this one read it
+		prefix := auditObjectPrefix(auditTestKey)
+		require.NoError(t, chain.kv.Batch(ctx, auditChainSection, []BatchOp{
+			{Mode: kv.BatchOpCreate, Key: prefix + auditRV(100) + auditSuccessorSuffix, Value: []byte("other")},
+		}))
+		err := chain.Append(ctx, event(`{"v":2}`), 200)
+		require.ErrorIs(t, err, kv.ErrKeyAlreadyExists)
+		require.NoError(t, chain.kv.Delete(ctx, auditChainSection, prefix+auditRV(100)+auditSuccessorSuffix))
+	})
+
+	t.Run("links are appended in resource version order", func(t *testing.T) {
+		require.NoError(t, chain.Append(ctx, event(`{"v":2}`), 200))
+		require.Error(t, chain.Append(ctx, event(`{"v":3}`), 150))
+	})
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/google/uuid"
 	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
 
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
 	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
@@ -184,3 +185,15 @@ func (s *cdkBlobSupport) GetResourceBlob(ctx context.Context, resource *resource
 	})
 	return rsp, err
 }
+
+func (s *cdkBlobSupport) DeleteResourceBlob(ctx context.Context, resource *resourcepb.ResourceKey, info *utils.BlobInfo) error {
+	path, err := s.getBlobPath(resource, info)
+	if err != nil {
+		return err
+	}
+	err = s.bucket.Delete(ctx, path)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,558 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+const (
+	casManifestFolder = "cas/manifests/"
+	casChunkFolder    = "cas/chunks/"
+	casMarkFolder     = "cas/marks/"
+
+	// Content defined chunking boundaries.  Small edits to a large dashboard
+	// only change the chunks around the edit, so most chunks are shared
+	// between versions.
+	casMinChunkSize = 16 * 1024
+	casMaxChunkSize = 256 * 1024
+	casChunkMask    = (1 << 16) - 1 // ~64KB average chunk size
+
+	// Unreferenced chunks are first marked, and only removed by a collection that
+	// runs at least this long after the mark.  Writers touch the chunks they reuse
+	// when those are older than half of this period, and must write their manifest
+	// within half of this period, so a chunk that gets referenced again is always
+	// touched after it was marked.
+	defaultCASGracePeriod = time.Hour
+)
+
+// casManifest is stored for every blob and lists the chunks (in order) that make up the value
+type casManifest struct {
+	Size        int64    `json:"size"`
+	MD5         string   `json:"md5"`
+	ContentType string   `json:"contentType,omitempty"`
+	Chunks      []string `json:"chunks"`
+}
+
+// BlobGCStats reports the result of a chunk garbage collection
+type BlobGCStats struct {
+	Manifests      int
+	Chunks         int
+	Referenced     int
+	Marked         int
+	Deleted        int
+	BytesReclaimed int64
+}
+
+// NewCDKContentAddressedBlobSupport stores blob values as content addressed chunks.
+// Identical values, and unchanged chunks across versions of the same value, are only stored once.
+// Each blob is written as a small manifest that references its chunks, and chunks that are no
+// longer referenced by any manifest are removed by CollectGarbage.
+func NewCDKContentAddressedBlobSupport(ctx context.Context, opts CDKBlobSupportOptions) (*cdkCASBlobSupport, error) {
+	base, err := NewCDKBlobSupport(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &cdkCASBlobSupport{
+		cdkBlobSupport: base.(*cdkBlobSupport),
+		gracePeriod:    defaultCASGracePeriod,
+		now:            time.Now,
+	}, nil
+}
+
+type cdkCASBlobSupport struct {
+	*cdkBlobSupport
+
+	gracePeriod time.Duration
+	now         func() time.Time
+}
+
+func (s *cdkCASBlobSupport) manifestPath(key *resourcepb.ResourceKey, info *utils.BlobInfo) (string, error) {
+	path, err := s.getBlobPath(key, info)
+	if err != nil {
+		return "", err
+	}
+	return s.root + casManifestFolder + strings.TrimPrefix(path, s.root), nil
+}
+
+func (s *cdkCASBlobSupport) chunkPath(hash string) string {
+	return s.root + casChunkFolder + hash[:2] + "/" + hash
+}
+
+func (s *cdkCASBlobSupport) markPath(hash string) string {
+	return s.root + casMarkFolder + hash[:2] + "/" + hash
+}
+
+// casBlobUID derives the blob uid from the content, so every version of a resource
+// that saves the same value shares one manifest.
+func casBlobUID(value []byte, contentType string) string {
+	sum := sha256.Sum256(value)
+	return uuid.NewSHA1(uuid.NameSpaceOID, append(sum[:], contentType...)).String()
+}
+
+// Signed URLs are only used for reading, since uploads must be chunked by the server
+func (s *cdkCASBlobSupport) PutResourceBlob(ctx context.Context, req *resourcepb.PutBlobRequest) (*resourcepb.PutBlobResponse, error) {
+	if req.Method == resourcepb.PutBlobRequest_HTTP {
+		return s.cdkBlobSupport.PutResourceBlob(ctx, req)
+	}
+	if len(req.Value) < 1 {
+		return nil, fmt.Errorf("missing content value")
+	}
+
+	info := &utils.BlobInfo{
+		UID: casBlobUID(req.Value, req.ContentType),
+	}
+	info.SetContentType(req.ContentType)
+	path, err := s.manifestPath(req.Resource, info)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := md5.Sum(req.Value)
+	rsp := &resourcepb.PutBlobResponse{
+		Uid:      info.UID,
+		MimeType: info.MimeType,
+		Charset:  info.Charset,
+		Size:     int64(len(req.Value)),
+		Hash:     hex.EncodeToString(sum[:]),
+	}
+
+	// The same value was already saved for the resource, by this or an earlier version
+	exists, err := s.bucket.Exists(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return rsp, nil
+	}
+
+	started := s.now()
+	manifest := casManifest{
+		Size:        rsp.Size,
+		MD5:         rsp.Hash,
+		ContentType: req.ContentType,
+	}
+	touched := map[string][]byte{}
+	for _, chunk := range splitContentDefinedChunks(req.Value) {
+		hash, rewritten, err := s.writeChunk(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		if rewritten {
+			touched[hash] = chunk
+		}
+		manifest.Chunks = append(manifest.Chunks, hash)
+	}
+
+	// A chunk that was touched too long ago may have been marked again by the garbage collection
+	if elapsed := s.now().Sub(started); elapsed > s.gracePeriod/2 {
+		return nil, fmt.Errorf("writing blob chunks took %s, which is longer than half of the garbage collection grace period", elapsed)
+	}
+
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	err = s.bucket.WriteAll(ctx, path, body, &blob.WriterOptions{
+		ContentType: "application/json",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The garbage collection checks a chunk right before deleting it.  A chunk that was touched
+	// between that check and the delete is gone, so it is written again.
+	for hash, chunk := range touched {
+		exists, err := s.bucket.Exists(ctx, s.chunkPath(hash))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+		if err := s.bucket.WriteAll(ctx, s.chunkPath(hash), chunk, &blob.WriterOptions{
+			ContentType: "application/octet-stream",
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return rsp, nil
+}
+
+// writeChunk stores the chunk unless it already exists, and returns its hash.
+// Chunks older than half of the grace period are written again to touch them, which
+// keeps the garbage collection from removing a marked chunk that is referenced again.
+func (s *cdkCASBlobSupport) writeChunk(ctx context.Context, chunk []byte) (hash string, rewritten bool, err error) {
+	sum := sha256.Sum256(chunk)
+	hash = hex.EncodeToString(sum[:])
+	path := s.chunkPath(hash)
+
+	attrs, err := s.bucket.Attributes(ctx, path)
+	switch {
+	case err == nil:
+		if attrs.ModTime.After(s.now().Add(-s.gracePeriod / 2)) {
+			return hash, false, nil // recently stored or touched
+		}
+		rewritten = true
+	case gcerrors.Code(err) != gcerrors.NotFound:
+		return "", false, err
+	}
+	return hash, rewritten, s.bucket.WriteAll(ctx, path, chunk, &blob.WriterOptions{
+		ContentType: "application/octet-stream",
+	})
+}
+
+func (s *cdkCASBlobSupport) GetResourceBlob(ctx context.Context, resource *resourcepb.ResourceKey, info *utils.BlobInfo, mustProxy bool) (*resourcepb.GetBlobResponse, error) {
+	path, err := s.manifestPath(resource, info)
+	if err != nil {
+		return nil, err
+	}
+	body, err := s.bucket.ReadAll(ctx, path)
+	if err != nil {
+		if gcerrors.Code(err) == gcerrors.NotFound {
+			// Written before content addressing was enabled (or uploaded with a signed URL)
+			return s.cdkBlobSupport.GetResourceBlob(ctx, resource, info, mustProxy)
+		}
+		return nil, err
+	}
+
+	manifest := casManifest{}
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid blob manifest: %w", err)
+	}
+
+	// The value is assembled from chunks, so it is always proxied
+	value := bytes.NewBuffer(make([]byte, 0, manifest.Size))
+	for _, hash := range manifest.Chunks {
+		chunk, err := s.bucket.ReadAll(ctx, s.chunkPath(hash))
+		if err != nil {
+			return nil, fmt.Errorf("reading blob chunk %s: %w", hash, err)
+		}
+		value.Write(chunk)
+	}
+	return &resourcepb.GetBlobResponse{
+		ContentType: info.ContentType(),
+		Value:       value.Bytes(),
+	}, nil
+}
+
+// DeleteResourceBlob removes the blob manifest, which is shared by every version of the resource
+// that saved the same value.  The chunks are removed by the garbage collection once no other
+// manifest references them.
+func (s *cdkCASBlobSupport) DeleteResourceBlob(ctx context.Context, resource *resourcepb.ResourceKey, info *utils.BlobInfo) error {
+	path, err := s.manifestPath(resource, info)
+	if err != nil {
+		return err
+	}
+	err = s.bucket.Delete(ctx, path)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return nil
+	}
+	return err
+}
+
+// CollectGarbage counts the references to each chunk from all manifests, and removes chunks in two steps:
+// a chunk that is not referenced and older than the grace period is marked, and a later collection removes
+// it once the mark is older than the grace period, as long as the chunk is still not referenced and was
+// not touched since it was marked.
+func (s *cdkCASBlobSupport) CollectGarbage(ctx context.Context, dryRun bool) (*BlobGCStats, error) {
+	ctx, span := tracer.Start(ctx, "resource.cdkCASBlobSupport.CollectGarbage")
+	defer span.End()
+
+	// Taken before the manifests are listed: a manifest written after this
+	// may be missed, but its chunks were touched after any older mark
+	start := s.now()
+	cutoff := start.Add(-s.gracePeriod)
+
+	stats := &BlobGCStats{}
+	refs := map[string]int{}
+
+	err := s.listObjects(ctx, casManifestFolder, func(obj *blob.ListObject) error {
+		body, err := s.bucket.ReadAll(ctx, obj.Key)
+		if err != nil {
+			return err
+		}
+		manifest := casManifest{}
+		if err := json.Unmarshal(body, &manifest); err != nil {
+			return fmt.Errorf("invalid blob manifest %s: %w", obj.Key, err)
+		}
+		stats.Manifests++
+		for _, hash := range manifest.Chunks {
+			refs[hash]++
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	marks := map[string]time.Time{}
+	err = s.listObjects(ctx, casMarkFolder, func(obj *blob.ListObject) error {
+		marks[obj.Key[strings.LastIndex(obj.Key, "/")+1:]] = obj.ModTime
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	unmark := func(hash string) error {
+		if dryRun {
+			return nil
+		}
+		err := s.bucket.Delete(ctx, s.markPath(hash))
+		if err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+			return err
+		}
+		return nil
+	}
+
+	err = s.listObjects(ctx, casChunkFolder, func(obj *blob.ListObject) error {
+		stats.Chunks++
+
+		hash := obj.Key[strings.LastIndex(obj.Key, "/")+1:]
+		markedAt, marked := marks[hash]
+		delete(marks, hash)
+
+		switch {
+		case refs[hash] > 0:
+			stats.Referenced++
+			if marked {
+				return unmark(hash)
+			}
+			return nil
+
+		case !marked:
+			if obj.ModTime.After(cutoff) {
+				return nil
+			}
+			stats.Marked++
+			if dryRun {
+				return nil
+			}
+			return s.bucket.WriteAll(ctx, s.markPath(hash), []byte{}, nil)
+
+		case markedAt.After(cutoff):
+			return nil // marked recently
+		}
+
+		// Check the chunk again right before deleting it, since a writer may have touched it
+		attrs, err := s.bucket.Attributes(ctx, obj.Key)
+		if gcerrors.Code(err) == gcerrors.NotFound {
+			return unmark(hash)
+		}
+		if err != nil {
+			return err
+		}
+		if !attrs.ModTime.Before(markedAt) {
+			return unmark(hash) // touched since it was marked
+		}
+
+		if !dryRun {
+			if err := s.bucket.Delete(ctx, obj.Key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+				return err
+			}
+		}
+		stats.Deleted++
+		stats.BytesReclaimed += obj.Size
+		return unmark(hash)
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	// Marks left over from chunks that were removed
+	for hash := range marks {
+		if err := unmark(hash); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// listObjects calls fn for every object below the folder
+func (s *cdkCASBlobSupport) listObjects(ctx context.Context, folder string, fn func(obj *blob.ListObject) error) error {
+	iter := s.bucket.List(&blob.ListOptions{Prefix: s.root + folder})
+	for {
+		obj, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if obj.IsDir {
+			continue
+		}
+		if err := fn(obj); err != nil {
+			return err
+		}
+	}
+}
+
+// splitContentDefinedChunks splits the value using a gear rolling hash, so chunk
+// boundaries depend on the content instead of the offset.
+func splitContentDefinedChunks(value []byte) [][]byte {
+	var chunks [][]byte
+	for len(value) > 0 {
+		end := nextChunkBoundary(value)
+		chunks = append(chunks, value[:end])
+		value = value[end:]
+	}
+	return chunks
+}
+
+func nextChunkBoundary(value []byte) int {
+	if len(value) <= casMinChunkSize {
+		return len(value)
+	}
+	limit := min(len(value), casMaxChunkSize)
+	var h uint64
+	for i := casMinChunkSize; i < limit; i++ {
+		h = (h << 1) + gearTable[value[i]]
+		if h&casChunkMask == 0 {
+			return i + 1
+		}
+	}
+	return limit
+}
+
+// gearTable holds 256 pseudo random values generated with splitmix64.
+// The values must never change, otherwise existing chunks would no longer be shared.
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+	seed := uint64(0x9E3779B97F4A7C15)
+	for i := range table {
+		seed += 0x9E3779B97F4A7C15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// blobGarbageCollector is implemented by blob stores that share content between blobs
+type blobGarbageCollector interface {
+	CollectGarbage(ctx context.Context, dryRun bool) (*BlobGCStats, error)
+}
+
+// startBlobGarbageCollection periodically removes unreferenced blob chunks
+func (s *server) startBlobGarbageCollection() {
+	gc, ok := s.blob.(blobGarbageCollector)
+	if !ok || s.blobGCInterval <= 0 {
+		return
+	}
+
+	s.indexersWG.Go(func() {
+		ticker := time.NewTicker(s.blobGCInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				stats, err := gc.CollectGarbage(s.ctx, false)
+				if err != nil {
+					s.log.Error("blob garbage collection failed", "error", err)
+					continue
+				}
+				s.log.Debug("blob garbage collection finished",
+					"manifests", stats.Manifests,
+					"chunks", stats.Chunks,
+					"marked", stats.Marked,
+					"deleted", stats.Deleted,
+					"bytesReclaimed", stats.BytesReclaimed)
+			}
+		}
+	})
+}
+
+// watchRemovedHistory releases the blobs of the versions the storage backend removes for good.
+// Deleting a resource does not release its blobs, so it can still be restored from the trash;
+// they are released once the garbage collection removes its history.
+func (s *server) watchRemovedHistory() {
+	notifier, ok := s.backend.(HistoryRemovalNotifier)
+	if !ok || s.blob == nil {
+		return
+	}
+	notifier.OnHistoryRemoved(s.releaseRemovedBlobs)
+}
+
+// releaseRemovedBlobs deletes the blobs referenced by the removed versions of the resource
+// that no remaining version references
+func (s *server) releaseRemovedBlobs(ctx context.Context, key PruningKey, values [][]byte) {
+	removed := map[string]*utils.BlobInfo{}
+	for _, value := range values {
+		if info := historyBlob(value); info != nil {
+			removed[info.UID] = info
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+
+	resourceKey := &resourcepb.ResourceKey{
+		Namespace: key.Namespace,
+		Group:     key.Group,
+		Resource:  key.Resource,
+		Name:      key.Name,
+	}
+	logger := s.log.FromContext(ctx)
+	fields := []any{"namespace", key.Namespace, "group", key.Group, "resource", key.Resource, "name", key.Name}
+	// Every remaining version, including those before a deletion, which can be restored
+	_, err := s.backend.ListHistory(ctx, &resourcepb.ListRequest{
+		Source:          resourcepb.ListRequest_HISTORY,
+		ResourceVersion: 1,
+		VersionMatchV2:  resourcepb.ResourceVersionMatchV2_NotOlderThan,
+		Options:         &resourcepb.ListOptions{Key: resourceKey},
+	}, func(iter ListIterator) error {
+		for iter.Next() {
+			if err := iter.Error(); err != nil {
+				return err
+			}
+			if info := historyBlob(iter.Value()); info != nil {
+				delete(removed, info.UID)
+			}
+		}
+		return iter.Error()
+	})
+	if err != nil {
+		logger.Error("failed to list the remaining history before releasing blobs", append(fields, "error", err)...)
+		return
+	}
+
+	for _, info := range removed {
+		if err := s.blob.DeleteResourceBlob(ctx, resourceKey, info); err != nil {
+			logger.Error("failed to release blob", append(fields, "uid", info.UID, "error", err)...)
+		}
+	}
+}
+
+// historyBlob returns the blob referenced by a version of a resource, if any
+func historyBlob(value []byte) *utils.BlobInfo {
+	obj, err := parseTrashItem(value)
+	if err != nil {
+		return nil
+	}
+	info := obj.GetBlob()
+	if info == nil || info.UID == "" {
+		return nil
+	}
+	return info
+}
@@ -0,0 +1,243 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/memblob"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	authlib "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+func countObjects(t *testing.T, bucket *blob.Bucket, prefix string) int {
+	t.Helper()
+	count := 0
+	iter := bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(context.Background())
+		if err != nil {
+			break
+		}
+		if !obj.IsDir {
+			count++
+		}
+	}
+	return count
+}
+
+func TestCDKContentAddressedBlobStore(t *testing.T) {
+	ctx := context.Background()
+	bucket := memblob.OpenBucket(nil)
+
+	store, err := NewCDKContentAddressedBlobSupport(ctx, CDKBlobSupportOptions{
+		Bucket: bucket,
+	})
+	require.NoError(t, err)
+
+	key := &resourcepb.ResourceKey{
+		Group:     "dashboard.grafana.app",
+		Resource:  "dashboards",
+		Namespace: "default",
+		Name:      "big",
+	}
+
+	// A large value, and a copy with a small edit in the middle
+	v1 := make([]byte, 1024*1024)
+	rand.New(rand.NewSource(1)).Read(v1)
+	v2 := bytes.Clone(v1)
+	copy(v2[500*1024:], []byte("edited"))
+
+	put := func(value []byte) *resourcepb.PutBlobResponse {
+		rsp, err := store.PutResourceBlob(ctx, &resourcepb.PutBlobRequest{
+			Resource:    key,
+			Method:      resourcepb.PutBlobRequest_GRPC,
+			ContentType: "application/json",
+			Value:       value,
+		})
+		require.NoError(t, err)
+		return rsp
+	}
+	get := func(rsp *resourcepb.PutBlobResponse) []byte {
+		found, err := store.GetResourceBlob(ctx, key, &utils.BlobInfo{
+			UID:      rsp.Uid,
+			MimeType: rsp.MimeType,
+			Charset:  rsp.Charset,
+		}, false)
+		require.NoError(t, err)
+		return found.Value
+	}
+
+	r1 := put(v1)
+	chunksAfterFirst := countObjects(t, bucket, casChunkFolder)
+	require.Greater(t, chunksAfterFirst, 1)
+
+	t.Run("identical values are stored once", func(t *testing.T) {
+		r := put(v1)
+		require.Equal(t, r1.Uid, r.Uid, "versions that save the same value share the manifest")
+		require.Equal(t, r1.Hash, r.Hash)
+		require.Equal(t, 1, countObjects(t, bucket, casManifestFolder))
+		require.Equal(t, chunksAfterFirst, countObjects(t, bucket, casChunkFolder))
+		require.Equal(t, v1, get(r))
+	})
+
+	r2 := put(v2)
+	t.Run("unchanged chunks are shared between versions", func(t *testing.T) {
+		added := countObjects(t, bucket, casChunkFolder) - chunksAfterFirst
+		require.Greater(t, added, 0)
+		require.LessOrEqual(t, added, 2)
+		require.Equal(t, v2, get(r2))
+		require.Equal(t, v1, get(r1))
+	})
+
+	// Every collection below runs more than a grace period after the previous one
+	collect := func(dryRun bool) *BlobGCStats {
+		t.Helper()
+		now := store.now().Add(2 * defaultCASGracePeriod)
+		store.now = func() time.Time { return now }
+		stats, err := store.CollectGarbage(ctx, dryRun)
+		require.NoError(t, err)
+		return stats
+	}
+
+	t.Run("garbage collection does not remove chunks that are referenced again", func(t *testing.T) {
+		stats := collect(false)
+		require.Equal(t, 0, stats.Marked)
+		require.Equal(t, 0, stats.Deleted)
+
+		require.NoError(t, store.DeleteResourceBlob(ctx, key, &utils.BlobInfo{UID: r2.Uid, MimeType: r2.MimeType}))
+		stats = collect(false)
+		require.Greater(t, stats.Marked, 0)
+		require.Equal(t, 0, stats.Deleted, "chunks are only marked by the first collection")
+
+		// The marked chunks are touched when they are used again
+		r := put(v2)
+		require.Equal(t, r2.Uid, r.Uid)
+		stats = collect(false)
+		require.Equal(t, 0, stats.Deleted)
+		require.Equal(t, 0, countObjects(t, bucket, casMarkFolder))
+		require.Equal(t, v2, get(r2))
+	})
+
+	t.Run("garbage collection removes chunks that stay unreferenced", func(t *testing.T) {
+		require.NoError(t, store.DeleteResourceBlob(ctx, key, &utils.BlobInfo{UID: r2.Uid, MimeType: r2.MimeType}))
+		stats := collect(false)
+		require.Greater(t, stats.Marked, 0)
+		require.Equal(t, 0, stats.Deleted)
+
+		stats = collect(true)
+		require.Greater(t, stats.Deleted, 0)
+		before := countObjects(t, bucket, casChunkFolder)
+
+		stats = collect(false)
+		require.Equal(t, before-stats.Deleted, countObjects(t, bucket, casChunkFolder))
+		require.Equal(t, 0, countObjects(t, bucket, casMarkFolder))
+		require.Equal(t, v1, get(r1))
+	})
+}
+
+func TestServerReleasesBlobsOfRemovedHistory(t *testing.T) {
+	ctx := context.Background()
+	backend := setupTestStorageBackend(t, func(opts *KVBackendOptions) {
+		opts.DashboardVersionsToKeep = 1
+	})
+	bucket := memblob.OpenBucket(nil)
+	store, err := NewCDKContentAddressedBlobSupport(ctx, CDKBlobSupportOptions{
+		Bucket: bucket,
+	})
+	require.NoError(t, err)
+
+	srv, err := NewResourceServer(ResourceServerOptions{
+		Backend: backend,
+		AccessClient: &callbackAccessClient{fn: func(authlib.CheckRequest, string) (authlib.CheckResponse, error) {
+			return allow()
+		}},
+		Blob: BlobConfig{Backend: store},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Stop(stopCtx)
+	})
+
+	key := &resourcepb.ResourceKey{
+		Namespace: "default",
+		Group:     "dashboard.grafana.app",
+		Resource:  "dashboards",
+		Name:      "d1",
+	}
+	put := func(value string) *utils.BlobInfo {
+		rsp, err := store.PutResourceBlob(ctx, &resourcepb.PutBlobRequest{
+			Resource:    key,
+			Method:      resourcepb.PutBlobRequest_GRPC,
+			ContentType: "application/json",
+			Value:       []byte(value),
+		})
+		require.NoError(t, err)
+		return &utils.BlobInfo{UID: rsp.Uid, MimeType: rsp.MimeType, Charset: rsp.Charset}
+	}
+	write := func(typ resourcepb.WatchEvent_Type, info *utils.BlobInfo, previousRV int64) int64 {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "dashboard.grafana.app/v1",
+			"kind":       "Dashboard",
+			"metadata":   map[string]any{"name": key.Name, "namespace": key.Namespace},
+		}}
+		meta, err := utils.MetaAccessor(obj)
+		require.NoError(t, err)
+		meta.SetBlob(info)
+		event := WriteEvent{Type: typ, Key: key, Value: objectToJSONBytes(t, obj), Object: meta, PreviousRV: previousRV}
+		if typ != resourcepb.WatchEvent_ADDED {
+			event.ObjectOld = meta
+		}
+		rv, err := backend.WriteEvent(ctx, event)
+		require.NoError(t, err)
+		return rv
+	}
+	exists := func(info *utils.BlobInfo) bool {
+		_, err := store.GetResourceBlob(ctx, key, info, true)
+		return err == nil
+	}
+	prune := func() {
+		require.NoError(t, backend.pruneEvents(ctx, PruningKey{
+			Namespace: key.Namespace,
+			Group:     key.Group,
+			Resource:  key.Resource,
+			Name:      key.Name,
+		}))
+	}
+
+	b1 := put(`{"v":1}`)
+	rv := write(resourcepb.WatchEvent_ADDED, b1, 0)
+	b2 := put(`{"v":2}`)
+	rv = write(resourcepb.WatchEvent_MODIFIED, b2, rv)
+
+	t.Run("blobs of pruned versions are released", func(t *testing.T) {
+		prune()
+		require.False(t, exists(b1))
+		require.True(t, exists(b2))
+
+		// The chunks are marked, then removed by the next collection
+		for range 2 {
+			now := store.now().Add(2 * defaultCASGracePeriod)
+			store.now = func() time.Time { return now }
+			_, err := store.CollectGarbage(ctx, false)
+			require.NoError(t, err)
+		}
+		require.Equal(t, 1, countObjects(t, bucket, casChunkFolder))
+	})
+
+	t.Run("blobs still referenced by a kept version are not released", func(t *testing.T) {
+		write(resourcepb.WatchEvent_MODIFIED, b2, rv)
+		prune()
+		require.True(t, exists(b2))
+	})
+}
@@ -3,7 +3,6 @@ package resource
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"strings"
 	"sync"
 
@@ -486,20 +485,8 @@ func getSelectableFieldsFromObject(tmp *unstructured.Unstructured, fields []stri
 	result := map[string]string{}
 
 	for _, field := range fields {
-		path := strings.Split(field, ".")
-		val, ok, err := unstructured.NestedFieldNoCopy(tmp.Object, path...)
-		if err != nil || !ok {
-			continue
-		}
-
-		switch v := val.(type) {
-		case string:
-			result[field] = v
-		case bool:
-			result[field] = strconv.FormatBool(v)
-		default:
-			// In practice there should only be strings, bools and int/float selectable fields.
-			result[field] = fmt.Sprintf("%v", v)
+		if val, ok := selectableFieldValue(tmp.Object, field); ok {
+			result[field] = val
 		}
 	}
 
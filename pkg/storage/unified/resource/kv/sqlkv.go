@@ -31,6 +31,9 @@ const (
 	SearchSnapshotDataSection     = "search/snapshot-data"
 	StatsDailySection             = "stats/daily"
 	StatsAggregatesSection        = "stats/aggregates"
+	AuditChainSection             = "unified/auditchain"
+	AuditCheckpointSection        = "unified/auditcheckpoint"
+	SchemaRewriteSection          = "unified/schemarewrite"
 )
 
 // validSaveSections is the set of sections accepted by SqlKV.Save.
@@ -44,6 +47,9 @@ var validSaveSections = map[string]bool{
 	SearchSnapshotDataSection:     true,
 	StatsDailySection:             true,
 	StatsAggregatesSection:        true,
+	AuditChainSection:             true,
+	AuditCheckpointSection:        true,
+	SchemaRewriteSection:          true,
 }
 
 var _ KV = &SqlKV{}
@@ -129,6 +135,12 @@ func (k *SqlKV) getQueryBuilder(section string) (*queryBuilder, error) {
 		tableName = "resource_stats_daily"
 	case StatsAggregatesSection:
 		tableName = "resource_stats_aggregates"
+	case AuditChainSection:
+		tableName = "resource_audit_chain"
+	case AuditCheckpointSection:
+		tableName = "resource_audit_checkpoint"
+	case SchemaRewriteSection:
+		tableName = "resource_schema_rewrite"
 	default:
 		return nil, fmt.Errorf("invalid section: %s", section)
 	}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"net/http"
 	"slices"
+	"strings"
+	"sync"
 
 	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func (s *server) listWithFieldSelectors(ctx context.Context, req *resourcepb.ListRequest) (*resourcepb.ListResponse, error) {
@@ -21,13 +24,23 @@ func (s *server) listWithFieldSelectors(ctx context.Context, req *resourcepb.Lis
 		}, nil
 	}
 
+	// Selectable fields are indexed under a prefix. The requirements belong to the caller, so they are copied
+	fields := make([]*resourcepb.Requirement, 0, len(req.Options.Fields))
 	for _, v := range req.Options.Fields {
-		v.Key = SEARCH_SELECTABLE_FIELDS_PREFIX + v.Key
+		fields = append(fields, &resourcepb.Requirement{
+			Key:      SEARCH_SELECTABLE_FIELDS_PREFIX + v.Key,
+			Operator: v.Operator,
+			Values:   v.Values,
+		})
 	}
 
 	srq := &resourcepb.ResourceSearchRequest{
-		Options: req.Options,
-		Limit:   req.Limit,
+		Options: &resourcepb.ListOptions{
+			Key:    req.Options.Key,
+			Labels: req.Options.Labels,
+			Fields: fields,
+		},
+		Limit: req.Limit,
 	}
 
 	var listRv int64
@@ -107,17 +120,134 @@ func (s *server) listWithFieldSelectors(ctx context.Context, req *resourcepb.Lis
 	return rsp, nil
 }
 
+// Field selector operators supported by List
+const (
+	FieldSelectorOperatorEquals    = "="
+	FieldSelectorOperatorNotEquals = "!="
+	FieldSelectorOperatorIn        = "in"
+	FieldSelectorOperatorNotIn     = "notin"
+	FieldSelectorOperatorPrefix    = "prefix"
+)
+
+// filterFieldSelectors drops requirements that can not be evaluated on stored objects,
+// and normalizes "==" to "=".
 func filterFieldSelectors(req *resourcepb.ListRequest) *resourcepb.ListRequest {
-	fields := make([]*resourcepb.Requirement, 0, len(req.Options.Fields))
-	for _, f := range req.Options.Fields {
-		if (f.Operator != "=" && f.Operator != "==") || f.Key == "metadata.namespace" {
+	req.Options.Fields = supportedFieldSelectors(req.Options.Fields)
+	return req
+}
+
+func supportedFieldSelectors(requirements []*resourcepb.Requirement) []*resourcepb.Requirement {
+	fields := make([]*resourcepb.Requirement, 0, len(requirements))
+	for _, f := range requirements {
+		if f.Key == "metadata.namespace" {
+			continue
+		}
+		switch f.Operator {
+		case "==":
+			// The requirement belongs to the caller, so it is copied rather than changed
+			f = &resourcepb.Requirement{
+				Key:      f.Key,
+				Operator: FieldSelectorOperatorEquals,
+				Values:   f.Values,
+			}
+		case FieldSelectorOperatorEquals, FieldSelectorOperatorNotEquals, FieldSelectorOperatorIn, FieldSelectorOperatorNotIn, FieldSelectorOperatorPrefix:
+		default:
 			continue
 		}
 		fields = append(fields, f)
 	}
-	req.Options.Fields = fields
+	return fields
+}
 
-	return req
+var manifestSelectableFields = sync.OnceValue(SelectableFields)
+
+// storageFieldSelectors returns the requirements that can be evaluated on stored objects:
+// metadata.name and the selectable fields declared by the app manifest.
+// Any other requirement is left for the caller to evaluate.
+func storageFieldSelectors(key *resourcepb.ResourceKey, fields []*resourcepb.Requirement) []*resourcepb.Requirement {
+	if len(fields) == 0 {
+		return nil
+	}
+	declared := manifestSelectableFields()[strings.ToLower(key.Group+"/"+key.Resource)]
+	out := make([]*resourcepb.Requirement, 0, len(fields))
+	for _, f := range fields {
+		if f.Key == "metadata.name" || slices.Contains(declared, f.Key) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// matchesFieldSelectors evaluates the requirements against a stored object.
+// This is used when the list can not be answered by the search index.
+func matchesFieldSelectors(value []byte, fields []*resourcepb.Requirement) (bool, error) {
+	if len(fields) == 0 {
+		return true, nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(value); err != nil {
+		return false, err
+	}
+
+	for _, f := range fields {
+		v, found := "", false
+		if f.Key == "metadata.name" {
+			v, found = obj.GetName(), true
+		} else {
+			v, found = selectableFieldValue(obj.Object, f.Key)
+		}
+
+		switch f.Operator {
+		case FieldSelectorOperatorEquals:
+			// multiple values must all match
+			for _, want := range f.Values {
+				if !found || v != want {
+					return false, nil
+				}
+			}
+		case FieldSelectorOperatorIn:
+			if !found || !slices.Contains(f.Values, v) {
+				return false, nil
+			}
+		case FieldSelectorOperatorNotEquals, FieldSelectorOperatorNotIn:
+			if found && slices.Contains(f.Values, v) {
+				return false, nil
+			}
+		case FieldSelectorOperatorPrefix:
+			if !found || !slices.ContainsFunc(f.Values, func(p string) bool { return strings.HasPrefix(v, p) }) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// watchEventMatchesFieldSelectors reports whether a watch event is sent to a watch with field selectors.
+// A modified object is also sent when only its previous version matched, so the client sees it leave the selection.
+// Deleted objects are matched on their previous version, when it is known.
+func watchEventMatchesFieldSelectors(event *WrittenEvent, previous *resourcepb.WatchEvent_Resource, fields []*resourcepb.Requirement) (bool, error) {
+	if len(fields) == 0 {
+		return true, nil
+	}
+
+	switch event.Type {
+	case resourcepb.WatchEvent_ADDED:
+		return matchesFieldSelectors(event.Value, fields)
+	case resourcepb.WatchEvent_MODIFIED:
+		match, err := matchesFieldSelectors(event.Value, fields)
+		if err != nil || match || previous == nil {
+			return match, err
+		}
+		return matchesFieldSelectors(previous.Value, fields)
+	case resourcepb.WatchEvent_DELETED:
+		if previous == nil {
+			return true, nil
+		}
+		return matchesFieldSelectors(previous.Value, fields)
+	default:
+		return true, nil
+	}
 }
 
 func (s *server) useFieldSelectorSearch(req *resourcepb.ListRequest) bool {
@@ -126,13 +126,27 @@ func TestFilterFieldSelectors(t *testing.T) {
 			},
 			wantFieldKeys: []string{"spec.foo"},
 		},
-		"removes multiple unsupported fields": {
+		"keeps supported operators": {
 			req: &resourcepb.ListRequest{
 				Options: &resourcepb.ListOptions{
 					Key: &resourcepb.ResourceKey{Namespace: "nsx"},
 					Fields: []*resourcepb.Requirement{
 						{Key: "metadata.namespace", Operator: "=", Values: []string{"ns", "other"}},
 						{Key: "spec.foo", Operator: "!="},
+						{Key: "spec.bar", Operator: "in"},
+						{Key: "spec.baz", Operator: "prefix"},
+					},
+				},
+			},
+			wantFieldKeys: []string{"spec.foo", "spec.bar", "spec.baz"},
+		},
+		"removes unsupported operators": {
+			req: &resourcepb.ListRequest{
+				Options: &resourcepb.ListOptions{
+					Key: &resourcepb.ResourceKey{Namespace: "nsx"},
+					Fields: []*resourcepb.Requirement{
+						{Key: "spec.foo", Operator: "exists"},
+						{Key: "spec.bar", Operator: "gt"},
 					},
 				},
 			},
@@ -151,6 +165,87 @@ func TestFilterFieldSelectors(t *testing.T) {
 			require.Equal(t, tc.wantFieldKeys, gotKeys)
 		})
 	}
+
+	t.Run("does not change the requirements of the caller", func(t *testing.T) {
+		requirement := &resourcepb.Requirement{Key: "spec.foo", Operator: "==", Values: []string{"a"}}
+		out := filterFieldSelectors(&resourcepb.ListRequest{
+			Options: &resourcepb.ListOptions{Fields: []*resourcepb.Requirement{requirement}},
+		})
+
+		require.Equal(t, "==", requirement.Operator)
+		require.Equal(t, FieldSelectorOperatorEquals, out.Options.Fields[0].Operator)
+	})
+}
+
+func TestWatchEventMatchesFieldSelectors(t *testing.T) {
+	prod := []byte(`{"metadata": {"name": "a"}, "spec": {"env": "prod"}}`)
+	dev := []byte(`{"metadata": {"name": "a"}, "spec": {"env": "dev"}}`)
+	fields := []*resourcepb.Requirement{{Key: "spec.env", Operator: "=", Values: []string{"prod"}}}
+
+	tests := map[string]struct {
+		event    *WrittenEvent
+		previous *resourcepb.WatchEvent_Resource
+		match    bool
+	}{
+		"added and matching":             {event: &WrittenEvent{Type: resourcepb.WatchEvent_ADDED, Value: prod}, match: true},
+		"added and not matching":         {event: &WrittenEvent{Type: resourcepb.WatchEvent_ADDED, Value: dev}, match: false},
+		"modified into the selection":    {event: &WrittenEvent{Type: resourcepb.WatchEvent_MODIFIED, Value: prod}, previous: &resourcepb.WatchEvent_Resource{Value: dev}, match: true},
+		"modified out of the selection":  {event: &WrittenEvent{Type: resourcepb.WatchEvent_MODIFIED, Value: dev}, previous: &resourcepb.WatchEvent_Resource{Value: prod}, match: true},
+		"modified outside the selection": {event: &WrittenEvent{Type: resourcepb.WatchEvent_MODIFIED, Value: dev}, previous: &resourcepb.WatchEvent_Resource{Value: dev}, match: false},
+		"deleted and matching":           {event: &WrittenEvent{Type: resourcepb.WatchEvent_DELETED}, previous: &resourcepb.WatchEvent_Resource{Value: prod}, match: true},
+		"deleted and not matching":       {event: &WrittenEvent{Type: resourcepb.WatchEvent_DELETED}, previous: &resourcepb.WatchEvent_Resource{Value: dev}, match: false},
+		"deleted without previous":       {event: &WrittenEvent{Type: resourcepb.WatchEvent_DELETED}, match: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			match, err := watchEventMatchesFieldSelectors(tc.event, tc.previous, fields)
+			require.NoError(t, err)
+			require.Equal(t, tc.match, match)
+		})
+	}
+
+	t.Run("fails on values that can not be evaluated", func(t *testing.T) {
+		_, err := watchEventMatchesFieldSelectors(&WrittenEvent{Type: resourcepb.WatchEvent_ADDED, Value: []byte("not json")}, nil, fields)
+		require.Error(t, err)
+	})
+}
+
+func TestMatchesFieldSelectors(t *testing.T) {
+	value := []byte(`{
+		"apiVersion": "example.grafana.app/v1",
+		"kind": "Example",
+		"metadata": {"name": "a"},
+		"spec": {"env": "prod-eu", "replicas": 3, "targets": [{"uid": "ds1"}]}
+	}`)
+
+	tests := map[string]struct {
+		fields []*resourcepb.Requirement
+		match  bool
+	}{
+		"equals":             {fields: []*resourcepb.Requirement{{Key: "spec.env", Operator: "=", Values: []string{"prod-eu"}}}, match: true},
+		"equals number":      {fields: []*resourcepb.Requirement{{Key: "spec.replicas", Operator: "=", Values: []string{"3"}}}, match: true},
+		"equals missing":     {fields: []*resourcepb.Requirement{{Key: "spec.missing", Operator: "=", Values: []string{"x"}}}, match: false},
+		"not equals":         {fields: []*resourcepb.Requirement{{Key: "spec.env", Operator: "!=", Values: []string{"prod-eu"}}}, match: false},
+		"not equals missing": {fields: []*resourcepb.Requirement{{Key: "spec.missing", Operator: "!=", Values: []string{"x"}}}, match: true},
+		"in":                 {fields: []*resourcepb.Requirement{{Key: "spec.env", Operator: "in", Values: []string{"dev", "prod-eu"}}}, match: true},
+		"not in":             {fields: []*resourcepb.Requirement{{Key: "spec.env", Operator: "notin", Values: []string{"dev", "prod-eu"}}}, match: false},
+		"prefix":             {fields: []*resourcepb.Requirement{{Key: "spec.env", Operator: "prefix", Values: []string{"prod-"}}}, match: true},
+		"array path":         {fields: []*resourcepb.Requirement{{Key: "spec.targets[0].uid", Operator: "=", Values: []string{"ds1"}}}, match: true},
+		"name":               {fields: []*resourcepb.Requirement{{Key: "metadata.name", Operator: "in", Values: []string{"a", "b"}}}, match: true},
+		"all must match": {fields: []*resourcepb.Requirement{
+			{Key: "spec.env", Operator: "prefix", Values: []string{"prod"}},
+			{Key: "spec.replicas", Operator: "!=", Values: []string{"3"}},
+		}, match: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			match, err := matchesFieldSelectors(value, tc.fields)
+			require.NoError(t, err)
+			require.Equal(t, tc.match, match)
+		})
+	}
 }
 
 func TestListWithFieldSelectors(t *testing.T) {
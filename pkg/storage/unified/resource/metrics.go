@@ -12,6 +12,7 @@ type StorageMetrics struct {
 	WatchEventLatency      *prometheus.HistogramVec
 	PollerLatency          prometheus.Histogram
 	ListWithFieldSelectors *prometheus.CounterVec
+	SchemaMigrations       *prometheus.CounterVec
 	RequestDuration        *prometheus.HistogramVec
 	DegradedOperations     *prometheus.CounterVec
 	Broadcaster            *BroadcasterMetrics
@@ -39,6 +40,10 @@ func ProvideStorageMetrics(reg prometheus.Registerer) *StorageMetrics {
 			Name: "storage_server_field_selector_search_count",
 			Help: "number of times List was served by field selector search",
 		}, []string{"resource", "served_by"}),
+		SchemaMigrations: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "storage_server_schema_migrations_total",
+			Help: "number of stored objects converted to a newer schema version",
+		}, []string{"resource", "mode"}),
 		RequestDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
 			Name:                            "storage_server_grpc_request_duration_seconds",
 			Help:                            "Time (in seconds) spent serving unified storage gRPC requests, labeled by group and resource.",
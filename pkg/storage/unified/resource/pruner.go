@@ -31,3 +31,14 @@ func (p *NoopPruner) Add(key PruningKey) error {
 func (p *NoopPruner) Start(ctx context.Context) {}
 
 func (p *NoopPruner) Stop() {}
+
+// HistoryRemovedFunc is called with the values of the versions of a resource that a storage
+// backend removed for good: versions over the history limit, or every version of a deleted
+// resource once it is garbage collected.
+type HistoryRemovedFunc func(ctx context.Context, key PruningKey, values [][]byte)
+
+// HistoryRemovalNotifier is implemented by storage backends that remove the history of
+// resources, so the server can release what only the removed versions referenced.
+type HistoryRemovalNotifier interface {
+	OnHistoryRemoved(fn HistoryRemovedFunc)
+}
@@ -0,0 +1,543 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/storage/unified/resource/kv"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// SchemaConvertFunc rewrites an object stored in one version into the next version.
+// The apiVersion is updated by the caller once the function returns.
+type SchemaConvertFunc func(ctx context.Context, obj *unstructured.Unstructured) error
+
+// SchemaConversion converts a stored resource between two versions of the same group
+type SchemaConversion struct {
+	Group    string
+	Resource string
+	From     string // the version stored in the object
+	To       string // the version written by the conversion
+	Convert  SchemaConvertFunc
+}
+
+type schemaConversionKey struct {
+	group    string
+	resource string
+	from     string
+}
+
+// SchemaMigrations holds the conversions registered by apps.
+// Conversions are chained, so an object stored in v0 can be upgraded
+// to v2 with a v0->v1 and a v1->v2 conversion.
+type SchemaMigrations struct {
+	mu          sync.RWMutex
+	conversions map[schemaConversionKey]SchemaConversion
+}
+
+var defaultSchemaMigrations = NewSchemaMigrations()
+
+// DefaultSchemaMigrations returns the conversions used by the storage server running in the
+// same process. App installers provide their conversions with appinstaller.SchemaConversionsProvider,
+// which registers them here before the objects of the app are served.
+func DefaultSchemaMigrations() *SchemaMigrations {
+	return defaultSchemaMigrations
+}
+
+func NewSchemaMigrations() *SchemaMigrations {
+	return &SchemaMigrations{
+		conversions: make(map[schemaConversionKey]SchemaConversion),
+	}
+}
+
+// Register adds conversions for a kind. Only one conversion may start from any given version,
+// and the conversions of a kind may not form a cycle. Either all the conversions are registered or none.
+func (m *SchemaMigrations) Register(conversions ...SchemaConversion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	added := make(map[schemaConversionKey]SchemaConversion, len(conversions))
+	for _, c := range conversions {
+		if c.Group == "" || c.Resource == "" || c.From == "" || c.To == "" {
+			return fmt.Errorf("schema conversion requires group, resource, from and to")
+		}
+		if c.From == c.To {
+			return fmt.Errorf("schema conversion %s/%s must change the version (%s)", c.Group, c.Resource, c.From)
+		}
+		if c.Convert == nil {
+			return fmt.Errorf("schema conversion %s/%s %s->%s is missing the convert function", c.Group, c.Resource, c.From, c.To)
+		}
+		k := schemaConversionKey{group: c.Group, resource: c.Resource, from: c.From}
+		existing, ok := m.conversions[k]
+		if !ok {
+			existing, ok = added[k]
+		}
+		if ok {
+			return fmt.Errorf("schema conversion %s/%s from %s already registered (to %s)", c.Group, c.Resource, c.From, existing.To)
+		}
+		added[k] = c
+	}
+
+	// Every chain starting from a new conversion must end at a version without conversions
+	for k := range added {
+		visited := map[string]bool{}
+		for version := k.from; ; {
+			if visited[version] {
+				return fmt.Errorf("schema conversions for %s/%s form a cycle at version %s", k.group, k.resource, version)
+			}
+			visited[version] = true
+
+			next := schemaConversionKey{group: k.group, resource: k.resource, from: version}
+			c, ok := added[next]
+			if !ok {
+				c, ok = m.conversions[next]
+			}
+			if !ok {
+				break
+			}
+			version = c.To
+		}
+	}
+
+	maps.Copy(m.conversions, added)
+	return nil
+}
+
+// Handles reports whether any conversion is registered for the group resource
+func (m *SchemaMigrations) Handles(group, resource string) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for k := range m.conversions {
+		if k.group == group && k.resource == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// groupResources returns the group resources with registered conversions, sorted
+func (m *SchemaMigrations) groupResources() []schema.GroupResource {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := map[schema.GroupResource]bool{}
+	out := []schema.GroupResource{}
+	for k := range m.conversions {
+		gr := schema.GroupResource{Group: k.group, Resource: k.resource}
+		if !seen[gr] {
+			seen[gr] = true
+			out = append(out, gr)
+		}
+	}
+	slices.SortFunc(out, func(a, b schema.GroupResource) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return out
+}
+
+// revision identifies the conversions registered for a group resource.
+// It changes whenever a conversion is added, so a finished rewrite runs again.
+func (m *SchemaMigrations) revision(group, resource string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	edges := []string{}
+	for k, c := range m.conversions {
+		if k.group == group && k.resource == resource {
+			edges = append(edges, c.From+">"+c.To)
+		}
+	}
+	slices.Sort(edges)
+	sum := sha256.Sum256([]byte(strings.Join(edges, ",")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Migrate upgrades the stored value to the latest registered version.
+// When no conversion applies, the original value is returned and changed is false.
+func (m *SchemaMigrations) Migrate(ctx context.Context, key *resourcepb.ResourceKey, value []byte) (out []byte, changed bool, err error) {
+	if m == nil || len(value) == 0 || !m.Handles(key.Group, key.Resource) {
+		return value, false, nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(value); err != nil {
+		return value, false, fmt.Errorf("unable to read stored object: %w", err)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Register rejects cycles, so the chain always ends
+	for {
+		gv, err := schema.ParseGroupVersion(obj.GetAPIVersion())
+		if err != nil {
+			return value, false, err
+		}
+		c, ok := m.conversions[schemaConversionKey{group: key.Group, resource: key.Resource, from: gv.Version}]
+		if !ok {
+			break
+		}
+
+		if err := c.Convert(ctx, obj); err != nil {
+			return value, false, fmt.Errorf("converting %s/%s %s from %s to %s: %w", key.Group, key.Resource, key.Name, c.From, c.To, err)
+		}
+		obj.SetAPIVersion(schema.GroupVersion{Group: gv.Group, Version: c.To}.String())
+		changed = true
+	}
+
+	if !changed {
+		return value, false, nil
+	}
+	out, err = obj.MarshalJSON()
+	if err != nil {
+		return value, false, err
+	}
+	return out, true, nil
+}
+
+// migrateOnRead converts values to the latest schema version while reading.
+// Conversion failures are logged and the stored value is returned unchanged.
+func (s *server) migrateOnRead(ctx context.Context, key *resourcepb.ResourceKey, value []byte) []byte {
+	if s.schemaMigrations == nil {
+		return value
+	}
+	out, changed, err := s.schemaMigrations.Migrate(ctx, key, value)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("failed to migrate stored object on read",
+			"group", key.Group,
+			"resource", key.Resource,
+			"namespace", key.Namespace,
+			"name", key.Name,
+			"error", err)
+		return value
+	}
+	if changed && s.storageMetrics != nil && s.storageMetrics.SchemaMigrations != nil {
+		s.storageMetrics.SchemaMigrations.WithLabelValues(key.Group+"/"+key.Resource, "read").Inc()
+	}
+	return out
+}
+
+// SchemaRewriteOptions configures a background rewrite of stored objects
+type SchemaRewriteOptions struct {
+	Group      string
+	Resource   string
+	Namespaces []string
+
+	// Items to process per page (defaults to 100)
+	BatchSize int64
+
+	// Continue tokens by namespace, as returned in a previous report.
+	// Namespaces marked as done in the previous report are skipped.
+	Resume map[string]*SchemaRewriteNamespaceReport
+
+	// Called after every processed page
+	Progress func(report *SchemaRewriteReport)
+}
+
+// SchemaRewriteFailure describes an object that could not be rewritten
+type SchemaRewriteFailure struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// SchemaRewriteNamespaceReport holds the rewrite progress for a single namespace
+type SchemaRewriteNamespaceReport struct {
+	Namespace     string                 `json:"namespace"`
+	Scanned       int64                  `json:"scanned"`
+	Rewritten     int64                  `json:"rewritten"`
+	Skipped       int64                  `json:"skipped"` // changed while the job was running
+	Failures      []SchemaRewriteFailure `json:"failures,omitempty"`
+	ContinueToken string                 `json:"continueToken,omitempty"`
+	Done          bool                   `json:"done"`
+}
+
+// SchemaRewriteReport summarizes a rewrite job
+type SchemaRewriteReport struct {
+	Group      string                                   `json:"group"`
+	Resource   string                                   `json:"resource"`
+	Namespaces map[string]*SchemaRewriteNamespaceReport `json:"namespaces"`
+}
+
+// RewriteSchemas upgrades every stored object in the selected namespaces to the latest
+// registered version. Writes go through Update, so access checks, history and watch events
+// behave exactly like a user update. The context must include an identity allowed to update.
+func (s *server) RewriteSchemas(ctx context.Context, opts SchemaRewriteOptions) (*SchemaRewriteReport, error) {
+	if !s.schemaMigrations.Handles(opts.Group, opts.Resource) {
+		return nil, fmt.Errorf("no schema conversions registered for %s/%s", opts.Group, opts.Resource)
+	}
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 100
+	}
+
+	report := &SchemaRewriteReport{
+		Group:      opts.Group,
+		Resource:   opts.Resource,
+		Namespaces: make(map[string]*SchemaRewriteNamespaceReport, len(opts.Namespaces)),
+	}
+	for _, ns := range opts.Namespaces {
+		nsReport := &SchemaRewriteNamespaceReport{Namespace: ns}
+		if prev, ok := opts.Resume[ns]; ok && prev != nil {
+			cp := *prev
+			nsReport = &cp
+		}
+		report.Namespaces[ns] = nsReport
+
+		for !nsReport.Done {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			if err := s.rewriteSchemaPage(ctx, opts, nsReport); err != nil {
+				return report, err
+			}
+			if opts.Progress != nil {
+				opts.Progress(report)
+			}
+		}
+	}
+	return report, nil
+}
+
+func (s *server) rewriteSchemaPage(ctx context.Context, opts SchemaRewriteOptions, report *SchemaRewriteNamespaceReport) error {
+	type pending struct {
+		key   *resourcepb.ResourceKey
+		rv    int64
+		value []byte
+		token string
+	}
+	var page []pending
+
+	_, err := s.backend.ListIterator(ctx, &resourcepb.ListRequest{
+		Limit:         opts.BatchSize,
+		NextPageToken: report.ContinueToken,
+		Options: &resourcepb.ListOptions{
+			Key: &resourcepb.ResourceKey{
+				Namespace: report.Namespace,
+				Group:     opts.Group,
+				Resource:  opts.Resource,
+			},
+		},
+	}, func(iter ListIterator) error {
+		for iter.Next() {
+			if err := iter.Error(); err != nil {
+				return err
+			}
+			page = append(page, pending{
+				key: &resourcepb.ResourceKey{
+					Namespace: report.Namespace,
+					Group:     opts.Group,
+					Resource:  opts.Resource,
+					Name:      iter.Name(),
+				},
+				rv:    iter.ResourceVersion(),
+				value: iter.Value(),
+				token: iter.ContinueToken(),
+			})
+			if int64(len(page)) >= opts.BatchSize {
+				break
+			}
+		}
+		return iter.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range page {
+		report.Scanned++
+		report.ContinueToken = item.token
+
+		value, changed, err := s.schemaMigrations.Migrate(ctx, item.key, item.value)
+		if err != nil {
+			report.Failures = append(report.Failures, SchemaRewriteFailure{Name: item.key.Name, Error: err.Error()})
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		rsp, err := s.Update(ctx, &resourcepb.UpdateRequest{
+			Key:             item.key,
+			ResourceVersion: item.rv,
+			Value:           value,
+		})
+		if err == nil && rsp.Error != nil {
+			if rsp.Error.Code == http.StatusConflict {
+				// Updated since it was listed; the new value is converted on read
+				report.Skipped++
+				continue
+			}
+			err = GetError(rsp.Error)
+		}
+		if err != nil {
+			report.Failures = append(report.Failures, SchemaRewriteFailure{Name: item.key.Name, Error: err.Error()})
+			continue
+		}
+		report.Rewritten++
+		if s.storageMetrics != nil && s.storageMetrics.SchemaMigrations != nil {
+			s.storageMetrics.SchemaMigrations.WithLabelValues(opts.Group+"/"+opts.Resource, "rewrite").Inc()
+		}
+	}
+
+	if int64(len(page)) < opts.BatchSize {
+		report.Done = true
+		report.ContinueToken = ""
+	}
+	return nil
+}
+
+const (
+	schemaRewriteSection = kv.SchemaRewriteSection
+
+	defaultSchemaRewriteInterval = time.Hour
+)
+
+// SchemaRewriteConfig configures the background job rewriting stored objects to the latest schema
+type SchemaRewriteConfig struct {
+	// Stores the progress of the rewrites, so they resume after a restart.
+	// When nil, objects are only converted on read.
+	KV KV
+
+	// How often new conversions and namespaces are looked for (defaults to 1h)
+	Interval time.Duration
+}
+
+// schemaRewriteState is the persisted progress of the rewrite of a group resource
+type schemaRewriteState struct {
+	Revision string               `json:"revision"`
+	Report   *SchemaRewriteReport `json:"report"`
+}
+
+// startSchemaRewrites periodically rewrites the objects stored with an older schema version.
+// Each page is persisted, so a restarted server continues where the previous one stopped.
+// Replicas may process the same page; the second update conflicts and is skipped.
+func (s *server) startSchemaRewrites() {
+	if s.schemaMigrations == nil || s.schemaRewriteKV == nil {
+		return
+	}
+	interval := s.schemaRewriteInterval
+	if interval <= 0 {
+		interval = defaultSchemaRewriteInterval
+	}
+
+	s.indexersWG.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				for _, gr := range s.schemaMigrations.groupResources() {
+					if err := s.runSchemaRewrite(s.ctx, gr.Group, gr.Resource); err != nil {
+						s.log.Error("schema rewrite failed", "group", gr.Group, "resource", gr.Resource, "error", err)
+					}
+				}
+			}
+		}
+	})
+}
+
+// runSchemaRewrite rewrites the objects of a group resource in every namespace,
+// resuming from the persisted progress
+func (s *server) runSchemaRewrite(ctx context.Context, group, resource string) error {
+	stateKey := group + "/" + resource
+	revision := s.schemaMigrations.revision(group, resource)
+
+	state := &schemaRewriteState{}
+	if err := s.readSchemaRewriteState(ctx, stateKey, state); err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("reading rewrite progress: %w", err)
+	}
+	if state.Revision != revision || state.Report == nil {
+		// New conversions were registered since the last run; start over
+		state = &schemaRewriteState{Revision: revision, Report: &SchemaRewriteReport{
+			Group:      group,
+			Resource:   resource,
+			Namespaces: map[string]*SchemaRewriteNamespaceReport{},
+		}}
+	}
+
+	stats, err := s.backend.GetResourceStats(ctx, NamespacedResource{Group: group, Resource: resource}, 0)
+	if err != nil {
+		return fmt.Errorf("listing namespaces: %w", err)
+	}
+
+	for _, stat := range stats {
+		ns := stat.Namespace
+		if prev, ok := state.Report.Namespaces[ns]; ok && prev.Done {
+			continue
+		}
+
+		nsCtx := identity.WithServiceIdentityForSingleNamespaceContext(ctx, ns)
+		_, err := s.RewriteSchemas(nsCtx, SchemaRewriteOptions{
+			Group:      group,
+			Resource:   resource,
+			Namespaces: []string{ns},
+			Resume:     state.Report.Namespaces,
+			Progress: func(report *SchemaRewriteReport) {
+				state.Report.Namespaces[ns] = report.Namespaces[ns]
+				if err := s.writeSchemaRewriteState(ctx, stateKey, state); err != nil {
+					s.log.Warn("failed to save schema rewrite progress", "group", group, "resource", resource, "namespace", ns, "error", err)
+				}
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("rewriting namespace %s: %w", ns, err)
+		}
+
+		report := state.Report.Namespaces[ns]
+		if report != nil && len(report.Failures) > 0 {
+			s.log.Warn("some objects could not be rewritten to the latest schema",
+				"group", group,
+				"resource", resource,
+				"namespace", ns,
+				"failures", len(report.Failures))
+		}
+	}
+	return nil
+}
+
+func (s *server) readSchemaRewriteState(ctx context.Context, key string, state *schemaRewriteState) error {
+	r, err := s.schemaRewriteKV.Get(ctx, schemaRewriteSection, key)
+	if err != nil {
+		return err
+	}
+	data, err := readAndClose(r)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, state)
+}
+
+func (s *server) writeSchemaRewriteState(ctx context.Context, key string, state *schemaRewriteState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	w, err := s.schemaRewriteKV.Save(ctx, schemaRewriteSection, key)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
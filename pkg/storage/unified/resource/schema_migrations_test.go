@@ -0,0 +1,184 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	badger "github.com/dgraph-io/badger/v4"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	authlib "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+func TestSchemaMigrations(t *testing.T) {
+	ctx := context.Background()
+	key := &resourcepb.ResourceKey{
+		Namespace: "default",
+		Group:     "playlist.grafana.app",
+		Resource:  "playlists",
+		Name:      "p1",
+	}
+
+	m := NewSchemaMigrations()
+	err := m.Register(
+		SchemaConversion{
+			Group:    key.Group,
+			Resource: key.Resource,
+			From:     "v0alpha1",
+			To:       "v1",
+			Convert: func(_ context.Context, obj *unstructured.Unstructured) error {
+				interval, _, _ := unstructured.NestedString(obj.Object, "spec", "interval")
+				unstructured.RemoveNestedField(obj.Object, "spec", "interval")
+				return unstructured.SetNestedField(obj.Object, interval, "spec", "refresh")
+			},
+		},
+		SchemaConversion{
+			Group:    key.Group,
+			Resource: key.Resource,
+			From:     "v1",
+			To:       "v2",
+			Convert: func(_ context.Context, obj *unstructured.Unstructured) error {
+				return unstructured.SetNestedField(obj.Object, true, "spec", "enabled")
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	t.Run("chains conversions up to the latest version", func(t *testing.T) {
+		out, changed, err := m.Migrate(ctx, key, []byte(`{"apiVersion":"playlist.grafana.app/v0alpha1","kind":"Playlist","metadata":{"name":"p1"},"spec":{"interval":"5m"}}`))
+		require.NoError(t, err)
+		require.True(t, changed)
+
+		obj := map[string]any{}
+		require.NoError(t, json.Unmarshal(out, &obj))
+		require.Equal(t, "playlist.grafana.app/v2", obj["apiVersion"])
+		require.Equal(t, map[string]any{"refresh": "5m", "enabled": true}, obj["spec"])
+	})
+
+	t.Run("latest version is unchanged", func(t *testing.T) {
+		in := []byte(`{"apiVersion":"playlist.grafana.app/v2","kind":"Playlist","metadata":{"name":"p1"}}`)
+		out, changed, err := m.Migrate(ctx, key, in)
+		require.NoError(t, err)
+		require.False(t, changed)
+		require.Equal(t, in, out)
+	})
+
+	t.Run("other resources are ignored", func(t *testing.T) {
+		in := []byte(`not json`)
+		out, changed, err := m.Migrate(ctx, &resourcepb.ResourceKey{Group: "other", Resource: "things"}, in)
+		require.NoError(t, err)
+		require.False(t, changed)
+		require.Equal(t, in, out)
+	})
+
+	t.Run("rejects duplicate and cyclic registrations", func(t *testing.T) {
+		noop := func(_ context.Context, _ *unstructured.Unstructured) error { return nil }
+		err := m.Register(SchemaConversion{Group: key.Group, Resource: key.Resource, From: "v1", To: "v3", Convert: noop})
+		require.ErrorContains(t, err, "already registered")
+
+		err = m.Register(SchemaConversion{Group: key.Group, Resource: key.Resource, From: "v2", To: "v0alpha1", Convert: noop})
+		require.ErrorContains(t, err, "cycle")
+
+		err = m.Register(
+			SchemaConversion{Group: key.Group, Resource: key.Resource, From: "v3", To: "v4", Convert: noop},
+			SchemaConversion{Group: key.Group, Resource: key.Resource, From: "v4", To: "v3", Convert: noop},
+		)
+		require.ErrorContains(t, err, "cycle")
+
+		in := []byte(`{"apiVersion":"playlist.grafana.app/v3","kind":"Playlist","metadata":{"name":"p1"}}`)
+		out, changed, err := m.Migrate(ctx, key, in)
+		require.NoError(t, err)
+		require.False(t, changed, "failed registrations are not partially applied")
+		require.Equal(t, in, out)
+	})
+}
+
+func TestRewriteSchemas(t *testing.T) {
+	ctx := authlib.WithAuthInfo(context.Background(), newWatchTestUser())
+	srv := newWatchTestServer(t, watchTestServerOpts{})
+
+	srv.schemaMigrations = NewSchemaMigrations()
+	require.NoError(t, srv.schemaMigrations.Register(SchemaConversion{
+		Group:    watchTestGroup,
+		Resource: watchTestResource,
+		From:     "v0alpha1",
+		To:       "v1",
+		Convert: func(_ context.Context, obj *unstructured.Unstructured) error {
+			interval, _, _ := unstructured.NestedString(obj.Object, "spec", "interval")
+			unstructured.RemoveNestedField(obj.Object, "spec", "interval")
+			return unstructured.SetNestedField(obj.Object, interval, "spec", "refresh")
+		},
+	}))
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+	srv.schemaRewriteKV = NewBadgerKV(db)
+
+	for range 3 {
+		require.NoError(t, createTestPlaylist(ctx, srv))
+	}
+	listKey := &resourcepb.ResourceKey{Namespace: watchTestNamespace, Group: watchTestGroup, Resource: watchTestResource}
+
+	t.Run("objects are converted on read", func(t *testing.T) {
+		rsp, err := srv.List(ctx, &resourcepb.ListRequest{Options: &resourcepb.ListOptions{Key: listKey}})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Len(t, rsp.Items, 3)
+		for _, item := range rsp.Items {
+			obj := map[string]any{}
+			require.NoError(t, json.Unmarshal(item.Value, &obj))
+			require.Equal(t, "playlist.grafana.app/v1", obj["apiVersion"])
+		}
+	})
+
+	t.Run("stored objects are rewritten and the progress is saved", func(t *testing.T) {
+		require.NoError(t, srv.runSchemaRewrite(ctx, watchTestGroup, watchTestResource))
+
+		state := &schemaRewriteState{}
+		require.NoError(t, srv.readSchemaRewriteState(ctx, watchTestGroup+"/"+watchTestResource, state))
+		require.Equal(t, srv.schemaMigrations.revision(watchTestGroup, watchTestResource), state.Revision)
+		report := state.Report.Namespaces[watchTestNamespace]
+		require.NotNil(t, report)
+		require.True(t, report.Done)
+		require.Equal(t, int64(3), report.Scanned)
+		require.Equal(t, int64(3), report.Rewritten)
+		require.Empty(t, report.Failures)
+
+		// the stored values are at the latest version now
+		_, err := srv.backend.ListIterator(ctx, &resourcepb.ListRequest{Options: &resourcepb.ListOptions{Key: listKey}}, func(iter ListIterator) error {
+			for iter.Next() {
+				obj := map[string]any{}
+				require.NoError(t, json.Unmarshal(iter.Value(), &obj))
+				require.Equal(t, "playlist.grafana.app/v1", obj["apiVersion"])
+				require.Equal(t, "5m", obj["spec"].(map[string]any)["refresh"])
+			}
+			return iter.Error()
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("finished namespaces are skipped", func(t *testing.T) {
+		var pages int
+		report, err := srv.RewriteSchemas(ctx, SchemaRewriteOptions{
+			Group:      watchTestGroup,
+			Resource:   watchTestResource,
+			Namespaces: []string{watchTestNamespace},
+			BatchSize:  2,
+			Progress:   func(*SchemaRewriteReport) { pages++ },
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, pages)
+		require.Equal(t, int64(3), report.Namespaces[watchTestNamespace].Scanned)
+		require.Zero(t, report.Namespaces[watchTestNamespace].Rewritten, "already at the latest version")
+
+		require.NoError(t, srv.runSchemaRewrite(ctx, watchTestGroup, watchTestResource))
+		state := &schemaRewriteState{}
+		require.NoError(t, srv.readSchemaRewriteState(ctx, watchTestGroup+"/"+watchTestResource, state))
+		require.Equal(t, int64(3), state.Report.Namespaces[watchTestNamespace].Rewritten)
+	})
+}
@@ -1,7 +1,9 @@
 package resource
 
 import (
+	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana-app-sdk/app"
@@ -79,3 +81,89 @@ func selectableFieldsForManifest(m app.Manifest) map[string][]string {
 
 	return fields
 }
+
+// selectableFieldSegment is a single step in a selectable field path
+type selectableFieldSegment struct {
+	name  string
+	index int // only used when name is empty
+}
+
+// parseSelectableFieldPath parses the JSONPath-like paths that can be declared as selectable fields.
+// Paths may start with "$." or ".", use dots between names and numeric indexes for arrays,
+// for example "spec.targets[0].datasource.uid".
+func parseSelectableFieldPath(path string) ([]selectableFieldSegment, error) {
+	p := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if p == "" {
+		return nil, fmt.Errorf("empty selectable field path")
+	}
+
+	var segments []selectableFieldSegment
+	for _, part := range strings.Split(p, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name == "" {
+			return nil, fmt.Errorf("invalid selectable field path: %s", path)
+		}
+		segments = append(segments, selectableFieldSegment{name: name})
+
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("invalid selectable field path: %s", path)
+			}
+			i, err := strconv.Atoi(idx)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid array index in selectable field path: %s", path)
+			}
+			segments = append(segments, selectableFieldSegment{index: i})
+			if after == "" {
+				break
+			}
+			if !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("invalid selectable field path: %s", path)
+			}
+			rest = after[1:]
+		}
+	}
+	return segments, nil
+}
+
+// selectableFieldValue returns the value at the selectable field path as a string.
+func selectableFieldValue(obj map[string]any, path string) (string, bool) {
+	segments, err := parseSelectableFieldPath(path)
+	if err != nil {
+		return "", false
+	}
+
+	var current any = obj
+	for _, seg := range segments {
+		switch v := current.(type) {
+		case map[string]any:
+			if seg.name == "" {
+				return "", false
+			}
+			current = v[seg.name]
+			if current == nil {
+				return "", false
+			}
+		case []any:
+			if seg.name != "" || seg.index >= len(v) {
+				return "", false
+			}
+			current = v[seg.index]
+		default:
+			return "", false
+		}
+	}
+
+	switch v := current.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case map[string]any, []any:
+		return "", false // only scalar values can be selected
+	default:
+		// In practice there should only be strings, bools and int/float selectable fields.
+		return fmt.Sprintf("%v", v), true
+	}
+}
@@ -164,3 +164,39 @@ func TestSelectableFields(t *testing.T) {
 	assert.Contains(t, result["iam.grafana.app/teambindings"], "spec.subject.name")
 	assert.Contains(t, result["iam.grafana.app/teambindings"], "spec.teamRef.name")
 }
+
+func TestSelectableFieldValue(t *testing.T) {
+	obj := map[string]any{
+		"spec": map[string]any{
+			"title":   "hello",
+			"enabled": true,
+			"count":   int64(3),
+			"targets": []any{
+				map[string]any{"datasource": map[string]any{"uid": "ds1"}},
+				map[string]any{"datasource": map[string]any{"uid": "ds2"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		path  string
+		value string
+		found bool
+	}{
+		{path: "spec.title", value: "hello", found: true},
+		{path: ".spec.title", value: "hello", found: true},
+		{path: "$.spec.enabled", value: "true", found: true},
+		{path: "spec.count", value: "3", found: true},
+		{path: "spec.targets[1].datasource.uid", value: "ds2", found: true},
+		{path: "spec.targets[2].datasource.uid"},
+		{path: "spec.targets"},
+		{path: "spec.missing"},
+		{path: "spec.targets[x]"},
+		{path: ""},
+	}
+	for _, tc := range tests {
+		v, found := selectableFieldValue(obj, tc.path)
+		assert.Equal(t, tc.found, found, tc.path)
+		assert.Equal(t, tc.value, v, tc.path)
+	}
+}
@@ -211,7 +211,9 @@ type BlobSupport interface {
 	// For large payloads, signed URLs are required to avoid protobuf message size limits
 	GetResourceBlob(ctx context.Context, resource *resourcepb.ResourceKey, info *utils.BlobInfo, mustProxy bool) (*resourcepb.GetBlobResponse, error)
 
-	// TODO? List+Delete?  This is for admin access
+	// Remove a blob once no version of the resource references it.  Removing a blob
+	// that does not exist is not an error.
+	DeleteResourceBlob(ctx context.Context, resource *resourcepb.ResourceKey, info *utils.BlobInfo) error
 }
 
 type QOSEnqueuer interface {
@@ -231,6 +233,13 @@ type BlobConfig struct {
 
 	// Directly implemented blob support
 	Backend BlobSupport
+
+	// Store blobs as content addressed chunks, so identical content is only stored once
+	ContentAddressed bool
+
+	// How often unreferenced chunks are removed. Zero disables the collection.
+	// Only used when ContentAddressed is set
+	GarbageCollectionInterval time.Duration
 }
 
 // Passed as input to the constructor
@@ -345,6 +354,15 @@ type ResourceServerOptions struct {
 	// Link RBAC
 	AccessClient claims.AccessClient
 
+	// Conversions applied to stored objects written with an older schema version
+	SchemaMigrations *SchemaMigrations
+
+	// Background rewrite of the objects stored with an older schema version
+	SchemaRewrite SchemaRewriteConfig
+
+	// Optional tamper evident hash chain for the resource history
+	AuditChain *AuditChain
+
 	// Manage secure values
 	SecureValues secrets.InlineSecureValueSupport
 
@@ -527,6 +545,10 @@ func NewUninitializedResourceServer(opts ResourceServerOptions) (*server, error)
 		access:                         opts.AccessClient,
 		secure:                         opts.SecureValues,
 		writeHooks:                     opts.WriteHooks,
+		schemaMigrations:               opts.SchemaMigrations,
+		schemaRewriteKV:                opts.SchemaRewrite.KV,
+		schemaRewriteInterval:          opts.SchemaRewrite.Interval,
+		auditChain:                     opts.AuditChain,
 		now:                            opts.Now,
 		ctx:                            ctx,
 		cancel:                         cancel,
@@ -541,6 +563,7 @@ func NewUninitializedResourceServer(opts ResourceServerOptions) (*server, error)
 		quotasConfig:                   opts.QuotasConfig,
 		artificialSuccessfulWriteDelay: opts.Search.IndexMinUpdateInterval,
 		bookmarkFrequency:              opts.BookmarkFrequency,
+		blobGCInterval:                 opts.Blob.GarbageCollectionInterval,
 		vectorWriteReconciler:          opts.VectorReconciler,
 	}
 
@@ -587,9 +610,13 @@ func initializeBlobStorage(opts ResourceServerOptions) (BlobSupport, error) {
 			return nil, err
 		}
 
-		return NewCDKBlobSupport(ctx, CDKBlobSupportOptions{
+		cdkOpts := CDKBlobSupportOptions{
 			Bucket: NewInstrumentedBucket(bucket, opts.Reg),
-		})
+		}
+		if opts.Blob.ContentAddressed {
+			return NewCDKContentAddressedBlobSupport(ctx, cdkOpts)
+		}
+		return NewCDKBlobSupport(ctx, cdkOpts)
 	}
 
 	// Check if the backend supports blob storage
@@ -611,6 +638,8 @@ type server struct {
 	diagnostics      resourcepb.DiagnosticsServer //nolint:staticcheck
 	access           claims.AccessClient
 	writeHooks       WriteAccessHooks
+	schemaMigrations *SchemaMigrations
+	auditChain       *AuditChain
 	now              func() int64
 	mostRecentRV     atomic.Int64 // The most recent resource version seen by the server
 	storageMetrics   *StorageMetrics
@@ -647,6 +676,13 @@ type server struct {
 
 	bookmarkFrequency time.Duration
 
+	// How often unreferenced blob chunks are removed (content addressed blobs only)
+	blobGCInterval time.Duration
+
+	// Progress store and interval of the background schema rewrite
+	schemaRewriteKV       KV
+	schemaRewriteInterval time.Duration
+
 	// Vector reconciler (which owns the backfiller). Started in Init,
 	// joined in Stop via indexersWG.
 	vectorWriteReconciler BroadcasterConsumer
@@ -678,6 +714,13 @@ func (s *server) Init(ctx context.Context) error {
 			s.startVectorIndexers()
 		}
 
+		if s.initErr == nil {
+			s.startBlobGarbageCollection()
+			s.watchRemovedHistory()
+			s.startAuditCheckpoints()
+			s.startSchemaRewrites()
+		}
+
 		if s.initErr != nil {
 			s.log.Error("error running resource server init", "error", s.initErr)
 		}
@@ -1051,7 +1094,9 @@ func (s *server) create(ctx context.Context, user claims.AuthInfo, req *resource
 			return nil, status.Error(codes.Aborted, err.Error())
 		}
 		rsp.Error = AsErrorResult(err)
+		return rsp, nil
 	}
+	s.appendAuditLink(ctx, event, rsp.ResourceVersion)
 	return rsp, nil
 }
 
@@ -1190,7 +1235,9 @@ func (s *server) update(ctx context.Context, user claims.AuthInfo, req *resource
 	rsp.ResourceVersion, err = s.backend.WriteEvent(ctx, *event)
 	if err != nil {
 		rsp.Error = AsErrorResult(err)
+		return rsp, nil
 	}
+	s.appendAuditLink(ctx, event, rsp.ResourceVersion)
 	return rsp, nil
 }
 
@@ -1306,7 +1353,9 @@ func (s *server) delete(ctx context.Context, user claims.AuthInfo, req *resource
 	rsp.ResourceVersion, err = s.backend.WriteEvent(ctx, event)
 	if err != nil {
 		rsp.Error = AsErrorResult(err)
+		return rsp, nil
 	}
+	s.appendAuditLink(ctx, &event, rsp.ResourceVersion)
 	return rsp, nil
 }
 
@@ -1375,9 +1424,13 @@ func (s *server) read(ctx context.Context, user claims.AuthInfo, req *resourcepb
 				Code: http.StatusForbidden,
 			}}, nil
 	}
+	value := rsp.Value
+	if rsp.Error == nil {
+		value = s.migrateOnRead(ctx, req.Key, value)
+	}
 	return &resourcepb.ReadResponse{
 		ResourceVersion: rsp.ResourceVersion,
-		Value:           rsp.Value,
+		Value:           value,
 		Error:           rsp.Error,
 	}, nil
 }
@@ -1472,14 +1525,29 @@ func (s *server) listAuthorized(ctx context.Context, req *resourcepb.ListRequest
 		nextToken string
 	)
 	maxPageBytes := s.maxPageSizeBytes
+	fieldSelectors := storageFieldSelectors(key, req.Options.Fields)
 
 	rv, err := backendList(ctx, req, func(iter ListIterator) error {
+		// Set when a stored value can not be evaluated against the field selectors
+		var selectorErr error
+
 		// Convert ListIterator to iter.Seq for FilterAuthorized
 		candidates := func(yield func(candidateItem) bool) {
 			for iter.Next() {
 				if err := iter.Error(); err != nil {
 					return
 				}
+				// Field selectors not served by the search index are evaluated on the stored value
+				if len(fieldSelectors) > 0 {
+					match, err := matchesFieldSelectors(iter.Value(), fieldSelectors)
+					if err != nil {
+						selectorErr = fmt.Errorf("evaluate field selectors on %s: %w", iter.Name(), err)
+						return
+					}
+					if !match {
+						continue
+					}
+				}
 				if !yield(candidateItem{
 					name:            iter.Name(),
 					folder:          iter.Folder(),
@@ -1517,14 +1585,24 @@ func (s *server) listAuthorized(ctx context.Context, req *resourcepb.ListRequest
 				break
 			}
 
+			value := s.migrateOnRead(ctx, &resourcepb.ResourceKey{
+				Namespace: key.Namespace,
+				Group:     key.Group,
+				Resource:  key.Resource,
+				Name:      item.name,
+			}, item.value)
+
 			rsp.Items = append(rsp.Items, &resourcepb.ResourceWrapper{
 				ResourceVersion: item.resourceVersion,
-				Value:           item.value,
+				Value:           value,
 			})
-			pageBytes += len(item.value)
+			pageBytes += len(value)
 			lastContinueToken = item.continueToken
 		}
 
+		if selectorErr != nil {
+			return selectorErr
+		}
 		return iter.Error()
 	})
 
@@ -1818,6 +1896,9 @@ func (s *server) Watch(req *resourcepb.WatchRequest, srv resourcepb.ResourceStor
 		return nil
 	}
 
+	// Field selectors that can be evaluated on stored objects
+	fieldSelectors := storageFieldSelectors(key, supportedFieldSelectors(req.Options.Fields))
+
 	var lastEmittedRV int64 // tracks the most recent RV sent to the client
 	if req.SendInitialEvents {
 		// Backfill the stream by adding every existing entities.
@@ -1829,10 +1910,24 @@ func (s *server) Watch(req *resourcepb.WatchRequest, srv resourcepb.ResourceStor
 				if !checker(iter.Name(), iter.Folder()) {
 					continue
 				}
+				// The field selectors are evaluated on the object at the latest schema version
+				value := s.migrateOnRead(ctx, &resourcepb.ResourceKey{
+					Namespace: iter.Namespace(),
+					Group:     req.Options.GetKey().GetGroup(),
+					Resource:  req.Options.GetKey().GetResource(),
+					Name:      iter.Name(),
+				}, iter.Value())
+				match, err := matchesFieldSelectors(value, fieldSelectors)
+				if err != nil {
+					return fmt.Errorf("evaluate field selectors on %s: %w", iter.Name(), err)
+				}
+				if !match {
+					continue
+				}
 				if err := srv.Send(&resourcepb.WatchEvent{
 					Type: resourcepb.WatchEvent_ADDED,
 					Resource: &resourcepb.WatchEvent_Resource{
-						Value:   iter.Value(),
+						Value:   value,
 						Version: iter.ResourceVersion(),
 					},
 				}); err != nil {
@@ -1896,6 +1991,8 @@ func (s *server) Watch(req *resourcepb.WatchRequest, srv resourcepb.ResourceStor
 				// remove the delete marker stored in the value for deleted objects
 				if event.Type == resourcepb.WatchEvent_DELETED {
 					value = []byte{}
+				} else {
+					value = s.migrateOnRead(ctx, event.Key, value)
 				}
 				resp := &resourcepb.WatchEvent{
 					Timestamp: event.Timestamp,
@@ -1922,6 +2019,16 @@ func (s *server) Watch(req *resourcepb.WatchRequest, srv resourcepb.ResourceStor
 						}
 					}
 				}
+				// The field selectors are evaluated on the objects at the latest schema version
+				migrated := *event
+				migrated.Value = value
+				match, err := watchEventMatchesFieldSelectors(&migrated, resp.Previous, fieldSelectors)
+				if err != nil {
+					return fmt.Errorf("evaluate field selectors on %s: %w", event.Key.Name, err)
+				}
+				if !match {
+					continue
+				}
 				if err := srv.Send(resp); err != nil {
 					return err
 				}
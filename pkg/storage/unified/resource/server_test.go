@@ -2231,6 +2231,10 @@ func (s *stubBlobSupport) GetResourceBlob(_ context.Context, _ *resourcepb.Resou
 	return &resourcepb.GetBlobResponse{}, nil
 }
 
+func (s *stubBlobSupport) DeleteResourceBlob(_ context.Context, _ *resourcepb.ResourceKey, _ *utils.BlobInfo) error {
+	return nil
+}
+
 // errorOnReadResourceBackend lets tests inject an arbitrary ReadResource
 // error to exercise PutBlob's failure passthrough.
 type errorOnReadResourceBackend struct {
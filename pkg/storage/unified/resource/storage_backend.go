@@ -14,6 +14,7 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bwmarrin/snowflake"
@@ -87,6 +88,7 @@ type kvStorageBackend struct {
 	eventRetentionPeriod    time.Duration
 	eventPruningInterval    time.Duration
 	historyPruner           Pruner
+	historyRemoved          atomic.Pointer[HistoryRemovedFunc]
 	garbageCollection       GarbageCollectionConfig
 	gcGate                  *GCGate
 	lastImportStore         *lastImportStore
@@ -415,7 +417,7 @@ func (k *kvStorageBackend) pruneEvents(ctx context.Context, key PruningKey) erro
 
 	prunerMaxLimit := LookupPrunerHistoryLimit(key.Group, key.Resource, k.dashboardVersionsToKeep)
 	counter := 0
-	deleted := 0
+	var pruned []DataKey
 	// iterate over all keys for the resource and delete versions beyond the configured limit
 	for datakey, err := range k.dataStore.Keys(ctx, ListRequestKey{
 		Namespace: key.Namespace,
@@ -435,12 +437,29 @@ func (k *kvStorageBackend) pruneEvents(ctx context.Context, key PruningKey) erro
 
 		// If we already have the configured number of versions, delete any more create or update events
 		if datakey.Action != DataActionDeleted {
-			err := k.dataStore.Delete(ctx, datakey)
+			pruned = append(pruned, datakey)
+		}
+	}
+
+	// The values are read after the listing, since some KV stores can not read while listing
+	removed := k.historyRemoved.Load()
+	var values [][]byte
+	deleted := 0
+	for _, datakey := range pruned {
+		if removed != nil {
+			value, err := k.readDataValue(ctx, datakey)
 			if err != nil {
 				return err
 			}
-			deleted += 1
+			values = append(values, value)
 		}
+		if err := k.dataStore.Delete(ctx, datakey); err != nil {
+			return err
+		}
+		deleted += 1
+	}
+	if removed != nil && len(values) > 0 {
+		(*removed)(ctx, key, values)
 	}
 
 	k.log.Debug("pruned history successfully",
@@ -485,6 +504,28 @@ func (k *kvStorageBackend) initPruner(ctx context.Context) error {
 	return nil
 }
 
+// OnHistoryRemoved implements HistoryRemovalNotifier. fn is called once the pruner or the
+// garbage collection removed versions of a resource.
+func (k *kvStorageBackend) OnHistoryRemoved(fn HistoryRemovedFunc) {
+	k.historyRemoved.Store(&fn)
+}
+
+func (k *kvStorageBackend) readDataValue(ctx context.Context, key DataKey) ([]byte, error) {
+	r, err := k.dataStore.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return readAndClose(r)
+}
+
+func (k *kvStorageBackend) readDataKey(ctx context.Context, key string) ([]byte, error) {
+	r, err := k.kv.Get(ctx, kv.DataSection, key)
+	if err != nil {
+		return nil, err
+	}
+	return readAndClose(r)
+}
+
 // KV returns the KV store backing this storage backend. See KVBackend.KV.
 func (b *kvStorageBackend) KV() KV {
 	return b.kv
@@ -698,11 +739,26 @@ func (b *kvStorageBackend) garbageCollectGroupResource(ctx context.Context, grou
 					continue
 				}
 
+				removed := b.historyRemoved.Load()
+				var values [][]byte
+				if removed != nil {
+					for _, deleteKey := range keysToDelete {
+						value, err := b.readDataKey(ctx, deleteKey)
+						if err != nil {
+							return fmt.Errorf("failed to read key '%s' before delete: %s", deleteKey, err)
+						}
+						values = append(values, value)
+					}
+				}
+
 				// if not in dry run mode, batch delete the keys
 				err = b.kv.BatchDelete(ctx, kv.DataSection, keysToDelete)
 				if err != nil {
 					return fmt.Errorf("failed to batch delete keys: %s", err)
 				}
+				if removed != nil {
+					(*removed)(ctx, PruningKey{Namespace: k.Namespace, Group: k.Group, Resource: k.Resource, Name: k.Name}, values)
+				}
 
 				// update the total number of keys deleted
 				keysDeleted = keysDeleted + int64(len(keysToDelete))
@@ -2627,11 +2627,15 @@ func requirementQuery(req *resourcepb.Requirement, prefix string) (query.Query,
 			return fieldFilterQuery(req.Key, filterValue(req.Key, v), prefix)
 		}), nil
 
-	case selection.NotIn:
+	case selection.NotIn, selection.NotEquals:
 		boolQuery := bleve.NewBooleanQuery()
 
 		var mustNotQueries []query.Query
 		for _, value := range req.Values {
+			if useExactTermQuery {
+				mustNotQueries = append(mustNotQueries, exactFieldTermQuery(req.Key, value, prefix))
+				continue
+			}
 			q := fieldFilterQuery(req.Key, filterValue(req.Key, value), prefix)
 			mustNotQueries = append(mustNotQueries, q)
 		}
@@ -2643,8 +2647,14 @@ func requirementQuery(req *resourcepb.Requirement, prefix string) (query.Query,
 
 		return boolQuery, nil
 
+	case resource.FieldSelectorOperatorPrefix:
+		return anyRequirementValueQuery(req.Values, func(v string) query.Query {
+			q := bleve.NewPrefixQuery(v)
+			q.SetField(prefix + req.Key)
+			return q
+		}), nil
+
 	// will fall through to the BadRequestError
-	case selection.NotEquals:
 	case selection.DoesNotExist:
 	case selection.GreaterThan:
 	case selection.LessThan:
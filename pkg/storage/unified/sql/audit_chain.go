@@ -0,0 +1,74 @@
+package sql
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/db"
+)
+
+// ProvideAuditChain creates the tamper evident history chain when it is enabled.
+// The chain is stored in the resource database; checkpoints are signed with the
+// configured ed25519 seed, which should be kept outside of the database, and
+// verified with the configured public key.
+func ProvideAuditChain(cfg *setting.Cfg, eDB db.DBProvider) (*resource.AuditChain, error) {
+	if !auditChainEnabled(cfg) {
+		return nil, nil
+	}
+	apiserverCfg := cfg.SectionWithEnvOverrides("grafana-apiserver")
+	if eDB == nil {
+		return nil, fmt.Errorf("audit chain requires the SQL resource database")
+	}
+
+	kv, err := openSQLKV(eDB)
+	if err != nil {
+		return nil, err
+	}
+
+	var signer resource.AuditChainSigner
+	if encoded := apiserverCfg.Key("audit_chain_signing_key").MustString(""); encoded != "" {
+		seed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit_chain_signing_key: %w", err)
+		}
+		signer, err = resource.NewEd25519AuditChainSigner(seed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The public key is configured on its own, so the checkpoints can be verified
+	// without the signing key, and a replaced signing key does not verify
+	var verifier resource.AuditChainVerifier
+	if encoded := apiserverCfg.Key("audit_chain_public_key").MustString(""); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit_chain_public_key: %w", err)
+		}
+		verifier, err = resource.NewEd25519AuditChainVerifier(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	opts := resource.AuditChainOptions{
+		KV:                 kv,
+		Signer:             signer,
+		Verifier:           verifier,
+		CheckpointInterval: apiserverCfg.Key("audit_chain_checkpoint_interval").MustDuration(15 * time.Minute),
+	}
+	// The history pruner removes the history rows of old links
+	if !cfg.DisablePruner {
+		opts.HistoryLimit = func(group, res string) int {
+			return resource.LookupPrunerHistoryLimit(group, res, cfg.DashboardVersionsToKeep)
+		}
+	}
+	return resource.NewAuditChain(opts)
+}
+
+func auditChainEnabled(cfg *setting.Cfg) bool {
+	return cfg.SectionWithEnvOverrides("grafana-apiserver").Key("audit_chain_enabled").MustBool(false)
+}
@@ -399,6 +399,7 @@ type backend struct {
 	dashboardVersionsToKeep int
 	batchTxnTimeout         time.Duration
 	historyPruner           resource.Pruner
+	historyRemoved          atomic.Pointer[resource.HistoryRemovedFunc]
 
 	garbageCollection GarbageCollectionConfig
 	gcGate            *resource.GCGate
@@ -521,16 +522,35 @@ func (b *backend) initPruner(ctx context.Context) error {
 		MinWait:    time.Second * 30,
 		MaxWait:    time.Minute * 5,
 		ProcessHandler: func(ctx context.Context, key resource.PruningKey) error {
-			return b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+			removed := b.historyRemoved.Load()
+			var values [][]byte
+			err := b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+				historyLimit := int64(resource.LookupPrunerHistoryLimit(key.Group, key.Resource, b.dashboardVersionsToKeep))
+				resourceKey := &resourcepb.ResourceKey{
+					Namespace: key.Namespace,
+					Group:     key.Group,
+					Resource:  key.Resource,
+					Name:      key.Name,
+				}
+				if removed != nil {
+					pruned, err := dbutil.Query(ctx, tx, sqlResourceHistoryPruneValues, &sqlPruneHistoryValuesRequest{
+						SQLTemplate:  sqltemplate.New(b.dialect),
+						HistoryLimit: historyLimit,
+						Key:          resourceKey,
+						Response:     new(historyValue),
+					})
+					if err != nil {
+						return fmt.Errorf("failed to read pruned history: %w", err)
+					}
+					for _, v := range pruned {
+						values = append(values, v.Value)
+					}
+				}
+
 				res, err := dbutil.Exec(ctx, tx, sqlResourceHistoryPrune, &sqlPruneHistoryRequest{
 					SQLTemplate:  sqltemplate.New(b.dialect),
-					HistoryLimit: int64(resource.LookupPrunerHistoryLimit(key.Group, key.Resource, b.dashboardVersionsToKeep)),
-					Key: &resourcepb.ResourceKey{
-						Namespace: key.Namespace,
-						Group:     key.Group,
-						Resource:  key.Resource,
-						Name:      key.Name,
-					},
+					HistoryLimit: historyLimit,
+					Key:          resourceKey,
 				})
 				if err != nil {
 					return fmt.Errorf("failed to prune history: %w", err)
@@ -547,6 +567,13 @@ func (b *backend) initPruner(ctx context.Context) error {
 					"rows", rows)
 				return nil
 			})
+			if err != nil {
+				return err
+			}
+			if removed != nil && len(values) > 0 {
+				(*removed)(ctx, key, values)
+			}
+			return nil
 		},
 		ErrorHandler: func(key resource.PruningKey, err error) {
 			b.log.Error("failed to prune history",
@@ -670,6 +697,8 @@ func (b *backend) garbageCollectBatch(ctx context.Context, group, resourceName s
 	span.SetAttributes(attribute.String("group", group), attribute.String("resource", resourceName), attribute.Int64("cutoffTimestamp", cutoffTimestamp), attribute.Int("batchSize", batchSize))
 	defer span.End()
 
+	removed := b.historyRemoved.Load()
+	var values []gcHistoryValue
 	var rowsAffected int64
 	err := b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
 		// query will return at most batchSize candidates
@@ -688,6 +717,18 @@ func (b *backend) garbageCollectBatch(ctx context.Context, group, resourceName s
 			return nil
 		}
 		span.AddEvent("candidates", trace.WithAttributes(attribute.Int("candidates", len(candidates))))
+		if removed != nil {
+			values, err = dbutil.Query(ctx, tx, sqlResourceHistoryGCValuesByNames, &sqlGarbageCollectValuesByNamesRequest{
+				SQLTemplate: sqltemplate.New(b.dialect),
+				Group:       group,
+				Resource:    resourceName,
+				Candidates:  candidates,
+				Response:    new(gcHistoryValue),
+			})
+			if err != nil {
+				return err
+			}
+		}
 		res, err := dbutil.Exec(ctx, tx, sqlResourceHistoryGCDeleteByNames, &sqlGarbageCollectDeleteByNamesRequest{
 			SQLTemplate: sqltemplate.New(b.dialect),
 			Group:       group,
@@ -705,9 +746,25 @@ func (b *backend) garbageCollectBatch(ctx context.Context, group, resourceName s
 		span.AddEvent("rows deleted", trace.WithAttributes(attribute.Int64("rowsDeleted", rowsAffected)))
 		return nil
 	})
+	if err == nil && removed != nil {
+		byName := map[resource.PruningKey][][]byte{}
+		for _, v := range values {
+			key := resource.PruningKey{Namespace: v.Namespace, Group: group, Resource: resourceName, Name: v.Name}
+			byName[key] = append(byName[key], v.Value)
+		}
+		for key, values := range byName {
+			(*removed)(ctx, key, values)
+		}
+	}
 	return rowsAffected, err
 }
 
+// OnHistoryRemoved implements resource.HistoryRemovalNotifier. fn is called once the pruner
+// or the garbage collection removed versions of a resource.
+func (b *backend) OnHistoryRemoved(fn resource.HistoryRemovedFunc) {
+	b.historyRemoved.Store(&fn)
+}
+
 func (b *backend) IsHealthy(ctx context.Context, _ *resourcepb.HealthCheckRequest) (*resourcepb.HealthCheckResponse, error) {
 	// ctxLogger := s.log.FromContext(log.WithContextualAttributes(ctx, []any{"method", "isHealthy"}))
 
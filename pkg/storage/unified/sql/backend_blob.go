@@ -123,3 +123,21 @@ func (b *backend) GetResourceBlob(ctx context.Context, key *resourcepb.ResourceK
 	}
 	return rsp, nil
 }
+
+func (b *backend) DeleteResourceBlob(ctx context.Context, key *resourcepb.ResourceKey, info *utils.BlobInfo) error {
+	b.logCall("DeleteResourceBlob")
+	ctx, span := tracer.Start(ctx, "sql.backend.DeleteResourceBlob")
+	defer span.End()
+
+	if info == nil || info.UID == "" {
+		return fmt.Errorf("missing blob info")
+	}
+	return b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+		_, err := dbutil.Exec(ctx, tx, sqlResourceBlobDelete, sqlResourceBlobDeleteRequest{
+			SQLTemplate: sqltemplate.New(b.dialect),
+			Key:         key,
+			UID:         info.UID,
+		})
+		return err
+	})
+}
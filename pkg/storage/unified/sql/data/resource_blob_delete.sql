@@ -0,0 +1,8 @@
+DELETE FROM {{ .Ident "resource_blob" }}
+WHERE 1 = 1
+  AND {{ .Ident "namespace" }} = {{ .Arg .Key.Namespace }}
+  AND {{ .Ident "group" }}     = {{ .Arg .Key.Group }}
+  AND {{ .Ident "resource" }}  = {{ .Arg .Key.Resource }}
+  AND {{ .Ident "name" }}      = {{ .Arg .Key.Name }}
+  AND {{ .Ident "uuid" }}      = {{ .Arg .UID }}
+;
@@ -0,0 +1,20 @@
+{{/* The values of the rows resource_history_gc_delete_by_names.sql removes, read before they are removed. */}}
+SELECT {{ .Ident "namespace" | .Into .Response.Namespace }},
+       {{ .Ident "name" | .Into .Response.Name }},
+       {{ .Ident "value" | .Into .Response.Value }}
+FROM {{ .Ident "resource_history" }}
+WHERE {{ .Ident "group" }} = {{ .Arg .Group }}
+  AND {{ .Ident "resource" }} = {{ .Arg .Resource }}
+  AND ({{ .Ident "namespace" }}, {{ .Ident "name" }}) IN (
+    {{- range $i, $candidate := .Candidates -}}
+    {{- if $i }}, {{ end -}}
+    ({{ $.Arg $candidate.Namespace }}, {{ $.Arg $candidate.Name }})
+    {{- end -}}
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM {{ .Ident "resource" }} r
+    WHERE r.{{ .Ident "namespace" }} = {{ .Ident "resource_history" }}.{{ .Ident "namespace" }}
+      AND r.{{ .Ident "group" }} = {{ .Ident "resource_history" }}.{{ .Ident "group" }}
+      AND r.{{ .Ident "resource" }} = {{ .Ident "resource_history" }}.{{ .Ident "resource" }}
+      AND r.{{ .Ident "name" }} = {{ .Ident "resource_history" }}.{{ .Ident "name" }}
+  );
@@ -297,6 +297,40 @@ func initResourceTables(mg *migrator.Migrator) string {
 	mg.AddMigration("create table "+resource_stats_aggregates_table.Name, migrator.NewAddTableMigration(resource_stats_aggregates_table))
 	mg.AddMigration("Change key_path collation of resource_stats_aggregates in postgres", migrator.NewRawSQLMigration("").Postgres(`ALTER TABLE resource_stats_aggregates ALTER COLUMN key_path TYPE VARCHAR(2048) COLLATE "C";`))
 
+	// Tables backing the unified/auditchain and unified/auditcheckpoint KV
+	// sections used by the optional tamper evident history chain.
+	resource_audit_chain_table := migrator.Table{
+		Name: "resource_audit_chain",
+		Columns: []*migrator.Column{
+			{Name: "key_path", Type: migrator.DB_NVarchar, Length: 2048, Nullable: false, IsPrimaryKey: true, IsLatin: true},
+			{Name: "value", Type: migrator.DB_Text, Nullable: false},
+		},
+	}
+	mg.AddMigration("create table "+resource_audit_chain_table.Name, migrator.NewAddTableMigration(resource_audit_chain_table))
+	mg.AddMigration("Change key_path collation of resource_audit_chain in postgres", migrator.NewRawSQLMigration("").Postgres(`ALTER TABLE resource_audit_chain ALTER COLUMN key_path TYPE VARCHAR(2048) COLLATE "C";`))
+
+	resource_audit_checkpoint_table := migrator.Table{
+		Name: "resource_audit_checkpoint",
+		Columns: []*migrator.Column{
+			{Name: "key_path", Type: migrator.DB_NVarchar, Length: 2048, Nullable: false, IsPrimaryKey: true, IsLatin: true},
+			{Name: "value", Type: migrator.DB_Text, Nullable: false},
+		},
+	}
+	mg.AddMigration("create table "+resource_audit_checkpoint_table.Name, migrator.NewAddTableMigration(resource_audit_checkpoint_table))
+	mg.AddMigration("Change key_path collation of resource_audit_checkpoint in postgres", migrator.NewRawSQLMigration("").Postgres(`ALTER TABLE resource_audit_checkpoint ALTER COLUMN key_path TYPE VARCHAR(2048) COLLATE "C";`))
+
+	// Table backing the unified/schemarewrite KV section, the progress of the
+	// background rewrite of objects stored with an older schema version.
+	resource_schema_rewrite_table := migrator.Table{
+		Name: "resource_schema_rewrite",
+		Columns: []*migrator.Column{
+			{Name: "key_path", Type: migrator.DB_NVarchar, Length: 2048, Nullable: false, IsPrimaryKey: true, IsLatin: true},
+			{Name: "value", Type: migrator.DB_Text, Nullable: false},
+		},
+	}
+	mg.AddMigration("create table "+resource_schema_rewrite_table.Name, migrator.NewAddTableMigration(resource_schema_rewrite_table))
+	mg.AddMigration("Change key_path collation of resource_schema_rewrite in postgres", migrator.NewRawSQLMigration("").Postgres(`ALTER TABLE resource_schema_rewrite ALTER COLUMN key_path TYPE VARCHAR(2048) COLLATE "C";`))
+
 	return marker
 }
 
@@ -46,8 +46,10 @@ var (
 	sqlResourceHistoryGet                  = mustTemplate("resource_history_get.sql")
 	sqlResourceHistoryDelete               = mustTemplate("resource_history_delete.sql")
 	sqlResourceHistoryPrune                = mustTemplate("resource_history_prune.sql")
+	sqlResourceHistoryPruneValues          = mustTemplate("resource_history_prune_values.sql")
 	sqlResourceHistoryGarbageGetCandidates = mustTemplate("resource_history_gc_get_candidates.sql")
 	sqlResourceHistoryGCDeleteByNames      = mustTemplate("resource_history_gc_delete_by_names.sql")
+	sqlResourceHistoryGCValuesByNames      = mustTemplate("resource_history_gc_values_by_names.sql")
 	sqlChunkCandidates                     = mustTemplate("chunk_candidates.sql")
 	sqlDeleteByGUIDs                       = mustTemplate("delete_by_guids.sql")
 	sqlResourceTrash                       = mustTemplate("resource_trash.sql")
@@ -59,6 +61,7 @@ var (
 
 	sqlResourceBlobInsert = mustTemplate("resource_blob_insert.sql")
 	sqlResourceBlobQuery  = mustTemplate("resource_blob_query.sql")
+	sqlResourceBlobDelete = mustTemplate("resource_blob_delete.sql")
 
 	sqlResourceLastImportTimeInsert = mustTemplate("resource_last_import_time_insert.sql")
 	sqlResourceLastImportTimeQuery  = mustTemplate("resource_last_import_time_query.sql")
@@ -423,6 +426,29 @@ func (r *sqlPruneHistoryRequest) Validate() error {
 	return nil
 }
 
+// sqlPruneHistoryValuesRequest reads the values of the rows a sqlPruneHistoryRequest
+// with the same fields removes
+type sqlPruneHistoryValuesRequest struct {
+	sqltemplate.SQLTemplate
+	Key                   *resourcepb.ResourceKey
+	PartitionByGeneration bool
+	HistoryLimit          int64
+	Response              *historyValue
+}
+
+type historyValue struct {
+	Value []byte
+}
+
+func (r *sqlPruneHistoryValuesRequest) Validate() error {
+	return (&sqlPruneHistoryRequest{Key: r.Key, HistoryLimit: r.HistoryLimit}).Validate()
+}
+
+func (r *sqlPruneHistoryValuesRequest) Results() (historyValue, error) {
+	x := *r.Response
+	return x, nil
+}
+
 type gcCandidateName struct {
 	Namespace string
 	Name      string
@@ -478,6 +504,31 @@ func (r *sqlGarbageCollectDeleteByNamesRequest) Validate() error {
 	return nil
 }
 
+type gcHistoryValue struct {
+	Namespace string
+	Name      string
+	Value     []byte
+}
+
+// sqlGarbageCollectValuesByNamesRequest reads the values of the rows a
+// sqlGarbageCollectDeleteByNamesRequest with the same fields removes
+type sqlGarbageCollectValuesByNamesRequest struct {
+	sqltemplate.SQLTemplate
+	Group      string
+	Resource   string
+	Candidates []gcCandidateName
+	Response   *gcHistoryValue
+}
+
+func (r *sqlGarbageCollectValuesByNamesRequest) Validate() error {
+	return (&sqlGarbageCollectDeleteByNamesRequest{Group: r.Group, Resource: r.Resource, Candidates: r.Candidates}).Validate()
+}
+
+func (r *sqlGarbageCollectValuesByNamesRequest) Results() (gcHistoryValue, error) {
+	x := *r.Response
+	return x, nil
+}
+
 // chunkCandidate is one row returned by the chunked-wipe candidate queries: a
 // row's guid plus the byte size of its value column.
 type chunkCandidate struct {
@@ -591,6 +642,22 @@ func (r sqlResourceBlobQueryRequest) Validate() error {
 	return nil
 }
 
+type sqlResourceBlobDeleteRequest struct {
+	sqltemplate.SQLTemplate
+	Key *resourcepb.ResourceKey
+	UID string
+}
+
+func (r sqlResourceBlobDeleteRequest) Validate() error {
+	if r.Key == nil || r.Key.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if r.UID == "" {
+		return fmt.Errorf("missing uid")
+	}
+	return nil
+}
+
 type groupResourceVersion struct {
 	Group, Resource string
 	ResourceVersion int64
@@ -187,6 +187,20 @@ func TestUnifiedStorageQueries(t *testing.T) {
 					},
 				},
 			},
+			sqlResourceHistoryGCValuesByNames: {
+				{
+					Name: "single path",
+					Data: &sqlGarbageCollectValuesByNamesRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Group:       "group",
+						Resource:    "res",
+						Candidates: []gcCandidateName{
+							{Namespace: "ns1", Name: "name1"},
+						},
+						Response: new(gcHistoryValue),
+					},
+				},
+			},
 			sqlChunkCandidates: {
 				{
 					Name: "resource",
@@ -430,6 +444,23 @@ func TestUnifiedStorageQueries(t *testing.T) {
 				},
 			},
 
+			sqlResourceHistoryPruneValues: {
+				{
+					Name: "max-versions",
+					Data: &sqlPruneHistoryValuesRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Key: &resourcepb.ResourceKey{
+							Namespace: "default",
+							Group:     "provisioning.grafana.app",
+							Resource:  "repositories",
+							Name:      "repo-xyz",
+						},
+						HistoryLimit: 10,
+						Response:     new(historyValue),
+					},
+				},
+			},
+
 			sqlResourceHistoryPrune: {
 				{
 					Name: "max-versions",
@@ -610,6 +641,21 @@ func TestUnifiedStorageQueries(t *testing.T) {
 					},
 				},
 			},
+			sqlResourceBlobDelete: {
+				{
+					Name: "basic",
+					Data: &sqlResourceBlobDeleteRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Key: &resourcepb.ResourceKey{
+							Namespace: "x",
+							Group:     "g",
+							Resource:  "r",
+							Name:      "name",
+						},
+						UID: "abc",
+					},
+				},
+			},
 			sqlResourceHistoryDelete: {
 				{
 					Name: "guid",
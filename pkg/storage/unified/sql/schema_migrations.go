@@ -0,0 +1,27 @@
+package sql
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/db"
+)
+
+// ProvideSchemaRewrite configures the background rewrite of objects stored with an older schema.
+// The progress is stored in the resource database; without it objects are only converted on read.
+func ProvideSchemaRewrite(cfg *setting.Cfg, eDB db.DBProvider) (resource.SchemaRewriteConfig, error) {
+	apiserverCfg := cfg.SectionWithEnvOverrides("grafana-apiserver")
+	if eDB == nil || !apiserverCfg.Key("schema_rewrite_enabled").MustBool(true) {
+		return resource.SchemaRewriteConfig{}, nil
+	}
+
+	kv, err := openSQLKV(eDB)
+	if err != nil {
+		return resource.SchemaRewriteConfig{}, err
+	}
+	return resource.SchemaRewriteConfig{
+		KV:       kv,
+		Interval: apiserverCfg.Key("schema_rewrite_interval").MustDuration(time.Hour),
+	}, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/grafana/authlib/types"
 	"github.com/grafana/dskit/services"
@@ -53,6 +54,13 @@ type ServerOptions struct {
 	SecureValues     secrets.InlineSecureValueSupport
 	OwnsIndexFn      func(key resource.NamespacedResource) (bool, error)
 
+	// AuditChain is optional; nil disables the history hash chain.
+	AuditChain *resource.AuditChain
+
+	// SchemaMigrations is optional; nil disables the conversion of objects stored with an older schema.
+	SchemaMigrations *resource.SchemaMigrations
+	SchemaRewrite    resource.SchemaRewriteConfig
+
 	// DashboardStats is optional; nil disables the backfill views filter.
 	DashboardStats builders.DashboardStats
 
@@ -82,6 +90,8 @@ func NewUninitializedResourceServer(opts ServerOptions) (resource.ResourceServer
 		withSearchClient,
 		withQuotaConfig,
 		withStorageMetrics,
+		withAuditChain,
+		withSchemaMigrations,
 	)
 	if err != nil {
 		return nil, err
@@ -169,7 +179,9 @@ func withAccessClient(opts *ServerOptions, resourceOpts *resource.ResourceServer
 func withBlobConfig(opts *ServerOptions, resourceOpts *resource.ResourceServerOptions) error {
 	apiserverCfg := opts.Cfg.SectionWithEnvOverrides("grafana-apiserver")
 	resourceOpts.Blob = resource.BlobConfig{
-		URL: apiserverCfg.Key("blob_url").MustString(""),
+		URL:                       apiserverCfg.Key("blob_url").MustString(""),
+		ContentAddressed:          apiserverCfg.Key("blob_content_addressed").MustBool(false),
+		GarbageCollectionInterval: apiserverCfg.Key("blob_gc_interval").MustDuration(time.Hour),
 	}
 	// Support local file blob
 	if strings.HasPrefix(resourceOpts.Blob.URL, "./data/") {
@@ -183,6 +195,17 @@ func withBlobConfig(opts *ServerOptions, resourceOpts *resource.ResourceServerOp
 	return nil
 }
 
+func withAuditChain(opts *ServerOptions, resourceOpts *resource.ResourceServerOptions) error {
+	resourceOpts.AuditChain = opts.AuditChain
+	return nil
+}
+
+func withSchemaMigrations(opts *ServerOptions, resourceOpts *resource.ResourceServerOptions) error {
+	resourceOpts.SchemaMigrations = opts.SchemaMigrations
+	resourceOpts.SchemaRewrite = opts.SchemaRewrite
+	return nil
+}
+
 func withMaxPageSizeBytes(opts *ServerOptions, resourceOpts *resource.ResourceServerOptions) error {
 	unifiedStorageCfg := opts.Cfg.SectionWithEnvOverrides("unified_storage")
 	maxPageSizeBytes := unifiedStorageCfg.Key("max_page_size_bytes")
@@ -398,22 +398,34 @@ func (s *service) registerServer(provider grpcserver.Provider) error {
 	}
 
 	serverOptions := ServerOptions{
-		Backend:        s.backend,
-		VectorBackend:  s.vectorBackend,
-		Embedder:       s.embedder,
-		Cfg:            s.cfg,
-		Tracer:         s.tracing,
-		Reg:            s.reg,
-		AccessClient:   authzClient,
-		SearchOptions:  searchOptions,
-		SearchClient:   s.searchClient,
-		StorageMetrics: s.storageMetrics,
-		IndexMetrics:   s.indexMetrics,
-		VectorMetrics:  s.vectorMetrics,
-		Features:       s.features,
-		QOSQueue:       s.queue,
-		OwnsIndexFn:    s.OwnsIndex,
-		DashboardStats: s.dashboardStats,
+		Backend:          s.backend,
+		VectorBackend:    s.vectorBackend,
+		Embedder:         s.embedder,
+		Cfg:              s.cfg,
+		Tracer:           s.tracing,
+		Reg:              s.reg,
+		AccessClient:     authzClient,
+		SearchOptions:    searchOptions,
+		SearchClient:     s.searchClient,
+		StorageMetrics:   s.storageMetrics,
+		IndexMetrics:     s.indexMetrics,
+		VectorMetrics:    s.vectorMetrics,
+		Features:         s.features,
+		QOSQueue:         s.queue,
+		OwnsIndexFn:      s.OwnsIndex,
+		DashboardStats:   s.dashboardStats,
+		SchemaMigrations: resource.DefaultSchemaMigrations(),
+	}
+
+	if !s.searchStandalone && auditChainEnabled(s.cfg) {
+		eDB, err := ProvideResourceDB(s.cfg, nil)
+		if err != nil {
+			return err
+		}
+		serverOptions.AuditChain, err = ProvideAuditChain(s.cfg, eDB)
+		if err != nil {
+			return err
+		}
 	}
 
 	if !s.searchStandalone && s.cfg.OverridesFilePath != "" {
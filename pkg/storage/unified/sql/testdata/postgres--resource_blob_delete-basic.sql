@@ -0,0 +1,8 @@
+DELETE FROM "resource_blob"
+WHERE 1 = 1
+  AND "namespace" = 'x'
+  AND "group"     = 'g'
+  AND "resource"  = 'r'
+  AND "name"      = 'name'
+  AND "uuid"      = 'abc'
+;
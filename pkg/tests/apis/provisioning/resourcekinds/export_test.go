@@ -5,7 +5,6 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
@@ -23,16 +22,12 @@ func TestIntegrationProvisioning_ResourceKinds_Export(t *testing.T) {
 	for _, rk := range resourceKinds {
 		rk := rk
 		t.Run(rk.name, func(t *testing.T) {
-			client := rk.client(t, helper)
-
 			const count = 3
 			wantTitles := map[string]bool{}
 			for i := 0; i < count; i++ {
 				name, title := rk.instance(i)
-				_, err := client.Resource.Create(ctx, rk.newResource(t, name, title), metav1.CreateOptions{})
-				require.NoError(t, err, "should create %s", name)
+				rk.createResource(t, ctx, helper, name, title)
 				wantTitles[title] = true
-				t.Cleanup(func() { _ = client.Resource.Delete(ctx, name, metav1.DeleteOptions{}) })
 			}
 
 			repo := rk.name + "-export-repo"
@@ -72,14 +67,10 @@ func TestIntegrationProvisioning_ResourceKinds_SelectiveExport(t *testing.T) {
 	for _, rk := range resourceKinds {
 		rk := rk
 		t.Run(rk.name, func(t *testing.T) {
-			client := rk.client(t, helper)
-
 			const count = 3
 			for i := 0; i < count; i++ {
 				name, title := rk.instance(i)
-				_, err := client.Resource.Create(ctx, rk.newResource(t, name, title), metav1.CreateOptions{})
-				require.NoError(t, err, "should create %s", name)
-				t.Cleanup(func() { _ = client.Resource.Delete(ctx, name, metav1.DeleteOptions{}) })
+				rk.createResource(t, ctx, helper, name, title)
 			}
 
 			repo := rk.name + "-selective-export-repo"
@@ -12,6 +12,7 @@
 //	  {
 //	    "folderScoped": false,                 // does sync stamp a grafana.app/folder annotation?
 //	    "featureFlags": ["playlistsRBAC"],     // extra feature toggles the kind needs (often none)
+//	    "requiresFolder": false,               // can the kind only be created inside a folder?
 //	    "manifest": { "apiVersion": "...", "kind": "...", "metadata": {...}, "spec": {...} }
 //	  }
 package resourcekinds
@@ -28,10 +29,12 @@ import (
 
 	ghmock "github.com/migueleliasweb/go-github-mock/src/mock"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/restmapper"
 
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
 	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/apis"
@@ -48,6 +51,8 @@ type kindSpec struct {
 	FolderScoped bool `json:"folderScoped"`
 	// FeatureFlags are extra feature toggles the kind needs (raw toggle names, e.g. "playlistsRBAC").
 	FeatureFlags []string `json:"featureFlags"`
+	// RequiresFolder reports whether the kind can only be created inside a folder (e.g. alert rules).
+	RequiresFolder bool `json:"requiresFolder"`
 	// Manifest is a sample resource manifest; the harness patches its name and spec.title per instance.
 	Manifest json.RawMessage `json:"manifest"`
 }
@@ -60,7 +65,9 @@ type resourceKind struct {
 	kind         string
 	folderScoped bool
 	featureFlags []string
-	manifest     []byte
+	// requiresFolder kinds are created inside an unmanaged folder when created directly in Grafana
+	requiresFolder bool
+	manifest       []byte
 }
 
 // resourceKinds is the table the generic harness runs, loaded from testdata/kinds/.
@@ -101,13 +108,14 @@ func mustLoadKinds() []resourceKind {
 		}
 
 		kinds = append(kinds, resourceKind{
-			name:         strings.TrimSuffix(e.Name(), ".json"),
-			group:        group,
-			version:      version,
-			kind:         meta.Kind,
-			folderScoped: spec.FolderScoped,
-			featureFlags: spec.FeatureFlags,
-			manifest:     []byte(spec.Manifest),
+			name:           strings.TrimSuffix(e.Name(), ".json"),
+			group:          group,
+			version:        version,
+			kind:           meta.Kind,
+			folderScoped:   spec.FolderScoped,
+			featureFlags:   spec.FeatureFlags,
+			requiresFolder: spec.RequiresFolder,
+			manifest:       []byte(spec.Manifest),
 		})
 	}
 	if len(kinds) == 0 {
@@ -151,6 +159,23 @@ func (rk resourceKind) newResource(t *testing.T, name, title string) *unstructur
 	return obj
 }
 
+// createResource creates a resource directly in Grafana, outside of any repository. Kinds that
+// require a folder are created inside a new unmanaged folder.
+func (rk resourceKind) createResource(t *testing.T, ctx context.Context, helper *common.ProvisioningTestHelper, name, title string) {
+	t.Helper()
+	obj := rk.newResource(t, name, title)
+	if rk.requiresFolder {
+		obj.SetAnnotations(map[string]string{
+			utils.AnnoKeyFolder: helper.CreateUnmanagedFolder(t, ctx, title+" folder", ""),
+		})
+	}
+
+	client := rk.client(t, helper)
+	_, err := client.Resource.Create(ctx, obj, metav1.CreateOptions{})
+	require.NoError(t, err, "should create %s", name)
+	t.Cleanup(func() { _ = client.Resource.Delete(ctx, name, metav1.DeleteOptions{}) })
+}
+
 // gvrCache memoizes the discovery-resolved plural resource per kind.
 var gvrCache sync.Map // schema.GroupVersionKind -> schema.GroupVersionResource
 
@@ -149,6 +149,10 @@ func (m *postgresMacroEngine) Interpolate(query *backend.DataQuery, timeRange ba
 	var macroError error
 
 	sql = m.ReplaceAllStringSubmatchFunc(rExp, sql, func(groups []string) string {
+		// Bind parameter references are bound once the macros are interpolated
+		if groups[1] == "__param" {
+			return groups[0]
+		}
 		// detect if $__timeGroup is supposed to add AS time for pre 5.3 compatibility
 		// if there is a ',' directly after the macro call $__timeGroup is probably used
 		// in the old way. Inside window function ORDER BY $__timeGroup will be followed
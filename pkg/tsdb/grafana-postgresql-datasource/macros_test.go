@@ -35,6 +35,12 @@ func TestMacroEngine(t *testing.T) {
 			require.Equal(t, "select min(time_column AS \"time\")", sql)
 		})
 
+		t.Run("keep bind parameter references", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "select $__time(time_column) WHERE host = $__param(0)")
+			require.NoError(t, err)
+			require.Equal(t, "select time_column AS \"time\" WHERE host = $__param(0)", sql)
+		})
+
 		t.Run("interpolate __timeFilter function", func(t *testing.T) {
 			sql, err := engine.Interpolate(query, timeRange, "WHERE $__timeFilter(time_column)")
 			require.NoError(t, err)
@@ -99,6 +99,7 @@ type QueryJson struct {
 	FillMode     string  `json:"fillMode"`
 	FillValue    float64 `json:"fillValue"`
 	Format       string  `json:"format"`
+	Parameters   []any   `json:"parameters"`
 }
 
 func (e *DataSourceHandler) TransformQueryError(logger log.Logger, err error) error {
@@ -198,14 +199,35 @@ func (e *DataSourceHandler) QueryData(ctx context.Context, req *backend.QueryDat
 	return result, nil
 }
 
-func (e *DataSourceHandler) execQuery(ctx context.Context, query string) ([]*pgconn.Result, error) {
+func (e *DataSourceHandler) execQuery(ctx context.Context, statements []statement) ([]*pgconn.Result, error) {
 	c, err := e.pool.Acquire(ctx)
 	if err != nil {
 		return nil, backend.DownstreamErrorf("failed to acquire connection: %w", err)
 	}
 	defer c.Release()
 
-	mrr := c.Conn().PgConn().Exec(ctx, query)
+	var results []*pgconn.Result
+	for _, s := range statements {
+		if len(s.args) > 0 {
+			result := c.Conn().PgConn().ExecParams(ctx, s.sql, encodeParameters(s.args), nil, nil, nil).Read()
+			if result.Err != nil {
+				return nil, result.Err
+			}
+			results = append(results, result)
+			continue
+		}
+
+		stmtResults, err := execSimpleQuery(ctx, c.Conn().PgConn(), s.sql)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, stmtResults...)
+	}
+	return results, nil
+}
+
+func execSimpleQuery(ctx context.Context, conn *pgconn.PgConn, query string) ([]*pgconn.Result, error) {
+	mrr := conn.Exec(ctx, query)
 	// Close returns the first error that occurred during the MultiResultReader's use. We will log that later.
 	defer mrr.Close() //nolint:errcheck
 	return mrr.ReadAll()
@@ -229,14 +251,15 @@ func (e *DataSourceHandler) executeQuery(queryContext context.Context, query bac
 	// global substitutions
 	interpolatedQuery := Interpolate(query, query.TimeRange, e.dsInfo.JsonData.TimeInterval, queryJSON.RawSql)
 
-	// data source specific substitutions
-	interpolatedQuery, err := e.macroEngine.Interpolate(&query, query.TimeRange, interpolatedQuery)
+	// data source specific substitutions, and bind parameters
+	statements, err := e.interpolateStatements(&query, query.TimeRange, interpolatedQuery, queryJSON.Parameters)
 	if err != nil {
 		e.handleQueryError("interpolation failed", e.TransformQueryError(logger, err), interpolatedQuery, backend.ErrorSourceDownstream, ch, queryResult)
 		return
 	}
+	interpolatedQuery = executedQueryString(statements)
 
-	results, err := e.execQuery(queryContext, interpolatedQuery)
+	results, err := e.execQuery(queryContext, statements)
 	if err != nil {
 		e.handleQueryError("db query error", e.TransformQueryError(logger, err), interpolatedQuery, backend.ErrorSourceDownstream, ch, queryResult)
 		return
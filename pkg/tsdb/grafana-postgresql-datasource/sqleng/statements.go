@@ -0,0 +1,280 @@
+package sqleng
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// paramRegExp matches the references to the bind parameters of a query. The frontend replaces
+// template variables with them when the query binds its variables.
+var paramRegExp = regexp.MustCompile(`\$__param\((\d+)\)`)
+
+// statement is a statement of a query, with the values of its bind parameters
+type statement struct {
+	sql  string
+	args []any
+}
+
+// interpolateStatements interpolates the macros of a query and then binds its parameters. Statements
+// with parameters run with the extended protocol, which runs a single statement, so queries with
+// parameters are split into their statements. Queries without parameters run as they are.
+func (e *DataSourceHandler) interpolateStatements(query *backend.DataQuery, timeRange backend.TimeRange, rawSQL string, params []any) ([]statement, error) {
+	parts := []string{rawSQL}
+	if paramRegExp.MatchString(rawSQL) {
+		if split := splitStatements(rawSQL); len(split) > 1 {
+			parts = split
+		}
+	}
+
+	statements := make([]statement, 0, len(parts))
+	for _, part := range parts {
+		interpolated, err := e.macroEngine.Interpolate(query, timeRange, part)
+		if err != nil {
+			return nil, err
+		}
+		bound, args, err := bindParameters(interpolated, params)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement{sql: bound, args: args})
+	}
+	return statements, nil
+}
+
+// bindParameters replaces the parameter references of a statement with placeholders, and returns
+// the values of the placeholders in order. Placeholders can't be used in strings and quoted
+// identifiers, such as '$host', so the references in them are replaced with their escaped values.
+// References in comments are left as they are.
+func bindParameters(stmt string, params []any) (string, []any, error) {
+	var b strings.Builder
+	var args []any
+	var bindErr error
+	bind := func(code string) {
+		b.WriteString(paramRegExp.ReplaceAllStringFunc(code, func(ref string) string {
+			value, err := parameterValue(ref, params)
+			if err != nil {
+				bindErr = err
+				return ref
+			}
+			args = append(args, value)
+			return "$" + strconv.Itoa(len(args))
+		}))
+	}
+
+	start := 0
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+		var end int
+		var escape func(string) (string, error)
+		switch {
+		case c == '\'':
+			escapes := i > 0 && (stmt[i-1] == 'E' || stmt[i-1] == 'e')
+			end = skipQuoted(stmt, i, c, escapes)
+			escape = func(value string) (string, error) {
+				if escapes {
+					value = strings.ReplaceAll(value, `\`, `\\`)
+				}
+				return strings.ReplaceAll(value, "'", "''"), nil
+			}
+		case c == '"':
+			end = skipQuoted(stmt, i, c, false)
+			escape = func(value string) (string, error) {
+				return strings.ReplaceAll(value, `"`, `""`), nil
+			}
+		case c == '$':
+			tag, ok := dollarQuoteTag(stmt[i:])
+			if !ok {
+				continue
+			}
+			end = skipUntil(stmt, i+len(tag), tag)
+			escape = func(value string) (string, error) {
+				// Dollar-quoted strings have no escapes, so their tag can't be in the value
+				if strings.Contains(value, tag) {
+					return "", backend.DownstreamErrorf("query parameter can't be used in a string quoted with %s", tag)
+				}
+				return value, nil
+			}
+		case c == '-' && strings.HasPrefix(stmt[i:], "--"):
+			end = skipUntil(stmt, i, "\n")
+		case c == '/' && strings.HasPrefix(stmt[i:], "/*"):
+			end = skipUntil(stmt, i+2, "*/")
+		default:
+			continue
+		}
+		end = min(end+1, len(stmt))
+
+		bind(stmt[start:i])
+		if escape != nil {
+			literal, err := quoteParameters(stmt[i:end], params, escape)
+			if err != nil {
+				bindErr = err
+			}
+			b.WriteString(literal)
+		} else {
+			b.WriteString(stmt[i:end])
+		}
+		start = end
+		i = end - 1
+	}
+	bind(stmt[start:])
+	return b.String(), args, bindErr
+}
+
+// parameterValue returns the value of a parameter reference
+func parameterValue(ref string, params []any) (any, error) {
+	idx, err := strconv.Atoi(paramRegExp.FindStringSubmatch(ref)[1])
+	if err != nil || idx >= len(params) {
+		return nil, backend.DownstreamErrorf("query parameter %s is not defined", ref)
+	}
+	return params[idx], nil
+}
+
+// quoteParameters replaces the parameter references of a string or quoted identifier with their
+// values, escaped for it
+func quoteParameters(literal string, params []any, escape func(string) (string, error)) (string, error) {
+	var quoteErr error
+	quoted := paramRegExp.ReplaceAllStringFunc(literal, func(ref string) string {
+		value, err := parameterValue(ref, params)
+		if err == nil {
+			var escaped string
+			if escaped, err = escape(formatParameter(value)); err == nil {
+				return escaped
+			}
+		}
+		quoteErr = err
+		return ref
+	})
+	return quoted, quoteErr
+}
+
+// encodeParameters encodes parameter values in the text format. The server infers their types.
+func encodeParameters(args []any) [][]byte {
+	values := make([][]byte, len(args))
+	for i, arg := range args {
+		if arg != nil {
+			values[i] = []byte(formatParameter(arg))
+		}
+	}
+	return values
+}
+
+// formatParameter returns the text of a parameter value
+func formatParameter(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// executedQueryString returns the statements of a query as they were executed
+func executedQueryString(statements []statement) string {
+	sqls := make([]string, 0, len(statements))
+	for _, s := range statements {
+		sqls = append(sqls, s.sql)
+	}
+	return strings.Join(sqls, ";\n")
+}
+
+// splitStatements splits a query on the semicolons that end its statements, skipping those in
+// strings, quoted identifiers, dollar-quoted strings and comments. Statements with nothing but
+// comments are dropped.
+func splitStatements(query string) []string {
+	var statements []string
+	start := 0
+	hasCode := false
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			// E'...' strings escape quotes with a backslash
+			escapes := i > 0 && (query[i-1] == 'E' || query[i-1] == 'e')
+			i = skipQuoted(query, i, c, escapes)
+			hasCode = true
+		case c == '"':
+			i = skipQuoted(query, i, c, false)
+			hasCode = true
+		case c == '$':
+			if tag, ok := dollarQuoteTag(query[i:]); ok {
+				i = skipUntil(query, i+len(tag), tag)
+			}
+			hasCode = true
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			i = skipUntil(query, i, "\n")
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipUntil(query, i+2, "*/")
+		case c == ';':
+			if hasCode {
+				statements = append(statements, strings.TrimSpace(query[start:i]))
+			}
+			start = i + 1
+			hasCode = false
+		case !isSpace(c):
+			hasCode = true
+		}
+	}
+	if hasCode {
+		statements = append(statements, strings.TrimSpace(query[start:]))
+	}
+	return statements
+}
+
+// dollarQuoteTag returns the tag ($$ or $name$) of the dollar-quoted string at the start of s.
+// Positional parameters such as $1 aren't tags, because tags can't start with a digit.
+func dollarQuoteTag(s string) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1], true
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80:
+		case c >= '0' && c <= '9' && i > 1:
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// skipQuoted returns the index of the quote that closes the string starting at i. Quotes are
+// escaped by doubling them, or with a backslash in escape strings.
+func skipQuoted(query string, i int, quote byte, escapes bool) int {
+	for i++; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if escapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(query)
+}
+
+// skipUntil returns the index of the last byte of the next occurrence of end, or the end of the query
+func skipUntil(query string, i int, end string) int {
+	idx := strings.Index(query[i:], end)
+	if idx == -1 {
+		return len(query)
+	}
+	return i + idx + len(end) - 1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
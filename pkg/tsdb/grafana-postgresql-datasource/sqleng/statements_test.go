@@ -0,0 +1,73 @@
+package sqleng
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+type noopMacroEngine struct{}
+
+func (noopMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+func TestSplitStatements(t *testing.T) {
+	for query, expected := range map[string][]string{
+		"SELECT 1;":                                        {"SELECT 1"},
+		"SET search_path = a; SELECT 1":                    {"SET search_path = a", "SELECT 1"},
+		`SELECT ';', "a;b"; SELECT 2`:                      {`SELECT ';', "a;b"`, "SELECT 2"},
+		`SELECT E'it\'s;', 'C:\'; SELECT 2`:                {`SELECT E'it\'s;', 'C:\'`, "SELECT 2"},
+		"DO $$ BEGIN PERFORM 1; END $$; SELECT 2":          {"DO $$ BEGIN PERFORM 1; END $$", "SELECT 2"},
+		"SELECT $tag$;$$;$tag$, $1; SELECT 2":              {"SELECT $tag$;$$;$tag$, $1", "SELECT 2"},
+		"SELECT 1 -- first;\n; /* second; */ SELECT 2; --": {"SELECT 1 -- first;", "/* second; */ SELECT 2"},
+	} {
+		require.Equal(t, expected, splitStatements(query), query)
+	}
+}
+
+func TestInterpolateStatements(t *testing.T) {
+	e := &DataSourceHandler{macroEngine: noopMacroEngine{}}
+	query := &backend.DataQuery{}
+
+	t.Run("Binds parameters in every statement", func(t *testing.T) {
+		statements, err := e.interpolateStatements(query, query.TimeRange,
+			"CREATE TEMP TABLE hosts AS SELECT $__param(0)::text AS host; SELECT * FROM t WHERE host IN (SELECT host FROM hosts) AND dc IN ($__param(1),$__param(2))",
+			[]any{"web-1", "eu", 2.5})
+		require.NoError(t, err)
+		require.Equal(t, []statement{
+			{sql: "CREATE TEMP TABLE hosts AS SELECT $1::text AS host", args: []any{"web-1"}},
+			{sql: "SELECT * FROM t WHERE host IN (SELECT host FROM hosts) AND dc IN ($1,$2)", args: []any{"eu", 2.5}},
+		}, statements)
+		require.Equal(t, [][]byte{[]byte("eu"), []byte("2.5")}, encodeParameters(statements[1].args))
+	})
+
+	t.Run("Keeps queries without parameters as they are", func(t *testing.T) {
+		statements, err := e.interpolateStatements(query, query.TimeRange, "SET a = 1; SELECT 1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []statement{{sql: "SET a = 1; SELECT 1"}}, statements)
+	})
+
+	t.Run("Escapes parameters in strings and quoted identifiers instead of binding them", func(t *testing.T) {
+		statements, err := e.interpolateStatements(query, query.TimeRange,
+			`SELECT "$__param(2)" FROM t WHERE host = '$__param(0)' AND path LIKE E'%$__param(1)%' AND v > $__param(3) AND d = $x$$__param(0)$x$ -- $__param(0)`,
+			[]any{`it's`, `C:\'; DROP TABLE t; --`, `a"b`, 1.5})
+		require.NoError(t, err)
+		require.Equal(t, []statement{{
+			sql:  `SELECT "a""b" FROM t WHERE host = 'it''s' AND path LIKE E'%C:\\''; DROP TABLE t; --%' AND v > $1 AND d = $x$it's$x$ -- $__param(0)`,
+			args: []any{1.5},
+		}}, statements)
+
+		_, err = e.interpolateStatements(query, query.TimeRange, "SELECT $x$$__param(0)$x$", []any{"$x$; DROP TABLE t"})
+		require.ErrorContains(t, err, "query parameter can't be used in a string quoted with $x$")
+	})
+
+	t.Run("Returns an error for undefined parameters", func(t *testing.T) {
+		_, err := e.interpolateStatements(query, query.TimeRange, "SELECT $__param(1)", []any{"a"})
+		require.ErrorContains(t, err, "query parameter $__param(1) is not defined")
+
+		_, err = e.interpolateStatements(query, query.TimeRange, "SELECT '$__param(1)'", []any{"a"})
+		require.ErrorContains(t, err, "query parameter $__param(1) is not defined")
+	})
+}
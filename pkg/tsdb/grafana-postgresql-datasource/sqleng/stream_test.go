@@ -0,0 +1,98 @@
+package sqleng
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStreamQuery(t *testing.T) {
+	query, interval, err := parseStreamQuery([]byte(`{"rawSql":"SELECT * FROM logs WHERE $__timeFilter(created_at)","format":"table"}`))
+	require.NoError(t, err)
+	require.Equal(t, "table", query.Format)
+	require.Equal(t, defaultPollInterval, interval)
+
+	_, interval, err = parseStreamQuery([]byte(`{"rawSql":"SELECT * FROM logs WHERE $__timeFilter(created_at)","pollInterval":"100ms"}`))
+	require.NoError(t, err)
+	require.Equal(t, minPollInterval, interval)
+
+	_, _, err = parseStreamQuery([]byte(`{"rawSql":"SELECT * FROM logs"}`))
+	require.Error(t, err)
+	require.True(t, backend.IsDownstreamError(err))
+
+	_, _, err = parseStreamQuery([]byte(`{"rawSql":"SELECT * FROM logs WHERE $__timeFilter(created_at)","pollInterval":"often"}`))
+	require.Error(t, err)
+	require.True(t, backend.IsDownstreamError(err))
+}
+
+func TestSubscribeStream(t *testing.T) {
+	handler := &DataSourceHandler{}
+
+	rsp, err := handler.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+		Path: "poll/abc",
+		Data: []byte(`{"rawSql":"SELECT * FROM logs WHERE $__timeFilter(created_at)"}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, rsp.Status)
+
+	rsp, err = handler.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+		Path: "tail/abc",
+		Data: []byte(`{"rawSql":"SELECT * FROM logs WHERE $__timeFilter(created_at)"}`),
+	})
+	require.Error(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusNotFound, rsp.Status)
+
+	rsp, err = handler.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+		Path: "poll/abc",
+		Data: []byte(`{"rawSql":"SELECT * FROM logs"}`),
+	})
+	require.Error(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusNotFound, rsp.Status)
+}
+
+func TestRowPoller(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newFrame := func(seconds []int, messages []string) *data.Frame {
+		times := make([]time.Time, 0, len(seconds))
+		for _, s := range seconds {
+			times = append(times, start.Add(time.Duration(s)*time.Second))
+		}
+		return data.NewFrame("", data.NewField("time", nil, times), data.NewField("message", nil, messages))
+	}
+	messages := func(frame *data.Frame) []any {
+		values := []any{}
+		for i := 0; i < frame.Rows(); i++ {
+			values = append(values, frame.Fields[1].At(i))
+		}
+		return values
+	}
+
+	poller := newRowPoller(start)
+
+	rows, err := poller.newRows(newFrame([]int{0, 1, 1}, []string{"a", "b", "c"}))
+	require.NoError(t, err)
+	require.Equal(t, []any{"a", "b", "c"}, messages(rows))
+	require.Equal(t, start.Add(time.Second), poller.from)
+
+	// the time filter includes the rows at the latest time again
+	rows, err = poller.newRows(newFrame([]int{1, 1, 1, 2}, []string{"b", "c", "d", "e"}))
+	require.NoError(t, err)
+	require.Equal(t, []any{"d", "e"}, messages(rows))
+	require.Equal(t, start.Add(2*time.Second), poller.from)
+
+	rows, err = poller.newRows(newFrame([]int{2}, []string{"e"}))
+	require.NoError(t, err)
+	require.Zero(t, rows.Rows())
+
+	rows, err = poller.newRows(nil)
+	require.NoError(t, err)
+	require.Nil(t, rows)
+
+	_, err = poller.newRows(data.NewFrame("", data.NewField("message", nil, []string{"f"})))
+	require.Error(t, err)
+	require.True(t, backend.IsDownstreamError(err))
+}
@@ -74,6 +74,7 @@ const (
 	TestDataQueryTypeRandomWalkTable              TestDataQueryType = "random_walk_table"
 	TestDataQueryTypeRandomWalkWithError          TestDataQueryType = "random_walk_with_error"
 	TestDataQueryTypeRawFrame                     TestDataQueryType = "raw_frame"
+	TestDataQueryTypeRecorded                     TestDataQueryType = "recorded"
 	TestDataQueryTypeServerError500               TestDataQueryType = "server_error_500"
 	TestDataQueryTypeSteps                        TestDataQueryType = "steps"
 	TestDataQueryTypeSimulation                   TestDataQueryType = "simulation"
@@ -128,6 +129,9 @@ type TestDataQuery struct {
 	QueryDelay            string  `json:"queryDelay,omitempty"`
 	QueryDelayVariability float64 `json:"queryDelayVariability,omitempty"`
 
+	// Recorded scenario: a response recorded by the query service, replayed over the query time range
+	Recording string `json:"recording,omitempty"`
+
 	Nodes     *NodesQuery      `json:"nodes,omitempty"`
 	PulseWave *PulseWaveQuery  `json:"pulseWave,omitempty"`
 	Sim       *SimulationQuery `json:"sim,omitempty"`
@@ -0,0 +1,65 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordedScenario(t *testing.T) {
+	s := &Service{}
+	recordedTo := time.UnixMilli(1609459320000)
+
+	frame := data.NewFrame("requests",
+		data.NewField("time", nil, []time.Time{recordedTo.Add(-time.Minute), recordedTo}),
+		data.NewField("value", nil, []float64{1, 2}),
+	)
+	content, err := json.Marshal(recording{
+		From:   recordedTo.Add(-time.Hour).UnixMilli(),
+		To:     recordedTo.UnixMilli(),
+		Frames: data.Frames{frame},
+	})
+	require.NoError(t, err)
+
+	newRequest := func(content string) *backend.QueryDataRequest {
+		model, err := json.Marshal(map[string]any{"scenarioId": "recorded", "recording": content})
+		require.NoError(t, err)
+		to := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		return &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				TimeRange: backend.TimeRange{From: to.Add(-time.Hour), To: to},
+				JSON:      model,
+			}},
+		}
+	}
+
+	t.Run("Replays the recorded frames over the query time range", func(t *testing.T) {
+		resp, err := s.handleRecordedScenario(context.Background(), newRequest(string(content)))
+		require.NoError(t, err)
+		res := resp.Responses["A"]
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		assert.Equal(t, "A", res.Frames[0].RefID)
+		assert.Equal(t, "requests", res.Frames[0].Name)
+
+		times := res.Frames[0].Fields[0]
+		assert.True(t, time.Date(2024, 5, 1, 11, 59, 0, 0, time.UTC).Equal(times.At(0).(time.Time)))
+		assert.True(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC).Equal(times.At(1).(time.Time)))
+		assert.Equal(t, 2.0, res.Frames[0].Fields[1].At(1))
+	})
+
+	t.Run("Returns a downstream error for invalid recordings", func(t *testing.T) {
+		resp, err := s.handleRecordedScenario(context.Background(), newRequest("{"))
+		require.NoError(t, err)
+		res := resp.Responses["A"]
+		require.ErrorContains(t, res.Error, "failed to parse the recording")
+		assert.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+	})
+}
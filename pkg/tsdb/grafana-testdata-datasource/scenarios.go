@@ -201,6 +201,12 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 		Name: "Raw Frames",
 	})
 
+	s.registerScenario(&Scenario{
+		ID:      kinds.TestDataQueryTypeRecorded,
+		Name:    "Recorded",
+		handler: s.handleRecordedScenario,
+	})
+
 	s.registerScenario(&Scenario{
 		ID:      kinds.TestDataQueryTypeCsvFile,
 		Name:    "CSV File",
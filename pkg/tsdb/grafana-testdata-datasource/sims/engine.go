@@ -66,6 +66,9 @@ func NewSimulationEngine() (*SimulationEngine, error) {
 		newFlightSimInfo,
 		newSinewaveInfo,
 		newTankSimInfo,
+		newQueueSimInfo,
+		newKubernetesSimInfo,
+		newSLOSimInfo,
 	}
 
 	for _, init := range initializers {
@@ -143,11 +146,13 @@ func (s *SimulationEngine) QueryData(ctx context.Context, req *backend.QueryData
 			return nil, fmt.Errorf("invalid simulation: %v", sq)
 		}
 
-		frame := sim.NewFrame(0)
+		var frame *data.Frame
 		if sq.Last {
+			frame = newFrameBetween(sim, q.TimeRange.To, q.TimeRange.To, 0)
 			v := sim.GetValues(q.TimeRange.To)
 			appendFrameRow(frame, v)
 		} else {
+			frame = newFrameBetween(sim, q.TimeRange.From, q.TimeRange.To, 0)
 			timeWalkerMs := q.TimeRange.From.UnixNano() / int64(time.Millisecond)
 			to := q.TimeRange.To.UnixNano() / int64(time.Millisecond)
 			stepMillis := q.Interval.Milliseconds()
@@ -294,11 +299,19 @@ func (s *SimulationEngine) RunStream(ctx context.Context, req *backend.RunStream
 			return ctx.Err()
 
 		case t := <-ticker.C:
+			if series, ok := sim.(seriesSimulation); ok {
+				// the schema is sent again when the series change
+				if next := series.NewFrameBetween(t, t, 1); !sameSeries(frame, next) {
+					frame = next
+					mode = data.IncludeAll
+				}
+			}
 			setFrameRow(frame, 0, sim.GetValues(t))
 			err := sender.SendFrame(frame, mode)
 			if err != nil {
 				return err
 			}
+			mode = data.IncludeDataOnly
 		}
 	}
 }
@@ -1,9 +1,13 @@
 package sims
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 )
 
@@ -62,3 +66,137 @@ func TestCoreSimulationRegistry(t *testing.T) {
 	_, err = sims.getSimFromPath("flight/1/")
 	require.Error(t, err)
 }
+
+func TestIncidentSimulations(t *testing.T) {
+	sims, err := NewSimulationEngine()
+	require.NoError(t, err)
+
+	// queryLast returns the values of a simulation at the given time, by field name and labels
+	queryLast := func(t *testing.T, simType string, at time.Time) map[string]any {
+		t.Helper()
+		sq := &simulationQuery{Last: true}
+		sq.Key = simulationKey{Type: simType, TickHZ: 1}
+		sb, err := json.Marshal(map[string]any{"sim": sq})
+		require.NoError(t, err)
+
+		rsp, err := sims.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				TimeRange: backend.TimeRange{From: at.Add(-time.Minute), To: at},
+				JSON:      sb,
+			}},
+		})
+		require.NoError(t, err)
+		frame := rsp.Responses["A"].Frames[0]
+		values := map[string]any{}
+		for _, field := range frame.Fields {
+			require.Equal(t, 1, field.Len())
+			values[valueKey(field.Name, field.Labels)], _ = field.ConcreteAt(0)
+		}
+		return values
+	}
+
+	t.Run("queue", func(t *testing.T) {
+		// incidents start every 30 minutes and last 5 minutes
+		start := time.Unix(1800*1000, 0)
+		labels := data.Labels{"queue": "queue-1"}
+
+		before := queryLast(t, "queue", start.Add(-time.Second))
+		during := queryLast(t, "queue", start.Add(4*time.Minute))
+		after := queryLast(t, "queue", start.Add(20*time.Minute))
+
+		require.Equal(t, false, before["incident"])
+		require.Equal(t, true, during["incident"])
+		require.Greater(t, during[valueKey("depth", labels)], 1000.0)
+		require.Less(t, before[valueKey("depth", labels)], 100.0)
+		require.Less(t, after[valueKey("depth", labels)], 100.0)
+		require.Greater(t, during[valueKey("lag", labels)], before[valueKey("lag", labels)])
+
+		require.Equal(t, during, queryLast(t, "queue", start.Add(4*time.Minute)))
+	})
+
+	t.Run("kubernetes", func(t *testing.T) {
+		sim := newTestKubernetesSim(t)
+
+		// the 4th incident crash loops the 5th pod every minute
+		at := time.Unix(2700*4+250, 0)
+		generation, _ := sim.podAt(4, float64(at.Unix()))
+		labels := sim.podLabels(4, generation)
+		generation, _ = sim.podAt(0, float64(at.Unix()))
+		other := sim.podLabels(0, generation)
+
+		values := queryLast(t, "kubernetes", at)
+		require.Equal(t, true, values["incident"])
+		require.Equal(t, 4.0, values[valueKey("restarts", labels)])
+		require.Equal(t, 0.0, values[valueKey("ready", labels)])
+		require.Equal(t, 4.0, values["readyPods"])
+		require.Equal(t, 0.0, values[valueKey("restarts", other)])
+	})
+
+	t.Run("slo", func(t *testing.T) {
+		// half way through an incident of 5 minutes
+		at := time.Unix(6*3600*100+150, 0)
+		checkout := data.Labels{"route": "/api/checkout"}
+		search := data.Labels{"route": "/api/search"}
+
+		values := queryLast(t, "slo", at)
+		require.Equal(t, true, values["incident"])
+		require.InDelta(t, 0.05, values[valueKey("errorRatio", checkout)], 1e-9)
+		require.InDelta(t, 25.1, values[valueKey("burnRate5m", checkout)], 1e-6)
+		require.InDelta(t, 0.2, values[valueKey("burnRate5m", search)], 1e-6)
+		require.Less(t, values[valueKey("errorBudgetRemaining", checkout)], values[valueKey("errorBudgetRemaining", search)])
+	})
+}
+
+func newTestKubernetesSim(t *testing.T) *kubernetesSim {
+	t.Helper()
+	sim, err := newKubernetesSimInfo().create(simulationState{Key: simulationKey{Type: "kubernetes", TickHZ: 1}})
+	require.NoError(t, err)
+	return sim.(*kubernetesSim)
+}
+
+func TestKubernetesPodChurn(t *testing.T) {
+	sim := newTestKubernetesSim(t)
+
+	// the first pod is replaced every hour
+	replaced := time.Unix(3600*1000, 0)
+	oldPod := sim.podLabels(0, 999)
+	newPod := sim.podLabels(0, 1000)
+	require.Regexp(t, "^api-[bcdfghjklmnpqrstvwxz2456789]{5}$", oldPod["pod"])
+	require.NotEqual(t, oldPod["pod"], newPod["pod"])
+	require.Equal(t, oldPod, sim.podLabels(0, 999))
+
+	before := sim.GetValues(replaced.Add(-time.Second))
+	after := sim.GetValues(replaced.Add(time.Second))
+	require.Equal(t, 3599.0, before[valueKey("age", oldPod)])
+	require.NotContains(t, before, valueKey("age", newPod))
+	require.Equal(t, 1.0, after[valueKey("age", newPod)])
+	require.NotContains(t, after, valueKey("age", oldPod))
+
+	// both pods have series in a frame of the time range, with values while they run
+	frame := sim.NewFrameBetween(replaced.Add(-time.Minute), replaced.Add(time.Minute), 0)
+	require.Len(t, frame.Fields, 1+6*5+2)
+	appendFrameRow(frame, before)
+	appendFrameRow(frame, after)
+	for _, field := range frame.Fields {
+		if field.Name != "age" {
+			continue
+		}
+		_, runsBefore := field.ConcreteAt(0)
+		_, runsAfter := field.ConcreteAt(1)
+		switch field.Labels["pod"] {
+		case oldPod["pod"]:
+			require.Equal(t, []bool{true, false}, []bool{runsBefore, runsAfter})
+		case newPod["pod"]:
+			require.Equal(t, []bool{false, true}, []bool{runsBefore, runsAfter})
+		default:
+			require.Equal(t, []bool{true, true}, []bool{runsBefore, runsAfter})
+		}
+	}
+
+	t.Run("without churn pods keep their name", func(t *testing.T) {
+		require.NoError(t, sim.SetConfig(map[string]any{"churn": false}))
+		require.Equal(t, data.Labels{"pod": "api-0", "deployment": "api"}, sim.podLabels(0, 1000))
+		require.Len(t, sim.NewFrameBetween(replaced.Add(-time.Minute), replaced.Add(time.Minute), 0).Fields, 1+5*5+2)
+	})
+}
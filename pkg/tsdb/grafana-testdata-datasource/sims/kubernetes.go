@@ -0,0 +1,235 @@
+package sims
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// kubernetesSim is a deployment whose pods are replaced one after the other. With churn, the
+// replacement of a pod gets a new name, so the series of the pods come and go. During incidents
+// one of the pods runs out of memory and crash loops. The values only depend on the time, so
+// every query returns the same data.
+type kubernetesSim struct {
+	key simulationKey
+	cfg kubernetesConfig
+}
+
+var (
+	_ Simulation       = (*kubernetesSim)(nil)
+	_ seriesSimulation = (*kubernetesSim)(nil)
+)
+
+// maxPodGenerations limits how many replacements of each pod a frame has series for
+const maxPodGenerations = 24
+
+// podNameAlphabet is the alphabet of the names kubernetes generates
+const podNameAlphabet = "bcdfghjklmnpqrstvwxz2456789"
+
+type kubernetesConfig struct {
+	Deployment       string  `json:"deployment"`
+	Pods             int     `json:"pods"`
+	PodLifetime      float64 `json:"podLifetime"`      // seconds before a pod is replaced
+	StartupTime      float64 `json:"startupTime"`      // seconds before a started pod is ready
+	CPURequest       float64 `json:"cpuRequest"`       // cores
+	MemoryLimit      float64 `json:"memoryLimit"`      // bytes
+	IncidentEvery    float64 `json:"incidentEvery"`    // seconds, 0 disables incidents
+	IncidentDuration float64 `json:"incidentDuration"` // seconds
+	CrashLoopPeriod  float64 `json:"crashLoopPeriod"`  // seconds between the crashes of a crash looping pod
+	Churn            bool    `json:"churn"`            // replaced pods get a new name
+}
+
+func (s *kubernetesSim) GetState() simulationState {
+	return simulationState{
+		Key:    s.key,
+		Config: s.cfg,
+	}
+}
+
+func (s *kubernetesSim) SetConfig(vals map[string]any) error {
+	return updateConfigObjectFromJSON(&s.cfg, vals)
+}
+
+// podAt returns the generation of the pod running at ts (unix seconds) in the i-th slot of the
+// deployment, counted from the unix epoch, and its age in seconds. Pods are replaced in turn, so
+// they don't all start at the same time.
+func (s *kubernetesSim) podAt(i int, ts float64) (int64, float64) {
+	lifetime := math.Max(s.cfg.PodLifetime, 1)
+	offset := float64(i) * lifetime / float64(clampCount(s.cfg.Pods, 1, 50))
+	generation := math.Floor((ts - offset) / lifetime)
+	return int64(generation), ts - offset - generation*lifetime
+}
+
+// podLabels returns the labels of a pod. Without churn, every generation of the pods of a slot
+// has the same name.
+func (s *kubernetesSim) podLabels(i int, generation int64) data.Labels {
+	name := fmt.Sprintf("%s-%d", s.cfg.Deployment, i)
+	if s.cfg.Churn {
+		x := mix(uint64(generation)*0x9e3779b97f4a7c15 + uint64(i)*0xbf58476d1ce4e5b9)
+		suffix := make([]byte, 5)
+		for j := range suffix {
+			suffix[j] = podNameAlphabet[x%uint64(len(podNameAlphabet))]
+			x /= uint64(len(podNameAlphabet))
+		}
+		name = fmt.Sprintf("%s-%s", s.cfg.Deployment, suffix)
+	}
+	return data.Labels{"pod": name, "deployment": s.cfg.Deployment}
+}
+
+func (s *kubernetesSim) NewFrame(size int) *data.Frame {
+	now := time.Now()
+	return s.NewFrameBetween(now, now, size)
+}
+
+// NewFrameBetween returns a frame with the series of the pods running between from and to. With
+// churn, only the latest generations of each pod have series.
+func (s *kubernetesSim) NewFrameBetween(from, to time.Time, size int) *data.Frame {
+	newField := newSeriesField
+	if s.cfg.Churn {
+		// the pods only have values while they run
+		newField = newNullableSeriesField
+	}
+
+	frame := data.NewFrame("", data.NewField("time", nil, make([]time.Time, size)))
+	for i := 0; i < clampCount(s.cfg.Pods, 1, 50); i++ {
+		first, _ := s.podAt(i, float64(from.UnixMilli())/1000)
+		last, _ := s.podAt(i, float64(to.UnixMilli())/1000)
+		if !s.cfg.Churn {
+			first = last
+		}
+		for generation := max(first, last-maxPodGenerations+1); generation <= last; generation++ {
+			labels := s.podLabels(i, generation)
+			frame.Fields = append(frame.Fields,
+				newField("cpu", labels, "short", size),
+				newField("memory", labels, "bytes", size),
+				newField("restarts", labels, "short", size),
+				newField("ready", labels, "bool", size),
+				newField("age", labels, "s", size),
+			)
+		}
+	}
+	frame.Fields = append(frame.Fields,
+		newSeriesField("readyPods", nil, "short", size),
+		data.NewField("incident", nil, make([]bool, size)),
+	)
+	return frame
+}
+
+func (s *kubernetesSim) GetValues(t time.Time) map[string]any {
+	cfg := s.cfg
+	pods := clampCount(cfg.Pods, 1, 50)
+	inc := incidentAt(t, cfg.IncidentEvery, cfg.IncidentDuration)
+	ts := float64(t.UnixMilli()) / 1000
+	lifetime := math.Max(cfg.PodLifetime, 1)
+	crashPeriod := math.Max(cfg.CrashLoopPeriod, 1)
+
+	values := map[string]any{
+		"time":     t,
+		"incident": inc.active,
+	}
+	readyPods := 0
+	for i := 0; i < pods; i++ {
+		generation, age := s.podAt(i, ts)
+
+		cpu := cfg.CPURequest * (0.6 + 0.2*math.Sin(ts/600+float64(i)) + 0.1*noiseAt(t, i))
+		memory := cfg.MemoryLimit * (0.4 + 0.2*age/lifetime + 0.02*noiseAt(t, i+pods))
+		ready := age >= cfg.StartupTime
+		if !ready {
+			cpu *= 2 // starting up
+		}
+
+		// the pod of the incident crashes when it reaches its memory limit, and counts the
+		// crashes of the incident since it started
+		restarts := 0.0
+		if cfg.IncidentEvery > 0 && inc.cycle%int64(pods) == int64(i) {
+			from := math.Max(inc.start, ts-age)
+			to := math.Min(ts, inc.start+cfg.IncidentDuration)
+			if to > from {
+				restarts = math.Floor((to-inc.start)/crashPeriod) - math.Floor((from-inc.start)/crashPeriod)
+			}
+			if inc.active {
+				sinceCrash := math.Mod(inc.elapsed, crashPeriod)
+				memory = cfg.MemoryLimit * (0.5 + 0.5*sinceCrash/crashPeriod)
+				if inc.elapsed >= crashPeriod && sinceCrash < cfg.StartupTime {
+					ready = false
+				}
+			}
+		}
+
+		readyValue := 0.0
+		if ready {
+			readyValue = 1
+			readyPods++
+		}
+
+		labels := s.podLabels(i, generation)
+		values[valueKey("cpu", labels)] = math.Max(cpu, 0)
+		values[valueKey("memory", labels)] = math.Round(memory)
+		values[valueKey("restarts", labels)] = restarts
+		values[valueKey("ready", labels)] = readyValue
+		values[valueKey("age", labels)] = math.Floor(age)
+	}
+	values["readyPods"] = float64(readyPods)
+	return values
+}
+
+func (s *kubernetesSim) Close() error {
+	return nil
+}
+
+func newKubernetesSimInfo() simulationInfo {
+	kc := kubernetesConfig{
+		Deployment:       "api",
+		Pods:             5,
+		PodLifetime:      3600,
+		StartupTime:      20,
+		CPURequest:       0.5,
+		MemoryLimit:      512 * 1024 * 1024,
+		IncidentEvery:    2700,
+		IncidentDuration: 600,
+		CrashLoopPeriod:  60,
+		Churn:            true,
+	}
+
+	df := data.NewFrame("")
+	df.Fields = append(df.Fields, data.NewField("deployment", nil, []string{kc.Deployment}))
+	df.Fields = append(df.Fields, data.NewField("pods", nil, []int64{int64(kc.Pods)}))
+	df.Fields = append(df.Fields, data.NewField("podLifetime", nil, []float64{kc.PodLifetime}).SetConfig(&data.FieldConfig{
+		Unit: "s",
+	}))
+	df.Fields = append(df.Fields, data.NewField("startupTime", nil, []float64{kc.StartupTime}).SetConfig(&data.FieldConfig{
+		Unit: "s",
+	}))
+	df.Fields = append(df.Fields, data.NewField("cpuRequest", nil, []float64{kc.CPURequest}))
+	df.Fields = append(df.Fields, data.NewField("memoryLimit", nil, []float64{kc.MemoryLimit}).SetConfig(&data.FieldConfig{
+		Unit: "bytes",
+	}))
+	df.Fields = append(df.Fields, data.NewField("incidentEvery", nil, []float64{kc.IncidentEvery}).SetConfig(&data.FieldConfig{
+		Unit: "s",
+	}))
+	df.Fields = append(df.Fields, data.NewField("incidentDuration", nil, []float64{kc.IncidentDuration}).SetConfig(&data.FieldConfig{
+		Unit: "s",
+	}))
+	df.Fields = append(df.Fields, data.NewField("crashLoopPeriod", nil, []float64{kc.CrashLoopPeriod}).SetConfig(&data.FieldConfig{
+		Unit: "s",
+	}))
+	df.Fields = append(df.Fields, data.NewField("churn", nil, []bool{kc.Churn}))
+
+	return simulationInfo{
+		Type:         "kubernetes",
+		Name:         "Kubernetes",
+		Description:  "Deployment with pod churn and crash looping pods",
+		ConfigFields: df,
+		OnlyForward:  false,
+		create: func(cfg simulationState) (Simulation, error) {
+			s := &kubernetesSim{
+				key: cfg.Key,
+				cfg: kc,
+			}
+			err := updateConfigObjectFromJSON(&s.cfg, cfg.Config) // override any fields
+			return s, err
+		},
+	}
+}
@@ -0,0 +1,164 @@
+package sims
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// queueSim is a set of message queues sharing a pool of consumers. During incidents the
+// consumers slow down and the queues build a backlog, which drains once the incident is over.
+// The values only depend on the time, so every query returns the same data.
+type queueSim struct {
+	key simulationKey
+	cfg queueConfig
+}
+
+var (
+	_ Simulation = (*queueSim)(nil)
+)
+
+type queueConfig struct {
+	Queues           int     `json:"queues"`           // number of queues
+	ArrivalRate      float64 `json:"arrivalRate"`      // messages/second of the first queue, the next ones get less
+	Consumers        int     `json:"consumers"`        // consumers of each queue
+	ConsumerRate     float64 `json:"consumerRate"`     // messages/second of each consumer
+	IncidentEvery    float64 `json:"incidentEvery"`    // seconds, 0 disables incidents
+	IncidentDuration float64 `json:"incidentDuration"` // seconds
+	IncidentSlowdown float64 `json:"incidentSlowdown"` // 0-1, capacity lost during incidents
+}
+
+func (s *queueSim) GetState() simulationState {
+	return simulationState{
+		Key:    s.key,
+		Config: s.cfg,
+	}
+}
+
+func (s *queueSim) SetConfig(vals map[string]any) error {
+	return updateConfigObjectFromJSON(&s.cfg, vals)
+}
+
+func (s *queueSim) queueLabels(i int) data.Labels {
+	return data.Labels{"queue": fmt.Sprintf("queue-%d", i+1)}
+}
+
+func (s *queueSim) NewFrame(size int) *data.Frame {
+	frame := data.NewFrame("", data.NewField("time", nil, make([]time.Time, size)))
+	for i := 0; i < clampCount(s.cfg.Queues, 1, 20); i++ {
+		labels := s.queueLabels(i)
+		frame.Fields = append(frame.Fields,
+			newSeriesField("depth", labels, "short", size),
+			newSeriesField("arrivalRate", labels, "reqps", size),
+			newSeriesField("processedRate", labels, "reqps", size),
+			newSeriesField("lag", labels, "s", size),
+		)
+	}
+	frame.Fields = append(frame.Fields, data.NewField("incident", nil, make([]bool, size)))
+	return frame
+}
+
+func (s *queueSim) GetValues(t time.Time) map[string]any {
+	cfg := s.cfg
+	inc := incidentAt(t, cfg.IncidentEvery, cfg.IncidentDuration)
+	capacity := float64(cfg.Consumers) * cfg.ConsumerRate
+	degraded := capacity * (1 - math.Min(math.Max(cfg.IncidentSlowdown, 0), 1))
+	hour := float64(t.Unix()%3600) / 3600 * 2 * math.Pi
+
+	values := map[string]any{
+		"time":     t,
+		"incident": inc.active,
+	}
+	for i := 0; i < clampCount(cfg.Queues, 1, 20); i++ {
+		nominal := cfg.ArrivalRate / float64(i+1)
+		arrival := math.Max(nominal*(1+0.2*math.Sin(hour+float64(i))+0.05*noiseAt(t, i)), 0)
+
+		// the backlog grows during the incident, and drains at the spare capacity after it
+		backlog := 0.0
+		if grow := nominal - degraded; grow > 0 && cfg.IncidentEvery > 0 {
+			built := grow * math.Min(inc.elapsed, cfg.IncidentDuration)
+			if inc.active {
+				backlog = built
+			} else if drain := capacity - nominal; drain > 0 {
+				backlog = math.Max(built-drain*(inc.elapsed-cfg.IncidentDuration), 0)
+			} else {
+				backlog = built
+			}
+		}
+
+		processed := arrival
+		switch {
+		case inc.active:
+			processed = math.Min(arrival, degraded)
+		case backlog > 0:
+			processed = capacity
+		}
+
+		// messages in flight are processed within a second
+		depth := math.Round(backlog + arrival)
+		lag := 0.0
+		if processed > 0 {
+			lag = depth / processed
+		}
+
+		labels := s.queueLabels(i)
+		values[valueKey("depth", labels)] = depth
+		values[valueKey("arrivalRate", labels)] = arrival
+		values[valueKey("processedRate", labels)] = processed
+		values[valueKey("lag", labels)] = lag
+	}
+	return values
+}
+
+func (s *queueSim) Close() error {
+	return nil
+}
+
+func newQueueSimInfo() simulationInfo {
+	qc := queueConfig{
+		Queues:           3,
+		ArrivalRate:      50,
+		Consumers:        2,
+		ConsumerRate:     40,
+		IncidentEvery:    1800,
+		IncidentDuration: 300,
+		IncidentSlowdown: 0.9,
+	}
+
+	df := data.NewFrame("")
+	df.Fields = append(df.Fields, data.NewField("queues", nil, []int64{int64(qc.Queues)}))
+	df.Fields = append(df.Fields, data.NewField("arrivalRate", nil, []float64{qc.ArrivalRate}).SetConfig(&data.FieldConfig{
+		Unit: "reqps",
+	}))
+	df.Fields = append(df.Fields, data.NewField("consumers", nil, []int64{int64(qc.Consumers)}))
+	df.Fields = append(df.Fields, data.NewField("consumerRate", nil, []float64{qc.ConsumerRate}).SetConfig(&data.FieldConfig{
+		Unit: "reqps",
+	}))
+	df.Fields = append(df.Fields, data.NewField("incidentEvery", nil, []float64{qc.IncidentEvery}).SetConfig(&data.FieldConfig{
+		Unit: "s",
+	}))
+	df.Fields = append(df.Fields, data.NewField("incidentDuration", nil, []float64{qc.IncidentDuration}).SetConfig(&data.FieldConfig{
+		Unit: "s",
+	}))
+	df.Fields = append(df.Fields, data.NewField("incidentSlowdown", nil, []float64{qc.IncidentSlowdown}).SetConfig(&data.FieldConfig{
+		Unit: "percentunit",
+	}))
+
+	return simulationInfo{
+		Type:         "queue",
+		Name:         "Queue",
+		Description:  "Message queues that build a backlog when their consumers slow down",
+		ConfigFields: df,
+		OnlyForward:  false,
+		create: func(cfg simulationState) (Simulation, error) {
+			s := &queueSim{
+				key: cfg.Key,
+				cfg: qc,
+			}
+			err := updateConfigObjectFromJSON(&s.cfg, cfg.Config) // override any fields
+			return s, err
+		},
+	}
+}
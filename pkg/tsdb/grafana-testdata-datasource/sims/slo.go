@@ -0,0 +1,171 @@
+package sims
+
+import (
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// sloRoutes are the routes of the simulated HTTP service. Incidents only affect the first one.
+var sloRoutes = []string{"/api/checkout", "/api/search", "/api/users", "/api/orders", "/api/payments"}
+
+// sloSim is an HTTP service with an availability SLO. During incidents the first route returns
+// more errors and burns the error budget. The values only depend on the time, so every query
+// returns the same data.
+type sloSim struct {
+	key simulationKey
+	cfg sloConfig
+}
+
+var (
+	_ Simulation = (*sloSim)(nil)
+)
+
+type sloConfig struct {
+	Routes            int     `json:"routes"`            // number of routes
+	RequestRate       float64 `json:"requestRate"`       // requests/second of the first route, the next ones get less
+	ErrorRate         float64 `json:"errorRate"`         // 0-1, ratio of errors outside incidents
+	IncidentErrorRate float64 `json:"incidentErrorRate"` // 0-1, ratio of errors during incidents
+	Objective         float64 `json:"objective"`         // 0-1, ratio of successful requests
+	Window            float64 `json:"window"`            // seconds of the SLO window
+	IncidentEvery     float64 `json:"incidentEvery"`     // seconds, 0 disables incidents
+	IncidentDuration  float64 `json:"incidentDuration"`  // seconds
+}
+
+func (s *sloSim) GetState() simulationState {
+	return simulationState{
+		Key:    s.key,
+		Config: s.cfg,
+	}
+}
+
+func (s *sloSim) SetConfig(vals map[string]any) error {
+	return updateConfigObjectFromJSON(&s.cfg, vals)
+}
+
+func (s *sloSim) NewFrame(size int) *data.Frame {
+	frame := data.NewFrame("", data.NewField("time", nil, make([]time.Time, size)))
+	for i := 0; i < clampCount(s.cfg.Routes, 1, len(sloRoutes)); i++ {
+		labels := data.Labels{"route": sloRoutes[i]}
+		frame.Fields = append(frame.Fields,
+			newSeriesField("requests", labels, "reqps", size),
+			newSeriesField("errors", labels, "reqps", size),
+			newSeriesField("errorRatio", labels, "percentunit", size),
+			newSeriesField("burnRate5m", labels, "short", size),
+			newSeriesField("burnRate1h", labels, "short", size),
+			newSeriesField("errorBudgetRemaining", labels, "percentunit", size),
+		)
+	}
+	frame.Fields = append(frame.Fields, data.NewField("incident", nil, make([]bool, size)))
+	return frame
+}
+
+// errorRatio returns the average error ratio of the first route over the last `window` seconds
+func (s *sloSim) errorRatio(ts, window float64) float64 {
+	cfg := s.cfg
+	if window <= 0 {
+		return cfg.ErrorRate
+	}
+	incident := incidentSeconds(ts-window, ts, cfg.IncidentEvery, cfg.IncidentDuration) / window
+	return cfg.ErrorRate + (cfg.IncidentErrorRate-cfg.ErrorRate)*incident
+}
+
+func (s *sloSim) GetValues(t time.Time) map[string]any {
+	cfg := s.cfg
+	inc := incidentAt(t, cfg.IncidentEvery, cfg.IncidentDuration)
+	ts := float64(t.UnixMilli()) / 1000
+	day := float64(t.Unix()%86400) / 86400 * 2 * math.Pi
+	budget := 1 - cfg.Objective
+
+	values := map[string]any{
+		"time":     t,
+		"incident": inc.active,
+	}
+	for i := 0; i < clampCount(cfg.Routes, 1, len(sloRoutes)); i++ {
+		requests := math.Max(cfg.RequestRate/float64(i+1)*(1+0.3*math.Sin(day-math.Pi/2)+0.05*noiseAt(t, i)), 0)
+
+		ratio, ratio5m, ratio1h, ratioWindow := cfg.ErrorRate, cfg.ErrorRate, cfg.ErrorRate, cfg.ErrorRate
+		if i == 0 {
+			if inc.active {
+				ratio = cfg.IncidentErrorRate
+			}
+			ratio5m = s.errorRatio(ts, 300)
+			ratio1h = s.errorRatio(ts, 3600)
+			ratioWindow = s.errorRatio(ts, cfg.Window)
+		}
+
+		burnRate5m, burnRate1h, remaining := 0.0, 0.0, 1.0
+		if budget > 0 {
+			burnRate5m = ratio5m / budget
+			burnRate1h = ratio1h / budget
+			remaining = 1 - ratioWindow/budget
+		}
+
+		labels := data.Labels{"route": sloRoutes[i]}
+		values[valueKey("requests", labels)] = requests
+		values[valueKey("errors", labels)] = requests * ratio
+		values[valueKey("errorRatio", labels)] = ratio
+		values[valueKey("burnRate5m", labels)] = burnRate5m
+		values[valueKey("burnRate1h", labels)] = burnRate1h
+		values[valueKey("errorBudgetRemaining", labels)] = remaining
+	}
+	return values
+}
+
+func (s *sloSim) Close() error {
+	return nil
+}
+
+func newSLOSimInfo() simulationInfo {
+	sc := sloConfig{
+		Routes:            3,
+		RequestRate:       100,
+		ErrorRate:         0.0002,
+		IncidentErrorRate: 0.05,
+		Objective:         0.999,
+		Window:            28 * 24 * 3600,
+		IncidentEvery:     6 * 3600,
+		IncidentDuration:  300,
+	}
+
+	df := data.NewFrame("")
+	df.Fields = append(df.Fields, data.NewField("routes", nil, []int64{int64(sc.Routes)}))
+	df.Fields = append(df.Fields, data.NewField("requestRate", nil, []float64{sc.RequestRate}).SetConfig(&data.FieldConfig{
+		Unit: "reqps",
+	}))
+	df.Fields = append(df.Fields, data.NewField("errorRate", nil, []float64{sc.ErrorRate}).SetConfig(&data.FieldConfig{
+		Unit: "percentunit",
+	}))
+	df.Fields = append(df.Fields, data.NewField("incidentErrorRate", nil, []float64{sc.IncidentErrorRate}).SetConfig(&data.FieldConfig{
+		Unit: "percentunit",
+	}))
+	df.Fields = append(df.Fields, data.NewField("objective", nil, []float64{sc.Objective}).SetConfig(&data.FieldConfig{
+		Unit: "percentunit",
+	}))
+	df.Fields = append(df.Fields, data.NewField("window", nil, []float64{sc.Window}).SetConfig(&data.FieldConfig{
+		Unit: "s",
+	}))
+	df.Fields = append(df.Fields, data.NewField("incidentEvery", nil, []float64{sc.IncidentEvery}).SetConfig(&data.FieldConfig{
+		Unit: "s",
+	}))
+	df.Fields = append(df.Fields, data.NewField("incidentDuration", nil, []float64{sc.IncidentDuration}).SetConfig(&data.FieldConfig{
+		Unit: "s",
+	}))
+
+	return simulationInfo{
+		Type:         "slo",
+		Name:         "SLO",
+		Description:  "HTTP service burning its error budget during incidents",
+		ConfigFields: df,
+		OnlyForward:  false,
+		create: func(cfg simulationState) (Simulation, error) {
+			s := &sloSim{
+				key: cfg.Key,
+				cfg: sc,
+			}
+			err := updateConfigObjectFromJSON(&s.cfg, cfg.Config) // override any fields
+			return s, err
+		},
+	}
+}
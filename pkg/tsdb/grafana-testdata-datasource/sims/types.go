@@ -49,3 +49,17 @@ type Simulation interface {
 	NewFrame(size int) *data.Frame
 	GetValues(t time.Time) map[string]any
 }
+
+// seriesSimulation is a simulation whose labelled series change over time, so the fields of
+// its frames depend on the time range
+type seriesSimulation interface {
+	NewFrameBetween(from, to time.Time, size int) *data.Frame
+}
+
+// newFrameBetween returns a frame with the fields of every series of the simulation between from and to
+func newFrameBetween(sim Simulation, from, to time.Time, size int) *data.Frame {
+	if s, ok := sim.(seriesSimulation); ok {
+		return s.NewFrameBetween(from, to, size)
+	}
+	return sim.NewFrame(size)
+}
@@ -2,7 +2,9 @@ package sims
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -47,24 +49,45 @@ func asStringMap(input any) (map[string]any, error) {
 	return v, err
 }
 
+// valueKey is the key of a field in the values of a simulation. Fields of labelled series share
+// their name, so the key of those includes the labels.
+func valueKey(name string, labels data.Labels) string {
+	if len(labels) == 0 {
+		return name
+	}
+	return name + "{" + labels.String() + "}"
+}
+
 func setFrameRow(frame *data.Frame, idx int, values map[string]any) {
 	for _, field := range frame.Fields {
-		v, ok := values[field.Name]
+		v, ok := values[valueKey(field.Name, field.Labels)]
 		if ok {
-			field.Set(idx, v)
+			field.SetConcrete(idx, v)
 		}
 	}
 }
 
 func appendFrameRow(frame *data.Frame, values map[string]any) {
 	for _, field := range frame.Fields {
-		v, ok := values[field.Name]
+		v, ok := values[valueKey(field.Name, field.Labels)]
+		field.Extend(1) // fill with nullable value
 		if ok {
-			field.Append(v)
-		} else {
-			field.Extend(1) // fill with nullable value
+			field.SetConcrete(field.Len()-1, v)
+		}
+	}
+}
+
+// sameSeries returns true when both frames have the same fields
+func sameSeries(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i, field := range a.Fields {
+		if valueKey(field.Name, field.Labels) != valueKey(b.Fields[i].Name, b.Fields[i].Labels) {
+			return false
 		}
 	}
+	return true
 }
 
 func getBodyFromRequest(req *http.Request) (map[string]any, error) {
@@ -74,3 +97,83 @@ func getBodyFromRequest(req *http.Request) (map[string]any, error) {
 	// TODO? create the map based on form parameters not JSON post
 	return result, err
 }
+
+// noiseAt returns a value between -1 and 1 that only depends on the time and the series, so
+// simulations return the same values every time they are queried
+func noiseAt(t time.Time, series int) float64 {
+	x := mix(uint64(t.UnixMilli())*0x9e3779b97f4a7c15 + uint64(series)*0xbf58476d1ce4e5b9)
+	return float64(x>>11)/float64(1<<53)*2 - 1
+}
+
+// mix scrambles the bits of x (the splitmix64 finalizer)
+func mix(x uint64) uint64 {
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// incident is the state of a simulated incident. Incidents start every `every` seconds, counted
+// from the unix epoch, and last `duration` seconds.
+type incident struct {
+	active  bool
+	cycle   int64   // index of the incident, counted from the unix epoch
+	start   float64 // unix seconds
+	elapsed float64 // seconds since the start
+}
+
+func incidentAt(t time.Time, every, duration float64) incident {
+	if every <= 0 || duration <= 0 {
+		return incident{}
+	}
+	ts := float64(t.UnixMilli()) / 1000
+	cycle := math.Floor(ts / every)
+	start := cycle * every
+	return incident{
+		active:  ts-start < duration,
+		cycle:   int64(cycle),
+		start:   start,
+		elapsed: ts - start,
+	}
+}
+
+// incidentSeconds returns how many seconds of the time range from..to (unix seconds) were in incidents
+func incidentSeconds(from, to, every, duration float64) float64 {
+	if every <= 0 || duration <= 0 || to <= from {
+		return 0
+	}
+	duration = math.Min(duration, every)
+	total := func(ts float64) float64 {
+		cycles := math.Floor(ts / every)
+		return cycles*duration + math.Min(ts-cycles*every, duration)
+	}
+	return total(to) - total(from)
+}
+
+// newSeriesField returns a float64 field of a labelled series
+func newSeriesField(name string, labels data.Labels, unit string, size int) *data.Field {
+	field := data.NewField(name, labels, make([]float64, size))
+	if unit != "" {
+		field.Config = &data.FieldConfig{Unit: unit}
+	}
+	return field
+}
+
+// newNullableSeriesField returns a nullable float64 field of a labelled series, for series that
+// only have values during part of the time range
+func newNullableSeriesField(name string, labels data.Labels, unit string, size int) *data.Field {
+	field := data.NewField(name, labels, make([]*float64, size))
+	if unit != "" {
+		field.Config = &data.FieldConfig{Unit: unit}
+	}
+	return field
+}
+
+func clampCount(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
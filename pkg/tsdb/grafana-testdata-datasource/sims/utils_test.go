@@ -3,6 +3,7 @@ package sims
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -28,3 +29,27 @@ func TestSimulationUtils(t *testing.T) {
 		"number": 5
 	}`, string(cfg))
 }
+
+func TestIncidents(t *testing.T) {
+	// incidents of 60s every 600s
+	inc := incidentAt(time.Unix(1200+30, 0), 600, 60)
+	require.True(t, inc.active)
+	require.Equal(t, int64(2), inc.cycle)
+	require.Equal(t, 1200.0, inc.start)
+	require.Equal(t, 30.0, inc.elapsed)
+
+	inc = incidentAt(time.Unix(1200+60, 0), 600, 60)
+	require.False(t, inc.active)
+
+	inc = incidentAt(time.Unix(1200+30, 0), 0, 60)
+	require.False(t, inc.active)
+
+	require.Equal(t, 60.0, incidentSeconds(0, 600, 600, 60))
+	require.Equal(t, 30.0, incidentSeconds(1230, 1800, 600, 60))
+	require.Equal(t, 60.0, incidentSeconds(1230, 1830, 600, 60))
+	require.Equal(t, 0.0, incidentSeconds(1300, 1700, 600, 60))
+
+	require.Equal(t, noiseAt(time.Unix(10, 0), 1), noiseAt(time.Unix(10, 0), 1))
+	require.NotEqual(t, noiseAt(time.Unix(10, 0), 1), noiseAt(time.Unix(10, 0), 2))
+	require.InDelta(t, 0, noiseAt(time.Unix(10, 0), 1), 1)
+}
@@ -0,0 +1,238 @@
+package graphite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type exprType int
+
+const (
+	exprPath exprType = iota
+	exprFunc
+	exprString
+	exprNumber
+	exprBool
+	exprNone
+)
+
+// expr is a parsed Graphite target expression
+type expr struct {
+	etype exprType
+	// str is the path of a path expression, the name of a function or the value of a string
+	str    string
+	num    float64
+	b      bool
+	args   []*expr
+	kwargs map[string]*expr
+	// text is the expression as written in the target, used to name the series it returns
+	text string
+}
+
+// parseTarget parses a Graphite target, such as aliasByNode(sumSeries(app.*.requests), 1)
+func parseTarget(target string) (*expr, error) {
+	p := &exprParser{input: target}
+	e, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos != len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d of the target", p.input[p.pos:], p.pos)
+	}
+	return e, nil
+}
+
+type exprParser struct {
+	input string
+	pos   int
+}
+
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *exprParser) peek() byte {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *exprParser) parseExpr() (*expr, error) {
+	p.skipSpaces()
+	start := p.pos
+
+	switch c := p.peek(); {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of the target")
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &expr{etype: exprString, str: s, text: p.input[start:p.pos]}, nil
+	case c == '(' || c == ')' || c == ',':
+		return nil, fmt.Errorf("unexpected %q at position %d of the target", c, p.pos)
+	}
+
+	token := p.parseToken(false)
+	if p.peek() == '(' {
+		return p.parseCall(token, start)
+	}
+
+	switch token {
+	case "true", "True":
+		return &expr{etype: exprBool, b: true, text: token}, nil
+	case "false", "False":
+		return &expr{etype: exprBool, b: false, text: token}, nil
+	case "None":
+		return &expr{etype: exprNone, text: token}, nil
+	}
+	if num, err := strconv.ParseFloat(token, 64); err == nil && !strings.ContainsAny(token, "*?") {
+		return &expr{etype: exprNumber, num: num, text: token}, nil
+	}
+	return &expr{etype: exprPath, str: token, text: token}, nil
+}
+
+// parseToken reads a function name, number or path. Commas inside braces, as in app.{a,b}.count,
+// are part of the path. In arguments, the token stops before the = of a keyword argument.
+func (p *exprParser) parseToken(arg bool) string {
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		case depth > 0:
+		case c == '(' || c == ')' || c == ',' || unicode.IsSpace(rune(c)):
+			return p.input[start:p.pos]
+		case c == '=' && arg && isIdentifier(p.input[start:p.pos]):
+			return p.input[start:p.pos]
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *exprParser) parseString() (string, error) {
+	quote := p.input[p.pos]
+	end := strings.IndexByte(p.input[p.pos+1:], quote)
+	if end == -1 {
+		return "", fmt.Errorf("unterminated string at position %d of the target", p.pos)
+	}
+	s := p.input[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return s, nil
+}
+
+func (p *exprParser) parseCall(name string, start int) (*expr, error) {
+	if !isIdentifier(name) {
+		return nil, fmt.Errorf("invalid function name %q", name)
+	}
+	e := &expr{etype: exprFunc, str: name}
+
+	// skip the opening parenthesis
+	p.pos++
+	p.skipSpaces()
+	if p.peek() == ')' {
+		p.pos++
+		e.text = p.input[start:p.pos]
+		return e, nil
+	}
+
+	for {
+		p.skipSpaces()
+		argStart := p.pos
+		if key := p.parseToken(true); key != "" && p.peek() == '=' {
+			p.pos++
+			value, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if e.kwargs == nil {
+				e.kwargs = map[string]*expr{}
+			}
+			e.kwargs[key] = value
+		} else {
+			p.pos = argStart
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if len(e.kwargs) > 0 {
+				return nil, fmt.Errorf("positional argument after keyword argument in %s", name)
+			}
+			e.args = append(e.args, arg)
+		}
+
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			e.text = p.input[start:p.pos]
+			return e, nil
+		default:
+			return nil, fmt.Errorf("expected , or ) after the arguments of %s at position %d", name, p.pos)
+		}
+	}
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c != '_' && !unicode.IsLetter(c) && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// walk calls fn for the expression and all its arguments
+func (e *expr) walk(fn func(*expr) error) error {
+	if err := fn(e); err != nil {
+		return err
+	}
+	for _, arg := range e.args {
+		if err := arg.walk(fn); err != nil {
+			return err
+		}
+	}
+	for _, arg := range e.kwargs {
+		if err := arg.walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstPathExpression returns the first path of a series name, such as app.web.requests for
+// scale(app.web.requests,10). Names that can't be parsed are returned as they are.
+func firstPathExpression(name string) string {
+	e, err := parseTarget(name)
+	if err != nil {
+		return name
+	}
+	path := ""
+	_ = e.walk(func(e *expr) error {
+		if e.etype == exprPath && path == "" {
+			path = e.str
+		}
+		return nil
+	})
+	if path == "" {
+		return name
+	}
+	return path
+}
@@ -0,0 +1,78 @@
+package graphite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	t.Run("Parses nested functions and their arguments", func(t *testing.T) {
+		e, err := parseTarget(`aliasByNode(movingAverage(sumSeries(app.{web,api}.*.requests), '5min'), 1, -1)`)
+		require.NoError(t, err)
+		assert.Equal(t, exprFunc, e.etype)
+		assert.Equal(t, "aliasByNode", e.str)
+		require.Len(t, e.args, 3)
+		assert.Equal(t, 1.0, e.args[1].num)
+		assert.Equal(t, -1.0, e.args[2].num)
+
+		movingAvg := e.args[0]
+		assert.Equal(t, "movingAverage", movingAvg.str)
+		assert.Equal(t, `movingAverage(sumSeries(app.{web,api}.*.requests), '5min')`, movingAvg.text)
+		require.Len(t, movingAvg.args, 2)
+		assert.Equal(t, exprString, movingAvg.args[1].etype)
+		assert.Equal(t, "5min", movingAvg.args[1].str)
+
+		sum := movingAvg.args[0]
+		require.Len(t, sum.args, 1)
+		assert.Equal(t, exprPath, sum.args[0].etype)
+		assert.Equal(t, "app.{web,api}.*.requests", sum.args[0].str)
+	})
+
+	t.Run("Parses keyword arguments", func(t *testing.T) {
+		e, err := parseTarget(`asPercent(app.*.requests, total=sumSeries(app.*.requests))`)
+		require.NoError(t, err)
+		require.Len(t, e.args, 1)
+		require.Contains(t, e.kwargs, "total")
+		assert.Equal(t, "sumSeries", e.kwargs["total"].str)
+	})
+
+	t.Run("Parses tagged series", func(t *testing.T) {
+		e, err := parseTarget(`sumSeries(seriesByTag('name=requests', 'env=prod'))`)
+		require.NoError(t, err)
+		tagged := e.args[0]
+		assert.Equal(t, "seriesByTag", tagged.str)
+		assert.Equal(t, `seriesByTag('name=requests', 'env=prod')`, tagged.text)
+		assert.Equal(t, "env=prod", tagged.args[1].str)
+	})
+
+	t.Run("Parses literals", func(t *testing.T) {
+		e, err := parseTarget(`asPercent(app.requests, None)`)
+		require.NoError(t, err)
+		assert.Equal(t, exprNone, e.args[1].etype)
+
+		e, err = parseTarget(`app.10`)
+		require.NoError(t, err)
+		assert.Equal(t, exprPath, e.etype)
+	})
+
+	t.Run("Returns errors for invalid targets", func(t *testing.T) {
+		for _, target := range []string{
+			"",
+			"sumSeries(app.requests",
+			"sumSeries(app.requests))",
+			"alias(app.requests, 'name)",
+			"sumSeries(,app.requests)",
+		} {
+			_, err := parseTarget(target)
+			assert.Error(t, err, target)
+		}
+	})
+}
+
+func TestFirstPathExpression(t *testing.T) {
+	assert.Equal(t, "app.web.requests", firstPathExpression("scale(app.web.requests,10)"))
+	assert.Equal(t, "app.web.requests", firstPathExpression("app.web.requests"))
+	assert.Equal(t, "not a (path", firstPathExpression("not a (path"))
+}
@@ -0,0 +1,677 @@
+package graphite
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fetchFunc returns the raw series of a path expression or of a fetch function such as seriesByTag,
+// with the start of the range moved back by bootstrap
+type fetchFunc func(ctx context.Context, target string, bootstrap time.Duration) ([]TargetResponseDTO, error)
+
+type evaluator struct {
+	fetch fetchFunc
+	// fetched caches the series of every target, as the same path is often used more than once,
+	// for example in asPercent(app.*.requests, sumSeries(app.*.requests))
+	fetched map[string][]TargetResponseDTO
+	// from is the start of the range of the query, zero when unknown
+	from time.Time
+	// bootstrap is how far before from the series being evaluated are fetched, so that functions
+	// over a window of past points, such as movingAverage, have a full window at the start of the range
+	bootstrap time.Duration
+}
+
+// seriesFunction runs a Graphite function with the arguments of the call
+type seriesFunction func(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error)
+
+// seriesFunctions are the Graphite functions that can run in the backend
+var seriesFunctions map[string]seriesFunction
+
+// fetchFunctions are run by Graphite and return raw series
+var fetchFunctions = map[string]bool{
+	"seriesByTag": true,
+}
+
+func init() {
+	seriesFunctions = map[string]seriesFunction{
+		"sumSeries":             aggregateSeriesFunction("sum"),
+		"sum":                   aggregateSeriesFunction("sum"),
+		"averageSeries":         aggregateSeriesFunction("average"),
+		"avg":                   aggregateSeriesFunction("average"),
+		"maxSeries":             aggregateSeriesFunction("max"),
+		"minSeries":             aggregateSeriesFunction("min"),
+		"countSeries":           aggregateSeriesFunction("count"),
+		"alias":                 alias,
+		"aliasByNode":           aliasByNode,
+		"scale":                 transformFunction("scale", func(v, factor float64) float64 { return v * factor }),
+		"offset":                transformFunction("offset", func(v, factor float64) float64 { return v + factor }),
+		"absolute":              absolute,
+		"derivative":            derivative,
+		"nonNegativeDerivative": nonNegativeDerivative,
+		"movingAverage":         movingAverage,
+		"asPercent":             asPercent,
+		"groupByNode":           groupByNode,
+		"groupByNodes":          groupByNodes,
+	}
+}
+
+// validateExpression reports the functions of the target that can't run in the backend, before
+// any series is fetched
+func validateExpression(e *expr) error {
+	return e.walk(func(e *expr) error {
+		if e.etype != exprFunc || fetchFunctions[e.str] {
+			return nil
+		}
+		if _, ok := seriesFunctions[e.str]; !ok {
+			return fmt.Errorf("unsupported function %q", e.str)
+		}
+		return nil
+	})
+}
+
+func (ev *evaluator) eval(ctx context.Context, e *expr) ([]TargetResponseDTO, error) {
+	switch e.etype {
+	case exprPath:
+		return ev.fetchTarget(ctx, e.str)
+	case exprFunc:
+		if fetchFunctions[e.str] {
+			return ev.fetchTarget(ctx, e.text)
+		}
+		fn, ok := seriesFunctions[e.str]
+		if !ok {
+			return nil, fmt.Errorf("unsupported function %q", e.str)
+		}
+		return fn(ctx, ev, e)
+	default:
+		return nil, fmt.Errorf("expected a series list, got %s", e.text)
+	}
+}
+
+func (ev *evaluator) fetchTarget(ctx context.Context, target string) ([]TargetResponseDTO, error) {
+	key := target
+	if ev.bootstrap > 0 {
+		key = fmt.Sprintf("%s@%s", target, ev.bootstrap)
+	}
+	if series, ok := ev.fetched[key]; ok {
+		return copySeriesList(series), nil
+	}
+	series, err := ev.fetch(ctx, target, ev.bootstrap)
+	if err != nil {
+		return nil, err
+	}
+	ev.fetched[key] = series
+	return copySeriesList(series), nil
+}
+
+// copySeriesList copies the series so that functions can change them in place
+func copySeriesList(list []TargetResponseDTO) []TargetResponseDTO {
+	out := make([]TargetResponseDTO, 0, len(list))
+	for _, s := range list {
+		s.DataPoints = append(DataTimeSeriesPoints{}, s.DataPoints...)
+		out = append(out, s)
+	}
+	return out
+}
+
+func (ev *evaluator) seriesArg(ctx context.Context, call *expr, i int) ([]TargetResponseDTO, error) {
+	if i >= len(call.args) {
+		return nil, fmt.Errorf("%s: missing series list argument", call.str)
+	}
+	return ev.eval(ctx, call.args[i])
+}
+
+// windowSeriesArg evaluates the series argument of a function over a window of past points, with the fetch
+// range extended back by the window. A window given as a number of points is converted to a duration with
+// the step of the series, which are fetched once without the extension to find it.
+func (ev *evaluator) windowSeriesArg(ctx context.Context, call *expr, i int, window time.Duration, points int) ([]TargetResponseDTO, error) {
+	if points > 0 {
+		list, err := ev.seriesArg(ctx, call, i)
+		if err != nil {
+			return nil, err
+		}
+		var step time.Duration
+		for _, s := range list {
+			step = max(step, seriesStep(s))
+		}
+		if step == 0 {
+			return list, nil
+		}
+		window = step * time.Duration(points)
+	}
+	if window <= 0 {
+		return ev.seriesArg(ctx, call, i)
+	}
+
+	ev.bootstrap += window
+	defer func() { ev.bootstrap -= window }()
+	return ev.seriesArg(ctx, call, i)
+}
+
+// trimBootstrap removes the points fetched before the range the caller of the function needs
+func (ev *evaluator) trimBootstrap(list []TargetResponseDTO) {
+	if ev.from.IsZero() {
+		return
+	}
+	start := float64(ev.from.Add(-ev.bootstrap).Unix())
+	for i := range list {
+		points := list[i].DataPoints[:0]
+		for _, p := range list[i].DataPoints {
+			if p[1].Float64 >= start {
+				points = append(points, p)
+			}
+		}
+		list[i].DataPoints = points
+	}
+}
+
+func numberArg(call *expr, i int) (float64, error) {
+	if i >= len(call.args) {
+		return 0, fmt.Errorf("%s: missing argument %d", call.str, i+1)
+	}
+	if call.args[i].etype != exprNumber {
+		return 0, fmt.Errorf("%s: argument %d must be a number, got %s", call.str, i+1, call.args[i].text)
+	}
+	return call.args[i].num, nil
+}
+
+func stringArg(call *expr, i int) (string, error) {
+	if i >= len(call.args) {
+		return "", fmt.Errorf("%s: missing argument %d", call.str, i+1)
+	}
+	if call.args[i].etype != exprString {
+		return "", fmt.Errorf("%s: argument %d must be a string, got %s", call.str, i+1, call.args[i].text)
+	}
+	return call.args[i].str, nil
+}
+
+func nodeArgs(call *expr, from int) ([]int, error) {
+	nodes := []int{}
+	for i := from; i < len(call.args); i++ {
+		n, err := numberArg(call, i)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, int(n))
+	}
+	return nodes, nil
+}
+
+// aggregators combine the values of several series at the same time, ignoring null values
+var aggregators = map[string]func(values []float64) float64{
+	"sum": func(values []float64) float64 {
+		total := 0.0
+		for _, v := range values {
+			total += v
+		}
+		return total
+	},
+	"average": func(values []float64) float64 {
+		total := 0.0
+		for _, v := range values {
+			total += v
+		}
+		return total / float64(len(values))
+	},
+	"max": func(values []float64) float64 {
+		m := math.Inf(-1)
+		for _, v := range values {
+			m = math.Max(m, v)
+		}
+		return m
+	},
+	"min": func(values []float64) float64 {
+		m := math.Inf(1)
+		for _, v := range values {
+			m = math.Min(m, v)
+		}
+		return m
+	},
+	"count": func(values []float64) float64 {
+		return float64(len(values))
+	},
+}
+
+// getAggregator returns the aggregator of a callback name such as sum, avg or sumSeries
+func getAggregator(name string) (func([]float64) float64, error) {
+	name = strings.TrimSuffix(name, "Series")
+	if name == "avg" {
+		name = "average"
+	}
+	agg, ok := aggregators[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported aggregation function %q", name)
+	}
+	return agg, nil
+}
+
+// aggregateSeries combines the series in a single one, point by point
+func aggregateSeries(list []TargetResponseDTO, name string, agg func([]float64) float64) TargetResponseDTO {
+	valuesAt := map[float64][]float64{}
+	times := []float64{}
+	for _, s := range list {
+		for _, p := range s.DataPoints {
+			ts := p[1].Float64
+			values, ok := valuesAt[ts]
+			if !ok {
+				times = append(times, ts)
+			}
+			if p[0].Valid {
+				values = append(values, p[0].Float64)
+			}
+			valuesAt[ts] = values
+		}
+	}
+	sort.Float64s(times)
+
+	points := make(DataTimeSeriesPoints, 0, len(times))
+	for _, ts := range times {
+		value := NewFloat(0, false)
+		if values := valuesAt[ts]; len(values) > 0 {
+			value = FloatFrom(agg(values))
+		}
+		points = append(points, DataTimePoint{value, FloatFrom(ts)})
+	}
+	return TargetResponseDTO{Target: name, DataPoints: points, Tags: map[string]any{"name": name}}
+}
+
+func aggregateSeriesFunction(aggregation string) seriesFunction {
+	return func(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error) {
+		list := []TargetResponseDTO{}
+		texts := make([]string, 0, len(call.args))
+		for i, arg := range call.args {
+			series, err := ev.seriesArg(ctx, call, i)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, series...)
+			texts = append(texts, arg.text)
+		}
+		if len(list) == 0 {
+			return list, nil
+		}
+		name := fmt.Sprintf("%sSeries(%s)", aggregation, strings.Join(texts, ","))
+		return []TargetResponseDTO{aggregateSeries(list, name, aggregators[aggregation])}, nil
+	}
+}
+
+// mapValues applies fn to the non-null values of the series
+func mapValues(s *TargetResponseDTO, fn func(v float64) float64) {
+	for i, p := range s.DataPoints {
+		if p[0].Valid {
+			s.DataPoints[i][0] = FloatFrom(fn(p[0].Float64))
+		}
+	}
+}
+
+func transformFunction(name string, fn func(v, arg float64) float64) seriesFunction {
+	return func(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error) {
+		list, err := ev.seriesArg(ctx, call, 0)
+		if err != nil {
+			return nil, err
+		}
+		arg, err := numberArg(call, 1)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list {
+			mapValues(&list[i], func(v float64) float64 { return fn(v, arg) })
+			list[i].Target = fmt.Sprintf("%s(%s,%s)", name, list[i].Target, call.args[1].text)
+		}
+		return list, nil
+	}
+}
+
+func alias(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error) {
+	list, err := ev.seriesArg(ctx, call, 0)
+	if err != nil {
+		return nil, err
+	}
+	name, err := stringArg(call, 1)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Target = name
+	}
+	return list, nil
+}
+
+// seriesNodes returns the nodes of the path of the series, without its tags
+func seriesNodes(name string) []string {
+	path := firstPathExpression(name)
+	if idx := strings.IndexByte(path, ';'); idx != -1 {
+		path = path[:idx]
+	}
+	return strings.Split(path, ".")
+}
+
+// joinNodes joins the nodes of the series at the positions, counted from the end when negative
+func joinNodes(name string, positions []int) string {
+	nodes := seriesNodes(name)
+	parts := make([]string, 0, len(positions))
+	for _, n := range positions {
+		if n < 0 {
+			n += len(nodes)
+		}
+		if n >= 0 && n < len(nodes) {
+			parts = append(parts, nodes[n])
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func aliasByNode(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error) {
+	list, err := ev.seriesArg(ctx, call, 0)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := nodeArgs(call, 1)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Target = joinNodes(list[i].Target, nodes)
+	}
+	return list, nil
+}
+
+func absolute(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error) {
+	list, err := ev.seriesArg(ctx, call, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		mapValues(&list[i], math.Abs)
+		list[i].Target = fmt.Sprintf("absolute(%s)", list[i].Target)
+	}
+	return list, nil
+}
+
+// derivatives replaces every value by its difference with the previous value. diff returns
+// the difference of two values, or false when it is null.
+func derivatives(s *TargetResponseDTO, diff func(prev, v float64) (float64, bool)) {
+	prev := NewFloat(0, false)
+	for i, p := range s.DataPoints {
+		value := p[0]
+		s.DataPoints[i][0] = NewFloat(0, false)
+		if value.Valid && prev.Valid {
+			if d, ok := diff(prev.Float64, value.Float64); ok {
+				s.DataPoints[i][0] = FloatFrom(d)
+			}
+		}
+		prev = value
+	}
+}
+
+func derivative(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error) {
+	// the previous point is fetched so that the first point of the range has a value
+	list, err := ev.windowSeriesArg(ctx, call, 0, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		derivatives(&list[i], func(prev, v float64) (float64, bool) { return v - prev, true })
+		list[i].Target = fmt.Sprintf("derivative(%s)", list[i].Target)
+	}
+	ev.trimBootstrap(list)
+	return list, nil
+}
+
+func nonNegativeDerivative(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error) {
+	list, err := ev.windowSeriesArg(ctx, call, 0, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	maxValue := math.NaN()
+	if len(call.args) > 1 {
+		if maxValue, err = numberArg(call, 1); err != nil {
+			return nil, err
+		}
+	}
+	for i := range list {
+		derivatives(&list[i], func(prev, v float64) (float64, bool) {
+			if v >= prev {
+				return v - prev, true
+			}
+			// the counter wrapped around at maxValue
+			if !math.IsNaN(maxValue) && maxValue >= v {
+				return maxValue - prev + v + 1, true
+			}
+			return 0, false
+		})
+		list[i].Target = fmt.Sprintf("nonNegativeDerivative(%s)", list[i].Target)
+	}
+	ev.trimBootstrap(list)
+	return list, nil
+}
+
+var intervalRegex = regexp.MustCompile(`^(-?\d+)([a-zA-Z]+)$`)
+
+// parseGraphiteInterval parses an interval such as 5min or 1h
+func parseGraphiteInterval(interval string) (time.Duration, error) {
+	match := intervalRegex.FindStringSubmatch(strings.TrimSpace(interval))
+	if match == nil {
+		return 0, fmt.Errorf("invalid interval %q", interval)
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q", interval)
+	}
+
+	var unit time.Duration
+	switch u := strings.ToLower(match[2]); {
+	case u == "s" || strings.HasPrefix(u, "sec"):
+		unit = time.Second
+	case u == "m" || strings.HasPrefix(u, "min"):
+		unit = time.Minute
+	case u == "h" || strings.HasPrefix(u, "hour"):
+		unit = time.Hour
+	case u == "d" || strings.HasPrefix(u, "day"):
+		unit = 24 * time.Hour
+	case u == "w" || strings.HasPrefix(u, "week"):
+		unit = 7 * 24 * time.Hour
+	case strings.HasPrefix(u, "mon"):
+		unit = 30 * 24 * time.Hour
+	case u == "y" || strings.HasPrefix(u, "year"):
+		unit = 365 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid interval unit %q", match[2])
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// seriesStep returns the time between the points of the series
+func seriesStep(s TargetResponseDTO) time.Duration {
+	if len(s.DataPoints) < 2 {
+		return 0
+	}
+	return time.Duration(s.DataPoints[1][1].Float64-s.DataPoints[0][1].Float64) * time.Second
+}
+
+func movingAverage(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error) {
+	if len(call.args) < 2 {
+		return nil, fmt.Errorf("movingAverage: missing window size")
+	}
+
+	window := call.args[1]
+	var duration time.Duration
+	var err error
+	switch window.etype {
+	case exprNumber:
+	case exprString:
+		if duration, err = parseGraphiteInterval(window.str); err != nil {
+			return nil, fmt.Errorf("movingAverage: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("movingAverage: window size must be a number or an interval, got %s", window.text)
+	}
+
+	list, err := ev.windowSeriesArg(ctx, call, 0, duration, int(window.num))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range list {
+		points := int(window.num)
+		if window.etype == exprString {
+			if step := seriesStep(list[i]); step > 0 {
+				points = int(duration / step)
+			}
+		}
+		points = max(points, 1)
+
+		values := make([]Float, len(list[i].DataPoints))
+		for j, p := range list[i].DataPoints {
+			values[j] = p[0]
+		}
+		for j := range list[i].DataPoints {
+			windowValues := []float64{}
+			for _, v := range values[max(0, j-points+1) : j+1] {
+				if v.Valid {
+					windowValues = append(windowValues, v.Float64)
+				}
+			}
+			list[i].DataPoints[j][0] = NewFloat(0, false)
+			if len(windowValues) > 0 {
+				list[i].DataPoints[j][0] = FloatFrom(aggregators["average"](windowValues))
+			}
+		}
+		list[i].Target = fmt.Sprintf("movingAverage(%s,%s)", list[i].Target, window.text)
+	}
+	ev.trimBootstrap(list)
+	return list, nil
+}
+
+func asPercent(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error) {
+	list, err := ev.seriesArg(ctx, call, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(call.args) > 2 {
+		return nil, fmt.Errorf("asPercent: grouping by nodes is not supported")
+	}
+
+	total, ok := call.kwargs["total"]
+	if len(call.args) > 1 {
+		total, ok = call.args[1], true
+	}
+
+	// totals has the total of every series
+	totals := make([]TargetResponseDTO, len(list))
+	totalText := ""
+	switch {
+	case !ok || total.etype == exprNone:
+		sum := aggregateSeries(list, "", aggregators["sum"])
+		for i := range totals {
+			totals[i] = sum
+		}
+	case total.etype == exprNumber:
+		totalText = total.text
+		for i := range list {
+			totals[i] = list[i]
+			totals[i].DataPoints = append(DataTimeSeriesPoints{}, list[i].DataPoints...)
+			mapValues(&totals[i], func(float64) float64 { return total.num })
+		}
+	default:
+		totalText = total.text
+		totalSeries, err := ev.eval(ctx, total)
+		if err != nil {
+			return nil, err
+		}
+		switch len(totalSeries) {
+		case 1:
+			for i := range totals {
+				totals[i] = totalSeries[0]
+			}
+		case len(list):
+			// series are matched with the totals of the same position
+			copy(totals, totalSeries)
+		default:
+			return nil, fmt.Errorf("asPercent: the total must be a single series or as many series as the series list")
+		}
+	}
+
+	for i := range list {
+		totalAt := map[float64]Float{}
+		for _, p := range totals[i].DataPoints {
+			totalAt[p[1].Float64] = p[0]
+		}
+		for j, p := range list[i].DataPoints {
+			t := totalAt[p[1].Float64]
+			list[i].DataPoints[j][0] = NewFloat(0, false)
+			if p[0].Valid && t.Valid && t.Float64 != 0 {
+				list[i].DataPoints[j][0] = FloatFrom(p[0].Float64 / t.Float64 * 100)
+			}
+		}
+		if totalText == "" {
+			list[i].Target = fmt.Sprintf("asPercent(%s)", list[i].Target)
+		} else {
+			list[i].Target = fmt.Sprintf("asPercent(%s,%s)", list[i].Target, totalText)
+		}
+	}
+	return list, nil
+}
+
+// groupSeries aggregates the series with the same nodes at the positions
+func groupSeries(list []TargetResponseDTO, nodes []int, callback string) ([]TargetResponseDTO, error) {
+	agg, err := getAggregator(callback)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	groups := map[string][]TargetResponseDTO{}
+	for _, s := range list {
+		key := joinNodes(s.Target, nodes)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], s)
+	}
+
+	out := make([]TargetResponseDTO, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, aggregateSeries(groups[key], key, agg))
+	}
+	return out, nil
+}
+
+func groupByNode(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error) {
+	list, err := ev.seriesArg(ctx, call, 0)
+	if err != nil {
+		return nil, err
+	}
+	node, err := numberArg(call, 1)
+	if err != nil {
+		return nil, err
+	}
+	callback := "average"
+	if len(call.args) > 2 {
+		if callback, err = stringArg(call, 2); err != nil {
+			return nil, err
+		}
+	}
+	return groupSeries(list, []int{int(node)}, callback)
+}
+
+func groupByNodes(ctx context.Context, ev *evaluator, call *expr) ([]TargetResponseDTO, error) {
+	list, err := ev.seriesArg(ctx, call, 0)
+	if err != nil {
+		return nil, err
+	}
+	callback, err := stringArg(call, 1)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := nodeArgs(call, 2)
+	if err != nil {
+		return nil, err
+	}
+	return groupSeries(list, nodes, callback)
+}
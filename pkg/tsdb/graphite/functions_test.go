@@ -0,0 +1,319 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSeries are the raw series of the fake Graphite, with a point every minute
+var testSeries = map[string][]TargetResponseDTO{
+	"app.*.requests": {
+		newTestSeries("app.web.requests", 1, 2, 3),
+		newTestSeries("app.api.requests", 3, 2, 1),
+	},
+	"app.web.requests": {
+		newTestSeries("app.web.requests", 1, 2, 3),
+	},
+	"app.*.*.errors": {
+		newTestSeries("app.web.eu.errors", 1, 1, 1),
+		newTestSeries("app.web.us.errors", 2, 2, 2),
+		newTestSeries("app.api.eu.errors", 4, 4, 4),
+	},
+}
+
+func newTestSeries(name string, values ...float64) TargetResponseDTO {
+	points := make(DataTimeSeriesPoints, 0, len(values))
+	for i, v := range values {
+		points = append(points, DataTimePoint{FloatFrom(v), FloatFrom(float64(1609459200 + 60*i))})
+	}
+	return TargetResponseDTO{Target: name, DataPoints: points}
+}
+
+func evaluateTestTarget(t *testing.T, target string) ([]TargetResponseDTO, []string) {
+	t.Helper()
+	fetched := []string{}
+	ev := &evaluator{
+		fetched: map[string][]TargetResponseDTO{},
+		fetch: func(_ context.Context, target string, _ time.Duration) ([]TargetResponseDTO, error) {
+			fetched = append(fetched, target)
+			return testSeries[target], nil
+		},
+	}
+	e, err := parseTarget(target)
+	require.NoError(t, err)
+	require.NoError(t, validateExpression(e))
+	series, err := ev.eval(context.Background(), e)
+	require.NoError(t, err)
+	return series, fetched
+}
+
+func seriesValues(s TargetResponseDTO) []any {
+	values := make([]any, 0, len(s.DataPoints))
+	for _, p := range s.DataPoints {
+		if p[0].Valid {
+			values = append(values, p[0].Float64)
+		} else {
+			values = append(values, nil)
+		}
+	}
+	return values
+}
+
+func TestEvaluateFunctions(t *testing.T) {
+	tests := []struct {
+		target string
+		names  []string
+		values [][]any
+	}{
+		{
+			target: "sumSeries(app.*.requests)",
+			names:  []string{"sumSeries(app.*.requests)"},
+			values: [][]any{{4.0, 4.0, 4.0}},
+		},
+		{
+			target: "maxSeries(app.*.requests)",
+			names:  []string{"maxSeries(app.*.requests)"},
+			values: [][]any{{3.0, 2.0, 3.0}},
+		},
+		{
+			target: "aliasByNode(app.*.requests, 1)",
+			names:  []string{"web", "api"},
+			values: [][]any{{1.0, 2.0, 3.0}, {3.0, 2.0, 1.0}},
+		},
+		{
+			target: "aliasByNode(scale(app.web.requests, 10), 1, -1)",
+			names:  []string{"web.requests"},
+			values: [][]any{{10.0, 20.0, 30.0}},
+		},
+		{
+			target: "alias(offset(app.web.requests, -1), 'requests')",
+			names:  []string{"requests"},
+			values: [][]any{{0.0, 1.0, 2.0}},
+		},
+		{
+			target: "derivative(app.web.requests)",
+			names:  []string{"derivative(app.web.requests)"},
+			values: [][]any{{nil, 1.0, 1.0}},
+		},
+		{
+			target: "nonNegativeDerivative(app.*.requests)",
+			names:  []string{"nonNegativeDerivative(app.web.requests)", "nonNegativeDerivative(app.api.requests)"},
+			values: [][]any{{nil, 1.0, 1.0}, {nil, nil, nil}},
+		},
+		{
+			target: "movingAverage(app.web.requests, 2)",
+			names:  []string{"movingAverage(app.web.requests,2)"},
+			values: [][]any{{1.0, 1.5, 2.5}},
+		},
+		{
+			target: "movingAverage(app.web.requests, '3min')",
+			names:  []string{"movingAverage(app.web.requests,'3min')"},
+			values: [][]any{{1.0, 1.5, 2.0}},
+		},
+		{
+			target: "asPercent(app.*.requests)",
+			names:  []string{"asPercent(app.web.requests)", "asPercent(app.api.requests)"},
+			values: [][]any{{25.0, 50.0, 75.0}, {75.0, 50.0, 25.0}},
+		},
+		{
+			target: "asPercent(app.web.requests, 4)",
+			names:  []string{"asPercent(app.web.requests,4)"},
+			values: [][]any{{25.0, 50.0, 75.0}},
+		},
+		{
+			target: "asPercent(app.web.requests, sumSeries(app.*.requests))",
+			names:  []string{"asPercent(app.web.requests,sumSeries(app.*.requests))"},
+			values: [][]any{{25.0, 50.0, 75.0}},
+		},
+		{
+			target: "groupByNode(app.*.*.errors, 1, 'sum')",
+			names:  []string{"web", "api"},
+			values: [][]any{{3.0, 3.0, 3.0}, {4.0, 4.0, 4.0}},
+		},
+		{
+			target: "groupByNode(app.*.*.errors, 2)",
+			names:  []string{"eu", "us"},
+			values: [][]any{{2.5, 2.5, 2.5}, {2.0, 2.0, 2.0}},
+		},
+		{
+			target: "groupByNodes(app.*.*.errors, 'maxSeries', 1, 2)",
+			names:  []string{"web.eu", "web.us", "api.eu"},
+			values: [][]any{{1.0, 1.0, 1.0}, {2.0, 2.0, 2.0}, {4.0, 4.0, 4.0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			series, _ := evaluateTestTarget(t, tt.target)
+			names := []string{}
+			values := [][]any{}
+			for _, s := range series {
+				names = append(names, s.Target)
+				values = append(values, seriesValues(s))
+			}
+			assert.Equal(t, tt.names, names)
+			assert.Equal(t, tt.values, values)
+		})
+	}
+
+	t.Run("Fetches every path once", func(t *testing.T) {
+		_, fetched := evaluateTestTarget(t, "asPercent(app.*.requests, sumSeries(app.*.requests))")
+		assert.Equal(t, []string{"app.*.requests"}, fetched)
+	})
+
+	t.Run("Does not change the series of other functions", func(t *testing.T) {
+		series, _ := evaluateTestTarget(t, "sumSeries(scale(app.web.requests, 2), app.web.requests)")
+		assert.Equal(t, []any{3.0, 6.0, 9.0}, seriesValues(series[0]))
+	})
+}
+
+func TestValidateExpression(t *testing.T) {
+	e, err := parseTarget("sumSeries(hitcount(app.*.requests, '1h'))")
+	require.NoError(t, err)
+	assert.ErrorContains(t, validateExpression(e), `unsupported function "hitcount"`)
+
+	e, err = parseTarget("sumSeries(seriesByTag('name=requests'))")
+	require.NoError(t, err)
+	assert.NoError(t, validateExpression(e))
+}
+
+func TestParseGraphiteInterval(t *testing.T) {
+	for interval, expected := range map[string]time.Duration{
+		"30s":    30 * time.Second,
+		"5min":   5 * time.Minute,
+		"2h":     2 * time.Hour,
+		"1d":     24 * time.Hour,
+		"1w":     7 * 24 * time.Hour,
+		"1mon":   30 * 24 * time.Hour,
+		"2hours": 2 * time.Hour,
+	} {
+		d, err := parseGraphiteInterval(interval)
+		require.NoError(t, err, interval)
+		assert.Equal(t, expected, d, interval)
+	}
+
+	_, err := parseGraphiteInterval("5x")
+	assert.Error(t, err)
+}
+
+func TestRunQueryWithEmulatedFunctions(t *testing.T) {
+	targets := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		target := r.FormValue("target")
+		targets = append(targets, target)
+		body, err := json.Marshal(testSeries[target])
+		require.NoError(t, err)
+		_, err = w.Write(body)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	service := &Service{logger: backend.Logger}
+	dsInfo := &datasourceInfo{Id: 1, URL: server.URL, HTTPClient: &http.Client{}, EmulateFunctions: true}
+	newRequest := func(target string) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:         "A",
+				TimeRange:     backend.TimeRange{From: time.Unix(1609459200, 0), To: time.Unix(1609459320, 0)},
+				MaxDataPoints: 100,
+				JSON:          []byte(`{"target": "` + target + `"}`),
+			}},
+		}
+	}
+
+	t.Run("Runs functions on the raw series", func(t *testing.T) {
+		result, err := service.RunQuery(context.Background(), newRequest("aliasByNode(app.*.requests, 1)"), dsInfo)
+		require.NoError(t, err)
+		res := result.Responses["A"]
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 2)
+		assert.Equal(t, "web", res.Frames[0].Fields[1].Config.DisplayNameFromDS)
+		assert.Equal(t, "A", res.Frames[0].RefID)
+		assert.Equal(t, []string{"app.*.requests"}, targets)
+	})
+
+	t.Run("Reports unsupported functions before querying Graphite", func(t *testing.T) {
+		targets = targets[:0]
+		result, err := service.RunQuery(context.Background(), newRequest("hitcount(app.*.requests, '1h')"), dsInfo)
+		require.NoError(t, err)
+		res := result.Responses["A"]
+		require.ErrorContains(t, res.Error, `unsupported function "hitcount"`)
+		assert.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+		assert.Empty(t, targets)
+	})
+}
+
+func TestRunQueryExtendsTheRangeOfWindowFunctions(t *testing.T) {
+	const from, until = 1609459200, 1609459320
+	requests := []url.Values{}
+	// the fake Graphite returns a point every minute of the requested range, worth 10 at from
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		requests = append(requests, r.PostForm)
+		start, err := strconv.ParseInt(r.FormValue("from"), 10, 64)
+		require.NoError(t, err)
+		points := DataTimeSeriesPoints{}
+		for ts := start; ts <= until; ts += 60 {
+			points = append(points, DataTimePoint{FloatFrom(float64(10 + (ts-from)/60)), FloatFrom(float64(ts))})
+		}
+		body, err := json.Marshal([]TargetResponseDTO{{Target: r.FormValue("target"), DataPoints: points}})
+		require.NoError(t, err)
+		_, err = w.Write(body)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	service := &Service{logger: backend.Logger}
+	dsInfo := &datasourceInfo{Id: 1, URL: server.URL, HTTPClient: &http.Client{}, EmulateFunctions: true}
+	run := func(t *testing.T, target string) []any {
+		t.Helper()
+		requests = requests[:0]
+		result, err := service.RunQuery(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:         "A",
+				TimeRange:     backend.TimeRange{From: time.Unix(from, 0), To: time.Unix(until, 0)},
+				MaxDataPoints: 100,
+				JSON:          []byte(`{"target": "` + target + `"}`),
+			}},
+		}, dsInfo)
+		require.NoError(t, err)
+		res := result.Responses["A"]
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+
+		values := []any{}
+		for i := 0; i < res.Frames[0].Rows(); i++ {
+			values = append(values, *res.Frames[0].Fields[1].At(i).(*float64))
+		}
+		return values
+	}
+
+	t.Run("movingAverage fetches its window before the range", func(t *testing.T) {
+		assert.Equal(t, []any{9.5, 10.5, 11.5}, run(t, "movingAverage(app.web.requests, '2min')"))
+		require.Len(t, requests, 1)
+		assert.Equal(t, "1609459080", requests[0].Get("from"))
+		assert.Equal(t, "200", requests[0].Get("maxDataPoints"))
+	})
+
+	t.Run("movingAverage over a number of points finds the step first", func(t *testing.T) {
+		assert.Equal(t, []any{9.0, 10.0, 11.0}, run(t, "movingAverage(app.web.requests, 3)"))
+		require.Len(t, requests, 2)
+		assert.Equal(t, "1609459200", requests[0].Get("from"))
+		assert.Equal(t, "1609459020", requests[1].Get("from"))
+	})
+
+	t.Run("derivative has a value at the start of the range", func(t *testing.T) {
+		assert.Equal(t, []any{1.0, 1.0, 1.0}, run(t, "derivative(app.web.requests)"))
+	})
+}
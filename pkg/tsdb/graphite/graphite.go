@@ -2,6 +2,7 @@ package graphite
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -46,6 +47,12 @@ type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
 	Id         int64
+	// EmulateFunctions runs the functions of targets in the backend, on raw series rendered by Graphite
+	EmulateFunctions bool
+}
+
+type graphiteJSONData struct {
+	EmulateFunctions bool `json:"emulateFunctions"`
 }
 
 func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -60,10 +67,18 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.Ins
 			return nil, err
 		}
 
+		jsonData := graphiteJSONData{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
 		model := datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
-			Id:         settings.ID,
+			HTTPClient:       client,
+			URL:              settings.URL,
+			Id:               settings.ID,
+			EmulateFunctions: jsonData.EmulateFunctions,
 		}
 
 		return model, nil
@@ -26,6 +26,8 @@ type queryModel struct {
 	req       *http.Request
 	formData  url.Values
 	rawTarget string
+	// expr is the parsed target when its functions run in the backend
+	expr *expr
 }
 
 func (s *Service) RunQuery(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datasourceInfo) (*backend.QueryDataResponse, error) {
@@ -45,11 +47,24 @@ func (s *Service) RunQuery(ctx context.Context, req *backend.QueryDataRequest, d
 			continue
 		}
 
-		graphiteQueries[query.RefID] = queryModel{
+		model := queryModel{
 			req:       graphiteReq,
 			formData:  formData,
 			rawTarget: target,
 		}
+		if dsInfo.EmulateFunctions {
+			// report targets that can't run in the backend before querying Graphite
+			parsed, err := parseTarget(target)
+			if err == nil {
+				err = validateExpression(parsed)
+			}
+			if err != nil {
+				result.Responses[query.RefID] = backend.ErrorResponseWithErrorSource(backend.DownstreamError(fmt.Errorf("invalid target: %w", err)))
+				return result, nil
+			}
+			model.expr = parsed
+		}
+		graphiteQueries[query.RefID] = model
 	}
 
 	if len(emptyQueries) != 0 {
@@ -80,6 +95,20 @@ func (s *Service) RunQuery(ctx context.Context, req *backend.QueryDataRequest, d
 			attribute.Int64("datasource_id", dsInfo.Id),
 			attribute.Int64("org_id", req.PluginContext.OrgID), // nolint:staticcheck
 		)
+		if graphiteReq.expr != nil {
+			queryFrames, err := s.evaluateTarget(ctx, dsInfo, graphiteReq, refId)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				span.End()
+				result.Responses[refId] = backend.ErrorResponseWithErrorSource(err)
+				return result, nil
+			}
+			frames = append(frames, queryFrames...)
+			span.End()
+			continue
+		}
+
 		res, err := dsInfo.HTTPClient.Do(graphiteReq.req)
 		if res != nil {
 			span.SetAttributes(attribute.Int("graphite.response.code", res.StatusCode))
@@ -196,13 +225,82 @@ func (s *Service) createGraphiteRequest(ctx context.Context, query backend.DataQ
 	return graphiteReq, formData, emptyQuery, target, nil
 }
 
+// evaluateTarget runs the functions of the target in the backend, on the raw series of its paths
+// rendered by Graphite
+func (s *Service) evaluateTarget(ctx context.Context, dsInfo *datasourceInfo, model queryModel, refId string) (data.Frames, error) {
+	var fetchErr error
+	ev := &evaluator{
+		fetched: map[string][]TargetResponseDTO{},
+		fetch: func(ctx context.Context, target string, bootstrap time.Duration) ([]TargetResponseDTO, error) {
+			series, err := s.renderTarget(ctx, dsInfo, model, target, bootstrap)
+			if err != nil {
+				fetchErr = err
+			}
+			return series, err
+		},
+	}
+
+	if from, err := strconv.ParseInt(model.formData.Get("from"), 10, 64); err == nil {
+		ev.from = time.Unix(from, 0)
+	}
+
+	series, err := ev.eval(ctx, model.expr)
+	if err != nil {
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return nil, backend.DownstreamError(err)
+	}
+	return s.seriesToDataFrames(series, refId)
+}
+
+// renderTarget renders a single target with the same request as the query, starting bootstrap earlier.
+// The maximum number of data points grows with the range, so that Graphite consolidates the points
+// of the longer range to the same step.
+func (s *Service) renderTarget(ctx context.Context, dsInfo *datasourceInfo, model queryModel, target string, bootstrap time.Duration) ([]TargetResponseDTO, error) {
+	formData := url.Values{}
+	for key, values := range model.formData {
+		formData[key] = values
+	}
+	formData["target"] = []string{target}
+
+	if bootstrap > 0 {
+		from, errFrom := strconv.ParseInt(formData.Get("from"), 10, 64)
+		until, errUntil := strconv.ParseInt(formData.Get("until"), 10, 64)
+		if errFrom != nil || errUntil != nil {
+			return nil, backend.PluginError(fmt.Errorf("failed to extend the range of %s: invalid time range", target))
+		}
+		extended := from - int64(bootstrap/time.Second)
+		formData.Set("from", strconv.FormatInt(extended, 10))
+		if maxDataPoints, err := strconv.ParseInt(formData.Get("maxDataPoints"), 10, 64); err == nil && maxDataPoints > 0 && until > from {
+			formData.Set("maxDataPoints", strconv.FormatInt(maxDataPoints*(until-extended)/(until-from), 10))
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, model.req.Method, model.req.URL.String(), strings.NewReader(formData.Encode()))
+	if err != nil {
+		return nil, backend.PluginError(fmt.Errorf("failed to create request: %w", err))
+	}
+	req.Header = model.req.Header.Clone()
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, backend.DownstreamError(err)
+	}
+	s.logger.Debug("Graphite render", "target", target, "status", res.StatusCode)
+	return s.parseResponse(res)
+}
+
 func (s *Service) toDataFrames(response *http.Response, refId string) (frames data.Frames, error error) {
 	responseData, err := s.parseResponse(response)
 	if err != nil {
 		return nil, err
 	}
+	return s.seriesToDataFrames(responseData, refId)
+}
 
-	frames = data.Frames{}
+func (s *Service) seriesToDataFrames(responseData []TargetResponseDTO, refId string) (data.Frames, error) {
+	frames := data.Frames{}
 	for _, series := range responseData {
 		timeVector := make([]time.Time, 0, len(series.DataPoints))
 		values := make([]*float64, 0, len(series.DataPoints))
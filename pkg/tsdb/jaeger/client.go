@@ -3,6 +3,7 @@ package jaeger
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -134,6 +135,91 @@ func (j *JaegerClient) Operations(ctx context.Context, s string) ([]string, erro
 }
 
 func (j *JaegerClient) Search(ctx context.Context, query *JaegerQuery, start, end int64) (*data.Frame, error) {
+	filter, err := ParseSpanFilter(query.SpanFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	var traces []types.TraceResponse
+	if filter.IsEmpty() {
+		traces, err = j.SearchTraces(ctx, query, start, end)
+	} else {
+		// The filter is applied before the limit, so the traces matching it are searched page by page
+		limit := query.Limit
+		if limit <= 0 {
+			limit = searchDefaultLimit
+		}
+		traces, err = j.SearchAllTraces(ctx, query, start, end, filter.MatchTrace, limit)
+		if errors.Is(err, errTooManyTraces) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	frames := utils.TransformSearchResponse(traces, j.settings.UID, j.settings.Name)
+	return frames, nil
+}
+
+// searchPageSize is the number of traces requested at once when paging through the traces of a time range
+const searchPageSize = 1000
+
+// searchMaxTraces bounds the number of traces read when paging through the traces of a time range,
+// which takes a request to Jaeger for each page
+const searchMaxTraces = 5000
+
+// searchDefaultLimit is the limit of Jaeger when a search has none
+const searchDefaultLimit = 100
+
+var errTooManyTraces = backend.DownstreamErrorf("the time range has more than %d traces, narrow the search", searchMaxTraces)
+
+// SearchAllTraces pages through the traces matching the query, newest first, and returns those kept by the
+// function, up to limit traces. A limit of 0 keeps the traces of the whole time range. Jaeger has no offset,
+// so each page ends where the oldest trace of the previous page starts. When the time range has more than
+// searchMaxTraces traces, the traces found so far are returned with errTooManyTraces.
+func (j *JaegerClient) SearchAllTraces(ctx context.Context, query *JaegerQuery, start, end int64, keep func(types.TraceResponse) bool, limit int) ([]types.TraceResponse, error) {
+	pageQuery := *query
+	pageQuery.Limit = searchPageSize
+
+	seen := map[string]bool{}
+	var kept []types.TraceResponse
+	for {
+		page, err := j.SearchTraces(ctx, &pageQuery, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		oldest := end
+		for _, trace := range page {
+			if seen[trace.TraceID] {
+				continue
+			}
+			seen[trace.TraceID] = true
+			if len(seen) > searchMaxTraces {
+				return kept, errTooManyTraces
+			}
+			for _, span := range trace.Spans {
+				oldest = min(oldest, span.StartTime)
+			}
+			if keep(trace) {
+				kept = append(kept, trace)
+				if limit > 0 && len(kept) == limit {
+					return kept, nil
+				}
+			}
+		}
+
+		// The last page, or a page of traces all starting at its end, which can not be paged further
+		if len(page) < pageQuery.Limit || oldest >= end || oldest <= start {
+			return kept, nil
+		}
+		end = oldest
+	}
+}
+
+// SearchTraces returns the traces matching the service, operation, tags and durations of the query.
+func (j *JaegerClient) SearchTraces(ctx context.Context, query *JaegerQuery, start, end int64) ([]types.TraceResponse, error) {
 	u, err := url.JoinPath(j.url, "/api/traces")
 	if err != nil {
 		return nil, backend.DownstreamErrorf("failed to join url path: %w", err)
@@ -222,8 +308,7 @@ func (j *JaegerClient) Search(ctx context.Context, query *JaegerQuery, start, en
 		return nil, backend.DownstreamErrorf("failed to unmarshal Jaeger response: %w", err)
 	}
 
-	frames := utils.TransformSearchResponse(result.Data, j.settings.UID, j.settings.Name)
-	return frames, nil
+	return result.Data, nil
 }
 
 func (j *JaegerClient) Trace(ctx context.Context, traceID string, start, end int64, refID string) (*data.Frame, error) {
@@ -1,15 +1,20 @@
 package jaeger
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/jaeger/types"
 )
 
 func TestJaegerClient_Services(t *testing.T) {
@@ -279,6 +284,52 @@ func TestJaegerClient_Search(t *testing.T) {
 	}
 }
 
+func TestJaegerClient_SearchAllTraces(t *testing.T) {
+	// A trace starting at each microsecond from 1 to 2500, returned newest first like Jaeger
+	const total = 2500
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		end, _ := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var traces []types.TraceResponse
+		for start := min(end, total); start >= 1 && len(traces) < limit; start-- {
+			id := strconv.FormatInt(start, 10)
+			traces = append(traces, types.TraceResponse{
+				TraceID: id,
+				Spans:   []types.Span{{TraceID: id, SpanID: id, StartTime: start}},
+			})
+		}
+		_ = json.NewEncoder(w).Encode(types.TracesResponse{Data: traces})
+	}))
+	defer server.Close()
+
+	client, err := New(server.Client(), log.NewNullLogger(), backend.DataSourceInstanceSettings{URL: server.URL})
+	require.NoError(t, err)
+	all := func(types.TraceResponse) bool { return true }
+
+	t.Run("pages through the whole time range", func(t *testing.T) {
+		requests = 0
+		traces, err := client.SearchAllTraces(t.Context(), &JaegerQuery{Service: "api", Limit: 20}, 1, total+1, all, 0)
+		require.NoError(t, err)
+		require.Len(t, traces, total)
+		require.Equal(t, 3, requests)
+	})
+
+	t.Run("stops once limit traces are kept", func(t *testing.T) {
+		requests = 0
+		even := func(trace types.TraceResponse) bool {
+			id, _ := strconv.Atoi(trace.TraceID)
+			return id%2 == 0 && id < 1200
+		}
+		traces, err := client.SearchAllTraces(t.Context(), &JaegerQuery{Service: "api"}, 1, total+1, even, 5)
+		require.NoError(t, err)
+		require.Len(t, traces, 5)
+		require.Equal(t, "1198", traces[0].TraceID)
+		require.Equal(t, 2, requests)
+	})
+}
+
 func TestJaegerClient_Trace(t *testing.T) {
 	tests := []struct {
 		name           string
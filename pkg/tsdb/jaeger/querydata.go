@@ -21,6 +21,10 @@ type JaegerQuery struct {
 	MinDuration string `json:"minDuration"`
 	MaxDuration string `json:"maxDuration"`
 	Limit       int    `json:"limit"`
+	// SpanFilter is a TraceQL-like filter of the spans of search and span metrics queries
+	SpanFilter string `json:"spanFilter"`
+	// Metric is the span metric of span metrics queries, all the metrics if empty
+	Metric string `json:"metric"`
 }
 
 func queryData(ctx context.Context, dsInfo *datasourceInfo, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
@@ -80,6 +84,17 @@ func queryData(ctx context.Context, dsInfo *datasourceInfo, req *backend.QueryDa
 			}
 		}
 
+		if query.QueryType == "spanMetrics" {
+			frames, err := querySpanMetrics(ctx, dsInfo, &query, q)
+			if err != nil {
+				response.Responses[q.RefID] = backend.ErrorResponseWithErrorSource(err)
+				continue
+			}
+			response.Responses[q.RefID] = backend.DataResponse{
+				Frames: frames,
+			}
+		}
+
 		if query.QueryType == "dependencyGraph" {
 			// TODO: enable routing to gRPC when ready, currently pending on: https://github.com/jaegertracing/jaeger/issues/7595
 			dependencies, err := dsInfo.JaegerClient.Dependencies(ctx, q.TimeRange.From.UnixMilli(), q.TimeRange.To.UnixMilli())
@@ -102,6 +117,22 @@ func queryData(ctx context.Context, dsInfo *datasourceInfo, req *backend.QueryDa
 	return response, nil
 }
 
+// querySpanMetrics computes the RED metrics of the spans of the traces matching the query, by service
+// and operation. The metrics cover every trace of the time range, so the query has no limit; a time range
+// with too many traces fails rather than returning metrics of part of them.
+func querySpanMetrics(ctx context.Context, dsInfo *datasourceInfo, query *JaegerQuery, q backend.DataQuery) (data.Frames, error) {
+	filter, err := ParseSpanFilter(query.SpanFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	traces, err := dsInfo.JaegerClient.SearchAllTraces(ctx, query, q.TimeRange.From.UnixMicro(), q.TimeRange.To.UnixMicro(), filter.MatchTrace, 0)
+	if err != nil {
+		return nil, err
+	}
+	return transformSpanMetrics(traces, query, filter, q.TimeRange.From, q.TimeRange.To, spanMetricsStep(q), q.RefID)
+}
+
 func transformDependenciesResponse(dependencies types.DependenciesResponse, refID string) []*data.Frame {
 	// Create nodes frame
 	nodesFrame := data.NewFrame(refID+"_nodes",
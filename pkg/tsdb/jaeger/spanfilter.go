@@ -0,0 +1,370 @@
+package jaeger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/jaeger/types"
+)
+
+// Span statuses, derived from the error and otel.status_code tags of Jaeger spans
+const (
+	spanStatusError = "error"
+	spanStatusOk    = "ok"
+	spanStatusUnset = "unset"
+)
+
+// SpanFilter selects spans with a subset of TraceQL, e.g.
+//
+//	{ resource.service.name = "api" && duration > 100ms && status = error }
+//
+// Conditions compare an intrinsic (name, service, duration, status, kind), a span tag (span.<key>),
+// a process tag (resource.<key>) or either of them (.<key> or <key>) to a value with =, !=, >, >=,
+// <, <=, =~ or !~. Conditions are combined with && and ||, && binding tighter. Regular expressions
+// are fully anchored.
+type SpanFilter struct {
+	// any of the groups must match, and all the conditions of a group
+	groups [][]spanCondition
+}
+
+// spanFilterToken is a token of a span filter. Quoted strings are never operators or braces.
+type spanFilterToken struct {
+	text   string
+	quoted bool
+}
+
+func (t spanFilterToken) is(s string) bool {
+	return !t.quoted && t.text == s
+}
+
+type spanCondition struct {
+	attribute string
+	op        string
+	value     string
+	number    *float64
+	regex     *regexp.Regexp
+}
+
+// ParseSpanFilter parses a span filter. An empty filter matches all spans.
+func ParseSpanFilter(s string) (SpanFilter, error) {
+	tokens, err := tokenizeSpanFilter(s)
+	if err != nil {
+		return SpanFilter{}, backend.DownstreamErrorf("invalid span filter: %w", err)
+	}
+	if len(tokens) > 0 && tokens[0].is("{") {
+		if !tokens[len(tokens)-1].is("}") {
+			return SpanFilter{}, backend.DownstreamErrorf("invalid span filter: missing closing brace")
+		}
+		tokens = tokens[1 : len(tokens)-1]
+	}
+
+	var filter SpanFilter
+	if len(tokens) == 0 {
+		return filter, nil
+	}
+
+	group := []spanCondition{}
+	for len(tokens) > 0 {
+		if len(tokens) < 3 {
+			return SpanFilter{}, backend.DownstreamErrorf("invalid span filter: incomplete condition at %q", tokens[0].text)
+		}
+		cond, err := newSpanCondition(tokens[0], tokens[1], tokens[2])
+		if err != nil {
+			return SpanFilter{}, backend.DownstreamErrorf("invalid span filter: %w", err)
+		}
+		group = append(group, cond)
+		tokens = tokens[3:]
+		if len(tokens) == 0 {
+			break
+		}
+
+		switch {
+		case tokens[0].is("&&"):
+		case tokens[0].is("||"):
+			filter.groups = append(filter.groups, group)
+			group = []spanCondition{}
+		default:
+			return SpanFilter{}, backend.DownstreamErrorf("invalid span filter: expected && or || but got %q", tokens[0].text)
+		}
+		tokens = tokens[1:]
+		if len(tokens) == 0 {
+			return SpanFilter{}, backend.DownstreamErrorf("invalid span filter: missing condition after operator")
+		}
+	}
+	filter.groups = append(filter.groups, group)
+	return filter, nil
+}
+
+func newSpanCondition(attributeToken, opToken, valueToken spanFilterToken) (spanCondition, error) {
+	attribute, op, value := attributeToken.text, opToken.text, valueToken.text
+	cond := spanCondition{attribute: attribute, op: op, value: value}
+	if attributeToken.quoted || !isSpanFilterWord(attribute) {
+		return cond, fmt.Errorf("expected an attribute but got %q", attribute)
+	}
+	if !valueToken.quoted && !isSpanFilterWord(value) {
+		return cond, fmt.Errorf("expected a value after %s %s but got %q", attribute, op, value)
+	}
+	if opToken.quoted {
+		op = ""
+	}
+
+	switch op {
+	case "=", "!=":
+	case ">", ">=", "<", "<=":
+		if attribute == "status" || attribute == "kind" {
+			return cond, fmt.Errorf("%s can't be compared with %s", attribute, op)
+		}
+	case "=~", "!~":
+		if attribute == "duration" {
+			return cond, fmt.Errorf("duration can't be compared with %s", op)
+		}
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return cond, fmt.Errorf("invalid regular expression %q: %w", value, err)
+		}
+		cond.regex = re
+		return cond, nil
+	default:
+		return cond, fmt.Errorf("expected an operator after %s but got %q", attribute, op)
+	}
+
+	switch attribute {
+	case "duration":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return cond, fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+		micros := float64(d.Microseconds())
+		cond.number = &micros
+	case "status":
+		if value != spanStatusError && value != spanStatusOk && value != spanStatusUnset {
+			return cond, fmt.Errorf("status must be one of %s, %s or %s but got %q", spanStatusError, spanStatusOk, spanStatusUnset, value)
+		}
+	default:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			cond.number = &f
+		} else if op != "=" && op != "!=" {
+			return cond, fmt.Errorf("%s must be compared to a number with %s but got %q", attribute, op, value)
+		}
+	}
+	return cond, nil
+}
+
+// Match returns true if the span matches the filter. process is the process of the span.
+func (f SpanFilter) Match(span types.Span, process types.TraceProcess) bool {
+	if len(f.groups) == 0 {
+		return true
+	}
+	for _, group := range f.groups {
+		matched := true
+		for _, cond := range group {
+			if !cond.match(span, process) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty returns true for the filter matching every span
+func (f SpanFilter) IsEmpty() bool {
+	return len(f.groups) == 0
+}
+
+// MatchTrace returns true when at least one span of the trace matches the filter
+func (f SpanFilter) MatchTrace(trace types.TraceResponse) bool {
+	for _, span := range trace.Spans {
+		if f.Match(span, trace.Processes[span.ProcessID]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c spanCondition) match(span types.Span, process types.TraceProcess) bool {
+	v, ok := spanAttribute(c.attribute, span, process)
+	if !ok {
+		return false
+	}
+
+	switch c.op {
+	case "=~":
+		return c.regex.MatchString(fmt.Sprint(v))
+	case "!~":
+		return !c.regex.MatchString(fmt.Sprint(v))
+	}
+
+	if c.number != nil {
+		if n, ok := toFloat(v); ok {
+			switch c.op {
+			case "=":
+				return n == *c.number
+			case "!=":
+				return n != *c.number
+			case ">":
+				return n > *c.number
+			case ">=":
+				return n >= *c.number
+			case "<":
+				return n < *c.number
+			case "<=":
+				return n <= *c.number
+			}
+		}
+	}
+
+	switch c.op {
+	case "=":
+		return fmt.Sprint(v) == c.value
+	case "!=":
+		return fmt.Sprint(v) != c.value
+	}
+	return false
+}
+
+// spanAttribute returns the value of an attribute of the span, and false if the span does not have it.
+func spanAttribute(attribute string, span types.Span, process types.TraceProcess) (any, bool) {
+	switch attribute {
+	case "name":
+		return span.OperationName, true
+	case "service", "resource.service.name":
+		return process.ServiceName, true
+	case "duration":
+		return span.Duration, true
+	case "status":
+		return spanStatus(span), true
+	case "kind":
+		if kind, ok := tagValue(span.Tags, "span.kind"); ok {
+			return kind, true
+		}
+		return "unspecified", true
+	}
+
+	if key, ok := strings.CutPrefix(attribute, "span."); ok {
+		return tagValue(span.Tags, key)
+	}
+	if key, ok := strings.CutPrefix(attribute, "resource."); ok {
+		return tagValue(process.Tags, key)
+	}
+	key := strings.TrimPrefix(attribute, ".")
+	if v, ok := tagValue(span.Tags, key); ok {
+		return v, true
+	}
+	return tagValue(process.Tags, key)
+}
+
+// spanStatus returns the status of the span, an error if it has the error tag of Jaeger or the
+// error status code of OpenTelemetry.
+func spanStatus(span types.Span) string {
+	if v, ok := tagValue(span.Tags, "error"); ok && fmt.Sprint(v) == "true" {
+		return spanStatusError
+	}
+	if v, ok := tagValue(span.Tags, "otel.status_code"); ok {
+		switch strings.ToUpper(fmt.Sprint(v)) {
+		case "ERROR":
+			return spanStatusError
+		case "OK":
+			return spanStatusOk
+		}
+	}
+	return spanStatusUnset
+}
+
+func tagValue(tags []types.KeyValueType, key string) (any, bool) {
+	for _, tag := range tags {
+		if tag.Key == key {
+			return tag.Value, true
+		}
+	}
+	return nil, false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// isSpanFilterWord returns true if the unquoted token is an attribute or a value.
+func isSpanFilterWord(s string) bool {
+	switch s {
+	case "{", "}", "&&", "||", "=", "!=", ">", ">=", "<", "<=", "=~", "!~", "":
+		return false
+	}
+	return true
+}
+
+// tokenizeSpanFilter splits a span filter into braces, operators, attributes and values. Quoted
+// strings are unquoted.
+func tokenizeSpanFilter(s string) ([]spanFilterToken, error) {
+	var tokens []spanFilterToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '{' || c == '}':
+			tokens = append(tokens, spanFilterToken{text: string(c)})
+			i++
+		case c == '"' || c == '`':
+			end := i + 1
+			for end < len(s) && s[end] != c {
+				if s[end] == '\\' && c == '"' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated string %s", s[i:])
+			}
+			value := s[i+1 : end]
+			if c == '"' {
+				unquoted, err := strconv.Unquote(s[i : end+1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid string %s: %w", s[i:end+1], err)
+				}
+				value = unquoted
+			}
+			tokens = append(tokens, spanFilterToken{text: value, quoted: true})
+			i = end + 1
+		case strings.ContainsRune("=!<>&|", rune(c)):
+			op := string(c)
+			if i+1 < len(s) && strings.ContainsRune("=~&|", rune(s[i+1])) {
+				op = s[i : i+2]
+			}
+			switch op {
+			case "=", "!=", ">", ">=", "<", "<=", "=~", "!~", "&&", "||":
+			default:
+				return nil, fmt.Errorf("unknown operator %q", op)
+			}
+			tokens = append(tokens, spanFilterToken{text: op})
+			i += len(op)
+		default:
+			end := i
+			for end < len(s) && !unicode.IsSpace(rune(s[end])) && !strings.ContainsRune("{}\"`=!<>&|", rune(s[end])) {
+				end++
+			}
+			tokens = append(tokens, spanFilterToken{text: s[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
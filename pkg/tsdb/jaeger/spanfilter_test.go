@@ -0,0 +1,81 @@
+package jaeger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/jaeger/types"
+)
+
+func TestSpanFilter(t *testing.T) {
+	process := types.TraceProcess{
+		ServiceName: "api",
+		Tags:        []types.KeyValueType{{Key: "region", Type: "string", Value: "eu"}},
+	}
+	span := types.Span{
+		OperationName: "GET /users",
+		Duration:      150000,
+		Tags: []types.KeyValueType{
+			{Key: "http.status_code", Type: "int64", Value: float64(500)},
+			{Key: "error", Type: "bool", Value: true},
+			{Key: "span.kind", Type: "string", Value: "server"},
+		},
+	}
+
+	tests := []struct {
+		filter  string
+		matches bool
+	}{
+		{filter: "", matches: true},
+		{filter: "{}", matches: true},
+		{filter: `{ resource.service.name = "api" }`, matches: true},
+		{filter: `{ service = "web" }`, matches: false},
+		{filter: `{ name =~ "GET .*" && kind = server }`, matches: true},
+		{filter: `{ name =~ "GET" }`, matches: false},
+		{filter: `{ name !~ "POST .*" }`, matches: true},
+		{filter: `{ duration > 100ms && duration <= 150ms }`, matches: true},
+		{filter: `{ duration > 1s }`, matches: false},
+		{filter: `{ status = error }`, matches: true},
+		{filter: `{ status = ok }`, matches: false},
+		{filter: `{ span.http.status_code >= 500 }`, matches: true},
+		{filter: `{ .http.status_code = 500 && resource.region = "eu" }`, matches: true},
+		{filter: `{ region = "us" }`, matches: false},
+		{filter: `{ span.region = "eu" }`, matches: false},
+		{filter: `{ missing != "x" }`, matches: false},
+		{filter: `{ service = "web" || status = error && duration < 1s }`, matches: true},
+		{filter: `{ service = "web" && status = error || duration > 1s }`, matches: false},
+		{filter: `{ name = "&&" || service = "api" }`, matches: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := ParseSpanFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, filter.Match(span, process))
+		})
+	}
+}
+
+func TestSpanFilterErrors(t *testing.T) {
+	for _, filter := range []string{
+		`{ service = "api"`,
+		`{ service = }`,
+		`{ service "api" }`,
+		`{ service = "api" && }`,
+		`{ service = "api" duration > 1s }`,
+		`{ duration > 100 }`,
+		`{ duration =~ "1.*" }`,
+		`{ status = failed }`,
+		`{ status > ok }`,
+		`{ name =~ "(" }`,
+		`{ http.status_code > high }`,
+		`{ service = "api }`,
+		`{ service & "api" }`,
+	} {
+		t.Run(filter, func(t *testing.T) {
+			_, err := ParseSpanFilter(filter)
+			assert.Error(t, err)
+		})
+	}
+}
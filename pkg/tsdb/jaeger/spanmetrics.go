@@ -0,0 +1,202 @@
+package jaeger
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/jaeger/types"
+)
+
+// Span metrics, the RED metrics of the spans of each service and operation
+const (
+	spanMetricRate        = "rate"
+	spanMetricErrorRate   = "errorRate"
+	spanMetricErrorRatio  = "errorRatio"
+	spanMetricDurationP50 = "p50"
+	spanMetricDurationP90 = "p90"
+	spanMetricDurationP95 = "p95"
+	spanMetricDurationP99 = "p99"
+)
+
+var spanMetrics = []string{
+	spanMetricRate,
+	spanMetricErrorRate,
+	spanMetricErrorRatio,
+	spanMetricDurationP50,
+	spanMetricDurationP90,
+	spanMetricDurationP95,
+	spanMetricDurationP99,
+}
+
+var spanMetricQuantiles = map[string]float64{
+	spanMetricDurationP50: 0.5,
+	spanMetricDurationP90: 0.9,
+	spanMetricDurationP95: 0.95,
+	spanMetricDurationP99: 0.99,
+}
+
+// spanMetricsDefaultStep is the step of the span metrics when the query has no interval
+const spanMetricsDefaultStep = time.Minute
+
+// spanGroup holds the spans of a service and operation, by step
+type spanGroup struct {
+	service   string
+	operation string
+	counts    []float64
+	errors    []float64
+	durations [][]float64
+}
+
+// spanMetricsStep returns the step of the span metrics of the query, the interval of the query
+// widened so that the time range has at most MaxDataPoints steps.
+func spanMetricsStep(q backend.DataQuery) time.Duration {
+	step := q.Interval
+	if step <= 0 {
+		step = spanMetricsDefaultStep
+	}
+	if q.MaxDataPoints > 0 {
+		if minStep := q.TimeRange.Duration() / time.Duration(q.MaxDataPoints); step < minStep {
+			step = (minStep + time.Second - 1).Truncate(time.Second)
+		}
+	}
+	return step
+}
+
+// transformSpanMetrics computes the metrics of the spans of the traces that match the filter and start
+// within the time range, grouped by service and operation. Each metric of each group is a frame with
+// a value for each step of the time range.
+//
+// Jaeger returns the traces with a span of the service and operation of the query, and these traces
+// only hold part of the spans of the other services and operations, so only the spans of the service
+// and operation of the query are counted.
+func transformSpanMetrics(traces []types.TraceResponse, query *JaegerQuery, filter SpanFilter, from, to time.Time, step time.Duration, refID string) (data.Frames, error) {
+	metrics := spanMetrics
+	if query.Metric != "" {
+		if !slices.Contains(spanMetrics, query.Metric) {
+			return nil, backend.DownstreamErrorf("unsupported span metric %q, supported metrics are %s", query.Metric, strings.Join(spanMetrics, ", "))
+		}
+		metrics = []string{query.Metric}
+	}
+
+	start := from.Truncate(step)
+	steps := int(to.Sub(start)/step) + 1
+	groups := map[[2]string]*spanGroup{}
+	for _, trace := range traces {
+		for _, span := range trace.Spans {
+			startTime := time.UnixMicro(span.StartTime)
+			if startTime.Before(from) || !startTime.Before(to) {
+				continue
+			}
+			process := trace.Processes[span.ProcessID]
+			if query.Service != "" && process.ServiceName != query.Service {
+				continue
+			}
+			if query.Operation != "" && span.OperationName != query.Operation {
+				continue
+			}
+			if !filter.Match(span, process) {
+				continue
+			}
+
+			key := [2]string{process.ServiceName, span.OperationName}
+			group, ok := groups[key]
+			if !ok {
+				group = &spanGroup{
+					service:   process.ServiceName,
+					operation: span.OperationName,
+					counts:    make([]float64, steps),
+					errors:    make([]float64, steps),
+					durations: make([][]float64, steps),
+				}
+				groups[key] = group
+			}
+
+			idx := int(startTime.Sub(start) / step)
+			group.counts[idx]++
+			if spanStatus(span) == spanStatusError {
+				group.errors[idx]++
+			}
+			group.durations[idx] = append(group.durations[idx], float64(span.Duration))
+		}
+	}
+
+	sorted := make([]*spanGroup, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, group)
+	}
+	slices.SortFunc(sorted, func(a, b *spanGroup) int {
+		if c := strings.Compare(a.service, b.service); c != 0 {
+			return c
+		}
+		return strings.Compare(a.operation, b.operation)
+	})
+
+	times := make([]time.Time, steps)
+	for i := range times {
+		times[i] = start.Add(time.Duration(i) * step)
+	}
+
+	frames := make(data.Frames, 0, len(sorted)*len(metrics))
+	for _, group := range sorted {
+		for _, m := range metrics {
+			frames = append(frames, spanMetricFrame(group, m, times, step, refID))
+		}
+	}
+	return frames, nil
+}
+
+func spanMetricFrame(group *spanGroup, metric string, times []time.Time, step time.Duration, refID string) *data.Frame {
+	values := make([]*float64, len(times))
+	config := &data.FieldConfig{}
+	for i := range times {
+		var v *float64
+		switch metric {
+		case spanMetricRate:
+			config.Unit = "reqps"
+			v = ptr(group.counts[i] / step.Seconds())
+		case spanMetricErrorRate:
+			config.Unit = "reqps"
+			v = ptr(group.errors[i] / step.Seconds())
+		case spanMetricErrorRatio:
+			config.Unit = "percentunit"
+			if group.counts[i] > 0 {
+				v = ptr(group.errors[i] / group.counts[i])
+			}
+		default:
+			config.Unit = "µs"
+			if len(group.durations[i]) > 0 {
+				v = ptr(quantile(group.durations[i], spanMetricQuantiles[metric]))
+			}
+		}
+		values[i] = v
+	}
+
+	labels := data.Labels{"service": group.service, "operation": group.operation}
+	frame := data.NewFrame(refID,
+		data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+		data.NewField(metric, labels, values).SetConfig(config),
+	)
+	frame.Meta = &data.FrameMeta{
+		Type:        data.FrameTypeTimeSeriesMulti,
+		TypeVersion: data.FrameTypeVersion{0, 1},
+	}
+	return frame
+}
+
+// quantile returns the q-quantile of the values, interpolating linearly between the closest ranks.
+// The values are sorted in place.
+func quantile(values []float64, q float64) float64 {
+	slices.Sort(values)
+	rank := q * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+}
+
+func ptr(f float64) *float64 {
+	return &f
+}
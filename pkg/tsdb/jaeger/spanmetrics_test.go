@@ -0,0 +1,121 @@
+package jaeger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/jaeger/types"
+)
+
+func TestTransformSpanMetrics(t *testing.T) {
+	from := time.Unix(600, 0)
+	to := time.Unix(720, 0)
+	errorTag := []types.KeyValueType{{Key: "error", Type: "bool", Value: true}}
+	span := func(operation, processID string, start time.Time, duration time.Duration, tags []types.KeyValueType) types.Span {
+		return types.Span{
+			OperationName: operation,
+			ProcessID:     processID,
+			StartTime:     start.UnixMicro(),
+			Duration:      duration.Microseconds(),
+			Tags:          tags,
+		}
+	}
+	traces := []types.TraceResponse{
+		{
+			Processes: map[string]types.TraceProcess{"p1": {ServiceName: "api"}, "p2": {ServiceName: "db"}},
+			Spans: []types.Span{
+				span("GET", "p1", from.Add(time.Second), 100*time.Millisecond, nil),
+				span("GET", "p1", from.Add(2*time.Second), 200*time.Millisecond, errorTag),
+				span("GET", "p1", from.Add(3*time.Second), 300*time.Millisecond, nil),
+				span("GET", "p1", from.Add(4*time.Second), 400*time.Millisecond, nil),
+				span("SELECT", "p2", from.Add(70*time.Second), 50*time.Millisecond, nil),
+				// outside of the time range
+				span("GET", "p1", from.Add(-time.Second), time.Second, errorTag),
+				span("GET", "p1", to, time.Second, errorTag),
+			},
+		},
+	}
+
+	t.Run("computes the metrics of each service and operation", func(t *testing.T) {
+		frames, err := transformSpanMetrics(traces, &JaegerQuery{}, SpanFilter{}, from, to, time.Minute, "A")
+		require.NoError(t, err)
+		require.Len(t, frames, 2*len(spanMetrics))
+
+		values := map[string][]*float64{}
+		for _, frame := range frames {
+			require.Equal(t, "A", frame.Name)
+			require.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+			require.Len(t, frame.Fields, 2)
+			require.Equal(t, []time.Time{from, from.Add(time.Minute), to}, fieldTimes(frame.Fields[0]))
+
+			field := frame.Fields[1]
+			key := field.Labels["service"] + "/" + field.Labels["operation"] + "/" + field.Name
+			values[key] = fieldValues(field)
+		}
+
+		assert.Equal(t, []*float64{ptr(4.0 / 60), ptr(0), ptr(0)}, values["api/GET/rate"])
+		assert.Equal(t, []*float64{ptr(1.0 / 60), ptr(0), ptr(0)}, values["api/GET/errorRate"])
+		assert.Equal(t, []*float64{ptr(0.25), nil, nil}, values["api/GET/errorRatio"])
+		assert.Equal(t, []*float64{ptr(250000), nil, nil}, values["api/GET/p50"])
+		assert.Equal(t, []*float64{ptr(370000), nil, nil}, values["api/GET/p90"])
+		assert.Equal(t, []*float64{ptr(0), ptr(1.0 / 60), ptr(0)}, values["db/SELECT/rate"])
+		assert.Equal(t, []*float64{nil, ptr(50000), nil}, values["db/SELECT/p99"])
+	})
+
+	t.Run("computes a single metric of the spans matching the filter", func(t *testing.T) {
+		filter, err := ParseSpanFilter(`{ duration >= 200ms }`)
+		require.NoError(t, err)
+
+		frames, err := transformSpanMetrics(traces, &JaegerQuery{Metric: spanMetricErrorRatio}, filter, from, to, time.Minute, "A")
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, data.Labels{"service": "api", "operation": "GET"}, frames[0].Fields[1].Labels)
+		assert.Equal(t, []*float64{ptr(1.0 / 3), nil, nil}, fieldValues(frames[0].Fields[1]))
+	})
+
+	t.Run("only computes the metrics of the service and operation of the query", func(t *testing.T) {
+		frames, err := transformSpanMetrics(traces, &JaegerQuery{Service: "db", Metric: spanMetricRate}, SpanFilter{}, from, to, time.Minute, "A")
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, data.Labels{"service": "db", "operation": "SELECT"}, frames[0].Fields[1].Labels)
+
+		frames, err = transformSpanMetrics(traces, &JaegerQuery{Service: "api", Operation: "POST", Metric: spanMetricRate}, SpanFilter{}, from, to, time.Minute, "A")
+		require.NoError(t, err)
+		require.Empty(t, frames)
+	})
+
+	t.Run("errors on unsupported metrics", func(t *testing.T) {
+		_, err := transformSpanMetrics(traces, &JaegerQuery{Metric: "apdex"}, SpanFilter{}, from, to, time.Minute, "A")
+		require.Error(t, err)
+	})
+}
+
+func TestSpanMetricsStep(t *testing.T) {
+	timeRange := backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(3600, 0)}
+
+	assert.Equal(t, time.Minute, spanMetricsStep(backend.DataQuery{TimeRange: timeRange}))
+	assert.Equal(t, 15*time.Second, spanMetricsStep(backend.DataQuery{TimeRange: timeRange, Interval: 15 * time.Second, MaxDataPoints: 1000}))
+	assert.Equal(t, 36*time.Second, spanMetricsStep(backend.DataQuery{TimeRange: timeRange, Interval: 15 * time.Second, MaxDataPoints: 100}))
+	assert.Equal(t, 2*time.Second, spanMetricsStep(backend.DataQuery{TimeRange: timeRange, Interval: time.Second, MaxDataPoints: 3000}))
+}
+
+func fieldTimes(field *data.Field) []time.Time {
+	times := make([]time.Time, field.Len())
+	for i := range times {
+		times[i] = field.At(i).(time.Time)
+	}
+	return times
+}
+
+func fieldValues(field *data.Field) []*float64 {
+	values := make([]*float64, field.Len())
+	for i := range values {
+		values[i] = field.At(i).(*float64)
+	}
+	return values
+}
@@ -6,6 +6,7 @@ import (
 	"hash/fnv"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -370,3 +371,81 @@ func makeStat(name string, interfaceValue interface{}, unit string) data.QuerySt
 		Value: value,
 	}
 }
+
+// mergeMetricFrames merges the metric frames of a split query. parts holds the frames of every time split
+// in time order, each with the frames of every stream shard. Values of a series in different shards are
+// added together, and timestamps already returned by an earlier time split are skipped.
+func mergeMetricFrames(parts [][]data.Frames) (data.Frames, error) {
+	type series struct {
+		frame  *data.Frame
+		values map[int64]float64
+	}
+
+	merged := []*series{}
+	seriesByLabels := map[string]*series{}
+	for _, shards := range parts {
+		splitValues := map[string]map[int64]float64{}
+		for _, frames := range shards {
+			for _, frame := range frames {
+				// Skip frames without fields
+				if len(frame.Fields) < 2 {
+					continue
+				}
+				if len(frame.Fields) != 2 || frame.Fields[0].Type() != data.FieldTypeTime || frame.Fields[1].Type() != data.FieldTypeFloat64 {
+					return nil, fmt.Errorf("invalid metric frame in split query response")
+				}
+
+				key := frame.Fields[1].Labels.String()
+				if _, ok := seriesByLabels[key]; !ok {
+					s := &series{frame: frame, values: map[int64]float64{}}
+					seriesByLabels[key] = s
+					merged = append(merged, s)
+				}
+				values, ok := splitValues[key]
+				if !ok {
+					values = map[int64]float64{}
+					splitValues[key] = values
+				}
+				for i := 0; i < frame.Rows(); i++ {
+					values[frame.Fields[0].At(i).(time.Time).UnixNano()] += frame.Fields[1].At(i).(float64)
+				}
+			}
+		}
+
+		for key, values := range splitValues {
+			s := seriesByLabels[key]
+			for ts, value := range values {
+				if _, ok := s.values[ts]; !ok {
+					s.values[ts] = value
+				}
+			}
+		}
+	}
+
+	frames := make(data.Frames, 0, len(merged))
+	for _, s := range merged {
+		timestamps := make([]int64, 0, len(s.values))
+		for ts := range s.values {
+			timestamps = append(timestamps, ts)
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		times := make([]time.Time, len(timestamps))
+		values := make([]float64, len(timestamps))
+		for i, ts := range timestamps {
+			times[i] = time.Unix(0, ts).UTC()
+			values[i] = s.values[ts]
+		}
+
+		timeField := data.NewField(s.frame.Fields[0].Name, s.frame.Fields[0].Labels, times)
+		timeField.Config = s.frame.Fields[0].Config
+		valueField := data.NewField(s.frame.Fields[1].Name, s.frame.Fields[1].Labels, values)
+		valueField.Config = s.frame.Fields[1].Config
+
+		frame := data.NewFrame(s.frame.Name, timeField, valueField)
+		frame.RefID = s.frame.RefID
+		frame.Meta = s.frame.Meta
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
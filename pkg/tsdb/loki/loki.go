@@ -77,6 +77,8 @@ type datasourceInfo struct {
 
 	schemaDatasource *schemas.SchemaDatasource
 	schemaProvider   *SchemaProvider
+
+	splitOpts splitOptions
 }
 
 type QueryJSONModel struct {
@@ -112,6 +114,11 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider, logger log.Log
 			return nil, backend.DownstreamError(fmt.Errorf("error creating http client: %w", err))
 		}
 
+		splitOpts, err := parseSplitOptions(settings.JSONData)
+		if err != nil {
+			return nil, backend.DownstreamError(fmt.Errorf("error reading settings: %w", err))
+		}
+
 		var schemaDs *schemas.SchemaDatasource
 		var schemaProv *SchemaProvider
 		grafCfg := config.GrafanaConfigFromContext(ctx)
@@ -133,6 +140,7 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider, logger log.Log
 			streams:          make(map[string]data.FrameJSONCache),
 			schemaDatasource: schemaDs,
 			schemaProvider:   schemaProv,
+			splitOpts:        splitOpts,
 		}
 		return model, nil
 	}
@@ -277,7 +285,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		resultLock := sync.Mutex{}
 		err = concurrency.ForEachJob(ctx, len(queries), 10, func(ctx context.Context, idx int) error {
 			query := queries[idx]
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, dsInfo.splitOpts, tracer, plog)
 
 			resultLock.Lock()
 			defer resultLock.Unlock()
@@ -286,7 +294,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		})
 	} else {
 		for _, query := range queries {
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, dsInfo.splitOpts, tracer, plog)
 			result.Responses[query.RefID] = queryRes
 		}
 	}
@@ -309,7 +317,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 	return result, err
 }
 
-func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, tracer trace.Tracer, plog log.Logger) backend.DataResponse {
+func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, splitOpts splitOptions, tracer trace.Tracer, plog log.Logger) backend.DataResponse {
 	ctx, span := tracer.Start(ctx, "datasource.loki.queryData.runQueries.runQuery", trace.WithAttributes(
 		attribute.Bool("runInParallel", runInParallel),
 		attribute.String("expr", query.Expr),
@@ -322,7 +330,7 @@ func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataR
 
 	defer span.End()
 
-	queryRes, err := runQuery(ctx, api, query, responseOpts, plog)
+	queryRes, err := runSplitQuery(ctx, api, query, responseOpts, splitOpts, plog)
 	if queryRes == nil {
 		// we always want to return a backend.DataResponse object, even if we received just an error
 		queryRes = &backend.DataResponse{}
@@ -346,20 +354,27 @@ func runQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, responseOpts
 		return res, err
 	}
 
+	if err := adjustFrames(res, query, responseOpts, plog); err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func adjustFrames(res *backend.DataResponse, query *lokiQuery, responseOpts ResponseOpts, plog log.Logger) error {
 	for _, frame := range res.Frames {
 		// Skip frames without fields
 		if len(frame.Fields) < 2 {
 			continue
 		}
 
-		err = adjustFrame(frame, query, false, responseOpts.logsDataplane)
+		err := adjustFrame(frame, query, false, responseOpts.logsDataplane)
 		if err != nil {
 			plog.Debug("Error adjusting frame", "error", err)
-			return res, err
+			return err
 		}
 	}
-
-	return res, nil
+	return nil
 }
 
 func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext) (*datasourceInfo, error) {
@@ -0,0 +1,371 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+const (
+	streamShardLabel = "__stream_shard__"
+
+	defaultSplitConcurrency = 5
+	defaultSplitRetries     = 2
+)
+
+// splitRetryBackoff is the wait before the first retry of a failed split, doubled for every following retry
+var splitRetryBackoff = 500 * time.Millisecond
+
+// splitOptions configure how long metric range queries are split in smaller queries
+type splitOptions struct {
+	// Interval is the longest time range of a split, zero disables splitting
+	Interval time.Duration
+	// Sharding additionally splits queries by the stream shards of their stream selector
+	Sharding bool
+	// Concurrency is how many splits of a query run in parallel
+	Concurrency int
+	// Retries is how many times a split failing with a server error is retried
+	Retries int
+}
+
+type splitJSONData struct {
+	QuerySplitInterval    string `json:"querySplitInterval"`
+	QueryShardingEnabled  bool   `json:"queryShardingEnabled"`
+	QuerySplitConcurrency int    `json:"querySplitConcurrency"`
+	QuerySplitRetries     *int   `json:"querySplitRetries"`
+}
+
+func parseSplitOptions(jsonData json.RawMessage) (splitOptions, error) {
+	opts := splitOptions{Concurrency: defaultSplitConcurrency, Retries: defaultSplitRetries}
+	if len(jsonData) == 0 {
+		return opts, nil
+	}
+
+	var model splitJSONData
+	if err := json.Unmarshal(jsonData, &model); err != nil {
+		return opts, fmt.Errorf("failed to parse query splitting settings: %w", err)
+	}
+
+	if model.QuerySplitInterval != "" {
+		interval, err := gtime.ParseIntervalStringToTimeDuration(model.QuerySplitInterval)
+		if err != nil {
+			return opts, fmt.Errorf("invalid query split interval %q: %w", model.QuerySplitInterval, err)
+		}
+		opts.Interval = interval
+	}
+	opts.Sharding = model.QueryShardingEnabled
+	if model.QuerySplitConcurrency > 0 {
+		opts.Concurrency = model.QuerySplitConcurrency
+	}
+	if model.QuerySplitRetries != nil && *model.QuerySplitRetries >= 0 {
+		opts.Retries = *model.QuerySplitRetries
+	}
+	return opts, nil
+}
+
+// runSplitQuery runs metric range queries as several smaller queries, split by time and optionally by
+// stream shard, and merges their frames. Other queries are run as a single query.
+func runSplitQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, responseOpts ResponseOpts, opts splitOptions, plog log.Logger) (*backend.DataResponse, error) {
+	if !opts.Sharding && opts.Interval <= 0 {
+		return runQuery(ctx, api, query, responseOpts, plog)
+	}
+	if query.QueryType != QueryTypeRange || query.Step <= 0 {
+		return runQuery(ctx, api, query, responseOpts, plog)
+	}
+	expr, err := syntax.ParseExpr(query.Expr)
+	if err != nil {
+		return runQuery(ctx, api, query, responseOpts, plog)
+	}
+	sampleExpr, ok := expr.(syntax.SampleExpr)
+	if !ok {
+		// log queries are limited by their number of lines, so they are not split
+		return runQuery(ctx, api, query, responseOpts, plog)
+	}
+
+	timeSplits := splitQueryByTime(query, opts.Interval)
+
+	shardExprs := []string{query.Expr}
+	if opts.Sharding && isShardable(sampleExpr) {
+		shards, err := getStreamShards(ctx, api, sampleExpr, query.Start, query.End)
+		if err != nil {
+			plog.Warn("Failed to get stream shards, running the query without sharding", "error", err)
+		} else if len(shards) > 0 {
+			shardExprs = shardQueryExprs(query.Expr, shards, opts.Concurrency)
+		}
+	}
+
+	if len(timeSplits) == 1 && len(shardExprs) == 1 {
+		return runQuery(ctx, api, query, responseOpts, plog)
+	}
+
+	splits := make([]lokiQuery, 0, len(timeSplits)*len(shardExprs))
+	for _, timeSplit := range timeSplits {
+		for _, shardExpr := range shardExprs {
+			split := *timeSplit
+			split.Expr = shardExpr
+			splits = append(splits, split)
+		}
+	}
+
+	plog.Debug("Running split query", "timeSplits", len(timeSplits), "shards", len(shardExprs), "concurrency", opts.Concurrency)
+
+	results := make([]*backend.DataResponse, len(splits))
+	err = concurrency.ForEachJob(ctx, len(splits), opts.Concurrency, func(ctx context.Context, idx int) error {
+		res, err := runSplitWithRetries(ctx, api, splits[idx], responseOpts, opts.Retries, plog)
+		if err != nil {
+			return err
+		}
+		if res.Error != nil {
+			if res.ErrorSource == backend.ErrorSourceDownstream {
+				return backend.DownstreamError(res.Error)
+			}
+			return backend.PluginError(res.Error)
+		}
+		results[idx] = res
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// results are grouped by time split, each with the responses of every shard
+	parts := make([][]data.Frames, len(timeSplits))
+	for i, res := range results {
+		parts[i/len(shardExprs)] = append(parts[i/len(shardExprs)], res.Frames)
+	}
+
+	frames, err := mergeMetricFrames(parts)
+	if err != nil {
+		return nil, err
+	}
+	if stats := mergeSplitStats(results); len(stats) > 0 {
+		for _, frame := range frames {
+			setFrameStats(frame, stats)
+		}
+	}
+
+	res := &backend.DataResponse{Frames: frames, Status: backend.StatusOK}
+	if err := adjustFrames(res, query, responseOpts, plog); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// mergeSplitStats adds up the Loki statistics of the splits. Every frame of a response carries the statistics
+// of the whole split, so they are read from its first frame. Rates are recomputed from the merged totals.
+func mergeSplitStats(results []*backend.DataResponse) map[string]any {
+	merged := map[string]any{}
+	for _, res := range results {
+		for _, frame := range res.Frames {
+			if frame.Meta == nil {
+				continue
+			}
+			if custom, ok := frame.Meta.Custom.(map[string]any); ok {
+				if stats, ok := custom["stats"].(map[string]any); ok {
+					addStats(merged, stats)
+				}
+			}
+			break
+		}
+	}
+
+	if summary, ok := merged["summary"].(map[string]any); ok {
+		if execTime, ok := summary["execTime"].(float64); ok && execTime > 0 {
+			if bytes, ok := summary["totalBytesProcessed"].(float64); ok {
+				summary["bytesProcessedPerSecond"] = bytes / execTime
+			}
+			if lines, ok := summary["totalLinesProcessed"].(float64); ok {
+				summary["linesProcessedPerSecond"] = lines / execTime
+			}
+		}
+	}
+	return merged
+}
+
+// addStats adds the numeric values of src to the ones of dst, recursing into nested statistics
+func addStats(dst, src map[string]any) {
+	for key, value := range src {
+		switch v := value.(type) {
+		case map[string]any:
+			nested, ok := dst[key].(map[string]any)
+			if !ok {
+				nested = map[string]any{}
+				dst[key] = nested
+			}
+			addStats(nested, v)
+		case float64:
+			prev, _ := dst[key].(float64)
+			dst[key] = prev + v
+		default:
+			if _, ok := dst[key]; !ok {
+				dst[key] = v
+			}
+		}
+	}
+}
+
+// setFrameStats replaces the Loki statistics of the frame, which may share its metadata with other frames
+func setFrameStats(frame *data.Frame, stats map[string]any) {
+	meta := data.FrameMeta{}
+	if frame.Meta != nil {
+		meta = *frame.Meta
+	}
+	custom := map[string]any{}
+	if c, ok := meta.Custom.(map[string]any); ok {
+		maps.Copy(custom, c)
+	}
+	custom["stats"] = stats
+	meta.Custom = custom
+	frame.Meta = &meta
+}
+
+// splitQueryByTime splits the time range of the query. The length of the splits is a multiple of the step,
+// so the splits are evaluated at the same timestamps as the whole query.
+func splitQueryByTime(query *lokiQuery, interval time.Duration) []*lokiQuery {
+	if interval <= 0 || query.End.Sub(query.Start) <= interval {
+		return []*lokiQuery{query}
+	}
+
+	length := max(query.Step, interval/query.Step*query.Step)
+	splits := []*lokiQuery{}
+	for start := query.Start; !start.After(query.End); start = start.Add(length) {
+		split := *query
+		split.Start = start
+		// the last timestamp evaluated by the split
+		split.End = start.Add(length - query.Step)
+		if split.End.After(query.End) {
+			split.End = query.End
+		}
+		splits = append(splits, &split)
+	}
+	return splits
+}
+
+// isShardable reports whether the results of the expression over distinct streams can be added together
+func isShardable(expr syntax.SampleExpr) bool {
+	switch e := expr.(type) {
+	case *syntax.RangeAggregationExpr:
+		// without grouping, every series comes from a single stream, so the series of the shards don't overlap
+		return e.Grouping == nil
+	case *syntax.VectorAggregationExpr:
+		switch e.Operation {
+		case syntax.OpTypeSum:
+			return isShardable(e.Left)
+		case syntax.OpTypeCount:
+			inner, ok := e.Left.(*syntax.RangeAggregationExpr)
+			return ok && inner.Grouping == nil
+		}
+	}
+	return false
+}
+
+// getStreamShards returns the stream shards of the only stream selector of the expression
+func getStreamShards(ctx context.Context, api *LokiAPI, expr syntax.SampleExpr, start, end time.Time) ([]string, error) {
+	var selector *syntax.MatchersExpr
+	selectors := 0
+	expr.Walk(func(e syntax.Expr) bool {
+		if m, ok := e.(*syntax.MatchersExpr); ok {
+			selector = m
+			selectors++
+		}
+		return true
+	})
+	if selectors != 1 {
+		return nil, nil
+	}
+
+	values := url.Values{}
+	values.Set("query", selector.String())
+	values.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	values.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	raw, err := api.RawQuery(ctx, "/loki/api/v1/label/"+streamShardLabel+"/values?"+values.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if raw.Status/100 != 2 {
+		return nil, makeLokiError(raw.Body)
+	}
+
+	var resp struct {
+		Data []string `json:"data"`
+	}
+	if err := json.Unmarshal(raw.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse stream shards: %w", err)
+	}
+
+	sort.Slice(resp.Data, func(i, j int) bool {
+		a, errA := strconv.Atoi(resp.Data[i])
+		b, errB := strconv.Atoi(resp.Data[j])
+		if errA != nil || errB != nil {
+			return resp.Data[i] < resp.Data[j]
+		}
+		return a < b
+	})
+	return resp.Data, nil
+}
+
+// shardQueryExprs returns the expression restricted to groups of stream shards, plus the
+// streams that are not sharded
+func shardQueryExprs(expr string, shards []string, groups int) []string {
+	size := (len(shards) + groups - 1) / max(groups, 1)
+	matchers := []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, streamShardLabel, "")}
+	for i := 0; i < len(shards); i += size {
+		group := shards[i:min(i+size, len(shards))]
+		matchers = append(matchers, labels.MustNewMatcher(labels.MatchRegexp, streamShardLabel, strings.Join(group, "|")))
+	}
+
+	exprs := make([]string, 0, len(matchers))
+	for _, matcher := range matchers {
+		tree, err := syntax.ParseExpr(expr)
+		if err != nil {
+			return []string{expr}
+		}
+		tree.Walk(func(e syntax.Expr) bool {
+			if m, ok := e.(*syntax.MatchersExpr); ok {
+				m.Mts = append(m.Mts, matcher)
+			}
+			return true
+		})
+		exprs = append(exprs, tree.String())
+	}
+	return exprs
+}
+
+// runSplitWithRetries runs a split, retrying it when Loki fails with a server error or is overloaded
+func runSplitWithRetries(ctx context.Context, api *LokiAPI, split lokiQuery, responseOpts ResponseOpts, retries int, plog log.Logger) (*backend.DataResponse, error) {
+	backoff := splitRetryBackoff
+	for attempt := 0; ; attempt++ {
+		res, err := api.DataQuery(ctx, split, responseOpts)
+		if err != nil || res.Error == nil || attempt >= retries || !isRetryableStatus(res.Status) || ctx.Err() != nil {
+			return res, err
+		}
+
+		plog.Debug("Retrying failed split", "attempt", attempt+1, "start", split.Start, "end", split.End, "status", res.Status, "error", res.Error)
+		select {
+		case <-ctx.Done():
+			return res, nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func isRetryableStatus(status backend.Status) bool {
+	// zero means that the request failed before a response was received
+	return status == 0 || status == backend.Status(http.StatusTooManyRequests) || status >= 500
+}
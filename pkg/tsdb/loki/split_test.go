@@ -0,0 +1,217 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/stretchr/testify/require"
+)
+
+type lokiRoundTripperFunc func(req *http.Request) *http.Response
+
+func (f lokiRoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func makeLokiResponse(status int, body string) *http.Response {
+	header := http.Header{}
+	header.Add("Content-Type", "application/json")
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(bytes.NewReader([]byte(body)))}
+}
+
+// fakeSplitLoki answers range queries with a point of value 1 at the start and end of the time range
+// and the same statistics for every query, and stream shard lookups with the shards
+type fakeSplitLoki struct {
+	mu       sync.Mutex
+	shards   []string
+	failures int
+	queries  []string
+}
+
+func (f *fakeSplitLoki) roundTrip(req *http.Request) *http.Response {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if strings.Contains(req.URL.Path, "/label/"+streamShardLabel+"/values") {
+		body, _ := json.Marshal(map[string]any{"status": "success", "data": f.shards})
+		return makeLokiResponse(http.StatusOK, string(body))
+	}
+
+	if f.failures > 0 {
+		f.failures--
+		return makeLokiResponse(http.StatusServiceUnavailable, `{"message":"too busy"}`)
+	}
+
+	qs := req.URL.Query()
+	f.queries = append(f.queries, qs.Get("query"))
+	start, _ := strconv.ParseInt(qs.Get("start"), 10, 64)
+	end, _ := strconv.ParseInt(qs.Get("end"), 10, 64)
+	values := fmt.Sprintf(`[%d, "1"]`, start/int64(time.Second))
+	if end != start {
+		values += fmt.Sprintf(`, [%d, "1"]`, end/int64(time.Second))
+	}
+	return makeLokiResponse(http.StatusOK, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"level":"error"},"values":[`+values+`]}],`+
+		`"stats":{"summary":{"totalBytesProcessed":100,"totalLinesProcessed":10,"execTime":0.5},"store":{"totalChunksRef":2}}}}`)
+}
+
+func makeSplitAPI(f *fakeSplitLoki) *LokiAPI {
+	client := &http.Client{Transport: lokiRoundTripperFunc(f.roundTrip)}
+	return newLokiAPI(client, "http://localhost:3100", backend.NewLoggerWith("logger", "test"), tracing.DefaultTracer())
+}
+
+func TestParseSplitOptions(t *testing.T) {
+	opts, err := parseSplitOptions(nil)
+	require.NoError(t, err)
+	require.Equal(t, splitOptions{Concurrency: defaultSplitConcurrency, Retries: defaultSplitRetries}, opts)
+
+	opts, err = parseSplitOptions([]byte(`{"querySplitInterval":"1d","queryShardingEnabled":true,"querySplitConcurrency":10,"querySplitRetries":0}`))
+	require.NoError(t, err)
+	require.Equal(t, splitOptions{Interval: 24 * time.Hour, Sharding: true, Concurrency: 10, Retries: 0}, opts)
+
+	_, err = parseSplitOptions([]byte(`{"querySplitInterval":"daily"}`))
+	require.Error(t, err)
+}
+
+func TestSplitQueryByTime(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := &lokiQuery{Expr: `rate({job="app"}[5m])`, QueryType: QueryTypeRange, Step: time.Hour, Start: start, End: start.Add(72 * time.Hour)}
+
+	splits := splitQueryByTime(query, 24*time.Hour)
+	require.Len(t, splits, 4)
+	require.Equal(t, query.Start, splits[0].Start)
+	require.Equal(t, query.End, splits[len(splits)-1].End)
+	for i := 1; i < len(splits); i++ {
+		// every timestamp is evaluated by exactly one split
+		require.Equal(t, query.Step, splits[i].Start.Sub(splits[i-1].End))
+	}
+
+	require.Len(t, splitQueryByTime(query, 0), 1)
+	require.Len(t, splitQueryByTime(query, 100*time.Hour), 1)
+}
+
+func TestIsShardable(t *testing.T) {
+	tests := map[string]bool{
+		`rate({job="app"}[5m])`:                                                 true,
+		`sum by (level) (count_over_time({job="app"}[5m]))`:                     true,
+		`sum(sum by (level) (rate({job="app"}[5m])))`:                           true,
+		`count(rate({job="app"}[5m]))`:                                          true,
+		`count(sum by (level) (rate({job="app"}[5m])))`:                         false,
+		`avg(rate({job="app"}[5m]))`:                                            false,
+		`quantile_over_time(0.99, {job="app"} | unwrap latency [5m]) by (path)`: false,
+	}
+	for expr, shardable := range tests {
+		t.Run(expr, func(t *testing.T) {
+			parsed, err := syntax.ParseExpr(expr)
+			require.NoError(t, err)
+			require.Equal(t, shardable, isShardable(parsed.(syntax.SampleExpr)))
+		})
+	}
+}
+
+func TestShardQueryExprs(t *testing.T) {
+	exprs := shardQueryExprs(`sum(rate({job="app"}[5m]))`, []string{"0", "1", "2", "3", "4"}, 2)
+	require.Len(t, exprs, 3)
+	require.Contains(t, exprs[0], `__stream_shard__=""`)
+	require.Contains(t, exprs[1], `__stream_shard__=~"0|1|2"`)
+	require.Contains(t, exprs[2], `__stream_shard__=~"3|4"`)
+	for _, expr := range exprs {
+		require.Contains(t, expr, `job="app"`)
+		_, err := syntax.ParseExpr(expr)
+		require.NoError(t, err)
+	}
+}
+
+func TestRunSplitQuery(t *testing.T) {
+	splitRetryBackoff = 0
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newQuery := func(expr string) *lokiQuery {
+		return &lokiQuery{Expr: expr, QueryType: QueryTypeRange, Direction: DirectionBackward, Step: time.Hour, Start: start, End: start.Add(72 * time.Hour), RefID: "A"}
+	}
+	logger := backend.NewLoggerWith("logger", "test")
+
+	t.Run("splits by time", func(t *testing.T) {
+		loki := &fakeSplitLoki{}
+		res, err := runSplitQuery(context.Background(), makeSplitAPI(loki), newQuery(`sum(rate({job="app"}[5m]))`), ResponseOpts{}, splitOptions{Interval: 24 * time.Hour, Concurrency: 2}, logger)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, loki.queries, 4)
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 7, frame.Rows())
+		require.Equal(t, start, frame.Fields[0].At(0).(time.Time))
+		require.Equal(t, start.Add(72*time.Hour), frame.Fields[0].At(6).(time.Time))
+		require.Equal(t, 1.0, frame.Fields[1].At(0).(float64))
+		require.Equal(t, "Expr: "+`sum(rate({job="app"}[5m]))`+"\nStep: 1h0m0s", frame.Meta.ExecutedQueryString)
+	})
+
+	t.Run("adds up the statistics of the splits", func(t *testing.T) {
+		loki := &fakeSplitLoki{}
+		res, err := runSplitQuery(context.Background(), makeSplitAPI(loki), newQuery(`sum(rate({job="app"}[5m]))`), ResponseOpts{}, splitOptions{Interval: 24 * time.Hour, Concurrency: 2}, logger)
+		require.NoError(t, err)
+		require.Len(t, loki.queries, 4)
+
+		stats := map[string]float64{}
+		for _, stat := range res.Frames[0].Meta.Stats {
+			stats[stat.DisplayName] = stat.Value
+		}
+		require.Equal(t, 400.0, stats["Summary: total bytes processed"])
+		require.Equal(t, 40.0, stats["Summary: total lines processed"])
+		require.Equal(t, 2.0, stats["Summary: exec time"])
+		require.Equal(t, 200.0, stats["Summary: bytes processed per second"])
+		require.Equal(t, 20.0, stats["Summary: lines processed per second"])
+		require.Equal(t, 8.0, stats["Store: total chunks ref"])
+	})
+
+	t.Run("adds up the values of the stream shards", func(t *testing.T) {
+		loki := &fakeSplitLoki{shards: []string{"0", "1", "2", "3"}}
+		res, err := runSplitQuery(context.Background(), makeSplitAPI(loki), newQuery(`sum(rate({job="app"}[5m]))`), ResponseOpts{}, splitOptions{Interval: 24 * time.Hour, Sharding: true, Concurrency: 2}, logger)
+		require.NoError(t, err)
+		require.Len(t, loki.queries, 4*3)
+
+		frame := res.Frames[0]
+		require.Equal(t, 7, frame.Rows())
+		for i := 0; i < frame.Rows(); i++ {
+			require.Equal(t, 3.0, frame.Fields[1].At(i).(float64))
+		}
+	})
+
+	t.Run("does not shard queries that can't be added up", func(t *testing.T) {
+		loki := &fakeSplitLoki{shards: []string{"0", "1", "2", "3"}}
+		_, err := runSplitQuery(context.Background(), makeSplitAPI(loki), newQuery(`avg(rate({job="app"}[5m]))`), ResponseOpts{}, splitOptions{Sharding: true, Concurrency: 2}, logger)
+		require.NoError(t, err)
+		require.Equal(t, []string{`avg(rate({job="app"}[5m]))`}, loki.queries)
+	})
+
+	t.Run("does not split log queries", func(t *testing.T) {
+		loki := &fakeSplitLoki{}
+		_, err := runSplitQuery(context.Background(), makeSplitAPI(loki), newQuery(`{job="app"}`), ResponseOpts{}, splitOptions{Interval: 24 * time.Hour, Concurrency: 2}, logger)
+		require.NoError(t, err)
+		require.Len(t, loki.queries, 1)
+	})
+
+	t.Run("retries failed splits", func(t *testing.T) {
+		loki := &fakeSplitLoki{failures: 2}
+		res, err := runSplitQuery(context.Background(), makeSplitAPI(loki), newQuery(`sum(rate({job="app"}[5m]))`), ResponseOpts{}, splitOptions{Interval: 24 * time.Hour, Concurrency: 1, Retries: 2}, logger)
+		require.NoError(t, err)
+		require.Equal(t, 7, res.Frames[0].Rows())
+	})
+
+	t.Run("fails when a split fails after its retries", func(t *testing.T) {
+		loki := &fakeSplitLoki{failures: 3}
+		_, err := runSplitQuery(context.Background(), makeSplitAPI(loki), newQuery(`sum(rate({job="app"}[5m]))`), ResponseOpts{}, splitOptions{Interval: 24 * time.Hour, Concurrency: 1, Retries: 2}, logger)
+		require.ErrorContains(t, err, "too busy")
+	})
+}
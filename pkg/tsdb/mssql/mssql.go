@@ -124,3 +124,33 @@ func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthReque
 
 	return dsHandler.CheckHealth(azusercontext.WithUserFromHealthCheckReq(ctx, req), req)
 }
+
+func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, err
+	}
+
+	return dsHandler.SubscribeStream(ctx, req)
+}
+
+func (s *Service) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.PublishStreamResponse{Status: backend.PublishStreamStatusNotFound}, err
+	}
+
+	return dsHandler.PublishStream(ctx, req)
+}
+
+// RunStream polls a query for its new rows
+func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	// streams have no request headers, so only the user is known for Azure Current User authentication
+	ctx = azusercontext.WithCurrentUser(ctx, azusercontext.CurrentUserContext{User: req.PluginContext.User})
+	return dsHandler.RunStream(ctx, req, sender)
+}
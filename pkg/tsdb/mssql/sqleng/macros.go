@@ -125,6 +125,10 @@ func (m *msSQLMacroEngine) Interpolate(query *backend.DataQuery, timeRange backe
 	var macroError error
 
 	sql = m.ReplaceAllStringSubmatchFunc(rExp, sql, func(groups []string) string {
+		// Bind parameter references are bound once the macros are interpolated
+		if groups[1] == "__param" {
+			return groups[0]
+		}
 		args := strings.Split(groups[2], ",")
 		for i, arg := range args {
 			args[i] = strings.Trim(arg, " ")
@@ -32,6 +32,13 @@ func TestMacroEngine(t *testing.T) {
 			require.Equal(t, "select time_column AS time", sql)
 		})
 
+		t.Run("keep bind parameter references", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, dfltTimeRange, "select $__time(time_column) WHERE host = $__param(0)")
+			require.Nil(t, err)
+
+			require.Equal(t, "select time_column AS time WHERE host = $__param(0)", sql)
+		})
+
 		t.Run("interpolate __timeEpoch function", func(t *testing.T) {
 			sql, err := engine.Interpolate(query, dfltTimeRange, "select $__timeEpoch(time_column)")
 			require.Nil(t, err)
@@ -112,6 +112,7 @@ type QueryJson struct {
 	FillMode     string  `json:"fillMode"`
 	FillValue    float64 `json:"fillValue"`
 	Format       string  `json:"format"`
+	Parameters   []any   `json:"parameters"`
 }
 
 func (e *DataSourceHandler) TransformQueryError(logger log.Logger, err error) error {
@@ -352,12 +353,19 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	// bind parameters, referenced by the query once its macros are interpolated
+	interpolatedQuery, args, err := bindParameters(interpolatedQuery, queryJson.Parameters)
+	if err != nil {
+		errAppendDebug("binding parameters failed", e.TransformQueryError(logger, err), interpolatedQuery, backend.ErrorSourceDownstream)
+		return
+	}
+
 	db, err := e.getDB(queryContext)
 	if err != nil {
 		errAppendDebug("retrieving database connection failed", e.TransformQueryError(logger, err), interpolatedQuery, backend.ErrorSourcePlugin)
 		return
 	}
-	rows, err := db.QueryContext(queryContext, interpolatedQuery)
+	rows, err := db.QueryContext(queryContext, interpolatedQuery, args...)
 	if err != nil {
 		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery, backend.ErrorSourceDownstream)
 		return
@@ -0,0 +1,135 @@
+package sqleng
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// paramRegExp matches the references to the bind parameters of a query. The frontend replaces
+// template variables with them when the query binds its variables.
+var paramRegExp = regexp.MustCompile(`\$__param\((\d+)\)`)
+
+// bindParameters replaces the parameter references of a query with the @p1, @p2... placeholders
+// of the driver, and returns the values of the placeholders in order. Queries run as a single
+// batch, so all their statements share the session and the placeholders. Placeholders can't be
+// used in strings and quoted identifiers, such as '$host', so the references in them are replaced
+// with their escaped values. References in comments are left as they are.
+func bindParameters(query string, params []any) (string, []any, error) {
+	var b strings.Builder
+	var args []any
+	var bindErr error
+	bind := func(code string) {
+		b.WriteString(paramRegExp.ReplaceAllStringFunc(code, func(ref string) string {
+			value, err := parameterValue(ref, params)
+			if err != nil {
+				bindErr = err
+				return ref
+			}
+			args = append(args, value)
+			return "@p" + strconv.Itoa(len(args))
+		}))
+	}
+
+	start := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		var end int
+		var closing byte
+		switch {
+		case c == '\'' || c == '"':
+			closing = c
+			end = skipQuoted(query, i, closing)
+		case c == '[':
+			closing = ']'
+			end = skipQuoted(query, i, closing)
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end = skipUntil(query, i, "\n")
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end = skipUntil(query, i+2, "*/")
+		default:
+			continue
+		}
+		end = min(end+1, len(query))
+
+		bind(query[start:i])
+		if closing != 0 {
+			literal, err := quoteParameters(query[i:end], params, closing)
+			if err != nil {
+				bindErr = err
+			}
+			b.WriteString(literal)
+		} else {
+			b.WriteString(query[i:end])
+		}
+		start = end
+		i = end - 1
+	}
+	bind(query[start:])
+	return b.String(), args, bindErr
+}
+
+// parameterValue returns the value of a parameter reference
+func parameterValue(ref string, params []any) (any, error) {
+	idx, err := strconv.Atoi(paramRegExp.FindStringSubmatch(ref)[1])
+	if err != nil || idx >= len(params) {
+		return nil, backend.DownstreamErrorf("query parameter %s is not defined", ref)
+	}
+	return params[idx], nil
+}
+
+// quoteParameters replaces the parameter references of a string or quoted identifier with their
+// values, escaped by doubling the closing quote
+func quoteParameters(literal string, params []any, closing byte) (string, error) {
+	var quoteErr error
+	quoted := paramRegExp.ReplaceAllStringFunc(literal, func(ref string) string {
+		value, err := parameterValue(ref, params)
+		if err != nil {
+			quoteErr = err
+			return ref
+		}
+		return strings.ReplaceAll(formatParameter(value), string(closing), string([]byte{closing, closing}))
+	})
+	return quoted, quoteErr
+}
+
+// formatParameter returns the text of a parameter value
+func formatParameter(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// skipQuoted returns the index of the quote that closes the string or identifier starting at i.
+// Closing quotes are escaped by doubling them.
+func skipQuoted(query string, i int, closing byte) int {
+	for i++; i < len(query); i++ {
+		if query[i] == closing {
+			if i+1 < len(query) && query[i+1] == closing {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(query)
+}
+
+// skipUntil returns the index of the last byte of the next occurrence of end, or the end of the query
+func skipUntil(query string, i int, end string) int {
+	idx := strings.Index(query[i:], end)
+	if idx == -1 {
+		return len(query)
+	}
+	return i + idx + len(end) - 1
+}
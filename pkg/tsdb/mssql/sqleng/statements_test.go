@@ -0,0 +1,34 @@
+package sqleng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindParameters(t *testing.T) {
+	query, args, err := bindParameters("DECLARE @host varchar(50) = $__param(0); SELECT * FROM t WHERE host = @host AND dc IN ($__param(1),$__param(2))", []any{"web-1", "eu", "us"})
+	require.NoError(t, err)
+	require.Equal(t, "DECLARE @host varchar(50) = @p1; SELECT * FROM t WHERE host = @host AND dc IN (@p2,@p3)", query)
+	require.Equal(t, []any{"web-1", "eu", "us"}, args)
+
+	query, args, err = bindParameters("SELECT 1", nil)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT 1", query)
+	require.Empty(t, args)
+
+	_, _, err = bindParameters("SELECT $__param(1)", []any{"a"})
+	require.ErrorContains(t, err, "query parameter $__param(1) is not defined")
+
+	_, _, err = bindParameters("SELECT '$__param(1)'", []any{"a"})
+	require.ErrorContains(t, err, "query parameter $__param(1) is not defined")
+}
+
+func TestBindParametersInQuotes(t *testing.T) {
+	query, args, err := bindParameters(
+		`SELECT [$__param(2)], "$__param(2)" FROM t WHERE host = N'$__param(0)' AND dc LIKE '%$__param(1)%' AND v > $__param(3) -- $__param(0)`,
+		[]any{"it's", "'; DROP TABLE t; --", `a]"b`, 1.5})
+	require.NoError(t, err)
+	require.Equal(t, `SELECT [a]]"b], "a]""b" FROM t WHERE host = N'it''s' AND dc LIKE '%''; DROP TABLE t; --%' AND v > @p1 -- $__param(0)`, query)
+	require.Equal(t, []any{1.5}, args)
+}
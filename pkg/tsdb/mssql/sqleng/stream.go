@@ -0,0 +1,234 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	streamPathPrefix = "poll/"
+
+	defaultPollInterval = 5 * time.Second
+	minPollInterval     = time.Second
+	// initialPollWindow is how far back the first poll of a stream looks for rows
+	initialPollWindow = 5 * time.Minute
+)
+
+// streamQuery is a query whose $__timeFilter is over a column that only increases, such as an
+// insertion time, so that polling it from the latest time seen returns the rows added since
+type streamQuery struct {
+	QueryJson
+	PollInterval string `json:"pollInterval"`
+}
+
+func parseStreamQuery(raw json.RawMessage) (*streamQuery, time.Duration, error) {
+	query := &streamQuery{}
+	if err := json.Unmarshal(raw, query); err != nil {
+		return nil, 0, backend.DownstreamErrorf("error unmarshal query json: %w", err)
+	}
+	if !strings.Contains(query.RawSql, "$__timeFilter(") {
+		return nil, 0, backend.DownstreamErrorf("streaming queries must filter by time with $__timeFilter")
+	}
+
+	interval := defaultPollInterval
+	if query.PollInterval != "" {
+		var err error
+		interval, err = time.ParseDuration(query.PollInterval)
+		if err != nil {
+			return nil, 0, backend.DownstreamErrorf("invalid poll interval %q: %w", query.PollInterval, err)
+		}
+		interval = max(interval, minPollInterval)
+	}
+	return query, interval, nil
+}
+
+func (e *DataSourceHandler) SubscribeStream(_ context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	// Expect poll/${key}
+	if !strings.HasPrefix(req.Path, streamPathPrefix) {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, backend.DownstreamErrorf("expected poll in channel path")
+	}
+	if _, _, err := parseStreamQuery(req.Data); err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+	return &backend.SubscribeStreamResponse{
+		Status: backend.SubscribeStreamStatusOK,
+	}, nil
+}
+
+func (e *DataSourceHandler) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{
+		Status: backend.PublishStreamStatusPermissionDenied,
+	}, nil
+}
+
+// RunStream polls the query and sends the rows that are new since the previous poll.
+// Single instance for each channel (results are shared with all listeners)
+func (e *DataSourceHandler) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	_, interval, err := parseStreamQuery(req.Data)
+	if err != nil {
+		return err
+	}
+
+	logger := e.log.FromContext(ctx)
+	poller := newRowPoller(time.Now().Add(-initialPollWindow))
+	prev := data.FrameJSONCache{}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		frame, err := e.pollQuery(ctx, req, poller.from, time.Now(), interval)
+		if ctx.Err() != nil {
+			logger.Debug("Stop polling (context canceled)")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		frame, err = poller.newRows(frame)
+		if err != nil {
+			return err
+		}
+		if frame != nil && frame.Rows() > 0 {
+			next, err := data.FrameToJSONCache(frame)
+			if err != nil {
+				return err
+			}
+			if next.SameSchema(&prev) {
+				err = sender.SendBytes(next.Bytes(data.IncludeDataOnly))
+			} else {
+				err = sender.SendFrame(frame, data.IncludeAll)
+			}
+			if err != nil {
+				return err
+			}
+			prev = next
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Debug("Stop polling (context canceled)")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollQuery runs the streaming query over the time range and returns its first frame
+func (e *DataSourceHandler) pollQuery(ctx context.Context, req *backend.RunStreamRequest, from, to time.Time, interval time.Duration) (*data.Frame, error) {
+	resp, err := e.QueryData(ctx, &backend.QueryDataRequest{
+		PluginContext: req.PluginContext,
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			JSON:      req.Data,
+			Interval:  interval,
+			TimeRange: backend.TimeRange{From: from, To: to},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := resp.Responses["A"]
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if len(res.Frames) == 0 {
+		return nil, nil
+	}
+	return res.Frames[0], nil
+}
+
+// rowPoller keeps track of the rows of a streaming query that have been sent. Every poll starts at
+// the latest time seen, rather than at the end of the previous poll, so rows that were not returned
+// yet, because of the row limit or a late commit, are picked up by the next poll.
+type rowPoller struct {
+	from time.Time
+	// seen are the keys of the rows at the from time that have been sent, which the
+	// inclusive time filter returns again
+	seen map[string]struct{}
+}
+
+func newRowPoller(from time.Time) *rowPoller {
+	return &rowPoller{from: from, seen: map[string]struct{}{}}
+}
+
+// newRows returns a copy of the frame with the rows that have not been sent yet, and moves
+// the poller to the latest time of the frame
+func (p *rowPoller) newRows(frame *data.Frame) (*data.Frame, error) {
+	if frame == nil || frame.Rows() == 0 {
+		return nil, nil
+	}
+
+	timeIndex := -1
+	for i, field := range frame.Fields {
+		if field.Type().Time() {
+			timeIndex = i
+			break
+		}
+	}
+	if timeIndex == -1 {
+		return nil, backend.DownstreamErrorf("streaming queries must return a time column")
+	}
+
+	latest := p.from
+	latestKeys := map[string]struct{}{}
+	out := frame.EmptyCopy()
+	for i := 0; i < frame.Rows(); i++ {
+		v, ok := frame.Fields[timeIndex].ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		t := v.(time.Time)
+		if t.Before(p.from) {
+			continue
+		}
+
+		key := rowKey(frame, i)
+		if t.After(latest) {
+			latest = t
+			latestKeys = map[string]struct{}{}
+		}
+		if t.Equal(latest) {
+			latestKeys[key] = struct{}{}
+		}
+		if _, sent := p.seen[key]; sent && t.Equal(p.from) {
+			continue
+		}
+		out.AppendRow(frame.RowCopy(i)...)
+	}
+
+	if latest.Equal(p.from) {
+		for key := range latestKeys {
+			p.seen[key] = struct{}{}
+		}
+	} else {
+		p.from = latest
+		p.seen = latestKeys
+	}
+	return out, nil
+}
+
+func rowKey(frame *data.Frame, rowIdx int) string {
+	var sb strings.Builder
+	for _, field := range frame.Fields {
+		if v, ok := field.ConcreteAt(rowIdx); ok {
+			fmt.Fprintf(&sb, "%v", v)
+		} else {
+			sb.WriteString("null")
+		}
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
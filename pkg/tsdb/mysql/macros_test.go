@@ -37,6 +37,13 @@ func TestMacroEngine(t *testing.T) {
 			require.Equal(t, "select min(UNIX_TIMESTAMP(time_column) as time_sec)", sql)
 		})
 
+		t.Run("keep bind parameter references", func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, "select $__time(time_column) WHERE host = $__param(0)")
+			require.Nil(t, err)
+
+			require.Equal(t, "select UNIX_TIMESTAMP(time_column) as time_sec WHERE host = $__param(0)", sql)
+		})
+
 		t.Run("interpolate __timeGroup function", func(t *testing.T) {
 			sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column,'5m')")
 			require.Nil(t, err)
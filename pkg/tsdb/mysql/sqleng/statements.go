@@ -0,0 +1,294 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// paramRegExp matches the references to the bind parameters of a query. The frontend replaces
+// template variables with them when the query binds its variables.
+var paramRegExp = regexp.MustCompile(`\$__param\((\d+)\)`)
+
+// statement is a statement of a query, with the values of its bind parameters
+type statement struct {
+	sql  string
+	args []any
+}
+
+// interpolateStatements interpolates the macros of a query and then binds the parameters it
+// references. Queries that bind their variables are split into their statements, unless they hold
+// a compound statement, as a statement can only bind its own placeholders. Other queries run as
+// they are, so interpolated variables can't add statements.
+func (e *DataSourceHandler) interpolateStatements(query *backend.DataQuery, timeRange backend.TimeRange, rawSQL string, params []any) ([]statement, error) {
+	parts := []string{rawSQL}
+	if paramRegExp.MatchString(rawSQL) {
+		if split := splitStatements(rawSQL); len(split) > 1 && !hasCompoundStatement(rawSQL) {
+			parts = split
+		}
+	}
+
+	statements := make([]statement, 0, len(parts))
+	for _, part := range parts {
+		interpolated, err := e.macroEngine.Interpolate(query, timeRange, part)
+		if err != nil {
+			return nil, err
+		}
+		bound, args, err := bindParameters(interpolated, params)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement{sql: bound, args: args})
+	}
+	return statements, nil
+}
+
+// bindParameters replaces the parameter references of a statement with placeholders, and returns
+// the values of the placeholders in order. Placeholders can't be used in strings and quoted
+// identifiers, such as '$host', so the references in them are replaced with their escaped values.
+// References in comments are left as they are.
+func bindParameters(stmt string, params []any) (string, []any, error) {
+	var b strings.Builder
+	var args []any
+	var bindErr error
+	bind := func(code string) {
+		b.WriteString(paramRegExp.ReplaceAllStringFunc(code, func(ref string) string {
+			value, err := parameterValue(ref, params)
+			if err != nil {
+				bindErr = err
+				return ref
+			}
+			args = append(args, value)
+			return "?"
+		}))
+	}
+
+	start := 0
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+		quoted := c == '\'' || c == '"' || c == '`'
+		var end int
+		switch {
+		case quoted:
+			end = skipQuoted(stmt, i, c)
+		case c == '#' || (c == '-' && strings.HasPrefix(stmt[i:], "--") && (i+2 == len(stmt) || isSpace(stmt[i+2]))):
+			end = skipUntil(stmt, i, "\n")
+		case c == '/' && strings.HasPrefix(stmt[i:], "/*"):
+			end = skipUntil(stmt, i+2, "*/")
+		default:
+			continue
+		}
+		end = min(end+1, len(stmt))
+
+		bind(stmt[start:i])
+		if quoted {
+			literal, err := quoteParameters(stmt[i:end], params, func(value string) string {
+				return escapeQuoted(value, c)
+			})
+			if err != nil {
+				bindErr = err
+			}
+			b.WriteString(literal)
+		} else {
+			b.WriteString(stmt[i:end])
+		}
+		start = end
+		i = end - 1
+	}
+	bind(stmt[start:])
+	return b.String(), args, bindErr
+}
+
+// parameterValue returns the value of a parameter reference
+func parameterValue(ref string, params []any) (any, error) {
+	idx, err := strconv.Atoi(paramRegExp.FindStringSubmatch(ref)[1])
+	if err != nil || idx >= len(params) {
+		return nil, backend.DownstreamErrorf("query parameter %s is not defined", ref)
+	}
+	return params[idx], nil
+}
+
+// quoteParameters replaces the parameter references of a string or quoted identifier with their
+// values, escaped for it
+func quoteParameters(literal string, params []any, escape func(string) string) (string, error) {
+	var quoteErr error
+	quoted := paramRegExp.ReplaceAllStringFunc(literal, func(ref string) string {
+		value, err := parameterValue(ref, params)
+		if err != nil {
+			quoteErr = err
+			return ref
+		}
+		return escape(formatParameter(value))
+	})
+	return quoted, quoteErr
+}
+
+// formatParameter returns the text of a parameter value
+func formatParameter(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// escapeQuoted escapes a value for a string or identifier quoted with quote. Quotes are doubled
+// rather than escaped with a backslash, so the value can't end the string even when the session
+// sets NO_BACKSLASH_ESCAPES.
+func escapeQuoted(value string, quote byte) string {
+	if quote != '`' {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+	}
+	return strings.ReplaceAll(value, string(quote), string([]byte{quote, quote}))
+}
+
+// executedQueryString returns the statements of a query as they were executed
+func executedQueryString(statements []statement) string {
+	sqls := make([]string, 0, len(statements))
+	for _, s := range statements {
+		sqls = append(sqls, s.sql)
+	}
+	return strings.Join(sqls, ";\n")
+}
+
+// queryStatements runs the statements of a query on the same connection, so the first ones can
+// prepare the session (variables, temporary tables) for the last one, and returns the rows of the
+// last one. The returned function releases the connection once the rows are closed.
+func queryStatements(ctx context.Context, db *sql.DB, statements []statement) (*sql.Rows, func(), error) {
+	if len(statements) == 1 {
+		rows, err := db.QueryContext(ctx, statements[0].sql, statements[0].args...)
+		return rows, func() {}, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	release := func() {
+		_ = conn.Close()
+	}
+
+	last := len(statements) - 1
+	for i, s := range statements[:last] {
+		if _, err := conn.ExecContext(ctx, s.sql, s.args...); err != nil {
+			release()
+			return nil, nil, fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	rows, err := conn.QueryContext(ctx, statements[last].sql, statements[last].args...)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return rows, release, nil
+}
+
+// splitStatements splits a query on the semicolons that end its statements, skipping those in
+// strings, quoted identifiers and comments. Statements with nothing but comments are dropped.
+func splitStatements(query string) []string {
+	var statements []string
+	start := 0
+	hasCode := false
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i, c)
+			hasCode = true
+		case c == '#' || (c == '-' && strings.HasPrefix(query[i:], "--") && (i+2 == len(query) || isSpace(query[i+2]))):
+			i = skipUntil(query, i, "\n")
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipUntil(query, i+2, "*/")
+		case c == ';':
+			if hasCode {
+				statements = append(statements, strings.TrimSpace(query[start:i]))
+			}
+			start = i + 1
+			hasCode = false
+		case !isSpace(c):
+			hasCode = true
+		}
+	}
+	if hasCode {
+		statements = append(statements, strings.TrimSpace(query[start:]))
+	}
+	return statements
+}
+
+// hasCompoundStatement reports whether the query holds a BEGIN ... END block, such as the body of a
+// procedure, trigger or event. The statements of the block end with semicolons too, so the query
+// can not be split. BEGIN followed by a semicolon or WORK starts a transaction instead.
+func hasCompoundStatement(query string) bool {
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i, c)
+		case c == '#' || (c == '-' && strings.HasPrefix(query[i:], "--") && (i+2 == len(query) || isSpace(query[i+2]))):
+			i = skipUntil(query, i, "\n")
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipUntil(query, i+2, "*/")
+		case isWordByte(c) && (i == 0 || !isWordByte(query[i-1])):
+			end := i
+			for end < len(query) && isWordByte(query[end]) {
+				end++
+			}
+			if strings.EqualFold(query[i:end], "begin") {
+				rest := strings.ToLower(strings.TrimLeft(query[end:], " \t\r\n"))
+				if rest != "" && rest[0] != ';' && !strings.HasPrefix(rest, "work") {
+					return true
+				}
+			}
+			i = end - 1
+		}
+	}
+	return false
+}
+
+// skipQuoted returns the index of the quote that closes the string starting at i. MySQL escapes
+// quotes by doubling them or with a backslash.
+func skipQuoted(query string, i int, quote byte) int {
+	for i++; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(query)
+}
+
+// skipUntil returns the index of the last byte of the next occurrence of end, or the end of the query
+func skipUntil(query string, i int, end string) int {
+	idx := strings.Index(query[i:], end)
+	if idx == -1 {
+		return len(query)
+	}
+	return i + idx + len(end) - 1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
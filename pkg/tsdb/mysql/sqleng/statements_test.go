@@ -0,0 +1,101 @@
+package sqleng
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+type noopMacroEngine struct{}
+
+func (noopMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+func TestSplitStatements(t *testing.T) {
+	for query, expected := range map[string][]string{
+		"SELECT 1":                {"SELECT 1"},
+		"SELECT 1;":               {"SELECT 1"},
+		"SELECT 1; ;":             {"SELECT 1"},
+		"SET @a := 1;\nSELECT @a": {"SET @a := 1", "SELECT @a"},
+		"SELECT ';', \"a;b\", `c;d` FROM t; SELECT 2":       {"SELECT ';', \"a;b\", `c;d` FROM t", "SELECT 2"},
+		"SELECT 'it''s; fine', 'back\\'slash;' ; SELECT 2":  {"SELECT 'it''s; fine', 'back\\'slash;'", "SELECT 2"},
+		"SELECT 1 -- first;\n; # second;\nSELECT 2 /* ; */": {"SELECT 1 -- first;", "# second;\nSELECT 2 /* ; */"},
+		"SELECT 1; -- done":      {"SELECT 1"},
+		"SELECT 1--2;\nSELECT 2": {"SELECT 1--2", "SELECT 2"},
+		"CREATE TEMPORARY TABLE t AS SELECT 1 AS v; SELECT v FROM t": {"CREATE TEMPORARY TABLE t AS SELECT 1 AS v", "SELECT v FROM t"},
+	} {
+		require.Equal(t, expected, splitStatements(query), query)
+	}
+}
+
+func TestHasCompoundStatement(t *testing.T) {
+	for query, expected := range map[string]bool{
+		"SELECT 1; SELECT 2": false,
+		"BEGIN; INSERT INTO t VALUES (1); COMMIT; SELECT * FROM t": false,
+		"BEGIN WORK; SELECT 1": false,
+		"SELECT 'begin select' AS `begin`, begin_at FROM t; SELECT 2":                    false,
+		"SELECT 1 -- begin select\n; SELECT 2":                                           false,
+		"CREATE PROCEDURE p() BEGIN SELECT 1; SELECT 2; END":                             true,
+		"CREATE TRIGGER t BEFORE INSERT ON t FOR EACH ROW\nbegin\n  SET NEW.a = 1;\nend": true,
+	} {
+		require.Equal(t, expected, hasCompoundStatement(query), query)
+	}
+}
+
+func TestInterpolateStatements(t *testing.T) {
+	e := &DataSourceHandler{macroEngine: noopMacroEngine{}}
+	query := &backend.DataQuery{}
+
+	t.Run("Binds parameters in every statement", func(t *testing.T) {
+		statements, err := e.interpolateStatements(query, query.TimeRange,
+			"SET @host := $__param(0); SELECT * FROM t WHERE host = @host AND dc IN ($__param(1),$__param(2))",
+			[]any{"web-1", "eu", "us"})
+		require.NoError(t, err)
+		require.Equal(t, []statement{
+			{sql: "SET @host := ?", args: []any{"web-1"}},
+			{sql: "SELECT * FROM t WHERE host = @host AND dc IN (?,?)", args: []any{"eu", "us"}},
+		}, statements)
+		require.Equal(t, "SET @host := ?;\nSELECT * FROM t WHERE host = @host AND dc IN (?,?)", executedQueryString(statements))
+	})
+
+	t.Run("Keeps single statements as they are", func(t *testing.T) {
+		statements, err := e.interpolateStatements(query, query.TimeRange, "SELECT 1;\n", nil)
+		require.NoError(t, err)
+		require.Equal(t, []statement{{sql: "SELECT 1;\n"}}, statements)
+	})
+
+	t.Run("Keeps queries that don't bind their variables as they are", func(t *testing.T) {
+		sql := "SELECT * FROM t WHERE host = 'web-1'; DROP TABLE t"
+		statements, err := e.interpolateStatements(query, query.TimeRange, sql, nil)
+		require.NoError(t, err)
+		require.Equal(t, []statement{{sql: sql}}, statements)
+	})
+
+	t.Run("Keeps compound statements as they are", func(t *testing.T) {
+		sql := "CREATE PROCEDURE p() BEGIN SELECT 1; SELECT 2; END"
+		statements, err := e.interpolateStatements(query, query.TimeRange, sql, nil)
+		require.NoError(t, err)
+		require.Equal(t, []statement{{sql: sql}}, statements)
+	})
+
+	t.Run("Escapes parameters in strings and quoted identifiers instead of binding them", func(t *testing.T) {
+		statements, err := e.interpolateStatements(query, query.TimeRange,
+			"SELECT `$__param(2)` FROM t WHERE host = '$__param(0)' AND dc LIKE \"%$__param(1)%\" AND v > $__param(3) -- $__param(0)",
+			[]any{"it's", `a\"; DROP TABLE t; --`, "c`d", 1.5})
+		require.NoError(t, err)
+		require.Equal(t, []statement{{
+			sql:  "SELECT `c``d` FROM t WHERE host = 'it''s' AND dc LIKE \"%a\\\\\"\"; DROP TABLE t; --%\" AND v > ? -- $__param(0)",
+			args: []any{1.5},
+		}}, statements)
+	})
+
+	t.Run("Returns an error for undefined parameters", func(t *testing.T) {
+		_, err := e.interpolateStatements(query, query.TimeRange, "SELECT $__param(1)", []any{"a"})
+		require.ErrorContains(t, err, "query parameter $__param(1) is not defined")
+
+		_, err = e.interpolateStatements(query, query.TimeRange, "SELECT '$__param(1)'", []any{"a"})
+		require.ErrorContains(t, err, "query parameter $__param(1) is not defined")
+	})
+}
@@ -4,7 +4,19 @@ import { CoreApp, type SelectableValue } from '@grafana/data';
 import { Trans, t } from '@grafana/i18n';
 import { Alert, InlineField, InlineFieldRow, Input, Select, TextLink } from '@grafana/ui';
 
-import { type ExpressionQuery, type ExpressionQuerySettings, ReducerMode, reducerModes, reducerTypes } from '../types';
+import {
+  type ExpressionQuery,
+  type ExpressionQuerySettings,
+  HistogramReducer,
+  histogramReducerTypes,
+  ReducerMode,
+  reducerModes,
+  reducerTypes,
+} from '../types';
+
+const defaultQuantile = 0.95;
+
+const reduceFunctions = [...reducerTypes, ...histogramReducerTypes];
 
 interface Props {
   app?: CoreApp;
@@ -15,18 +27,27 @@ interface Props {
 }
 
 export const Reduce = ({ labelWidth = 'auto', onChange, app, refIds, query }: Props) => {
-  const reducer = reducerTypes.find((o) => o.value === query.reducer);
+  const reducer = reduceFunctions.find((o) => o.value === query.reducer);
 
   const onRefIdChange = (value: SelectableValue<string>) => {
     onChange({ ...query, expression: value.value });
   };
 
   const onSelectReducer = (value: SelectableValue<string>) => {
-    onChange({ ...query, reducer: value.value });
+    let settings = query.settings;
+    if (value.value === HistogramReducer.Quantile && settings?.quantile === undefined) {
+      settings = { ...settings, quantile: defaultQuantile };
+    }
+    if (value.value === HistogramReducer.FractionAbove && settings?.threshold === undefined) {
+      settings = { ...settings, threshold: 0 };
+    }
+    onChange({ ...query, reducer: value.value, settings });
   };
 
   const onSettingsChanged = (settings: ExpressionQuerySettings) => {
-    onChange({ ...query, settings: settings });
+    // keep the parameters of the histogram reducers when the mode changes
+    const { quantile, threshold } = query.settings ?? {};
+    onChange({ ...query, settings: { quantile, threshold, ...settings } });
   };
 
   const onModeChanged = (value: SelectableValue<ReducerMode>) => {
@@ -60,8 +81,50 @@ export const Reduce = ({ labelWidth = 'auto', onChange, app, refIds, query }: Pr
     onSettingsChanged({ mode: ReducerMode.ReplaceNonNumbers, replaceWithValue: value ?? 0 });
   };
 
+  const onQuantileChanged = (e: React.FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, settings: { ...query.settings, quantile: e.currentTarget.valueAsNumber } });
+  };
+
+  const onThresholdChanged = (e: React.FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, settings: { ...query.settings, threshold: e.currentTarget.valueAsNumber } });
+  };
+
   const mode = query.settings?.mode ?? ReducerMode.Strict;
 
+  const histogramParam = () => {
+    switch (query.reducer) {
+      case HistogramReducer.Quantile:
+        return (
+          <InlineField
+            label={t('expressions.reduce.histogram-param.label-quantile', 'Quantile')}
+            labelWidth={labelWidth}
+            tooltip={t('expressions.reduce.histogram-param.tooltip-quantile', 'Between 0 and 1, for example 0.95')}
+          >
+            <Input
+              type="number"
+              min={0}
+              max={1}
+              step={0.01}
+              width={10}
+              onChange={onQuantileChanged}
+              value={query.settings?.quantile ?? defaultQuantile}
+            />
+          </InlineField>
+        );
+      case HistogramReducer.FractionAbove:
+        return (
+          <InlineField
+            label={t('expressions.reduce.histogram-param.label-threshold', 'Threshold')}
+            labelWidth={labelWidth}
+          >
+            <Input type="number" width={10} onChange={onThresholdChanged} value={query.settings?.threshold ?? 0} />
+          </InlineField>
+        );
+      default:
+        return null;
+    }
+  };
+
   const replaceWithNumber = () => {
     if (mode !== ReducerMode.ReplaceNonNumbers) {
       return;
@@ -106,8 +169,9 @@ export const Reduce = ({ labelWidth = 'auto', onChange, app, refIds, query }: Pr
       </InlineFieldRow>
       <InlineFieldRow>
         <InlineField label={t('expressions.reduce.label-function', 'Function')} labelWidth={labelWidth}>
-          <Select options={reducerTypes} value={reducer} onChange={onSelectReducer} width={20} />
+          <Select options={reduceFunctions} value={reducer} onChange={onSelectReducer} width={20} />
         </InlineField>
+        {histogramParam()}
         <InlineField label={t('expressions.reduce.label-mode', 'Mode')} labelWidth={labelWidth}>
           <Select onChange={onModeChanged} options={reducerModes} value={mode} width={25} />
         </InlineField>
@@ -85,6 +85,25 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
 ];
 
+/** Reducers that only apply to histograms, such as Prometheus native histograms */
+export enum HistogramReducer {
+  Quantile = 'quantile',
+  FractionAbove = 'fraction_above',
+}
+
+export const histogramReducerTypes: Array<SelectableValue<string>> = [
+  {
+    value: HistogramReducer.Quantile,
+    label: 'Quantile',
+    description: 'Get a quantile of the observations of a histogram',
+  },
+  {
+    value: HistogramReducer.FractionAbove,
+    label: 'Fraction above',
+    description: 'Get the fraction of the observations of a histogram above a threshold',
+  },
+];
+
 export enum ReducerMode {
   Strict = '', // backend API wants an empty string to support "strict" mode
   ReplaceNonNumbers = 'replaceNN',
@@ -162,6 +181,10 @@ export interface ThresholdExpressionQuery extends ExpressionQuery {
 export interface ExpressionQuerySettings {
   mode?: ReducerMode;
   replaceWithValue?: number;
+  /** Only valid when reducer is quantile, between 0 and 1 */
+  quantile?: number;
+  /** Only valid when reducer is fraction_above */
+  threshold?: number;
 }
 
 export interface ClassicCondition {
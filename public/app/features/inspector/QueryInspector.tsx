@@ -1,6 +1,6 @@
 import { css } from '@emotion/css';
 import { PureComponent } from 'react';
-import { Subscription } from 'rxjs';
+import { lastValueFrom, Subscription } from 'rxjs';
 
 import { LoadingState, type PanelData } from '@grafana/data';
 import { selectors } from '@grafana/e2e-selectors';
@@ -10,6 +10,8 @@ import { Button, ClipboardButton, JSONFormatter, LoadingPlaceholder, Space, Stac
 import { backendSrv } from 'app/core/services/backend_srv';
 
 import { getPanelInspectorStyles2 } from './styles';
+import { downloadAsJson } from './utils/download';
+import { getRecordings, isRecordableRequest, type QueryResultsBody, RECORD_RESPONSE_HEADER } from './utils/recordings';
 
 interface ExecutedQueryInfo {
   refId: string;
@@ -24,11 +26,18 @@ interface Props {
   onRefreshQuery: () => void;
 }
 
+interface RecordableRequest {
+  url: string;
+  data: unknown;
+}
+
 interface State {
   allNodesExpanded: boolean | null;
   isMocking: boolean;
+  isRecording: boolean;
   mockedResponse: string;
   response: {};
+  recordableRequest?: RecordableRequest;
   executedQueries: ExecutedQueryInfo[];
 }
 
@@ -42,6 +51,7 @@ export class QueryInspector extends PureComponent<Props, State> {
       executedQueries: [],
       allNodesExpanded: null,
       isMocking: false,
+      isRecording: false,
       mockedResponse: '',
       response: {},
     };
@@ -115,6 +125,11 @@ export class QueryInspector extends PureComponent<Props, State> {
       return;
     }
 
+    // keep the request of the query service to record its responses
+    const recordableRequest: RecordableRequest | undefined = isRecordableRequest(response.config?.url)
+      ? { url: response.config.url, data: response.config.data }
+      : undefined;
+
     response = { ...response }; // clone - dont modify the response
 
     if (response.headers) {
@@ -152,9 +167,33 @@ export class QueryInspector extends PureComponent<Props, State> {
 
     this.setState({
       response: response,
+      recordableRequest,
     });
   }
 
+  onRecordResponses = async () => {
+    const { recordableRequest } = this.state;
+    if (!recordableRequest) {
+      return;
+    }
+
+    this.setState({ isRecording: true });
+    try {
+      const response = await lastValueFrom(
+        backendSrv.fetch<QueryResultsBody>({
+          url: recordableRequest.url,
+          method: 'POST',
+          data: recordableRequest.data,
+          headers: { [RECORD_RESPONSE_HEADER]: 'true' },
+          hideFromInspector: true,
+        })
+      );
+      downloadAsJson(getRecordings(response.data), 'recordings');
+    } finally {
+      this.setState({ isRecording: false });
+    }
+  };
+
   setFormattedJson = (formattedJson: {}) => {
     this.formattedJson = formattedJson;
   };
@@ -242,7 +281,7 @@ export class QueryInspector extends PureComponent<Props, State> {
   }
 
   render() {
-    const { allNodesExpanded, executedQueries, response } = this.state;
+    const { allNodesExpanded, executedQueries, isRecording, recordableRequest, response } = this.state;
     const { onRefreshQuery, data } = this.props;
     const openNodes = this.getNrOfOpenNodes();
     const styles = getPanelInspectorStyles2(config.theme2);
@@ -287,6 +326,21 @@ export class QueryInspector extends PureComponent<Props, State> {
               <Trans i18nKey="inspector.query.copy-to-clipboard">Copy to clipboard</Trans>
             </ClipboardButton>
           )}
+
+          {haveData && recordableRequest && (
+            <Button
+              icon={isRecording ? 'spinner' : 'download-alt'}
+              variant="secondary"
+              disabled={isRecording}
+              onClick={this.onRecordResponses}
+              tooltip={t(
+                'inspector.query.record-responses-tooltip',
+                'Runs the query again and downloads its responses, to replay them with the Recorded scenario of the TestData datasource'
+              )}
+            >
+              <Trans i18nKey="inspector.query.record-responses">Record responses</Trans>
+            </Button>
+          )}
         </Stack>
         <Space v={2} />
         <div className={styles.content}>
@@ -0,0 +1,30 @@
+import { getRecordings, isRecordableRequest } from './recordings';
+
+describe('recordings', () => {
+  describe('isRecordableRequest', () => {
+    it('accepts the requests of the query service', () => {
+      expect(isRecordableRequest('api/ds/query')).toBe(true);
+      expect(isRecordableRequest('/api/ds/query?ds_type=prometheus&requestId=Q100')).toBe(true);
+    });
+
+    it('rejects other requests', () => {
+      expect(isRecordableRequest(undefined)).toBe(false);
+      expect(isRecordableRequest('api/datasources/uid/abc/resources/labels')).toBe(false);
+      expect(isRecordableRequest('api/ds/query/expressions')).toBe(false);
+    });
+  });
+
+  describe('getRecordings', () => {
+    it('returns the recording of every result by refId', () => {
+      const recording = { from: 1000, to: 2000, frames: [] };
+      const body = {
+        results: {
+          A: { frames: [{ schema: { fields: [] }, data: { values: [[JSON.stringify(recording)]] } }] },
+          B: { frames: [] },
+        },
+      };
+
+      expect(getRecordings(body)).toEqual({ A: recording });
+    });
+  });
+});
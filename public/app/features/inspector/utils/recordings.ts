@@ -0,0 +1,33 @@
+import { type DataFrameJSON } from '@grafana/data';
+
+/**
+ * Asks the query service for recordings of the query responses instead of the responses.
+ * The recorded scenario of the TestData datasource replays them.
+ */
+export const RECORD_RESPONSE_HEADER = 'X-Grafana-Record-Response';
+
+export interface QueryResultsBody {
+  results?: Record<string, { frames?: DataFrameJSON[] }>;
+}
+
+/**
+ * Only the responses of the query service can be recorded
+ */
+export function isRecordableRequest(url?: string): boolean {
+  return Boolean(url && /(^|\/)api\/ds\/query(\?|$)/.test(url));
+}
+
+/**
+ * Returns the recordings of a query service response by refId.
+ * Each recording is held in the only value of the single frame of its result.
+ */
+export function getRecordings(body: QueryResultsBody): Record<string, unknown> {
+  const recordings: Record<string, unknown> = {};
+  for (const [refId, result] of Object.entries(body.results ?? {})) {
+    const recording = result.frames?.[0]?.data?.values?.[0]?.[0];
+    if (typeof recording === 'string') {
+      recordings[refId] = JSON.parse(recording);
+    }
+  }
+  return recordings;
+}
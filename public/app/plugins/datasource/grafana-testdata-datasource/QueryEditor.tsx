@@ -16,6 +16,7 @@ import { NodeGraphEditor } from './components/NodeGraphEditor';
 import { PredictablePulseEditor } from './components/PredictablePulseEditor';
 import { RandomWalkEditor } from './components/RandomWalkEditor';
 import { RawFrameEditor } from './components/RawFrameEditor';
+import { RecordedEditor } from './components/RecordedEditor';
 import { SimulationQueryEditor } from './components/SimulationQueryEditor';
 import { StreamingClientEditor } from './components/StreamingClientEditor';
 import { USAQueryEditor, usaQueryModes } from './components/USAQueryEditor';
@@ -302,6 +303,9 @@ export const QueryEditor = ({ query, datasource, onChange, onRunQuery }: Props)
       {scenarioId === TestDataQueryType.RawFrame && (
         <RawFrameEditor onChange={onUpdate} query={query} ds={datasource} />
       )}
+      {scenarioId === TestDataQueryType.Recorded && (
+        <RecordedEditor onChange={onUpdate} query={query} ds={datasource} />
+      )}
       {scenarioId === TestDataQueryType.CSVFile && <CSVFileEditor onChange={onUpdate} query={query} ds={datasource} />}
       {scenarioId === TestDataQueryType.CSVContent && (
         <CSVContentEditor onChange={onUpdate} query={query} ds={datasource} />
@@ -0,0 +1,74 @@
+import { useState } from 'react';
+
+import { Alert, CodeEditor, FileDropzone, FileDropzoneDefaultChildren } from '@grafana/ui';
+
+import { type EditorProps } from '../QueryEditor';
+
+/**
+ * Returns the recording in the content, which is either a single recording or
+ * the recordings downloaded from the query inspector by refId
+ */
+export const parseRecording = (content: string, refId: string): string => {
+  const json = JSON.parse(content);
+  if (Array.isArray(json?.frames)) {
+    return content;
+  }
+
+  const recordings = Object.values(json ?? {});
+  const recording = json?.[refId] ?? (recordings.length === 1 ? recordings[0] : undefined);
+  if (!Array.isArray(recording?.frames)) {
+    throw new Error(`No recording found for query ${refId}`);
+  }
+  return JSON.stringify(recording, null, 2);
+};
+
+export const RecordedEditor = ({ onChange, query }: EditorProps) => {
+  const [error, setError] = useState<string>();
+
+  const onSaveRecording = (content: string) => {
+    if (!content.trim()) {
+      setError(undefined);
+      onChange({ ...query, recording: undefined });
+      return;
+    }
+
+    try {
+      setError(undefined);
+      onChange({ ...query, recording: parseRecording(content, query.refId) });
+    } catch (e) {
+      setError(e instanceof Error ? e.message : 'Unable to read the recording');
+    }
+  };
+
+  const onLoadRecording = (result: string | ArrayBuffer | null) => {
+    if (typeof result === 'string') {
+      onSaveRecording(result);
+    }
+  };
+
+  return (
+    <>
+      {error && <Alert title={error} severity="error" />}
+      <FileDropzone
+        options={{ multiple: false, accept: ['.json'] }}
+        readAs="readAsText"
+        fileListRenderer={() => null}
+        onLoad={onLoadRecording}
+      >
+        <FileDropzoneDefaultChildren
+          primaryText="Upload recording"
+          secondaryText="Record responses from the query inspector, then drag and drop the file here or click to browse"
+        />
+      </FileDropzone>
+      <CodeEditor
+        height={300}
+        language="json"
+        value={query.recording ?? ''}
+        onBlur={onSaveRecording}
+        onSave={onSaveRecording}
+        showMiniMap={false}
+        showLineNumbers={true}
+      />
+    </>
+  );
+};
@@ -26,6 +26,7 @@ export enum TestDataQueryType {
   RandomWalkTable = 'random_walk_table',
   RandomWalkWithError = 'random_walk_with_error',
   RawFrame = 'raw_frame',
+  Recorded = 'recorded',
   ServerError500 = 'server_error_500',
   Simulation = 'simulation',
   Steps = 'steps',
@@ -131,4 +132,5 @@ export interface TestDataDataQuery extends common.DataQuery {
   errorStatusCode?: number;
   queryDelay?: string;
   queryDelayVariability?: number;
+  recording?: string;
 }
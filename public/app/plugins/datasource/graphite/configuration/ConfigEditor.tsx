@@ -103,6 +103,16 @@ export class ConfigEditor extends PureComponent<Props, State> {
               />
             </Field>
           )}
+          <Field
+            label="Backend functions"
+            description="Runs common functions such as sumSeries or aliasByNode in Grafana, on raw series from Graphite. Targets with other functions fail."
+          >
+            <Switch
+              id="emulate-functions"
+              value={!!options.jsonData.emulateFunctions}
+              onChange={onUpdateDatasourceJsonDataOptionChecked(this.props, 'emulateFunctions')}
+            />
+          </Field>
         </FieldSet>
         <MappingsConfiguration
           mappings={(options.jsonData.importConfiguration?.loki?.mappings || []).map(toString)}
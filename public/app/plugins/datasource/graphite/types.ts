@@ -24,6 +24,7 @@ export interface GraphiteOptions extends DataSourceJsonData {
   graphiteVersion: string;
   graphiteType: GraphiteType;
   rollupIndicatorEnabled?: boolean;
+  emulateFunctions?: boolean;
   importConfiguration: GraphiteQueryImportConfiguration;
 }
 
@@ -1,7 +1,7 @@
 import { css } from '@emotion/css';
 import { useState } from 'react';
 
-import { type QueryEditorProps } from '@grafana/data';
+import { type QueryEditorProps, type SelectableValue } from '@grafana/data';
 import {
   Button,
   FileDropzone,
@@ -11,17 +11,28 @@ import {
   Modal,
   QueryField,
   RadioButtonGroup,
+  Select,
   useStyles2,
   useTheme2,
 } from '@grafana/ui';
 
 import { type JaegerDatasource } from '../datasource';
-import { type JaegerQuery, type JaegerQueryType } from '../types';
+import { type JaegerQuery, type JaegerQueryType, type JaegerSpanMetric } from '../types';
 
 import { SearchForm } from './SearchForm';
 
 type Props = QueryEditorProps<JaegerDatasource, JaegerQuery>;
 
+const spanMetricOptions: Array<SelectableValue<JaegerSpanMetric>> = [
+  { value: 'rate', label: 'Rate', description: 'Spans per second' },
+  { value: 'errorRate', label: 'Error rate', description: 'Spans with errors per second' },
+  { value: 'errorRatio', label: 'Error ratio', description: 'Fraction of the spans with errors' },
+  { value: 'p50', label: 'Duration p50', description: 'Median span duration' },
+  { value: 'p90', label: 'Duration p90', description: '90th percentile of the span durations' },
+  { value: 'p95', label: 'Duration p95', description: '95th percentile of the span durations' },
+  { value: 'p99', label: 'Duration p99', description: '99th percentile of the span durations' },
+];
+
 export function QueryEditor({ datasource, query, onChange, onRunQuery }: Props) {
   const [uploadModalOpen, setUploadModalOpen] = useState(false);
   const theme = useTheme2();
@@ -36,6 +47,35 @@ export function QueryEditor({ datasource, query, onChange, onRunQuery }: Props)
     switch (query.queryType) {
       case 'search':
         return <SearchForm datasource={datasource} query={query} onChange={onChange} />;
+      case 'spanMetrics':
+        return (
+          <>
+            <InlineFieldRow>
+              <InlineField
+                label="Metric"
+                labelWidth={14}
+                tooltip="Metric of the spans of each service and operation. All the metrics are returned if none is selected."
+              >
+                <Select
+                  inputId="metric"
+                  options={spanMetricOptions}
+                  value={query.metric ?? null}
+                  placeholder="All metrics"
+                  onChange={(v) =>
+                    onChange({
+                      ...query,
+                      metric: v?.value,
+                    })
+                  }
+                  isClearable
+                  width={40}
+                  aria-label={'select-span-metric'}
+                />
+              </InlineField>
+            </InlineFieldRow>
+            <SearchForm datasource={datasource} query={query} onChange={onChange} />
+          </>
+        );
       case 'dependencyGraph':
         return null;
       default:
@@ -82,6 +122,7 @@ export function QueryEditor({ datasource, query, onChange, onRunQuery }: Props)
                   { value: 'search', label: 'Search' },
                   { value: undefined, label: 'TraceID' },
                   { value: 'dependencyGraph', label: 'Dependency graph' },
+                  { value: 'spanMetrics', label: 'Span metrics' },
                 ]}
                 value={query.queryType}
                 onChange={(v) =>
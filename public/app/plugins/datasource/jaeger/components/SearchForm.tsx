@@ -10,6 +10,7 @@ import { type JaegerDatasource } from '../datasource';
 import { type JaegerQuery } from '../types';
 
 const durationPlaceholder = 'e.g. 1.2s, 100ms, 500us';
+const spanFilterPlaceholder = '{ span.http.status_code >= 500 || duration > 1s }';
 
 type Props = {
   datasource: JaegerDatasource;
@@ -160,6 +161,27 @@ export function SearchForm({ datasource, query, onChange }: Props) {
             />
           </InlineField>
         </InlineFieldRow>
+        <InlineFieldRow>
+          <InlineField
+            label="Span Filter"
+            labelWidth={14}
+            grow
+            tooltip={'TraceQL-like filter of the spans, e.g. { name =~ "GET .*" && duration > 100ms && status = error }'}
+          >
+            <Input
+              id="spanFilter"
+              name="spanFilter"
+              value={query.spanFilter || ''}
+              placeholder={spanFilterPlaceholder}
+              onChange={(v) =>
+                onChange({
+                  ...query,
+                  spanFilter: v.currentTarget.value,
+                })
+              }
+            />
+          </InlineField>
+        </InlineFieldRow>
         <InlineFieldRow>
           <InlineField label="Min Duration" labelWidth={14} grow>
             <Input
@@ -192,22 +214,25 @@ export function SearchForm({ datasource, query, onChange }: Props) {
             />
           </InlineField>
         </InlineFieldRow>
-        <InlineFieldRow>
-          <InlineField label="Limit" labelWidth={14} grow tooltip="Maximum number of returned results">
-            <Input
-              id="limit"
-              name="limit"
-              value={query.limit || ''}
-              type="number"
-              onChange={(v) =>
-                onChange({
-                  ...query,
-                  limit: v.currentTarget.value ? parseInt(v.currentTarget.value, 10) : undefined,
-                })
-              }
-            />
-          </InlineField>
-        </InlineFieldRow>
+        {/* Span metrics are computed from every trace of the time range */}
+        {query.queryType !== 'spanMetrics' && (
+          <InlineFieldRow>
+            <InlineField label="Limit" labelWidth={14} grow tooltip="Maximum number of returned results">
+              <Input
+                id="limit"
+                name="limit"
+                value={query.limit || ''}
+                type="number"
+                onChange={(v) =>
+                  onChange({
+                    ...query,
+                    limit: v.currentTarget.value ? parseInt(v.currentTarget.value, 10) : undefined,
+                  })
+                }
+              />
+            </InlineField>
+          </InlineFieldRow>
+        )}
       </div>
       {alertText && <TemporaryAlert text={alertText} severity="error" />}
     </>
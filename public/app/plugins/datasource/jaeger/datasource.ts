@@ -109,6 +109,7 @@ export class JaegerDatasource extends DataSourceWithBackend<JaegerQuery, JaegerJ
       query: this.templateSrv.replace(query.query ?? '', scopedVars),
       minDuration: this.templateSrv.replace(query.minDuration ?? '', scopedVars),
       maxDuration: this.templateSrv.replace(query.maxDuration ?? '', scopedVars),
+      spanFilter: this.templateSrv.replace(query.spanFilter ?? '', scopedVars),
     };
   }
 
@@ -46,9 +46,15 @@ export type JaegerQuery = {
   minDuration?: string;
   maxDuration?: string;
   limit?: number;
+  // TraceQL-like filter of the spans of search and span metrics queries
+  spanFilter?: string;
+  // span metric of span metrics queries, undefined means all the metrics
+  metric?: JaegerSpanMetric;
 } & DataQuery;
 
-export type JaegerQueryType = 'search' | 'upload' | 'dependencyGraph';
+export type JaegerQueryType = 'search' | 'upload' | 'dependencyGraph' | 'spanMetrics';
+
+export type JaegerSpanMetric = 'rate' | 'errorRate' | 'errorRatio' | 'p50' | 'p90' | 'p95' | 'p99';
 
 export type JaegerResponse = {
   data: TraceResponse[];
@@ -15,6 +15,7 @@ import { type LokiOptions } from '../types';
 import { AlertingSettings } from './AlertingSettings';
 import { DerivedFields } from './DerivedFields';
 import { QuerySettings } from './QuerySettings';
+import { QuerySplittingSettings } from './QuerySplittingSettings';
 
 export type Props = DataSourcePluginOptionsEditorProps<LokiOptions>;
 
@@ -68,6 +69,7 @@ export const ConfigEditor = (props: Props) => {
             maxLines={options.jsonData.maxLines || ''}
             onMaxLinedChange={(value) => onOptionsChange(setMaxLines(options, value))}
           />
+          <QuerySplittingSettings options={options} onOptionsChange={onOptionsChange} />
           <DerivedFields
             fields={options.jsonData.derivedFields}
             onChange={(value) => onOptionsChange(setDerivedFields(options, value))}
@@ -0,0 +1,43 @@
+import { render, screen } from '@testing-library/react';
+import userEvent from '@testing-library/user-event';
+
+import { createDefaultConfigOptions } from '../mocks/datasource';
+
+import { QuerySplittingSettings } from './QuerySplittingSettings';
+
+describe('QuerySplittingSettings', () => {
+  it('should render', () => {
+    render(<QuerySplittingSettings options={createDefaultConfigOptions()} onOptionsChange={() => {}} />);
+    expect(screen.getByText('Query splitting')).toBeInTheDocument();
+    expect(screen.getByRole('switch')).not.toBeChecked();
+  });
+
+  it('should update the split interval', async () => {
+    const onChange = jest.fn();
+    render(<QuerySplittingSettings options={createDefaultConfigOptions()} onOptionsChange={onChange} />);
+    await userEvent.type(screen.getByLabelText('Split interval'), 'd');
+    expect(onChange).toHaveBeenCalledWith(
+      expect.objectContaining({
+        jsonData: expect.objectContaining({ maxLines: '531', querySplitInterval: 'd' }),
+      })
+    );
+  });
+
+  it('should enable sharding', async () => {
+    const onChange = jest.fn();
+    render(<QuerySplittingSettings options={createDefaultConfigOptions()} onOptionsChange={onChange} />);
+    await userEvent.click(screen.getByRole('switch'));
+    expect(onChange).toHaveBeenCalledWith(
+      expect.objectContaining({ jsonData: expect.objectContaining({ queryShardingEnabled: true }) })
+    );
+  });
+
+  it('should keep zero retries', async () => {
+    const onChange = jest.fn();
+    render(<QuerySplittingSettings options={createDefaultConfigOptions()} onOptionsChange={onChange} />);
+    await userEvent.type(screen.getByLabelText('Split retries'), '0');
+    expect(onChange).toHaveBeenCalledWith(
+      expect.objectContaining({ jsonData: expect.objectContaining({ querySplitRetries: 0 }) })
+    );
+  });
+});
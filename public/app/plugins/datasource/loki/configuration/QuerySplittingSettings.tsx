@@ -0,0 +1,104 @@
+import * as React from 'react';
+
+import { type DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import { ConfigDescriptionLink, ConfigSubSection } from '@grafana/plugin-ui';
+import { InlineField, InlineSwitch, Input } from '@grafana/ui';
+
+import { type LokiOptions } from '../types';
+
+type Props = Pick<DataSourcePluginOptionsEditorProps<LokiOptions>, 'options' | 'onOptionsChange'>;
+
+const parseCount = (value: string): number | undefined => {
+  const count = parseInt(value, 10);
+  return Number.isNaN(count) ? undefined : count;
+};
+
+export function QuerySplittingSettings({ options, onOptionsChange }: Props) {
+  const updateJsonData = (jsonData: Partial<LokiOptions>) =>
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, ...jsonData } });
+
+  return (
+    <ConfigSubSection
+      title="Query splitting"
+      description={
+        <ConfigDescriptionLink
+          description="Split long metric queries run by the Grafana server, such as alert rule queries, into smaller queries."
+          suffix="loki/configure-loki-data-source/#query-splitting"
+          feature="query splitting"
+        />
+      }
+    >
+      <InlineField
+        label="Split interval"
+        htmlFor="loki_config_querySplitInterval"
+        labelWidth={22}
+        disabled={options.readOnly}
+        tooltip="The longest time range of a split query, for example 1d. Leave empty to not split queries by time."
+      >
+        <Input
+          id="loki_config_querySplitInterval"
+          value={options.jsonData.querySplitInterval ?? ''}
+          onChange={(event: React.FormEvent<HTMLInputElement>) =>
+            updateJsonData({ querySplitInterval: event.currentTarget.value || undefined })
+          }
+          width={16}
+          placeholder="1d"
+          spellCheck={false}
+        />
+      </InlineField>
+      <InlineField
+        label="Shard queries"
+        htmlFor="loki_config_queryShardingEnabled"
+        labelWidth={22}
+        disabled={options.readOnly}
+        tooltip="Also split queries by the stream shards of their stream selector. Requires stream sharding to be enabled in Loki."
+      >
+        <InlineSwitch
+          id="loki_config_queryShardingEnabled"
+          value={options.jsonData.queryShardingEnabled ?? false}
+          onChange={(event: React.FormEvent<HTMLInputElement>) =>
+            updateJsonData({ queryShardingEnabled: event.currentTarget.checked })
+          }
+        />
+      </InlineField>
+      <InlineField
+        label="Split concurrency"
+        htmlFor="loki_config_querySplitConcurrency"
+        labelWidth={22}
+        disabled={options.readOnly}
+        tooltip="How many split queries run in parallel (default: 5)."
+      >
+        <Input
+          type="number"
+          id="loki_config_querySplitConcurrency"
+          value={options.jsonData.querySplitConcurrency ?? ''}
+          onChange={(event: React.FormEvent<HTMLInputElement>) =>
+            updateJsonData({ querySplitConcurrency: parseCount(event.currentTarget.value) })
+          }
+          width={16}
+          min={1}
+          placeholder="5"
+        />
+      </InlineField>
+      <InlineField
+        label="Split retries"
+        htmlFor="loki_config_querySplitRetries"
+        labelWidth={22}
+        disabled={options.readOnly}
+        tooltip="How many times a split query failing with a server error is retried (default: 2). Set to 0 to not retry."
+      >
+        <Input
+          type="number"
+          id="loki_config_querySplitRetries"
+          value={options.jsonData.querySplitRetries ?? ''}
+          onChange={(event: React.FormEvent<HTMLInputElement>) =>
+            updateJsonData({ querySplitRetries: parseCount(event.currentTarget.value) })
+          }
+          width={16}
+          min={0}
+          placeholder="2"
+        />
+      </InlineField>
+    </ConfigSubSection>
+  );
+}
@@ -32,6 +32,10 @@ export interface LokiOptions extends DataSourceJsonData {
   derivedFields?: DerivedFieldConfig[];
   alertmanager?: string;
   keepCookies?: string[];
+  querySplitInterval?: string;
+  queryShardingEnabled?: boolean;
+  querySplitConcurrency?: number;
+  querySplitRetries?: number;
 }
 
 export interface LokiStreamResult {